@@ -2,6 +2,7 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"reflect"
 	"strings"
@@ -14,10 +15,13 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/power"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 
 	"github.com/ipfs/go-cid"
 	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/pkg/errors"
 )
 
 // ActorView represents a generic way to represent details about any actor to the user.
@@ -28,6 +32,9 @@ type ActorView struct {
 	Nonce     uint64        `json:"nonce"`
 	Balance   types.AttoFIL `json:"balance"`
 	Head      cid.Cid       `json:"head,omitempty"`
+	// State is the decoded state of actors of known types. Only actor show
+	// sets it.
+	State interface{} `json:"state,omitempty"`
 }
 
 var actorCmd = &cmds.Command{
@@ -35,12 +42,27 @@ var actorCmd = &cmds.Command{
 		Tagline: "Interact with actors. Actors are built-in smart contracts.",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"ls": actorLsCmd,
+		"ls":   actorLsCmd,
+		"show": actorShowCmd,
 	},
 }
 
 var actorLsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the actors in the state tree at the head of the chain",
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("code", "Only list actors with this code cid"),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		code := cid.Undef
+		if c, ok := req.Options["code"].(string); ok {
+			var err error
+			if code, err = cid.Decode(c); err != nil {
+				return errors.Wrap(err, "invalid code cid")
+			}
+		}
+
 		results, err := GetPorcelainAPI(env).ActorLs(req.Context)
 		if err != nil {
 			return err
@@ -50,30 +72,12 @@ var actorLsCmd = &cmds.Command{
 			if result.Error != nil {
 				return result.Error
 			}
-
-			var output *ActorView
-
-			switch {
-			case result.Actor.Empty(): // empty (balance only) actors have no Code.
-				output = makeActorView(result.Actor, result.Address, nil)
-			case result.Actor.Code.Equals(types.AccountActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &account.Actor{})
-			case result.Actor.Code.Equals(types.InitActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &initactor.Actor{})
-			case result.Actor.Code.Equals(types.StorageMarketActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &storagemarket.Actor{})
-			case result.Actor.Code.Equals(types.PaymentBrokerActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &paymentbroker.Actor{})
-			case result.Actor.Code.Equals(types.PowerActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &power.Actor{})
-			case result.Actor.Code.Equals(types.MinerActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
-			case result.Actor.Code.Equals(types.BootstrapMinerActorCodeCid):
-				output = makeActorView(result.Actor, result.Address, &miner.Actor{})
-			default:
-				output = makeActorView(result.Actor, result.Address, nil)
+			if code.Defined() && !result.Actor.Code.Equals(code) {
+				continue
 			}
 
+			output := actorViewOf(result.Actor, result.Address)
+
 			if err := re.Emit(output); err != nil {
 				return err
 			}
@@ -97,6 +101,103 @@ var actorLsCmd = &cmds.Command{
 	},
 }
 
+var actorShowCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show an actor and its decoded state",
+		ShortDescription: `
+Prints the code, balance, nonce and head of the actor at an address. The state
+of miner, storage market, power and init actors is decoded; for other actors
+only the head cid of their state is shown.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address of the actor to show"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		act, err := GetPorcelainAPI(env).ActorGet(req.Context, addr)
+		if err != nil {
+			return err
+		}
+		output := actorViewOf(act, addr.String())
+
+		if st := newActorState(act.Code); st != nil && act.Head.Defined() {
+			node, err := GetPorcelainAPI(env).DAGGetNode(req.Context, act.Head.String())
+			if err != nil {
+				return errors.Wrapf(err, "failed to load state of actor %s", addr)
+			}
+			raw, ok := node.(ipld.Node)
+			if !ok {
+				return errors.Errorf("state of actor %s is not a dag node", addr)
+			}
+			if err := actor.UnmarshalStorage(raw.RawData(), st); err != nil {
+				return errors.Wrapf(err, "failed to decode state of actor %s", addr)
+			}
+			output.State = st
+		}
+
+		return re.Emit(output)
+	},
+	Type: &ActorView{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, a *ActorView) error {
+			marshaled, err := json.MarshalIndent(a, "", "  ")
+			if err != nil {
+				return err
+			}
+			_, err = fmt.Fprintf(w, "%s\n", marshaled)
+			return err
+		}),
+	},
+}
+
+// actorViewOf returns the view of an actor, naming its type after its code.
+func actorViewOf(act *actor.Actor, addr string) *ActorView {
+	switch {
+	case act.Empty(): // empty (balance only) actors have no Code.
+		return makeActorView(act, addr, nil)
+	case act.Code.Equals(types.AccountActorCodeCid):
+		return makeActorView(act, addr, &account.Actor{})
+	case act.Code.Equals(types.InitActorCodeCid):
+		return makeActorView(act, addr, &initactor.Actor{})
+	case act.Code.Equals(types.StorageMarketActorCodeCid):
+		return makeActorView(act, addr, &storagemarket.Actor{})
+	case act.Code.Equals(types.PaymentBrokerActorCodeCid):
+		return makeActorView(act, addr, &paymentbroker.Actor{})
+	case act.Code.Equals(types.PowerActorCodeCid):
+		return makeActorView(act, addr, &power.Actor{})
+	case act.Code.Equals(types.MinerActorCodeCid):
+		return makeActorView(act, addr, &miner.Actor{})
+	case act.Code.Equals(types.BootstrapMinerActorCodeCid):
+		return makeActorView(act, addr, &miner.Actor{})
+	default:
+		return makeActorView(act, addr, nil)
+	}
+}
+
+// newActorState returns an empty state to decode the state of actors with
+// the given code into, or nil if their state is not known.
+func newActorState(code cid.Cid) interface{} {
+	switch {
+	case !code.Defined():
+		return nil
+	case code.Equals(types.InitActorCodeCid):
+		return &initactor.State{}
+	case code.Equals(types.StorageMarketActorCodeCid):
+		return &storagemarket.State{}
+	case code.Equals(types.PowerActorCodeCid):
+		return &power.State{}
+	case code.Equals(types.MinerActorCodeCid), code.Equals(types.BootstrapMinerActorCodeCid):
+		return &miner.State{}
+	default:
+		return nil
+	}
+}
+
 func makeActorView(act *actor.Actor, addr string, actType interface{}) *ActorView {
 	var actorType string
 	if actType == nil {
@@ -9,8 +9,11 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
+	"github.com/filecoin-project/go-filecoin/fixtures"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 func TestActorDaemon(t *testing.T) {
@@ -41,3 +44,85 @@ func TestActorDaemon(t *testing.T) {
 		}
 	})
 }
+
+func TestActorLs(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	t.Run("lists all actors", func(t *testing.T) {
+		var addrs []string
+		for _, view := range d.ListActors() {
+			addrs = append(addrs, view.Address)
+		}
+		assert.Contains(t, addrs, fixtures.TestMiners[0])
+		assert.Contains(t, addrs, address.StorageMarketAddress.String())
+		assert.Contains(t, addrs, address.PaymentBrokerAddress.String())
+	})
+
+	t.Run("filters actors by code", func(t *testing.T) {
+		miner := d.ActorState(fixtures.TestMiners[0])
+		views := d.ListActorsOfCode(miner.Code)
+		require.NotEmpty(t, views)
+		for _, view := range views {
+			assert.Equal(t, miner.Code, view.Code)
+			assert.Equal(t, miner.ActorType, view.ActorType)
+		}
+
+		addrs := []string{}
+		for _, view := range d.ListActorsOfCode(types.AccountActorCodeCid) {
+			assert.Equal(t, "AccountActor", view.ActorType)
+			addrs = append(addrs, view.Address)
+		}
+		assert.Contains(t, addrs, fixtures.TestAddresses[0])
+		assert.NotContains(t, addrs, fixtures.TestMiners[0])
+	})
+
+	t.Run("rejects an invalid code", func(t *testing.T) {
+		d.RunFail("invalid code cid", "actor", "ls", "--code", "notacid")
+	})
+}
+
+func TestActorShow(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	t.Run("shows the decoded state of a miner", func(t *testing.T) {
+		view := d.ActorState(fixtures.TestMiners[0])
+		assert.Contains(t, []string{"MinerActor", "BootstrapMinerActor"}, view.ActorType)
+		assert.Equal(t, fixtures.TestMiners[0], view.Address)
+		assert.True(t, view.Head.Defined())
+
+		// Sector sets are encoded as arrays of sector ids.
+		var st struct {
+			Owner      string
+			Asks       []interface{}
+			ProvingSet []uint64
+		}
+		require.NoError(t, json.Unmarshal(view.State, &st))
+		assert.Equal(t, fixtures.TestAddresses[0], st.Owner)
+		assert.Empty(t, st.Asks)
+		assert.NotNil(t, st.ProvingSet)
+
+		d.RunSuccess("mining", "start")
+		d.WaitForMessageRequireSuccess(d.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "10"))
+		d.RunSuccess("mining", "stop")
+
+		require.NoError(t, json.Unmarshal(d.ActorState(fixtures.TestMiners[0]).State, &st))
+		assert.Len(t, st.Asks, 1)
+	})
+
+	t.Run("shows the head of actors without known state", func(t *testing.T) {
+		view := d.ActorState(address.PaymentBrokerAddress.String())
+		assert.Equal(t, "PaymentbrokerActor", view.ActorType)
+		assert.True(t, view.Head.Defined())
+		assert.Empty(t, view.State)
+	})
+
+	t.Run("fails for addresses without an actor", func(t *testing.T) {
+		d.RunFail("not found", "actor", "show", d.CreateAddress())
+	})
+}
@@ -2,9 +2,12 @@ package commands
 
 import (
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
+	"io/ioutil"
+	"strings"
 
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
@@ -13,6 +16,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
 )
 
 var walletCmd = &cmds.Command{
@@ -20,9 +24,21 @@ var walletCmd = &cmds.Command{
 		Tagline: "Manage your filecoin wallets",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"balance": balanceCmd,
-		"import":  walletImportCmd,
-		"export":  walletExportCmd,
+		"balance":       balanceCmd,
+		"total-balance": totalBalanceCmd,
+		"default":       defaultAddressCmd,
+		"set-default":   walletSetDefaultCmd,
+		"import":        walletImportCmd,
+		"import-seed":   walletImportSeedCmd,
+		"nonce":         walletNonceCmd,
+		"export":        walletExportCmd,
+		"pubkey":        walletPubkeyCmd,
+		"rm":            walletRmCmd,
+		"unlock":        walletUnlockCmd,
+		"validate-key":  walletValidateKeyCmd,
+		"decode-sig":    walletDecodeSigCmd,
+		"sign":          walletSignCmd,
+		"verify":        walletVerifyCmd,
 	},
 }
 
@@ -48,8 +64,22 @@ type AddressLsResult struct {
 }
 
 var addrsNewCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create a new address in the wallet",
+		ShortDescription: `
+Generates a new key of the given type, secp256k1 by default, stores it in the
+wallet and prints its address.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("type", "type of the key to create, secp256k1 or bls").WithDefault(types.SECP256K1),
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		addr, err := GetPorcelainAPI(env).WalletNewAddress()
+		protocol, err := addressProtocolOfKeyType(req.Options["type"].(string))
+		if err != nil {
+			return err
+		}
+		addr, err := GetPorcelainAPI(env).WalletNewAddress(protocol)
 		if err != nil {
 			return err
 		}
@@ -64,6 +94,19 @@ var addrsNewCmd = &cmds.Command{
 	},
 }
 
+// addressProtocolOfKeyType returns the protocol of the addresses of keys of
+// type keyType.
+func addressProtocolOfKeyType(keyType string) (address.Protocol, error) {
+	switch keyType {
+	case types.SECP256K1:
+		return address.SECP256K1, nil
+	case types.BLS:
+		return address.BLS, nil
+	default:
+		return 0, errors.Errorf("unsupported key type %q, must be one of %s, %s", keyType, types.SECP256K1, types.BLS)
+	}
+}
+
 var addrsLsCmd = &cmds.Command{
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		addrs := GetPorcelainAPI(env).WalletAddresses()
@@ -115,6 +158,13 @@ var addrsLookupCmd = &cmds.Command{
 }
 
 var defaultAddressCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the default wallet address",
+		ShortDescription: `
+Prints the address that commands send from when --from is omitted. If no
+default has been set, the first address in the wallet becomes the default.
+`,
+	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		addr, err := GetPorcelainAPI(env).WalletDefaultAddress()
 		if err != nil {
@@ -133,6 +183,13 @@ var defaultAddressCmd = &cmds.Command{
 }
 
 var balanceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the balance of an address",
+		ShortDescription: `
+Prints the balance of an address in FIL, as a decimal of up to 18 places.
+An address that has no actor on chain yet has a balance of 0.
+`,
+	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("address", true, false, "Address to get balance for"),
 	},
@@ -156,11 +213,92 @@ var balanceCmd = &cmds.Command{
 	},
 }
 
+var totalBalanceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the sum of the balances of all wallet addresses",
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		balance, err := GetPorcelainAPI(env).WalletTotalBalance(req.Context)
+		if err != nil {
+			return err
+		}
+		return re.Emit(balance)
+	},
+	Type: &types.AttoFIL{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, b types.AttoFIL) error {
+			return PrintString(w, b)
+		}),
+	},
+}
+
 // WalletSerializeResult is the type wallet export and import return and expect.
 type WalletSerializeResult struct {
 	KeyInfo []*types.KeyInfo
 }
 
+var walletImportSeedCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Import the addresses of a seed phrase",
+		ShortDescription: `
+Reads a BIP39 mnemonic from stdin or the given file and imports the keys of
+its first --count addresses, derived along the path m/44'/461'/0'/0/i. The
+same phrase always yields the same addresses.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("seedFile", true, false, "File containing the mnemonic").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.IntOption("count", "number of addresses to import").WithDefault(1),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		count, _ := req.Options["count"].(int)
+		if count < 1 || count > wallet.MaxMnemonicKeys {
+			return fmt.Errorf("count must be between 1 and %d, got %d", wallet.MaxMnemonicKeys, count)
+		}
+
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no file given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given file was not a files.File")
+		}
+
+		phrase, err := ioutil.ReadAll(fi)
+		if err != nil {
+			return err
+		}
+
+		addrs, err := GetPorcelainAPI(env).WalletImportMnemonic(string(phrase), count)
+		if err != nil {
+			return err
+		}
+
+		var alr AddressLsResult
+		for _, addr := range addrs {
+			alr.Addresses = append(alr.Addresses, addr.String())
+		}
+
+		return re.Emit(&alr)
+	},
+	Type: &AddressLsResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, addrs *AddressLsResult) error {
+			for _, addr := range addrs.Addresses {
+				_, err := fmt.Fprintln(w, addr)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
 var walletImportCmd = &cmds.Command{
 	Arguments: []cmdkit.Argument{
 		cmdkit.FileArg("walletFile", true, false, "File containing wallet data to import").EnableStdin(),
@@ -212,7 +350,382 @@ var walletImportCmd = &cmds.Command{
 	},
 }
 
+var walletValidateKeyCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Check a wallet file without importing it",
+		ShortDescription: `
+Parses a wallet file as accepted by wallet import, checks the type and length
+of each key and prints the address each key would import as. Nothing is added
+to the wallet.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("walletFile", true, false, "File containing wallet data to validate").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no file given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given file was not a files.File")
+		}
+
+		var wir *WalletSerializeResult
+		if err := json.NewDecoder(fi).Decode(&wir); err != nil {
+			return errors.Wrap(err, "failed to parse wallet file")
+		}
+		if wir == nil || len(wir.KeyInfo) == 0 {
+			return fmt.Errorf("no keys in wallet file")
+		}
+
+		var alr AddressLsResult
+		for i, ki := range wir.KeyInfo {
+			if ki == nil {
+				return fmt.Errorf("key %d is empty", i)
+			}
+			if err := ki.Validate(); err != nil {
+				return errors.Wrapf(err, "key %d", i)
+			}
+			addr, err := ki.Address()
+			if err != nil {
+				return errors.Wrapf(err, "key %d", i)
+			}
+			alr.Addresses = append(alr.Addresses, addr.String())
+		}
+
+		return re.Emit(&alr)
+	},
+	Type: &AddressLsResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, addrs *AddressLsResult) error {
+			for _, addr := range addrs.Addresses {
+				_, err := fmt.Fprintln(w, addr)
+				if err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
+var walletDecodeSigCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the components of a signature",
+		ShortDescription: `
+Decodes a signature given in hex (optionally prefixed with 0x) or in base64, as
+signatures appear in JSON output. Prints its scheme and length and, for
+secp256k1 signatures, its r and s values and recovery id. Signatures do not
+carry their scheme, so it is inferred from the length: 65 bytes for secp256k1
+and 96 bytes for bls.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("signature", true, false, "Signature in hex or base64"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		sig, err := parseSignature(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		components, err := types.DecodeSignature(sig)
+		if err != nil {
+			return err
+		}
+		return re.Emit(components)
+	},
+	Type: &types.SignatureComponents{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, components *types.SignatureComponents) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Scheme:      %s\n", components.Scheme)
+			sw.Printf("Length:      %d\n", components.Length)
+			if components.Scheme == types.SECP256K1 {
+				sw.Printf("R:           %x\n", components.R)
+				sw.Printf("S:           %x\n", components.S)
+				sw.Printf("Recovery ID: %d\n", components.RecoveryID)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+// parseSignature decodes a signature from hex, with or without a 0x prefix,
+// or from base64.
+func parseSignature(s string) (types.Signature, error) {
+	if sig, err := hex.DecodeString(strings.TrimPrefix(s, "0x")); err == nil {
+		return sig, nil
+	}
+	sig, err := base64.StdEncoding.DecodeString(s)
+	if err != nil {
+		return nil, errors.New("signature is neither hex nor base64")
+	}
+	return sig, nil
+}
+
+var walletSignCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Sign data with the key of a wallet address",
+		ShortDescription: `
+Signs the bytes of the given file, or of stdin, with the key of the --from
+address, or of the default address, and prints the hex encoded signature, e.g.
+to prove control of an address off-chain:
+
+$ go-filecoin wallet sign --from <address> < challenge
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("data", true, false, "Data to sign").EnableStdin(),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address to sign with"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		data, err := readFileArg(req)
+		if err != nil {
+			return err
+		}
+
+		sig, err := GetPorcelainAPI(env).WalletSign(req.Context, addr, data)
+		if err != nil {
+			return err
+		}
+		return re.Emit(hex.EncodeToString(sig))
+	},
+	Encoders: stringEncoderMap,
+}
+
+// WalletVerifyResult is the result of running the wallet verify command.
+type WalletVerifyResult struct {
+	Valid bool
+}
+
+var walletVerifyCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Check a signature of data by an address",
+		ShortDescription: `
+Checks that the signature, in hex or base64, is a signature of the bytes of the
+given file, or of stdin, by the address, and prints true or false. The address
+does not need to be in the wallet.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address that signed the data"),
+		cmdkit.StringArg("signature", true, false, "Signature in hex or base64"),
+		cmdkit.FileArg("data", true, false, "Data that was signed").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		sig, err := parseSignature(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		data, err := readFileArg(req)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&WalletVerifyResult{Valid: GetPorcelainAPI(env).WalletVerify(data, addr, sig)})
+	},
+	Type: &WalletVerifyResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *WalletVerifyResult) error {
+			_, err := fmt.Fprintln(w, res.Valid)
+			return err
+		}),
+	},
+}
+
+// readFileArg reads the whole of the first file argument of req.
+func readFileArg(req *cmds.Request) ([]byte, error) {
+	iter := req.Files.Entries()
+	if !iter.Next() {
+		return nil, fmt.Errorf("no file given: %s", iter.Err())
+	}
+
+	fi, ok := iter.Node().(files.File)
+	if !ok {
+		return nil, fmt.Errorf("given file was not a files.File")
+	}
+	return ioutil.ReadAll(fi)
+}
+
+var walletRmCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Delete the key of a wallet address",
+		ShortDescription: `
+Removes the private key of the given address from the wallet. Export the key
+first if you might need it again; a deleted key cannot be recovered. The
+default address cannot be deleted.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address of the key to delete"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		if err := GetPorcelainAPI(env).WalletDeleteAddress(addr); err != nil {
+			if err == wallet.ErrUnknownAddress {
+				return fmt.Errorf("address %s is not in the wallet", addr)
+			}
+			return err
+		}
+		return re.Emit(&addressResult{addr.String()})
+	},
+	Type: &addressResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, a *addressResult) error {
+			_, err := fmt.Fprintln(w, a.Address)
+			return err
+		}),
+	},
+}
+
+var walletSetDefaultCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Set the default wallet address",
+		ShortDescription: `
+Makes the given address the one that commands send from when --from is
+omitted. The address must be in the wallet. The default is stored in the
+config and kept across daemon restarts.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address to use as the default"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		if err := GetPorcelainAPI(env).WalletSetDefaultAddress(addr); err != nil {
+			return err
+		}
+		return re.Emit(&addressResult{addr.String()})
+	},
+	Type: &addressResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, a *addressResult) error {
+			_, err := fmt.Fprintln(w, a.Address)
+			return err
+		}),
+	},
+}
+
+var walletNonceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the nonce of the next message from an address",
+		ShortDescription: `
+Prints the nonce the next message sent from the given address must have to be
+mined. This is the address's nonce on chain, advanced past the nonces of its
+messages pending in the message pool. If messages were removed from the pool,
+e.g. with 'mpool rm', the first free nonce is printed.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address to get the next nonce for"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		nonce, err := GetPorcelainAPI(env).MessageNextNonce(req.Context, addr)
+		if err != nil {
+			return err
+		}
+		return re.Emit(nonce)
+	},
+	Type: uint64(0),
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, nonce uint64) error {
+			_, err := fmt.Fprintln(w, nonce)
+			return err
+		}),
+	},
+}
+
+var walletPubkeyCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the public key of a wallet address",
+		ShortDescription: `
+Prints the hex encoded public key of the given address. The public key of a
+BLS address is in the BLS public key encoding.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address to show the public key of"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		addr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		pubkey, err := GetPorcelainAPI(env).WalletGetPubKeyForAddress(addr)
+		if err != nil {
+			return err
+		}
+		return re.Emit(hex.EncodeToString(pubkey))
+	},
+	Encoders: stringEncoderMap,
+}
+
+var walletUnlockCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Unlock the encrypted keys of the wallet",
+		ShortDescription: `
+Unlocks the wallet backends that keep their keys encrypted, so that their
+addresses can sign. Pass the passphrase on stdin to keep it out of your shell
+history:
+
+$ go-filecoin wallet unlock < passphrase-file
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("passphrase", true, false, "Passphrase of the encrypted keys").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := GetPorcelainAPI(env).WalletUnlock(req.Arguments[0]); err != nil {
+			return err
+		}
+		return re.Emit("wallet unlocked")
+	},
+	Encoders: stringEncoderMap,
+}
+
 var walletExportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Export the keys of wallet addresses",
+		ShortDescription: `
+Prints the private keys of the given addresses, e.g. to back them up. The JSON
+output (--enc=json) can be imported again with:
+
+$ go-filecoin wallet import <file>
+
+Keys held by a remote signer cannot be exported.
+`,
+	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("addresses", true, true, "Addresses of keys to export").EnableStdin(),
 	},
@@ -1,18 +1,29 @@
 package commands_test
 
 import (
+	"bytes"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
 	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/filecoin-project/go-bls-sigs"
 	"github.com/ipfs/go-cid"
+	"github.com/minio/blake2b-simd"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
 	"github.com/filecoin-project/go-filecoin/fixtures"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
@@ -33,6 +44,54 @@ func TestAddrsNewAndList(t *testing.T) {
 	}
 }
 
+func TestAddrsNewType(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	secpAddr, err := address.NewFromString(d.CreateWalletAddrOfType(types.SECP256K1))
+	require.NoError(t, err)
+	assert.Equal(t, address.SECP256K1, secpAddr.Protocol())
+
+	blsAddr, err := address.NewFromString(d.CreateWalletAddrOfType(types.BLS))
+	require.NoError(t, err)
+	assert.Equal(t, address.BLS, blsAddr.Protocol())
+
+	list := d.RunSuccess("address", "ls").ReadStdout()
+	assert.Contains(t, list, secpAddr.String())
+	assert.Contains(t, list, blsAddr.String())
+
+	d.RunFail("must be one of secp256k1, bls", "address", "new", "--type", "ed25519")
+}
+
+func TestWalletImportSeed(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	phrase := "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	addrs := d1.ImportSeed(phrase, 3)
+	require.Len(t, addrs, 3)
+	list := d1.RunSuccess("address", "ls").ReadStdout()
+	for _, addr := range addrs {
+		assert.Contains(t, list, addr)
+	}
+
+	// Another wallet recovers the same addresses from the phrase.
+	assert.Equal(t, addrs, d2.ImportSeed(phrase, 3))
+	assert.Equal(t, addrs[:1], d2.ImportSeed(phrase, 1))
+
+	d1.RunWithStdin(strings.NewReader("abandon about"), "wallet", "import-seed").AssertFail("must have 12, 15, 18, 21 or 24 words")
+	d1.RunWithStdin(strings.NewReader(strings.Repeat("abandon ", 12)), "wallet", "import-seed").AssertFail("invalid mnemonic checksum")
+	d1.RunWithStdin(strings.NewReader(phrase), "wallet", "import-seed", "--count", "0").AssertFail("count must be between 1 and 100")
+	d1.RunWithStdin(strings.NewReader(phrase), "wallet", "import-seed", "--count", "101").AssertFail("count must be between 1 and 100")
+}
+
 func TestWalletBalance(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -52,6 +111,42 @@ func TestWalletBalance(t *testing.T) {
 	addrNew := d.RunSuccess("address new")
 	balance = d.RunSuccess("wallet", "balance", addrNew.ReadStdoutTrimNewlines())
 	assert.Equal(t, "0", balance.ReadStdoutTrimNewlines())
+
+	t.Log("[success] decoded as AttoFIL")
+	assert.Equal(t, types.NewAttoFILFromFIL(9999900000), *d.WalletBalance(address.NetworkAddress.String()))
+	assert.True(t, d.WalletBalance(addr).IsZero())
+
+	t.Log("[success] never seen outside the wallet, zero")
+	neverSeen := address.NewForTestGetter()()
+	assert.True(t, d.WalletBalance(neverSeen.String()).IsZero())
+}
+
+func TestWalletTotalBalance(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := makeTestDaemonWithMinerAndStart(t)
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+	d1.ConnectSuccess(d2)
+
+	assert.True(t, d2.TotalBalance().IsZero())
+
+	addr1 := d2.CreateAddress()
+	addr2 := d2.CreateAddress()
+	for addr, value := range map[string]string{addr1: "10", addr2: "25"} {
+		d1.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1", "--gas-limit", "300",
+			"--value", value,
+			addr,
+		)
+	}
+	d1.MineAndPropagate(10*time.Second, d2)
+
+	assert.Equal(t, types.NewAttoFILFromFIL(35), *d2.TotalBalance())
+	assert.Equal(t, "35", d2.RunSuccess("wallet", "total-balance").ReadStdoutTrimNewlines())
 }
 
 func TestAddrLookupAndUpdate(t *testing.T) {
@@ -147,6 +242,139 @@ func TestWalletExportImportRoundTrip(t *testing.T) {
 
 }
 
+func TestWalletExportKeyImportsIntoAnotherDaemon(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	addr := d1.CreateAddress()
+	ki := d1.ExportWalletKey(addr)
+	exported, err := ki.Address()
+	require.NoError(t, err)
+	assert.Equal(t, addr, exported.String())
+
+	walletFile, err := json.Marshal(commands.WalletSerializeResult{KeyInfo: []*types.KeyInfo{ki}})
+	require.NoError(t, err)
+	imported := d2.RunWithStdin(bytes.NewReader(walletFile), "wallet", "import").AssertSuccess().ReadStdoutTrimNewlines()
+	assert.Equal(t, addr, imported)
+	assert.True(t, ki.Equals(d2.ExportWalletKey(addr)))
+}
+
+func TestWalletRm(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	addr := d.CreateAddress()
+	d.DeleteWalletAddr(addr)
+
+	var addrs commands.AddressLsResult
+	th.RunSuccessJSON(d, &addrs, "address", "ls")
+	assert.NotContains(t, addrs.Addresses, addr)
+
+	d.RunFail("not in the wallet", "wallet", "rm", addr)
+
+	def := d.GetDefaultAddress()
+	d.RunFail("cannot delete the default address", "wallet", "rm", def)
+}
+
+func TestWalletSetDefault(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(
+		t,
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[1]),
+	).Start()
+	defer d.ShutdownSuccess()
+
+	assert.Equal(t, fixtures.TestAddresses[0], strings.TrimSpace(d.RunSuccess("wallet", "default").ReadStdout()))
+
+	d.SetDefaultAddress(fixtures.TestAddresses[1])
+	assert.Equal(t, fixtures.TestAddresses[1], strings.TrimSpace(d.RunSuccess("wallet", "default").ReadStdout()))
+
+	t.Run("message send without --from uses the default", func(t *testing.T) {
+		msgcid := d.RunSuccess("message", "send",
+			"--gas-price", "1", "--gas-limit", "300",
+			fixtures.TestAddresses[2],
+		).ReadStdoutTrimNewlines()
+
+		status := d.RunSuccess("message", "status", msgcid).ReadStdout()
+		assert.Contains(t, status, fixtures.TestAddresses[1])
+	})
+
+	t.Run("rejects an address that is not in the wallet", func(t *testing.T) {
+		d.RunFail("not in the wallet", "wallet", "set-default", fixtures.TestAddresses[3])
+		assert.Equal(t, fixtures.TestAddresses[1], strings.TrimSpace(d.RunSuccess("wallet", "default").ReadStdout()))
+	})
+
+	t.Run("default survives a restart", func(t *testing.T) {
+		d.Restart()
+		assert.Equal(t, fixtures.TestAddresses[1], strings.TrimSpace(d.RunSuccess("wallet", "default").ReadStdout()))
+	})
+}
+
+func TestWalletPubkey(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	addr := d.CreateAddress()
+	pubkey, err := hex.DecodeString(d.RunSuccess("wallet", "pubkey", addr).ReadStdoutTrimNewlines())
+	require.NoError(t, err)
+	derived, err := address.NewSecp256k1Address(pubkey)
+	require.NoError(t, err)
+	assert.Equal(t, addr, derived.String())
+}
+
+func TestWalletUnlockWithoutEncryptedKeys(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	d.RunFail("no encrypted backend", "wallet", "unlock", "passphrase")
+}
+
+func TestWalletEncrypted(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	dir, err := ioutil.TempDir("", "wallet-passphrase")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	passphraseFile := filepath.Join(dir, "passphrase")
+	require.NoError(t, ioutil.WriteFile(passphraseFile, []byte("correct horse\n"), 0600))
+
+	d := th.NewDaemon(t, th.WalletPassphraseFile(passphraseFile)).Start()
+	defer d.ShutdownSuccess()
+
+	data := []byte("data to be signed")
+	def := d.GetDefaultAddress()
+
+	t.Run("the wallet starts locked", func(t *testing.T) {
+		d.RunWithStdin(bytes.NewReader(data), "wallet", "sign").AssertFail("wallet is locked")
+	})
+
+	t.Run("rejects a wrong passphrase", func(t *testing.T) {
+		d.RunFail("wrong passphrase", "wallet", "unlock", "battery staple")
+		d.RunWithStdin(bytes.NewReader(data), "wallet", "sign").AssertFail("wallet is locked")
+	})
+
+	t.Run("signs once unlocked", func(t *testing.T) {
+		d.RunSuccess("wallet", "unlock", "correct horse")
+
+		sig := d.RunWithStdin(bytes.NewReader(data), "wallet", "sign").AssertSuccess().ReadStdoutTrimNewlines()
+		assert.Equal(t, "true", d.RunWithStdin(bytes.NewReader(data), "wallet", "verify", def, sig).AssertSuccess().ReadStdoutTrimNewlines())
+	})
+}
+
 func TestWalletExportPrivateKeyConsistentDisplay(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -173,3 +401,167 @@ func mustDecodeCid(cidStr string) cid.Cid {
 
 	return decode
 }
+
+func TestWalletValidateKey(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	validFile := fixtures.KeyFilePaths()[0]
+	raw, err := ioutil.ReadFile(validFile)
+	require.NoError(t, err)
+
+	dir, err := ioutil.TempDir("", "validate-key")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+
+	t.Run("valid key file", func(t *testing.T) {
+		require.NoError(t, d.ValidateKeyFile(validFile))
+		out := d.RunSuccess("wallet", "validate-key", validFile)
+		assert.Equal(t, fixtures.TestAddresses[0], out.ReadStdoutTrimNewlines())
+
+		// Validating does not import the key.
+		assert.NotContains(t, d.RunSuccess("address", "ls").ReadStdout(), fixtures.TestAddresses[0])
+	})
+
+	t.Run("truncated key", func(t *testing.T) {
+		var wallet struct {
+			KeyInfo []*types.KeyInfo
+		}
+		require.NoError(t, json.Unmarshal(raw, &wallet))
+		wallet.KeyInfo[0].PrivateKey = wallet.KeyInfo[0].PrivateKey[:16]
+		truncated, err := json.Marshal(wallet)
+		require.NoError(t, err)
+		path := filepath.Join(dir, "truncated-key.key")
+		require.NoError(t, ioutil.WriteFile(path, truncated, 0644))
+
+		err = d.ValidateKeyFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), path)
+		assert.Contains(t, err.Error(), "invalid secp256k1 private key length: got 16 bytes, expected 32")
+	})
+
+	t.Run("truncated file", func(t *testing.T) {
+		path := filepath.Join(dir, "truncated-file.key")
+		require.NoError(t, ioutil.WriteFile(path, raw[:len(raw)/2], 0644))
+
+		err := d.ValidateKeyFile(path)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to parse wallet file")
+	})
+}
+
+func TestWalletDecodeSignature(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	sk, err := crypto.GenerateKey()
+	require.NoError(t, err)
+	hash := blake2b.Sum256([]byte("sign me"))
+	sig, err := crypto.SignSecp(sk, hash[:])
+	require.NoError(t, err)
+
+	components := d.DecodeSignature(sig)
+	assert.Equal(t, types.SECP256K1, components.Scheme)
+	assert.Equal(t, types.SecpSignatureBytes, components.Length)
+	assert.Equal(t, sig[:32], components.R)
+	assert.Equal(t, sig[32:64], components.S)
+	assert.True(t, components.RecoveryID <= 3, "recovery id %d out of range", components.RecoveryID)
+
+	// Signatures as they appear in JSON output are accepted too.
+	out := d.RunSuccess("wallet", "decode-sig", base64.StdEncoding.EncodeToString(sig)).ReadStdout()
+	assert.Contains(t, out, "secp256k1")
+
+	d.RunFail("neither secp256k1", "wallet", "decode-sig", "0x0102")
+}
+
+func TestWalletNonce(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	from := fixtures.TestAddresses[0]
+	start := d.Nonce(from)
+
+	send := func() cid.Cid {
+		out := d.RunSuccess("message", "send",
+			"--from", from,
+			"--gas-price", "1", "--gas-limit", "300",
+			fixtures.TestAddresses[1],
+		)
+		c, err := cid.Parse(out.ReadStdoutTrimNewlines())
+		require.NoError(t, err)
+		return c
+	}
+
+	send()
+	second := send()
+	send()
+	assert.Equal(t, start+3, d.Nonce(from))
+
+	// Removing the second message also removes the third, which depends on it.
+	d.MpoolRm(second)
+	assert.Equal(t, start+1, d.Nonce(from))
+
+	d.RunSuccess("mining", "once")
+	assert.Equal(t, start+1, d.Nonce(from))
+
+	assert.Equal(t, uint64(0), d.Nonce(fixtures.TestAddresses[4]))
+}
+
+func TestWalletSignAndVerify(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	data := []byte("prove you hold this address")
+
+	isValid := func(addr string, sig types.Signature) bool {
+		a, err := address.NewFromString(addr)
+		require.NoError(t, err)
+		return types.IsValidSignature(data, a, sig)
+	}
+	verify := func(addr string, sig types.Signature, data []byte) string {
+		return d.RunWithStdin(bytes.NewReader(data), "wallet", "verify", addr, hex.EncodeToString(sig)).AssertSuccess().ReadStdoutTrimNewlines()
+	}
+
+	t.Run("secp256k1", func(t *testing.T) {
+		addr := d.CreateAddress()
+		sig := d.SignData(addr, data)
+		assert.True(t, isValid(addr, sig))
+
+		assert.Equal(t, "true", verify(addr, sig, data))
+		assert.Equal(t, "false", verify(addr, sig, []byte("other data")))
+		assert.Equal(t, "false", verify(d.CreateAddress(), sig, data))
+	})
+
+	t.Run("bls", func(t *testing.T) {
+		sk := bls.PrivateKeyGenerate()
+		ki := &types.KeyInfo{PrivateKey: sk[:], CryptSystem: types.BLS}
+		walletFile, err := json.Marshal(commands.WalletSerializeResult{KeyInfo: []*types.KeyInfo{ki}})
+		require.NoError(t, err)
+		addr := d.RunWithStdin(bytes.NewReader(walletFile), "wallet", "import").AssertSuccess().ReadStdoutTrimNewlines()
+
+		sig := d.SignData(addr, data)
+		assert.True(t, isValid(addr, sig))
+
+		assert.Equal(t, "true", verify(addr, sig, data))
+		assert.Equal(t, "false", verify(addr, sig, []byte("other data")))
+	})
+
+	t.Run("signs with the default address", func(t *testing.T) {
+		sig := d.RunWithStdin(bytes.NewReader(data), "wallet", "sign").AssertSuccess().ReadStdoutTrimNewlines()
+		assert.Equal(t, "true", d.RunWithStdin(bytes.NewReader(data), "wallet", "verify", d.GetDefaultAddress(), sig).AssertSuccess().ReadStdoutTrimNewlines())
+	})
+
+	t.Run("fails for an address not in the wallet", func(t *testing.T) {
+		d.RunWithStdin(bytes.NewReader(data), "wallet", "sign", "--from", fixtures.TestAddresses[0]).AssertFail("could not find address")
+	})
+}
@@ -2,18 +2,25 @@
 package commands
 
 import (
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/ipfs/go-cid"
 	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	files "github.com/ipfs/go-ipfs-files"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
 )
 
 var chainCmd = &cmds.Command{
@@ -21,54 +28,95 @@ var chainCmd = &cmds.Command{
 		Tagline: "Inspect the filecoin blockchain",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"export":   storeExportCmd,
-		"head":     storeHeadCmd,
-		"import":   storeImportCmd,
-		"ls":       storeLsCmd,
-		"status":   storeStatusCmd,
-		"set-head": storeSetHeadCmd,
-		"sync":     storeSyncCmd,
+		"block":        storeBlockCmd,
+		"block-cid":    storeBlockCidCmd,
+		"block-reward": storeBlockRewardCmd,
+		"check-weight": storeCheckWeightCmd,
+		"export":       storeExportCmd,
+		"faults":       storeFaultsCmd,
+		"head":         storeHeadCmd,
+		"import":       storeImportCmd,
+		"ls":           storeLsCmd,
+		"orphans":      storeOrphansCmd,
+		"receipts":     storeReceiptsCmd,
+		"status":       storeStatusCmd,
+		"set-head":     storeSetHeadCmd,
+		"sync":         storeSyncCmd,
 	},
 }
 
+// ChainHeadResult is the head tipset as output by chain head.
+type ChainHeadResult struct {
+	Key    block.TipSetKey
+	Height uint64
+	Blocks []*block.Block
+}
+
 var storeHeadCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Get heaviest tipset CIDs",
+		ShortDescription: `
+Prints the CIDs of the blocks of the head tipset. The JSON output (--enc=json)
+is a single object holding the CIDs, the height and the blocks of the tipset.
+`,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		head, err := GetPorcelainAPI(env).ChainHead()
 		if err != nil {
 			return err
 		}
-		return re.Emit(head.Key())
+		height, err := head.Height()
+		if err != nil {
+			return err
+		}
+		return re.Emit(&ChainHeadResult{
+			Key:    head.Key(),
+			Height: height,
+			Blocks: head.ToSlice(),
+		})
 	},
-	Type: []cid.Cid{},
+	Type: &ChainHeadResult{},
 	Encoders: cmds.EncoderMap{
-		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res []cid.Cid) error {
-			for _, r := range res {
-				_, err := fmt.Fprintln(w, r.String())
-				if err != nil {
-					return err
-				}
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *ChainHeadResult) error {
+			sw := NewSilentWriter(w)
+			for _, c := range res.Key.ToSlice() {
+				sw.Println(c.String())
 			}
-			return nil
+			return sw.Error()
 		}),
 	},
 }
 
 var storeLsCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
-		Tagline:          "List blocks in the blockchain",
-		ShortDescription: `Provides a list of blocks in order from head to genesis. By default, only CIDs are returned for each block.`,
+		Tagline: "List blocks in the blockchain",
+		ShortDescription: `Provides a list of blocks in order from head to genesis. By default, only CIDs are returned for each block.
+Long chains can be paged through with --limit and --from: each page starts at
+the parents of the last tipset of the previous page.`,
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("long", "l", "List blocks in long format, including CID, Miner, StateRoot, block height and message count respectively"),
+		cmdkit.UintOption("limit", "List at most this many tipsets, defaults to all"),
+		cmdkit.StringOption("from", "Tipset (comma-separated block CIDs) to start at, defaults to the chain head"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		iter, err := GetPorcelainAPI(env).ChainLs(req.Context)
+		limit, _ := req.Options["limit"].(uint)
+
+		api := GetPorcelainAPI(env)
+		fromKey := api.ChainHeadKey()
+		if from, ok := req.Options["from"].(string); ok {
+			blockCids, err := cidsFromSlice(strings.Split(from, ","))
+			if err != nil {
+				return errors.Wrap(err, "--from is not a list of block cids")
+			}
+			fromKey = block.NewTipSetKey(blockCids...)
+		}
+
+		iter, err := api.ChainLsFrom(req.Context, fromKey)
 		if err != nil {
 			return err
 		}
+		var listed uint
 		for ; !iter.Complete(); err = iter.Next() {
 			if err != nil {
 				return err
@@ -76,9 +124,13 @@ var storeLsCmd = &cmds.Command{
 			if !iter.Value().Defined() {
 				panic("tipsets from this iterator should have at least one member")
 			}
+			if limit > 0 && listed == limit {
+				break
+			}
 			if err := re.Emit(iter.Value().ToSlice()); err != nil {
 				return err
 			}
+			listed++
 		}
 		return nil
 	},
@@ -116,6 +168,344 @@ var storeLsCmd = &cmds.Command{
 	},
 }
 
+// ChainReceiptResult is a message mined on chain together with its receipt.
+type ChainReceiptResult struct {
+	Height     uint64
+	MessageCid cid.Cid
+	ExitCode   uint8
+	GasAttoFIL types.AttoFIL
+	Return     [][]byte
+}
+
+var storeReceiptsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the receipts of messages mined in a range of heights",
+		ShortDescription: `Provides the receipt of every message executed in the tipsets between
+--from-height and --to-height inclusive, in order from the highest tipset to
+the lowest. By default the range covers the whole chain. Each line lists the
+tipset height, message CID, exit code, gas charge in FIL and the hex encoded
+return values.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("from-height", "Lowest tipset height to include"),
+		cmdkit.Uint64Option("to-height", "Highest tipset height to include, defaults to the chain head"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromHeight, _ := req.Options["from-height"].(uint64)
+		toHeight, hasTo := req.Options["to-height"].(uint64)
+		if hasTo && toHeight < fromHeight {
+			return fmt.Errorf("to-height %d is below from-height %d", toHeight, fromHeight)
+		}
+
+		api := GetPorcelainAPI(env)
+		iter, err := api.ChainLs(req.Context)
+		if err != nil {
+			return err
+		}
+		for ; !iter.Complete(); err = iter.Next() {
+			if err != nil {
+				return err
+			}
+			height, err := iter.Value().Height()
+			if err != nil {
+				return err
+			}
+			if height < fromHeight {
+				return nil
+			}
+			if hasTo && height > toHeight {
+				continue
+			}
+
+			chainMsgs, err := api.MessagesInTipSet(req.Context, iter.Value())
+			if err != nil {
+				return err
+			}
+			for _, chainMsg := range chainMsgs {
+				msgCid, err := chainMsg.Message.Cid()
+				if err != nil {
+					return err
+				}
+				if err := re.Emit(&ChainReceiptResult{
+					Height:     height,
+					MessageCid: msgCid,
+					ExitCode:   chainMsg.Receipt.ExitCode,
+					GasAttoFIL: chainMsg.Receipt.GasAttoFIL,
+					Return:     chainMsg.Receipt.Return,
+				}); err != nil {
+					return err
+				}
+			}
+		}
+		return nil
+	},
+	Type: &ChainReceiptResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *ChainReceiptResult) error {
+			returns := make([]string, len(res.Return))
+			for i, r := range res.Return {
+				returns[i] = hex.EncodeToString(r)
+			}
+			_, err := fmt.Fprintf(w, "%d\t%s\t%d\t%s\t%s\n", res.Height, res.MessageCid, res.ExitCode, res.GasAttoFIL, strings.Join(returns, ","))
+			return err
+		}),
+	},
+}
+
+var storeBlockCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show a block with its messages and their receipts",
+		ShortDescription: `Prints the header of the block with the given CID, its messages and, if the
+block is on the current chain, the receipt of each message from executing the
+tipset that includes the block. Fails if the node does not have the block.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the block to show"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		id, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		blk, err := GetPorcelainAPI(env).ChainBlock(req.Context, id)
+		if err != nil {
+			return err
+		}
+		return re.Emit(blk)
+	},
+	Type: porcelain.BlockWithReceipts{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, blk *porcelain.BlockWithReceipts) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Block %s\n", blk.Header.Cid())
+			sw.Printf("Miner:  %s\n", blk.Header.Miner)
+			sw.Printf("Height: %d\n", blk.Header.Height)
+			sw.Printf("Messages: %d\n", len(blk.Messages))
+			for i, m := range blk.Messages {
+				msgCid, err := m.Cid()
+				if err != nil {
+					return err
+				}
+				if i < len(blk.Receipts) && blk.Receipts[i] != nil {
+					sw.Printf("  %s exit code %d\n", msgCid, blk.Receipts[i].ExitCode)
+				} else {
+					sw.Printf("  %s\n", msgCid)
+				}
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var storeBlockCidCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Compute the CID of a block header",
+		ShortDescription: `Reads a JSON encoded block header, as printed by 'chain ls --enc=json' or
+'show header --enc=json', and prints its CID. The CID is computed from the
+same CBOR serialization the node uses to store and address blocks.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("header", true, false, "JSON encoded block header").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no header given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given header was not a files.File")
+		}
+		defer func() { _ = fi.Close() }()
+
+		var header block.Block
+		if err := json.NewDecoder(fi).Decode(&header); err != nil {
+			return errors.Wrap(err, "could not decode block header")
+		}
+		return re.Emit(header.Cid())
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, c cid.Cid) error {
+			_, err := fmt.Fprintln(w, c)
+			return err
+		}),
+	},
+}
+
+// ChainCheckWeightResult is the range of heights over which chain weight was
+// found to strictly increase.
+type ChainCheckWeightResult struct {
+	FromHeight uint64
+	ToHeight   uint64
+}
+
+var storeCheckWeightCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Verify that chain weight increases from parent to child",
+		ShortDescription: `Every block records the weight of its parent tipset. Checks that the weight
+recorded by each tipset between --from-height and --to-height inclusive is
+greater than the weight recorded by its parent, and fails with the lowest
+tipset where it is not. By default the range covers the whole chain. Children
+of the genesis tipset are not checked, since genesis has weight zero.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("from-height", "Lowest tipset height to check"),
+		cmdkit.Uint64Option("to-height", "Highest tipset height to check, defaults to the chain head"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromHeight, _ := req.Options["from-height"].(uint64)
+		toHeight, hasTo := req.Options["to-height"].(uint64)
+		if !hasTo {
+			head, err := GetPorcelainAPI(env).ChainHead()
+			if err != nil {
+				return err
+			}
+			toHeight, err = head.Height()
+			if err != nil {
+				return err
+			}
+		}
+		if toHeight < fromHeight {
+			return fmt.Errorf("to-height %d is below from-height %d", toHeight, fromHeight)
+		}
+
+		violation, err := GetPorcelainAPI(env).ChainCheckWeight(req.Context, fromHeight, toHeight)
+		if err != nil {
+			return err
+		}
+		if violation != nil {
+			return fmt.Errorf("weight does not increase at height %d: tipset %s has parent weight %d, parent at height %d has parent weight %d",
+				violation.Height, violation.Key, violation.ParentWeight, violation.ParentHeight, violation.GrandparentWeight)
+		}
+		return re.Emit(&ChainCheckWeightResult{FromHeight: fromHeight, ToHeight: toHeight})
+	},
+	Type: &ChainCheckWeightResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *ChainCheckWeightResult) error {
+			_, err := fmt.Fprintf(w, "weight increases from height %d to %d\n", res.FromHeight, res.ToHeight)
+			return err
+		}),
+	},
+}
+
+var storeFaultsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List miners detected producing more than one block at a height",
+		ShortDescription: `A miner that produces two different blocks at the same height equivocates,
+which is a consensus fault. Lists each equivocation the syncer has detected
+since the node started, with the miner, the height and the conflicting block
+cids.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		faults := GetPorcelainAPI(env).ChainEquivocations()
+		if faults == nil {
+			faults = []consensus.Equivocation{}
+		}
+		return re.Emit(faults)
+	},
+	Type: []consensus.Equivocation{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, faults []consensus.Equivocation) error {
+			sw := NewSilentWriter(w)
+			if len(faults) == 0 {
+				sw.Println("no equivocations detected")
+				return sw.Error()
+			}
+			for _, fault := range faults {
+				blocks := make([]string, len(fault.Blocks))
+				for i, c := range fault.Blocks {
+					blocks[i] = c.String()
+				}
+				sw.Printf("miner %s at height %d: %s\n", fault.Miner, fault.Height, strings.Join(blocks, ", "))
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+// ChainOrphan is a validated block that is not an ancestor of the head.
+type ChainOrphan struct {
+	Cid    cid.Cid
+	Height uint64
+	Miner  address.Address
+}
+
+var storeOrphansCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List validated blocks that are not on the chain of the head",
+		ShortDescription: `Lists the blocks the node has validated that are not ancestors of the current
+head, such as the blocks of a fork abandoned in a reorg, lowest first. Only
+blocks validated since the node started are known; the chain is reloaded from
+the head on startup.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		blocks, err := GetPorcelainAPI(env).ChainOrphans(req.Context)
+		if err != nil {
+			return err
+		}
+		orphans := make([]ChainOrphan, len(blocks))
+		for i, blk := range blocks {
+			orphans[i] = ChainOrphan{
+				Cid:    blk.Cid(),
+				Height: uint64(blk.Height),
+				Miner:  blk.Miner,
+			}
+		}
+		return re.Emit(orphans)
+	},
+	Type: []ChainOrphan{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, orphans []ChainOrphan) error {
+			sw := NewSilentWriter(w)
+			if len(orphans) == 0 {
+				sw.Println("no orphaned blocks")
+				return sw.Error()
+			}
+			for _, orphan := range orphans {
+				sw.Printf("%d\t%s\t%s\n", orphan.Height, orphan.Cid, orphan.Miner)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var storeBlockRewardCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the block reward paid for mining a block at a height",
+		ShortDescription: `Computes the FIL the emission schedule pays to the owner of the miner of a
+block at --at, which defaults to the height of the next block. Gas paid by the
+block's messages is not included.`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("at", "Height of the block, defaults to the height after the chain head"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		api := GetPorcelainAPI(env)
+		height, ok := req.Options["at"].(uint64)
+		if !ok {
+			head, err := api.ChainTipSet(api.ChainHeadKey())
+			if err != nil {
+				return err
+			}
+			headHeight, err := head.Height()
+			if err != nil {
+				return err
+			}
+			height = headHeight + 1
+		}
+		return re.Emit(api.ChainBlockReward(height))
+	},
+	Type: types.AttoFIL{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, reward types.AttoFIL) error {
+			return PrintString(w, reward)
+		}),
+	},
+}
+
 var storeStatusCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Show status of chain sync operation.",
@@ -180,10 +570,16 @@ var storeSyncCmd = &cmds.Command{
 var storeExportCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Export the chain store to a car file.",
+		ShortDescription: `
+Writes the chain from the given tipset, or from the head when no cids are
+given, back to and including the genesis block and its state to a car file.
+The file can be loaded with go-filecoin chain import, or into a new repo with
+go-filecoin init --genesisfile.
+`,
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("file", true, false, "File to export chain data to."),
-		cmdkit.StringArg("cids", true, true, "CID's of the blocks of the tipset to export from."),
+		cmdkit.StringArg("cids", false, true, "CID's of the blocks of the tipset to export from, defaults to the head."),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		f, err := os.Create(req.Arguments[0])
@@ -197,6 +593,9 @@ var storeExportCmd = &cmds.Command{
 			return err
 		}
 		expKey := block.NewTipSetKey(expCids...)
+		if expKey.Empty() {
+			expKey = GetPorcelainAPI(env).ChainHeadKey()
+		}
 
 		if err := GetPorcelainAPI(env).ChainExport(req.Context, expKey, f); err != nil {
 			return err
@@ -208,6 +607,13 @@ var storeExportCmd = &cmds.Command{
 var storeImportCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Import the chain from a car file.",
+		ShortDescription: `
+Loads the blocks of a car file written by go-filecoin chain export into the
+chain store and prints the key of its head. The file is refused unless its
+head connects back to a genesis block, and it holds the messages and receipts
+of every block and the state of the genesis block. The head is not changed;
+use go-filecoin chain sync to validate the imported chain and move to it.
+`,
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.FileArg("file", true, false, "File to import chain data from.").EnableStdin(),
@@ -1,19 +1,25 @@
 package commands_test
 
 import (
-	"bytes"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
 	"github.com/filecoin-project/go-filecoin/fixtures"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 func TestChainHead(t *testing.T) {
@@ -24,16 +30,32 @@ func TestChainHead(t *testing.T) {
 
 	jsonResult := d.RunSuccess("chain", "head", "--enc", "json").ReadStdoutTrimNewlines()
 
-	var cidsFromJSON []cid.Cid
-	err := json.Unmarshal([]byte(jsonResult), &cidsFromJSON)
+	var head commands.ChainHeadResult
+	err := json.Unmarshal([]byte(jsonResult), &head)
 	assert.NoError(t, err)
+	assert.Equal(t, uint64(0), head.Height)
+	require.Len(t, head.Blocks, 1)
+	assert.Equal(t, head.Key.ToSlice()[0], head.Blocks[0].Cid())
 
 	textResult := d.RunSuccess("chain", "ls", "--enc", "text").ReadStdoutTrimNewlines()
 
 	textCid, err := cid.Decode(textResult)
 	require.NoError(t, err)
 
-	assert.Equal(t, textCid, cidsFromJSON[0])
+	assert.Equal(t, textCid, head.Key.ToSlice()[0])
+	assert.Equal(t, textResult, d.RunSuccess("chain", "head").ReadStdoutTrimNewlines())
+
+	t.Run("follows the head as blocks are mined", func(t *testing.T) {
+		miner := makeTestDaemonWithMinerAndStart(t)
+		defer miner.ShutdownSuccess()
+
+		miner.RunSuccess("mining", "once")
+		ts := miner.ChainHead()
+		height, err := ts.Height()
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), height)
+		assert.Equal(t, ts.Key(), miner.HeadKey())
+	})
 }
 
 func TestChainLs(t *testing.T) {
@@ -47,15 +69,9 @@ func TestChainLs(t *testing.T) {
 		c, err := cid.Parse(result1)
 		require.NoError(t, err)
 
-		op2 := d.RunSuccess("chain", "ls", "--enc", "json")
-		result2 := op2.ReadStdoutTrimNewlines()
-
 		var bs [][]block.Block
-		for _, line := range bytes.Split([]byte(result2), []byte{'\n'}) {
-			var b []block.Block
-			err := json.Unmarshal(line, &b)
-			require.NoError(t, err)
-			bs = append(bs, b)
+		th.RunSuccessJSON(d, &bs, "chain", "ls")
+		for _, b := range bs {
 			require.Equal(t, 1, len(b))
 		}
 
@@ -68,14 +84,51 @@ func TestChainLs(t *testing.T) {
 		d := th.NewDaemon(t).Start()
 		defer d.ShutdownSuccess()
 
-		op := d.RunSuccess("chain", "ls", "--enc", "json")
-		result := op.ReadStdoutTrimNewlines()
-
 		var b []block.Block
-		err := json.Unmarshal([]byte(result), &b)
-		require.NoError(t, err)
+		th.RunSuccessJSON(d, &b, "chain", "ls")
 
 		assert.True(t, b[0].Parents.Empty())
+
+		// The single tipset can also be decoded as the only one of the chain.
+		var bs [][]block.Block
+		th.RunSuccessJSON(d, &bs, "chain", "ls")
+		require.Len(t, bs, 1)
+		assert.Equal(t, b, bs[0])
+	})
+
+	t.Run("chain ls pages through the chain with --limit and --from", func(t *testing.T) {
+		d := makeTestDaemonWithMinerAndStart(t)
+		defer d.ShutdownSuccess()
+		for i := 0; i < 4; i++ {
+			d.RunSuccess("mining", "once")
+		}
+
+		head := d.GetChainHeadN(2)
+		require.Len(t, head, 2)
+		assert.Equal(t, uint64(4), uint64(head[0][0].Height))
+		assert.Equal(t, uint64(3), uint64(head[1][0].Height))
+
+		// Each page starts at the parents of the last tipset of the previous one.
+		fromParents := func(tipset []block.Block) string {
+			var cids []string
+			for _, c := range tipset[0].Parents.ToSlice() {
+				cids = append(cids, c.String())
+			}
+			return strings.Join(cids, ",")
+		}
+
+		var page [][]block.Block
+		th.RunSuccessJSON(d, &page, "chain", "ls", "--limit", "2", "--from", fromParents(head[1]))
+		require.Len(t, page, 2)
+		assert.Equal(t, uint64(2), uint64(page[0][0].Height))
+		assert.Equal(t, uint64(1), uint64(page[1][0].Height))
+
+		var rest [][]block.Block
+		th.RunSuccessJSON(d, &rest, "chain", "ls", "--from", fromParents(page[1]))
+		require.Len(t, rest, 1)
+		assert.True(t, rest[0][0].Parents.Empty())
+
+		d.RunFail("not a list of block cids", "chain", "ls", "--from", "2")
 	})
 
 	t.Run("chain ls with text encoding returns only CIDs", func(t *testing.T) {
@@ -120,4 +173,345 @@ func TestChainLs(t *testing.T) {
 		assert.Contains(t, chainLsResult, `"height":"0"`)
 		assert.Contains(t, chainLsResult, `"height":"1"`)
 	})
+
+	t.Run("chain ls streams one CID per line", func(t *testing.T) {
+		daemon := makeTestDaemonWithMinerAndStart(t)
+		defer daemon.ShutdownSuccess()
+
+		newBlockCid := daemon.RunSuccess("mining", "once", "--enc", "text").ReadStdoutTrimNewlines()
+
+		var lines []string
+		out := daemon.RunStreaming(func(line string) {
+			lines = append(lines, line)
+		}, "chain", "ls").AssertSuccess()
+
+		require.Len(t, lines, 2)
+		assert.Equal(t, newBlockCid, lines[0])
+		assert.Equal(t, out.ReadStdoutTrimNewlines(), strings.Join(lines, "\n"))
+	})
+}
+
+func TestChainReceipts(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	send := func() cid.Cid {
+		out := d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1",
+			"--gas-limit", "300",
+			"--value", "10",
+			fixtures.TestAddresses[1],
+		)
+		c, err := cid.Parse(out.ReadStdoutTrimNewlines())
+		require.NoError(t, err)
+		return c
+	}
+
+	// two messages at height 1, one at height 2
+	firstBlockMsgs := []cid.Cid{send(), send()}
+	d.RunSuccess("mining", "once")
+	secondBlockMsg := send()
+	d.RunSuccess("mining", "once")
+
+	rows := d.Receipts(0, 2)
+	require.Len(t, rows, 3)
+
+	assert.Equal(t, uint64(2), rows[0].Height)
+	assert.True(t, secondBlockMsg.Equals(rows[0].MessageCid))
+
+	var heightOneCids []cid.Cid
+	for _, row := range rows[1:] {
+		assert.Equal(t, uint64(1), row.Height)
+		heightOneCids = append(heightOneCids, row.MessageCid)
+	}
+	assert.ElementsMatch(t, firstBlockMsgs, heightOneCids)
+
+	for _, row := range rows {
+		assert.Equal(t, uint8(0), row.ExitCode)
+	}
+
+	t.Run("range excludes tipsets outside it", func(t *testing.T) {
+		rows := d.Receipts(2, 2)
+		require.Len(t, rows, 1)
+		assert.True(t, secondBlockMsg.Equals(rows[0].MessageCid))
+
+		assert.Empty(t, d.Receipts(0, 0))
+	})
+
+	t.Run("rejects an inverted range", func(t *testing.T) {
+		d.RunFail("below from-height", "chain", "receipts", "--from-height", "2", "--to-height", "1")
+	})
+}
+
+func TestChainBlock(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	out := d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1",
+		"--gas-limit", "300",
+		fixtures.TestAddresses[1],
+	)
+	msgCid, err := cid.Parse(out.ReadStdoutTrimNewlines())
+	require.NoError(t, err)
+	d.RunSuccess("mining", "once")
+
+	head := d.GetChainHead()
+	require.Len(t, head, 1)
+
+	blk := d.GetBlock(head[0].Cid())
+	assert.True(t, head[0].Cid().Equals(blk.Header.Cid()))
+	require.Len(t, blk.Messages, 1)
+	got, err := blk.Messages[0].Cid()
+	require.NoError(t, err)
+	assert.True(t, msgCid.Equals(got))
+	require.Len(t, blk.Receipts, 1)
+	assert.Equal(t, uint8(0), blk.Receipts[0].ExitCode)
+
+	t.Run("unknown block", func(t *testing.T) {
+		d.RunFail("block not found", "chain", "block", types.NewCidForTestGetter()().String())
+	})
+}
+
+func TestChainCheckWeight(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	for i := 0; i < 5; i++ {
+		d.RunSuccess("mining", "once")
+	}
+
+	assert.NoError(t, d.CheckWeightMonotonic(0, 5))
+	assert.NoError(t, d.CheckWeightMonotonic(3, 4))
+
+	d.RunFail("to-height 1 is below from-height 2", "chain", "check-weight", "--from-height", "2", "--to-height", "1")
+}
+
+func TestChainBlockCid(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunSuccess("mining", "once")
+	head := d.GetChainHead()[0]
+
+	assert.Equal(t, head.Cid(), d.ComputeBlockCid(head))
+
+	t.Run("changing a field changes the cid", func(t *testing.T) {
+		altered := head
+		altered.Timestamp++
+		assert.NotEqual(t, head.Cid(), d.ComputeBlockCid(altered))
+	})
+
+	t.Run("rejects input that is not a header", func(t *testing.T) {
+		d.RunWithStdin(strings.NewReader("not json"), "chain", "block-cid").AssertFail("could not decode block header")
+	})
+}
+
+func TestChainFaults(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// Two daemons mining for the same miner produce conflicting blocks at
+	// height 1 while they are disconnected.
+	d1 := makeTestDaemonWithMinerAndStart(t)
+	defer d1.ShutdownSuccess()
+	d2 := makeTestDaemonWithMinerAndStart(t)
+	defer d2.ShutdownSuccess()
+
+	assert.Empty(t, d2.ChainFaults())
+
+	// A message in one of the blocks makes sure they differ.
+	d1.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value", "10",
+		fixtures.TestAddresses[1],
+	)
+	d1.RunSuccess("mining", "once")
+	d2.RunSuccess("mining", "once")
+	blk1 := d1.GetChainHead()[0]
+	blk2 := d2.GetChainHead()[0]
+	require.Equal(t, blk1.Height, blk2.Height)
+	require.NotEqual(t, blk1.Cid(), blk2.Cid())
+
+	// Syncing d1's chain shows d2 the other block at height 1.
+	d1.ConnectSuccess(d2)
+	d1.MineAndPropagate(10*time.Second, d2)
+
+	faults := d2.ChainFaults()
+	require.Len(t, faults, 1)
+	assert.Equal(t, fixtures.TestMiners[0], faults[0].Miner.String())
+	assert.Equal(t, uint64(blk1.Height), faults[0].Height)
+	assert.ElementsMatch(t, []cid.Cid{blk1.Cid(), blk2.Cid()}, faults[0].Blocks)
+
+	out := d2.RunSuccess("chain", "faults").ReadStdout()
+	assert.Contains(t, out, blk1.Cid().String())
+	assert.Contains(t, out, blk2.Cid().String())
+}
+
+func TestChainOrphans(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// Two daemons mining for the same miner while disconnected each build
+	// their own block at height 1.
+	d1 := makeTestDaemonWithMinerAndStart(t)
+	defer d1.ShutdownSuccess()
+	d2 := makeTestDaemonWithMinerAndStart(t)
+	defer d2.ShutdownSuccess()
+
+	d1.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value", "10",
+		fixtures.TestAddresses[1],
+	)
+	d1.RunSuccess("mining", "once")
+	d2.RunSuccess("mining", "once")
+	abandoned := d2.GetChainHead()[0]
+	require.NotEqual(t, d1.GetChainHead()[0].Cid(), abandoned.Cid())
+	assert.Empty(t, d2.ChainOrphans())
+
+	// d2 reorgs onto d1's longer chain, abandoning its own block.
+	d1.ConnectSuccess(d2)
+	d1.MineAndPropagate(10*time.Second, d2)
+	require.Equal(t, d1.HeadKey(), d2.HeadKey())
+
+	orphans := d2.ChainOrphans()
+	require.Len(t, orphans, 1)
+	assert.Equal(t, abandoned.Cid(), orphans[0].Cid)
+	assert.Equal(t, uint64(abandoned.Height), orphans[0].Height)
+	assert.Equal(t, fixtures.TestMiners[0], orphans[0].Miner.String())
+
+	out := d2.RunSuccess("chain", "orphans").ReadStdout()
+	assert.Contains(t, out, abandoned.Cid().String())
+}
+
+func TestChainBlockReward(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	// The genesis block is not mined, every later block pays 1000 FIL.
+	assert.Equal(t, types.ZeroAttoFIL.String(), d.BlockReward(0).String())
+	for _, height := range []uint64{1, 2, 3, 1000} {
+		assert.Equal(t, types.NewAttoFILFromFIL(1000).String(), d.BlockReward(height).String(), "height %d", height)
+	}
+
+	// Without --at the reward is for the next block.
+	out := d.RunSuccess("chain", "block-reward").ReadStdoutTrimNewlines()
+	assert.Equal(t, d.BlockReward(1).String(), out)
+
+	// Mining an empty block pays exactly the block reward to the miner's owner.
+	owner, err := address.NewFromString(fixtures.TestAddresses[0])
+	require.NoError(t, err)
+	before := queryBalance(t, d, owner)
+	d.RunSuccess("mining", "once")
+	after := queryBalance(t, d, owner)
+	assert.Equal(t, d.BlockReward(1).String(), after.Sub(before).String())
+}
+
+func TestChainExportImport(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miner := makeTestDaemonWithMinerAndStart(t)
+	defer miner.ShutdownSuccess()
+	miner.RunSuccess("mining", "once")
+	miner.RunSuccess("mining", "once")
+	head := miner.HeadKey()
+
+	f, err := ioutil.TempFile("", "chain.*.car")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	defer func() { _ = os.Remove(f.Name()) }()
+	miner.ChainExport(f.Name())
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	t.Run("chain import loads the exported chain", func(t *testing.T) {
+		var imported block.TipSetKey
+		th.RunSuccessJSON(d, &imported, "chain", "import", f.Name())
+		assert.True(t, head.Equals(imported))
+	})
+
+	t.Run("init imports and syncs to the exported chain", func(t *testing.T) {
+		d2 := th.NewDaemon(t, th.ImportChain(f.Name())).Start()
+		defer d2.ShutdownSuccess()
+		assert.True(t, head.Equals(d2.HeadKey()))
+	})
+}
+
+func TestClusterConsensus(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("synced nodes agree", func(t *testing.T) {
+		miner := makeTestDaemonWithMinerAndStart(t)
+		defer miner.ShutdownSuccess()
+		d2 := th.NewDaemon(t).Start()
+		defer d2.ShutdownSuccess()
+		d3 := th.NewDaemon(t).Start()
+		defer d3.ShutdownSuccess()
+
+		miner.ConnectSuccess(d2)
+		miner.ConnectSuccess(d3)
+		miner.MineAndPropagate(10*time.Second, d2, d3)
+
+		agreed, heads := miner.ClusterConsensus([]*th.TestDaemon{d2, d3})
+		assert.True(t, agreed)
+		require.Len(t, heads, 3)
+		for _, head := range heads {
+			assert.Equal(t, miner.HeadKey(), head)
+		}
+	})
+
+	t.Run("partitioned nodes disagree", func(t *testing.T) {
+		miner := makeTestDaemonWithMinerAndStart(t)
+		defer miner.ShutdownSuccess()
+		d2 := th.NewDaemon(t).Start()
+		defer d2.ShutdownSuccess()
+
+		// The nodes are not connected, so d2 never sees the new block.
+		miner.RunSuccess("mining", "once")
+
+		agreed, heads := miner.ClusterConsensus([]*th.TestDaemon{d2})
+		assert.False(t, agreed)
+		require.Len(t, heads, 2)
+		assert.Equal(t, miner.HeadKey(), heads[miner.GetID()])
+		assert.Equal(t, d2.HeadKey(), heads[d2.GetID()])
+		assert.False(t, heads[miner.GetID()].Equals(heads[d2.GetID()]))
+	})
+}
+
+func TestInduceReorg(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := makeTestDaemonWithMinerAndStart(t)
+	defer d1.ShutdownSuccess()
+	d2 := makeTestDaemonWithMinerAndStart(t)
+	defer d2.ShutdownSuccess()
+	d1.ConnectSuccess(d2)
+
+	base := d1.ChainHead()
+	baseHeight, err := base.Height()
+	require.NoError(t, err)
+
+	// d2 mines the longer, heavier fork, which d1 reorgs onto.
+	head := th.InduceReorg(d1, d2, 1, 3)
+
+	height, err := head.Height()
+	require.NoError(t, err)
+	assert.Equal(t, baseHeight+3, height)
+	assert.Equal(t, head.Key(), d1.HeadKey())
+	assert.Equal(t, head.Key(), d2.HeadKey())
+	assert.Len(t, d1.ChainOrphans(), 1)
 }
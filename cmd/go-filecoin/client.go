@@ -1,17 +1,23 @@
 package commands
 
 import (
-	"errors"
+	"bytes"
+	"context"
 	"fmt"
 	"io"
+	"io/ioutil"
 	"strconv"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
 	files "github.com/ipfs/go-ipfs-files"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/retrieval"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
@@ -22,12 +28,19 @@ var clientCmd = &cmds.Command{
 		Tagline: "Make deals, store data, retrieve data",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"audit-deal":           clientAuditDealCmd,
 		"cat":                  clientCatCmd,
+		"deal-lifetime":        clientDealLifetimeCmd,
+		"deal-log":             clientDealLogCmd,
+		"find-miners":          clientFindMinersCmd,
 		"import":               clientImportDataCmd,
 		"propose-storage-deal": clientProposeStorageDealCmd,
 		"query-storage-deal":   clientQueryStorageDealCmd,
+		"renew-deal":           clientRenewDealCmd,
 		"verify-storage-deal":  clientVerifyStorageDealCmd,
+		"wait-deal":            clientWaitDealCmd,
 		"list-asks":            clientListAsksCmd,
+		"list-deals":           clientListDealsCmd,
 		"payments":             paymentsCmd,
 	},
 }
@@ -64,8 +77,8 @@ var clientImportDataCmd = &cmds.Command{
 		Tagline: "Import data into the local node",
 		ShortDescription: `
 Imports data previously exported with the client cat command into the storage
-market. This command takes only one argument, the path of the file to import.
-See the go-filecoin client cat command for more details.
+market. The data is read from the file at the given path, or from stdin if no
+path is given. See the go-filecoin client cat command for more details.
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -113,6 +126,12 @@ Duration should be specified with the number of blocks for which to store the
 data. New blocks are generated about every 30 seconds, so the time given should
 be represented as a count of 30 second intervals. For example, 1 minute would
 be 2, 1 hour would be 120, and 1 day would be 2880.
+
+With --verbose the transcript of the negotiation with the miner is printed as
+well, also when the deal is not made. Transcripts are kept and can be shown
+again with:
+
+$ go-filecoin client deal-log <id>
 `,
 	},
 	Arguments: []cmdkit.Argument{
@@ -123,9 +142,11 @@ be 2, 1 hour would be 120, and 1 day would be 2880.
 	},
 	Options: []cmdkit.Option{
 		cmdkit.BoolOption("allow-duplicates", "Allows duplicate proposals to be created. Unless this flag is set, you will not be able to make more than one deal per piece per miner. This protection exists to prevent erroneous duplicate deals."),
+		cmdkit.BoolOption("verbose", "Print the transcript of the negotiation with the miner"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		allowDuplicates, _ := req.Options["allow-duplicates"].(bool)
+		verbose, _ := req.Options["verbose"].(bool)
 
 		miner, err := address.NewFromString(req.Arguments[0])
 		if err != nil {
@@ -148,6 +169,125 @@ be 2, 1 hour would be 120, and 1 day would be 2880.
 		}
 
 		resp, err := GetStorageAPI(env).ProposeStorageDeal(req.Context, data, miner, askid, duration, allowDuplicates)
+		if err != nil {
+			negErr, ok := err.(*storage.NegotiationError)
+			if !verbose || !ok {
+				return err
+			}
+			transcript, tErr := GetPorcelainAPI(env).DealTranscriptGet(negErr.ProposalCid)
+			if tErr != nil {
+				return err
+			}
+			var buf bytes.Buffer
+			sw := NewSilentWriter(&buf)
+			writeTranscript(sw, transcript)
+			return fmt.Errorf("%s\n%s", err, buf.String())
+		}
+
+		result := ProposeStorageDealResult{Response: resp.Response}
+		if verbose {
+			result.Transcript, err = GetPorcelainAPI(env).DealTranscriptGet(resp.ProposalCid)
+			if err != nil {
+				return err
+			}
+		}
+		return re.Emit(&result)
+	},
+	Type: ProposeStorageDealResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, result *ProposeStorageDealResult) error {
+			sw := NewSilentWriter(w)
+			if result.Transcript != nil {
+				writeTranscript(sw, result.Transcript)
+				sw.Println()
+			}
+			sw.Printf("State:   %s\n", result.State.String())
+			sw.Printf("Message: %s\n", result.Message)
+			sw.Printf("DealID:  %s\n", result.ProposalCid.String())
+			return sw.Error()
+		}),
+	},
+}
+
+// ProposeStorageDealResult is the output of the propose-storage-deal command.
+// Transcript is only set with --verbose.
+type ProposeStorageDealResult struct {
+	storagedeal.Response
+	Transcript *storagedeal.Transcript `json:",omitempty"`
+}
+
+var clientDealLogCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the negotiation transcript of a storage deal proposal",
+		ShortDescription: `
+Prints the steps this node took while proposing the deal with the given id and
+what the miner replied: the signed proposal, the miner's response and whether
+its signature verified. Transcripts are kept for proposals the miner rejected
+too, whose ids are reported in the error of propose-storage-deal.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "CID of the deal proposal"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		proposalCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		transcript, err := GetPorcelainAPI(env).DealTranscriptGet(proposalCid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(transcript)
+	},
+	Type: storagedeal.Transcript{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, transcript *storagedeal.Transcript) error {
+			sw := NewSilentWriter(w)
+			writeTranscript(sw, transcript)
+			return sw.Error()
+		}),
+	},
+}
+
+func writeTranscript(sw *SilentWriter, transcript *storagedeal.Transcript) {
+	sw.Printf("Proposal %s to %s\n", transcript.ProposalCid, transcript.Miner)
+	for i, entry := range transcript.Entries {
+		sw.Printf("%2d. %s: %s\n", i+1, entry.Step, entry.Detail)
+	}
+}
+
+var clientRenewDealCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Extend a storage deal with the same miner",
+		ShortDescription: `
+Proposes a new deal to the miner of the deal with the given id for the same
+data, covering the --extend blocks after the original deal ends, at the
+original price per byte and block. The miner keeps the data it already holds,
+so it is neither transferred nor sealed again, and only deals whose data is
+sealed can be renewed. If the miner rejects the renewal its reason is returned
+as an error. Prints the id of the renewal deal.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "CID of the deal to renew"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("extend", "Number of blocks to extend the deal by"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		proposalCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		extend, ok := req.Options["extend"].(uint64)
+		if !ok {
+			return errors.New("must specify the number of blocks with --extend")
+		}
+
+		resp, err := GetStorageAPI(env).RenewStorageDeal(req.Context, proposalCid, extend)
 		if err != nil {
 			return err
 		}
@@ -200,6 +340,62 @@ format is specified with the --enc flag.
 	},
 }
 
+// dealPollInterval is how often client wait-deal queries the miner.
+const dealPollInterval = time.Second
+
+var clientWaitDealCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Wait for a storage deal to reach a state",
+		ShortDescription: `
+Queries the miner of the storage deal proposal specified by the id until the
+deal reaches the given state or a later one; a deal that is complete has been
+sealed. Fails as soon as the deal is rejected or fails, or when the timeout
+passes.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "CID of deal to wait for"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("state", "State to wait for: accepted, started, staged or complete, also called sealed").WithDefault(storagedeal.Complete.String()),
+		cmdkit.StringOption("timeout", "Maximum time to wait for the deal. e.g., 300ms, 1.5h, 2h45m.").WithDefault("10m"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		propcid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		target, err := storagedeal.ParseState(req.Options["state"].(string))
+		if err != nil {
+			return err
+		}
+
+		timeoutDuration, err := time.ParseDuration(req.Options["timeout"].(string))
+		if err != nil {
+			return errors.Wrap(err, "Invalid timeout string")
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context, timeoutDuration)
+		defer cancel()
+
+		resp, err := GetStorageAPI(env).WaitForStorageDeal(ctx, propcid, target, dealPollInterval)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(resp)
+	},
+	Type: storagedeal.SignedResponse{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, resp *storagedeal.SignedResponse) error {
+			fmt.Fprintf(w, "Status: %s\n", resp.State.String()) // nolint: errcheck
+			fmt.Fprintf(w, "Message: %s\n", resp.Message)       // nolint: errcheck
+			return nil
+		}),
+	},
+}
+
 // VerifyStorageDealResult wraps the success in an interface type
 type VerifyStorageDealResult struct {
 	validPip bool
@@ -238,6 +434,142 @@ is invalid.  Returns nil otherwise.
 	Type: &VerifyStorageDealResult{},
 }
 
+var clientDealLifetimeCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show how long until a storage deal expires",
+		ShortDescription: `
+Reports the height at which the deal with the given id was proposed, its
+duration, the height at which it ends and the number of blocks remaining
+relative to the current chain head. Once the end height is reached the deal
+is reported as expired with zero blocks remaining.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "CID of the deal"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		proposalCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		lifetime, err := GetPorcelainAPI(env).ClientDealLifetime(req.Context, proposalCid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(lifetime)
+	},
+	Type: porcelain.DealLifetime{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, lifetime *porcelain.DealLifetime) error {
+			state := lifetime.State.String()
+			if lifetime.Expired {
+				state = "expired"
+			}
+			sw := NewSilentWriter(w)
+			sw.Printf("Start height: %d\n", lifetime.StartHeight)
+			sw.Printf("Duration:     %d\n", lifetime.Duration)
+			sw.Printf("End height:   %d\n", lifetime.EndHeight)
+			sw.Printf("Remaining:    %d\n", lifetime.Remaining)
+			sw.Printf("State:        %s\n", state)
+			return sw.Error()
+		}),
+	},
+}
+
+var clientAuditDealCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Check that a miner still holds the data of a storage deal",
+		ShortDescription: `
+Challenges the miner of the deal with the given id to hash a random range of
+the deal's piece together with a random nonce, and compares the result with
+the same digest computed from this node's copy of the piece. The audit passes
+if they match. It fails if the miner cannot read the piece or answers with a
+different digest. Unlike the PoSts miners submit on chain, an audit can be run
+at any time.
+
+The data of the deal must still be available on this node.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("id", true, false, "CID of the deal"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		proposalCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		deal, err := GetPorcelainAPI(env).DealGet(req.Context, proposalCid)
+		if err != nil {
+			return err
+		}
+
+		r, err := GetPorcelainAPI(env).DAGCat(req.Context, deal.Proposal.PieceRef)
+		if err != nil {
+			return errors.Wrap(err, "failed to read the deal's data")
+		}
+		data, err := ioutil.ReadAll(r)
+		if err != nil {
+			return errors.Wrap(err, "failed to read the deal's data")
+		}
+
+		mpid, err := GetPorcelainAPI(env).MinerGetPeerID(req.Context, deal.Miner)
+		if err != nil {
+			return err
+		}
+
+		result, err := GetRetrievalAPI(env).AuditPiece(req.Context, deal.Proposal.PieceRef, mpid, data)
+		if err != nil {
+			return err
+		}
+		return re.Emit(result)
+	},
+	Type: retrieval.AuditResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, result *retrieval.AuditResult) error {
+			if !result.Passed {
+				_, err := fmt.Fprintf(w, "failed: %s\n", result.Reason)
+				return err
+			}
+			_, err := fmt.Fprintf(w, "passed: bytes %d to %d match\n", result.Offset, result.Offset+result.Length)
+			return err
+		}),
+	},
+}
+
+var clientListDealsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the storage deals this node proposed",
+		ShortDescription: `
+Lists the storage deals this node proposed as a client to miners other than its
+own, with their proposal cid, state, total price, duration in blocks and miner.
+Rejected and failed deals are only listed when selected with --state.
+`,
+	},
+	Options: []cmdkit.Option{
+		dealStateOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		states, err := parseDealStates(req)
+		if err != nil {
+			return err
+		}
+		ownMiner, err := ownMinerAddress(env)
+		if err != nil {
+			return err
+		}
+
+		deals, err := GetPorcelainAPI(env).ClientListDeals(req.Context, ownMiner, states...)
+		if err != nil {
+			return err
+		}
+		return re.Emit(deals)
+	},
+	Type:     []porcelain.DealRecord{},
+	Encoders: dealRecordsEncoders,
+}
+
 var clientListAsksCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "List all asks in the storage market",
@@ -269,6 +601,53 @@ respectively.
 	},
 }
 
+var clientFindMinersCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List miners to choose from for a storage deal",
+		ShortDescription: `
+Lists the miners with an unexpired ask, most powerful first, along with their
+cheapest ask. Results will be returned as a space separated table with miner,
+power, ask id, price and expiration respectively.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("min-power", "Only list miners with at least this many bytes of power"),
+		cmdkit.StringOption("max-price", "Only list miners asking at most this price in FIL"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var minPower *types.BytesAmount
+		if p, ok := req.Options["min-power"].(uint64); ok {
+			minPower = types.NewBytesAmount(p)
+		}
+
+		var maxPrice *types.AttoFIL
+		if o := req.Options["max-price"]; o != nil {
+			price, ok := types.NewAttoFILFromFILString(o.(string))
+			if !ok {
+				return ErrInvalidPrice
+			}
+			maxPrice = &price
+		}
+
+		candidates, err := GetPorcelainAPI(env).ClientFindMiners(req.Context, minPower, maxPrice)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(candidates)
+	},
+	Type: []porcelain.MinerCandidate{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, candidates []porcelain.MinerCandidate) error {
+			sw := NewSilentWriter(w)
+			for _, c := range candidates {
+				sw.Printf("%s %s %.3d %s %s\n", c.Miner, c.Power, c.AskID, c.Price, c.Expiry)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
 var paymentsCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline:          "List payments for a given deal",
@@ -3,7 +3,10 @@ package commands_test
 import (
 	"bytes"
 	"context"
+	"io/ioutil"
 	"math/big"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -16,6 +19,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/filecoin-project/go-filecoin/tools/fast"
 	"github.com/filecoin-project/go-filecoin/tools/fast/fastesting"
@@ -36,6 +40,83 @@ func TestListAsks(t *testing.T) {
 	assert.Equal(t, fixtures.TestMiners[0]+" 000 20 11", listAsksOutput)
 }
 
+func TestClientImportFile(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	dir, err := ioutil.TempDir("", "client-import")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+
+	data := bytes.Repeat([]byte("HODLHODLHODL"), 100000)
+	path := filepath.Join(dir, "data")
+	require.NoError(t, ioutil.WriteFile(path, data, 0644))
+
+	t.Run("imports the same data as stdin", func(t *testing.T) {
+		fileCid := d.ImportFile(path)
+		stdinCid := d.RunWithStdin(bytes.NewReader(data), "client", "import").ReadStdoutTrimNewlines()
+		assert.Equal(t, stdinCid, fileCid)
+
+		out := d.RunSuccess("client", "cat", fileCid)
+		assert.Equal(t, string(data), out.ReadStdout())
+	})
+
+	t.Run("fails for a missing file", func(t *testing.T) {
+		d.RunFail("no such file or directory", "client", "import", filepath.Join(dir, "missing"))
+	})
+}
+
+func TestClientWaitDeal(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	unknown := types.CidFromString(t, "unknown deal").String()
+	d.RunFail("unknown deal state", "client", "wait-deal", unknown, "--state", "sealing")
+	d.RunFail("failed to fetch deal", "client", "wait-deal", unknown, "--state", "accepted")
+
+	// sealed is accepted as an alias for complete.
+	d.RunFail("failed to fetch deal", "client", "wait-deal", unknown, "--state", "sealed")
+}
+
+func TestClientWaitDealState(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	minerDaemon := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+		th.AutoSealInterval("1"),
+	).Start()
+	defer minerDaemon.ShutdownSuccess()
+
+	clientDaemon := th.NewDaemon(t,
+		th.KeyFile(fixtures.KeyFilePaths()[1]),
+		th.DefaultAddress(fixtures.TestAddresses[1]),
+	).Start()
+	defer clientDaemon.ShutdownSuccess()
+
+	minerDaemon.RunSuccess("mining", "start")
+	minerDaemon.UpdatePeerID()
+	minerDaemon.ConnectSuccess(clientDaemon)
+
+	addAskCid := minerDaemon.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "10")
+	clientDaemon.WaitForMessageRequireSuccess(addAskCid)
+	dataCid := clientDaemon.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+
+	proposeDealOutput := clientDaemon.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "5").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(proposeDealOutput, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	clientDaemon.WaitDealState(dealCid, "staged")
+	clientDaemon.WaitDealState(dealCid, "accepted")
+	clientDaemon.WaitDealState(dealCid, "sealed")
+}
+
 func TestStorageDealsAfterRestart(t *testing.T) {
 	t.Skip("Long term solution: #3642")
 	tf.IntegrationTest(t)
@@ -319,3 +400,315 @@ func TestSelfDialStorageGoodError(t *testing.T) {
 	assert.Error(t, err)
 	fastesting.AssertStdErrContains(t, miningNode, "attempting to make storage deal with self")
 }
+
+func TestDealLifetime(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	proposeDeal := func(data, duration string) string {
+		dataCid := client.RunWithStdin(strings.NewReader(data), "client", "import").ReadStdoutTrimNewlines()
+		out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", duration).ReadStdoutTrimNewlines()
+		splitOnSpace := strings.Split(out, " ")
+		return splitOnSpace[len(splitOnSpace)-1]
+	}
+	longDeal := proposeDeal("HODLHODLHODL", "1000")
+	shortDeal := proposeDeal("FLEETINGDATA", "2")
+
+	// Mine at a controlled pace from here on.
+	miner.RunSuccess("mining stop")
+	miner.MustHaveChainHeadBy(10*time.Second, []*th.TestDaemon{client})
+
+	before := client.DealLifetime(longDeal)
+	assert.Equal(t, uint64(1000), before.Duration)
+	assert.Equal(t, before.StartHeight+1000, before.EndHeight)
+	assert.False(t, before.Expired)
+
+	for i := 0; i < 3; i++ {
+		miner.MineAndPropagate(10*time.Second, client)
+	}
+
+	after := client.DealLifetime(longDeal)
+	assert.Equal(t, before.Remaining-3, after.Remaining)
+	assert.Equal(t, before.EndHeight, after.EndHeight)
+
+	expired := client.DealLifetime(shortDeal)
+	assert.True(t, expired.Expired)
+	assert.Equal(t, uint64(0), expired.Remaining)
+	assert.Contains(t, client.RunSuccess("client", "deal-lifetime", shortDeal).ReadStdout(), "expired")
+}
+
+func TestRenewDeal(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+	proposeDealOutput := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "1000").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(proposeDealOutput, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	// Only deals whose piece is sealed can be renewed.
+	client.WaitDealState(dealCid, "complete")
+
+	original := client.DealLifetime(dealCid)
+	renewalCid := client.RenewDeal(dealCid, 500)
+	assert.NotEqual(t, dealCid, renewalCid)
+
+	renewal := client.DealLifetime(renewalCid)
+	assert.Equal(t, original.PieceRef, renewal.PieceRef)
+	assert.Equal(t, original.EndHeight+500, renewal.EndHeight)
+
+	client.RunFail("must specify the number of blocks", "client", "renew-deal", dealCid)
+}
+
+func TestClientFindMiners(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miningDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer miningDaemon.ShutdownSuccess()
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.KeyFile(fixtures.KeyFilePaths()[3])).Start()
+	defer d.ShutdownSuccess()
+
+	miningDaemon.ConnectSuccess(d)
+	miningDaemon.RunSuccess("mining", "start")
+
+	// The genesis miner has power, the new miners have none.
+	powerful := fixtures.TestMiners[0]
+	cheap := d.CreateMinerWithSectorSize(fixtures.TestAddresses[2], types.OneKiBSectorSize.Uint64()).String()
+	pricey := d.CreateMinerWithSectorSize(fixtures.TestAddresses[3], types.OneKiBSectorSize.Uint64()).String()
+
+	d.WaitForMessageRequireSuccess(miningDaemon.MinerSetPrice(powerful, fixtures.TestAddresses[0], "30", "1000"))
+	d.WaitForMessageRequireSuccess(d.MinerSetPrice(cheap, fixtures.TestAddresses[2], "10", "1000"))
+	d.WaitForMessageRequireSuccess(d.MinerSetPrice(pricey, fixtures.TestAddresses[3], "20", "1000"))
+
+	minerAddrs := func(candidates []th.MinerCandidate) []string {
+		var addrs []string
+		for _, c := range candidates {
+			addrs = append(addrs, c.Miner.String())
+		}
+		return addrs
+	}
+
+	t.Run("sorted by power and then price", func(t *testing.T) {
+		candidates := d.FindMiners(nil, nil)
+		assert.Equal(t, []string{powerful, cheap, pricey}, minerAddrs(candidates))
+		assert.True(t, candidates[0].Power.GreaterThan(types.ZeroBytes))
+		assert.Equal(t, types.NewAttoFILFromFIL(10), candidates[1].Price)
+	})
+
+	t.Run("filters by power", func(t *testing.T) {
+		candidates := d.FindMiners(types.NewBytesAmount(1), nil)
+		assert.Equal(t, []string{powerful}, minerAddrs(candidates))
+	})
+
+	t.Run("filters by price", func(t *testing.T) {
+		maxPrice := types.NewAttoFILFromFIL(20)
+		candidates := d.FindMiners(nil, &maxPrice)
+		assert.Equal(t, []string{cheap, pricey}, minerAddrs(candidates))
+	})
+
+	t.Run("filters by power and price", func(t *testing.T) {
+		maxPrice := types.NewAttoFILFromFIL(20)
+		assert.Empty(t, d.FindMiners(types.NewBytesAmount(1), &maxPrice))
+	})
+}
+
+func TestAuditDeal(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+	out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "100").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(out, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	miner.RunSuccess("mining", "seal-now")
+	require.NoError(t, th.WaitForIt(300, time.Second, func() (bool, error) {
+		out := client.RunSuccess("client", "query-storage-deal", dealCid).ReadStdout()
+		return strings.Contains(out, "complete"), nil
+	}))
+
+	assert.True(t, client.AuditDeal(dealCid))
+
+	// Once the miner loses the data the audit fails.
+	miner.DropPiece(dataCid)
+	assert.False(t, client.AuditDeal(dealCid))
+	assert.Contains(t, client.RunSuccess("client", "audit-deal", dealCid).ReadStdout(), "failed")
+}
+
+func TestClientDealLog(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	steps := func(transcript *storagedeal.Transcript) []string {
+		var out []string
+		for _, entry := range transcript.Entries {
+			out = append(out, entry.Step)
+		}
+		return out
+	}
+
+	t.Run("accepted deal", func(t *testing.T) {
+		dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+		out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "100", "--verbose").ReadStdout()
+		assert.Contains(t, out, "response signature verified")
+
+		fields := strings.Fields(out)
+		dealCid := fields[len(fields)-1]
+
+		transcript := client.DealLog(dealCid)
+		assert.Equal(t, dealCid, transcript.ProposalCid.String())
+		assert.Equal(t, []string{
+			"proposal signed",
+			"payment created",
+			"proposal sent",
+			"response received",
+			"response signature verified",
+			"deal recorded",
+		}, steps(transcript))
+	})
+
+	t.Run("rejected deal", func(t *testing.T) {
+		// Proposals against the old ask now pay less than the miner asks for.
+		addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "50", "100")
+		client.WaitForMessageRequireSuccess(addAskCid)
+
+		dataCid := client.RunWithStdin(strings.NewReader("HODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+		stderr := client.RunFail("deal rejected", "client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "100", "--verbose").ReadStderr()
+		assert.Contains(t, stderr, "deal not made")
+
+		// The error names the proposal: "proposal <cid>: ..."
+		dealCid := strings.TrimSuffix(strings.Fields(stderr[strings.Index(stderr, "proposal "):])[1], ":")
+		transcript := client.DealLog(dealCid)
+		entries := transcript.Entries
+		require.NotEmpty(t, entries)
+		assert.Equal(t, "deal not made", entries[len(entries)-1].Step)
+		assert.Contains(t, entries[len(entries)-1].Detail, "deal rejected: proposed price")
+		assert.NotContains(t, steps(transcript), "deal recorded")
+	})
+}
+
+func TestListDeals(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	assert.Empty(t, client.ClientListDeals())
+	assert.Empty(t, miner.MinerListDeals())
+
+	dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+	out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "100").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(out, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	clientDeals := client.ClientListDeals()
+	require.Len(t, clientDeals, 1)
+	assert.Equal(t, dealCid, clientDeals[0].ProposalCid.String())
+	assert.Equal(t, uint64(100), clientDeals[0].Duration)
+	assert.Equal(t, fixtures.TestMiners[0], clientDeals[0].Counterparty.String())
+
+	minerDeals := miner.MinerListDeals()
+	require.Len(t, minerDeals, 1)
+	assert.Equal(t, dealCid, minerDeals[0].ProposalCid.String())
+	assert.Equal(t, fixtures.TestAddresses[2], minerDeals[0].Counterparty.String())
+
+	// The deal moves to complete once its sector is sealed.
+	miner.RunSuccess("mining", "seal-now")
+	require.NoError(t, th.WaitForIt(300, time.Second, func() (bool, error) {
+		deals := miner.MinerListDeals("complete")
+		return len(deals) == 1, nil
+	}))
+	assert.Empty(t, miner.MinerListDeals("accepted", "staged"))
+	assert.Empty(t, client.ClientListDeals("failed", "rejected"))
+
+	client.RunFail("unknown deal state", "client", "list-deals", "--state=closed")
+}
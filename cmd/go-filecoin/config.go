@@ -2,11 +2,14 @@ package commands
 
 import (
 	"encoding/json"
+	"fmt"
 	"io"
 	"strings"
 
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/config"
 )
 
 var configCmd = &cmds.Command{
@@ -71,6 +74,9 @@ $ go-filecoin config bootstrap
 }
 `,
 	},
+	Subcommands: map[string]*cmds.Command{
+		"diff": configDiffCmd,
+	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("key", true, false, "The key of the config entry (e.g. \"api.address\")"),
 		cmdkit.StringArg("value", false, false, "Optionally, a value with which to set the config entry"),
@@ -109,3 +115,45 @@ $ go-filecoin config bootstrap
 		}),
 	},
 }
+
+var configDiffCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the config fields that differ from another repo's config",
+		ShortDescription: `
+Compares this node's config file with the config file of the repo at
+<otherRepoDir> and prints each field whose value differs, one per line, as:
+
+KEY: THIS_VALUE -> OTHER_VALUE
+
+Values are printed as JSON. Nothing is printed if the configs are identical.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("otherRepoDir", true, false, "The repo directory holding the config to compare against"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		diffs, err := GetPorcelainAPI(env).ConfigDiff(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+		return re.Emit(diffs)
+	},
+	Type: []config.FieldDiff{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, diffs []config.FieldDiff) error {
+			for _, d := range diffs {
+				value, err := json.Marshal(d.Value)
+				if err != nil {
+					return err
+				}
+				other, err := json.Marshal(d.Other)
+				if err != nil {
+					return err
+				}
+				if _, err := fmt.Fprintf(w, "%s: %s -> %s\n", d.Key, value, other); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
@@ -56,13 +56,13 @@ func TestConfigDaemon(t *testing.T) {
 		d := th.NewDaemon(t).Start()
 		defer d.ShutdownSuccess()
 
-		d.RunSuccess("config", "bootstrap", `{"addresses": ["fake1", "fake2"], "period": "1m", "minPeerThreshold": 0}`)
+		d.RunSuccess("config", "bootstrap", `{"addresses": ["/ip4/127.0.0.1/tcp/6001", "/ip4/127.0.0.1/tcp/6002"], "period": "1m", "minPeerThreshold": 0}`)
 		op1 := d.RunSuccess("config", "bootstrap")
 
 		// validate output
 		jsonOut := op1.ReadStdout()
 		bootstrapConfig := config.NewDefaultConfig().Bootstrap
-		bootstrapConfig.Addresses = []string{"fake1", "fake2"}
+		bootstrapConfig.Addresses = []string{"/ip4/127.0.0.1/tcp/6001", "/ip4/127.0.0.1/tcp/6002"}
 		someJSON, err := json.MarshalIndent(bootstrapConfig, "", "\t")
 		require.NoError(t, err)
 		assert.Equal(t, fmt.Sprintf("%s\n", string(someJSON)), jsonOut)
@@ -71,4 +71,39 @@ func TestConfigDaemon(t *testing.T) {
 		cfg := d.Config()
 		assert.Equal(t, cfg.Bootstrap, bootstrapConfig)
 	})
+
+	t.Run("config set rejects unknown keys and values of the wrong type", func(t *testing.T) {
+		d := th.NewDaemon(t).Start()
+		defer d.ShutdownSuccess()
+
+		d.ConfigSet("bootstrap.minPeerThreshold", "3")
+		assert.Equal(t, "3", d.ConfigGet("bootstrap.minPeerThreshold"))
+		assert.Equal(t, 3, d.Config().Bootstrap.MinPeerThreshold)
+
+		d.RunFail("invalid for config", "config", "bootstrap.nope", "1")
+		d.RunFail("cannot unmarshal", "config", "bootstrap.minPeerThreshold", `"four"`)
+		assert.Equal(t, "3", d.ConfigGet("bootstrap.minPeerThreshold"))
+	})
+}
+
+func TestConfigDiff(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	assert.Empty(t, d1.ConfigDiff(d1))
+
+	// init picks a default address and sector directory per repo, align them
+	cfg1 := d1.Config()
+	d2.RunSuccess("config", "wallet.defaultAddress", cfg1.Wallet.DefaultAddress.String())
+	d2.RunSuccess("config", "sectorbase.rootdir", cfg1.SectorBase.RootDir)
+	assert.Empty(t, d1.ConfigDiff(d2))
+
+	d2.RunSuccess("config", "heartbeat.nickname", "Bob")
+	assert.Equal(t, []string{"heartbeat.nickname"}, d1.ConfigDiff(d2))
+	assert.Equal(t, []string{"heartbeat.nickname"}, d2.ConfigDiff(d1))
 }
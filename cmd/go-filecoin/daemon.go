@@ -25,6 +25,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/journal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 var daemonCmd = &cmds.Command{
@@ -38,6 +39,8 @@ var daemonCmd = &cmds.Command{
 		cmdkit.BoolOption(ELStdout),
 		cmdkit.BoolOption(IsRelay, "advertise and allow filecoin network traffic to be relayed through this node"),
 		cmdkit.StringOption(BlockTime, "time a node waits before trying to mine the next block").WithDefault(consensus.DefaultBlockTime.String()),
+		cmdkit.StringOption(MockMineInterval, "stamp each mined block this long after its parent instead of with the current time, for tests"),
+		cmdkit.StringOption(MockMineWinner, "address of the only miner allowed to mine blocks, which wins every election, for single-node tests"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		return daemonRun(req, re)
@@ -95,6 +98,25 @@ func daemonRun(req *cmds.Request, re cmds.ResponseEmitter) error {
 		return errors.Wrap(err, "Bad block time passed")
 	}
 	opts = append(opts, node.BlockTime(blockTime))
+
+	if intervalStr, ok := req.Options[MockMineInterval].(string); ok && intervalStr != "" {
+		interval, err := time.ParseDuration(intervalStr)
+		if err != nil {
+			return errors.Wrap(err, "Bad mock mine interval passed")
+		}
+		if interval < blockTime {
+			return errors.Errorf("mock mine interval %s is shorter than the block time %s", interval, blockTime)
+		}
+		opts = append(opts, node.MockMineInterval(interval))
+	}
+
+	if winnerStr, ok := req.Options[MockMineWinner].(string); ok && winnerStr != "" {
+		winner, err := address.NewFromString(winnerStr)
+		if err != nil {
+			return errors.Wrap(err, "Bad mock mine winner passed")
+		}
+		opts = append(opts, node.MockMineWinner(winner))
+	}
 	opts = append(opts, node.ClockConfigOption(clock.NewSystemClock()))
 
 	journal, err := journal.NewZapJournal(rep.JournalPath())
@@ -2,12 +2,14 @@ package commands_test
 
 import (
 	"fmt"
+	"io/ioutil"
 	"net/http"
 	"os"
 	"path/filepath"
+	"sync"
 	"testing"
+	"time"
 
-	manet "github.com/multiformats/go-multiaddr-net"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -26,6 +28,29 @@ func TestDaemonStartupMessage(t *testing.T) {
 	assert.Regexp(t, "\\nSwarm listening on.*", out)
 }
 
+func TestDaemonLogToFile(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	dir, err := ioutil.TempDir("", "daemon-log")
+	require.NoError(t, err)
+	defer func() {
+		require.NoError(t, os.RemoveAll(dir))
+	}()
+	logFile := filepath.Join(dir, "daemon.log")
+
+	daemon := th.NewDaemon(t, th.LogToFile(logFile)).Start()
+	daemon.RunSuccess("id")
+	daemon.ShutdownSuccess()
+
+	// The output is still there for the test to read.
+	out := daemon.ReadStdout()
+	assert.Regexp(t, "^My peer ID is [a-zA-Z0-9]*", out)
+
+	log, err := ioutil.ReadFile(logFile)
+	require.NoError(t, err)
+	assert.Contains(t, string(log), "My peer ID is")
+}
+
 func TestDaemonApiFile(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -41,6 +66,106 @@ func TestDaemonApiFile(t *testing.T) {
 	assert.True(t, os.IsNotExist(err))
 }
 
+func TestDaemonShutdownWaitsForExit(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	daemon := th.NewDaemon(t).Start()
+	repoDir := daemon.RepoDir()
+	daemon.RunSuccess("id")
+
+	assert.True(t, daemon.Shutdown(), "daemon had to be killed")
+
+	_, err := os.Stat(repoDir)
+	assert.True(t, os.IsNotExist(err), "repo was not deleted")
+
+	// The output can still be read after the process is gone.
+	assert.Contains(t, daemon.ReadStdout(), "My peer ID is")
+}
+
+func TestDaemonRepoLock(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	daemon := th.NewDaemon(t).Start()
+	defer daemon.ShutdownSuccess()
+
+	// A second daemon on the same repo must not start.
+	daemon.RunFail("repo already in use", "daemon")
+
+	// Local commands that write the repo refuse it too.
+	daemon.RunFail("repo already in use", "init")
+
+	// The running daemon is unaffected.
+	daemon.RunSuccess("id")
+}
+
+func TestDaemonWaitForAPISurfacesCause(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// The daemon is initialized but never started, so it never writes its
+	// api file.
+	daemon := th.NewDaemon(t, th.APITimeout(time.Second))
+	defer func() {
+		require.NoError(t, os.RemoveAll(filepath.Dir(daemon.RepoDir())))
+	}()
+
+	start := time.Now()
+	err := daemon.WaitForAPI()
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "failed to come online in 1s")
+	assert.Contains(t, err.Error(), "api: no such file or directory")
+	assert.True(t, time.Since(start) < 5*time.Second, "waited %s", time.Since(start))
+}
+
+func TestDaemonsStartConcurrently(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	var lk sync.Mutex
+	apiAddrs := make(map[string]bool)
+
+	t.Run("group", func(t *testing.T) {
+		for i := 0; i < 20; i++ {
+			t.Run(fmt.Sprintf("daemon %d", i), func(t *testing.T) {
+				t.Parallel()
+
+				daemon := th.NewDaemon(t).Start()
+				defer daemon.ShutdownSuccess()
+
+				addr, err := daemon.CmdAddr()
+				require.NoError(t, err)
+				assert.NotEmpty(t, daemon.GetID())
+
+				lk.Lock()
+				defer lk.Unlock()
+				assert.False(t, apiAddrs[addr.String()], "api address %s used twice", addr)
+				apiAddrs[addr.String()] = true
+			})
+		}
+	})
+	assert.Len(t, apiAddrs, 20)
+}
+
+func TestDaemonAPIOnAllInterfaces(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// Start succeeding means the liveness check reached the API.
+	td := th.NewDaemon(t, th.CmdAPIAddr("/ip4/0.0.0.0/tcp/0")).Start()
+	defer td.ShutdownSuccess()
+
+	maddr, err := td.CmdAddr()
+	require.NoError(t, err)
+	assert.Contains(t, maddr.String(), "/ip4/0.0.0.0/")
+
+	apiURL, err := td.APIURL()
+	require.NoError(t, err)
+	assert.Regexp(t, `^http://127\.0\.0\.1:\d+/api$`, apiURL)
+
+	res, err := http.Get(apiURL + "/id")
+	require.NoError(t, err)
+	defer res.Body.Close() // nolint: errcheck
+	assert.Equal(t, http.StatusOK, res.StatusCode)
+	assert.NotEmpty(t, td.GetID())
+}
+
 func TestDaemonCORS(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -48,13 +173,10 @@ func TestDaemonCORS(t *testing.T) {
 		td := th.NewDaemon(t).Start()
 		defer td.ShutdownSuccess()
 
-		maddr, err := td.CmdAddr()
+		apiURL, err := td.APIURL()
 		assert.NoError(t, err)
 
-		_, host, err := manet.DialArgs(maddr)
-		assert.NoError(t, err)
-
-		url := fmt.Sprintf("http://%s/api/id", host)
+		url := apiURL + "/id"
 		req, err := http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "http://localhost:8080")
@@ -88,13 +210,10 @@ func TestDaemonCORS(t *testing.T) {
 		td := th.NewDaemon(t).Start()
 		defer td.ShutdownSuccess()
 
-		maddr, err := td.CmdAddr()
-		assert.NoError(t, err)
-
-		_, host, err := manet.DialArgs(maddr)
+		apiURL, err := td.APIURL()
 		assert.NoError(t, err)
 
-		url := fmt.Sprintf("http://%s/api/id", host)
+		url := apiURL + "/id"
 		req, err := http.NewRequest("GET", url, nil)
 		assert.NoError(t, err)
 		req.Header.Add("Origin", "http://disallowed.origin")
@@ -110,13 +229,10 @@ func TestDaemonOverHttp(t *testing.T) {
 	td := th.NewDaemon(t).Start()
 	defer td.ShutdownSuccess()
 
-	maddr, err := td.CmdAddr()
-	require.NoError(t, err)
-
-	_, host, err := manet.DialArgs(maddr)
+	apiURL, err := td.APIURL()
 	require.NoError(t, err)
 
-	url := fmt.Sprintf("http://%s/api/daemon", host)
+	url := apiURL + "/daemon"
 	req, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
 	res, err := http.DefaultClient.Do(req)
@@ -2,8 +2,13 @@
 package commands
 
 import (
+	"context"
+	"time"
+
+	"github.com/ipfs/go-cid"
 	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
 	cmds "github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
 )
 
 var dagCmd = &cmds.Command{
@@ -11,6 +16,7 @@ var dagCmd = &cmds.Command{
 		Tagline: "Interact with IPLD DAG objects.",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"cat": dagCatCmd,
 		"get": dagGetCmd,
 	},
 }
@@ -31,3 +37,45 @@ var dagGetCmd = &cmds.Command{
 		return re.Emit(out)
 	},
 }
+
+var dagCatCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Print the data of a DAG by the CID of its root",
+		ShortDescription: `
+Prints the data imported with client import, reassembled from the leaves of
+the DAG, to stdout. Fails if the root cannot be fetched within the timeout.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the root of the DAG"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("timeout", "Maximum time to wait for the root. e.g., 300ms, 1.5h, 2h45m.").WithDefault("1m"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		c, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid cid "+req.Arguments[0])
+		}
+
+		timeoutDuration, err := time.ParseDuration(req.Options["timeout"].(string))
+		if err != nil {
+			return errors.Wrap(err, "Invalid timeout string")
+		}
+
+		// Fetch the root first, so that a missing DAG fails instead of
+		// waiting for the network forever.
+		ctx, cancel := context.WithTimeout(req.Context, timeoutDuration)
+		defer cancel()
+		if _, err := GetPorcelainAPI(env).DAGGetNode(ctx, c.String()); err != nil {
+			return errors.Wrapf(err, "failed to get root %s", c)
+		}
+
+		dr, err := GetPorcelainAPI(env).DAGCat(req.Context, c)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(dr)
+	},
+}
@@ -57,3 +57,29 @@ func TestDagDaemon(t *testing.T) {
 		// types.AssertHaveSameCid(assert, &expected, &actual)
 	})
 }
+
+func TestDagCat(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	t.Run("reassembles imported data spanning many leaves", func(t *testing.T) {
+		// Large enough for the importer to split it into several chunks.
+		data := bytes.Repeat([]byte("HODLHODLHODL"), 100000)
+		root := d.RunWithStdin(bytes.NewReader(data), "client", "import").ReadStdoutTrimNewlines()
+
+		var node struct {
+			Links []interface{}
+		}
+		require.NoError(t, json.Unmarshal([]byte(d.RunSuccess("dag", "get", root, "--enc", "json").ReadStdout()), &node))
+		assert.True(t, len(node.Links) > 1)
+
+		assert.Equal(t, data, d.DagCat(root))
+	})
+
+	t.Run("fails for a missing cid", func(t *testing.T) {
+		missing := types.CidFromString(t, "not imported")
+		d.RunFail("failed to get root", "dag", "cat", missing.String(), "--timeout", "1s")
+	})
+}
@@ -4,12 +4,14 @@ import (
 	"encoding/json"
 	"io"
 	"strconv"
+	"strings"
 
 	"github.com/ipfs/go-cid"
 	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
 	cmds "github.com/ipfs/go-ipfs-cmds"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
@@ -94,6 +96,47 @@ deals, active deals, finished deals and cancelled deals.
 	},
 }
 
+var dealStateOption = cmdkit.StringOption("state", "Comma separated states of the deals to list, e.g. accepted,failed. Lists all but rejected and failed deals by default")
+
+// parseDealStates parses the states given with dealStateOption.
+func parseDealStates(req *cmds.Request) ([]storagedeal.State, error) {
+	opt, _ := req.Options["state"].(string)
+	if opt == "" {
+		return nil, nil
+	}
+
+	var states []storagedeal.State
+	for _, name := range strings.Split(opt, ",") {
+		state, err := storagedeal.ParseState(strings.TrimSpace(name))
+		if err != nil {
+			return nil, err
+		}
+		states = append(states, state)
+	}
+	return states, nil
+}
+
+// ownMinerAddress returns the address of the miner of this node, or
+// address.Undef if it has none.
+func ownMinerAddress(env cmds.Environment) (address.Address, error) {
+	v, err := GetPorcelainAPI(env).ConfigGet("mining.minerAddress")
+	if err != nil {
+		return address.Undef, err
+	}
+	addr, _ := v.(address.Address)
+	return addr, nil
+}
+
+var dealRecordsEncoders = cmds.EncoderMap{
+	cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, records []porcelain.DealRecord) error {
+		sw := NewSilentWriter(w)
+		for _, r := range records {
+			sw.Printf("%s\t%s\t%s FIL\t%d blocks\t%s\n", r.ProposalCid, r.State, r.TotalPrice, r.Duration, r.Counterparty)
+		}
+		return sw.Error()
+	}),
+}
+
 var dealsRedeemCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Redeem vouchers for a deal",
@@ -0,0 +1,104 @@
+package commands
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"strconv"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+var devCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Development and testing tools",
+		ShortDescription: `Commands that manipulate the node in ways a production network never would,
+to reproduce conditions that are hard to produce on demand. They are only
+available on networks running in test proofs mode.`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"drop-piece": devDropPieceCmd,
+		"null-round": devNullRoundCmd,
+	},
+}
+
+var devNullRoundCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Simulate <n> rounds in which no block is mined",
+		ShortDescription: `Advances the epoch the next block is mined at by <n> without producing
+blocks. The next 'mining once' builds a block at the current head height plus
+the pending null rounds plus one. Repeated calls accumulate. Prints the total
+number of null rounds pending.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("n", true, false, "Number of null rounds to simulate"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := requireDevNetwork(req.Context, env); err != nil {
+			return err
+		}
+
+		n, err := strconv.ParseUint(req.Arguments[0], 10, 64)
+		if err != nil || n == 0 {
+			return fmt.Errorf("invalid number of null rounds: %s", req.Arguments[0])
+		}
+
+		return re.Emit(GetBlockAPI(env).MiningAddNullRounds(n))
+	},
+	Type: uint64(0),
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, pending uint64) error {
+			_, err := fmt.Fprintf(w, "%d null rounds pending\n", pending)
+			return err
+		}),
+	},
+}
+
+var devDropPieceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Simulate the loss of the data of a piece",
+		ShortDescription: `Makes this node's retrieval miner behave as if it no longer holds the piece
+with the given CID: retrievals and audits of the piece fail until the node
+restarts. The sealed data itself is left untouched.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "Content identifier of the piece to drop"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := requireDevNetwork(req.Context, env); err != nil {
+			return err
+		}
+
+		pieceCid, err := cid.Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		GetRetrievalAPI(env).DropPiece(pieceCid)
+		return re.Emit(pieceCid)
+	},
+	Type: cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, pieceCid cid.Cid) error {
+			_, err := fmt.Fprintf(w, "dropped piece %s\n", pieceCid)
+			return err
+		}),
+	},
+}
+
+// requireDevNetwork returns ErrDevOnly unless the node's network runs in test
+// proofs mode.
+func requireDevNetwork(ctx context.Context, env cmds.Environment) error {
+	pp, err := GetPorcelainAPI(env).ProtocolParameters(ctx)
+	if err != nil {
+		return err
+	}
+	if pp.ProofsMode != types.TestProofsMode {
+		return ErrDevOnly
+	}
+	return nil
+}
@@ -0,0 +1,49 @@
+package commands_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+func TestDevNullRound(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miner := makeTestDaemonWithMinerAndStart(t)
+	defer miner.ShutdownSuccess()
+
+	peer := th.NewDaemon(t).Start()
+	defer peer.ShutdownSuccess()
+
+	miner.ConnectSuccess(peer)
+
+	miner.MineAndPropagate(10*time.Second, peer)
+	before := miner.GetChainHead()[0]
+	require.Equal(t, types.Uint64(1), before.Height)
+
+	t.Run("rejects zero rounds", func(t *testing.T) {
+		miner.RunFail("invalid number of null rounds", "dev", "null-round", "0")
+	})
+
+	miner.NullRounds(3)
+	miner.MineAndPropagate(10*time.Second, peer)
+
+	afterNull := miner.GetChainHead()[0]
+	assert.Equal(t, before.Height+4, afterNull.Height)
+	assert.True(t, afterNull.ParentWeight > before.ParentWeight)
+
+	// The peer synced the block built over the gap and agrees on the head.
+	assert.Equal(t, afterNull.Cid(), peer.GetChainHead()[0].Cid())
+
+	// Null rounds are consumed by one block; the next one follows directly.
+	miner.MineAndPropagate(10*time.Second, peer)
+	next := miner.GetChainHead()[0]
+	assert.Equal(t, afterNull.Height+1, next.Height)
+	assert.True(t, next.ParentWeight > afterNull.ParentWeight)
+}
@@ -29,4 +29,7 @@ var (
 
 	// ErrNoWalletAddresses indicates that there are no addresses in wallet to mine to.
 	ErrNoWalletAddresses = fmt.Errorf("no addresses in wallet to mine to")
+
+	// ErrDevOnly indicates that a dev command was run against a node that is not on a development network.
+	ErrDevOnly = errors.New("dev commands are only available on networks running in test proofs mode")
 )
@@ -0,0 +1,120 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/tools/gengen/util"
+)
+
+// defaultGenesisTimestamp is the timestamp of genesis blocks made by genesis
+// new, the same as gengen's, so that a spec always yields the same block.
+const defaultGenesisTimestamp = 123456789
+
+var genesisCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Create genesis blocks",
+	},
+	Subcommands: map[string]*cmds.Command{
+		"new": genesisNewCmd,
+	},
+}
+
+var genesisNewCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Write a genesis car file from a JSON spec",
+		ShortDescription: `
+Reads a genesis spec, in the format of fixtures/setup.json, and writes the
+genesis block and its state to a car file that go-filecoin init accepts with
+--genesisfile. The spec gives the number of keys to generate, the filecoin
+preallocated to the address of each key and the miners to create with their
+owner key and committed sectors:
+
+{
+  "keys": 2,
+  "preAlloc": ["1000", "10"],
+  "miners": [{"owner": 0, "numCommittedSectors": 1}],
+  "network": "go-filecoin-test"
+}
+
+The same spec and seed always produce the same genesis block. Specs without a
+proofs mode use the test proofs mode, and miners without a sector size use
+the sector size of the proofs mode. The generated keys are in the JSON output
+(--enc=json), from where they can be imported with go-filecoin wallet import.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("spec", "Path of the JSON genesis spec"),
+		cmdkit.StringOption("out", "Path of the car file to write").WithDefault("genesis.car"),
+		cmdkit.Int64Option("seed", "Seed of the generated keys").WithDefault(int64(0)),
+		cmdkit.Int64Option("timestamp", "Unix timestamp of the genesis block").WithDefault(int64(defaultGenesisTimestamp)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		specPath, _ := req.Options["spec"].(string)
+		if specPath == "" {
+			return errors.New("a genesis spec is required, pass it with --spec")
+		}
+		spec, err := readGenesisSpec(specPath)
+		if err != nil {
+			return err
+		}
+		gengen.ApplyProofsModeDefaults(spec, false, false)
+
+		out, err := os.Create(req.Options["out"].(string))
+		if err != nil {
+			return err
+		}
+		defer out.Close() // nolint: errcheck
+
+		seed := req.Options["seed"].(int64)
+		timestamp := time.Unix(req.Options["timestamp"].(int64), 0)
+		info, err := gengen.GenGenesisCar(spec, out, seed, timestamp)
+		if err != nil {
+			return err
+		}
+		if err := out.Close(); err != nil {
+			return err
+		}
+		return re.Emit(info)
+	},
+	Type: &gengen.RenderedGenInfo{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, info *gengen.RenderedGenInfo) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Genesis: %s\n", info.GenesisCid)
+			for i, ki := range info.Keys {
+				addr, err := ki.Address()
+				if err != nil {
+					return err
+				}
+				sw.Printf("Key %d:   %s\n", i, addr)
+			}
+			for _, m := range info.Miners {
+				sw.Printf("Miner:   %s, owned by key %d, power %s\n", m.Address, m.Owner, m.Power)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+// readGenesisSpec parses the genesis spec at path.
+func readGenesisSpec(path string) (*gengen.GenesisCfg, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close() // nolint: errcheck
+
+	var spec gengen.GenesisCfg
+	if err := json.NewDecoder(f).Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse genesis spec: %s", err)
+	}
+	return &spec, nil
+}
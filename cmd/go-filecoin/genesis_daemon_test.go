@@ -0,0 +1,86 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/tools/gengen/util"
+)
+
+func TestGenesisNew(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	dir, err := ioutil.TempDir("", "genesis-new")
+	require.NoError(t, err)
+	defer os.RemoveAll(dir) // nolint: errcheck
+
+	writeSpec := func(name string, spec *gengen.GenesisCfg) string {
+		specJSON, err := json.Marshal(spec)
+		require.NoError(t, err)
+		path := filepath.Join(dir, name)
+		require.NoError(t, ioutil.WriteFile(path, specJSON, 0644))
+		return path
+	}
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	spec := &gengen.GenesisCfg{
+		Keys:     2,
+		PreAlloc: []string{"1000", "10"},
+		Miners: []*gengen.CreateStorageMinerConfig{
+			{Owner: 1, NumCommittedSectors: 2},
+		},
+		Network: "go-filecoin-test",
+	}
+	specPath := writeSpec("spec.json", spec)
+	carPath := filepath.Join(dir, "genesis.car")
+
+	var info gengen.RenderedGenInfo
+	th.RunSuccessJSON(d, &info, "genesis", "new", "--spec", specPath, "--out", carPath)
+	require.Len(t, info.Keys, 2)
+	require.Len(t, info.Miners, 1)
+	assert.Equal(t, 1, info.Miners[0].Owner)
+	assert.Equal(t, types.NewBytesAmount(2*types.OneKiBSectorSize.Uint64()), info.Miners[0].Power)
+
+	t.Run("is deterministic", func(t *testing.T) {
+		var again gengen.RenderedGenInfo
+		th.RunSuccessJSON(d, &again, "genesis", "new", "--spec", specPath, "--out", filepath.Join(dir, "again.car"))
+		assert.Equal(t, info.GenesisCid, again.GenesisCid)
+
+		_, made := th.MakeGenesis(t, spec)
+		assert.Equal(t, info.GenesisCid, made.GenesisCid)
+	})
+
+	t.Run("a node starts from the genesis", func(t *testing.T) {
+		node := th.NewDaemon(t, th.GenesisFile(carPath)).Start()
+		defer node.ShutdownSuccess()
+
+		assert.Equal(t, info.GenesisCid, node.GetChainHead()[0].Cid())
+		for i, fil := range []uint64{1000, 10} {
+			addr, err := info.Keys[i].Address()
+			require.NoError(t, err)
+			assert.Equal(t, types.NewAttoFILFromFIL(fil), *node.WalletBalance(addr.String()))
+		}
+	})
+
+	t.Run("rejects inconsistent specs", func(t *testing.T) {
+		bad := writeSpec("bad.json", &gengen.GenesisCfg{
+			Keys:     1,
+			PreAlloc: []string{"1000"},
+			Miners:   []*gengen.CreateStorageMinerConfig{{Owner: 1, NumCommittedSectors: 1}},
+		})
+		d.RunFail("owner 1 is not one of the 1 keys", "genesis", "new", "--spec", bad, "--out", filepath.Join(dir, "bad.car"))
+
+		d.RunFail("genesis spec is required", "genesis", "new")
+	})
+}
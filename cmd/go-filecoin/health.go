@@ -0,0 +1,36 @@
+package commands
+
+import (
+	"io"
+
+	"github.com/ipfs/go-ipfs-cmdkit"
+	"github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+)
+
+var healthCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Check the health of the node's subsystems",
+		ShortDescription: `
+Reports whether the node is in sync with the chain, how full its message pool
+is, whether its wallet has addresses and whether it has enough peers. Each is
+ok, degraded or failed, and the overall state is the worst of them.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		return re.Emit(GetPorcelainAPI(env).Health(req.Context))
+	},
+	Type: &porcelain.HealthReport{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, report *porcelain.HealthReport) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("%-13s%s\n", "status:", report.State)
+			sw.Printf("%-13s%-10s%s\n", "sync:", report.Sync.State, report.Sync.Detail)
+			sw.Printf("%-13s%-10s%s\n", "mpool:", report.MessagePool.State, report.MessagePool.Detail)
+			sw.Printf("%-13s%-10s%s\n", "wallet:", report.Wallet.State, report.Wallet.Detail)
+			sw.Printf("%-13s%-10s%s\n", "peers:", report.Peers.State, report.Peers.Detail)
+			return sw.Error()
+		}),
+	},
+}
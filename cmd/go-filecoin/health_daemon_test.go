@@ -0,0 +1,42 @@
+package commands_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+)
+
+func TestHealth(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("isolated node has degraded peers", func(t *testing.T) {
+		d := th.NewDaemon(t).Start()
+		defer d.ShutdownSuccess()
+
+		report := d.Health()
+		assert.Equal(t, "degraded", report.State)
+		assert.Equal(t, "degraded", report.Peers.State)
+		assert.Contains(t, report.Peers.Detail, "0 peers")
+		assert.Equal(t, "ok", report.Sync.State)
+		assert.Equal(t, "ok", report.MessagePool.State)
+		assert.Equal(t, "ok", report.Wallet.State)
+
+		out := d.RunSuccess("health").ReadStdout()
+		assert.Contains(t, out, "status:      degraded")
+	})
+
+	t.Run("connected node is ok", func(t *testing.T) {
+		d1 := th.NewDaemon(t).Start()
+		defer d1.ShutdownSuccess()
+		d2 := th.NewDaemon(t).Start()
+		defer d2.ShutdownSuccess()
+		d1.ConnectSuccess(d2)
+
+		report := d1.Health()
+		assert.Equal(t, "ok", report.Peers.State)
+		assert.Equal(t, "ok", report.State)
+	})
+}
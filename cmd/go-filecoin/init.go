@@ -8,9 +8,9 @@ import (
 	"net/http"
 	"net/url"
 	"os"
+	"strings"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
-	"github.com/ipfs/go-car"
 	"github.com/ipfs/go-hamt-ipld"
 	"github.com/ipfs/go-ipfs-blockstore"
 	"github.com/ipfs/go-ipfs-cmdkit"
@@ -21,6 +21,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/fixtures"
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/node"
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/paths"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/config"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
@@ -37,6 +38,7 @@ var initCmd = &cmds.Command{
 	Options: []cmdkit.Option{
 		cmdkit.StringOption(GenesisFile, "path of file or HTTP(S) URL containing archive of genesis block DAG data"),
 		cmdkit.StringOption(PeerKeyFile, "path of file containing key to use for new node's libp2p identity"),
+		cmdkit.StringOption(WalletPassphraseFile, "when set, encrypts the keys of the wallet with the passphrase in this file. The daemon then starts with the wallet locked, see wallet unlock"),
 		cmdkit.StringOption(WithMiner, "when set, creates a custom genesis block with a pre generated miner account, requires running the daemon using dev mode (--dev)"),
 		cmdkit.StringOption(OptionSectorDir, "path of directory into which staged and sealed sectors will be written"),
 		cmdkit.StringOption(DefaultAddress, "when set, sets the daemons's default address to the provided address"),
@@ -74,7 +76,8 @@ var initCmd = &cmds.Command{
 		}
 
 		peerKeyFile, _ := req.Options[PeerKeyFile].(string)
-		initopts, err := getNodeInitOpts(peerKeyFile)
+		walletPassphraseFile, _ := req.Options[WalletPassphraseFile].(string)
+		initopts, err := getNodeInitOpts(peerKeyFile, walletPassphraseFile)
 		if err != nil {
 			return err
 		}
@@ -166,7 +169,7 @@ func loadGenesis(ctx context.Context, rep repo.Repo, sourceName string) (consens
 	}
 	defer func() { _ = source.Close() }()
 
-	genesisBlk, err := extractGenesisBlock(source, rep)
+	genesisBlk, err := extractGenesisBlock(ctx, source, rep)
 	if err != nil {
 		return nil, err
 	}
@@ -179,7 +182,7 @@ func loadGenesis(ctx context.Context, rep repo.Repo, sourceName string) (consens
 
 }
 
-func getNodeInitOpts(peerKeyFile string) ([]node.InitOpt, error) {
+func getNodeInitOpts(peerKeyFile string, walletPassphraseFile string) ([]node.InitOpt, error) {
 	var initOpts []node.InitOpt
 	if peerKeyFile != "" {
 		data, err := ioutil.ReadFile(peerKeyFile)
@@ -193,6 +196,18 @@ func getNodeInitOpts(peerKeyFile string) ([]node.InitOpt, error) {
 		initOpts = append(initOpts, node.PeerKeyOpt(peerKey))
 	}
 
+	if walletPassphraseFile != "" {
+		data, err := ioutil.ReadFile(walletPassphraseFile)
+		if err != nil {
+			return nil, err
+		}
+		passphrase := strings.TrimRight(string(data), "\r\n")
+		if passphrase == "" {
+			return nil, fmt.Errorf("wallet passphrase file %s is empty", walletPassphraseFile)
+		}
+		initOpts = append(initOpts, node.WalletPassphraseOpt(passphrase))
+	}
+
 	return initOpts, nil
 }
 
@@ -222,15 +237,16 @@ func openGenesisSource(sourceName string) (io.ReadCloser, error) {
 	return source, nil
 }
 
-func extractGenesisBlock(source io.ReadCloser, rep repo.Repo) (*block.Block, error) {
+func extractGenesisBlock(ctx context.Context, source io.ReadCloser, rep repo.Repo) (*block.Block, error) {
 	bs := blockstore.NewBlockstore(rep.Datastore())
-	ch, err := car.LoadCar(bs, source)
+	// import refuses chains that do not connect back to a genesis block.
+	headKey, err := chain.Import(ctx, bs, source)
 	if err != nil {
 		return nil, err
 	}
 
 	// need to check if we are being handed a car file with a single genesis block or an entire chain.
-	bsBlk, err := bs.Get(ch.Roots[0])
+	bsBlk, err := bs.Get(headKey.ToSlice()[0])
 	if err != nil {
 		return nil, err
 	}
@@ -256,7 +272,7 @@ func extractGenesisBlock(source io.ReadCloser, rep repo.Repo) (*block.Block, err
 
 		gensisBlk = cur
 
-		logInit.Infow("initialized go-filecoin with genesis file containing partial chain", "genesisCID", gensisBlk.Cid().String(), "headCIDs", ch.Roots)
+		logInit.Infow("initialized go-filecoin with genesis file containing partial chain", "genesisCID", gensisBlk.Cid().String(), "headCIDs", headKey)
 	} else {
 		gensisBlk = cur
 	}
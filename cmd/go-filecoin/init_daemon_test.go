@@ -8,7 +8,6 @@ import (
 	"strconv"
 	"testing"
 
-	manet "github.com/multiformats/go-multiaddr-net"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/build/project"
@@ -22,13 +21,10 @@ func TestInitOverHttp(t *testing.T) {
 	td := th.NewDaemon(t).Start()
 	defer td.ShutdownSuccess()
 
-	maddr, err := td.CmdAddr()
+	apiURL, err := td.APIURL()
 	require.NoError(t, err)
 
-	_, host, err := manet.DialArgs(maddr)
-	require.NoError(t, err)
-
-	url := fmt.Sprintf("http://%s/api/init", host)
+	url := apiURL + "/init"
 	req, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
 	res, err := http.DefaultClient.Do(req)
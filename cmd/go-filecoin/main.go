@@ -69,6 +69,9 @@ const (
 	// GenesisFile is the path of file containing archive of genesis block DAG data
 	GenesisFile = "genesisfile"
 
+	// WalletPassphraseFile is the path of file containing the passphrase the keys of the wallet are encrypted with
+	WalletPassphraseFile = "wallet-passphrase-file"
+
 	// DevnetStaging populates config bootstrap addrs with the dns multiaddrs of the staging devnet and other staging devnet specific bootstrap parameters
 	DevnetStaging = "devnet-staging"
 
@@ -81,6 +84,18 @@ const (
 	// IsRelay when set causes the the daemon to provide libp2p relay
 	// services allowing other filecoin nodes behind NATs to talk directly.
 	IsRelay = "is-relay"
+
+	// MockMineInterval is the duration string of the time between the
+	// timestamps of consecutive blocks the daemon mines, instead of stamping
+	// blocks with the current time. Timestamps never run past the current time.
+	// Used by tests to make mining deterministic.
+	MockMineInterval = "mock-mine-interval"
+
+	// MockMineWinner is the address of the only miner the daemon mines blocks
+	// for. That miner wins every election, whatever its power. Used by tests to
+	// control which node wins when several mine; every node in such a test
+	// must be started with the same winner to accept its blocks.
+	MockMineWinner = "mock-mine-winner"
 )
 
 // command object for the local cli
@@ -120,6 +135,7 @@ NETWORK COMMANDS
 ACTOR COMMANDS
   go-filecoin actor                  - Interact with actors. Actors are built-in smart contracts
   go-filecoin paych                  - Payment channel operations
+  go-filecoin market                 - Inspect and manage funds held in escrow for deals
 
 MESSAGE COMMANDS
   go-filecoin message                - Manage messages
@@ -127,6 +143,9 @@ MESSAGE COMMANDS
   go-filecoin outbox                 - Manage the outbound message queue
 
 TOOL COMMANDS
+  go-filecoin dev                    - Development and testing tools
+  go-filecoin genesis                - Create genesis blocks
+  go-filecoin health                 - Check the health of the node's subsystems
   go-filecoin inspect                - Show info about the go-filecoin node
   go-filecoin leb128                 - Leb128 cli encode/decode
   go-filecoin log                    - Interact with the daemon event log output
@@ -152,6 +171,7 @@ var rootCmdDaemon = &cmds.Command{
 // all top level commands, not available to daemon
 var rootSubcmdsLocal = map[string]*cmds.Command{
 	"daemon":  daemonCmd,
+	"genesis": genesisCmd,
 	"init":    initCmd,
 	"version": versionCmd,
 	"leb128":  leb128Cmd,
@@ -167,11 +187,14 @@ var rootSubcmdsDaemon = map[string]*cmds.Command{
 	"client":           clientCmd,
 	"dag":              dagCmd,
 	"deals":            dealsCmd,
+	"dev":              devCmd,
 	"dht":              dhtCmd,
+	"health":           healthCmd,
 	"id":               idCmd,
 	"inspect":          inspectCmd,
 	"leb128":           leb128Cmd,
 	"log":              logCmd,
+	"market":           marketCmd,
 	"message":          msgCmd,
 	"miner":            minerCmd,
 	"mining":           miningCmd,
@@ -225,6 +248,13 @@ func (e *executor) Execute(req *cmds.Request, re cmds.ResponseEmitter, env cmds.
 		return e.exec.Execute(req, re, env)
 	}
 
+	// Run the client side hook before sending, as the cmds http client does.
+	if req.Command.PreRun != nil {
+		if err := req.Command.PreRun(req, env); err != nil {
+			return err
+		}
+	}
+
 	client := cmdhttp.NewClient(e.api, cmdhttp.ClientWithAPIPrefix(APIPrefix))
 
 	res, err := client.Send(req)
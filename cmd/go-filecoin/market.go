@@ -0,0 +1,160 @@
+package commands
+
+import (
+	"fmt"
+	"io"
+
+	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
+	cmds "github.com/ipfs/go-ipfs-cmds"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+var marketCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Inspect the storage market and manage funds held in escrow for deals",
+		ShortDescription: `
+Deal payments are escrowed in payment channels created by the client. The
+escrow commands total the funds held in a payer's channels and reclaim the
+ones whose channels have expired. The orderbook command lists the asks and
+bids of the storage market.
+`,
+	},
+	Subcommands: map[string]*cmds.Command{
+		"escrow":    marketEscrowCmd,
+		"orderbook": marketOrderBookCmd,
+		"withdraw":  marketWithdrawCmd,
+	},
+}
+
+var marketEscrowCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the funds an address holds in escrow",
+		ShortDescription: `
+Prints the funds locked in payment channels that may still be redeemed by
+their targets, and the funds available to withdraw from expired channels.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address of the payer"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		payer, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		escrow, err := GetPorcelainAPI(env).PaymentChannelEscrow(req.Context, payer)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(escrow)
+	},
+	Type: porcelain.Escrow{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, escrow *porcelain.Escrow) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Locked:    %s FIL\n", escrow.Locked)
+			sw.Printf("Available: %s FIL\n", escrow.Available)
+			return sw.Error()
+		}),
+	},
+}
+
+var marketWithdrawCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Withdraw the funds available in escrow",
+		ShortDescription: `
+Sends a reclaim message for each expired payment channel of the payer that
+still holds funds. The funds are returned to the payer once the messages are
+mined.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address of the payer"),
+		priceOption,
+		limitOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		gasPrice, gasLimit, _, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		withdrawal, err := GetPorcelainAPI(env).PaymentChannelWithdraw(req.Context, fromAddr, gasPrice, gasLimit)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(withdrawal)
+	},
+	Type: porcelain.Withdrawal{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, withdrawal *porcelain.Withdrawal) error {
+			if len(withdrawal.Messages) == 0 {
+				_, err := fmt.Fprintln(w, "nothing to withdraw")
+				return err
+			}
+			sw := NewSilentWriter(w)
+			sw.Printf("Withdrawing %s FIL\n", withdrawal.Amount)
+			for _, c := range withdrawal.Messages {
+				sw.Println(c.String())
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var marketOrderBookCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show the asks and bids of the storage market",
+		ShortDescription: `
+Prints every unexpired ask on chain, cheapest first, with its price in FIL per
+byte per block, the miner's sector size and the number of blocks until the ask
+expires.
+
+Bids are not recorded on chain, so the bids listed are the storage deal
+proposals this node knows of that a miner has accepted but not yet completed:
+the ones it made as a client and, if it is a miner, the ones it received. They
+are grouped by client with their total price, size and duration in blocks.
+`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		book, err := GetPorcelainAPI(env).MarketOrderBook(req.Context)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(book)
+	},
+	Type: porcelain.OrderBook{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, book *porcelain.OrderBook) error {
+			sw := NewSilentWriter(w)
+			sw.Println("Asks:")
+			if len(book.Asks) == 0 {
+				sw.Println("  none")
+			}
+			for _, ask := range book.Asks {
+				sw.Printf("  %s ask %d: %s FIL/byte/block, sector size %s, expires at %s (%d blocks)\n",
+					ask.Miner, ask.ID, ask.Price, ask.SectorSize, ask.Expiry, ask.Duration)
+			}
+			sw.Println("Bids:")
+			if len(book.Bids) == 0 {
+				sw.Println("  none")
+			}
+			for _, bid := range book.Bids {
+				sw.Printf("  %s to %s: %s FIL for %s bytes over %d blocks (%s, proposal %s)\n",
+					bid.Client, bid.Miner, bid.TotalPrice, bid.Size, bid.Duration, bid.State, bid.ProposalID)
+			}
+			return sw.Error()
+		}),
+	},
+}
@@ -0,0 +1,188 @@
+package commands_test
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/fixtures"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+func TestMarketEscrowAndWithdraw(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer d.ShutdownSuccess()
+
+	payer := fixtures.TestAddresses[0]
+	assert.Equal(t, th.MarketEscrow{Locked: types.ZeroAttoFIL, Available: types.ZeroAttoFIL}, d.MarketEscrow(payer))
+	out := d.RunSuccess("market", "withdraw", "--from", payer, "--gas-price", "1", "--gas-limit", "300")
+	assert.Equal(t, "nothing to withdraw", out.ReadStdoutTrimNewlines())
+
+	eol := uint64(d.GetChainHead()[0].Height) + 3
+	out = d.RunSuccess("paych", "create", fixtures.TestAddresses[1], "10", strconv.FormatUint(eol, 10),
+		"--from", payer,
+		"--gas-price", "1", "--gas-limit", "300",
+	)
+	createCid, err := cid.Decode(out.ReadStdoutTrimNewlines())
+	require.NoError(t, err)
+	d.RunSuccess("mining", "once")
+	d.WaitForMessageRequireSuccess(createCid)
+
+	// The channel is open, so its funds are locked.
+	escrow := d.MarketEscrow(payer)
+	assert.Equal(t, types.NewAttoFILFromFIL(10), escrow.Locked)
+	assert.Equal(t, types.ZeroAttoFIL, escrow.Available)
+
+	for uint64(d.GetChainHead()[0].Height) < eol {
+		d.RunSuccess("mining", "once")
+	}
+
+	// Once the channel expires its funds become available.
+	escrow = d.MarketEscrow(payer)
+	assert.Equal(t, types.ZeroAttoFIL, escrow.Locked)
+	assert.Equal(t, types.NewAttoFILFromFIL(10), escrow.Available)
+
+	reclaims := d.MarketWithdraw(payer)
+	require.Len(t, reclaims, 1)
+	d.RunSuccess("mining", "once")
+	d.WaitForMessageRequireSuccess(reclaims[0])
+
+	assert.Equal(t, th.MarketEscrow{Locked: types.ZeroAttoFIL, Available: types.ZeroAttoFIL}, d.MarketEscrow(payer))
+}
+
+func TestMarketEscrowForDeal(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	payer := fixtures.TestAddresses[2]
+	before := client.MarketEscrow(payer)
+
+	dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+	proposeDealOutput := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "5").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(proposeDealOutput, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	// The last voucher pays out the whole value of the deal.
+	out := client.RunSuccess("client", "payments", dealCid, "--enc=json")
+	var vouchers []*types.PaymentVoucher
+	require.NoError(t, json.Unmarshal([]byte(out.ReadStdout()), &vouchers))
+	require.NotEmpty(t, vouchers)
+	dealValue := vouchers[len(vouchers)-1].Amount
+
+	during := client.MarketEscrow(payer)
+	assert.Equal(t, before.Locked.Add(dealValue), during.Locked)
+
+	// Once the channel expires after the deal completes, the escrow is no
+	// longer locked. Whatever the miner did not redeem becomes available.
+	require.NoError(t, th.WaitForIt(50, 1*time.Second, func() (bool, error) {
+		return client.MarketEscrow(payer).Locked.Equal(before.Locked), nil
+	}))
+	after := client.MarketEscrow(payer)
+	assert.True(t, after.Available.LessEqual(before.Available.Add(dealValue)))
+}
+
+func TestMarketOrderBookAsks(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miningDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer miningDaemon.ShutdownSuccess()
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2])).Start()
+	defer d.ShutdownSuccess()
+
+	miningDaemon.ConnectSuccess(d)
+	miningDaemon.RunSuccess("mining", "start")
+
+	assert.Empty(t, d.OrderBook().Asks)
+
+	genesisMiner := fixtures.TestMiners[0]
+	newMiner := d.CreateMinerWithSectorSize(fixtures.TestAddresses[2], types.OneKiBSectorSize.Uint64()).String()
+	d.WaitForMessageRequireSuccess(miningDaemon.MinerSetPrice(genesisMiner, fixtures.TestAddresses[0], "30", "1000"))
+	d.WaitForMessageRequireSuccess(d.MinerSetPrice(newMiner, fixtures.TestAddresses[2], "10", "1000"))
+
+	book := d.OrderBook()
+	require.Len(t, book.Asks, 2)
+	assert.Empty(t, book.Bids)
+
+	// Asks are listed cheapest first.
+	assert.Equal(t, newMiner, book.Asks[0].Miner.String())
+	assert.Equal(t, types.NewAttoFILFromFIL(10), book.Asks[0].Price)
+	assert.Equal(t, types.OneKiBSectorSize, book.Asks[0].SectorSize)
+	assert.Equal(t, genesisMiner, book.Asks[1].Miner.String())
+	assert.Equal(t, types.NewAttoFILFromFIL(30), book.Asks[1].Price)
+	for _, ask := range book.Asks {
+		assert.True(t, ask.Duration > 0 && ask.Duration <= 1000)
+	}
+
+	out := d.RunSuccess("market", "orderbook").ReadStdout()
+	assert.Contains(t, out, newMiner)
+	assert.Contains(t, out, genesisMiner)
+}
+
+func TestMarketOrderBookBids(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+	miner.ConnectSuccess(client)
+
+	client.WaitForMessageRequireSuccess(miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100"))
+
+	dataCid := client.RunWithStdin(strings.NewReader("HODLHODLHODL"), "client", "import").ReadStdoutTrimNewlines()
+	proposeDealOutput := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "5").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(proposeDealOutput, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	// Both sides of the deal list the client's bid.
+	for _, d := range []*th.TestDaemon{client, miner} {
+		bids := d.OrderBook().Bids
+		require.Len(t, bids, 1)
+		assert.Equal(t, fixtures.TestAddresses[2], bids[0].Client.String())
+		assert.Equal(t, fixtures.TestMiners[0], bids[0].Miner.String())
+		assert.Equal(t, dealCid, bids[0].ProposalID.String())
+		assert.Equal(t, uint64(5), bids[0].Duration)
+	}
+}
@@ -2,10 +2,12 @@ package commands
 
 import (
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
@@ -17,6 +19,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/cst"
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
@@ -28,10 +31,13 @@ var msgCmd = &cmds.Command{
 		Tagline: "Send and monitor messages",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"send":       msgSendCmd,
-		"sendsigned": signedMsgSendCmd,
-		"status":     msgStatusCmd,
-		"wait":       msgWaitCmd,
+		"estimate-gas": msgEstimateGasCmd,
+		"replay-one":   msgReplayOneCmd,
+		"send":         msgSendCmd,
+		"sendsigned":   signedMsgSendCmd,
+		"show":         msgShowCmd,
+		"status":       msgStatusCmd,
+		"wait":         msgWaitCmd,
 	},
 }
 
@@ -186,6 +192,91 @@ var signedMsgSendCmd = &cmds.Command{
 	},
 }
 
+var msgEstimateGasCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Estimate the gas limit and price of a message",
+		ShortDescription: `
+Dry-runs the message against the state of the chain head and prints the gas
+limit to send it with, the gas it used plus a margin, and a suggested gas
+price: the median price of the messages in the message pool, but no less than
+this node's minimum gas price for mining. Fails if the message would fail.
+
+Method parameters are given as the hex encoding of their ABI encoding, and are
+checked against the signature of the method on the target actor.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("target", true, false, "Address of the actor to send the message to"),
+		cmdkit.StringArg("method", false, false, "ID of the method to invoke on the target actor"),
+		cmdkit.StringArg("params", false, false, "Hex encoded ABI encoding of the method parameters"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("value", "Value to send with message in FIL"),
+		cmdkit.StringOption("from", "Address to send message from"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		target, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		rawVal := req.Options["value"]
+		if rawVal == nil {
+			rawVal = "0"
+		}
+		val, ok := types.NewAttoFILFromFILString(rawVal.(string))
+		if !ok {
+			return errors.New("mal-formed value")
+		}
+
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		methodID := types.SendMethodID
+		if len(req.Arguments) > 1 {
+			id, err := strconv.ParseUint(req.Arguments[1], 10, 64)
+			if err != nil {
+				return errors.Wrap(err, "invalid method id")
+			}
+			methodID = types.MethodID(id)
+		}
+
+		var params []interface{}
+		if len(req.Arguments) > 2 {
+			encoded, err := hex.DecodeString(req.Arguments[2])
+			if err != nil {
+				return errors.Wrap(err, "params must be hex encoded")
+			}
+			sig, err := GetPorcelainAPI(env).ActorGetSignature(req.Context, target, methodID)
+			if err != nil {
+				return errors.Wrap(err, "failed to get method signature")
+			}
+			values, err := abi.DecodeValues(encoded, sig.Params)
+			if err != nil {
+				return errors.Wrap(err, "invalid params")
+			}
+			params = abi.FromValues(values)
+		}
+
+		estimate, err := GetPorcelainAPI(env).MessageEstimateGas(req.Context, fromAddr, target, val, methodID, params...)
+		if err != nil {
+			return err
+		}
+		return re.Emit(estimate)
+	},
+	Type: &porcelain.GasEstimate{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, estimate *porcelain.GasEstimate) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("GasLimit:\t%d\n", estimate.GasLimit)
+			sw.Printf("GasPrice:\t%s\n", estimate.GasPrice)
+			return sw.Error()
+		}),
+	},
+}
+
 // WaitResult is the result of a message wait call.
 type WaitResult struct {
 	Message   *types.SignedMessage
@@ -361,6 +452,160 @@ var msgStatusCmd = &cmds.Command{
 	},
 }
 
+// States of a message as reported by message show.
+const (
+	MessageStateUnknown = "unknown"
+	MessageStatePending = "pending"
+	MessageStateMined   = "mined"
+)
+
+// MessageShowResult is a message as found on chain or in the message pool.
+type MessageShowResult struct {
+	// State is one of MessageStateUnknown, MessageStatePending or MessageStateMined.
+	State   string
+	Message *types.SignedMessage `json:",omitempty"`
+	// Receipt, Block and Height are only set for a mined message.
+	Receipt *types.MessageReceipt `json:",omitempty"`
+	Block   *cid.Cid              `json:",omitempty"`
+	Height  uint64                `json:",omitempty"`
+}
+
+var msgShowCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show a message without waiting for it to be mined",
+		ShortDescription: `Looks up the message with <cid> on chain, in the message pool and in the
+outbox and prints its state: mined, pending or unknown. A mined message is
+shown with its receipt and the block that first included it. Unlike 'message
+wait', this returns immediately.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the message to show"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Parse(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid cid "+req.Arguments[0])
+		}
+
+		api := GetPorcelainAPI(env)
+		chainMsg, onChain, err := api.MessageFind(req.Context, msgCid)
+		if err != nil {
+			return err
+		}
+		if onChain {
+			blkCid := chainMsg.Block.Cid()
+			return re.Emit(&MessageShowResult{
+				State:   MessageStateMined,
+				Message: chainMsg.Message,
+				Receipt: chainMsg.Receipt,
+				Block:   &blkCid,
+				Height:  uint64(chainMsg.Block.Height),
+			})
+		}
+
+		if poolMsg, ok := api.MessagePoolGet(msgCid); ok {
+			return re.Emit(&MessageShowResult{State: MessageStatePending, Message: poolMsg})
+		}
+		for _, addr := range api.OutboxQueues() {
+			for _, qm := range api.OutboxQueueLs(addr) {
+				c, err := qm.Msg.Cid()
+				if err != nil {
+					return err
+				}
+				if c.Equals(msgCid) {
+					return re.Emit(&MessageShowResult{State: MessageStatePending, Message: qm.Msg})
+				}
+			}
+		}
+		return re.Emit(&MessageShowResult{State: MessageStateUnknown})
+	},
+	Type: &MessageShowResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MessageShowResult) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("State: %s\n", res.State)
+			if res.Block != nil {
+				sw.Printf("Block: %s at height %d\n", res.Block, res.Height)
+			}
+			if res.Receipt != nil {
+				sw.Printf("Receipt: %v\n", res.Receipt)
+			}
+			if res.Message != nil {
+				sw.Println(res.Message.String())
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var msgReplayOneCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Execute a single message against historical chain state",
+		ShortDescription: `Loads the state at the tipset given by --at and executes the message with
+<cid>, found on chain or in the message pool, as if it were the only message
+in the next block. --at is either a block height, in which case the tipset in
+effect at that height on the current chain is used, or a comma-separated list
+of the block CIDs of a tipset. It defaults to the chain head. Prints the
+receipt and the actors the message changed. Nothing is written to the chain.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("cid", true, false, "CID of the message to replay"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("at", "Height or tipset (comma-separated block CIDs) whose state to execute against"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		msgCid, err := cid.Parse(req.Arguments[0])
+		if err != nil {
+			return errors.Wrap(err, "invalid cid "+req.Arguments[0])
+		}
+
+		api := GetPorcelainAPI(env)
+		baseKey := api.ChainHeadKey()
+		if at, ok := req.Options["at"].(string); ok {
+			if height, err := strconv.ParseUint(at, 10, 64); err == nil {
+				ts, err := api.ChainTipSetAtHeight(req.Context, height)
+				if err != nil {
+					return err
+				}
+				baseKey = ts.Key()
+			} else {
+				blockCids, err := cidsFromSlice(strings.Split(at, ","))
+				if err != nil {
+					return errors.Wrap(err, "--at is neither a height nor a list of block cids")
+				}
+				baseKey = block.NewTipSetKey(blockCids...)
+			}
+		}
+
+		result, err := api.MessageReplayOne(req.Context, msgCid, baseKey)
+		if err != nil {
+			return err
+		}
+		return re.Emit(result)
+	},
+	Type: msg.ReplayResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *msg.ReplayResult) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Executed at height %d\n", res.Height)
+			sw.Printf("Exit code: %d\n", res.Receipt.ExitCode)
+			sw.Printf("Gas: %s\n", res.Receipt.GasAttoFIL)
+			if res.Error != "" {
+				sw.Printf("Error: %s\n", res.Error)
+			}
+			for _, change := range res.Trace {
+				sw.Printf("%s: balance %s -> %s, nonce %d -> %d", change.Address, change.BalanceBefore, change.BalanceAfter, change.NonceBefore, change.NonceAfter)
+				if change.HeadChanged {
+					sw.Printf(", state changed")
+				}
+				sw.Println()
+			}
+			return sw.Error()
+		}),
+	},
+}
+
 func appendJSON(val interface{}, out []byte) ([]byte, error) {
 	m, err := json.MarshalIndent(val, "", "\t")
 	if err != nil {
@@ -1,18 +1,24 @@
 package commands_test
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"strconv"
 	"strings"
 	"testing"
+	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
 	"github.com/filecoin-project/go-filecoin/fixtures"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
@@ -107,6 +113,77 @@ func TestMessageWait(t *testing.T) {
 		)
 		assert.Equal(t, "", wait.ReadStdout())
 	})
+
+	t.Run("[success] helper returns an error on timeout", func(t *testing.T) {
+		msg := d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1",
+			"--gas-limit", "300",
+			fixtures.TestAddresses[1],
+		)
+		msgcid, err := cid.Parse(msg.ReadStdoutTrimNewlines())
+		require.NoError(t, err)
+
+		_, err = d.WaitForMessageTimeout(msgcid, 100*time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "deadline exceeded")
+
+		d.RunSuccess("mining once")
+
+		rcpt, err := d.WaitForMessageTimeout(msgcid, time.Minute)
+		require.NoError(t, err)
+		assert.Equal(t, uint8(0), rcpt.ExitCode)
+	})
+}
+
+func TestMessageEstimateGas(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	estimate := d.EstimateGas(fixtures.TestAddresses[0], fixtures.TestAddresses[1], "10")
+	assert.Equal(t, types.NewGasPrice(1), estimate.GasPrice)
+
+	// A message sent with the estimate is mined successfully.
+	msgcid := d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", estimate.GasPrice.String(),
+		"--gas-limit", strconv.FormatUint(uint64(estimate.GasLimit), 10),
+		"--value", "10",
+		fixtures.TestAddresses[1],
+	).ReadStdoutTrimNewlines()
+	d.RunSuccess("mining once")
+	c, err := cid.Parse(msgcid)
+	require.NoError(t, err)
+	d.WaitForMessageRequireSuccess(c)
+
+	d.RunFail("would fail", "message", "estimate-gas",
+		"--from", fixtures.TestAddresses[0],
+		"--value", "1000000000",
+		fixtures.TestAddresses[1],
+	)
+
+	t.Run("with method params", func(t *testing.T) {
+		target, err := address.NewFromString(fixtures.TestAddresses[1])
+		require.NoError(t, err)
+		method := strconv.FormatUint(uint64(paymentbroker.CreateChannel), 10)
+		params := hex.EncodeToString(abi.MustConvertParams(target, types.NewBlockHeight(1000)))
+
+		channelEstimate := d.EstimateGas(fixtures.TestAddresses[0],
+			address.PaymentBrokerAddress.String(), "10", method, params)
+		assert.True(t, channelEstimate.GasLimit > estimate.GasLimit,
+			"creating a channel should need more gas than a transfer")
+
+		d.RunFail("invalid params", "message", "estimate-gas",
+			"--from", fixtures.TestAddresses[0],
+			address.PaymentBrokerAddress.String(), method, "0102",
+		)
+		d.RunFail("hex", "message", "estimate-gas",
+			"--from", fixtures.TestAddresses[0],
+			address.PaymentBrokerAddress.String(), method, "not hex",
+		)
+	})
 }
 
 func TestMessageSendBlockGasLimit(t *testing.T) {
@@ -187,3 +264,90 @@ func TestMessageStatus(t *testing.T) {
 		assert.NotContains(t, status, "On chain")
 	})
 }
+
+func TestMessageShow(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	out := d.RunSuccess("message", "send",
+		"--from", fixtures.TestAddresses[0],
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value=1234",
+		fixtures.TestAddresses[1],
+	)
+	msgCid, err := cid.Parse(out.ReadStdoutTrimNewlines())
+	require.NoError(t, err)
+
+	pending := d.MessageShow(msgCid)
+	assert.Equal(t, commands.MessageStatePending, pending.State)
+	require.NotNil(t, pending.Message)
+	assert.Equal(t, "1234", pending.Message.Message.Value.String())
+	assert.Nil(t, pending.Receipt)
+	assert.Nil(t, pending.Block)
+
+	d.RunSuccess("mining", "once")
+
+	mined := d.MessageShow(msgCid)
+	assert.Equal(t, commands.MessageStateMined, mined.State)
+	require.NotNil(t, mined.Receipt)
+	assert.Equal(t, uint8(0), mined.Receipt.ExitCode)
+	head := d.GetChainHead()
+	require.NotNil(t, mined.Block)
+	assert.True(t, head[0].Cid().Equals(*mined.Block))
+	assert.Equal(t, uint64(head[0].Height), mined.Height)
+
+	unknown := d.MessageShow(types.NewCidForTestGetter()())
+	assert.Equal(t, commands.MessageStateUnknown, unknown.State)
+	assert.Nil(t, unknown.Message)
+}
+
+func TestMessageReplayOne(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	sender := d.CreateAddress()
+	fund := func(value string) uint64 {
+		msgCid := d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1", "--gas-limit", "300",
+			"--value", value,
+			sender,
+		).ReadStdout()
+		d.RunSuccess("mining", "once")
+		d.RunSuccess("message", "wait", strings.TrimSpace(msgCid))
+		return uint64(d.GetChainHead()[0].Height)
+	}
+
+	// Too little to cover the transfer below, then enough.
+	poorHeight := fund("1")
+	richHeight := fund("100")
+
+	msgCid := strings.TrimSpace(d.RunSuccess("message", "send",
+		"--from", sender,
+		"--gas-price", "1", "--gas-limit", "300",
+		"--value", "50",
+		fixtures.TestAddresses[1],
+	).ReadStdout())
+	d.RunSuccess("mining", "once")
+	d.RunSuccess("message", "wait", msgCid)
+
+	t.Run("reverts against state with insufficient funds", func(t *testing.T) {
+		rcpt := d.ReplayMessageAt(msgCid, poorHeight)
+		assert.NotEqual(t, 0, int(rcpt.ExitCode))
+	})
+
+	t.Run("succeeds against state with enough funds", func(t *testing.T) {
+		rcpt := d.ReplayMessageAt(msgCid, richHeight)
+		assert.Equal(t, 0, int(rcpt.ExitCode))
+	})
+
+	t.Run("unknown message", func(t *testing.T) {
+		// A block cid names no message.
+		blkCid := d.GetChainHead()[0].Cid().String()
+		d.RunFail("not found", "message", "replay-one", blkCid)
+	})
+}
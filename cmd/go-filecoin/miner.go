@@ -1,9 +1,11 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"math/big"
+	"os"
 	"strconv"
 	"strings"
 
@@ -24,15 +26,20 @@ var minerCmd = &cmds.Command{
 		Tagline: "Manage a single miner actor",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"create":         minerCreateCmd,
-		"owner":          minerOwnerCmd,
-		"power":          minerPowerCmd,
-		"set-price":      minerSetPriceCmd,
-		"update-peerid":  minerUpdatePeerIDCmd,
-		"collateral":     minerCollateralCmd,
-		"proving-window": minerProvingWindowCmd,
-		"set-worker":     minerSetWorkerAddressCmd,
-		"worker":         minerWorkerAddressCmd,
+		"create":          minerCreateCmd,
+		"export-deals":    minerExportDealsCmd,
+		"faults":          minerFaultsCmd,
+		"list-deals":      minerListDealsCmd,
+		"owner":           minerOwnerCmd,
+		"power":           minerPowerCmd,
+		"set-price":       minerSetPriceCmd,
+		"update-peerid":   minerUpdatePeerIDCmd,
+		"collateral":      minerCollateralCmd,
+		"pending-sectors": minerPendingSectorsCmd,
+		"pledge-cost":     minerPledgeCostCmd,
+		"proving-window":  minerProvingWindowCmd,
+		"set-worker":      minerSetWorkerAddressCmd,
+		"worker":          minerWorkerAddressCmd,
 	},
 }
 
@@ -56,7 +63,7 @@ additional sectors.`,
 		cmdkit.StringArg("collateral", true, false, "The amount of collateral, in FIL."),
 	},
 	Options: []cmdkit.Option{
-		cmdkit.StringOption("sectorsize", "size of the sectors which this miner will commit, in bytes"),
+		cmdkit.StringOption("sectorsize", "sector-size", "size of the sectors which this miner will commit, in bytes"),
 		cmdkit.StringOption("from", "address to send from"),
 		cmdkit.StringOption("peerid", "Base58-encoded libp2p peer ID that the miner will operate"),
 		priceOption,
@@ -71,22 +78,18 @@ additional sectors.`,
 			return err
 		}
 
-		sectorSize, err := optionalSectorSizeWithDefault(req.Options["sectorsize"], pp.SupportedSectors[0].Size)
+		defaultSize, err := defaultSectorSize(pp)
 		if err != nil {
 			return err
 		}
 
-		// TODO: It may become the case that the protocol does not specify an
-		// enumeration of supported sector sizes, but rather that any sector
-		// size for which a miner has Groth parameters and a verifying key is
-		// supported.
-		// https://github.com/filecoin-project/specs/pull/318
-		if !pp.IsSupportedSectorSize(sectorSize) {
-			supportedStrs := make([]string, len(pp.SupportedSectors))
-			for i, si := range pp.SupportedSectors {
-				supportedStrs[i] = si.Size.String()
-			}
-			return fmt.Errorf("unsupported sector size: %s (supported sizes: %s)", sectorSize, strings.Join(supportedStrs, ", "))
+		sectorSize, err := optionalSectorSizeWithDefault(req.Options["sectorsize"], defaultSize)
+		if err != nil {
+			return err
+		}
+
+		if err := checkSupportedSectorSize(pp, sectorSize); err != nil {
+			return err
 		}
 
 		fromAddr, err := fromAddrOrDefault(req, env)
@@ -456,6 +459,96 @@ var minerCollateralCmd = &cmds.Command{
 	},
 }
 
+var minerFaultsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show whether a miner was slashed for missing its PoSt",
+		ShortDescription: `A miner that does not submit a PoSt within the grace period after its proving
+period ends is slashed by the next mining node to see it: it loses all its power
+and the collateral of the sectors it failed to prove is burnt. Shows the number
+of sectors the miner was slashed for, the height it was slashed at, the
+collateral burnt in attoFIL and the power it has left.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "The address of the miner"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		faults, err := GetPorcelainAPI(env).MinerGetFaults(req.Context, minerAddr)
+		if err != nil {
+			return err
+		}
+		return re.Emit(faults)
+	},
+	Type: porcelain.MinerFaults{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, faults *porcelain.MinerFaults) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Faults:             %d\n", faults.Faults)
+			if faults.SlashedAt.IsZero() {
+				sw.Println("Slashed at:         never")
+			} else {
+				sw.Printf("Slashed at:         %s\n", faults.SlashedAt.String())
+			}
+			sw.Printf("Slashed collateral: %s\n", faults.SlashedCollateral.String())
+			sw.Printf("Power:              %s\n", faults.Power.String())
+			return sw.Error()
+		}),
+	},
+}
+
+var minerPledgeCostCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Get the collateral required to commit <power> bytes of storage",
+		ShortDescription: `Computes the collateral a miner must pledge to commit enough sectors to reach
+<power> bytes of storage, given the protocol's current collateral requirements.
+Power that does not fill a whole sector is rounded up to the next sector.
+Values reported in FIL, suitable as the collateral argument to 'miner create'.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("power", true, false, "The target amount of power, in bytes"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("sectorsize", "sector-size", "size of the sectors which the miner will commit, in bytes"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		power, ok := types.NewBytesAmountFromString(req.Arguments[0], 10)
+		if !ok || !power.IsPositive() {
+			return fmt.Errorf("invalid power: %s", req.Arguments[0])
+		}
+
+		pp, err := GetPorcelainAPI(env).ProtocolParameters(req.Context)
+		if err != nil {
+			return err
+		}
+
+		defaultSize, err := defaultSectorSize(pp)
+		if err != nil {
+			return err
+		}
+
+		sectorSize, err := optionalSectorSizeWithDefault(req.Options["sectorsize"], defaultSize)
+		if err != nil {
+			return err
+		}
+
+		if err := checkSupportedSectorSize(pp, sectorSize); err != nil {
+			return err
+		}
+
+		return re.Emit(miner.CollateralForPower(sectorSize, power))
+	},
+	Type: types.AttoFIL{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, af types.AttoFIL) error {
+			return PrintString(w, af)
+		}),
+	},
+}
+
 var minerProvingWindowCmd = &cmds.Command{
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("miner", true, false, "Miner address to get proving window for"),
@@ -494,12 +587,146 @@ ProvingSet: %s
 	},
 }
 
+var minerPendingSectorsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List sectors of <miner> that are committed but not yet proven",
+		ShortDescription: `Lists the sectors with a commitment on chain for which no PoSt has been
+accepted yet, along with the deadline: the last block height at which a PoSt
+covering the sector is on time.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "Miner address to list pending sectors for"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddress, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		pending, err := GetPorcelainAPI(env).MinerGetPendingSectors(req.Context, minerAddress)
+		if err != nil {
+			return err
+		}
+		return re.Emit(pending)
+	},
+	Type: []porcelain.MinerPendingSector{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, pending []porcelain.MinerPendingSector) error {
+			for _, sector := range pending {
+				if _, err := fmt.Fprintf(w, "%d\tdeadline %s\n", sector.SectorID, sector.Deadline.String()); err != nil {
+					return err
+				}
+			}
+			return nil
+		}),
+	},
+}
+
+// MinerExportDealsResult is the type returned when exporting the deals of a
+// miner.
+type MinerExportDealsResult struct {
+	Path  string
+	Deals int
+}
+
+var minerExportDealsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Write the deals of <miner> to <file> as JSON",
+		ShortDescription: `Writes a manifest of the storage deals this node holds for <miner>, one entry
+per deal the miner accepted and has not failed, ordered by start height. Each
+entry lists the proposal and piece cids, the client, the total price, the size,
+the start and end heights and, once the piece is sealed, the sector id.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "Miner address to export the deals of"),
+		cmdkit.StringArg("file", true, false, "File to write the manifest to"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddress, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		deals, err := GetPorcelainAPI(env).MinerListDeals(req.Context, minerAddress)
+		if err != nil {
+			return err
+		}
+
+		f, err := os.Create(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		enc := json.NewEncoder(f)
+		enc.SetIndent("", "  ")
+		if err := enc.Encode(deals); err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		return re.Emit(&MinerExportDealsResult{Path: req.Arguments[1], Deals: len(deals)})
+	},
+	Type: MinerExportDealsResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *MinerExportDealsResult) error {
+			_, err := fmt.Fprintf(w, "exported %d deals to %s\n", res.Deals, res.Path)
+			return err
+		}),
+	},
+}
+
+var minerListDealsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the storage deals <miner> received",
+		ShortDescription: `Lists the storage deals this node holds for <miner>, by default the miner of
+this node, with their proposal cid, state, total price, duration in blocks and
+client. Rejected and failed deals are only listed when selected with --state.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", false, false, "Miner address to list the deals of"),
+	},
+	Options: []cmdkit.Option{
+		dealStateOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		states, err := parseDealStates(req)
+		if err != nil {
+			return err
+		}
+
+		var minerAddress address.Address
+		if len(req.Arguments) > 0 {
+			minerAddress, err = address.NewFromString(req.Arguments[0])
+		} else {
+			minerAddress, err = ownMinerAddress(env)
+		}
+		if err != nil {
+			return err
+		}
+		if minerAddress.Empty() {
+			return errors.New("this node has no miner, pass the miner address as argument")
+		}
+
+		deals, err := GetPorcelainAPI(env).MinerListDealRecords(req.Context, minerAddress, states...)
+		if err != nil {
+			return err
+		}
+		return re.Emit(deals)
+	},
+	Type:     []porcelain.DealRecord{},
+	Encoders: dealRecordsEncoders,
+}
+
 var minerSetWorkerAddressCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline:          "Set the address of the miner worker. Returns a message CID",
-		ShortDescription: "Set the address of the miner worker to the provided address. When a miner is created, this address defaults to the miner owner. Use this command to change the default. Returns a message CID to wait for the message to appear on chain.",
+		ShortDescription: "Set the address of the worker of <miner> to the provided address. When a miner is created, this address defaults to the miner owner. Use this command to change the default. The message is signed by the miner owner, whose key must be in the wallet. Returns a message CID to wait for the message to appear on chain.",
 	},
 	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "The address of the miner"),
 		cmdkit.StringArg("new-address", true, false, "The address of the new miner worker."),
 	},
 	Options: []cmdkit.Option{
@@ -507,7 +734,12 @@ var minerSetWorkerAddressCmd = &cmds.Command{
 		limitOption,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		newWorker, err := address.NewFromString(req.Arguments[0])
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		newWorker, err := address.NewFromString(req.Arguments[1])
 		if err != nil {
 			return err
 		}
@@ -517,7 +749,7 @@ var minerSetWorkerAddressCmd = &cmds.Command{
 			return err
 		}
 
-		msgCid, err := GetPorcelainAPI(env).MinerSetWorkerAddress(req.Context, newWorker, gasPrice, gasLimit)
+		msgCid, err := GetPorcelainAPI(env).MinerSetWorkerAddress(req.Context, minerAddr, newWorker, gasPrice, gasLimit)
 		if err != nil {
 			return err
 		}
@@ -536,36 +768,70 @@ var minerSetWorkerAddressCmd = &cmds.Command{
 // MinerWorkerResult is a struct containing the result of a MinerWorker or MinerSetWorker command.
 type MinerWorkerResult struct {
 	WorkerAddress address.Address `json:"workerAddress"`
+	// InWallet is true if this node's wallet holds the worker key, i.e. the
+	// node can sign blocks for the miner.
+	InWallet bool `json:"inWallet"`
 }
 
 var minerWorkerAddressCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline:          "Show the address of the miner worker",
-		ShortDescription: "Show the address of the miner worker",
+		ShortDescription: "Show the address of the worker of <miner> and whether this node's wallet holds its key. Blocks for the miner are signed with the worker key.",
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "The address of the miner"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		ret, err := GetPorcelainAPI(env).ConfigGet("mining.minerAddress")
+		minerAddr, err := address.NewFromString(req.Arguments[0])
 		if err != nil {
-			return errors.Wrap(err, "problem getting miner address")
-		}
-		minerAddr, ok := ret.(address.Address)
-		if !ok {
-			return errors.New("problem converting miner address")
+			return err
 		}
 		workerAddr, err := GetPorcelainAPI(env).MinerGetWorkerAddress(req.Context, minerAddr, GetPorcelainAPI(env).ChainHeadKey())
 		if err != nil {
 			return errors.Wrap(err, "problem getting worker address")
 		}
+		_, err = GetPorcelainAPI(env).WalletFind(workerAddr)
 
-		res := MinerWorkerResult{WorkerAddress: workerAddr}
-		fmt.Printf("workerAddr: %s", res.WorkerAddress.String())
-		return re.Emit(&res)
+		return re.Emit(&MinerWorkerResult{
+			WorkerAddress: workerAddr,
+			InWallet:      err == nil,
+		})
 	},
 	Type: &MinerWorkerResult{},
 	Encoders: cmds.EncoderMap{
 		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, result *MinerWorkerResult) error {
-			fmt.Fprintln(w, result.WorkerAddress.String()) // nolint: errcheck
-			return nil
+			inWallet := "not in wallet"
+			if result.InWallet {
+				inWallet = "in wallet"
+			}
+			_, err := fmt.Fprintf(w, "%s (%s)\n", result.WorkerAddress, inWallet)
+			return err
 		}),
 	},
 }
+
+// defaultSectorSize returns the first sector size the protocol supports.
+func defaultSectorSize(pp *porcelain.ProtocolParams) (*types.BytesAmount, error) {
+	if len(pp.SupportedSectors) == 0 {
+		return nil, errors.New("the protocol supports no sector sizes")
+	}
+	return pp.SupportedSectors[0].Size, nil
+}
+
+// checkSupportedSectorSize returns an error listing the supported sector sizes
+// if sectorSize is not one of them.
+func checkSupportedSectorSize(pp *porcelain.ProtocolParams, sectorSize *types.BytesAmount) error {
+	// TODO: It may become the case that the protocol does not specify an
+	// enumeration of supported sector sizes, but rather that any sector
+	// size for which a miner has Groth parameters and a verifying key is
+	// supported.
+	// https://github.com/filecoin-project/specs/pull/318
+	if !pp.IsSupportedSectorSize(sectorSize) {
+		supportedStrs := make([]string, len(pp.SupportedSectors))
+		for i, si := range pp.SupportedSectors {
+			supportedStrs[i] = si.Size.String()
+		}
+		return fmt.Errorf("unsupported sector size: %s (supported sizes: %s)", sectorSize, strings.Join(supportedStrs, ", "))
+	}
+	return nil
+}
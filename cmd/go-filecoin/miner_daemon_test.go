@@ -8,18 +8,23 @@ import (
 	"fmt"
 	"io/ioutil"
 	"math/big"
+	"path/filepath"
+	"strconv"
 	"strings"
 	"sync"
 	"testing"
 	"time"
 
+	go_sectorbuilder "github.com/filecoin-project/go-sectorbuilder"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
 	"github.com/filecoin-project/go-filecoin/fixtures"
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -82,12 +87,13 @@ func TestMinerHelp(t *testing.T) {
 	})
 	t.Run("set-worker --help shows set-worker help", func(t *testing.T) {
 		expected := []string{
-			"go-filecoin miner set-worker <new-address> - Set the address of the miner worker",
-			"go-filecoin miner set-worker [--gas-price=<gas-price>] [--gas-limit=<gas-limit>] [--] <new-address>",
+			"go-filecoin miner set-worker <miner> <new-address> - Set the address of the miner worker",
+			"go-filecoin miner set-worker [--gas-price=<gas-price>] [--gas-limit=<gas-limit>] [--] <miner> <new-address>",
+			"<miner>       - The address of the miner",
 			"<new-address> - The address of the new miner worker.",
 			"--gas-price string - Price (FIL e.g. 0.00013) to pay for each GasUnit consumed mining this message.",
 			"--gas-limit uint64 - Maximum GasUnits this message is allowed to consume.",
-			"Set the address of the miner worker to the provided address. When a miner is created, this address defaults to the miner owner. Use this command to change the default.",
+			"Set the address of the worker of <miner> to the provided address. When a miner is created, this address defaults to the miner owner. Use this command to change the default.",
 		}
 		result := runHelpSuccess(t, "miner", "set-worker", "--help")
 		for _, elem := range expected {
@@ -96,7 +102,7 @@ func TestMinerHelp(t *testing.T) {
 	})
 	t.Run("worker --help shows worker help", func(t *testing.T) {
 		result := runHelpSuccess(t, "miner", "worker", "--help")
-		assert.Contains(t, result, "go-filecoin miner worker - Show the address of the miner worker")
+		assert.Contains(t, result, "go-filecoin miner worker <miner> - Show the address of the miner worker")
 	})
 }
 
@@ -212,6 +218,91 @@ func TestMinerCreate(t *testing.T) {
 	})
 }
 
+func TestMinerCreateWithSectorSize(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miningDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer miningDaemon.ShutdownSuccess()
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2])).Start()
+	defer d.ShutdownSuccess()
+
+	miningDaemon.ConnectSuccess(d)
+	miningDaemon.RunSuccess("mining", "start")
+
+	t.Run("rejects a sector size the network does not support", func(t *testing.T) {
+		d.RunFail("unsupported sector size",
+			"miner", "create",
+			"--from", fixtures.TestAddresses[2],
+			"--sector-size", strconv.FormatUint(2*types.OneKiBSectorSize.Uint64(), 10),
+			"--gas-price", "1", "--gas-limit", "300", "20",
+		)
+	})
+
+	sectorSize := types.OneKiBSectorSize
+	minerAddr := d.CreateMinerWithSectorSize(fixtures.TestAddresses[2], sectorSize.Uint64())
+
+	t.Run("mining status reports the sector size", func(t *testing.T) {
+		var status commands.MiningStatusResult
+		out := d.RunSuccess("mining", "status", "--enc=json").ReadStdout()
+		require.NoError(t, json.Unmarshal([]byte(out), &status))
+
+		assert.Equal(t, minerAddr, status.Miner)
+		assert.True(t, sectorSize.Equal(status.SectorSize))
+	})
+
+	t.Run("rejects a piece too large for the sector size", func(t *testing.T) {
+		d.RunSuccess("mining", "setup")
+
+		maxUserBytes := go_sectorbuilder.GetMaxUserBytesPerStagedSector(sectorSize.Uint64())
+		tooLarge := bytes.NewReader(make([]byte, maxUserBytes+1))
+		d.RunWithStdin(tooLarge, "mining", "add-piece").AssertFail("piece too large for sector")
+	})
+}
+
+func TestMinerWorkerInWallet(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miningDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer miningDaemon.ShutdownSuccess()
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2])).Start()
+	defer d.ShutdownSuccess()
+
+	miningDaemon.ConnectSuccess(d)
+	miningDaemon.RunSuccess("mining", "start")
+
+	owner := fixtures.TestAddresses[2]
+	minerAddr := d.CreateMinerWithSectorSize(owner, types.OneKiBSectorSize.Uint64()).String()
+
+	worker, inWallet := d.MinerWorker(minerAddr)
+	assert.Equal(t, owner, worker.String())
+	assert.True(t, inWallet)
+
+	t.Run("a worker whose key the wallet holds can mine", func(t *testing.T) {
+		newWorker := d.CreateAddress()
+		d.SetMinerWorker(minerAddr, newWorker)
+
+		worker, inWallet := d.MinerWorker(minerAddr)
+		assert.Equal(t, newWorker, worker.String())
+		assert.True(t, inWallet)
+		assert.NoError(t, d.MiningCheck())
+	})
+
+	t.Run("a worker whose key the wallet lacks cannot mine", func(t *testing.T) {
+		remoteWorker := miningDaemon.CreateAddress()
+		d.SetMinerWorker(minerAddr, remoteWorker)
+
+		worker, inWallet := d.MinerWorker(minerAddr)
+		assert.Equal(t, remoteWorker, worker.String())
+		assert.False(t, inWallet)
+
+		err := d.MiningCheck()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "wallet does not hold the key for worker "+remoteWorker)
+	})
+}
+
 func TestMinerSetPrice(t *testing.T) {
 	t.Skip("Long term solution: #3642")
 	tf.IntegrationTest(t)
@@ -249,6 +340,23 @@ func TestMinerCreateSuccess(t *testing.T) {
 	assert.NotEqual(t, address.Undef, minerAddress)
 }
 
+func TestCreateStorageMinerAddr(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	minerDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer minerDaemon.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2])).Start()
+	defer client.ShutdownSuccess()
+	minerDaemon.ConnectSuccess(client)
+
+	minerAddr, msgCid := client.CreateStorageMinerAddr(minerDaemon, fixtures.TestAddresses[2])
+
+	var created address.Address
+	client.WaitForMessageResult(msgCid, &created)
+	assert.Equal(t, minerAddr, created)
+}
+
 func requireMinerCreate(ctx context.Context, t *testing.T, env *fastesting.TestEnvironment, minerNode *fast.Filecoin) address.Address {
 
 	pparams, err := minerNode.Protocol(ctx)
@@ -393,6 +501,63 @@ func TestMinerPower(t *testing.T) {
 	assert.Equal(t, "3072 / 6144", power)
 }
 
+func TestMinerPowerOfGenesisMiner(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	// The genesis miner is the only miner, with one committed sector.
+	power, total := d.MinerPower(fixtures.TestMiners[0])
+	assert.Equal(t, d.MiningStatus().SectorSize, power)
+	assert.Equal(t, power, total)
+
+	out := d.RunSuccess("miner", "power", fixtures.TestMiners[0]).ReadStdoutTrimNewlines()
+	assert.Equal(t, fmt.Sprintf("%s / %s", power, total), out)
+}
+
+func TestMinerPowerGrowsBySealedSector(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.FunctionalTest(t)
+
+	// The genesis miner mines the blocks including the new miner's messages.
+	bootstrap := makeTestDaemonWithMinerAndStart(t)
+	defer bootstrap.ShutdownSuccess()
+	bootstrap.MiningStart()
+	defer bootstrap.MiningStop()
+
+	// Bootstrap miners never submit a PoSt, so a new miner is needed to see
+	// power grow.
+	d := th.NewDaemon(t,
+		th.KeyFile(fixtures.KeyFilePaths()[2]),
+		th.AutoSealInterval("1"),
+	).Start()
+	defer d.ShutdownSuccess()
+	bootstrap.ConnectSuccess(d)
+
+	minerAddr, _ := d.CreateStorageMinerAddr(bootstrap, fixtures.TestAddresses[2])
+	before, _ := d.MinerPower(minerAddr)
+	assert.True(t, before.IsZero())
+	sectorSize := d.MiningStatus().SectorSize
+
+	// Mining starts the storage miner, which seals and proves the sector.
+	d.MiningStart()
+	defer d.MiningStop()
+
+	d.RunWithStdin(strings.NewReader("HODL"), "mining", "add-piece").AssertSuccess()
+	d.RunSuccess("mining", "seal-now")
+
+	// Committing the sector does not add power, the miner's first PoSt
+	// proving it does.
+	var after, total *types.BytesAmount
+	require.NoError(t, th.WaitForIt(300, time.Second, func() (bool, error) {
+		after, total = d.MinerPower(minerAddr)
+		return after.GreaterThan(before), nil
+	}))
+	assert.Equal(t, sectorSize, after)
+	assert.True(t, after.LessEqual(total))
+}
+
 func TestMinerActiveCollateral(t *testing.T) {
 	t.Skip("Long term solution: #3642")
 	tf.IntegrationTest(t)
@@ -433,6 +598,48 @@ func TestMinerActiveCollateral(t *testing.T) {
 	assert.Equal(t, expectedCollateral, collateral)
 }
 
+func TestMinerPledgeCost(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t).Start()
+	defer d.ShutdownSuccess()
+
+	sectorSize := types.OneKiBSectorSize.Uint64()
+
+	// the cost of whole sectors matches the collateral commitSector requires
+	threeSectors := types.NewBytesAmount(3 * sectorSize)
+	assert.Equal(t, miner.MinimumCollateralPerSector.MulBigInt(big.NewInt(3)), *d.PledgeCost(threeSectors))
+
+	// partial sectors are rounded up
+	assert.Equal(t, miner.MinimumCollateralPerSector, *d.PledgeCost(types.NewBytesAmount(1)))
+	assert.Equal(t, miner.MinimumCollateralPerSector.MulBigInt(big.NewInt(2)), *d.PledgeCost(types.NewBytesAmount(sectorSize + 1)))
+
+	d.RunFail("invalid power", "miner", "pledge-cost", "0")
+	d.RunFail("unsupported sector size", "miner", "pledge-cost", "--sector-size", "2048", "1024")
+}
+
+func TestMinerPendingSectors(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miningDaemon := makeTestDaemonWithMinerAndStart(t)
+	defer miningDaemon.ShutdownSuccess()
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2])).Start()
+	defer d.ShutdownSuccess()
+
+	miningDaemon.ConnectSuccess(d)
+	miningDaemon.RunSuccess("mining", "start")
+
+	// Genesis miners get their power without committing sectors.
+	assert.Empty(t, d.PendingSectors(fixtures.TestMiners[0]))
+
+	// A new miner has nothing committed, so nothing is pending.
+	minerAddr := d.CreateMinerWithSectorSize(fixtures.TestAddresses[2], types.OneKiBSectorSize.Uint64())
+	assert.Empty(t, d.PendingSectors(minerAddr.String()))
+
+	d.RunFail("unknown address network", "miner", "pending-sectors", "not-an-address")
+}
+
 var testConfig = &gengen.GenesisCfg{
 	ProofsMode: types.TestProofsMode,
 	Keys:       4,
@@ -467,7 +674,7 @@ func TestMinerWorker(t *testing.T) {
 	minerNode := env.RequireNewNodeWithFunds(1000)
 
 	t.Run("if there is no miner worker, returns error and outputs nothing", func(t *testing.T) {
-		res, err := minerNode.MinerWorker(ctx)
+		res, err := minerNode.MinerWorker(ctx, address.TestAddress)
 		require.NotNil(t, err)
 		lastErr, err := minerNode.LastCmdStdErrStr()
 		require.NoError(t, err)
@@ -483,8 +690,7 @@ func TestMinerWorker(t *testing.T) {
 		workerAddr, err := minerNode.MinerOwner(ctx, minerAddr)
 		require.NoError(t, err)
 
-		res, err := minerNode.MinerWorker(ctx)
-		fmt.Println(minerNode.LastCmdStdErrStr())
+		res, err := minerNode.MinerWorker(ctx, minerAddr)
 		require.NoError(t, err)
 		assert.Equal(t, workerAddr.String(), res.WorkerAddress.String())
 	})
@@ -503,7 +709,7 @@ func TestMinerSetWorker(t *testing.T) {
 	newAddr := address.NewForTestGetter()()
 
 	t.Run("fails if there is no miner worker", func(t *testing.T) {
-		_, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+		_, err := minerNode.MinerSetWorker(ctx, address.TestAddress, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
 		require.NotNil(t, err)
 
 		series.CtxMiningOnce(ctx)
@@ -515,9 +721,9 @@ func TestMinerSetWorker(t *testing.T) {
 
 	t.Run("succceeds if there is a miner", func(t *testing.T) {
 		series.CtxMiningNext(ctx, 1)
-		_ = requireMinerCreate(ctx, t, env, minerNode)
+		minerAddr := requireMinerCreate(ctx, t, env, minerNode)
 
-		msgCid, err := minerNode.MinerSetWorker(ctx, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
+		msgCid, err := minerNode.MinerSetWorker(ctx, minerAddr, newAddr, fast.AOPrice(big.NewFloat(1.0)), fast.AOLimit(300))
 		require.NoError(t, err)
 
 		series.CtxMiningOnce(ctx)
@@ -526,9 +732,81 @@ func TestMinerSetWorker(t *testing.T) {
 		require.NoError(t, err)
 		require.Equal(t, 0, int(resp.Receipt.ExitCode))
 
-		res2, err := minerNode.MinerWorker(ctx)
+		res2, err := minerNode.MinerWorker(ctx, minerAddr)
 		require.NoError(t, err)
 
 		assert.Equal(t, newAddr.String(), res2.WorkerAddress.String())
 	})
 }
+
+func TestMinerExportDeals(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	minerDaemon := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer minerDaemon.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	minerDaemon.RunSuccess("mining start")
+	minerDaemon.UpdatePeerID()
+	minerDaemon.ConnectSuccess(client)
+
+	addAskCid := minerDaemon.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	proposeDeal := func(data, duration string) (string, string) {
+		dataCid := client.RunWithStdin(strings.NewReader(data), "client", "import").ReadStdoutTrimNewlines()
+		out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", duration).ReadStdoutTrimNewlines()
+		splitOnSpace := strings.Split(out, " ")
+		return dataCid, splitOnSpace[len(splitOnSpace)-1]
+	}
+	firstData, firstDeal := proposeDeal("HODLHODLHODL", "100")
+	secondData, secondDeal := proposeDeal("FOREVERDATA", "50")
+
+	minerDaemon.RunSuccess("mining", "seal-now")
+	for _, dealCid := range []string{firstDeal, secondDeal} {
+		require.NoError(t, th.WaitForIt(300, time.Second, func() (bool, error) {
+			out := client.RunSuccess("client", "query-storage-deal", dealCid).ReadStdout()
+			return strings.Contains(out, "complete"), nil
+		}))
+	}
+
+	dir, err := ioutil.TempDir("", "export-deals")
+	require.NoError(t, err)
+	path := filepath.Join(dir, "deals.json")
+	minerDaemon.ExportMinerDeals(fixtures.TestMiners[0], path)
+
+	manifest, err := ioutil.ReadFile(path)
+	require.NoError(t, err)
+	var deals []porcelain.MinerDeal
+	require.NoError(t, json.Unmarshal(manifest, &deals))
+	require.Len(t, deals, 2)
+
+	byProposal := map[string]porcelain.MinerDeal{}
+	for _, deal := range deals {
+		byProposal[deal.ProposalCid.String()] = deal
+	}
+	for _, expected := range []struct {
+		dealCid, dataCid string
+		duration         uint64
+	}{
+		{firstDeal, firstData, 100},
+		{secondDeal, secondData, 50},
+	} {
+		deal, ok := byProposal[expected.dealCid]
+		require.True(t, ok, "deal %s missing from manifest", expected.dealCid)
+		assert.Equal(t, expected.dataCid, deal.PieceCid.String())
+		assert.Equal(t, fixtures.TestAddresses[2], deal.Client.String())
+		assert.Equal(t, deal.StartHeight+expected.duration, deal.EndHeight)
+		assert.True(t, deal.TotalPrice.GreaterThan(types.ZeroAttoFIL))
+		assert.True(t, deal.Size.GreaterThan(types.ZeroBytes))
+		assert.NotNil(t, deal.SectorID)
+		assert.Equal(t, storagedeal.Complete, deal.State)
+	}
+}
@@ -1,6 +1,7 @@
 package commands
 
 import (
+	"encoding/json"
 	"fmt"
 	"io"
 	"strconv"
@@ -9,6 +10,7 @@ import (
 	cmdkit "github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
 	files "github.com/ipfs/go-ipfs-files"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -20,14 +22,16 @@ var miningCmd = &cmds.Command{
 		Tagline: "Manage all mining operations for a node",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"address":   miningAddrCmd,
-		"once":      miningOnceCmd,
-		"start":     miningStartCmd,
-		"status":    miningStatusCmd,
-		"stop":      miningStopCmd,
-		"setup":     miningSetupCmd,
-		"seal-now":  miningSealCmd,
-		"add-piece": miningAddPieceCmd,
+		"address":           miningAddrCmd,
+		"once":              miningOnceCmd,
+		"start":             miningStartCmd,
+		"status":            miningStatusCmd,
+		"stop":              miningStopCmd,
+		"setup":             miningSetupCmd,
+		"seal-now":          miningSealCmd,
+		"add-piece":         miningAddPieceCmd,
+		"check":             miningCheckCmd,
+		"set-min-gas-price": miningSetMinGasPriceCmd,
 	},
 }
 
@@ -88,12 +92,39 @@ var miningSetupCmd = &cmds.Command{
 var miningStartCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Start mining blocks and other mining related operations",
+		ShortDescription: `
+Starts mining blocks continuously. With --until mining stops by itself once a
+block is mined at the given height, so the head does not overshoot it.
+
+Mining pauses while the node is connected to fewer peers than the
+mining.min_peers config value and resumes once enough peers connect.
+'mining status' reports why mining is paused.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("until", "Stop mining once a block is mined at this height"),
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
-		if err := GetBlockAPI(env).MiningStart(req.Context); err != nil {
-			return err
+		until, ok := req.Options["until"].(uint64)
+		started := "Started mining"
+		if !ok {
+			if err := GetBlockAPI(env).MiningStart(req.Context); err != nil {
+				return err
+			}
+		} else {
+			if until == 0 {
+				return errors.New("--until must be a height above zero")
+			}
+			if err := GetBlockAPI(env).MiningStartUntil(req.Context, until); err != nil {
+				return err
+			}
+			started = fmt.Sprintf("Started mining until height %d", until)
+		}
+
+		if err := GetBlockAPI(env).MiningPaused(); err != nil {
+			return re.Emit(fmt.Sprintf("%s, paused: %s", started, err))
 		}
-		return re.Emit("Started mining")
+		return re.Emit(started)
 	},
 	Type:     "",
 	Encoders: stringEncoderMap,
@@ -102,11 +133,14 @@ var miningStartCmd = &cmds.Command{
 // MiningStatusResult is the type returned when get mining status.
 type MiningStatusResult struct {
 	Active        bool                         `json:"active"`
+	StopHeight    uint64                       `json:"stopHeight,omitempty"`
+	PausedReason  string                       `json:"pausedReason,omitempty"`
 	Miner         address.Address              `json:"minerAddress"`
 	Owner         address.Address              `json:"owner"`
 	Collateral    types.AttoFIL                `json:"collateral"`
 	ProvingPeriod porcelain.MinerProvingWindow `json:"provingPeriod,omitempty"`
 	Power         porcelain.MinerPower         `json:"minerPower"`
+	SectorSize    *types.BytesAmount           `json:"sectorSize"`
 }
 
 var miningStatusCmd = &cmds.Command{
@@ -115,6 +149,12 @@ var miningStatusCmd = &cmds.Command{
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		isMining := GetBlockAPI(env).MiningIsActive()
+		var pausedReason string
+		if isMining {
+			if err := GetBlockAPI(env).MiningPaused(); err != nil {
+				pausedReason = err.Error()
+			}
+		}
 
 		// Get the Miner Address
 		minerAddress, err := GetBlockAPI(env).MinerAddress()
@@ -142,13 +182,21 @@ var miningStatusCmd = &cmds.Command{
 			return err
 		}
 
+		sectorSize, err := GetPorcelainAPI(env).MinerGetSectorSize(req.Context, minerAddress)
+		if err != nil {
+			return err
+		}
+
 		return re.Emit(&MiningStatusResult{
 			Active:        isMining,
+			StopHeight:    GetBlockAPI(env).MiningStopHeight(),
+			PausedReason:  pausedReason,
 			Miner:         minerAddress,
 			Owner:         owner,
 			Collateral:    collateral,
 			Power:         power,
 			ProvingPeriod: mpp,
+			SectorSize:    sectorSize,
 		})
 	},
 	Type: &MiningStatusResult{},
@@ -158,12 +206,23 @@ var miningStatusCmd = &cmds.Command{
 			for p := range res.ProvingPeriod.ProvingSet {
 				pSet = append(pSet, p)
 			}
+			stopHeight := "none"
+			if res.StopHeight > 0 {
+				stopHeight = strconv.FormatUint(res.StopHeight, 10)
+			}
+			paused := "no"
+			if res.PausedReason != "" {
+				paused = res.PausedReason
+			}
 			_, err := fmt.Fprintf(w, `Mining Status
 Active:     %s
+Until:      %s
+Paused:     %s
 Address:    %s
 Owner:      %s
 Collateral: %s
 Power:      %s / %s
+SectorSize: %s
 
 Proving Period
 Start:         %s
@@ -171,10 +230,13 @@ End:           %s
 Proving Set:   %s
 
 `, strconv.FormatBool(res.Active),
+				stopHeight,
+				paused,
 				res.Miner.String(),
 				res.Owner.String(),
 				res.Collateral.String(),
 				res.Power.Power.String(), res.Power.Total.String(),
+				res.SectorSize.String(),
 				res.ProvingPeriod.Start.String(),
 				res.ProvingPeriod.End.String(),
 				pSet)
@@ -187,6 +249,11 @@ Proving Set:   %s
 var miningStopCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Stop block mining",
+		ShortDescription: `
+Stops mining started with 'mining start'. The block being mined, if any, is
+abandoned; once the command returns the node mines no more blocks until mining
+is started again.
+`,
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		GetBlockAPI(env).MiningStop(req.Context)
@@ -208,6 +275,56 @@ var miningSealCmd = &cmds.Command{
 	Encoders: stringEncoderMap,
 }
 
+var miningSetMinGasPriceCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Set the lowest gas price of messages included in mined blocks",
+		ShortDescription: `Sets mining.min_gas_price in the config. Blocks this node mines from then on
+only include messages from the message pool whose gas price is at least
+<price>; cheaper messages stay in the pool. A price of 0 includes all messages.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("price", true, false, "Minimum gas price in FIL"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		price, ok := types.NewAttoFILFromFILString(req.Arguments[0])
+		if !ok {
+			return errors.New("invalid gas price (specify FIL as a decimal number)")
+		}
+
+		jsonPrice, err := json.Marshal(price)
+		if err != nil {
+			return err
+		}
+		if err := GetPorcelainAPI(env).ConfigSet("mining.min_gas_price", string(jsonPrice)); err != nil {
+			return err
+		}
+		return re.Emit(price)
+	},
+	Type: &types.AttoFIL{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, price types.AttoFIL) error {
+			_, err := fmt.Fprintf(w, "minimum gas price set to %s FIL\n", price)
+			return err
+		}),
+	},
+}
+
+var miningCheckCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Check that this node holds the keys needed to mine",
+		ShortDescription: `Verifies that the wallet holds the owner and worker keys of the configured
+miner and that they are of a type that can sign blocks. Fails describing the
+first problem found.`,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if err := GetPorcelainAPI(env).MinerCheckSigningKeys(req.Context); err != nil {
+			return err
+		}
+		return re.Emit("mining keys ok")
+	},
+	Encoders: stringEncoderMap,
+}
+
 var stringEncoderMap = cmds.EncoderMap{
 	cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, t string) error {
 		fmt.Fprintln(w, t) // nolint: errcheck
@@ -7,11 +7,13 @@ import (
 	"testing"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	files "github.com/ipfs/go-ipfs-files"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/fixtures"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/tools/fast"
@@ -19,13 +21,6 @@ import (
 	"github.com/filecoin-project/go-filecoin/tools/fast/series"
 )
 
-func parseInt(t *testing.T, s string) *big.Int {
-	i := new(big.Int)
-	i, err := i.SetString(strings.TrimSpace(s), 10)
-	assert.True(t, err, "couldn't parse as big.Int %q", s)
-	return i
-}
-
 func TestMiningGenBlock(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -34,16 +29,116 @@ func TestMiningGenBlock(t *testing.T) {
 
 	addr := fixtures.TestAddresses[0]
 
-	s := d.RunSuccess("wallet", "balance", addr)
-	beforeBalance := parseInt(t, s.ReadStdout())
+	beforeBalance := d.WalletBalance(addr)
+
+	d.RunSuccess("mining", "once")
+
+	afterBalance := d.WalletBalance(addr)
+	assert.Equal(t, beforeBalance.Add(types.NewAttoFILFromFIL(1000)), *afterBalance)
+}
+
+func TestMiningMockMine(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("blocks are stamped a fixed interval apart", func(t *testing.T) {
+		d := th.NewDaemon(
+			t,
+			th.WithMiner(fixtures.TestMiners[0]),
+			th.KeyFile(fixtures.KeyFilePaths()[0]),
+			th.MockMineInterval(30*time.Second),
+		).Start()
+		defer d.ShutdownSuccess()
+
+		parent := d.GetChainHead()[0].Timestamp
+
+		d.RunSuccess("mining", "once")
+		first := d.GetChainHead()[0]
+		assert.Equal(t, parent+30, first.Timestamp)
+
+		d.RunSuccess("mining", "once")
+		second := d.GetChainHead()[0]
+		assert.Equal(t, first.Height+1, second.Height)
+		assert.Equal(t, first.Timestamp+30, second.Timestamp)
+	})
+
+	t.Run("only the winner mines", func(t *testing.T) {
+		d := th.NewDaemon(
+			t,
+			th.WithMiner(fixtures.TestMiners[0]),
+			th.KeyFile(fixtures.KeyFilePaths()[0]),
+			th.MockMineWinner(fixtures.TestAddresses[1]),
+		).Start()
+		defer d.ShutdownSuccess()
+
+		height := d.GetChainHead()[0].Height
+		d.RunFail("is not the mock mining winner", "mining", "once")
+		assert.Equal(t, height, d.GetChainHead()[0].Height)
+	})
+}
+
+func TestMiningBlockMessageLimit(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.ConfigSet("blocks.max_messages", "3")
+
+	for i := 0; i < 5; i++ {
+		d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1",
+			"--gas-limit", "300",
+			"--value", "10",
+			fixtures.TestAddresses[1],
+		)
+	}
 
 	d.RunSuccess("mining", "once")
+	height := uint64(d.GetChainHead()[0].Height)
+
+	assert.Len(t, d.Receipts(height, height), 3)
+
+	pending := strings.Split(strings.TrimSpace(d.RunSuccess("mpool", "ls").ReadStdout()), "\n")
+	assert.Len(t, pending, 2)
+}
+
+func TestMiningMinGasPrice(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
 
-	s = d.RunSuccess("wallet", "balance", addr)
-	afterBalance := parseInt(t, s.ReadStdout())
-	sum := new(big.Int)
+	minPrice := types.NewAttoFILFromFIL(2)
+	d.SetMinGasPrice(&minPrice)
 
-	assert.Equal(t, sum.Add(beforeBalance, big.NewInt(1000)), afterBalance)
+	send := func(gasPrice string) cid.Cid {
+		out := d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", gasPrice,
+			"--gas-limit", "300",
+			"--value", "10",
+			fixtures.TestAddresses[2],
+		)
+		c, err := cid.Decode(out.ReadStdoutTrimNewlines())
+		require.NoError(t, err)
+		return c
+	}
+	// The cheap message comes last so the others do not wait on its nonce.
+	above := send("3")
+	at := send("2")
+	below := send("1")
+
+	d.RunSuccess("mining", "once")
+	height := uint64(d.GetChainHead()[0].Height)
+	assert.Len(t, d.Receipts(height, height), 2)
+	d.WaitForMessageRequireSuccess(above)
+	d.WaitForMessageRequireSuccess(at)
+
+	// The cheap message is still pending after another block.
+	d.RunSuccess("mining", "once")
+	pending := d.RunSuccess("mpool", "ls").ReadStdout()
+	assert.Equal(t, below.String(), strings.TrimSpace(pending))
 }
 
 func TestMiningAddPieceAndSealNow(t *testing.T) {
@@ -111,3 +206,120 @@ func TestMiningAddPieceAndSealNow(t *testing.T) {
 	}
 	assert.Fail(t, "timed out waiting for miner to gain power from sealing")
 }
+
+func TestMiningCheck(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	t.Run("passes when the wallet holds the miner owner key", func(t *testing.T) {
+		d := makeTestDaemonWithMinerAndStart(t)
+		defer d.ShutdownSuccess()
+
+		assert.NoError(t, d.MiningCheck())
+	})
+
+	t.Run("fails when the wallet lacks the miner owner key", func(t *testing.T) {
+		d := th.NewDaemon(t, th.WithMiner(fixtures.TestMiners[0])).Start()
+		defer d.ShutdownSuccess()
+
+		err := d.MiningCheck()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "wallet does not hold the key for owner")
+		assert.Contains(t, err.Error(), fixtures.TestMiners[0])
+	})
+}
+
+func TestMiningStartUntil(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunFail("--until must be a height above zero", "mining", "start", "--until", "0")
+
+	d.MineUntil(10)
+	assert.Equal(t, types.Uint64(10), d.GetChainHead()[0].Height)
+
+	// Mining stops by itself, so leaving it alone does not overshoot.
+	time.Sleep(5 * th.BlockTimeTest)
+	assert.Equal(t, types.Uint64(10), d.GetChainHead()[0].Height)
+	status := d.RunSuccess("mining", "status").ReadStdout()
+	assert.Contains(t, status, "Active:     false")
+	assert.Contains(t, status, "Until:      none")
+
+	d.RunFail("chain head is already at height 10", "mining", "start", "--until", "10")
+}
+
+func TestMiningStartStop(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	status := d.MiningStatus()
+	assert.False(t, status.Active)
+	assert.Equal(t, fixtures.TestMiners[0], status.Miner.String())
+
+	d.MiningStart()
+	assert.True(t, d.MiningStatus().Active)
+	d.RunFail("already mining", "mining", "start")
+
+	// The worker keeps producing tipsets until stopped.
+	require.NoError(t, th.WaitForIt(100, th.BlockTimeTest, func() (bool, error) {
+		return d.GetChainHead()[0].Height >= 3, nil
+	}))
+
+	d.MiningStop()
+	assert.False(t, d.MiningStatus().Active)
+	stopped := d.GetChainHead()[0].Height
+	time.Sleep(5 * th.BlockTimeTest)
+	assert.Equal(t, stopped, d.GetChainHead()[0].Height)
+
+	// Mining can be started again after a stop.
+	d.MiningStart()
+	defer d.MiningStop()
+	require.NoError(t, th.WaitForIt(100, th.BlockTimeTest, func() (bool, error) {
+		return d.GetChainHead()[0].Height > stopped, nil
+	}))
+}
+
+func TestMiningStatusReportsStopHeight(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.RunSuccess("mining", "start", "--until", "1000")
+	defer d.RunSuccess("mining", "stop")
+
+	status := d.RunSuccess("mining", "status").ReadStdout()
+	assert.Contains(t, status, "Until:      1000")
+}
+
+func TestMiningMinPeers(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := makeTestDaemonWithMinerAndStart(t)
+	defer d.ShutdownSuccess()
+
+	d.ConfigSet("mining.min_peers", "1")
+	start := d.RunSuccess("mining", "start").ReadStdout()
+	defer d.RunSuccess("mining", "stop")
+	assert.Contains(t, start, "paused: connected to 0 peers, mining.min_peers is 1")
+
+	// Without enough peers no blocks are mined.
+	time.Sleep(5 * th.BlockTimeTest)
+	assert.Equal(t, types.Uint64(0), d.GetChainHead()[0].Height)
+	status := d.RunSuccess("mining", "status").ReadStdout()
+	assert.Contains(t, status, "Active:     true")
+	assert.Contains(t, status, "Paused:     connected to 0 peers, mining.min_peers is 1")
+
+	peer := th.NewDaemon(t).Start()
+	defer peer.ShutdownSuccess()
+	d.ConnectSuccess(peer)
+
+	require.NoError(t, th.WaitForIt(100, th.BlockTimeTest, func() (bool, error) {
+		return d.GetChainHead()[0].Height > 0, nil
+	}))
+	status = d.RunSuccess("mining", "status").ReadStdout()
+	assert.Contains(t, status, "Paused:     no")
+}
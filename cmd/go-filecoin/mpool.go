@@ -1,16 +1,23 @@
 package commands
 
 import (
+	"context"
 	"encoding/base64"
 	"fmt"
 	"io"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+	files "github.com/ipfs/go-ipfs-files"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 )
 
@@ -19,18 +26,33 @@ var mpoolCmd = &cmds.Command{
 		Tagline: "Manage the message pool",
 	},
 	Subcommands: map[string]*cmds.Command{
-		"ls":   mpoolLsCmd,
-		"show": mpoolShowCmd,
-		"rm":   mpoolRemoveCmd,
+		"check-conflicts": mpoolCheckConflictsCmd,
+		"import":          mpoolImportCmd,
+		"ls":              mpoolLsCmd,
+		"show":            mpoolShowCmd,
+		"rm":              mpoolRemoveCmd,
+		"wait-for-count":  mpoolWaitForCountCmd,
 	},
 }
 
 var mpoolLsCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "View the pool of outstanding messages",
+		ShortDescription: `
+Lists the cids of the pending messages, ordered by sender and, within each
+sender, by nonce. With --enc=json the messages themselves are listed, with
+their sender, recipient, nonce, value, method and gas.
+`,
 	},
 	Options: []cmdkit.Option{
-		cmdkit.UintOption("wait-for-count", "Block until this number of messages are in the pool").WithDefault(0),
+		cmdkit.UintOption("wait-for-count", "Deprecated: use mpool wait-for-count. Block until this number of messages are in the pool").WithDefault(0),
+	},
+	// PreRun runs on the client, so the warning goes to the user's stderr.
+	PreRun: func(req *cmds.Request, env cmds.Environment) error {
+		if messageCount, _ := req.Options["wait-for-count"].(uint); messageCount > 0 {
+			_, _ = fmt.Fprintln(os.Stderr, "mpool ls --wait-for-count is deprecated, use mpool wait-for-count instead")
+		}
+		return nil
 	},
 	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
 		messageCount, _ := req.Options["wait-for-count"].(uint)
@@ -57,6 +79,45 @@ var mpoolLsCmd = &cmds.Command{
 	},
 }
 
+var mpoolWaitForCountCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Wait until the pool holds a number of messages",
+		ShortDescription: `
+Blocks until at least the given number of messages are pending, then lists
+them like mpool ls. Fails if the timeout passes first.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("count", true, false, "Number of messages to wait for"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("timeout", "Maximum time to wait for the messages. e.g., 300ms, 1.5h, 2h45m.").WithDefault("10m"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		messageCount, err := strconv.ParseUint(req.Arguments[0], 10, 32)
+		if err != nil {
+			return errors.Wrap(err, "invalid count")
+		}
+
+		timeoutDuration, err := time.ParseDuration(req.Options["timeout"].(string))
+		if err != nil {
+			return errors.Wrap(err, "Invalid timeout string")
+		}
+
+		ctx, cancel := context.WithTimeout(req.Context, timeoutDuration)
+		defer cancel()
+
+		pending, err := GetPorcelainAPI(env).MessagePoolWait(ctx, uint(messageCount))
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(pending)
+	},
+	Type:     []*types.SignedMessage{},
+	Encoders: mpoolLsCmd.Encoders,
+}
+
 var mpoolShowCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Show content of an outstanding message",
@@ -109,6 +170,13 @@ Signature: %s
 var mpoolRemoveCmd = &cmds.Command{
 	Helptext: cmdkit.HelpText{
 		Tagline: "Delete a message from the message pool",
+		ShortDescription: `
+Removes a pending message from the message pool, e.g. one whose gas price is
+too low to ever be mined. The pending messages of the same sender with higher
+nonces cannot be mined without it, so they are removed as well. Prints the
+cids of all removed messages. Messages this node sent also remain in its
+outbox queue, see 'go-filecoin outbox clear'.
+`,
 	},
 	Arguments: []cmdkit.Argument{
 		cmdkit.StringArg("cid", true, false, "The CID of the message to delete"),
@@ -119,8 +187,112 @@ var mpoolRemoveCmd = &cmds.Command{
 			return errors.Wrap(err, "invalid message cid")
 		}
 
-		GetPorcelainAPI(env).MessagePoolRemove(msgCid)
+		removed, err := GetPorcelainAPI(env).MessagePoolEvict(msgCid)
+		if err != nil {
+			return err
+		}
+		return re.Emit(removed)
+	},
+	Type: []cid.Cid{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, removed []cid.Cid) error {
+			sw := NewSilentWriter(w)
+			for _, c := range removed {
+				sw.Println(c)
+			}
+			return sw.Error()
+		}),
+	},
+}
 
-		return nil
+var mpoolImportCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add pre-signed messages from a file to the message pool",
+		ShortDescription: `
+Reads JSON encoded signed messages, one per line, and adds each one that passes
+validation to the message pool. Messages that fail to decode or validate are
+rejected individually and do not stop the import. Imported messages are not
+broadcast to the network.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.FileArg("file", true, false, "File of newline delimited signed messages").EnableStdin(),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		iter := req.Files.Entries()
+		if !iter.Next() {
+			return fmt.Errorf("no file given: %s", iter.Err())
+		}
+
+		fi, ok := iter.Node().(files.File)
+		if !ok {
+			return fmt.Errorf("given file was not a files.File")
+		}
+		defer func() { _ = fi.Close() }()
+
+		result, err := GetPorcelainAPI(env).MessagePoolImport(req.Context, fi)
+		if err != nil {
+			return err
+		}
+		return re.Emit(result)
+	},
+	Type: &porcelain.MessagePoolImportResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, result *porcelain.MessagePoolImportResult) error {
+			sw := NewSilentWriter(w)
+			for _, reason := range result.Errors {
+				sw.Printf("rejected %s\n", reason)
+			}
+			sw.Printf("accepted: %d, rejected: %d\n", result.Accepted, result.Rejected)
+			return sw.Error()
+		}),
+	},
+}
+
+var mpoolCheckConflictsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Find pending messages that reuse the nonce of another message",
+		ShortDescription: `
+Lists each sender and nonce used by a pending message and by a different
+pending message or a different message mined in the most recent tipsets. Only
+one message per sender and nonce can ever be executed, so a conflict usually
+means a wallet reused a nonce.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.Uint64Option("depth", "Number of tipsets below the head to check for mined messages").WithDefault(uint64(100)),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		depth, _ := req.Options["depth"].(uint64)
+		conflicts, err := GetPorcelainAPI(env).MessagePoolConflicts(req.Context, depth)
+		if err != nil {
+			return err
+		}
+		return re.Emit(conflicts)
+	},
+	Type: []message.NonceConflict{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, conflicts []message.NonceConflict) error {
+			sw := NewSilentWriter(w)
+			if len(conflicts) == 0 {
+				sw.Println("no conflicts")
+				return sw.Error()
+			}
+			cidStrings := func(cids []cid.Cid) string {
+				strs := make([]string, len(cids))
+				for i, c := range cids {
+					strs[i] = c.String()
+				}
+				return strings.Join(strs, ", ")
+			}
+			for _, conflict := range conflicts {
+				sw.Printf("%s nonce %d: pending %s", conflict.From, conflict.Nonce, cidStrings(conflict.Pending))
+				if len(conflict.Mined) > 0 {
+					sw.Printf("; mined %s", cidStrings(conflict.Mined))
+				}
+				sw.Println()
+			}
+			return sw.Error()
+		}),
 	},
 }
@@ -1,16 +1,25 @@
 package commands_test
 
 import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"strings"
 	"sync"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/fixtures"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 func TestMpoolLs(t *testing.T) {
@@ -49,6 +58,44 @@ func TestMpoolLs(t *testing.T) {
 		assert.Equal(t, 2, len(cids))
 	})
 
+	t.Run("lists message details in nonce order", func(t *testing.T) {
+		d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+		defer d.ShutdownSuccess()
+
+		var cids []string
+		for i := 0; i < 3; i++ {
+			cids = append(cids, sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[2]).ReadStdoutTrimNewlines())
+		}
+
+		pending := d.MpoolLs()
+		require.Len(t, pending, 3)
+		for i, smsg := range pending {
+			c, err := smsg.Cid()
+			require.NoError(t, err)
+			assert.Equal(t, cids[i], c.String())
+
+			assert.Equal(t, fixtures.TestAddresses[0], smsg.Message.From.String())
+			assert.Equal(t, fixtures.TestAddresses[2], smsg.Message.To.String())
+			assert.Equal(t, types.NewAttoFILFromFIL(10), smsg.Message.Value)
+			assert.Equal(t, types.NewGasPrice(1), smsg.Message.GasPrice)
+			assert.Equal(t, types.NewGasUnits(300), smsg.Message.GasLimit)
+			if i > 0 {
+				assert.Equal(t, pending[i-1].Message.CallSeqNum+1, smsg.Message.CallSeqNum)
+			}
+		}
+	})
+
+	t.Run("waiting longer than the command timeout fails", func(t *testing.T) {
+		d := th.NewDaemon(t).Start()
+		defer d.ShutdownSuccess()
+
+		out := d.RunWithOpts(th.RunOpts{Timeout: time.Second}, "mpool", "ls", "--wait-for-count=1")
+		_, err := out.Status()
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "after 1s")
+		assert.Contains(t, err.Error(), "mpool ls --wait-for-count=1")
+	})
+
 	t.Run("wait for enough messages", func(t *testing.T) {
 
 		d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
@@ -78,6 +125,52 @@ func TestMpoolLs(t *testing.T) {
 	})
 }
 
+func TestMpoolWaitForCount(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// sendMessage does not assert the output, so it may run off the test goroutine.
+	sendMessage := func(d *th.TestDaemon, from string, to string) *th.CmdOutput {
+		return d.Run("message", "send",
+			"--from", from,
+			"--gas-price", "1", "--gas-limit", "300",
+			"--value=10", to,
+		)
+	}
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+	defer d.ShutdownSuccess()
+
+	t.Run("times out when the count is not reached", func(t *testing.T) {
+		err := d.WaitForMpoolCount(1, time.Second)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "0 of 1 messages in pool")
+	})
+
+	t.Run("returns once the count is reached", func(t *testing.T) {
+		sent := make(chan *th.CmdOutput, 2)
+		go func() {
+			sent <- sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[1])
+			sent <- sendMessage(d, fixtures.TestAddresses[0], fixtures.TestAddresses[1])
+		}()
+
+		require.NoError(t, d.WaitForMpoolCount(2, time.Minute))
+		(<-sent).AssertSuccess()
+		(<-sent).AssertSuccess()
+
+		out := d.RunSuccess("mpool", "wait-for-count", "2")
+		assert.Len(t, strings.Split(strings.Trim(out.ReadStdout(), "\n"), "\n"), 2)
+	})
+
+	t.Run("mpool ls --wait-for-count warns that it is deprecated", func(t *testing.T) {
+		out := d.RunSuccess("mpool", "ls", "--wait-for-count=2")
+		assert.Contains(t, out.ReadStderr(), "--wait-for-count is deprecated")
+	})
+
+	t.Run("rejects an invalid count", func(t *testing.T) {
+		d.RunFail("invalid count", "mpool", "wait-for-count", "two")
+	})
+}
+
 func TestMpoolShow(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -130,4 +223,145 @@ func TestMpoolRm(t *testing.T) {
 		out := d.RunSuccess("mpool", "ls").ReadStdoutTrimNewlines()
 		assert.Equal(t, "", out)
 	})
+
+	t.Run("removes the messages with later nonces too", func(t *testing.T) {
+		d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+		defer d.ShutdownSuccess()
+
+		var cids []cid.Cid
+		for i := 0; i < 2; i++ {
+			out := d.RunSuccess("message", "send",
+				"--from", fixtures.TestAddresses[0],
+				"--gas-price", "1", "--gas-limit", "300",
+				"--value=10", fixtures.TestAddresses[2],
+			).ReadStdoutTrimNewlines()
+			c, err := cid.Decode(out)
+			require.NoError(t, err)
+			cids = append(cids, c)
+		}
+		require.Len(t, d.MpoolLs(), 2)
+
+		// The second message cannot be mined without the first.
+		assert.Equal(t, cids, d.MpoolRm(cids[0]))
+		assert.Empty(t, d.MpoolLs())
+	})
+
+	t.Run("fails for a message that is not pending", func(t *testing.T) {
+		d := th.NewDaemon(t).Start()
+		defer d.ShutdownSuccess()
+
+		const c = "QmR8BauakNcBa3RbE4nbQu76PDiJgoQgz8AJdhJuiU4TAw"
+		d.RunFail("not found in pool", "mpool", "rm", c)
+	})
+}
+
+func TestMpoolImport(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[0])).Start()
+	defer d.ShutdownSuccess()
+
+	// Sign the messages with the daemon's funded key.
+	var exported struct {
+		KeyInfo []*types.KeyInfo
+	}
+	out := d.RunSuccess("wallet", "export", fixtures.TestAddresses[0], "--enc=json").ReadStdout()
+	require.NoError(t, json.Unmarshal([]byte(out), &exported))
+	require.Len(t, exported.KeyInfo, 1)
+	signer := types.NewMockSigner([]types.KeyInfo{*exported.KeyInfo[0]})
+
+	from, err := address.NewFromString(fixtures.TestAddresses[0])
+	require.NoError(t, err)
+	to, err := address.NewFromString(fixtures.TestAddresses[2])
+	require.NoError(t, err)
+
+	const count = 100
+	var input bytes.Buffer
+	var signed []*types.SignedMessage
+	for i := 0; i < count; i++ {
+		msg := types.NewMeteredMessage(from, to, uint64(i), types.NewAttoFILFromFIL(1), types.SendMethodID, nil, types.NewGasPrice(1), types.NewGasUnits(300))
+		smsg, err := types.NewSignedMessage(*msg, &signer)
+		require.NoError(t, err)
+		signed = append(signed, smsg)
+
+		raw, err := json.Marshal(smsg)
+		require.NoError(t, err)
+		input.Write(raw)
+		input.WriteString("\n")
+	}
+
+	// A message whose signature doesn't match and a line that isn't a message
+	// are each rejected without stopping the import.
+	forged := *signed[0]
+	forged.Message.Value = types.NewAttoFILFromFIL(1000)
+	raw, err := json.Marshal(&forged)
+	require.NoError(t, err)
+	input.Write(raw)
+	input.WriteString("\nnot a message\n")
+
+	dir, err := ioutil.TempDir("", "mpool-import")
+	require.NoError(t, err)
+	defer func() { require.NoError(t, os.RemoveAll(dir)) }()
+	path := filepath.Join(dir, "messages.ndjson")
+	require.NoError(t, ioutil.WriteFile(path, input.Bytes(), 0644))
+
+	accepted, rejected := d.MpoolImport(path)
+	assert.Equal(t, count, accepted)
+	assert.Equal(t, 2, rejected)
+
+	pending := strings.Split(d.RunSuccess("mpool", "ls").ReadStdoutTrimNewlines(), "\n")
+	require.Len(t, pending, count)
+	inPool := make(map[string]bool)
+	for _, c := range pending {
+		inPool[c] = true
+	}
+	for _, smsg := range signed {
+		c, err := smsg.Cid()
+		require.NoError(t, err)
+		assert.True(t, inPool[c.String()], "message %s is not pending", c)
+	}
+}
+
+func TestMpoolCheckConflicts(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	// Two daemons with the same key each send a different message with the
+	// same nonce while disconnected, as a wallet reusing nonces would.
+	d1 := makeTestDaemonWithMinerAndStart(t)
+	defer d1.ShutdownSuccess()
+	d2 := makeTestDaemonWithMinerAndStart(t)
+	defer d2.ShutdownSuccess()
+
+	send := func(d *th.TestDaemon, to string) cid.Cid {
+		out := d.RunSuccess("message", "send",
+			"--from", fixtures.TestAddresses[0],
+			"--gas-price", "1", "--gas-limit", "300",
+			"--value", "10",
+			to,
+		)
+		c, err := cid.Decode(strings.TrimSpace(out.ReadStdout()))
+		require.NoError(t, err)
+		return c
+	}
+	mined := send(d1, fixtures.TestAddresses[1])
+	stale := send(d2, fixtures.TestAddresses[2])
+	assert.Empty(t, d2.MpoolConflicts())
+
+	// d1 mines its message and d2 syncs the block, leaving its own message
+	// pending with a nonce that is already used on chain.
+	d1.ConnectSuccess(d2)
+	d1.MineAndPropagate(10*time.Second, d2)
+	require.Equal(t, d1.HeadKey(), d2.HeadKey())
+
+	conflicts := d2.MpoolConflicts()
+	require.Len(t, conflicts, 1)
+	assert.Equal(t, fixtures.TestAddresses[0], conflicts[0].From.String())
+	assert.Equal(t, uint64(0), conflicts[0].Nonce)
+	assert.Equal(t, []cid.Cid{stale}, conflicts[0].Pending)
+	assert.Equal(t, []cid.Cid{mined}, conflicts[0].Mined)
+
+	assert.Empty(t, d1.MpoolConflicts())
+	out := d2.RunSuccess("mpool", "check-conflicts").ReadStdout()
+	assert.Contains(t, out, stale.String())
+	assert.Contains(t, out, mined.String())
 }
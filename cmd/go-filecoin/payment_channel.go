@@ -25,6 +25,7 @@ var paymentChannelCmd = &cmds.Command{
 		"ls":      lsCmd,
 		"reclaim": reclaimCmd,
 		"redeem":  redeemCmd,
+		"update":  updateCmd,
 		"voucher": voucherCmd,
 	},
 }
@@ -594,6 +595,117 @@ var extendCmd = &cmds.Command{
 	},
 }
 
+// UpdateResult type returned from Update
+type UpdateResult struct {
+	Cid     cid.Cid
+	GasUsed types.GasUnits
+	Preview bool
+}
+
+var updateCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Add funds to a given channel",
+		ShortDescription: `Adds funds to a payment channel of the sender without changing when it expires. Use
+'go-filecoin paych extend' to also extend its lifetime, or to add funds to a cancelled channel.`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("channel", true, false, "Id of channel to add funds to"),
+		cmdkit.StringArg("amount", true, false, "Amount in FIL to add to the channel"),
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("from", "Address of the channel creator"),
+		priceOption,
+		limitOption,
+		previewOption,
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		fromAddr, err := fromAddrOrDefault(req, env)
+		if err != nil {
+			return err
+		}
+
+		channel, ok := types.NewChannelIDFromString(req.Arguments[0], 10)
+		if !ok {
+			return fmt.Errorf("invalid channel id")
+		}
+
+		amount, ok := types.NewAttoFILFromFILString(req.Arguments[1])
+		if !ok {
+			return ErrInvalidAmount
+		}
+
+		gasPrice, gasLimit, preview, err := parseGasOptions(req)
+		if err != nil {
+			return err
+		}
+
+		// The payment broker only adds funds when extending a channel, so
+		// extend it to the height at which it already expires. Extending
+		// also sets the agreed eol, so a cancelled channel, which expires
+		// before its agreed eol, can't be updated without losing it.
+		channels, err := GetPorcelainAPI(env).PaymentChannelLs(req.Context, fromAddr, fromAddr)
+		if err != nil {
+			return err
+		}
+		pc, ok := channels[channel.String()]
+		if !ok {
+			return fmt.Errorf("%s has no payment channel %s", fromAddr, channel)
+		}
+		if !pc.Eol.Equal(pc.AgreedEol) {
+			return fmt.Errorf("payment channel %s expires at %s, before its agreed eol %s; use paych extend", channel, pc.Eol, pc.AgreedEol)
+		}
+
+		if preview {
+			usedGas, err := GetPorcelainAPI(env).MessagePreview(
+				req.Context,
+				fromAddr,
+				address.PaymentBrokerAddress,
+				paymentbroker.Extend,
+				channel, pc.Eol,
+			)
+			if err != nil {
+				return err
+			}
+			return re.Emit(&UpdateResult{
+				Cid:     cid.Cid{},
+				GasUsed: usedGas,
+				Preview: true,
+			})
+		}
+
+		c, _, err := GetPorcelainAPI(env).MessageSend(
+			req.Context,
+			fromAddr,
+			address.PaymentBrokerAddress,
+			amount,
+			gasPrice,
+			gasLimit,
+			paymentbroker.Extend,
+			channel, pc.Eol,
+		)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(&UpdateResult{
+			Cid:     c,
+			GasUsed: types.NewGasUnits(0),
+			Preview: false,
+		})
+	},
+	Type: &UpdateResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *UpdateResult) error {
+			if res.Preview {
+				output := strconv.FormatUint(uint64(res.GasUsed), 10)
+				_, err := w.Write([]byte(output))
+				return err
+			}
+			return PrintString(w, res.Cid)
+		}),
+	},
+}
+
 // CancelResult type returned from Cancel
 type CancelResult struct {
 	Cid     cid.Cid
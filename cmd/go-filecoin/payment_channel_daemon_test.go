@@ -9,6 +9,8 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/fixtures"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
@@ -471,6 +473,84 @@ func TestPaymentChannelCancelSuccess(t *testing.T) {
 	assert.Equal(t, types.NewBlockHeight(10004), channel.Eol)
 }
 
+func TestPaymentChannelCreateUpdateRedeem(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	payer := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer payer.ShutdownSuccess()
+
+	target := th.NewDaemon(t,
+		th.KeyFile(fixtures.KeyFilePaths()[1]),
+		th.DefaultAddress(fixtures.TestAddresses[1]),
+	).Start()
+	defer target.ShutdownSuccess()
+
+	payer.RunSuccess("mining", "start")
+	payer.ConnectSuccess(target)
+
+	payerAddr, targetAddr := fixtures.TestAddresses[0], fixtures.TestAddresses[1]
+	channelOf := func(chanid *types.ChannelID) *paymentbroker.PaymentChannel {
+		var channels map[string]*paymentbroker.PaymentChannel
+		th.RunSuccessJSON(payer, &channels, "paych", "ls", "--from", payerAddr)
+		require.Contains(t, channels, chanid.String())
+		return channels[chanid.String()]
+	}
+
+	channelAmount := types.NewAttoFILFromFIL(1000)
+	chanid := payer.CreatePaymentChannel(payerAddr, targetAddr, &channelAmount)
+	channel := channelOf(chanid)
+	assert.Equal(t, channelAmount, channel.Amount)
+	assert.Equal(t, targetAddr, channel.Target.String())
+	eol, agreedEol := channel.Eol, channel.AgreedEol
+
+	// update adds funds but leaves the channel expiry alone
+	updateAmount := types.NewAttoFILFromFIL(50)
+	mcid := payer.RunSuccess("paych", "update",
+		"--from", payerAddr,
+		"--gas-price", "1", "--gas-limit", "300",
+		chanid.String(), updateAmount.String(),
+	).ReadStdoutTrimNewlines()
+	payer.WaitForMessageRequireSuccess(mustDecodeCid(mcid))
+	channel = channelOf(chanid)
+	assert.Equal(t, channelAmount.Add(updateAmount), channel.Amount)
+	assert.Equal(t, eol, channel.Eol)
+	assert.Equal(t, agreedEol, channel.AgreedEol)
+
+	// the target redeems a voucher and is paid its amount, less gas
+	voucherAmount := types.NewAttoFILFromFIL(10)
+	voucher := payer.RunSuccess("paych", "voucher",
+		"--from", payerAddr,
+		chanid.String(), voucherAmount.String(),
+	).ReadStdoutTrimNewlines()
+
+	targetBefore := target.WalletBalance(targetAddr)
+	mcid = target.RunSuccess("paych", "redeem",
+		"--from", targetAddr,
+		"--gas-price", "1", "--gas-limit", "300",
+		voucher,
+	).ReadStdoutTrimNewlines()
+	rcpt := target.WaitForMessageRequireSuccess(mustDecodeCid(mcid))
+
+	assert.Equal(t, targetBefore.Add(voucherAmount).Sub(rcpt.GasAttoFIL), *target.WalletBalance(targetAddr))
+	assert.Equal(t, voucherAmount, channelOf(chanid).AmountRedeemed)
+
+	payer.RunFail("has no payment channel", "paych", "update", "--from", payerAddr, "999", "1")
+
+	// a cancelled channel can't be updated without losing its agreed eol
+	cancelled := payer.CreatePaymentChannel(payerAddr, targetAddr, &channelAmount)
+	mcid = payer.RunSuccess("paych", "cancel",
+		"--from", payerAddr,
+		"--gas-price", "1", "--gas-limit", "300",
+		cancelled.String(),
+	).ReadStdoutTrimNewlines()
+	payer.WaitForMessageRequireSuccess(mustDecodeCid(mcid))
+	payer.RunFail("before its agreed eol", "paych", "update", "--from", payerAddr, cancelled.String(), "1")
+}
+
 type paychResources struct {
 	t *testing.T
 
@@ -1,6 +1,10 @@
 package commands
 
 import (
+	"fmt"
+	"io"
+	"os"
+
 	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
@@ -13,6 +17,7 @@ var retrievalClientCmd = &cmds.Command{
 		Tagline: "Manage retrieval client operations",
 	},
 	Subcommands: map[string]*cmds.Command{
+		"retrieve":       clientRetrieveCmd,
 		"retrieve-piece": clientRetrievePieceCmd,
 	},
 }
@@ -49,3 +54,70 @@ var clientRetrievePieceCmd = &cmds.Command{
 		return re.Emit(readCloser)
 	},
 }
+
+// RetrieveResult is the type returned when retrieving a piece to a file.
+type RetrieveResult struct {
+	Path string
+	Size int64
+}
+
+var clientRetrieveCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Retrieve piece data stored by a miner into a file",
+		ShortDescription: `
+Retrieves the piece with the given cid from <miner> and writes its bytes to
+<out-file>. Fails with "miner does not have the piece" when the miner does not
+hold the piece, in which case no file is written.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("miner", true, false, "Retrieval miner actor address"),
+		cmdkit.StringArg("cid", true, false, "Content identifier of piece to read"),
+		cmdkit.StringArg("out-file", true, false, "File to write the piece data to"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		minerAddr, err := address.NewFromString(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		pieceCID, err := cid.Decode(req.Arguments[1])
+		if err != nil {
+			return err
+		}
+
+		mpid, err := GetPorcelainAPI(env).MinerGetPeerID(req.Context, minerAddr)
+		if err != nil {
+			return err
+		}
+
+		readCloser, err := GetRetrievalAPI(env).RetrievePiece(req.Context, pieceCID, mpid, minerAddr)
+		if err != nil {
+			return err
+		}
+		defer func() { _ = readCloser.Close() }()
+
+		f, err := os.Create(req.Arguments[2])
+		if err != nil {
+			return err
+		}
+		defer func() { _ = f.Close() }()
+
+		size, err := io.Copy(f, readCloser)
+		if err != nil {
+			return err
+		}
+		if err := f.Close(); err != nil {
+			return err
+		}
+
+		return re.Emit(&RetrieveResult{Path: req.Arguments[2], Size: size})
+	},
+	Type: RetrieveResult{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, res *RetrieveResult) error {
+			_, err := fmt.Fprintf(w, "wrote %d bytes to %s\n", res.Size, res.Path)
+			return err
+		}),
+	},
+}
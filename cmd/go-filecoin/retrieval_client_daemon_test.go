@@ -3,13 +3,20 @@ package commands_test
 import (
 	"context"
 	"math/big"
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/ipfs/go-ipfs-files"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/fixtures"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/filecoin-project/go-filecoin/tools/fast"
 	"github.com/filecoin-project/go-filecoin/tools/fast/fastesting"
@@ -50,3 +57,66 @@ func TestSelfDialRetrievalGoodError(t *testing.T) {
 	assert.Error(t, err)
 	fastesting.AssertStdErrContains(t, env.GenesisMiner, "attempting to retrieve piece from self")
 }
+
+func TestRetrieveData(t *testing.T) {
+	t.Skip("Long term solution: #3642")
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t, th.KeyFile(fixtures.KeyFilePaths()[2]), th.DefaultAddress(fixtures.TestAddresses[2])).Start()
+	defer client.ShutdownSuccess()
+
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+
+	miner.ConnectSuccess(client)
+
+	addAskCid := miner.MinerSetPrice(fixtures.TestMiners[0], fixtures.TestAddresses[0], "20", "100")
+	client.WaitForMessageRequireSuccess(addAskCid)
+
+	data := "HODLHODLHODL"
+	dataCid := client.RunWithStdin(strings.NewReader(data), "client", "import").ReadStdoutTrimNewlines()
+	out := client.RunSuccess("client", "propose-storage-deal", fixtures.TestMiners[0], dataCid, "0", "100").ReadStdoutTrimNewlines()
+	splitOnSpace := strings.Split(out, " ")
+	dealCid := splitOnSpace[len(splitOnSpace)-1]
+
+	miner.RunSuccess("mining", "seal-now")
+	require.NoError(t, th.WaitForIt(300, time.Second, func() (bool, error) {
+		out := client.RunSuccess("client", "query-storage-deal", dealCid).ReadStdout()
+		return strings.Contains(out, "complete"), nil
+	}))
+
+	assert.Equal(t, data, string(client.RetrieveData(miner, dataCid)))
+}
+
+func TestRetrieveDataNotHeldByMiner(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	miner := th.NewDaemon(t,
+		th.WithMiner(fixtures.TestMiners[0]),
+		th.KeyFile(fixtures.KeyFilePaths()[0]),
+		th.DefaultAddress(fixtures.TestAddresses[0]),
+	).Start()
+	defer miner.ShutdownSuccess()
+
+	client := th.NewDaemon(t).Start()
+	defer client.ShutdownSuccess()
+
+	miner.ConnectSuccess(client)
+	miner.RunSuccess("mining start")
+	miner.UpdatePeerID()
+	miner.RunSuccess("mining stop")
+	miner.MustHaveChainHeadBy(10*time.Second, []*th.TestDaemon{client})
+
+	unknownCid := types.NewCidForTestGetter()().String()
+	outFile := filepath.Join(client.RepoDir(), "retrieved")
+	client.RunFail("miner does not have the piece", "retrieval-client", "retrieve", fixtures.TestMiners[0], unknownCid, outFile)
+	_, err := os.Stat(outFile)
+	assert.True(t, os.IsNotExist(err))
+}
@@ -3,13 +3,16 @@ package commands
 import (
 	"fmt"
 	"io"
+	"sort"
 	"strings"
 
 	"github.com/ipfs/go-ipfs-cmdkit"
 	"github.com/ipfs/go-ipfs-cmds"
+	"github.com/libp2p/go-libp2p-core/metrics"
 	"github.com/libp2p/go-libp2p-core/peer"
 	ma "github.com/multiformats/go-multiaddr"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
 )
 
@@ -24,8 +27,12 @@ libp2p peers on the internet.
 `,
 	},
 	Subcommands: map[string]*cmds.Command{
-		"connect": swarmConnectCmd,
-		"peers":   swarmPeersCmd,
+		"bandwidth":  swarmBandwidthCmd,
+		"connect":    swarmConnectCmd,
+		"disconnect": swarmDisconnectCmd,
+		"hello":      swarmHelloCmd,
+		"peers":      swarmPeersCmd,
+		"protocols":  swarmProtocolsCmd,
 	},
 }
 
@@ -34,6 +41,9 @@ var swarmPeersCmd = &cmds.Command{
 		Tagline: "List peers with open connections.",
 		ShortDescription: `
 'go-filecoin swarm peers' lists the set of peers this node is connected to.
+With --verbose it also shows who opened each connection (inbound or
+outbound), the latency to the peer and the protocols of its open streams.
+The latency is n/a until it has been measured.
 `,
 	},
 	Options: []cmdkit.Option{
@@ -63,6 +73,9 @@ var swarmPeersCmd = &cmds.Command{
 				} else {
 					fmt.Fprintf(w, "%s%s", info.Addr, ids) // nolint: errcheck
 				}
+				if info.Direction != "" {
+					fmt.Fprintf(w, " %s", info.Direction) // nolint: errcheck
+				}
 				if info.Latency != "" {
 					fmt.Fprintf(w, " %s", info.Latency) // nolint: errcheck
 				}
@@ -122,3 +135,205 @@ go-filecoin swarm connect /ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUE
 		}),
 	},
 }
+
+var swarmDisconnectCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Close the connections to a given peer.",
+		ShortDescription: `
+'go-filecoin swarm disconnect' closes all connections to a peer. It fails if
+the node is not connected to the peer.
+
+The address format is a multiaddr ending in the peer id:
+
+go-filecoin swarm disconnect /ip4/104.131.131.82/tcp/4001/ipfs/QmaCpDMGvV2BGHeYERUEnRQAwe3N8SzbUtfsmvsqQLuvuJ
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("address", true, false, "Address of peer to disconnect from."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		pid, err := GetPorcelainAPI(env).NetworkDisconnect(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(pid)
+	},
+	Type: peer.ID(""),
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, result peer.ID) error {
+			fmt.Fprintf(w, "disconnect %s success\n", result.Pretty()) // nolint: errcheck
+			return nil
+		}),
+	},
+}
+
+var swarmHelloCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Perform the hello handshake with a peer.",
+		ShortDescription: `
+'go-filecoin swarm hello' requests a hello message from a connected peer and
+prints what each side reported: the genesis block, the head tipset, its height
+and its parent weight. Peers whose genesis blocks differ disconnect from each
+other after the handshake and never sync. If the peer is connected but does not
+answer, the last hello message it sent is shown instead.
+`,
+	},
+	Arguments: []cmdkit.Argument{
+		cmdkit.StringArg("peerID", true, false, "ID of the peer to say hello to."),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		pid, err := peer.IDB58Decode(req.Arguments[0])
+		if err != nil {
+			return err
+		}
+
+		report, err := GetPorcelainAPI(env).NetworkHelloReport(req.Context, pid)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(report)
+	},
+	Type: porcelain.HelloReport{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, report *porcelain.HelloReport) error {
+			sw := NewSilentWriter(w)
+			sw.Printf("Peer:          %s\n", report.Peer.Pretty())
+			if report.Replayed {
+				sw.Println("(peer did not answer, showing its last hello message)")
+			}
+			writeHelloSide(sw, "Ours", &report.Ours)
+			writeHelloSide(sw, "Theirs", &report.Theirs)
+			sw.Printf("Genesis match: %t\n", report.GenesisMatch)
+			if !report.GenesisMatch {
+				sw.Println("The peers are on different networks and will not sync.")
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var swarmProtocolsCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "List the libp2p protocols the node handles.",
+		ShortDescription: `
+'go-filecoin swarm protocols' lists the ids of the protocols this node has
+handlers for, with their versions, and any core protocol (hello, kad, bitswap,
+graphsync, identify, ping) it does not handle as disabled. Two nodes can only
+exchange data over a protocol id both of them handle.
+
+With --peer, also shows which of the ids a connected peer announced it
+supports, i.e. which of them a stream to that peer can negotiate.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.StringOption("peer", "ID of a peer to compare the protocols with"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		var pid peer.ID
+		if o, ok := req.Options["peer"].(string); ok {
+			var err error
+			pid, err = peer.IDB58Decode(o)
+			if err != nil {
+				return err
+			}
+		}
+
+		protocols, err := GetPorcelainAPI(env).NetworkProtocols(pid)
+		if err != nil {
+			return err
+		}
+
+		return re.Emit(protocols)
+	},
+	Type: []porcelain.ProtocolInfo{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, protocols []porcelain.ProtocolInfo) error {
+			_, withPeer := req.Options["peer"].(string)
+			sw := NewSilentWriter(w)
+			for _, p := range protocols {
+				if !p.Enabled {
+					sw.Printf("%s (disabled)\n", p.Name)
+					continue
+				}
+				line := p.ID
+				if withPeer && p.PeerSupported {
+					line += " (peer supports)"
+				}
+				sw.Println(line)
+			}
+			return sw.Error()
+		}),
+	},
+}
+
+var swarmBandwidthCmd = &cmds.Command{
+	Helptext: cmdkit.HelpText{
+		Tagline: "Show how much data the node moved over the swarm.",
+		ShortDescription: `
+'go-filecoin swarm bandwidth' shows the bytes received and sent over the swarm
+since the node started or since the counters were last reset, and the current
+rates in bytes per second. --by-peer and --by-protocol break the totals down.
+
+With --reset, shows the totals by peer and protocol and then resets the
+counters, so that the next call shows only what was moved in between.
+`,
+	},
+	Options: []cmdkit.Option{
+		cmdkit.BoolOption("by-peer", "Also show the bandwidth used with each peer"),
+		cmdkit.BoolOption("by-protocol", "Also show the bandwidth used by each protocol"),
+		cmdkit.BoolOption("reset", "Reset the counters after showing them"),
+	},
+	Run: func(req *cmds.Request, re cmds.ResponseEmitter, env cmds.Environment) error {
+		if reset, _ := req.Options["reset"].(bool); reset {
+			return re.Emit(GetPorcelainAPI(env).NetworkResetBandwidth())
+		}
+
+		byPeer, _ := req.Options["by-peer"].(bool)
+		byProtocol, _ := req.Options["by-protocol"].(bool)
+		return re.Emit(GetPorcelainAPI(env).NetworkBandwidth(byPeer, byProtocol))
+	},
+	Type: net.BandwidthStats{},
+	Encoders: cmds.EncoderMap{
+		cmds.Text: cmds.MakeTypedEncoder(func(req *cmds.Request, w io.Writer, stats *net.BandwidthStats) error {
+			sw := NewSilentWriter(w)
+			writeBandwidth(sw, "Total", stats.Total)
+			writeBandwidthBreakdown(sw, "By peer", stats.ByPeer)
+			writeBandwidthBreakdown(sw, "By protocol", stats.ByProtocol)
+			return sw.Error()
+		}),
+	},
+}
+
+func writeBandwidth(sw *SilentWriter, name string, stats metrics.Stats) {
+	sw.Printf("%s: in %d B (%.1f B/s), out %d B (%.1f B/s)\n", name, stats.TotalIn, stats.RateIn, stats.TotalOut, stats.RateOut)
+}
+
+func writeBandwidthBreakdown(sw *SilentWriter, name string, stats map[string]metrics.Stats) {
+	if len(stats) == 0 {
+		return
+	}
+	keys := make([]string, 0, len(stats))
+	for k := range stats {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	sw.Printf("%s:\n", name)
+	for _, k := range keys {
+		writeBandwidth(sw, "  "+k, stats[k])
+	}
+}
+
+func writeHelloSide(sw *SilentWriter, name string, side *porcelain.HelloSide) {
+	weight := "unknown"
+	if side.ParentWeight != nil {
+		weight = fmt.Sprintf("%d", *side.ParentWeight)
+	}
+	sw.Printf("%s:\n", name)
+	sw.Printf("  Genesis:       %s\n", side.Genesis)
+	sw.Printf("  Head:          %s\n", side.Head)
+	sw.Printf("  Height:        %d\n", side.Height)
+	sw.Printf("  Parent weight: %s\n", weight)
+}
@@ -1,10 +1,18 @@
 package commands_test
 
 import (
+	"io/ioutil"
+	"os"
+	"strings"
 	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	gengen "github.com/filecoin-project/go-filecoin/tools/gengen/util"
 )
 
 func TestSwarmConnectPeersValid(t *testing.T) {
@@ -19,6 +27,91 @@ func TestSwarmConnectPeersValid(t *testing.T) {
 	d1.ConnectSuccess(d2)
 }
 
+func TestSwarmPeersVerbose(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	assert.Equal(t, 0, d1.PeerCount())
+
+	d1.ConnectSuccess(d2)
+	require.Equal(t, 1, d1.PeerCount())
+	require.Equal(t, 1, d2.PeerCount())
+
+	outbound := d1.Peers()[0]
+	assert.Equal(t, d2.GetID(), outbound.Peer)
+	assert.Equal(t, "outbound", outbound.Direction)
+	assert.NotEmpty(t, outbound.Latency)
+
+	inbound := d2.Peers()[0]
+	assert.Equal(t, d1.GetID(), inbound.Peer)
+	assert.Equal(t, "inbound", inbound.Direction)
+}
+
+func TestSwarmBandwidth(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	d1.ConnectSuccess(d2)
+	d1.Hello(d2.GetID())
+
+	before := d1.ResetBandwidth()
+	assert.NotZero(t, before.Total.TotalIn)
+	assert.NotZero(t, before.Total.TotalOut)
+	assert.Contains(t, before.ByPeer, d2.GetID())
+	assert.NotEmpty(t, before.ByProtocol)
+
+	after := d1.Bandwidth()
+	assert.True(t, after.Total.TotalOut < before.Total.TotalOut, "counters were not reset")
+
+	text := d1.RunSuccess("swarm", "bandwidth").ReadStdout()
+	assert.Contains(t, text, "Total: in ")
+}
+
+func TestSwarmConnectCluster(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	peersOf := func(d *th.TestDaemon) string {
+		return d.RunSuccess("swarm", "peers").ReadStdout()
+	}
+
+	t.Run("ring", func(t *testing.T) {
+		c := th.NewCluster(t, 4).ConnectRing()
+		defer c.Shutdown()
+
+		daemons := c.Daemons()
+		for i, d := range daemons {
+			peers := peersOf(d)
+			assert.Contains(t, peers, daemons[(i+1)%4].GetID())
+			assert.Contains(t, peers, daemons[(i+3)%4].GetID())
+		}
+	})
+
+	t.Run("full mesh", func(t *testing.T) {
+		c := th.NewCluster(t, 3).ConnectAll()
+		defer c.Shutdown()
+
+		require.Len(t, c.Followers(), 2)
+		for _, d := range c.Daemons() {
+			peers := peersOf(d)
+			for _, other := range c.Daemons() {
+				if other != d {
+					assert.Contains(t, peers, other.GetID())
+				}
+			}
+		}
+	})
+}
+
 func TestSwarmConnectPeersInvalid(t *testing.T) {
 	tf.IntegrationTest(t)
 
@@ -29,3 +122,90 @@ func TestSwarmConnectPeersInvalid(t *testing.T) {
 		"swarm connect /ip4/hello",
 	)
 }
+
+func TestSwarmDisconnect(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	d1.ConnectSuccess(d2)
+	d1.DisconnectSuccess(d2)
+
+	t.Run("fails when not connected", func(t *testing.T) {
+		d1.RunFail("not connected to peer", "swarm", "disconnect", d2.GetAddresses()[0])
+	})
+
+	t.Run("can reconnect", func(t *testing.T) {
+		d1.ConnectSuccess(d2)
+	})
+}
+
+func TestSwarmHello(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	t.Run("peers on the same genesis match", func(t *testing.T) {
+		d2 := th.NewDaemon(t).Start()
+		defer d2.ShutdownSuccess()
+		d1.ConnectSuccess(d2)
+
+		hello := d1.Hello(d2.GetID())
+		assert.True(t, hello.GenesisMatch)
+		assert.Equal(t, hello.Ours.Genesis, hello.Theirs.Genesis)
+		assert.Equal(t, uint64(0), hello.Theirs.Height)
+		require.NotNil(t, hello.Ours.ParentWeight)
+	})
+
+	t.Run("peers on different genesis do not match", func(t *testing.T) {
+		fi, err := ioutil.TempFile("", "gengentest")
+		require.NoError(t, err)
+		defer os.Remove(fi.Name()) // nolint: errcheck
+		_, err = gengen.GenGenesisCar(testConfig, fi, 0, genesisTime)
+		require.NoError(t, err)
+		require.NoError(t, fi.Close())
+
+		d2 := th.NewDaemon(t, th.GenesisFile(fi.Name())).Start()
+		defer d2.ShutdownSuccess()
+		d1.RunSuccess("swarm", "connect", d2.GetAddresses()[0])
+
+		hello := d1.Hello(d2.GetID())
+		assert.False(t, hello.GenesisMatch)
+		assert.NotEqual(t, hello.Ours.Genesis, hello.Theirs.Genesis)
+
+		out := d1.RunSuccess("swarm", "hello", d2.GetID())
+		assert.Contains(t, out.ReadStdout(), "Genesis match: false")
+	})
+}
+
+func TestSwarmProtocols(t *testing.T) {
+	tf.IntegrationTest(t)
+
+	d1 := th.NewDaemon(t).Start()
+	defer d1.ShutdownSuccess()
+
+	d2 := th.NewDaemon(t).Start()
+	defer d2.ShutdownSuccess()
+
+	enabled := map[string]bool{}
+	for _, p := range d1.SwarmProtocols() {
+		if p.Enabled {
+			enabled[p.Name] = true
+		}
+	}
+	for _, name := range []string{"/filecoin/hello", "/ipfs/bitswap", "/ipfs/graphsync", "/ipfs/id", "/ipfs/ping"} {
+		assert.True(t, enabled[name], "%s is not enabled", name)
+	}
+
+	// The peer announces its protocols once connected.
+	d1.ConnectSuccess(d2)
+	require.NoError(t, th.WaitForIt(10, 500*time.Millisecond, func() (bool, error) {
+		out := d1.RunSuccess("swarm", "protocols", "--peer", d2.GetID()).ReadStdout()
+		return strings.Contains(out, "(peer supports)"), nil
+	}))
+}
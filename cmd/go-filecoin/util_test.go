@@ -7,7 +7,9 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
@@ -36,3 +38,22 @@ func TestOptionalAddr(t *testing.T) {
 		assert.Equal(t, address.Undef, addr)
 	})
 }
+
+func TestDefaultSectorSize(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("is the first supported size", func(t *testing.T) {
+		pp := &porcelain.ProtocolParams{SupportedSectors: []porcelain.SectorInfo{
+			{Size: types.OneKiBSectorSize},
+			{Size: types.TwoHundredFiftySixMiBSectorSize},
+		}}
+		size, err := defaultSectorSize(pp)
+		require.NoError(t, err)
+		assert.Equal(t, types.OneKiBSectorSize, size)
+	})
+
+	t.Run("fails when no size is supported", func(t *testing.T) {
+		_, err := defaultSectorSize(&porcelain.ProtocolParams{})
+		assert.EqualError(t, err, "the protocol supports no sector sizes")
+	})
+}
@@ -11,7 +11,6 @@ import (
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 
-	"github.com/multiformats/go-multiaddr-net"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 )
@@ -34,13 +33,10 @@ func TestVersionOverHttp(t *testing.T) {
 	td := th.NewDaemon(t).Start()
 	defer td.ShutdownSuccess()
 
-	maddr, err := td.CmdAddr()
+	apiURL, err := td.APIURL()
 	require.NoError(t, err)
 
-	_, host, err := manet.DialArgs(maddr)
-	require.NoError(t, err)
-
-	url := fmt.Sprintf("http://%s/api/version", host)
+	url := apiURL + "/version"
 	req, err := http.NewRequest("POST", url, nil)
 	require.NoError(t, err)
 	res, err := http.DefaultClient.Do(req)
@@ -3,10 +3,12 @@ package submodule
 import (
 	"context"
 	"sync"
+	"time"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/mining"
 	mining_protocol "github.com/filecoin-project/go-filecoin/internal/pkg/protocol/mining"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 // BlockMiningSubmodule enhances the `Node` with block mining capabilities.
@@ -22,15 +24,32 @@ type BlockMiningSubmodule struct {
 	Mining          struct {
 		sync.Mutex
 		IsMining bool
+		// StopHeight is the chain height at which mining stops, zero
+		// to mine indefinitely.
+		StopHeight uint64
 	}
 	MiningDoneWg *sync.WaitGroup
+
+	// MockMineInterval, when nonzero, is the time between the timestamps of
+	// a mined block and its parents for each round since them.
+	MockMineInterval time.Duration
+	// MockMineWinner, when set, is the only miner blocks are mined for. It
+	// wins every election.
+	MockMineWinner address.Address
+}
+
+type blockMiningConfig interface {
+	MockMineInterval() time.Duration
+	MockMineWinner() address.Address
 }
 
 type newBlockFunc func(context.Context, *block.Block)
 
 // NewBlockMiningSubmodule creates a new block mining submodule.
-func NewBlockMiningSubmodule(ctx context.Context) (BlockMiningSubmodule, error) {
+func NewBlockMiningSubmodule(ctx context.Context, config blockMiningConfig) (BlockMiningSubmodule, error) {
 	return BlockMiningSubmodule{
+		MockMineInterval: config.MockMineInterval(),
+		MockMineWinner:   config.MockMineWinner(),
 		// BlockMiningAPI:     nil,
 		// AddNewlyMinedBlock: nil,
 		// cancelMining:       nil,
@@ -12,6 +12,9 @@ type StorageProtocolSubmodule struct {
 
 	// Storage Market Interfaces
 	StorageMiner *storage.Miner
+
+	// FaultSlasher slashes miners that miss their PoSt deadline
+	FaultSlasher *storage.FaultSlasher
 }
 
 // NewStorageProtocolSubmodule creates a new storage protocol submodule.
@@ -19,5 +22,6 @@ func NewStorageProtocolSubmodule(ctx context.Context) (StorageProtocolSubmodule,
 	return StorageProtocolSubmodule{
 		// StorageAPI: nil,
 		// StorageMiner: nil,
+		// FaultSlasher: nil,
 	}, nil
 }
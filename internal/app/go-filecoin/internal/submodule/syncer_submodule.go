@@ -18,6 +18,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/net/pubsub"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 // SyncerSubmodule enhances the node with chain syncing capabilities
@@ -36,6 +37,7 @@ type syncerConfig interface {
 	GenesisCid() cid.Cid
 	BlockTime() time.Duration
 	Clock() clock.Clock
+	MockMineWinner() address.Address
 }
 
 type nodeChainSelector interface {
@@ -62,7 +64,11 @@ func NewSyncerSubmodule(ctx context.Context, config syncerConfig, repo chainRepo
 	}
 
 	// set up consensus
-	nodeConsensus := consensus.NewExpected(blockstore.CborStore, blockstore.Blockstore, chn.Processor, chn.ActorState, config.BlockTime(), consensus.ElectionMachine{}, consensus.TicketMachine{})
+	var electionValidator consensus.ElectionValidator = consensus.ElectionMachine{}
+	if winner := config.MockMineWinner(); !winner.Empty() {
+		electionValidator = consensus.MockWinnerElectionValidator{ElectionValidator: electionValidator, Winner: winner}
+	}
+	nodeConsensus := consensus.NewExpected(blockstore.CborStore, blockstore.Blockstore, chn.Processor, chn.ActorState, config.BlockTime(), electionValidator, consensus.TicketMachine{})
 	nodeChainSelector := consensus.NewChainSelector(blockstore.CborStore, chn.ActorState, config.GenesisCid())
 
 	// setup fecher
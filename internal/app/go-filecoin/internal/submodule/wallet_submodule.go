@@ -17,13 +17,21 @@ type walletRepo interface {
 	WalletDatastore() repo.Datastore
 }
 
-// NewWalletSubmodule creates a new storage protocol submodule.
-func NewWalletSubmodule(ctx context.Context, repo walletRepo) (WalletSubmodule, error) {
-	backend, err := wallet.NewDSBackend(repo.WalletDatastore())
+// NewWalletSubmodule creates a new storage protocol submodule. The wallet
+// manages the addresses in the repo's datastore, whose keys are encrypted if
+// encrypted is true, and in any extra backends.
+func NewWalletSubmodule(ctx context.Context, repo walletRepo, encrypted bool, extra ...wallet.Backend) (WalletSubmodule, error) {
+	var backend wallet.Backend
+	var err error
+	if encrypted {
+		backend, err = wallet.NewEncryptedBackend(repo.WalletDatastore())
+	} else {
+		backend, err = wallet.NewDSBackend(repo.WalletDatastore())
+	}
 	if err != nil {
 		return WalletSubmodule{}, errors.Wrap(err, "failed to set up wallet backend")
 	}
-	fcWallet := wallet.New(backend)
+	fcWallet := wallet.New(append([]wallet.Backend{backend}, extra...)...)
 
 	return WalletSubmodule{
 		Wallet: fcWallet,
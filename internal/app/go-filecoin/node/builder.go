@@ -23,6 +23,8 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/proofs/verification"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/version"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
 )
 
 // Builder is a helper to aid in the construction of a filecoin node.
@@ -37,6 +39,13 @@ type Builder struct {
 	isRelay     bool
 	clock       clock.Clock
 	genCid      cid.Cid
+	// mockMineInterval and mockMineWinner make mining deterministic for tests.
+	mockMineInterval time.Duration
+	mockMineWinner   address.Address
+	// walletBackends are added to the wallet alongside its datastore backend.
+	walletBackends []wallet.Backend
+	// encryptedWallet is true if the keys in the wallet datastore are encrypted.
+	encryptedWallet bool
 }
 
 // BuilderOpt is an option for building a filecoin node.
@@ -66,6 +75,26 @@ func BlockTime(blockTime time.Duration) BuilderOpt {
 	}
 }
 
+// MockMineInterval makes the node stamp each block it mines interval after
+// the earliest timestamp of its parents for every round since them, instead of
+// with the current time.
+func MockMineInterval(interval time.Duration) BuilderOpt {
+	return func(c *Builder) error {
+		c.mockMineInterval = interval
+		return nil
+	}
+}
+
+// MockMineWinner makes the node mine only for the miner at winner, which wins
+// every election the node runs or validates. Mining for any other miner fails
+// instead of running elections.
+func MockMineWinner(winner address.Address) BuilderOpt {
+	return func(c *Builder) error {
+		c.mockMineWinner = winner
+		return nil
+	}
+}
+
 // Libp2pOptions returns a node config option that sets up the libp2p node
 func Libp2pOptions(opts ...libp2p.Option) BuilderOpt {
 	return func(b *Builder) error {
@@ -110,6 +139,26 @@ func JournalConfigOption(jrl journal.Journal) BuilderOpt {
 	}
 }
 
+// RemoteSigner returns a function that makes the node's wallet sign for addrs
+// by forwarding each request to the HTTP signer at url. The node never holds
+// the private keys of these addresses.
+func RemoteSigner(url string, addrs []address.Address) BuilderOpt {
+	return func(c *Builder) error {
+		c.walletBackends = append(c.walletBackends, wallet.NewRemoteBackend(url, addrs))
+		return nil
+	}
+}
+
+// EncryptedWallet returns a function that makes the node's wallet keep the
+// keys in the repo's wallet datastore encrypted. The wallet starts locked, see
+// `wallet unlock`.
+func EncryptedWallet() BuilderOpt {
+	return func(c *Builder) error {
+		c.encryptedWallet = true
+		return nil
+	}
+}
+
 // New creates a new node.
 func New(ctx context.Context, opts ...BuilderOpt) (*Node, error) {
 	// initialize builder and set base values
@@ -194,7 +243,7 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		return nil, errors.Wrap(err, "failed to build node.Syncer")
 	}
 
-	nd.Wallet, err = submodule.NewWalletSubmodule(ctx, b.repo)
+	nd.Wallet, err = submodule.NewWalletSubmodule(ctx, b.repo, b.encryptedWallet, b.walletBackends...)
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.Wallet")
 	}
@@ -209,7 +258,7 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		return nil, errors.Wrap(err, "failed to build node.StorageNetworking")
 	}
 
-	nd.BlockMining, err = submodule.NewBlockMiningSubmodule(ctx)
+	nd.BlockMining, err = submodule.NewBlockMiningSubmodule(ctx, (*builder)(b))
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to build node.BlockMining")
 	}
@@ -236,12 +285,15 @@ func (b *Builder) build(ctx context.Context) (*Node, error) {
 		DAG:           dag.NewDAG(merkledag.NewDAGService(nd.Blockservice.Blockservice)),
 		Deals:         strgdls.New(b.repo.DealsDatastore()),
 		Expected:      nd.syncer.Consensus,
+		Hello:         nd.Discovery.HelloHandler,
 		MsgPool:       nd.Messaging.MsgPool,
 		MsgPreviewer:  msg.NewPreviewer(nd.chain.ChainReader, nd.Blockstore.CborStore, nd.Blockstore.Blockstore, nd.chain.Processor),
+		MsgReplayer:   msg.NewReplayer(nd.chain.ChainReader, nd.Blockstore.Blockstore, nd.chain.Processor),
 		ActState:      nd.chain.ActorState,
 		MsgWaiter:     msg.NewWaiter(nd.chain.ChainReader, nd.chain.MessageStore, nd.Blockstore.Blockstore, nd.Blockstore.CborStore),
 		Network:       nd.network.Network,
 		Outbox:        nd.Messaging.Outbox,
+		Processor:     nd.chain.Processor,
 		SectorBuilder: nd.SectorBuilder,
 		Wallet:        nd.Wallet.Wallet,
 	}))
@@ -278,6 +330,14 @@ func (b builder) Clock() clock.Clock {
 	return b.clock
 }
 
+func (b builder) MockMineInterval() time.Duration {
+	return b.mockMineInterval
+}
+
+func (b builder) MockMineWinner() address.Address {
+	return b.mockMineWinner
+}
+
 func (b builder) Journal() journal.Journal {
 	return b.journal
 }
@@ -25,6 +25,10 @@ func OptionsFromRepo(r repo.Repo) ([]BuilderOpt, error) {
 		),
 	}
 
+	if cfg.Wallet.Encrypted {
+		cfgopts = append(cfgopts, EncryptedWallet())
+	}
+
 	dsopt := func(c *Builder) error {
 		c.repo = r
 		return nil
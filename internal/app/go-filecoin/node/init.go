@@ -20,8 +20,9 @@ const defaultPeerKeyBits = 2048
 
 // initCfg contains configuration for initializing a node's repo.
 type initCfg struct {
-	peerKey    crypto.PrivKey
-	defaultKey *types.KeyInfo
+	peerKey          crypto.PrivKey
+	defaultKey       *types.KeyInfo
+	walletPassphrase string
 }
 
 // InitOpt is an option for initialization of a node's repo.
@@ -43,6 +44,14 @@ func DefaultKeyOpt(ki *types.KeyInfo) InitOpt {
 	}
 }
 
+// WalletPassphraseOpt makes initialization encrypt the keys of the wallet with
+// passphrase. The node then starts with the wallet locked.
+func WalletPassphraseOpt(passphrase string) InitOpt {
+	return func(opts *initCfg) {
+		opts.walletPassphrase = passphrase
+	}
+}
+
 // Init initializes a Filecoin repo with genesis state and keys.
 // This will always set the configuration for wallet default address (to the specified default
 // key or a newly generated one), but otherwise leave the repo's config object intact.
@@ -63,7 +72,7 @@ func Init(ctx context.Context, r repo.Repo, gen consensus.GenesisInitFunc, opts
 		return err
 	}
 
-	defaultKey, err := initDefaultKey(r.WalletDatastore(), cfg.defaultKey)
+	defaultKey, err := initDefaultKey(r.WalletDatastore(), cfg.defaultKey, cfg.walletPassphrase)
 	if err != nil {
 		return err
 	}
@@ -73,6 +82,7 @@ func Init(ctx context.Context, r repo.Repo, gen consensus.GenesisInitFunc, opts
 		return errors.Wrap(err, "failed to extract address from default key")
 	}
 	r.Config().Wallet.DefaultAddress = defaultAddress
+	r.Config().Wallet.Encrypted = cfg.walletPassphrase != ""
 	if err = r.ReplaceConfig(r.Config()); err != nil {
 		return errors.Wrap(err, "failed to write config")
 	}
@@ -94,11 +104,26 @@ func initPeerKey(store keystore.Keystore, key crypto.PrivKey) error {
 	return nil
 }
 
-func initDefaultKey(store repo.Datastore, key *types.KeyInfo) (*types.KeyInfo, error) {
-	backend, err := wallet.NewDSBackend(store)
-	if err != nil {
-		return nil, errors.Wrap(err, "failed to open wallet datastore")
+func initDefaultKey(store repo.Datastore, key *types.KeyInfo, passphrase string) (*types.KeyInfo, error) {
+	var backend wallet.Backend
+	if passphrase == "" {
+		dsBackend, err := wallet.NewDSBackend(store)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open wallet datastore")
+		}
+		backend = dsBackend
+	} else {
+		encrypted, err := wallet.NewEncryptedBackend(store)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to open wallet datastore")
+		}
+		if err := encrypted.Unlock(passphrase); err != nil {
+			return nil, errors.Wrap(err, "failed to unlock wallet")
+		}
+		backend = encrypted
 	}
+
+	var err error
 	w := wallet.New(backend)
 	if key == nil {
 		key, err = w.NewKeyInfo()
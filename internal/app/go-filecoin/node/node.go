@@ -126,7 +126,6 @@ func (node *Node) Start(ctx context.Context) error {
 	if err != nil {
 		return errors.Wrap(err, "failed to set up protocols:")
 	}
-	node.RetrievalProtocol.RetrievalMiner = retrieval.NewMiner(node)
 
 	var syncCtx context.Context
 	syncCtx, node.syncer.CancelChainSync = context.WithCancel(context.Background())
@@ -232,6 +231,12 @@ func (node *Node) setIsMining(isMining bool) {
 	node.BlockMining.Mining.IsMining = isMining
 }
 
+func (node *Node) setMiningStopHeight(stopHeight uint64) {
+	node.BlockMining.Mining.Lock()
+	defer node.BlockMining.Mining.Unlock()
+	node.BlockMining.Mining.StopHeight = stopHeight
+}
+
 func (node *Node) handleNewMiningOutput(ctx context.Context, miningOutCh <-chan mining.Output) {
 	defer func() {
 		node.BlockMining.MiningDoneWg.Done()
@@ -248,12 +253,23 @@ func (node *Node) handleNewMiningOutput(ctx context.Context, miningOutCh <-chan
 				log.Errorf("stopping mining. error: %s", output.Err.Error())
 				node.StopMining(context.Background())
 			} else {
+				stopHeight := node.MiningStopHeight()
+				height := uint64(output.NewBlock.Height)
+				if stopHeight > 0 && height > stopHeight {
+					// The block skipped over the stop height after null
+					// rounds, or was mined before mining stopped. Drop it.
+					go node.StopMining(context.Background())
+					continue
+				}
 				node.BlockMining.MiningDoneWg.Add(1)
 				go func() {
 					if node.IsMining() {
 						node.BlockMining.AddNewlyMinedBlock(ctx, output.NewBlock)
 					}
 					node.BlockMining.MiningDoneWg.Done()
+					if stopHeight > 0 && height == stopHeight {
+						node.StopMining(context.Background())
+					}
 				}()
 			}
 		}
@@ -286,6 +302,12 @@ func (node *Node) handleNewChainHeads(ctx context.Context, prevHead block.TipSet
 					log.Error(err)
 				}
 			}
+
+			if node.StorageProtocol.FaultSlasher != nil {
+				if err := node.StorageProtocol.FaultSlasher.OnNewHeaviestTipSet(ctx, newHead); err != nil {
+					log.Error(err)
+				}
+			}
 		case <-ctx.Done():
 			return
 		}
@@ -400,16 +422,46 @@ func (node *Node) SetupMining(ctx context.Context) error {
 		node.StorageProtocol.StorageMiner = storageMiner
 	}
 
+	// ensure we slash miners that miss their PoSt, paying gas from the
+	// miner's owner
+	if node.StorageProtocol.FaultSlasher == nil {
+		ownerAddr, err := node.PorcelainAPI.MinerGetOwnerAddress(ctx, minerAddr)
+		if err != nil {
+			return errors.Wrap(err, "failed to get miner owner address")
+		}
+		node.StorageProtocol.FaultSlasher = storage.NewFaultSlasher(node.PorcelainAPI, ownerAddr)
+	}
+
 	return nil
 }
 
 // StartMining causes the node to start feeding blocks to the mining worker and initializes
 // the SectorBuilder for the mining address.
 func (node *Node) StartMining(ctx context.Context) error {
+	return node.StartMiningUntil(ctx, 0)
+}
+
+// StartMiningUntil is like StartMining but stops mining once the node mines a
+// block at stopHeight. A stopHeight of zero mines indefinitely.
+func (node *Node) StartMiningUntil(ctx context.Context, stopHeight uint64) error {
 	if node.IsMining() {
 		return errors.New("Node is already mining")
 	}
 
+	if stopHeight > 0 {
+		head, err := node.PorcelainAPI.ChainHead()
+		if err != nil {
+			return errors.Wrap(err, "failed to get chain head")
+		}
+		h, err := head.Height()
+		if err != nil {
+			return errors.Wrap(err, "failed to get chain head height")
+		}
+		if h >= stopHeight {
+			return errors.Errorf("chain head is already at height %d", h)
+		}
+	}
+
 	err := node.SetupMining(ctx)
 	if err != nil {
 		return errors.Wrap(err, "failed to setup mining")
@@ -428,7 +480,7 @@ func (node *Node) StartMining(ctx context.Context) error {
 	_, mineDelay := node.MiningTimes()
 
 	if node.BlockMining.MiningScheduler == nil {
-		node.BlockMining.MiningScheduler = mining.NewScheduler(node.BlockMining.MiningWorker, mineDelay, node.PorcelainAPI.ChainHead)
+		node.BlockMining.MiningScheduler = mining.NewScheduler(node.BlockMining.MiningWorker, mineDelay, node.PorcelainAPI.ChainHead, node.MiningPaused)
 	} else if node.BlockMining.MiningScheduler.IsStarted() {
 		return fmt.Errorf("miner scheduler already started")
 	}
@@ -516,6 +568,7 @@ func (node *Node) StartMining(ctx context.Context) error {
 	} else {
 		log.Debug("auto-seal is disabled")
 	}
+	node.setMiningStopHeight(stopHeight)
 	node.setIsMining(true)
 
 	return nil
@@ -613,6 +666,7 @@ func initStorageMinerForNode(ctx context.Context, node *Node) (*storage.Miner, e
 // StopMining stops mining on new blocks.
 func (node *Node) StopMining(ctx context.Context) {
 	node.setIsMining(false)
+	node.setMiningStopHeight(0)
 
 	if node.BlockMining.CancelMining != nil {
 		node.BlockMining.CancelMining()
@@ -655,16 +709,19 @@ func (node *Node) setupProtocols() error {
 		node.AddNewBlock,
 		node.chain.ChainReader,
 		node.IsMining,
+		node.MiningStopHeight,
+		node.MiningPaused,
 		mineDelay,
 		node.SetupMining,
-		node.StartMining,
+		node.StartMiningUntil,
 		node.StopMining,
 		node.GetMiningWorker)
 
 	node.BlockMining.BlockMiningAPI = &blockMiningAPI
 
-	// set up retrieval client and api
-	retapi := retrieval.NewAPI(retrieval.NewClient(node.network.Host, node.PorcelainAPI))
+	// set up retrieval client, miner and api
+	node.RetrievalProtocol.RetrievalMiner = retrieval.NewMiner(node, node.PorcelainAPI)
+	retapi := retrieval.NewAPI(retrieval.NewClient(node.network.Host, node.PorcelainAPI), node.RetrievalProtocol.RetrievalMiner)
 	node.RetrievalProtocol.RetrievalAPI = &retapi
 
 	// set up storage client and api
@@ -716,6 +773,11 @@ func (node *Node) CreateMiningWorker(ctx context.Context) (mining.Worker, error)
 		Processor:     processor,
 		Blockstore:    node.Blockstore.Blockstore,
 		Clock:         node.Clock,
+		MaxMessages:   func() uint { return node.Repo.Config().Blocks.MaxMessages },
+		MinGasPrice:   func() types.AttoFIL { return node.Repo.Config().Mining.MinGasPrice },
+
+		MockMineInterval: node.BlockMining.MockMineInterval,
+		MockMineWinner:   node.BlockMining.MockMineWinner,
 	}), nil
 }
 
@@ -776,6 +838,25 @@ func (node *Node) IsMining() bool {
 	return node.BlockMining.Mining.IsMining
 }
 
+// MiningStopHeight returns the height at which the node stops mining, or zero
+// if it mines indefinitely.
+func (node *Node) MiningStopHeight() uint64 {
+	node.BlockMining.Mining.Lock()
+	defer node.BlockMining.Mining.Unlock()
+	return node.BlockMining.Mining.StopHeight
+}
+
+// MiningPaused returns why mining is paused, or nil if the node may mine. It
+// is paused while the node has fewer peers than mining.min_peers.
+func (node *Node) MiningPaused() error {
+	minPeers := node.Repo.Config().Mining.MinPeers
+	peers := uint(len(node.network.Host.Network().Peers()))
+	if peers < minPeers {
+		return fmt.Errorf("connected to %d peers, mining.min_peers is %d", peers, minPeers)
+	}
+	return nil
+}
+
 // Chain returns the chain submodule.
 func (node *Node) Chain() submodule.ChainSubmodule {
 	return node.chain
@@ -0,0 +1,94 @@
+package node_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/node"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/version"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+func TestRemoteSigner(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The signer holds the key; the node only knows its address.
+	ms := types.NewMockSigner(types.MustGenerateKeyInfo(1, 42))
+	remoteAddr := ms.Addresses[0]
+	var requests int32
+	// The handler runs on the server's goroutines, so it sends its errors to
+	// the test goroutine rather than failing the test itself.
+	signerErrs := make(chan error, 16)
+	signer := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&requests, 1)
+		err := func() error {
+			var req wallet.RemoteSignRequest
+			if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+				return err
+			}
+			sig, err := ms.SignBytes(req.Data, req.Address)
+			if err != nil {
+				return err
+			}
+			return json.NewEncoder(w).Encode(wallet.RemoteSignResponse{Signature: sig})
+		}()
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusInternalServerError)
+		}
+		signerErrs <- err
+	}))
+	defer signer.Close()
+
+	genesis := consensus.MakeGenesisFunc(
+		consensus.ActorAccount(remoteAddr, types.NewAttoFILFromFIL(100)),
+		consensus.Network(version.TEST),
+	)
+	nd := node.GenNode(t, &node.TestNodeOptions{
+		GenesisFunc: genesis,
+		BuilderOpts: append(node.DefaultTestingConfig(), node.RemoteSigner(signer.URL, []address.Address{remoteAddr})),
+	})
+	node.StartNodes(t, []*node.Node{nd})
+	defer node.StopNodes([]*node.Node{nd})
+
+	assert.True(t, nd.Wallet.Wallet.HasAddress(remoteAddr))
+	_, err := nd.Wallet.Wallet.Export([]address.Address{remoteAddr})
+	assert.Error(t, err)
+
+	// The message pool only accepts messages whose signature verifies
+	// against the sender's on-chain account.
+	mcid, pubErrCh, err := nd.PorcelainAPI.MessageSend(
+		ctx,
+		remoteAddr,
+		address.NetworkAddress,
+		types.NewAttoFILFromFIL(1),
+		types.NewGasPrice(1),
+		types.NewGasUnits(0),
+		types.SendMethodID,
+	)
+	require.NoError(t, err)
+	require.NoError(t, <-pubErrCh)
+	require.NoError(t, <-signerErrs)
+	assert.Equal(t, int32(1), atomic.LoadInt32(&requests))
+
+	pending := nd.Messaging.Inbox.Pool().Pending()
+	require.Len(t, pending, 1)
+	c, err := pending[0].Cid()
+	require.NoError(t, err)
+	assert.Equal(t, mcid, c)
+	assert.Equal(t, remoteAddr, pending[0].Message.From)
+	assert.True(t, pending[0].VerifySignature())
+}
@@ -24,7 +24,9 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/strgdls"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/config"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/discovery"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
@@ -48,12 +50,15 @@ type API struct {
 	config        *cfg.Config
 	dag           *dag.DAG
 	expected      consensus.Protocol
+	hello         *discovery.HelloProtocolHandler
 	msgPool       *message.Pool
 	msgPreviewer  *msg.Previewer
+	msgReplayer   *msg.Replayer
 	actorState    *consensus.ActorStateStore
 	msgWaiter     *msg.Waiter
 	network       *net.Network
 	outbox        *message.Outbox
+	processor     *consensus.DefaultProcessor
 	sectorBuilder func() sectorbuilder.SectorBuilder
 	storagedeals  *strgdls.Store
 	wallet        *wallet.Wallet
@@ -68,11 +73,14 @@ type APIDeps struct {
 	DAG           *dag.DAG
 	Deals         *strgdls.Store
 	Expected      consensus.Protocol
+	Hello         *discovery.HelloProtocolHandler
 	MsgPool       *message.Pool
 	MsgPreviewer  *msg.Previewer
+	MsgReplayer   *msg.Replayer
 	MsgWaiter     *msg.Waiter
 	Network       *net.Network
 	Outbox        *message.Outbox
+	Processor     *consensus.DefaultProcessor
 	SectorBuilder func() sectorbuilder.SectorBuilder
 	Wallet        *wallet.Wallet
 }
@@ -87,11 +95,14 @@ func New(deps *APIDeps) *API {
 		config:        deps.Config,
 		dag:           deps.DAG,
 		expected:      deps.Expected,
+		hello:         deps.Hello,
 		msgPool:       deps.MsgPool,
 		msgPreviewer:  deps.MsgPreviewer,
+		msgReplayer:   deps.MsgReplayer,
 		msgWaiter:     deps.MsgWaiter,
 		network:       deps.Network,
 		outbox:        deps.Outbox,
+		processor:     deps.Processor,
 		sectorBuilder: deps.SectorBuilder,
 		storagedeals:  deps.Deals,
 		wallet:        deps.Wallet,
@@ -136,6 +147,12 @@ func (api *API) ConfigGet(dottedPath string) (interface{}, error) {
 	return api.config.Get(dottedPath)
 }
 
+// ConfigDiff returns the fields whose values differ between the local config
+// and the config of the repo at otherRepoDir.
+func (api *API) ConfigDiff(otherRepoDir string) ([]config.FieldDiff, error) {
+	return api.config.Diff(otherRepoDir)
+}
+
 // ChainGetBlock gets a block by CID
 func (api *API) ChainGetBlock(ctx context.Context, id cid.Cid) (*block.Block, error) {
 	return api.chain.GetBlock(ctx, id)
@@ -171,6 +188,17 @@ func (api *API) ChainLs(ctx context.Context) (*chain.TipsetIterator, error) {
 	return api.chain.Ls(ctx)
 }
 
+// ChainLsFrom returns an iterator of tipsets from the tipset at key to genesis
+func (api *API) ChainLsFrom(ctx context.Context, key block.TipSetKey) (*chain.TipsetIterator, error) {
+	return api.chain.LsFrom(ctx, key)
+}
+
+// ChainTipSets returns every tipset the node has validated, including those
+// that are not ancestors of the head.
+func (api *API) ChainTipSets() []block.TipSet {
+	return api.chain.TipSets()
+}
+
 // ChainSampleRandomness produces a slice of random bytes sampled from a TipSet
 // in the blockchain at a given height, useful for things like PoSt challenge seed
 // generation.
@@ -183,6 +211,18 @@ func (api *API) SyncerStatus() status.Status {
 	return api.syncer.Status()
 }
 
+// ChainEquivocations returns the miners the syncer has seen producing more
+// than one block at a height.
+func (api *API) ChainEquivocations() []consensus.Equivocation {
+	return api.syncer.Equivocations()
+}
+
+// ChainBlockReward returns the block reward paid to the miner of a block at
+// height h.
+func (api *API) ChainBlockReward(h uint64) types.AttoFIL {
+	return api.processor.BlockRewardAt(h)
+}
+
 // ChainSyncHandleNewTipSet submits a chain head to the syncer for processing.
 func (api *API) ChainSyncHandleNewTipSet(ci *block.ChainInfo) error {
 	return api.syncer.HandleNewTipSet(ci)
@@ -208,6 +248,16 @@ func (api *API) DealPut(storageDeal *storagedeal.Deal) error {
 	return api.storagedeals.Put(storageDeal)
 }
 
+// DealTranscriptPut stores the negotiation transcript of a deal
+func (api *API) DealTranscriptPut(transcript *storagedeal.Transcript) error {
+	return api.storagedeals.PutTranscript(transcript)
+}
+
+// DealTranscriptGet returns the negotiation transcript of the proposal with the given cid
+func (api *API) DealTranscriptGet(proposalCid cid.Cid) (*storagedeal.Transcript, error) {
+	return api.storagedeals.GetTranscript(proposalCid)
+}
+
 // OutboxQueues lists addresses with non-empty outbox queues (in no particular order).
 func (api *API) OutboxQueues() []address.Address {
 	return api.outbox.Queue().Queues()
@@ -238,12 +288,32 @@ func (api *API) MessagePoolRemove(cid cid.Cid) {
 	api.msgPool.Remove(cid)
 }
 
+// MessagePoolAdd validates a signed message and adds it to the message pool
+// without broadcasting it.
+func (api *API) MessagePoolAdd(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
+	head, err := api.chain.GetTipSet(api.chain.Head())
+	if err != nil {
+		return cid.Undef, err
+	}
+	height, err := head.Height()
+	if err != nil {
+		return cid.Undef, err
+	}
+	return api.msgPool.Add(ctx, smsg, height)
+}
+
 // MessagePreview previews the Gas cost of a message by running it locally on the client and
 // recording the amount of Gas used.
 func (api *API) MessagePreview(ctx context.Context, from, to address.Address, method types.MethodID, params ...interface{}) (types.GasUnits, error) {
 	return api.msgPreviewer.Preview(ctx, from, to, method, params...)
 }
 
+// MessageReplay executes a message against the state of the tipset at baseKey
+// and reports its receipt and the actors it changed. Nothing is persisted.
+func (api *API) MessageReplay(ctx context.Context, message *types.UnsignedMessage, baseKey block.TipSetKey) (*msg.ReplayResult, error) {
+	return api.msgReplayer.Replay(ctx, message, baseKey)
+}
+
 // MessageQuery calls an actor's method using the most recent chain state. It is read-only,
 // it does not change any state. It is use to interrogate actor state. The from address
 // is optional; if not provided, an address will be chosen from the node's wallet.
@@ -280,6 +350,12 @@ func (api *API) MessageFind(ctx context.Context, msgCid cid.Cid) (*msg.ChainMess
 	return api.msgWaiter.Find(ctx, msgCid)
 }
 
+// MessagesInTipSet returns every message executed in the given tipset along
+// with its receipt, in execution order.
+func (api *API) MessagesInTipSet(ctx context.Context, ts block.TipSet) ([]*msg.ChainMessage, error) {
+	return api.msgWaiter.TipSetMessages(ctx, ts)
+}
+
 // MessageWait invokes the callback when a message with the given cid appears on chain.
 // It will find the message in both the case that it is already on chain and
 // the case that it appears in a newly mined block. An error is returned if one is
@@ -294,6 +370,18 @@ func (api *API) NetworkGetBandwidthStats() metrics.Stats {
 	return api.network.GetBandwidthStats()
 }
 
+// NetworkBandwidth gets the bandwidth used since the counters were last
+// reset, optionally by peer and by protocol
+func (api *API) NetworkBandwidth(byPeer, byProtocol bool) net.BandwidthStats {
+	return api.network.Bandwidth(byPeer, byProtocol)
+}
+
+// NetworkResetBandwidth gets the bandwidth used since the counters were last
+// reset and resets them
+func (api *API) NetworkResetBandwidth() net.BandwidthStats {
+	return api.network.ResetBandwidth()
+}
+
 // NetworkGetPeerAddresses gets the current addresses of the node
 func (api *API) NetworkGetPeerAddresses() []ma.Multiaddr {
 	return api.network.GetPeerAddresses()
@@ -324,11 +412,31 @@ func (api *API) NetworkFindPeer(ctx context.Context, peerID peer.ID) (peer.AddrI
 	return api.network.FindPeer(ctx, peerID)
 }
 
+// NetworkHello performs the hello handshake with a peer
+func (api *API) NetworkHello(ctx context.Context, pid peer.ID) (*discovery.HelloExchange, error) {
+	return api.hello.Handshake(ctx, pid)
+}
+
 // NetworkConnect connects to peers at the given addresses
 func (api *API) NetworkConnect(ctx context.Context, addrs []string) (<-chan net.ConnectionResult, error) {
 	return api.network.Connect(ctx, addrs)
 }
 
+// NetworkDisconnect closes the connections to the peer at the given address
+func (api *API) NetworkDisconnect(addr string) (peer.ID, error) {
+	return api.network.Disconnect(addr)
+}
+
+// NetworkProtocols returns the ids of the protocols the node handles
+func (api *API) NetworkProtocols() []string {
+	return api.network.Protocols()
+}
+
+// NetworkPeerProtocols returns the ids of the protocols a peer supports
+func (api *API) NetworkPeerProtocols(pid peer.ID) ([]string, error) {
+	return api.network.PeerProtocols(pid)
+}
+
 // NetworkPeers lists peers currently available on the network
 func (api *API) NetworkPeers(ctx context.Context, verbose, latency, streams bool) (*net.SwarmConnInfos, error) {
 	return api.network.Peers(ctx, verbose, latency, streams)
@@ -339,6 +447,17 @@ func (api *API) SignBytes(data []byte, addr address.Address) (types.Signature, e
 	return api.wallet.SignBytes(data, addr)
 }
 
+// SignBytesContext is SignBytes, but gives up once ctx is done.
+func (api *API) SignBytesContext(ctx context.Context, data []byte, addr address.Address) (types.Signature, error) {
+	return api.wallet.SignBytesContext(ctx, data, addr)
+}
+
+// SignBytesBatch signs each of the given items with the private key of the
+// given address, loading the key once if the wallet backend allows it.
+func (api *API) SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error) {
+	return api.wallet.SignBytesBatch(items, addr)
+}
+
 // WalletAddresses gets addresses from the wallet
 func (api *API) WalletAddresses() []address.Address {
 	return api.wallet.Addresses()
@@ -354,9 +473,10 @@ func (api *API) WalletGetPubKeyForAddress(addr address.Address) ([]byte, error)
 	return api.wallet.GetPubKeyForAddress(addr)
 }
 
-// WalletNewAddress generates a new wallet address
-func (api *API) WalletNewAddress() (address.Address, error) {
-	return wallet.NewAddress(api.wallet, address.SECP256K1)
+// WalletNewAddress generates a new wallet address using the given protocol,
+// address.SECP256K1 or address.BLS
+func (api *API) WalletNewAddress(protocol address.Protocol) (address.Address, error) {
+	return wallet.NewAddress(api.wallet, protocol)
 }
 
 // WalletImport adds a given set of KeyInfos to the wallet
@@ -364,11 +484,41 @@ func (api *API) WalletImport(kinfos ...*types.KeyInfo) ([]address.Address, error
 	return api.wallet.Import(kinfos...)
 }
 
+// WalletImportMnemonic adds the keys of the first count addresses derived
+// from the BIP39 mnemonic phrase to the wallet
+func (api *API) WalletImportMnemonic(phrase string, count int) ([]address.Address, error) {
+	kinfos, err := wallet.MnemonicKeys(phrase, count)
+	if err != nil {
+		return nil, err
+	}
+	return api.wallet.Import(kinfos...)
+}
+
 // WalletExport returns the KeyInfos for the given wallet addresses
 func (api *API) WalletExport(addrs []address.Address) ([]*types.KeyInfo, error) {
 	return api.wallet.Export(addrs)
 }
 
+// WalletUnlock unlocks the encrypted keys of the wallet
+func (api *API) WalletUnlock(passphrase string) error {
+	return api.wallet.Unlock(passphrase)
+}
+
+// WalletDeleteAddress removes the key of the given address from the wallet
+func (api *API) WalletDeleteAddress(addr address.Address) error {
+	return api.wallet.DeleteAddress(addr)
+}
+
+// WalletSign signs data with the key of addr, giving up once ctx is done
+func (api *API) WalletSign(ctx context.Context, addr address.Address, data []byte) (types.Signature, error) {
+	return api.wallet.SignBytesContext(ctx, data, addr)
+}
+
+// WalletVerify checks that sig is a signature of data by addr
+func (api *API) WalletVerify(data []byte, addr address.Address, sig types.Signature) bool {
+	return api.wallet.Verify(data, addr, sig)
+}
+
 // DAGGetNode returns the associated DAG node for the passed in CID.
 func (api *API) DAGGetNode(ctx context.Context, ref string) (interface{}, error) {
 	return api.dag.GetNode(ctx, ref)
@@ -1,6 +1,7 @@
 package cfg
 
 import (
+	"github.com/filecoin-project/go-filecoin/internal/pkg/config"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
 	"sync"
 )
@@ -33,3 +34,22 @@ func (s *Config) Set(dottedKey string, jsonString string) error {
 func (s *Config) Get(dottedKey string) (interface{}, error) {
 	return s.repo.Config().Get(dottedKey)
 }
+
+// Diff compares the config file of the local repo with the config file of the
+// repo at otherRepoDir. Both sides are read from disk so that values overridden
+// by daemon flags for this run do not show up as differences.
+func (s *Config) Diff(otherRepoDir string) ([]config.FieldDiff, error) {
+	repoDir, err := s.repo.Path()
+	if err != nil {
+		return nil, err
+	}
+	local, err := repo.ReadConfig(repoDir)
+	if err != nil {
+		return nil, err
+	}
+	other, err := repo.ReadConfig(otherRepoDir)
+	if err != nil {
+		return nil, err
+	}
+	return local.Diff(other)
+}
@@ -48,7 +48,7 @@ func TestConfigSet(t *testing.T) {
 		repo := repo.NewInMemoryRepo()
 		cfgAPI := NewConfig(repo)
 
-		jsonBlob := `{"addresses": ["bootup1", "bootup2"]}`
+		jsonBlob := `{"addresses": ["/ip4/127.0.0.1/tcp/6001", "/ip4/127.0.0.1/tcp/6002"]}`
 
 		err := cfgAPI.Set("bootstrap", jsonBlob)
 		require.NoError(t, err)
@@ -57,7 +57,7 @@ func TestConfigSet(t *testing.T) {
 
 		// validate output
 		expected := config.NewDefaultConfig().Bootstrap
-		expected.Addresses = []string{"bootup1", "bootup2"}
+		expected.Addresses = []string{"/ip4/127.0.0.1/tcp/6001", "/ip4/127.0.0.1/tcp/6002"}
 		assert.Equal(t, expected, out)
 
 		// validate config write
@@ -65,9 +65,9 @@ func TestConfigSet(t *testing.T) {
 		assert.Equal(t, expected, cfg.Bootstrap)
 		assert.Equal(t, defaultCfg.Datastore, cfg.Datastore)
 
-		err = cfgAPI.Set("api.address", ":1234")
+		err = cfgAPI.Set("api.address", "/ip4/127.0.0.1/tcp/1234")
 		require.NoError(t, err)
-		assert.Equal(t, ":1234", cfg.API.Address)
+		assert.Equal(t, "/ip4/127.0.0.1/tcp/1234", cfg.API.Address)
 
 		testAddr := address.TestAddress2.String()
 		err = cfgAPI.Set("mining.minerAddress", testAddr)
@@ -100,7 +100,7 @@ func TestConfigSet(t *testing.T) {
 		jsonBlob := `{"addresses": ["bootup1", "bootup2"]}`
 
 		err := cfgAPI.Set("botstrap", jsonBlob)
-		assert.EqualError(t, err, "json: unknown field \"botstrap\"")
+		assert.EqualError(t, err, "key: botstrap invalid for config")
 
 		// bad value type (bootstrap is a struct not a list)
 		jsonBlobBadType := `["bootup1", "bootup2"]`
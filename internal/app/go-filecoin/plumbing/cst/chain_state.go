@@ -34,6 +34,7 @@ type chainReadWriter interface {
 	GetTipSet(block.TipSetKey) (block.TipSet, error)
 	GetTipSetState(context.Context, block.TipSetKey) (state.Tree, error)
 	SetHead(context.Context, block.TipSet) error
+	TipSets() []block.TipSet
 }
 
 // ChainStateReadWriter composes a:
@@ -90,13 +91,24 @@ func (chn *ChainStateReadWriter) GetTipSet(key block.TipSetKey) (block.TipSet, e
 
 // Ls returns an iterator over tipsets from head to genesis.
 func (chn *ChainStateReadWriter) Ls(ctx context.Context) (*chain.TipsetIterator, error) {
-	ts, err := chn.readWriter.GetTipSet(chn.readWriter.GetHead())
+	return chn.LsFrom(ctx, chn.readWriter.GetHead())
+}
+
+// LsFrom returns an iterator over tipsets from the tipset at key to genesis.
+func (chn *ChainStateReadWriter) LsFrom(ctx context.Context, key block.TipSetKey) (*chain.TipsetIterator, error) {
+	ts, err := chn.readWriter.GetTipSet(key)
 	if err != nil {
 		return nil, err
 	}
 	return chain.IterAncestors(ctx, chn.readWriter, ts), nil
 }
 
+// TipSets returns every tipset the chain store has validated, whether or not
+// it is an ancestor of the head.
+func (chn *ChainStateReadWriter) TipSets() []block.TipSet {
+	return chn.readWriter.TipSets()
+}
+
 // GetBlock gets a block by CID
 func (chn *ChainStateReadWriter) GetBlock(ctx context.Context, id cid.Cid) (*block.Block, error) {
 	bsblk, err := chn.bstore.Get(id)
@@ -4,11 +4,13 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync/status"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
 )
 
 type chainSync interface {
 	BlockProposer() chainsync.BlockProposer
 	Status() status.Status
+	Equivocations() []consensus.Equivocation
 }
 
 // ChainSyncProvider provides access to chain sync operations and their status.
@@ -29,6 +31,12 @@ func (chs *ChainSyncProvider) Status() status.Status {
 	return chs.sync.Status()
 }
 
+// Equivocations returns the miners seen producing more than one block at a
+// height, with the conflicting blocks.
+func (chs *ChainSyncProvider) Equivocations() []consensus.Equivocation {
+	return chs.sync.Equivocations()
+}
+
 // HandleNewTipSet extends the Syncer's chain store with the given tipset if they
 // represent a valid extension. It limits the length of new chains it will
 // attempt to validate and caches invalid blocks it has encountered to
@@ -0,0 +1,159 @@
+package msg
+
+import (
+	"context"
+
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	vmerrors "github.com/filecoin-project/go-filecoin/internal/pkg/vm/errors"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/state"
+)
+
+// Abstracts over a store of blockchain state.
+type replayerChainReader interface {
+	GetTipSet(block.TipSetKey) (block.TipSet, error)
+	GetTipSetState(context.Context, block.TipSetKey) (state.Tree, error)
+}
+
+type messageApplier interface {
+	// ApplyMessage applies a single message to the given state tree.
+	ApplyMessage(ctx context.Context, st state.Tree, vms vm.StorageMap, msg *types.UnsignedMessage, minerOwnerAddr address.Address, bh *types.BlockHeight, gasTracker *vm.GasTracker, ancestors []block.TipSet) (*consensus.ApplicationResult, error)
+}
+
+// ActorChange records how applying a message changed one actor.
+type ActorChange struct {
+	Address       address.Address
+	BalanceBefore types.AttoFIL
+	BalanceAfter  types.AttoFIL
+	NonceBefore   types.Uint64
+	NonceAfter    types.Uint64
+	HeadChanged   bool
+}
+
+// ReplayResult is the outcome of executing one message against the state of
+// a chosen tipset.
+type ReplayResult struct {
+	// Height is the block height the message was executed at, one above the
+	// base tipset.
+	Height uint64
+	// Receipt is the receipt the message would get in a block at Height.
+	Receipt *types.MessageReceipt
+	// Error explains a non-zero exit code, or why the message could not be
+	// applied at all.
+	Error string
+	// Trace lists the actors whose state the message changed.
+	Trace []ActorChange
+}
+
+// Replayer executes messages against historical chain state without
+// persisting the result.
+type Replayer struct {
+	// To load tipsets and their state.
+	chainReader replayerChainReader
+	// For vm storage.
+	bs bstore.Blockstore
+	// To apply messages.
+	processor messageApplier
+}
+
+// NewReplayer constructs a Replayer.
+func NewReplayer(chainReader replayerChainReader, bs bstore.Blockstore, processor messageApplier) *Replayer {
+	return &Replayer{chainReader, bs, processor}
+}
+
+// Replay executes msg against the state of the tipset at baseKey, as if it
+// were the only message in a block on top of that tipset. Gas is credited to
+// the burnt funds actor since no miner mines the block. Nothing is written
+// back to the chain or the datastore.
+func (r *Replayer) Replay(ctx context.Context, msg *types.UnsignedMessage, baseKey block.TipSetKey) (*ReplayResult, error) {
+	base, err := r.chainReader.GetTipSet(baseKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get tipset %s", baseKey)
+	}
+	h, err := base.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get base tipset height")
+	}
+	bh := types.NewBlockHeight(h + 1)
+
+	before, err := r.chainReader.GetTipSetState(ctx, baseKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load state of tipset %s", baseKey)
+	}
+	st, err := r.chainReader.GetTipSetState(ctx, baseKey)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to load state of tipset %s", baseKey)
+	}
+
+	ancestorHeight := bh.Sub(types.NewBlockHeight(uint64(consensus.AncestorRoundsNeeded)))
+	ancestors, err := chain.GetRecentAncestors(ctx, base, r.chainReader, ancestorHeight)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get recent ancestors")
+	}
+
+	result := &ReplayResult{Height: h + 1}
+	vms := vm.NewStorageMap(r.bs)
+	applied, err := r.processor.ApplyMessage(ctx, st, vms, msg, address.BurntFundsAddress, bh, vm.NewGasTracker(), ancestors)
+	if err != nil {
+		if vmerrors.IsFault(err) {
+			return nil, err
+		}
+		// The message could not be applied to this state at all, so it
+		// would not have been included in a block. Report it like a
+		// reverted message that changed nothing.
+		result.Receipt = &types.MessageReceipt{
+			ExitCode:   vmerrors.CodeError(errors.Cause(err)),
+			GasAttoFIL: types.ZeroAttoFIL,
+		}
+		result.Error = err.Error()
+		return result, nil
+	}
+
+	result.Receipt = applied.Receipt
+	if applied.ExecutionError != nil {
+		result.Error = applied.ExecutionError.Error()
+	}
+
+	result.Trace, err = diffActors(ctx, before, st)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to trace actor changes")
+	}
+	return result, nil
+}
+
+// diffActors lists the actors in after that differ from their counterpart in
+// before. Actors created by the message have an empty before state.
+func diffActors(ctx context.Context, before, after state.Tree) ([]ActorChange, error) {
+	var changes []ActorChange
+	err := after.ForEachActor(ctx, func(addr address.Address, act *actor.Actor) error {
+		old, err := before.GetActor(ctx, addr)
+		if state.IsActorNotFoundError(err) {
+			old = &actor.Actor{Balance: types.ZeroAttoFIL}
+		} else if err != nil {
+			return err
+		}
+
+		headChanged := !old.Head.Equals(act.Head)
+		if old.Balance.Equal(act.Balance) && old.Nonce == act.Nonce && !headChanged {
+			return nil
+		}
+		changes = append(changes, ActorChange{
+			Address:       addr,
+			BalanceBefore: old.Balance,
+			BalanceAfter:  act.Balance,
+			NonceBefore:   old.Nonce,
+			NonceAfter:    act.Nonce,
+			HeadChanged:   headChanged,
+		})
+		return nil
+	})
+	return changes, err
+}
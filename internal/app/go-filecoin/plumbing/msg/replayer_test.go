@@ -0,0 +1,77 @@
+package msg
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	bstore "github.com/ipfs/go-ipfs-blockstore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+func TestReplay(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	newAddr := address.NewForTestGetter()
+	fromAddr := newAddr()
+	toAddr := newAddr()
+
+	r := repo.NewInMemoryRepo()
+	bs := bstore.NewBlockstore(r.Datastore())
+	testGen := consensus.MakeGenesisFunc(
+		consensus.ActorAccount(fromAddr, types.NewAttoFILFromFIL(10)),
+	)
+	deps := requireCommonDepsWithGifAndBlockstore(t, testGen, r, bs)
+	head := deps.chainStore.GetHead()
+
+	replayer := NewReplayer(deps.chainStore, deps.blockstore, consensus.NewDefaultProcessor())
+	transfer := func(fil uint64) *types.UnsignedMessage {
+		return types.NewMeteredMessage(fromAddr, toAddr, 0, types.NewAttoFILFromFIL(fil), types.SendMethodID, nil, types.NewAttoFIL(big.NewInt(1)), types.NewGasUnits(300))
+	}
+
+	t.Run("a transfer the sender can afford succeeds", func(t *testing.T) {
+		result, err := replayer.Replay(ctx, transfer(4), head)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(1), result.Height)
+		assert.Equal(t, uint8(0), result.Receipt.ExitCode)
+		assert.Empty(t, result.Error)
+
+		changes := map[address.Address]ActorChange{}
+		for _, change := range result.Trace {
+			changes[change.Address] = change
+		}
+		require.Contains(t, changes, fromAddr)
+		assert.Equal(t, types.NewAttoFILFromFIL(10), changes[fromAddr].BalanceBefore)
+		expectedBalance := types.NewAttoFILFromFIL(6).Sub(result.Receipt.GasAttoFIL)
+		assert.Equal(t, expectedBalance, changes[fromAddr].BalanceAfter)
+		assert.Equal(t, types.Uint64(1), changes[fromAddr].NonceAfter)
+		require.Contains(t, changes, toAddr)
+		assert.Equal(t, types.ZeroAttoFIL, changes[toAddr].BalanceBefore)
+		assert.Equal(t, types.NewAttoFILFromFIL(4), changes[toAddr].BalanceAfter)
+	})
+
+	t.Run("a transfer the sender cannot afford reverts", func(t *testing.T) {
+		result, err := replayer.Replay(ctx, transfer(40), head)
+		require.NoError(t, err)
+		assert.NotEqual(t, uint8(0), result.Receipt.ExitCode)
+		assert.Contains(t, result.Error, "balance insufficient")
+		assert.Empty(t, result.Trace)
+	})
+
+	t.Run("replays do not change chain state", func(t *testing.T) {
+		st, err := deps.chainStore.GetTipSetState(ctx, head)
+		require.NoError(t, err)
+		from, err := st.GetActor(ctx, fromAddr)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewAttoFILFromFIL(10), from.Balance)
+		assert.Equal(t, types.Uint64(0), from.Nonce)
+	})
+}
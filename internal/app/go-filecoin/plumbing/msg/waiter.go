@@ -208,6 +208,70 @@ func (w *Waiter) receiptForTipset(ctx context.Context, ts block.TipSet, targetMs
 	return nil, false, nil
 }
 
+// TipSetMessages returns every message executed in the tipset, in execution
+// order, each with the block that first included it and its receipt.
+func (w *Waiter) TipSetMessages(ctx context.Context, ts block.TipSet) ([]*ChainMessage, error) {
+	// Messages are keyed by the CID of their unwrapped body, matching the
+	// de-duplicated messages the receipts were generated from.
+	tsMessages := make([][]*types.UnsignedMessage, ts.Len())
+	included := make(map[cid.Cid]*ChainMessage)
+	for i := 0; i < ts.Len(); i++ {
+		blk := ts.At(i)
+		secpMsgs, blsMsgs, err := w.messageProvider.LoadMessages(ctx, blk.Messages)
+		if err != nil {
+			return nil, err
+		}
+
+		wrappedMsgs := make([]*types.SignedMessage, 0, len(blsMsgs)+len(secpMsgs))
+		for _, msg := range blsMsgs {
+			wrappedMsgs = append(wrappedMsgs, &types.SignedMessage{Message: *msg})
+		}
+		wrappedMsgs = append(wrappedMsgs, secpMsgs...)
+
+		for _, msg := range wrappedMsgs {
+			tsMessages[i] = append(tsMessages[i], &msg.Message)
+			unwrappedCid, err := msg.Message.Cid()
+			if err != nil {
+				return nil, err
+			}
+			if _, ok := included[unwrappedCid]; !ok {
+				included[unwrappedCid] = &ChainMessage{Message: msg, Block: blk}
+			}
+		}
+	}
+
+	receiptCid, err := w.chainReader.GetTipSetReceiptsRoot(ts.Key())
+	if err != nil {
+		return nil, err
+	}
+	receipts, err := w.messageProvider.LoadReceipts(ctx, receiptCid)
+	if err != nil {
+		return nil, err
+	}
+
+	deduped, err := consensus.DeduppedMessages(tsMessages)
+	if err != nil {
+		return nil, err
+	}
+
+	var chainMsgs []*ChainMessage
+	for _, blkMessages := range deduped {
+		for _, msg := range blkMessages {
+			if len(chainMsgs) >= len(receipts) {
+				return nil, errors.Errorf("could not find message receipt at index %d", len(chainMsgs))
+			}
+			msgCid, err := msg.Cid()
+			if err != nil {
+				return nil, err
+			}
+			chainMsg := included[msgCid]
+			chainMsg.Receipt = receipts[len(chainMsgs)]
+			chainMsgs = append(chainMsgs, chainMsg)
+		}
+	}
+	return chainMsgs, nil
+}
+
 func (w *Waiter) receiptByIndex(ctx context.Context, tsKey block.TipSetKey, targetCid cid.Cid, messages [][]*types.UnsignedMessage) (*types.MessageReceipt, error) {
 	receiptCid, err := w.chainReader.GetTipSetReceiptsRoot(tsKey)
 	if err != nil {
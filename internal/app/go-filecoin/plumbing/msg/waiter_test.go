@@ -156,6 +156,40 @@ func TestWaitRespectsContextCancel(t *testing.T) {
 	}
 }
 
+func TestTipSetMessages(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	cst, chainStore, msgStore, waiter := setupTest(t)
+
+	m1, m2, m3 := newSignedMessage(), newSignedMessage(), newSignedMessage()
+	headTipSet, err := chainStore.GetTipSet(chainStore.GetHead())
+	require.NoError(t, err)
+	chainWithMsgs := newChainWithMessages(cst, msgStore, headTipSet, smsgsSet{smsgs{m1, m2, m3}})
+	ts := chainWithMsgs[len(chainWithMsgs)-1]
+	require.Equal(t, 1, ts.Len())
+	require.NoError(t, chainStore.PutTipSetMetadata(ctx, &chain.TipSetMetadata{
+		TipSet:          ts,
+		TipSetStateRoot: ts.ToSlice()[0].StateRoot,
+		TipSetReceipts:  ts.ToSlice()[0].MessageReceipts,
+	}))
+
+	chainMsgs, err := waiter.TipSetMessages(ctx, ts)
+	require.NoError(t, err)
+	require.Len(t, chainMsgs, 3)
+
+	expected := smsgs{m1, m2, m3}
+	for i, smsg := range expected {
+		assert.True(t, types.SmsgCidsEqual(smsg, chainMsgs[i].Message))
+		assert.Equal(t, ts.At(0).Cid(), chainMsgs[i].Block.Cid())
+
+		// newChainWithMessages records each message's cid as its receipt's return value
+		c, err := smsg.Cid()
+		require.NoError(t, err)
+		assert.Equal(t, c.Bytes(), chainMsgs[i].Receipt.Return[0])
+	}
+}
+
 // NewChainWithMessages creates a chain of tipsets containing the given messages
 // and stores them in the given store.  Note the msg arguments are slices of
 // slices of messages -- each slice of slices goes into a successive tipset,
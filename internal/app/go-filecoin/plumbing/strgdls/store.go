@@ -1,6 +1,7 @@
 package strgdls
 
 import (
+	"github.com/ipfs/go-cid"
 	"github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-datastore/query"
 	"github.com/pkg/errors"
@@ -18,6 +19,12 @@ type Store struct {
 // StorageDealPrefix is the datastore prefix for storage deals
 const StorageDealPrefix = "storagedeals"
 
+// DealTranscriptPrefix is the datastore prefix for deal negotiation transcripts
+const DealTranscriptPrefix = "dealtranscripts"
+
+// ErrTranscriptNotFound means there is no transcript for a proposal
+var ErrTranscriptNotFound = errors.New("deal transcript not found")
+
 // New returns a new Store.
 func New(dealsDatastore repo.Datastore) *Store {
 	return &Store{dealsDs: dealsDatastore}
@@ -48,3 +55,35 @@ func (store *Store) Put(storageDeal *storagedeal.Deal) error {
 
 	return nil
 }
+
+// PutTranscript puts the negotiation transcript into the datastore
+func (store *Store) PutTranscript(transcript *storagedeal.Transcript) error {
+	datum, err := encoding.Encode(transcript)
+	if err != nil {
+		return errors.Wrap(err, "could not marshal deal transcript")
+	}
+
+	key := datastore.KeyWithNamespaces([]string{DealTranscriptPrefix, transcript.ProposalCid.String()})
+	if err := store.dealsDs.Put(key, datum); err != nil {
+		return errors.Wrap(err, "could not save deal transcript to disk")
+	}
+	return nil
+}
+
+// GetTranscript gets the negotiation transcript of the proposal with the given cid
+func (store *Store) GetTranscript(proposalCid cid.Cid) (*storagedeal.Transcript, error) {
+	key := datastore.KeyWithNamespaces([]string{DealTranscriptPrefix, proposalCid.String()})
+	datum, err := store.dealsDs.Get(key)
+	if err == datastore.ErrNotFound {
+		return nil, ErrTranscriptNotFound
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "could not read deal transcript from disk")
+	}
+
+	var transcript storagedeal.Transcript
+	if err := encoding.Decode(datum, &transcript); err != nil {
+		return nil, errors.Wrap(err, "could not unmarshal deal transcript")
+	}
+	return &transcript, nil
+}
@@ -101,3 +101,30 @@ func TestDealStoreRoundTrip(t *testing.T) {
 	assert.Equal(t, totalPrice, retrievedDeal.Proposal.Payment.Vouchers[0].Amount)
 	assert.Equal(t, *validAt, retrievedDeal.Proposal.Payment.Vouchers[0].ValidAt)
 }
+
+func TestDealTranscriptRoundTrip(t *testing.T) {
+	tf.UnitTest(t)
+
+	store := strgdls.New(repo.NewInMemoryRepo().DealsDs)
+	proposalCid, err := convert.ToCid("proposal")
+	require.NoError(t, err)
+
+	_, err = store.GetTranscript(proposalCid)
+	assert.Equal(t, strgdls.ErrTranscriptNotFound, err)
+
+	transcript := &storagedeal.Transcript{ProposalCid: proposalCid, Miner: address.NewForTestGetter()()}
+	transcript.Record("proposal sent", "to %s", "peer")
+	transcript.Record("response received", "state: %s", storagedeal.Rejected)
+	require.NoError(t, store.PutTranscript(transcript))
+
+	retrieved, err := store.GetTranscript(proposalCid)
+	require.NoError(t, err)
+	assert.Equal(t, transcript, retrieved)
+
+	// Transcripts are not listed as deals.
+	dealIterator, err := store.Iterator()
+	require.NoError(t, err)
+	entries, err := (*dealIterator).Rest()
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
 	go_sectorbuilder "github.com/filecoin-project/go-sectorbuilder"
@@ -14,6 +15,8 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing"
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	minerActor "github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
@@ -64,11 +67,32 @@ func (a *API) ChainHead() (block.TipSet, error) {
 	return ChainHead(a)
 }
 
+// ChainCheckWeight returns the lowest tipset between fromHeight and toHeight
+// whose weight does not exceed its parent's, or nil if there is none
+func (a *API) ChainCheckWeight(ctx context.Context, fromHeight, toHeight uint64) (*chain.WeightViolation, error) {
+	return ChainCheckWeight(ctx, a, fromHeight, toHeight)
+}
+
+// ChainTipSetAtHeight returns the tipset whose state is in effect at height
+func (a *API) ChainTipSetAtHeight(ctx context.Context, height uint64) (block.TipSet, error) {
+	return ChainTipSetAtHeight(ctx, a, height)
+}
+
+// ChainOrphans returns the validated blocks that are not ancestors of the head
+func (a *API) ChainOrphans(ctx context.Context) ([]*block.Block, error) {
+	return ChainOrphans(ctx, a)
+}
+
 // ChainGetFullBlock returns the full block given the header cid
 func (a *API) ChainGetFullBlock(ctx context.Context, id cid.Cid) (*block.FullBlock, error) {
 	return GetFullBlock(ctx, a, id)
 }
 
+// ChainBlock returns the block with the given cid with its messages and their receipts
+func (a *API) ChainBlock(ctx context.Context, id cid.Cid) (*BlockWithReceipts, error) {
+	return ChainBlock(ctx, a, id)
+}
+
 // CreatePayments establishes a payment channel and create multiple payments against it
 func (a *API) CreatePayments(ctx context.Context, config CreatePaymentsParams) (*CreatePaymentsReturn, error) {
 	return CreatePayments(ctx, a, config)
@@ -96,6 +120,16 @@ func (a *API) DealRedeemPreview(ctx context.Context, fromAddr address.Address, d
 	return DealRedeemPreview(ctx, a, fromAddr, dealCid)
 }
 
+// ClientListDeals lists the deals this node proposed as a client
+func (a *API) ClientListDeals(ctx context.Context, ownMiner address.Address, states ...storagedeal.State) ([]DealRecord, error) {
+	return ClientListDeals(ctx, a, ownMiner, states...)
+}
+
+// MinerListDealRecords lists the deals the given miner received
+func (a *API) MinerListDealRecords(ctx context.Context, minerAddr address.Address, states ...storagedeal.State) ([]DealRecord, error) {
+	return MinerListDealRecords(ctx, a, minerAddr, states...)
+}
+
 // DealsLs returns a channel with all deals
 func (a *API) DealsLs(ctx context.Context) (<-chan *StorageDealLsResult, error) {
 	return DealsLs(ctx, a)
@@ -107,6 +141,41 @@ func (a *API) MessagePoolWait(ctx context.Context, messageCount uint) ([]*types.
 	return MessagePoolWait(ctx, a, messageCount)
 }
 
+// Health checks the chain sync, message pool, wallet and peer connections of
+// the node.
+func (a *API) Health(ctx context.Context) *HealthReport {
+	return Health(ctx, a)
+}
+
+// MessagePoolEvict removes a pending message and the pending messages that
+// depend on it from the message pool.
+func (a *API) MessagePoolEvict(msgCid cid.Cid) ([]cid.Cid, error) {
+	return MessagePoolEvict(a, msgCid)
+}
+
+// MessagePoolImport adds the signed messages read from r to the message pool.
+func (a *API) MessagePoolImport(ctx context.Context, r io.Reader) (*MessagePoolImportResult, error) {
+	return MessagePoolImport(ctx, a, r)
+}
+
+// MessagePoolConflicts reports the nonces a pending message shares with a
+// different pending message or one mined in the last depth tipsets.
+func (a *API) MessagePoolConflicts(ctx context.Context, depth uint64) ([]message.NonceConflict, error) {
+	return MessagePoolConflicts(ctx, a, depth)
+}
+
+// MessageEstimateGas dry-runs a message to estimate the gas limit and price
+// it needs
+func (a *API) MessageEstimateGas(ctx context.Context, from, to address.Address, value types.AttoFIL, method types.MethodID, params ...interface{}) (*GasEstimate, error) {
+	return MessageEstimateGas(ctx, a, from, to, value, method, params...)
+}
+
+// MessageReplayOne executes the message with the given cid against the state of
+// the tipset at baseKey without persisting the result.
+func (a *API) MessageReplayOne(ctx context.Context, msgCid cid.Cid, baseKey block.TipSetKey) (*msg.ReplayResult, error) {
+	return MessageReplayOne(ctx, a, msgCid, baseKey)
+}
+
 // MinerCreate creates a miner
 func (a *API) MinerCreate(
 	ctx context.Context,
@@ -180,11 +249,33 @@ func (a *API) MinerGetProvingWindow(ctx context.Context, minerAddr address.Addre
 	return MinerGetProvingWindow(ctx, a, minerAddr)
 }
 
+// MinerGetPendingSectors lists the committed but not yet proven sectors of the given miner
+func (a *API) MinerGetPendingSectors(ctx context.Context, minerAddr address.Address) ([]MinerPendingSector, error) {
+	return MinerGetPendingSectors(ctx, a, minerAddr)
+}
+
+// MinerGetFaults queries whether the given miner was slashed for missing its PoSt
+func (a *API) MinerGetFaults(ctx context.Context, minerAddr address.Address) (MinerFaults, error) {
+	return MinerGetFaults(ctx, a, minerAddr)
+}
+
+// MinerListDeals lists the deals the given miner has accepted, from this
+// node's deal store
+func (a *API) MinerListDeals(ctx context.Context, minerAddr address.Address) ([]MinerDeal, error) {
+	return MinerListDeals(ctx, a, minerAddr)
+}
+
 // MinerGetCollateral queries for the proving period of the given miner
 func (a *API) MinerGetCollateral(ctx context.Context, minerAddr address.Address) (types.AttoFIL, error) {
 	return MinerGetCollateral(ctx, a, minerAddr)
 }
 
+// MinerCheckSigningKeys verifies that the wallet holds usable keys for the
+// owner and worker of the configured miner
+func (a *API) MinerCheckSigningKeys(ctx context.Context) error {
+	return MinerCheckSigningKeys(ctx, a)
+}
+
 // MinerPreviewSetPrice calculates the amount of Gas needed for a call to MinerSetPrice.
 // This method accepts all the same arguments as MinerSetPrice.
 func (a *API) MinerPreviewSetPrice(
@@ -202,17 +293,39 @@ func (a *API) ProtocolParameters(ctx context.Context) (*ProtocolParams, error) {
 	return ProtocolParameters(ctx, a)
 }
 
+// MessageNextNonce returns the nonce the next message from the given address
+// must have, given its on-chain nonce and its pending messages.
+func (a *API) MessageNextNonce(ctx context.Context, addr address.Address) (uint64, error) {
+	return MessageNextNonce(ctx, a, addr)
+}
+
 // WalletBalance returns the current balance of the given wallet address.
 func (a *API) WalletBalance(ctx context.Context, address address.Address) (types.AttoFIL, error) {
 	return WalletBalance(ctx, a, address)
 }
 
+// WalletTotalBalance sums the current balances of every address in the wallet
+func (a *API) WalletTotalBalance(ctx context.Context) (types.AttoFIL, error) {
+	return WalletTotalBalance(ctx, a)
+}
+
 // WalletDefaultAddress returns a default wallet address from the config.
 // If none is set it picks the first address in the wallet and sets it as the default in the config.
 func (a *API) WalletDefaultAddress() (address.Address, error) {
 	return WalletDefaultAddress(a)
 }
 
+// WalletSetDefaultAddress makes the given wallet address the default address.
+func (a *API) WalletSetDefaultAddress(addr address.Address) error {
+	return WalletSetDefaultAddress(a, addr)
+}
+
+// WalletDeleteAddress removes the key of the given address from the wallet,
+// unless it is the default address.
+func (a *API) WalletDeleteAddress(addr address.Address) error {
+	return WalletDeleteAddress(a, addr)
+}
+
 // PaymentChannelLs lists payment channels for a given payer
 func (a *API) PaymentChannelLs(
 	ctx context.Context,
@@ -234,16 +347,45 @@ func (a *API) PaymentChannelVoucher(
 	return PaymentChannelVoucher(ctx, a, fromAddr, channel, amount, validAt, condition)
 }
 
+// PaymentChannelEscrow reports the funds a payer has locked in and available
+// from its payment channels
+func (a *API) PaymentChannelEscrow(ctx context.Context, payerAddr address.Address) (*Escrow, error) {
+	return PaymentChannelEscrow(ctx, a, payerAddr)
+}
+
+// PaymentChannelWithdraw reclaims a payer's expired payment channels
+func (a *API) PaymentChannelWithdraw(ctx context.Context, payerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (*Withdrawal, error) {
+	return PaymentChannelWithdraw(ctx, a, payerAddr, gasPrice, gasLimit)
+}
+
 // ClientListAsks returns a channel with asks from the latest chain state
 func (a *API) ClientListAsks(ctx context.Context) <-chan Ask {
 	return ClientListAsks(ctx, a)
 }
 
+// MarketOrderBook returns the unexpired asks on chain and the open bids this
+// node knows of
+func (a *API) MarketOrderBook(ctx context.Context) (*OrderBook, error) {
+	return MarketOrderBook(ctx, a)
+}
+
+// ClientFindMiners returns the miners meeting the given power and price
+// criteria, most powerful first
+func (a *API) ClientFindMiners(ctx context.Context, minPower *types.BytesAmount, maxPrice *types.AttoFIL) ([]MinerCandidate, error) {
+	return ClientFindMiners(ctx, a, minPower, maxPrice)
+}
+
 // ClientValidateDeal checks to see that a storage deal is in the `Complete` state, and that its PIP is valid
 func (a *API) ClientValidateDeal(ctx context.Context, proposalCid cid.Cid, proofInfo *storagedeal.ProofInfo) error {
 	return ClientVerifyStorageDeal(ctx, a, proposalCid, proofInfo)
 }
 
+// ClientDealLifetime reports how many blocks remain until the deal this node
+// proposed expires
+func (a *API) ClientDealLifetime(ctx context.Context, proposalCid cid.Cid) (*DealLifetime, error) {
+	return ClientDealLifetime(ctx, a, proposalCid)
+}
+
 // CalculatePoSt invokes the sector builder to calculate a proof-of-spacetime.
 func (a *API) CalculatePoSt(ctx context.Context, sortedCommRs go_sectorbuilder.SortedSectorInfo, seed types.PoStChallengeSeed) (types.PoStProof, error) {
 	return CalculatePoSt(ctx, a, sortedCommRs, seed)
@@ -269,7 +411,19 @@ func (a *API) PingMinerWithTimeout(
 	return PingMinerWithTimeout(ctx, minerPID, timeout, a)
 }
 
+// NetworkHelloReport performs the hello handshake with a peer and reports
+// both sides of it
+func (a *API) NetworkHelloReport(ctx context.Context, pid peer.ID) (*HelloReport, error) {
+	return NetworkHelloReport(ctx, a, pid)
+}
+
+// NetworkProtocols lists the protocols the node handles and the core
+// protocols it does not, and which of them pid supports if it is not empty
+func (a *API) NetworkProtocols(pid peer.ID) ([]ProtocolInfo, error) {
+	return NetworkProtocols(a, pid)
+}
+
 // MinerSetWorkerAddress sets the miner worker address to the provided address
-func (a *API) MinerSetWorkerAddress(ctx context.Context, toAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
-	return MinerSetWorkerAddress(ctx, a, toAddr, gasPrice, gasLimit)
+func (a *API) MinerSetWorkerAddress(ctx context.Context, minerAddr, toAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (cid.Cid, error) {
+	return MinerSetWorkerAddress(ctx, a, minerAddr, toAddr, gasPrice, gasLimit)
 }
@@ -2,9 +2,15 @@ package porcelain
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 
+	"github.com/ipfs/go-ipfs-blockstore"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
@@ -20,9 +26,75 @@ type chainHeadPlumbing interface {
 	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
 }
 
+// ErrNoChainHead is returned by ChainHead when the chain store has no head,
+// i.e. no genesis block was loaded.
+var ErrNoChainHead = errors.New("chain store has no head tipset")
+
 // ChainHead gets the current head tipset from plumbing.
 func ChainHead(plumbing chainHeadPlumbing) (block.TipSet, error) {
-	return plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	key := plumbing.ChainHeadKey()
+	if key.Empty() {
+		return block.UndefTipSet, ErrNoChainHead
+	}
+	return plumbing.ChainTipSet(key)
+}
+
+type chainCheckWeightPlumbing interface {
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+}
+
+// ChainCheckWeight walks the chain from the head and returns the lowest
+// tipset with height in [fromHeight, toHeight] whose weight does not strictly
+// exceed its parent's, or nil if weight increases throughout.
+func ChainCheckWeight(ctx context.Context, plumbing chainCheckWeightPlumbing, fromHeight, toHeight uint64) (*chain.WeightViolation, error) {
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.FindWeightViolation(iter, fromHeight, toHeight)
+}
+
+type chainTipSetAtHeightPlumbing interface {
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+}
+
+// ChainTipSetAtHeight returns the tipset on the current chain whose state is
+// in effect at height: the tipset at that height, or the closest one below it
+// when the height was a null round.
+func ChainTipSetAtHeight(ctx context.Context, plumbing chainTipSetAtHeightPlumbing, height uint64) (block.TipSet, error) {
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return block.UndefTipSet, err
+	}
+	for ; !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return block.UndefTipSet, err
+		}
+		h, err := iter.Value().Height()
+		if err != nil {
+			return block.UndefTipSet, err
+		}
+		if h <= height {
+			return iter.Value(), nil
+		}
+	}
+	return block.UndefTipSet, fmt.Errorf("no tipset at or below height %d", height)
+}
+
+type chainOrphansPlumbing interface {
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+	ChainTipSets() []block.TipSet
+}
+
+// ChainOrphans returns the blocks the node has validated that are not
+// ancestors of the current head, lowest first. These are the blocks of forks
+// the node has abandoned, for instance after a reorg.
+func ChainOrphans(ctx context.Context, plumbing chainOrphansPlumbing) ([]*block.Block, error) {
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return chain.FindOrphans(iter, plumbing.ChainTipSets())
 }
 
 type fullBlockPlumbing interface {
@@ -48,6 +120,93 @@ func GetFullBlock(ctx context.Context, plumbing fullBlockPlumbing, id cid.Cid) (
 	return &out, nil
 }
 
+// ErrBlockNotFound is returned by ChainBlock when the node does not have the
+// requested block.
+var ErrBlockNotFound = errors.New("block not found")
+
+// BlockWithReceipts is a block with its messages and their receipts.
+type BlockWithReceipts struct {
+	Header   *block.Block
+	Messages []*types.SignedMessage
+	// Receipts holds the receipt of each of Messages, in the same order. It is
+	// empty if the block is not on the current chain.
+	Receipts []*types.MessageReceipt
+}
+
+type chainBlockPlumbing interface {
+	ChainGetBlock(context.Context, cid.Cid) (*block.Block, error)
+	ChainGetMessages(context.Context, types.TxMeta) ([]*types.SignedMessage, error)
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+	MessagesInTipSet(ctx context.Context, ts block.TipSet) ([]*msg.ChainMessage, error)
+}
+
+// ChainBlock returns the block with the given cid with its messages and, if
+// the block is on the current chain, their receipts from executing the tipset
+// that includes the block.
+func ChainBlock(ctx context.Context, plumbing chainBlockPlumbing, id cid.Cid) (*BlockWithReceipts, error) {
+	header, err := plumbing.ChainGetBlock(ctx, id)
+	if err != nil {
+		if errors.Cause(err) == blockstore.ErrNotFound {
+			return nil, errors.Wrap(ErrBlockNotFound, id.String())
+		}
+		return nil, err
+	}
+	messages, err := plumbing.ChainGetMessages(ctx, header.Messages)
+	if err != nil {
+		return nil, err
+	}
+	out := &BlockWithReceipts{Header: header, Messages: messages}
+
+	ts, found, err := tipSetIncluding(ctx, plumbing, header)
+	if err != nil || !found {
+		return out, err
+	}
+	chainMsgs, err := plumbing.MessagesInTipSet(ctx, ts)
+	if err != nil {
+		return nil, err
+	}
+	receipts := make(map[cid.Cid]*types.MessageReceipt, len(chainMsgs))
+	for _, chainMsg := range chainMsgs {
+		c, err := chainMsg.Message.Message.Cid()
+		if err != nil {
+			return nil, err
+		}
+		receipts[c] = chainMsg.Receipt
+	}
+	for _, m := range messages {
+		c, err := m.Message.Cid()
+		if err != nil {
+			return nil, err
+		}
+		out.Receipts = append(out.Receipts, receipts[c])
+	}
+	return out, nil
+}
+
+// tipSetIncluding returns the tipset of the current chain that includes blk.
+func tipSetIncluding(ctx context.Context, plumbing chainBlockPlumbing, blk *block.Block) (block.TipSet, bool, error) {
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return block.UndefTipSet, false, err
+	}
+	for ; !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return block.UndefTipSet, false, err
+		}
+		h, err := iter.Value().Height()
+		if err != nil {
+			return block.UndefTipSet, false, err
+		}
+		if h < uint64(blk.Height) {
+			break
+		}
+		if h == uint64(blk.Height) && iter.Value().Key().Has(blk.Cid()) {
+			return iter.Value(), true, nil
+		}
+	}
+	return block.UndefTipSet, false, nil
+}
+
 type getStableActorPlumbing interface {
 	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
 	ChainHeadKey() block.TipSetKey
@@ -0,0 +1,79 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/ipfs/go-ipfs-blockstore"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+type fakeChainHeadPlumbing struct {
+	t    *testing.T
+	head block.TipSet
+}
+
+func (plumbing *fakeChainHeadPlumbing) ChainHeadKey() block.TipSetKey {
+	return plumbing.head.Key()
+}
+
+func (plumbing *fakeChainHeadPlumbing) ChainTipSet(key block.TipSetKey) (block.TipSet, error) {
+	require.True(plumbing.t, key.Equals(plumbing.head.Key()))
+	return plumbing.head, nil
+}
+
+func TestChainHead(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("returns the head tipset", func(t *testing.T) {
+		head := th.RequireNewTipSet(t, &block.Block{Height: types.Uint64(7)})
+
+		ts, err := porcelain.ChainHead(&fakeChainHeadPlumbing{t: t, head: head})
+		require.NoError(t, err)
+		assert.True(t, head.Equals(ts))
+	})
+
+	t.Run("fails without a head", func(t *testing.T) {
+		_, err := porcelain.ChainHead(&fakeChainHeadPlumbing{t: t, head: block.UndefTipSet})
+		assert.Equal(t, porcelain.ErrNoChainHead, err)
+	})
+}
+
+type fakeChainBlockPlumbing struct{}
+
+func (plumbing *fakeChainBlockPlumbing) ChainGetBlock(_ context.Context, c cid.Cid) (*block.Block, error) {
+	return nil, errors.Wrapf(blockstore.ErrNotFound, "failed to get block %s", c)
+}
+
+func (plumbing *fakeChainBlockPlumbing) ChainGetMessages(context.Context, types.TxMeta) ([]*types.SignedMessage, error) {
+	panic("not implemented")
+}
+
+func (plumbing *fakeChainBlockPlumbing) ChainLs(context.Context) (*chain.TipsetIterator, error) {
+	panic("not implemented")
+}
+
+func (plumbing *fakeChainBlockPlumbing) MessagesInTipSet(context.Context, block.TipSet) ([]*msg.ChainMessage, error) {
+	panic("not implemented")
+}
+
+func TestChainBlock(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("reports an unknown block as not found", func(t *testing.T) {
+		_, err := porcelain.ChainBlock(context.Background(), &fakeChainBlockPlumbing{}, types.NewCidForTestGetter()())
+		require.Error(t, err)
+		assert.Equal(t, porcelain.ErrBlockNotFound, errors.Cause(err))
+	})
+}
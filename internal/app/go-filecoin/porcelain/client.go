@@ -3,6 +3,7 @@ package porcelain
 import (
 	"context"
 	"math/big"
+	"sort"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
@@ -96,6 +97,84 @@ func listAsksFromActorResult(ctx context.Context, plumbing claPlubming, actorRes
 	return nil
 }
 
+// MinerCandidate is a miner that may be chosen for a storage deal, with its
+// cheapest unexpired ask.
+type MinerCandidate struct {
+	Miner  address.Address
+	Power  *types.BytesAmount
+	Price  types.AttoFIL
+	AskID  uint64
+	Expiry *types.BlockHeight
+}
+
+type cfmPlumbing interface {
+	claPlubming
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+}
+
+// ClientFindMiners returns the miners with an unexpired ask that have at
+// least minPower and, if maxPrice is not nil, whose cheapest ask costs at
+// most maxPrice. Miners are sorted by power, most powerful first, and then by
+// price.
+func ClientFindMiners(ctx context.Context, plumbing cfmPlumbing, minPower *types.BytesAmount, maxPrice *types.AttoFIL) ([]MinerCandidate, error) {
+	head, err := plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, err
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, err
+	}
+	height := types.NewBlockHeight(h)
+
+	cheapest := make(map[address.Address]Ask)
+	for ask := range ClientListAsks(ctx, plumbing) {
+		if ask.Error != nil {
+			return nil, ask.Error
+		}
+		if ask.Expiry.LessThan(height) {
+			continue
+		}
+		if maxPrice != nil && ask.Price.GreaterThan(*maxPrice) {
+			continue
+		}
+		if best, ok := cheapest[ask.Miner]; ok && !ask.Price.LessThan(best.Price) {
+			continue
+		}
+		cheapest[ask.Miner] = ask
+	}
+
+	candidates := []MinerCandidate{}
+	for minerAddr, ask := range cheapest {
+		power, err := MinerGetPower(ctx, plumbing, minerAddr)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get power of miner %s", minerAddr)
+		}
+		if minPower != nil && power.Power.LessThan(minPower) {
+			continue
+		}
+		candidates = append(candidates, MinerCandidate{
+			Miner:  minerAddr,
+			Power:  &power.Power,
+			Price:  ask.Price,
+			AskID:  ask.ID,
+			Expiry: ask.Expiry,
+		})
+	}
+
+	sort.Slice(candidates, func(i, j int) bool {
+		a, b := candidates[i], candidates[j]
+		if !a.Power.Equal(b.Power) {
+			return b.Power.LessThan(a.Power)
+		}
+		if !a.Price.Equal(b.Price) {
+			return a.Price.LessThan(b.Price)
+		}
+		return a.Miner.String() < b.Miner.String()
+	})
+	return candidates, nil
+}
+
 // The subset of plumbing used by ClientVerifyStorageDeal
 type cvsdPlumbing interface {
 	ChainHeadKey() block.TipSetKey
@@ -128,6 +207,60 @@ func ClientVerifyStorageDeal(ctx context.Context, plumbing cvsdPlumbing, proposa
 	return nil
 }
 
+// DealLifetime describes how long a storage deal lasts and how much of it
+// remains at the chain head.
+type DealLifetime struct {
+	PieceRef    cid.Cid
+	StartHeight uint64
+	Duration    uint64
+	EndHeight   uint64
+	// Remaining is the number of blocks from the head to EndHeight, zero
+	// once the deal has expired.
+	Remaining uint64
+	Expired   bool
+	// State is the state of the deal when it was last recorded.
+	State storagedeal.State
+}
+
+// The subset of plumbing used by ClientDealLifetime
+type cdlPlumbing interface {
+	ChainHeadKey() block.TipSetKey
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+	DealGet(ctx context.Context, proposalCid cid.Cid) (*storagedeal.Deal, error)
+}
+
+// ClientDealLifetime reports the start, end and remaining blocks of the deal
+// this node proposed with the given proposal cid.
+func ClientDealLifetime(ctx context.Context, plumbing cdlPlumbing, proposalCid cid.Cid) (*DealLifetime, error) {
+	deal, err := plumbing.DealGet(ctx, proposalCid)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get deal")
+	}
+
+	head, err := plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, err
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := &DealLifetime{
+		PieceRef:    deal.Proposal.PieceRef,
+		StartHeight: deal.StartHeight,
+		Duration:    deal.Proposal.Duration,
+		EndHeight:   deal.StartHeight + deal.Proposal.Duration,
+		State:       deal.Response.State,
+	}
+	if h < lifetime.EndHeight {
+		lifetime.Remaining = lifetime.EndHeight - h
+	} else {
+		lifetime.Expired = true
+	}
+	return lifetime, nil
+}
+
 func getAskByID(ctx context.Context, plumbing claPlubming, addr address.Address, id uint64) (Ask, error) {
 	ret, err := plumbing.MessageQuery(ctx, address.Undef, addr, miner.GetAsk, plumbing.ChainHeadKey(), big.NewInt(int64(id)))
 	if err != nil {
@@ -2,21 +2,27 @@ package porcelain_test
 
 import (
 	"context"
+	"fmt"
 	"math/big"
 	"testing"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/power"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/state"
 
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/ipfs/go-cid"
 	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 type claPlumbing struct {
@@ -134,3 +140,202 @@ func TestClientListAsks(t *testing.T) {
 		assert.Error(t, result.Error, "MESSAGE FAILURE")
 	})
 }
+
+type cdlPlumbing struct {
+	deal *storagedeal.Deal
+	head block.TipSet
+}
+
+func (cdl *cdlPlumbing) ChainHeadKey() block.TipSetKey {
+	return cdl.head.Key()
+}
+
+func (cdl *cdlPlumbing) ChainTipSet(_ block.TipSetKey) (block.TipSet, error) {
+	return cdl.head, nil
+}
+
+func (cdl *cdlPlumbing) DealGet(_ context.Context, c cid.Cid) (*storagedeal.Deal, error) {
+	if cdl.deal == nil || !cdl.deal.Response.ProposalCid.Equals(c) {
+		return nil, porcelain.ErrDealNotFound
+	}
+	return cdl.deal, nil
+}
+
+func TestClientDealLifetime(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	proposalCid := types.CidFromString(t, "proposal")
+	pieceRef := types.CidFromString(t, "piece")
+	deal := &storagedeal.Deal{
+		Proposal:    &storagedeal.SignedProposal{Proposal: storagedeal.Proposal{PieceRef: pieceRef, Duration: 20}},
+		Response:    &storagedeal.SignedResponse{Response: storagedeal.Response{State: storagedeal.Accepted, ProposalCid: proposalCid}},
+		StartHeight: 10,
+	}
+	plumbingAt := func(height uint64) *cdlPlumbing {
+		head, err := block.NewTipSet(&block.Block{Height: types.Uint64(height)})
+		require.NoError(t, err)
+		return &cdlPlumbing{deal: deal, head: head}
+	}
+
+	t.Run("active deal", func(t *testing.T) {
+		lifetime, err := porcelain.ClientDealLifetime(ctx, plumbingAt(15), proposalCid)
+		require.NoError(t, err)
+		assert.Equal(t, &porcelain.DealLifetime{
+			PieceRef:    pieceRef,
+			StartHeight: 10,
+			Duration:    20,
+			EndHeight:   30,
+			Remaining:   15,
+			State:       storagedeal.Accepted,
+		}, lifetime)
+	})
+
+	t.Run("expired deal", func(t *testing.T) {
+		for _, h := range []uint64{30, 45} {
+			lifetime, err := porcelain.ClientDealLifetime(ctx, plumbingAt(h), proposalCid)
+			require.NoError(t, err)
+			assert.Equal(t, uint64(0), lifetime.Remaining)
+			assert.True(t, lifetime.Expired)
+		}
+	})
+
+	t.Run("unknown deal", func(t *testing.T) {
+		_, err := porcelain.ClientDealLifetime(ctx, plumbingAt(15), types.CidFromString(t, "other"))
+		assert.Error(t, err)
+	})
+}
+
+type cfmMiner struct {
+	power uint64
+	asks  []miner.Ask
+}
+
+type cfmPlumbing struct {
+	miners map[address.Address]cfmMiner
+	head   block.TipSet
+}
+
+func (cfm *cfmPlumbing) ActorLs(ctx context.Context) (<-chan state.GetAllActorsResult, error) {
+	out := make(chan state.GetAllActorsResult)
+	go func() {
+		defer close(out)
+		for addr := range cfm.miners {
+			out <- state.GetAllActorsResult{
+				Address: addr.String(),
+				Actor:   &actor.Actor{Code: types.MinerActorCodeCid},
+			}
+		}
+	}()
+	return out, nil
+}
+
+func (cfm *cfmPlumbing) ChainHeadKey() block.TipSetKey {
+	return cfm.head.Key()
+}
+
+func (cfm *cfmPlumbing) ChainTipSet(_ block.TipSetKey) (block.TipSet, error) {
+	return cfm.head, nil
+}
+
+func (cfm *cfmPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, _ block.TipSetKey, params ...interface{}) ([][]byte, error) {
+	switch method {
+	case miner.GetAsks:
+		var ids []types.Uint64
+		for _, ask := range cfm.miners[to].asks {
+			ids = append(ids, types.Uint64(ask.ID.Uint64()))
+		}
+		raw, err := encoding.Encode(ids)
+		return [][]byte{raw}, err
+	case miner.GetAsk:
+		id := params[0].(*big.Int)
+		for _, ask := range cfm.miners[to].asks {
+			if ask.ID.Cmp(id) == 0 {
+				raw, err := encoding.Encode(ask)
+				return [][]byte{raw}, err
+			}
+		}
+		return nil, fmt.Errorf("no ask %s", id)
+	case power.GetPowerReport:
+		minerAddr := params[0].(address.Address)
+		val := abi.Value{
+			Val:  types.NewPowerReport(cfm.miners[minerAddr].power, 0),
+			Type: abi.PowerReport,
+		}
+		raw, err := val.Serialize()
+		return [][]byte{raw}, err
+	case power.GetTotalPower:
+		return [][]byte{types.NewBytesAmount(100).Bytes()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+}
+
+func TestClientFindMiners(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addrGetter := address.NewForTestGetter()
+	strong, weak, idle, stale := addrGetter(), addrGetter(), addrGetter(), addrGetter()
+
+	ask := func(id int64, price, expiry uint64) miner.Ask {
+		return miner.Ask{
+			ID:     big.NewInt(id),
+			Price:  types.NewAttoFILFromFIL(price),
+			Expiry: types.NewBlockHeight(expiry),
+		}
+	}
+	head, err := block.NewTipSet(&block.Block{Height: 10})
+	require.NoError(t, err)
+	plumbing := &cfmPlumbing{
+		head: head,
+		miners: map[address.Address]cfmMiner{
+			strong: {power: 50, asks: []miner.Ask{ask(0, 30, 100), ask(1, 25, 100)}},
+			weak:   {power: 10, asks: []miner.Ask{ask(0, 5, 100)}},
+			// Ties on power are broken by price.
+			idle: {power: 10, asks: []miner.Ask{ask(0, 8, 100)}},
+			// A miner whose only ask has expired is not listed.
+			stale: {power: 90, asks: []miner.Ask{ask(0, 1, 9)}},
+		},
+	}
+	minerAddrs := func(candidates []porcelain.MinerCandidate) []address.Address {
+		var addrs []address.Address
+		for _, c := range candidates {
+			addrs = append(addrs, c.Miner)
+		}
+		return addrs
+	}
+
+	t.Run("sorts by power with the cheapest ask", func(t *testing.T) {
+		candidates, err := porcelain.ClientFindMiners(ctx, plumbing, nil, nil)
+		require.NoError(t, err)
+		assert.Equal(t, []address.Address{strong, weak, idle}, minerAddrs(candidates))
+		assert.Equal(t, porcelain.MinerCandidate{
+			Miner:  strong,
+			Power:  types.NewBytesAmount(50),
+			Price:  types.NewAttoFILFromFIL(25),
+			AskID:  1,
+			Expiry: types.NewBlockHeight(100),
+		}, candidates[0])
+	})
+
+	t.Run("filters by power", func(t *testing.T) {
+		candidates, err := porcelain.ClientFindMiners(ctx, plumbing, types.NewBytesAmount(11), nil)
+		require.NoError(t, err)
+		assert.Equal(t, []address.Address{strong}, minerAddrs(candidates))
+	})
+
+	t.Run("filters by price", func(t *testing.T) {
+		maxPrice := types.NewAttoFILFromFIL(6)
+		candidates, err := porcelain.ClientFindMiners(ctx, plumbing, nil, &maxPrice)
+		require.NoError(t, err)
+		assert.Equal(t, []address.Address{weak}, minerAddrs(candidates))
+	})
+
+	t.Run("no matches", func(t *testing.T) {
+		maxPrice := types.NewAttoFILFromFIL(6)
+		candidates, err := porcelain.ClientFindMiners(ctx, plumbing, types.NewBytesAmount(11), &maxPrice)
+		require.NoError(t, err)
+		assert.Empty(t, candidates)
+	})
+}
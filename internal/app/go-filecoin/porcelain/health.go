@@ -0,0 +1,153 @@
+package porcelain
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync/status"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// HealthState is the state of a subsystem of the node.
+type HealthState string
+
+const (
+	// HealthOK means the subsystem works as expected.
+	HealthOK = HealthState("ok")
+	// HealthDegraded means the subsystem works but the node may lag behind
+	// or run out of capacity.
+	HealthDegraded = HealthState("degraded")
+	// HealthFailed means the subsystem can't do its job.
+	HealthFailed = HealthState("failed")
+)
+
+// healthRank orders the states from best to worst.
+var healthRank = map[HealthState]int{
+	HealthOK:       0,
+	HealthDegraded: 1,
+	HealthFailed:   2,
+}
+
+// mpoolDegradedPercent is how full the message pool may get before it is
+// reported degraded.
+const mpoolDegradedPercent = 90
+
+// HealthCheck is the result of checking one subsystem.
+type HealthCheck struct {
+	State  HealthState
+	Detail string
+}
+
+// HealthReport is the health of each subsystem of the node. State is the
+// worst state of any of them.
+type HealthReport struct {
+	State       HealthState
+	Sync        HealthCheck
+	MessagePool HealthCheck
+	Wallet      HealthCheck
+	Peers       HealthCheck
+}
+
+type healthPlumbing interface {
+	ChainHeadKey() block.TipSetKey
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+	ConfigGet(dottedPath string) (interface{}, error)
+	MessagePoolPending() []*types.SignedMessage
+	NetworkPeers(ctx context.Context, verbose, latency, streams bool) (*net.SwarmConnInfos, error)
+	SyncerStatus() status.Status
+	WalletAddresses() []address.Address
+}
+
+// Health checks the chain sync, message pool, wallet and peer connections of
+// the node.
+func Health(ctx context.Context, plumbing healthPlumbing) *HealthReport {
+	report := &HealthReport{
+		Sync:        syncHealth(plumbing),
+		MessagePool: mpoolHealth(plumbing),
+		Wallet:      walletHealth(plumbing),
+		Peers:       peersHealth(ctx, plumbing),
+	}
+
+	report.State = HealthOK
+	for _, check := range []HealthCheck{report.Sync, report.MessagePool, report.Wallet, report.Peers} {
+		if healthRank[check.State] > healthRank[report.State] {
+			report.State = check.State
+		}
+	}
+	return report
+}
+
+func syncHealth(plumbing healthPlumbing) HealthCheck {
+	head, err := plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	if err != nil {
+		return HealthCheck{HealthFailed, errors.Wrap(err, "failed to load chain head").Error()}
+	}
+	height, err := head.Height()
+	if err != nil {
+		return HealthCheck{HealthFailed, err.Error()}
+	}
+
+	syncStatus := plumbing.SyncerStatus()
+	if !syncStatus.SyncingComplete || !syncStatus.SyncingFetchComplete {
+		return HealthCheck{HealthDegraded, fmt.Sprintf("catching up: at height %d, syncing to %d", height, syncStatus.SyncingHeight)}
+	}
+	return HealthCheck{HealthOK, fmt.Sprintf("in sync at height %d", height)}
+}
+
+func mpoolHealth(plumbing healthPlumbing) HealthCheck {
+	size := uint(len(plumbing.MessagePoolPending()))
+	maxSize, err := plumbing.ConfigGet("mpool.maxPoolSize")
+	if err != nil {
+		return HealthCheck{HealthFailed, err.Error()}
+	}
+	limit, ok := maxSize.(uint)
+	if !ok {
+		return HealthCheck{HealthFailed, "invalid mpool.maxPoolSize"}
+	}
+
+	detail := fmt.Sprintf("%d of %d messages", size, limit)
+	switch {
+	case size >= limit:
+		return HealthCheck{HealthFailed, "full: " + detail}
+	case size*100 >= limit*mpoolDegradedPercent:
+		return HealthCheck{HealthDegraded, "nearly full: " + detail}
+	default:
+		return HealthCheck{HealthOK, detail}
+	}
+}
+
+func walletHealth(plumbing healthPlumbing) HealthCheck {
+	addrs := plumbing.WalletAddresses()
+	if len(addrs) == 0 {
+		return HealthCheck{HealthFailed, "no addresses loaded"}
+	}
+	return HealthCheck{HealthOK, fmt.Sprintf("%d addresses loaded", len(addrs))}
+}
+
+func peersHealth(ctx context.Context, plumbing healthPlumbing) HealthCheck {
+	peers, err := plumbing.NetworkPeers(ctx, false, false, false)
+	if err != nil {
+		return HealthCheck{HealthFailed, errors.Wrap(err, "failed to list peers").Error()}
+	}
+	threshold, err := plumbing.ConfigGet("bootstrap.minPeerThreshold")
+	if err != nil {
+		return HealthCheck{HealthFailed, err.Error()}
+	}
+	minPeers, ok := threshold.(int)
+	if !ok {
+		return HealthCheck{HealthFailed, "invalid bootstrap.minPeerThreshold"}
+	}
+
+	count := len(peers.Peers)
+	detail := fmt.Sprintf("%d peers, minimum %d", count, minPeers)
+	// A node without peers can't sync or broadcast even if no minimum is set.
+	if count == 0 || count < minPeers {
+		return HealthCheck{HealthDegraded, detail}
+	}
+	return HealthCheck{HealthOK, detail}
+}
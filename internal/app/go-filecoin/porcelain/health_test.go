@@ -0,0 +1,129 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync/status"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+type fakeHealthPlumbing struct {
+	head     block.TipSet
+	status   status.Status
+	pending  []*types.SignedMessage
+	maxPool  uint
+	minPeers int
+	peers    int
+	addrs    []address.Address
+}
+
+func (p *fakeHealthPlumbing) ChainHeadKey() block.TipSetKey {
+	return p.head.Key()
+}
+
+func (p *fakeHealthPlumbing) ChainTipSet(_ block.TipSetKey) (block.TipSet, error) {
+	return p.head, nil
+}
+
+func (p *fakeHealthPlumbing) ConfigGet(dottedPath string) (interface{}, error) {
+	switch dottedPath {
+	case "mpool.maxPoolSize":
+		return p.maxPool, nil
+	case "bootstrap.minPeerThreshold":
+		return p.minPeers, nil
+	}
+	panic("unexpected config path " + dottedPath)
+}
+
+func (p *fakeHealthPlumbing) MessagePoolPending() []*types.SignedMessage {
+	return p.pending
+}
+
+func (p *fakeHealthPlumbing) NetworkPeers(_ context.Context, _, _, _ bool) (*net.SwarmConnInfos, error) {
+	infos := &net.SwarmConnInfos{}
+	for i := 0; i < p.peers; i++ {
+		infos.Peers = append(infos.Peers, net.SwarmConnInfo{})
+	}
+	return infos, nil
+}
+
+func (p *fakeHealthPlumbing) SyncerStatus() status.Status {
+	return p.status
+}
+
+func (p *fakeHealthPlumbing) WalletAddresses() []address.Address {
+	return p.addrs
+}
+
+func TestHealth(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	head, err := block.NewTipSet(&block.Block{Height: 5})
+	require.NoError(t, err)
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+
+	healthy := func() *fakeHealthPlumbing {
+		return &fakeHealthPlumbing{
+			head:     head,
+			status:   *status.NewDefaultChainStatus(),
+			maxPool:  10,
+			minPeers: 2,
+			peers:    3,
+			addrs:    []address.Address{address.TestAddress},
+		}
+	}
+
+	t.Run("all ok", func(t *testing.T) {
+		report := porcelain.Health(ctx, healthy())
+		assert.Equal(t, porcelain.HealthOK, report.State)
+		assert.Equal(t, porcelain.HealthCheck{State: porcelain.HealthOK, Detail: "in sync at height 5"}, report.Sync)
+		assert.Equal(t, porcelain.HealthCheck{State: porcelain.HealthOK, Detail: "0 of 10 messages"}, report.MessagePool)
+		assert.Equal(t, porcelain.HealthCheck{State: porcelain.HealthOK, Detail: "1 addresses loaded"}, report.Wallet)
+		assert.Equal(t, porcelain.HealthCheck{State: porcelain.HealthOK, Detail: "3 peers, minimum 2"}, report.Peers)
+	})
+
+	t.Run("catching up and too few peers", func(t *testing.T) {
+		plumbing := healthy()
+		plumbing.status.SyncingComplete = false
+		plumbing.status.SyncingHeight = 20
+		plumbing.peers = 1
+
+		report := porcelain.Health(ctx, plumbing)
+		assert.Equal(t, porcelain.HealthDegraded, report.State)
+		assert.Equal(t, porcelain.HealthDegraded, report.Sync.State)
+		assert.Contains(t, report.Sync.Detail, "syncing to 20")
+		assert.Equal(t, porcelain.HealthDegraded, report.Peers.State)
+	})
+
+	t.Run("no peers is degraded without a minimum", func(t *testing.T) {
+		plumbing := healthy()
+		plumbing.minPeers = 0
+		plumbing.peers = 0
+
+		report := porcelain.Health(ctx, plumbing)
+		assert.Equal(t, porcelain.HealthDegraded, report.Peers.State)
+	})
+
+	t.Run("full pool and empty wallet fail", func(t *testing.T) {
+		plumbing := healthy()
+		plumbing.pending = types.NewSignedMsgs(9, signer)
+		assert.Equal(t, porcelain.HealthDegraded, porcelain.Health(ctx, plumbing).MessagePool.State)
+
+		plumbing.pending = types.NewSignedMsgs(10, signer)
+		plumbing.addrs = nil
+		report := porcelain.Health(ctx, plumbing)
+		assert.Equal(t, porcelain.HealthFailed, report.State)
+		assert.Equal(t, porcelain.HealthFailed, report.MessagePool.State)
+		assert.Equal(t, porcelain.HealthFailed, report.Wallet.State)
+	})
+}
@@ -0,0 +1,162 @@
+package porcelain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/state"
+)
+
+// The subset of plumbing used by MessageNextNonce
+type mnnPlumbing interface {
+	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
+	MessagePoolPending() []*types.SignedMessage
+}
+
+// MessageNextNonce returns the nonce the next message from addr must have to
+// be mined: the actor's on-chain nonce, advanced past each consecutive nonce
+// already taken by a pending message in the pool. A gap in the pending nonces,
+// e.g. left by evicting messages from the pool, is the next nonce.
+func MessageNextNonce(ctx context.Context, plumbing mnnPlumbing, addr address.Address) (uint64, error) {
+	act, err := plumbing.ActorGet(ctx, addr)
+	if err != nil && !state.IsActorNotFoundError(err) {
+		return 0, errors.Wrapf(err, "failed to get actor %s", addr)
+	}
+	nonce, err := actor.NextNonce(act)
+	if err != nil {
+		return 0, err
+	}
+
+	pending := make(map[uint64]bool)
+	for _, smsg := range plumbing.MessagePoolPending() {
+		if smsg.Message.From == addr {
+			pending[uint64(smsg.Message.CallSeqNum)] = true
+		}
+	}
+	for pending[nonce] {
+		nonce++
+	}
+	return nonce, nil
+}
+
+// The subset of plumbing used by MessageReplayOne
+type mroPlumbing interface {
+	MessageFind(ctx context.Context, msgCid cid.Cid) (*msg.ChainMessage, bool, error)
+	MessagePoolGet(cid cid.Cid) (*types.SignedMessage, bool)
+	MessageReplay(ctx context.Context, message *types.UnsignedMessage, baseKey block.TipSetKey) (*msg.ReplayResult, error)
+}
+
+// MessageReplayOne executes the message with the given cid, found on chain or
+// in the message pool, against the state of the tipset at baseKey.
+func MessageReplayOne(ctx context.Context, plumbing mroPlumbing, msgCid cid.Cid, baseKey block.TipSetKey) (*msg.ReplayResult, error) {
+	signed, inPool := plumbing.MessagePoolGet(msgCid)
+	if !inPool {
+		chainMsg, found, err := plumbing.MessageFind(ctx, msgCid)
+		if err != nil {
+			return nil, err
+		}
+		if !found {
+			return nil, errors.Errorf("message %s not found on chain or in the message pool", msgCid)
+		}
+		signed = chainMsg.Message
+	}
+	return plumbing.MessageReplay(ctx, &signed.Message, baseKey)
+}
+
+// GasEstimate is the gas a message is expected to need.
+type GasEstimate struct {
+	// GasLimit is the gas the message used when dry-run, plus a margin for
+	// state changes before it is mined.
+	GasLimit types.GasUnits
+	// GasPrice is the median gas price of the messages in the message pool,
+	// but no less than this node's minimum gas price for mining.
+	GasPrice types.AttoFIL
+}
+
+// gasLimitMarginPercent is how much gas an estimate adds to the gas a
+// message used when it was dry-run.
+const gasLimitMarginPercent = 10
+
+// minSuggestedGasPrice is the lowest gas price an estimate suggests.
+var minSuggestedGasPrice = types.NewGasPrice(1)
+
+// The subset of plumbing used by MessageEstimateGas
+type megPlumbing interface {
+	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
+	ChainHeadKey() block.TipSetKey
+	ConfigGet(dottedPath string) (interface{}, error)
+	MessagePoolPending() []*types.SignedMessage
+	MessageReplay(ctx context.Context, message *types.UnsignedMessage, baseKey block.TipSetKey) (*msg.ReplayResult, error)
+}
+
+// MessageEstimateGas dry-runs a message from `from` against the state of the
+// chain head to estimate its gas limit, and suggests a gas price. It fails if
+// the message would not execute successfully.
+func MessageEstimateGas(ctx context.Context, plumbing megPlumbing, from, to address.Address, value types.AttoFIL, method types.MethodID, params ...interface{}) (*GasEstimate, error) {
+	encodedParams, err := abi.ToEncodedValues(params...)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid params")
+	}
+	fromActor, err := plumbing.ActorGet(ctx, from)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get actor %s", from)
+	}
+	nonce, err := actor.NextNonce(fromActor)
+	if err != nil {
+		return nil, err
+	}
+
+	// At a gas price of one, the gas charged is the gas used.
+	dryRun := types.NewMeteredMessage(from, to, nonce, value, method, encodedParams, types.NewGasPrice(1), types.BlockGasLimit)
+	result, err := plumbing.MessageReplay(ctx, dryRun, plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to dry-run message")
+	}
+	if result.Receipt.ExitCode != 0 {
+		return nil, errors.Errorf("message would fail with exit code %d: %s", result.Receipt.ExitCode, result.Error)
+	}
+	used := result.Receipt.GasAttoFIL.AsBigInt().Uint64()
+
+	price, err := suggestGasPrice(plumbing)
+	if err != nil {
+		return nil, err
+	}
+	return &GasEstimate{
+		GasLimit: types.NewGasUnits(used + used*gasLimitMarginPercent/100),
+		GasPrice: price,
+	}, nil
+}
+
+func suggestGasPrice(plumbing megPlumbing) (types.AttoFIL, error) {
+	price := minSuggestedGasPrice
+
+	pending := plumbing.MessagePoolPending()
+	if len(pending) > 0 {
+		prices := make([]types.AttoFIL, len(pending))
+		for i, smsg := range pending {
+			prices[i] = smsg.Message.GasPrice
+		}
+		sort.Slice(prices, func(i, j int) bool { return prices[i].LessThan(prices[j]) })
+		if median := prices[len(prices)/2]; median.GreaterThan(price) {
+			price = median
+		}
+	}
+
+	ret, err := plumbing.ConfigGet("mining.min_gas_price")
+	if err != nil {
+		return types.ZeroAttoFIL, err
+	}
+	if minPrice := ret.(types.AttoFIL); minPrice.GreaterThan(price) {
+		price = minPrice
+	}
+	return price, nil
+}
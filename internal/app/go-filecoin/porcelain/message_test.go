@@ -0,0 +1,224 @@
+package porcelain_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+type fakeMessageReplayPlumbing struct {
+	pool     map[cid.Cid]*types.SignedMessage
+	chain    map[cid.Cid]*types.SignedMessage
+	replayed *types.UnsignedMessage
+	base     block.TipSetKey
+}
+
+func (p *fakeMessageReplayPlumbing) MessageFind(ctx context.Context, msgCid cid.Cid) (*msg.ChainMessage, bool, error) {
+	signed, ok := p.chain[msgCid]
+	if !ok {
+		return nil, false, nil
+	}
+	return &msg.ChainMessage{Message: signed}, true, nil
+}
+
+func (p *fakeMessageReplayPlumbing) MessagePoolGet(msgCid cid.Cid) (*types.SignedMessage, bool) {
+	signed, ok := p.pool[msgCid]
+	return signed, ok
+}
+
+func (p *fakeMessageReplayPlumbing) MessageReplay(ctx context.Context, message *types.UnsignedMessage, baseKey block.TipSetKey) (*msg.ReplayResult, error) {
+	p.replayed = message
+	p.base = baseKey
+	return &msg.ReplayResult{Receipt: &types.MessageReceipt{}}, nil
+}
+
+func TestMessageReplayOne(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	ki := types.MustGenerateKeyInfo(2, 42)
+	signer := types.NewMockSigner(ki)
+	msgs := types.NewSignedMsgs(2, signer)
+	onChainCid, err := msgs[0].Cid()
+	require.NoError(t, err)
+	inPoolCid, err := msgs[1].Cid()
+	require.NoError(t, err)
+
+	baseKey := block.NewTipSetKey(types.CidFromString(t, "base"))
+	newPlumbing := func() *fakeMessageReplayPlumbing {
+		return &fakeMessageReplayPlumbing{
+			chain: map[cid.Cid]*types.SignedMessage{onChainCid: msgs[0]},
+			pool:  map[cid.Cid]*types.SignedMessage{inPoolCid: msgs[1]},
+		}
+	}
+
+	t.Run("replays a message found on chain", func(t *testing.T) {
+		plumbing := newPlumbing()
+		_, err := porcelain.MessageReplayOne(ctx, plumbing, onChainCid, baseKey)
+		require.NoError(t, err)
+		assert.Equal(t, &msgs[0].Message, plumbing.replayed)
+		assert.Equal(t, baseKey, plumbing.base)
+	})
+
+	t.Run("replays a message found in the pool", func(t *testing.T) {
+		plumbing := newPlumbing()
+		_, err := porcelain.MessageReplayOne(ctx, plumbing, inPoolCid, baseKey)
+		require.NoError(t, err)
+		assert.Equal(t, &msgs[1].Message, plumbing.replayed)
+	})
+
+	t.Run("fails for an unknown message", func(t *testing.T) {
+		plumbing := newPlumbing()
+		unknown := types.CidFromString(t, "unknown")
+		_, err := porcelain.MessageReplayOne(ctx, plumbing, unknown, baseKey)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found on chain or in the message pool")
+		assert.Nil(t, plumbing.replayed)
+	})
+}
+
+type fakeEstimateGasPlumbing struct {
+	nonce       uint64
+	gasUsed     uint64
+	exitCode    uint8
+	pending     []*types.SignedMessage
+	minGasPrice types.AttoFIL
+	replayed    *types.UnsignedMessage
+}
+
+func (p *fakeEstimateGasPlumbing) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
+	return &actor.Actor{Nonce: types.Uint64(p.nonce), Balance: types.NewAttoFILFromFIL(1)}, nil
+}
+
+func (p *fakeEstimateGasPlumbing) ChainHeadKey() block.TipSetKey {
+	return block.NewTipSetKey()
+}
+
+func (p *fakeEstimateGasPlumbing) ConfigGet(dottedPath string) (interface{}, error) {
+	return p.minGasPrice, nil
+}
+
+func (p *fakeEstimateGasPlumbing) MessagePoolPending() []*types.SignedMessage {
+	return p.pending
+}
+
+func (p *fakeEstimateGasPlumbing) MessageReplay(ctx context.Context, message *types.UnsignedMessage, baseKey block.TipSetKey) (*msg.ReplayResult, error) {
+	p.replayed = message
+	gas := types.NewGasPrice(int64(p.gasUsed))
+	return &msg.ReplayResult{Receipt: &types.MessageReceipt{ExitCode: p.exitCode, GasAttoFIL: gas}, Error: "reverted"}, nil
+}
+
+func TestMessageEstimateGas(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	signer := types.NewMockSigner(types.MustGenerateKeyInfo(2, 42))
+	from, to := signer.Addresses[0], signer.Addresses[1]
+
+	pricedMsgs := func(prices ...int64) []*types.SignedMessage {
+		var out []*types.SignedMessage
+		for _, price := range prices {
+			unsigned := types.NewMeteredMessage(from, to, 0, types.ZeroAttoFIL, types.SendMethodID, nil, types.NewGasPrice(price), types.NewGasUnits(0))
+			smsg, err := types.NewSignedMessage(*unsigned, signer)
+			require.NoError(t, err)
+			out = append(out, smsg)
+		}
+		return out
+	}
+
+	t.Run("dry-runs the message and adds a margin to the gas used", func(t *testing.T) {
+		plumbing := &fakeEstimateGasPlumbing{nonce: 3, gasUsed: 1000, minGasPrice: types.ZeroAttoFIL}
+		estimate, err := porcelain.MessageEstimateGas(ctx, plumbing, from, to, types.NewAttoFILFromFIL(2), types.SendMethodID)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasUnits(1100), estimate.GasLimit)
+		assert.Equal(t, types.NewGasPrice(1), estimate.GasPrice)
+
+		assert.Equal(t, types.Uint64(3), plumbing.replayed.Nonce)
+		assert.Equal(t, types.NewAttoFILFromFIL(2), plumbing.replayed.Value)
+	})
+
+	t.Run("suggests the median pending gas price", func(t *testing.T) {
+		plumbing := &fakeEstimateGasPlumbing{gasUsed: 10, pending: pricedMsgs(5, 1, 9), minGasPrice: types.ZeroAttoFIL}
+		estimate, err := porcelain.MessageEstimateGas(ctx, plumbing, from, to, types.ZeroAttoFIL, types.SendMethodID)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(5), estimate.GasPrice)
+	})
+
+	t.Run("suggests no less than the minimum gas price", func(t *testing.T) {
+		plumbing := &fakeEstimateGasPlumbing{gasUsed: 10, pending: pricedMsgs(5), minGasPrice: types.NewGasPrice(7)}
+		estimate, err := porcelain.MessageEstimateGas(ctx, plumbing, from, to, types.ZeroAttoFIL, types.SendMethodID)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewGasPrice(7), estimate.GasPrice)
+	})
+
+	t.Run("fails for messages that would fail", func(t *testing.T) {
+		plumbing := &fakeEstimateGasPlumbing{exitCode: 1, minGasPrice: types.ZeroAttoFIL}
+		_, err := porcelain.MessageEstimateGas(ctx, plumbing, from, to, types.ZeroAttoFIL, types.SendMethodID)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "reverted")
+	})
+}
+
+type fakeMessageNextNoncePlumbing struct {
+	actor   *actor.Actor
+	pending []*types.SignedMessage
+}
+
+func (p *fakeMessageNextNoncePlumbing) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
+	return p.actor, nil
+}
+
+func (p *fakeMessageNextNoncePlumbing) MessagePoolPending() []*types.SignedMessage {
+	return p.pending
+}
+
+func TestMessageNextNonce(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	signer := types.NewMockSigner(types.MustGenerateKeyInfo(1, 42))
+	addr := signer.Addresses[0]
+	// Nonces 0 to 4.
+	msgs := types.NewSignedMsgs(5, signer)
+	other := types.NewSignedMsgs(3, types.NewMockSigner(types.MustGenerateKeyInfo(1, 43)))
+	onChain := func(nonce uint64) *actor.Actor {
+		return &actor.Actor{Code: types.AccountActorCodeCid, Nonce: types.Uint64(nonce)}
+	}
+
+	t.Run("on-chain nonce without pending messages", func(t *testing.T) {
+		nonce, err := porcelain.MessageNextNonce(ctx, &fakeMessageNextNoncePlumbing{actor: onChain(3), pending: other}, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(3), nonce)
+	})
+
+	t.Run("zero for an address without an actor", func(t *testing.T) {
+		nonce, err := porcelain.MessageNextNonce(ctx, &fakeMessageNextNoncePlumbing{}, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(0), nonce)
+	})
+
+	t.Run("after the pending messages", func(t *testing.T) {
+		plumbing := &fakeMessageNextNoncePlumbing{actor: onChain(2), pending: msgs[1:4]}
+		nonce, err := porcelain.MessageNextNonce(ctx, plumbing, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(4), nonce)
+	})
+
+	t.Run("fills a gap in the pending messages", func(t *testing.T) {
+		plumbing := &fakeMessageNextNoncePlumbing{actor: onChain(1), pending: []*types.SignedMessage{msgs[1], msgs[3], msgs[4]}}
+		nonce, err := porcelain.MessageNextNonce(ctx, plumbing, addr)
+		require.NoError(t, err)
+		assert.Equal(t, uint64(2), nonce)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
@@ -13,6 +14,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
 	minerActor "github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
@@ -20,6 +22,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	vmErrors "github.com/filecoin-project/go-filecoin/internal/pkg/vm/errors"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
 )
 
 // mcAPI is the subset of the plumbing.API that MinerCreate uses.
@@ -449,6 +452,96 @@ func MinerGetProvingWindow(ctx context.Context, plumbing minerQueryAndDeserializ
 	}, nil
 }
 
+// MinerPendingSector is a committed sector that no PoSt has been accepted
+// for yet.
+type MinerPendingSector struct {
+	SectorID uint64
+	Deadline types.BlockHeight
+}
+
+// MinerGetPendingSectors lists the sectors miner `minerAddr` has committed but
+// not yet proven, with the height by which each must be proven.
+func MinerGetPendingSectors(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address) ([]MinerPendingSector, error) {
+	res, err := plumbing.MessageQuery(
+		ctx,
+		address.Undef,
+		minerAddr,
+		minerActor.GetPendingSectors,
+		plumbing.ChainHeadKey(),
+	)
+	if err != nil {
+		return nil, errors.Wrap(err, "query GetPendingSectors method failed")
+	}
+
+	pendingVal, err := abi.Deserialize(res[0], abi.UintArray)
+	if err != nil {
+		return nil, errors.Wrap(err, "deserialization failed")
+	}
+	pairs, ok := pendingVal.Val.([]types.Uint64)
+	if !ok || len(pairs)%2 != 0 {
+		return nil, errors.New("type assertion failed")
+	}
+
+	pending := make([]MinerPendingSector, 0, len(pairs)/2)
+	for i := 0; i < len(pairs); i += 2 {
+		pending = append(pending, MinerPendingSector{
+			SectorID: uint64(pairs[i]),
+			Deadline: *types.NewBlockHeight(uint64(pairs[i+1])),
+		})
+	}
+	return pending, nil
+}
+
+// MinerFaults describes the slashing of a miner for missing its PoSt.
+type MinerFaults struct {
+	// Faults is the number of sectors the miner was slashed for.
+	Faults uint64
+	// SlashedAt is the height the miner was slashed at, zero if it never was.
+	SlashedAt types.BlockHeight
+	// SlashedCollateral is the collateral burnt by the slashing.
+	SlashedCollateral types.AttoFIL
+	// Power is the storage power the miner has left.
+	Power types.BytesAmount
+}
+
+// MinerGetFaults queries whether miner `minerAddr` has been slashed for
+// missing its PoSt and what it lost.
+func MinerGetFaults(ctx context.Context, plumbing minerQueryAndDeserialize, minerAddr address.Address) (MinerFaults, error) {
+	faultsVal, err := queryAndDeserialize(ctx, plumbing, minerAddr, minerActor.GetFaults, plumbing.ChainHeadKey())
+	if err != nil {
+		return MinerFaults{}, errors.Wrap(err, "query GetFaults method failed")
+	}
+	faults, ok := faultsVal.Val.([]types.Uint64)
+	if !ok || len(faults) != 2 {
+		return MinerFaults{}, errors.New("type assertion failed")
+	}
+
+	slashedVal, err := queryAndDeserialize(ctx, plumbing, minerAddr, minerActor.GetSlashedCollateral, plumbing.ChainHeadKey())
+	if err != nil {
+		return MinerFaults{}, errors.Wrap(err, "query GetSlashedCollateral method failed")
+	}
+	slashed, ok := slashedVal.Val.(types.AttoFIL)
+	if !ok {
+		return MinerFaults{}, errors.New("type assertion failed")
+	}
+
+	powerVal, err := queryAndDeserialize(ctx, plumbing, minerAddr, minerActor.GetPower, plumbing.ChainHeadKey())
+	if err != nil {
+		return MinerFaults{}, errors.Wrap(err, "query GetPower method failed")
+	}
+	power, ok := powerVal.Val.(*types.BytesAmount)
+	if !ok {
+		return MinerFaults{}, errors.New("type assertion failed")
+	}
+
+	return MinerFaults{
+		Faults:            uint64(faults[0]),
+		SlashedAt:         *types.NewBlockHeight(uint64(faults[1])),
+		SlashedCollateral: slashed,
+		Power:             *power,
+	}, nil
+}
+
 // MinerPower contains a miners power and the total power of the network
 type MinerPower struct {
 	Power types.BytesAmount
@@ -518,23 +611,28 @@ type mwapi interface {
 	MinerGetOwnerAddress(ctx context.Context, minerAddr address.Address) (address.Address, error)
 }
 
-// MinerSetWorkerAddress sets the worker address of the miner actor to the provided new address,
-// waits for the message to appear on chain and then sets miner.workerAddr config to the new address.
+// MinerSetWorkerAddress sets the worker address of the miner actor to the provided new address
+// with a message signed by the miner's owner. If minerAddr is empty the miner configured at
+// mining.minerAddress is used.
 func MinerSetWorkerAddress(
 	ctx context.Context,
 	plumbing mwapi,
+	minerAddr address.Address,
 	workerAddr address.Address,
 	gasPrice types.AttoFIL,
 	gasLimit types.GasUnits,
 ) (cid.Cid, error) {
 
-	retVal, err := plumbing.ConfigGet("mining.minerAddress")
-	if err != nil {
-		return cid.Undef, err
-	}
-	minerAddr, ok := retVal.(address.Address)
-	if !ok {
-		return cid.Undef, errors.New("problem converting miner address")
+	if minerAddr.Empty() {
+		retVal, err := plumbing.ConfigGet("mining.minerAddress")
+		if err != nil {
+			return cid.Undef, err
+		}
+		var ok bool
+		minerAddr, ok = retVal.(address.Address)
+		if !ok {
+			return cid.Undef, errors.New("problem converting miner address")
+		}
 	}
 
 	minerOwnerAddr, err := plumbing.MinerGetOwnerAddress(ctx, minerAddr)
@@ -553,3 +651,148 @@ func MinerSetWorkerAddress(
 		workerAddr)
 	return c, err
 }
+
+// mckAPI is the subset of the plumbing.API that MinerCheckSigningKeys uses.
+type mckAPI interface {
+	ConfigGet(dottedPath string) (interface{}, error)
+	ChainHeadKey() block.TipSetKey
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, baseKey block.TipSetKey, params ...interface{}) ([][]byte, error)
+	ActorGetStableSignature(ctx context.Context, actorAddr address.Address, method types.MethodID) (*vm.FunctionSignature, error)
+	WalletFind(addr address.Address) (wallet.Backend, error)
+}
+
+// MinerCheckSigningKeys verifies that the wallet holds usable keys for the
+// owner and worker of the miner configured at mining.minerAddress. Mining
+// with a miner whose keys the wallet lacks fails when the first block is
+// signed, so this surfaces the problem before mining starts.
+func MinerCheckSigningKeys(ctx context.Context, plumbing mckAPI) error {
+	retVal, err := plumbing.ConfigGet("mining.minerAddress")
+	if err != nil {
+		return err
+	}
+	minerAddr, ok := retVal.(address.Address)
+	if !ok {
+		return errors.New("problem converting miner address")
+	}
+	if minerAddr.Empty() {
+		return errors.New("no miner configured: mining.minerAddress is not set")
+	}
+
+	ownerAddr, err := MinerGetOwnerAddress(ctx, plumbing, minerAddr)
+	if err != nil {
+		return errors.Wrapf(err, "could not get owner address of miner %s", minerAddr)
+	}
+	if err := checkSigningKey(plumbing, minerAddr, "owner", ownerAddr); err != nil {
+		return err
+	}
+
+	workerAddr, err := MinerGetWorkerAddress(ctx, plumbing, minerAddr, plumbing.ChainHeadKey())
+	if err != nil {
+		return errors.Wrapf(err, "could not get worker address of miner %s", minerAddr)
+	}
+	if workerAddr == ownerAddr {
+		return nil
+	}
+	return checkSigningKey(plumbing, minerAddr, "worker", workerAddr)
+}
+
+// checkSigningKey returns an error if the wallet cannot produce a block
+// signature that validates against addr.
+func checkSigningKey(plumbing mckAPI, minerAddr address.Address, role string, addr address.Address) error {
+	var cryptSystem string
+	switch addr.Protocol() {
+	case address.SECP256K1:
+		cryptSystem = types.SECP256K1
+	case address.BLS:
+		cryptSystem = types.BLS
+	default:
+		return fmt.Errorf("%s %s of miner %s cannot sign blocks: only secp256k1 and bls addresses can", role, addr, minerAddr)
+	}
+
+	backend, err := plumbing.WalletFind(addr)
+	if err != nil {
+		return fmt.Errorf("wallet does not hold the key for %s %s of miner %s", role, addr, minerAddr)
+	}
+
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return errors.Wrapf(err, "could not get key for %s %s of miner %s", role, addr, minerAddr)
+	}
+	if ki.CryptSystem != cryptSystem {
+		return fmt.Errorf("key for %s %s of miner %s is a %s key, expected %s", role, addr, minerAddr, ki.CryptSystem, cryptSystem)
+	}
+
+	return nil
+}
+
+// MinerDeal is a storage obligation of a miner, as recorded in its deal store.
+type MinerDeal struct {
+	ProposalCid cid.Cid
+	PieceCid    cid.Cid
+	Client      address.Address
+	TotalPrice  types.AttoFIL
+	Size        *types.BytesAmount
+	StartHeight uint64
+	EndHeight   uint64
+	// SectorID is the sector holding the piece, nil until it is sealed.
+	SectorID *uint64
+	State    storagedeal.State
+}
+
+// MinerListDeals lists the deals miner `minerAddr` has accepted and not
+// failed, as stored in this node's deal store, ordered by start height.
+func MinerListDeals(ctx context.Context, plumbing dealGetPlumbing, minerAddr address.Address) ([]MinerDeal, error) {
+	dealCh, err := plumbing.DealsLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	deals := []MinerDeal{}
+	index := make(map[cid.Cid]int)
+	for result := range dealCh {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		deal := result.Deal
+		if deal.Miner != minerAddr || deal.Proposal == nil || deal.Response == nil {
+			continue
+		}
+		if deal.Response.State == storagedeal.Rejected || deal.Response.State == storagedeal.Failed {
+			continue
+		}
+
+		md := MinerDeal{
+			ProposalCid: deal.Response.ProposalCid,
+			PieceCid:    deal.Proposal.PieceRef,
+			Client:      deal.Proposal.Payment.Payer,
+			TotalPrice:  deal.Proposal.TotalPrice,
+			Size:        deal.Proposal.Size,
+			StartHeight: deal.StartHeight,
+			EndHeight:   deal.StartHeight + deal.Proposal.Duration,
+			State:       deal.Response.State,
+		}
+		if deal.Response.ProofInfo != nil {
+			sectorID := deal.Response.ProofInfo.SectorID
+			md.SectorID = &sectorID
+		}
+
+		// A node that is both client and miner of a deal stores it twice;
+		// keep the copy that knows the sector.
+		if i, ok := index[md.ProposalCid]; ok {
+			if deals[i].SectorID == nil {
+				deals[i] = md
+			}
+			continue
+		}
+		index[md.ProposalCid] = len(deals)
+		deals = append(deals, md)
+	}
+
+	sort.Slice(deals, func(i, j int) bool {
+		if deals[i].StartHeight != deals[j].StartHeight {
+			return deals[i].StartHeight < deals[j].StartHeight
+		}
+		return deals[i].ProposalCid.String() < deals[j].ProposalCid.String()
+	})
+	return deals, nil
+}
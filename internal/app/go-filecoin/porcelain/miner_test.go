@@ -16,6 +16,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/cfg"
 	. "github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -493,6 +494,13 @@ func (mpp *minerGetProvingPeriodPlumbing) MessageQuery(ctx context.Context, optF
 		}
 		return [][]byte{ret}, nil
 	}
+	if method == miner.GetPendingSectors {
+		ret, err := (&abi.Value{Type: abi.UintArray, Val: []types.Uint64{3, 20, 4, 30}}).Serialize()
+		if err != nil {
+			return nil, err
+		}
+		return [][]byte{ret}, nil
+	}
 	if method == miner.GetProvingSetCommitments {
 		commitments := make(map[string]types.Commitments)
 		commitments["foo"] = types.Commitments{
@@ -530,6 +538,62 @@ func TestMinerProvingPeriod(t *testing.T) {
 	assert.NotNil(t, pp.ProvingSet["foo"])
 }
 
+func TestMinerGetPendingSectors(t *testing.T) {
+	tf.UnitTest(t)
+
+	pending, err := MinerGetPendingSectors(context.Background(), &minerGetProvingPeriodPlumbing{}, address.TestAddress2)
+	require.NoError(t, err)
+	require.Len(t, pending, 2)
+	assert.Equal(t, uint64(3), pending[0].SectorID)
+	assert.Equal(t, "20", pending[0].Deadline.String())
+	assert.Equal(t, uint64(4), pending[1].SectorID)
+	assert.Equal(t, "30", pending[1].Deadline.String())
+}
+
+type minerGetFaultsPlumbing struct{}
+
+func (mgfp *minerGetFaultsPlumbing) ChainHeadKey() block.TipSetKey {
+	return block.NewTipSetKey()
+}
+
+func (mgfp *minerGetFaultsPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, _ block.TipSetKey, params ...interface{}) ([][]byte, error) {
+	var val *abi.Value
+	switch method {
+	case miner.GetFaults:
+		val = &abi.Value{Type: abi.UintArray, Val: []types.Uint64{2, 700}}
+	case miner.GetSlashedCollateral:
+		val = &abi.Value{Type: abi.AttoFIL, Val: types.NewAttoFILFromFIL(3)}
+	case miner.GetPower:
+		val = &abi.Value{Type: abi.BytesAmount, Val: types.NewBytesAmount(0)}
+	default:
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+	ret, err := val.Serialize()
+	if err != nil {
+		return nil, err
+	}
+	return [][]byte{ret}, nil
+}
+
+func (mgfp *minerGetFaultsPlumbing) ActorGetStableSignature(ctx context.Context, actorAddr address.Address, method types.MethodID) (*vm.FunctionSignature, error) {
+	_, signature, ok := (&miner.Actor{}).Method(method)
+	if !ok {
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+	return signature, nil
+}
+
+func TestMinerGetFaults(t *testing.T) {
+	tf.UnitTest(t)
+
+	faults, err := MinerGetFaults(context.Background(), &minerGetFaultsPlumbing{}, address.TestAddress2)
+	require.NoError(t, err)
+	assert.Equal(t, uint64(2), faults.Faults)
+	assert.Equal(t, "700", faults.SlashedAt.String())
+	assert.Equal(t, types.NewAttoFILFromFIL(3), faults.SlashedCollateral)
+	assert.True(t, faults.Power.IsZero())
+}
+
 type minerGetPeerIDPlumbing struct{}
 
 func (mgop *minerGetPeerIDPlumbing) ChainHeadKey() block.TipSetKey {
@@ -642,7 +706,7 @@ func TestMinerGetLastCommittedSectorID(t *testing.T) {
 
 type minerSetWorkerAddressPlumbing struct {
 	getOwnerFail, getWorkerFail, msgFail, msgWaitFail, cfgFail bool
-	minerAddr, ownerAddr, workerAddr                           address.Address
+	minerAddr, ownerAddr, workerAddr, sentTo                   address.Address
 }
 
 func (mswap *minerSetWorkerAddressPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error) {
@@ -650,6 +714,7 @@ func (mswap *minerSetWorkerAddressPlumbing) MessageSend(ctx context.Context, fro
 	if mswap.msgFail {
 		return cid.Cid{}, nil, errors.New("MsgFail")
 	}
+	mswap.sentTo = to
 	return types.EmptyMessagesCID, nil, nil
 }
 
@@ -693,9 +758,22 @@ func TestMinerSetWorkerAddress(t *testing.T) {
 			minerAddr:  minerAddr,
 		}
 
-		_, err := MinerSetWorkerAddress(context.Background(), plumbing, workerAddr, gprice, glimit)
+		_, err := MinerSetWorkerAddress(context.Background(), plumbing, address.Undef, workerAddr, gprice, glimit)
 		assert.NoError(t, err)
 		assert.Equal(t, workerAddr.String(), plumbing.workerAddr.String())
+		assert.Equal(t, minerAddr, plumbing.sentTo)
+	})
+
+	t.Run("An explicit miner address is used instead of the configured miner", func(t *testing.T) {
+		otherMiner := address.TestAddress2
+		plumbing := &minerSetWorkerAddressPlumbing{
+			cfgFail:   true,
+			ownerAddr: minerOwner,
+		}
+
+		_, err := MinerSetWorkerAddress(context.Background(), plumbing, otherMiner, workerAddr, gprice, glimit)
+		require.NoError(t, err)
+		assert.Equal(t, otherMiner, plumbing.sentTo)
 	})
 
 	testCases := []struct {
@@ -722,9 +800,170 @@ func TestMinerSetWorkerAddress(t *testing.T) {
 
 	for _, test := range testCases {
 		t.Run(test.name, func(t *testing.T) {
-			_, err := MinerSetWorkerAddress(context.Background(), test.plumbing, workerAddr, gprice, glimit)
+			_, err := MinerSetWorkerAddress(context.Background(), test.plumbing, address.Undef, workerAddr, gprice, glimit)
 			assert.Error(t, err, test.error)
 			assert.Empty(t, test.plumbing.workerAddr)
 		})
 	}
 }
+
+type minerCheckSigningKeysPlumbing struct {
+	config *cfg.Config
+	wallet *wallet.Wallet
+	owner  address.Address
+	worker address.Address
+}
+
+func newMinerCheckSigningKeysPlumbing(t *testing.T) *minerCheckSigningKeysPlumbing {
+	testRepo := repo.NewInMemoryRepo()
+	backend, err := wallet.NewDSBackend(testRepo.WalletDatastore())
+	require.NoError(t, err)
+
+	plumbing := &minerCheckSigningKeysPlumbing{
+		config: cfg.NewConfig(testRepo),
+		wallet: wallet.New(backend),
+	}
+	plumbing.owner, err = wallet.NewAddress(plumbing.wallet, address.SECP256K1)
+	require.NoError(t, err)
+	plumbing.worker = plumbing.owner
+	require.NoError(t, plumbing.config.Set("mining.minerAddress", address.TestAddress2.String()))
+	return plumbing
+}
+
+func (p *minerCheckSigningKeysPlumbing) ConfigGet(dottedPath string) (interface{}, error) {
+	return p.config.Get(dottedPath)
+}
+
+func (p *minerCheckSigningKeysPlumbing) ChainHeadKey() block.TipSetKey {
+	return block.NewTipSetKey()
+}
+
+func (p *minerCheckSigningKeysPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, _ block.TipSetKey, params ...interface{}) ([][]byte, error) {
+	switch method {
+	case miner.GetOwner:
+		return [][]byte{p.owner.Bytes()}, nil
+	case miner.GetWorker:
+		return [][]byte{p.worker.Bytes()}, nil
+	default:
+		return nil, fmt.Errorf("unsupported method: %s", method)
+	}
+}
+
+func (p *minerCheckSigningKeysPlumbing) ActorGetStableSignature(ctx context.Context, actorAddr address.Address, method types.MethodID) (*vm.FunctionSignature, error) {
+	return nil, fmt.Errorf("unsupported method: %s", method)
+}
+
+func (p *minerCheckSigningKeysPlumbing) WalletFind(addr address.Address) (wallet.Backend, error) {
+	return p.wallet.Find(addr)
+}
+
+func TestMinerCheckSigningKeys(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	t.Run("passes when the wallet holds the owner key", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		assert.NoError(t, MinerCheckSigningKeys(ctx, plumbing))
+	})
+
+	t.Run("passes with a bls worker key", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		var err error
+		plumbing.worker, err = wallet.NewAddress(plumbing.wallet, address.BLS)
+		require.NoError(t, err)
+		assert.NoError(t, MinerCheckSigningKeys(ctx, plumbing))
+	})
+
+	t.Run("fails when no miner is configured", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		plumbing.config = cfg.NewConfig(repo.NewInMemoryRepo())
+		err := MinerCheckSigningKeys(ctx, plumbing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no miner configured")
+	})
+
+	t.Run("fails when the wallet lacks the owner key", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		plumbing.owner = address.TestAddress
+		err := MinerCheckSigningKeys(ctx, plumbing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("wallet does not hold the key for owner %s", address.TestAddress))
+	})
+
+	t.Run("fails when the wallet lacks the worker key", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		plumbing.worker = address.TestAddress
+		err := MinerCheckSigningKeys(ctx, plumbing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), fmt.Sprintf("wallet does not hold the key for worker %s", address.TestAddress))
+	})
+
+	t.Run("fails when the owner address cannot sign", func(t *testing.T) {
+		plumbing := newMinerCheckSigningKeysPlumbing(t)
+		var err error
+		plumbing.owner, err = address.NewIDAddress(100)
+		require.NoError(t, err)
+		err = MinerCheckSigningKeys(ctx, plumbing)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "cannot sign blocks")
+	})
+}
+
+func TestMinerListDeals(t *testing.T) {
+	tf.UnitTest(t)
+
+	addrGetter := address.NewForTestGetter()
+	minerAddr, otherMiner, client := addrGetter(), addrGetter(), addrGetter()
+	cidGetter := types.NewCidForTestGetter()
+
+	deal := func(minerAddr address.Address, proposalCid cid.Cid, start uint64, state storagedeal.State, proof *storagedeal.ProofInfo) *storagedeal.Deal {
+		return &storagedeal.Deal{
+			Miner: minerAddr,
+			Proposal: &storagedeal.SignedProposal{Proposal: storagedeal.Proposal{
+				PieceRef:   proposalCid,
+				Size:       types.NewBytesAmount(42),
+				TotalPrice: types.NewAttoFILFromFIL(7),
+				Duration:   20,
+				Payment:    storagedeal.PaymentInfo{Payer: client},
+			}},
+			Response: &storagedeal.SignedResponse{Response: storagedeal.Response{
+				State:       state,
+				ProposalCid: proposalCid,
+				ProofInfo:   proof,
+			}},
+			StartHeight: start,
+		}
+	}
+	sealed, staged, rejected, others := cidGetter(), cidGetter(), cidGetter(), cidGetter()
+	plumbing := &testDealLsPlumbing{
+		deals: []*storagedeal.Deal{
+			deal(minerAddr, staged, 9, storagedeal.Staged, nil),
+			// The client's copy of a deal with this node's own miner.
+			deal(minerAddr, sealed, 5, storagedeal.Accepted, nil),
+			deal(minerAddr, sealed, 5, storagedeal.Complete, &storagedeal.ProofInfo{SectorID: 3}),
+			deal(minerAddr, rejected, 1, storagedeal.Rejected, nil),
+			deal(otherMiner, others, 2, storagedeal.Complete, nil),
+		},
+	}
+
+	deals, err := MinerListDeals(context.Background(), plumbing, minerAddr)
+	require.NoError(t, err)
+	require.Len(t, deals, 2)
+
+	sectorID := uint64(3)
+	assert.Equal(t, MinerDeal{
+		ProposalCid: sealed,
+		PieceCid:    sealed,
+		Client:      client,
+		TotalPrice:  types.NewAttoFILFromFIL(7),
+		Size:        types.NewBytesAmount(42),
+		StartHeight: 5,
+		EndHeight:   25,
+		SectorID:    &sectorID,
+		State:       storagedeal.Complete,
+	}, deals[0])
+	assert.Equal(t, staged, deals[1].ProposalCid)
+	assert.Nil(t, deals[1].SectorID)
+	assert.Equal(t, uint64(29), deals[1].EndHeight)
+}
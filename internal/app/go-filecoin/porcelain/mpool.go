@@ -1,9 +1,22 @@
 package porcelain
 
 import (
+	"bufio"
+	"bytes"
 	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
 	"time"
 
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/msg"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 )
 
@@ -12,14 +25,152 @@ type mpwPlumbing interface {
 	MessagePoolPending() []*types.SignedMessage
 }
 
-// MessagePoolWait waits until the message pool contains at least messageCount unmined messages.
+// MessagePoolWait waits until the message pool contains at least messageCount unmined messages
+// and returns them ordered by sender and nonce. It fails when ctx is done first.
 func MessagePoolWait(ctx context.Context, plumbing mpwPlumbing, messageCount uint) ([]*types.SignedMessage, error) {
 	pending := plumbing.MessagePoolPending()
 	for len(pending) < int(messageCount) {
-		// Poll pending again after subscribing in case a message arrived since.
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "%d of %d messages in pool", len(pending), messageCount)
+		case <-time.After(200 * time.Millisecond):
+		}
 		pending = plumbing.MessagePoolPending()
-		time.Sleep(200 * time.Millisecond)
 	}
 
-	return pending, nil
+	return sortPending(pending), nil
+}
+
+// sortPending returns a copy of pending ordered by sender and, within each
+// sender, by nonce, the order in which the messages can be mined.
+func sortPending(pending []*types.SignedMessage) []*types.SignedMessage {
+	sorted := append([]*types.SignedMessage{}, pending...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		fi, fj := sorted[i].Message.From.String(), sorted[j].Message.From.String()
+		if fi != fj {
+			return fi < fj
+		}
+		return sorted[i].Message.CallSeqNum < sorted[j].Message.CallSeqNum
+	})
+	return sorted
+}
+
+// The subset of plumbing used by MessagePoolEvict
+type mpePlumbing interface {
+	MessagePoolGet(cid cid.Cid) (*types.SignedMessage, bool)
+	MessagePoolPending() []*types.SignedMessage
+	MessagePoolRemove(cid cid.Cid)
+}
+
+// MessagePoolEvict removes the pending message with cid msgCid from the pool,
+// together with the pending messages of the same sender with higher nonces,
+// which cannot be mined without it. It returns the cids of the removed
+// messages in nonce order and fails if msgCid is not pending.
+func MessagePoolEvict(plumbing mpePlumbing, msgCid cid.Cid) ([]cid.Cid, error) {
+	evicted, ok := plumbing.MessagePoolGet(msgCid)
+	if !ok {
+		return nil, fmt.Errorf("message %s not found in pool (already mined?)", msgCid)
+	}
+
+	var removed []cid.Cid
+	for _, smsg := range sortPending(plumbing.MessagePoolPending()) {
+		if smsg.Message.From != evicted.Message.From || smsg.Message.CallSeqNum < evicted.Message.CallSeqNum {
+			continue
+		}
+		c, err := smsg.Cid()
+		if err != nil {
+			return removed, err
+		}
+		plumbing.MessagePoolRemove(c)
+		removed = append(removed, c)
+	}
+	return removed, nil
+}
+
+// MessagePoolImportResult counts the messages of an import that were added to
+// the pool and the ones that were not.
+type MessagePoolImportResult struct {
+	Accepted int
+	Rejected int
+	// Errors holds the reason each rejected message was rejected, prefixed
+	// with its line number in the input.
+	Errors []string
+}
+
+// The subset of plumbing used by MessagePoolImport
+type mpiPlumbing interface {
+	MessagePoolAdd(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error)
+}
+
+// MessagePoolImport reads JSON encoded signed messages from r, one per line,
+// and adds each to the message pool. A message that can't be decoded or fails
+// validation, including its signature check, is rejected without stopping the
+// import. Blank lines are skipped.
+func MessagePoolImport(ctx context.Context, plumbing mpiPlumbing, r io.Reader) (*MessagePoolImportResult, error) {
+	result := &MessagePoolImportResult{Errors: []string{}}
+	reject := func(line int, err error) {
+		result.Rejected++
+		result.Errors = append(result.Errors, fmt.Sprintf("line %d: %s", line, err))
+	}
+
+	scanner := bufio.NewScanner(r)
+	// Messages with large params don't fit in the default 64KiB buffer.
+	scanner.Buffer(make([]byte, 0, 64*1024), 10*1024*1024)
+	line := 0
+	for scanner.Scan() {
+		line++
+		raw := bytes.TrimSpace(scanner.Bytes())
+		if len(raw) == 0 {
+			continue
+		}
+
+		var smsg types.SignedMessage
+		if err := json.Unmarshal(raw, &smsg); err != nil {
+			reject(line, err)
+			continue
+		}
+		if _, err := plumbing.MessagePoolAdd(ctx, &smsg); err != nil {
+			reject(line, err)
+			continue
+		}
+		result.Accepted++
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// The subset of plumbing used by MessagePoolConflicts
+type mpcPlumbing interface {
+	ChainLs(ctx context.Context) (*chain.TipsetIterator, error)
+	MessagePoolPending() []*types.SignedMessage
+	MessagesInTipSet(ctx context.Context, ts block.TipSet) ([]*msg.ChainMessage, error)
+}
+
+// MessagePoolConflicts reports every sender nonce that a pending message
+// shares with a different pending message, or with a different message mined
+// in the `depth` most recent tipsets of the chain. Two such messages can not
+// both be executed, which points at a wallet reusing nonces.
+func MessagePoolConflicts(ctx context.Context, plumbing mpcPlumbing, depth uint64) ([]message.NonceConflict, error) {
+	var mined []*types.SignedMessage
+	iter, err := plumbing.ChainLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	for i := uint64(0); i < depth && !iter.Complete(); i++ {
+		chainMsgs, err := plumbing.MessagesInTipSet(ctx, iter.Value())
+		if err != nil {
+			return nil, err
+		}
+		for _, chainMsg := range chainMsgs {
+			mined = append(mined, chainMsg.Message)
+		}
+		if err := iter.Next(); err != nil {
+			return nil, err
+		}
+	}
+
+	return message.FindNonceConflicts(plumbing.MessagePoolPending(), mined)
 }
@@ -1,10 +1,15 @@
 package porcelain_test
 
 import (
+	"bytes"
 	"context"
+	"encoding/json"
 	"sync"
 	"testing"
+	"time"
 
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -77,6 +82,41 @@ func TestMessagePoolWait(t *testing.T) {
 
 		finished.Wait()
 	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		plumbing := newFakeMpoolWaitPlumbing(nil)
+		plumbing.pending = types.NewSignedMsgs(1, signer)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := porcelain.MessagePoolWait(ctx, plumbing, 2)
+		require.Error(t, err)
+		assert.Equal(t, context.DeadlineExceeded, errors.Cause(err))
+		assert.Contains(t, err.Error(), "1 of 2 messages in pool")
+	})
+}
+
+func TestMessagePoolWaitOrdersBySenderAndNonce(t *testing.T) {
+	tf.UnitTest(t)
+
+	a := types.NewSignedMsgs(3, types.NewMockSigner(types.MustGenerateKeyInfo(1, 42)))
+	b := types.NewSignedMsgs(2, types.NewMockSigner(types.MustGenerateKeyInfo(1, 43)))
+
+	plumbing := newFakeMpoolWaitPlumbing(nil)
+	plumbing.pending = []*types.SignedMessage{a[2], b[1], a[0], b[0], a[1]}
+
+	msgs, err := porcelain.MessagePoolWait(context.Background(), plumbing, 0)
+	require.NoError(t, err)
+	require.Len(t, msgs, 5)
+
+	first, second := a, b
+	if b[0].Message.From.String() < a[0].Message.From.String() {
+		first, second = b, a
+	}
+	assert.Equal(t, append(append([]*types.SignedMessage{}, first...), second...), msgs)
+
+	// The plumbing's slice is left alone.
+	assert.Equal(t, a[2], plumbing.pending[0])
 }
 
 // assertMessagePoolWaitAsync waits for msgCount messages asynchronously
@@ -93,3 +133,123 @@ func assertMessagePoolWaitAsync(plumbing *fakeMpoolWaitPlumbing, msgCount uint,
 
 	return &finished
 }
+
+type fakeMpoolEvictPlumbing struct {
+	pending map[cid.Cid]*types.SignedMessage
+}
+
+func newFakeMpoolEvictPlumbing(t *testing.T, msgs ...*types.SignedMessage) *fakeMpoolEvictPlumbing {
+	plumbing := &fakeMpoolEvictPlumbing{pending: make(map[cid.Cid]*types.SignedMessage)}
+	for _, smsg := range msgs {
+		c, err := smsg.Cid()
+		require.NoError(t, err)
+		plumbing.pending[c] = smsg
+	}
+	return plumbing
+}
+
+func (plumbing *fakeMpoolEvictPlumbing) MessagePoolGet(c cid.Cid) (*types.SignedMessage, bool) {
+	smsg, ok := plumbing.pending[c]
+	return smsg, ok
+}
+
+func (plumbing *fakeMpoolEvictPlumbing) MessagePoolPending() []*types.SignedMessage {
+	var pending []*types.SignedMessage
+	for _, smsg := range plumbing.pending {
+		pending = append(pending, smsg)
+	}
+	return pending
+}
+
+func (plumbing *fakeMpoolEvictPlumbing) MessagePoolRemove(c cid.Cid) {
+	delete(plumbing.pending, c)
+}
+
+func TestMessagePoolEvict(t *testing.T) {
+	tf.UnitTest(t)
+
+	a := types.NewSignedMsgs(3, types.NewMockSigner(types.MustGenerateKeyInfo(1, 42)))
+	b := types.NewSignedMsgs(2, types.NewMockSigner(types.MustGenerateKeyInfo(1, 43)))
+	mustCid := func(smsg *types.SignedMessage) cid.Cid {
+		c, err := smsg.Cid()
+		require.NoError(t, err)
+		return c
+	}
+
+	t.Run("removes the messages that depend on the evicted one", func(t *testing.T) {
+		plumbing := newFakeMpoolEvictPlumbing(t, append(a, b...)...)
+
+		removed, err := porcelain.MessagePoolEvict(plumbing, mustCid(a[1]))
+		require.NoError(t, err)
+		assert.Equal(t, []cid.Cid{mustCid(a[1]), mustCid(a[2])}, removed)
+
+		// Earlier messages and those of other senders stay.
+		assert.Len(t, plumbing.pending, 3)
+		assert.Contains(t, plumbing.pending, mustCid(a[0]))
+		assert.Contains(t, plumbing.pending, mustCid(b[0]))
+		assert.Contains(t, plumbing.pending, mustCid(b[1]))
+	})
+
+	t.Run("removes only the last message", func(t *testing.T) {
+		plumbing := newFakeMpoolEvictPlumbing(t, a...)
+
+		removed, err := porcelain.MessagePoolEvict(plumbing, mustCid(a[2]))
+		require.NoError(t, err)
+		assert.Equal(t, []cid.Cid{mustCid(a[2])}, removed)
+		assert.Len(t, plumbing.pending, 2)
+	})
+
+	t.Run("fails for a message that is not pending", func(t *testing.T) {
+		plumbing := newFakeMpoolEvictPlumbing(t, a[0])
+
+		_, err := porcelain.MessagePoolEvict(plumbing, mustCid(a[1]))
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not found in pool")
+		assert.Len(t, plumbing.pending, 1)
+	})
+}
+
+type fakeMpoolImportPlumbing struct {
+	added []*types.SignedMessage
+}
+
+func (plumbing *fakeMpoolImportPlumbing) MessagePoolAdd(ctx context.Context, smsg *types.SignedMessage) (cid.Cid, error) {
+	if !smsg.VerifySignature() {
+		return cid.Undef, errors.New("invalid signature")
+	}
+	plumbing.added = append(plumbing.added, smsg)
+	return smsg.Cid()
+}
+
+func TestMessagePoolImport(t *testing.T) {
+	tf.UnitTest(t)
+
+	signer, _ := types.NewMockSignersAndKeyInfo(1)
+	msgs := types.NewSignedMsgs(3, signer)
+
+	forged := *msgs[2]
+	forged.Message.CallSeqNum = 7
+
+	var input bytes.Buffer
+	for _, smsg := range []*types.SignedMessage{msgs[0], msgs[1], &forged} {
+		raw, err := json.Marshal(smsg)
+		require.NoError(t, err)
+		input.Write(raw)
+		input.WriteString("\n")
+	}
+	input.WriteString("\nnot a message\n")
+
+	plumbing := &fakeMpoolImportPlumbing{}
+	result, err := porcelain.MessagePoolImport(context.Background(), plumbing, &input)
+	require.NoError(t, err)
+
+	// The forged message and the garbage line are rejected, the rest added.
+	assert.Equal(t, 2, result.Accepted)
+	assert.Equal(t, 2, result.Rejected)
+	require.Len(t, result.Errors, 2)
+	assert.Contains(t, result.Errors[0], "line 3: invalid signature")
+	assert.Contains(t, result.Errors[1], "line 5:")
+	require.Len(t, plumbing.added, 2)
+	assert.Equal(t, msgs[0].Message.CallSeqNum, plumbing.added[0].Message.CallSeqNum)
+	assert.Equal(t, msgs[1].Message.CallSeqNum, plumbing.added[1].Message.CallSeqNum)
+}
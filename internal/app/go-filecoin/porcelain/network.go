@@ -3,11 +3,17 @@ package porcelain
 import (
 	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
 
+	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/discovery"
 )
 
 type netPlumbing interface {
@@ -35,3 +41,147 @@ func PingMinerWithTimeout(ctx context.Context, minerPID peer.ID, timeout time.Du
 		return fmt.Errorf("couldn't establish connection to miner: %s, timed out after %s", ctx.Err(), timeout.String())
 	}
 }
+
+type helloPlumbing interface {
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+	NetworkHello(ctx context.Context, pid peer.ID) (*discovery.HelloExchange, error)
+}
+
+// HelloSide is one side of a hello handshake.
+type HelloSide struct {
+	Genesis cid.Cid
+	Head    block.TipSetKey
+	Height  uint64
+	// ParentWeight is the parent weight of the head tipset, or nil if the
+	// head is not in the local chain store. The hello message itself does
+	// not carry a weight.
+	ParentWeight *uint64
+}
+
+// HelloReport describes a hello handshake with a peer.
+type HelloReport struct {
+	Peer         peer.ID
+	Ours         HelloSide
+	Theirs       HelloSide
+	GenesisMatch bool
+	// Replayed is set when the peer did not answer and its last hello
+	// message is reported instead.
+	Replayed bool
+}
+
+// NetworkHelloReport performs the hello handshake with a peer and reports
+// both sides of it.
+func NetworkHelloReport(ctx context.Context, plumbing helloPlumbing, pid peer.ID) (*HelloReport, error) {
+	exchange, err := plumbing.NetworkHello(ctx, pid)
+	if err != nil {
+		return nil, err
+	}
+	return &HelloReport{
+		Peer:         pid,
+		Ours:         helloSide(plumbing, &exchange.Ours),
+		Theirs:       helloSide(plumbing, &exchange.Theirs),
+		GenesisMatch: exchange.GenesisMatch(),
+		Replayed:     exchange.Replayed,
+	}, nil
+}
+
+func helloSide(plumbing helloPlumbing, msg *discovery.HelloMessage) HelloSide {
+	side := HelloSide{
+		Genesis: msg.GenesisHash,
+		Head:    msg.HeaviestTipSetCids,
+		Height:  msg.HeaviestTipSetHeight,
+	}
+	if ts, err := plumbing.ChainTipSet(msg.HeaviestTipSetCids); err == nil {
+		if w, err := ts.ParentWeight(); err == nil {
+			side.ParentWeight = &w
+		}
+	}
+	return side
+}
+
+// coreProtocols are the protocols, by name, every node is expected to speak.
+var coreProtocols = []string{
+	"/filecoin/hello",
+	"/fil/kad",
+	"/ipfs/bitswap",
+	"/ipfs/graphsync",
+	"/ipfs/id",
+	"/ipfs/ping",
+}
+
+type protocolsPlumbing interface {
+	NetworkProtocols() []string
+	NetworkPeerProtocols(pid peer.ID) ([]string, error)
+}
+
+// ProtocolInfo describes a libp2p protocol.
+type ProtocolInfo struct {
+	// ID is the full protocol id, empty for a core protocol the node does not
+	// handle.
+	ID string
+	// Name is the id without its version.
+	Name string
+	// Version is the last element of the id. For hello and kad it is the
+	// network name.
+	Version string
+	// Enabled is true if the node handles the protocol.
+	Enabled bool
+	// PeerSupported is true if the peer the protocols were listed for
+	// announced it supports this id, so streams to it can negotiate it.
+	PeerSupported bool
+}
+
+// NetworkProtocols lists the protocols the node handles and any core protocol
+// it does not, sorted by id. If pid is not empty it also reports which of
+// the protocols that peer supports.
+func NetworkProtocols(plumbing protocolsPlumbing, pid peer.ID) ([]ProtocolInfo, error) {
+	var peerProtocols map[string]bool
+	if pid != "" {
+		supported, err := plumbing.NetworkPeerProtocols(pid)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to get the protocols of peer %s", pid.Pretty())
+		}
+		peerProtocols = make(map[string]bool, len(supported))
+		for _, id := range supported {
+			peerProtocols[id] = true
+		}
+	}
+
+	infos := []ProtocolInfo{}
+	enabled := make(map[string]bool)
+	for _, id := range plumbing.NetworkProtocols() {
+		name, version := splitProtocolID(id)
+		enabled[name] = true
+		infos = append(infos, ProtocolInfo{
+			ID:            id,
+			Name:          name,
+			Version:       version,
+			Enabled:       true,
+			PeerSupported: peerProtocols[id],
+		})
+	}
+	for _, name := range coreProtocols {
+		if !enabled[name] {
+			infos = append(infos, ProtocolInfo{Name: name})
+		}
+	}
+
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Name != infos[j].Name {
+			return infos[i].Name < infos[j].Name
+		}
+		return infos[i].ID < infos[j].ID
+	})
+	return infos, nil
+}
+
+// splitProtocolID splits a protocol id such as /ipfs/bitswap/1.1.0 into its
+// name and version. Ids with only two elements, such as /ipfs/bitswap, have no
+// version.
+func splitProtocolID(id string) (name string, version string) {
+	i := strings.LastIndex(id, "/")
+	if i <= 0 || strings.Count(id, "/") < 3 {
+		return id, ""
+	}
+	return id[:i], id[i+1:]
+}
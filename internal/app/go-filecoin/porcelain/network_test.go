@@ -7,10 +7,12 @@ import (
 
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	. "github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/net"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/libp2p/go-libp2p/p2p/protocol/ping"
 )
 
@@ -67,3 +69,55 @@ func TestPingTimeout(t *testing.T) {
 
 	assert.Error(t, PingMinerWithTimeout(ctx, pid, 100*time.Millisecond, plumbing))
 }
+
+type ntwkProtocolsPlumbing struct {
+	protocols     []string
+	peerProtocols []string
+}
+
+func (npp *ntwkProtocolsPlumbing) NetworkProtocols() []string {
+	return npp.protocols
+}
+
+func (npp *ntwkProtocolsPlumbing) NetworkPeerProtocols(pid peer.ID) ([]string, error) {
+	return npp.peerProtocols, nil
+}
+
+func TestNetworkProtocols(t *testing.T) {
+	tf.UnitTest(t)
+
+	plumbing := &ntwkProtocolsPlumbing{
+		protocols: []string{
+			"/ipfs/ping/1.0.0",
+			"/filecoin/hello/localnet",
+			"/ipfs/bitswap/1.1.0",
+			"/ipfs/bitswap",
+			"/ipfs/id/push/1.0.0",
+			"/ipfs/id/1.0.0",
+		},
+		peerProtocols: []string{"/filecoin/hello/localnet", "/ipfs/bitswap"},
+	}
+
+	protocols, err := NetworkProtocols(plumbing, "")
+	require.NoError(t, err)
+	assert.Equal(t, []ProtocolInfo{
+		{Name: "/fil/kad"},
+		{ID: "/filecoin/hello/localnet", Name: "/filecoin/hello", Version: "localnet", Enabled: true},
+		{ID: "/ipfs/bitswap", Name: "/ipfs/bitswap", Enabled: true},
+		{ID: "/ipfs/bitswap/1.1.0", Name: "/ipfs/bitswap", Version: "1.1.0", Enabled: true},
+		{Name: "/ipfs/graphsync"},
+		{ID: "/ipfs/id/1.0.0", Name: "/ipfs/id", Version: "1.0.0", Enabled: true},
+		{ID: "/ipfs/id/push/1.0.0", Name: "/ipfs/id/push", Version: "1.0.0", Enabled: true},
+		{ID: "/ipfs/ping/1.0.0", Name: "/ipfs/ping", Version: "1.0.0", Enabled: true},
+	}, protocols)
+
+	protocols, err = NetworkProtocols(plumbing, th.RequireRandomPeerID(t))
+	require.NoError(t, err)
+	var supported []string
+	for _, p := range protocols {
+		if p.PeerSupported {
+			supported = append(supported, p.ID)
+		}
+	}
+	assert.Equal(t, []string{"/filecoin/hello/localnet", "/ipfs/bitswap"}, supported)
+}
@@ -0,0 +1,158 @@
+package porcelain
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// OrderBookAsk is an unexpired ask of a storage miner.
+type OrderBookAsk struct {
+	Miner address.Address
+	ID    uint64
+	// Price is in attoFIL per byte per block.
+	Price types.AttoFIL
+	// SectorSize is the size of the sectors the miner seals.
+	SectorSize *types.BytesAmount
+	Expiry     *types.BlockHeight
+	// Duration is the number of blocks left until the ask expires.
+	Duration uint64
+}
+
+// OrderBookBid is a storage deal proposal from a client that is still in
+// progress.
+type OrderBookBid struct {
+	Client     address.Address
+	Miner      address.Address
+	ProposalID cid.Cid
+	TotalPrice types.AttoFIL
+	Size       *types.BytesAmount
+	// Duration is the number of blocks the client wants its data stored.
+	Duration uint64
+	State    storagedeal.State
+}
+
+// OrderBook lists the asks and bids of the storage market.
+type OrderBook struct {
+	Asks []OrderBookAsk
+	Bids []OrderBookBid
+}
+
+type obPlumbing interface {
+	claPlubming
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+	DealsLs(context.Context) (<-chan *StorageDealLsResult, error)
+}
+
+// MarketOrderBook returns every unexpired ask on chain, cheapest first, and
+// every bid this node knows of. The chain does not record bids, so they are
+// the accepted storage deal proposals in this node's deal store that have not
+// completed yet: the ones it made as a client and, on a miner, the ones it
+// received. Bids are sorted by client and then miner.
+func MarketOrderBook(ctx context.Context, plumbing obPlumbing) (*OrderBook, error) {
+	head, err := plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, err
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, err
+	}
+	height := types.NewBlockHeight(h)
+
+	book := &OrderBook{Asks: []OrderBookAsk{}, Bids: []OrderBookBid{}}
+	sectorSizes := make(map[address.Address]*types.BytesAmount)
+	for ask := range ClientListAsks(ctx, plumbing) {
+		if ask.Error != nil {
+			return nil, ask.Error
+		}
+		if ask.Expiry.LessThan(height) {
+			continue
+		}
+
+		sectorSize, ok := sectorSizes[ask.Miner]
+		if !ok {
+			ret, err := plumbing.MessageQuery(ctx, address.Undef, ask.Miner, miner.GetSectorSize, plumbing.ChainHeadKey())
+			if err != nil {
+				return nil, errors.Wrapf(err, "failed to get sector size of miner %s", ask.Miner)
+			}
+			sectorSize = types.NewBytesAmountFromBytes(ret[0])
+			sectorSizes[ask.Miner] = sectorSize
+		}
+
+		book.Asks = append(book.Asks, OrderBookAsk{
+			Miner:      ask.Miner,
+			ID:         ask.ID,
+			Price:      ask.Price,
+			SectorSize: sectorSize,
+			Expiry:     ask.Expiry,
+			Duration:   ask.Expiry.Sub(height).AsBigInt().Uint64(),
+		})
+	}
+	sort.Slice(book.Asks, func(i, j int) bool {
+		a, b := book.Asks[i], book.Asks[j]
+		if !a.Price.Equal(b.Price) {
+			return a.Price.LessThan(b.Price)
+		}
+		if a.Miner != b.Miner {
+			return a.Miner.String() < b.Miner.String()
+		}
+		return a.ID < b.ID
+	})
+
+	deals, err := plumbing.DealsLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+	seen := make(map[cid.Cid]bool)
+	for result := range deals {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		deal := result.Deal
+		if deal.Proposal == nil || deal.Response == nil || !isOpenBid(deal.Response.State) {
+			continue
+		}
+		// A node that is both client and miner of a deal stores it twice.
+		if seen[deal.Response.ProposalCid] {
+			continue
+		}
+		seen[deal.Response.ProposalCid] = true
+
+		book.Bids = append(book.Bids, OrderBookBid{
+			Client:     deal.Proposal.Payment.Payer,
+			Miner:      deal.Miner,
+			ProposalID: deal.Response.ProposalCid,
+			TotalPrice: deal.Proposal.TotalPrice,
+			Size:       deal.Proposal.Size,
+			Duration:   deal.Proposal.Duration,
+			State:      deal.Response.State,
+		})
+	}
+	sort.Slice(book.Bids, func(i, j int) bool {
+		a, b := book.Bids[i], book.Bids[j]
+		if a.Client != b.Client {
+			return a.Client.String() < b.Client.String()
+		}
+		if a.Miner != b.Miner {
+			return a.Miner.String() < b.Miner.String()
+		}
+		return a.ProposalID.String() < b.ProposalID.String()
+	})
+
+	return book, nil
+}
+
+// isOpenBid returns true for the states of a deal that a miner has accepted
+// but not yet completed.
+func isOpenBid(state storagedeal.State) bool {
+	return state == storagedeal.Accepted || state == storagedeal.Started || state == storagedeal.Staged
+}
@@ -0,0 +1,131 @@
+package porcelain_test
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+type obPlumbing struct {
+	*cfmPlumbing
+	deals []storagedeal.Deal
+}
+
+func (ob *obPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, baseKey block.TipSetKey, params ...interface{}) ([][]byte, error) {
+	if method == miner.GetSectorSize {
+		return [][]byte{types.OneKiBSectorSize.Bytes()}, nil
+	}
+	return ob.cfmPlumbing.MessageQuery(ctx, optFrom, to, method, baseKey, params...)
+}
+
+func (ob *obPlumbing) DealsLs(_ context.Context) (<-chan *porcelain.StorageDealLsResult, error) {
+	out := make(chan *porcelain.StorageDealLsResult, len(ob.deals))
+	for _, deal := range ob.deals {
+		out <- &porcelain.StorageDealLsResult{Deal: deal}
+	}
+	close(out)
+	return out, nil
+}
+
+func TestMarketOrderBook(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addrGetter := address.NewForTestGetter()
+	minerA, minerB, clientA, clientB := addrGetter(), addrGetter(), addrGetter(), addrGetter()
+
+	ask := func(id int64, price, expiry uint64) miner.Ask {
+		return miner.Ask{
+			ID:     big.NewInt(id),
+			Price:  types.NewAttoFILFromFIL(price),
+			Expiry: types.NewBlockHeight(expiry),
+		}
+	}
+	deal := func(client, minerAddr address.Address, proposal string, state storagedeal.State) storagedeal.Deal {
+		return storagedeal.Deal{
+			Miner: minerAddr,
+			Proposal: &storagedeal.SignedProposal{Proposal: storagedeal.Proposal{
+				Size:       types.NewBytesAmount(42),
+				TotalPrice: types.NewAttoFILFromFIL(7),
+				Duration:   20,
+				Payment:    storagedeal.PaymentInfo{Payer: client},
+			}},
+			Response: &storagedeal.SignedResponse{Response: storagedeal.Response{
+				State:       state,
+				ProposalCid: types.CidFromString(t, proposal),
+			}},
+		}
+	}
+
+	head, err := block.NewTipSet(&block.Block{Height: 10})
+	require.NoError(t, err)
+	plumbing := &obPlumbing{
+		cfmPlumbing: &cfmPlumbing{
+			head: head,
+			miners: map[address.Address]cfmMiner{
+				minerA: {asks: []miner.Ask{ask(0, 30, 100), ask(1, 5, 9)}},
+				minerB: {asks: []miner.Ask{ask(0, 20, 50)}},
+			},
+		},
+		deals: []storagedeal.Deal{
+			deal(clientB, minerA, "first", storagedeal.Staged),
+			deal(clientA, minerB, "second", storagedeal.Accepted),
+			// A node that is both client and miner has the deal twice.
+			deal(clientA, minerB, "second", storagedeal.Accepted),
+			deal(clientA, minerA, "rejected", storagedeal.Rejected),
+			deal(clientA, minerA, "complete", storagedeal.Complete),
+		},
+	}
+
+	book, err := porcelain.MarketOrderBook(ctx, plumbing)
+	require.NoError(t, err)
+
+	// The expired ask is left out and the rest are cheapest first.
+	assert.Equal(t, []porcelain.OrderBookAsk{
+		{
+			Miner:      minerB,
+			ID:         0,
+			Price:      types.NewAttoFILFromFIL(20),
+			SectorSize: types.OneKiBSectorSize,
+			Expiry:     types.NewBlockHeight(50),
+			Duration:   40,
+		},
+		{
+			Miner:      minerA,
+			ID:         0,
+			Price:      types.NewAttoFILFromFIL(30),
+			SectorSize: types.OneKiBSectorSize,
+			Expiry:     types.NewBlockHeight(100),
+			Duration:   90,
+		},
+	}, book.Asks)
+
+	// Only bids that are still open are listed, once each, by client.
+	require.Len(t, book.Bids, 2)
+	bids := map[address.Address]porcelain.OrderBookBid{}
+	for _, bid := range book.Bids {
+		bids[bid.Client] = bid
+	}
+	assert.Equal(t, porcelain.OrderBookBid{
+		Client:     clientA,
+		Miner:      minerB,
+		ProposalID: types.CidFromString(t, "second"),
+		TotalPrice: types.NewAttoFILFromFIL(7),
+		Size:       types.NewBytesAmount(42),
+		Duration:   20,
+		State:      storagedeal.Accepted,
+	}, bids[clientA])
+	assert.Equal(t, storagedeal.Staged, bids[clientB].State)
+	assert.True(t, book.Bids[0].Client.String() < book.Bids[1].Client.String())
+}
@@ -2,10 +2,13 @@ package porcelain
 
 import (
 	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
-
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
@@ -102,3 +105,123 @@ func PaymentChannelVoucher(
 
 	return voucher, nil
 }
+
+// Escrow summarizes the funds a payer holds in payment channels.
+type Escrow struct {
+	// Locked is held by channels that have not reached their end of life
+	// and may still be redeemed by their targets.
+	Locked types.AttoFIL
+	// Available is held by expired channels and may be withdrawn by the
+	// payer.
+	Available types.AttoFIL
+	// Reclaimable lists the expired channels that hold Available.
+	Reclaimable []*types.ChannelID
+}
+
+type pcePlumbing interface {
+	ChainHeadKey() block.TipSetKey
+	ChainTipSet(key block.TipSetKey) (block.TipSet, error)
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, baseKey block.TipSetKey, params ...interface{}) ([][]byte, error)
+	WalletDefaultAddress() (address.Address, error)
+}
+
+// PaymentChannelEscrow totals the unredeemed funds in payerAddr's payment
+// channels, split by whether the channel has expired as of the chain head.
+func PaymentChannelEscrow(ctx context.Context, plumbing pcePlumbing, payerAddr address.Address) (*Escrow, error) {
+	channels, err := PaymentChannelLs(ctx, plumbing, address.Undef, payerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	head, err := plumbing.ChainTipSet(plumbing.ChainHeadKey())
+	if err != nil {
+		return nil, err
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, err
+	}
+	height := types.NewBlockHeight(h)
+
+	escrow := &Escrow{
+		Locked:      types.ZeroAttoFIL,
+		Available:   types.ZeroAttoFIL,
+		Reclaimable: []*types.ChannelID{},
+	}
+	for _, key := range sortedChannelKeys(channels) {
+		channel := channels[key]
+		remaining := channel.Amount.Sub(channel.AmountRedeemed)
+		if !remaining.IsPositive() {
+			continue
+		}
+
+		// The payment broker allows reclaiming at or after Eol.
+		if height.LessThan(channel.Eol) {
+			escrow.Locked = escrow.Locked.Add(remaining)
+			continue
+		}
+		chid, ok := types.NewChannelIDFromString(key, 10)
+		if !ok {
+			return nil, errors.Errorf("invalid channel id %s", key)
+		}
+		escrow.Available = escrow.Available.Add(remaining)
+		escrow.Reclaimable = append(escrow.Reclaimable, chid)
+	}
+	return escrow, nil
+}
+
+// sortedChannelKeys returns the channel ids of channels in numeric order.
+func sortedChannelKeys(channels map[string]*paymentbroker.PaymentChannel) []string {
+	keys := make([]string, 0, len(channels))
+	for key := range channels {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if len(keys[i]) != len(keys[j]) {
+			return len(keys[i]) < len(keys[j])
+		}
+		return keys[i] < keys[j]
+	})
+	return keys
+}
+
+// Withdrawal records the reclaim messages sent to withdraw a payer's
+// available escrow.
+type Withdrawal struct {
+	Amount   types.AttoFIL
+	Messages []cid.Cid
+}
+
+type pcwPlumbing interface {
+	pcePlumbing
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error)
+}
+
+// PaymentChannelWithdraw sends a reclaim message for each of payerAddr's
+// expired payment channels that still holds funds. The funds are returned
+// once the messages are mined.
+func PaymentChannelWithdraw(ctx context.Context, plumbing pcwPlumbing, payerAddr address.Address, gasPrice types.AttoFIL, gasLimit types.GasUnits) (*Withdrawal, error) {
+	escrow, err := PaymentChannelEscrow(ctx, plumbing, payerAddr)
+	if err != nil {
+		return nil, err
+	}
+
+	withdrawal := &Withdrawal{Amount: escrow.Available, Messages: []cid.Cid{}}
+	for _, chid := range escrow.Reclaimable {
+		c, _, err := plumbing.MessageSend(
+			ctx,
+			payerAddr,
+			address.PaymentBrokerAddress,
+			types.ZeroAttoFIL,
+			gasPrice,
+			gasLimit,
+			paymentbroker.Reclaim,
+			chid,
+		)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to reclaim channel %s", chid)
+		}
+		withdrawal.Messages = append(withdrawal.Messages, c)
+	}
+	return withdrawal, nil
+}
@@ -6,6 +6,7 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
+	"github.com/ipfs/go-cid"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -128,3 +129,77 @@ func TestPaymentChannelVoucher(t *testing.T) {
 		assert.NotEqual(t, expectedVoucher.Signature, voucher.Signature)
 	})
 }
+
+type testPaymentChannelEscrowPlumbing struct {
+	testPaymentChannelLsPlumbing
+	head     block.TipSet
+	reclaims []*types.ChannelID
+}
+
+func (p *testPaymentChannelEscrowPlumbing) ChainTipSet(_ block.TipSetKey) (block.TipSet, error) {
+	return p.head, nil
+}
+
+func (p *testPaymentChannelEscrowPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error) {
+	require.Equal(p.testing, paymentbroker.Reclaim, method)
+	chid := params[0].(*types.ChannelID)
+	p.reclaims = append(p.reclaims, chid)
+	return types.CidFromString(p.testing, "reclaim"+chid.String()), nil, nil
+}
+
+func TestPaymentChannelEscrow(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	channel := func(amount, redeemed, eol uint64) *paymentbroker.PaymentChannel {
+		return &paymentbroker.PaymentChannel{
+			Amount:         types.NewAttoFILFromFIL(amount),
+			AmountRedeemed: types.NewAttoFILFromFIL(redeemed),
+			AgreedEol:      types.NewBlockHeight(eol),
+			Eol:            types.NewBlockHeight(eol),
+		}
+	}
+	newPlumbing := func(t *testing.T) *testPaymentChannelEscrowPlumbing {
+		head, err := block.NewTipSet(&block.Block{Height: 8})
+		require.NoError(t, err)
+		return &testPaymentChannelEscrowPlumbing{
+			testPaymentChannelLsPlumbing: testPaymentChannelLsPlumbing{
+				testing: t,
+				channels: map[string]*paymentbroker.PaymentChannel{
+					"10": channel(7, 0, 20), // open
+					"11": channel(3, 3, 1),  // expired but fully redeemed
+					"2":  channel(10, 4, 5), // expired
+					"3":  channel(5, 0, 8),  // expires at the head
+				},
+			},
+			head: head,
+		}
+	}
+
+	t.Run("splits funds by channel expiry", func(t *testing.T) {
+		escrow, err := porcelain.PaymentChannelEscrow(ctx, newPlumbing(t), address.Undef)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewAttoFILFromFIL(7), escrow.Locked)
+		assert.Equal(t, types.NewAttoFILFromFIL(11), escrow.Available)
+		assert.Equal(t, []*types.ChannelID{types.NewChannelID(2), types.NewChannelID(3)}, escrow.Reclaimable)
+	})
+
+	t.Run("withdraw reclaims expired channels", func(t *testing.T) {
+		plumbing := newPlumbing(t)
+		withdrawal, err := porcelain.PaymentChannelWithdraw(ctx, plumbing, address.Undef, types.NewAttoFILFromFIL(1), types.NewGasUnits(300))
+		require.NoError(t, err)
+		assert.Equal(t, types.NewAttoFILFromFIL(11), withdrawal.Amount)
+		assert.Equal(t, []*types.ChannelID{types.NewChannelID(2), types.NewChannelID(3)}, plumbing.reclaims)
+		assert.Equal(t, []cid.Cid{types.CidFromString(t, "reclaim2"), types.CidFromString(t, "reclaim3")}, withdrawal.Messages)
+	})
+
+	t.Run("nothing to withdraw", func(t *testing.T) {
+		plumbing := newPlumbing(t)
+		plumbing.channels = map[string]*paymentbroker.PaymentChannel{"1": channel(5, 0, 20)}
+		withdrawal, err := porcelain.PaymentChannelWithdraw(ctx, plumbing, address.Undef, types.NewAttoFILFromFIL(1), types.NewGasUnits(300))
+		require.NoError(t, err)
+		assert.Equal(t, types.ZeroAttoFIL, withdrawal.Amount)
+		assert.Empty(t, withdrawal.Messages)
+		assert.Empty(t, plumbing.reclaims)
+	})
+}
@@ -25,11 +25,11 @@ type cpPlumbing interface {
 	MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, baseKey block.TipSetKey, params ...interface{}) ([][]byte, error)
 	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error)
 	MessageWait(ctx context.Context, msgCid cid.Cid, cb func(*block.Block, *types.SignedMessage, *types.MessageReceipt) error) error
-	SignBytes(data []byte, addr address.Address) (types.Signature, error)
+	SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error)
 }
 
-// CreatePaymentsParams structures all the parameters for the CreatePayments command. All values are required.
-// The first payment will be valid at PaymentStart+PaymentInterval. Payment voucher will be created for every
+// CreatePaymentsParams structures all the parameters for the CreatePayments command. All values except
+// PaymentStart are required. The first payment will be valid at PaymentStart+PaymentInterval. Payment voucher will be created for every
 // PaymentInterval after that until PaymentStart+Duration is reached.
 // ChannelExpiry is when the channel closes and must be after the final payment is valid.
 type CreatePaymentsParams struct {
@@ -58,8 +58,12 @@ type CreatePaymentsParams struct {
 	// PaymentInterval is the time between payments (in block height)
 	PaymentInterval uint64
 
+	// PaymentStart is the block height the payments start from. The current block height is used if
+	// it is nil.
+	PaymentStart *types.BlockHeight
+
 	// ChannelExpiry is the time (block height) at which the payment channel will close. It must
-	// be greater than the current block height plus Duration.
+	// be greater than PaymentStart plus Duration.
 	ChannelExpiry types.BlockHeight
 
 	// GasPrice is the price of gas to be paid to create the payment channel
@@ -115,10 +119,13 @@ func CreatePayments(ctx context.Context, plumbing cpPlumbing, config CreatePayme
 	if err != nil {
 		return nil, errors.Wrap(err, "Could not retrieve block height for making payments")
 	}
-	currentHeight := types.NewBlockHeight(h)
+	paymentStart := types.NewBlockHeight(h)
+	if config.PaymentStart != nil {
+		paymentStart = config.PaymentStart
+	}
 
 	// validate that channel expiry gives us enough time
-	lastPayment := currentHeight.Add(types.NewBlockHeight(config.Duration))
+	lastPayment := paymentStart.Add(types.NewBlockHeight(config.Duration))
 	if config.ChannelExpiry.LessThan(lastPayment) {
 		return nil, fmt.Errorf("channel would expire (%s) before last payment is made (%s)", config.ChannelExpiry.String(), lastPayment)
 	}
@@ -172,25 +179,37 @@ func CreatePayments(ctx context.Context, plumbing cpPlumbing, config CreatePayme
 	headKey := plumbing.ChainHeadKey()
 	response.Vouchers = []*types.PaymentVoucher{}
 	voucherAmount := types.ZeroAttoFIL
+	var sigData [][]byte
 	for i := 0; uint64(i+1)*config.PaymentInterval < config.Duration; i++ {
 		voucherAmount = voucherAmount.Add(valuePerPayment)
 		if voucherAmount.GreaterThan(config.Value) {
 			voucherAmount = config.Value
 		}
 
-		validAt := currentHeight.Add(types.NewBlockHeight(uint64(i+1) * config.PaymentInterval))
-		err = createPayment(ctx, plumbing, headKey, response, voucherAmount, validAt, condition)
+		validAt := paymentStart.Add(types.NewBlockHeight(uint64(i+1) * config.PaymentInterval))
+		data, err := createPayment(ctx, plumbing, headKey, response, voucherAmount, validAt, condition)
 		if err != nil {
 			return response, err
 		}
+		sigData = append(sigData, data)
 	}
 
 	// create last payment
-	validAt := currentHeight.Add(types.NewBlockHeight(config.Duration))
-	err = createPayment(ctx, plumbing, headKey, response, config.Value, validAt, nil)
+	validAt := paymentStart.Add(types.NewBlockHeight(config.Duration))
+	data, err := createPayment(ctx, plumbing, headKey, response, config.Value, validAt, nil)
 	if err != nil {
 		return response, err
 	}
+	sigData = append(sigData, data)
+
+	// sign all vouchers at once so that the payer's key is loaded only once
+	sigs, err := plumbing.SignBytesBatch(sigData, response.From)
+	if err != nil {
+		return response, err
+	}
+	for i, voucher := range response.Vouchers {
+		voucher.Signature = sigs[i]
+	}
 
 	return response, nil
 }
@@ -241,7 +260,9 @@ func ValidatePaymentVoucherCondition(ctx context.Context, condition *types.Predi
 	return nil
 }
 
-func createPayment(ctx context.Context, plumbing cpPlumbing, baseKey block.TipSetKey, response *CreatePaymentsReturn, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) error {
+// createPayment adds an unsigned voucher to the response and returns the data
+// to sign for it.
+func createPayment(ctx context.Context, plumbing cpPlumbing, baseKey block.TipSetKey, response *CreatePaymentsReturn, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, error) {
 
 	ret, err := plumbing.MessageQuery(ctx,
 		response.From,
@@ -254,20 +275,19 @@ func createPayment(ctx context.Context, plumbing cpPlumbing, baseKey block.TipSe
 		condition,
 	)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	var voucher types.PaymentVoucher
 	if err := encoding.Decode(ret[0], &voucher); err != nil {
-		return err
+		return nil, err
 	}
 
-	sig, err := paymentbroker.SignVoucher(&voucher.Channel, amount, validAt, voucher.Payer, condition, plumbing)
+	data, err := paymentbroker.VoucherSignatureData(&voucher.Channel, amount, validAt, condition)
 	if err != nil {
-		return err
+		return nil, err
 	}
-	voucher.Signature = sig
 
 	response.Vouchers = append(response.Vouchers, &voucher)
-	return nil
+	return data, nil
 }
@@ -94,8 +94,12 @@ func (ptp *paymentsTestPlumbing) ChainHeadKey() block.TipSetKey {
 	return block.NewTipSetKey()
 }
 
-func (ptp *paymentsTestPlumbing) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
-	return []byte("signature"), nil
+func (ptp *paymentsTestPlumbing) SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error) {
+	sigs := make([]types.Signature, len(items))
+	for i := range items {
+		sigs[i] = []byte("signature")
+	}
+	return sigs, nil
 }
 
 func validPaymentsConfig() CreatePaymentsParams {
@@ -160,7 +164,7 @@ func TestCreatePayments(t *testing.T) {
 			assert.Equal(t, miner.VerifyPieceInclusion, voucher.Condition.Method)
 			assert.Equal(t, config.CommP[:], voucher.Condition.Params[0])
 
-			// voucher signature should be what is returned by SignBytes
+			// voucher signature should be what is returned by SignBytesBatch
 			sig := types.Signature([]byte("signature"))
 			assert.Equal(t, sig, voucher.Signature)
 		}
@@ -173,6 +177,30 @@ func TestCreatePayments(t *testing.T) {
 		assert.Nil(t, paymentResponse.Vouchers[9].Condition)
 	})
 
+	t.Run("Payments start at PaymentStart when it is set", func(t *testing.T) {
+		config := validPaymentsConfig()
+		config.PaymentStart = types.NewBlockHeight(startingBlock + 100)
+		config.ChannelExpiry = *types.NewBlockHeight(startingBlock + 100 + config.Duration)
+
+		paymentResponse, err := CreatePayments(context.Background(), successPlumbing, config)
+		require.NoError(t, err)
+
+		require.Len(t, paymentResponse.Vouchers, 10)
+		for i, voucher := range paymentResponse.Vouchers {
+			assert.Equal(t, *config.PaymentStart.Add(types.NewBlockHeight(config.PaymentInterval * uint64(i+1))), voucher.ValidAt)
+		}
+	})
+
+	t.Run("Errors if channel expires before payments starting at PaymentStart end", func(t *testing.T) {
+		config := validPaymentsConfig()
+		config.PaymentStart = types.NewBlockHeight(startingBlock + 100)
+		config.ChannelExpiry = *types.NewBlockHeight(startingBlock + 100 + config.Duration - 1)
+
+		_, err := CreatePayments(context.Background(), successPlumbing, config)
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "channel would expire")
+	})
+
 	t.Run("Payments constructed correctly when paymentInterval does not divide duration", func(t *testing.T) {
 		config := validPaymentsConfig()
 
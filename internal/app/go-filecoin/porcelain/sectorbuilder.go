@@ -10,11 +10,14 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/sectorbuilder"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 
 	"github.com/pkg/errors"
 )
 
 type sbPlumbing interface {
+	ConfigGet(dottedPath string) (interface{}, error)
+	MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error)
 	SectorBuilder() sectorbuilder.SectorBuilder
 	DAGImportData(context.Context, io.Reader) (ipld.Node, error)
 	DAGCat(context.Context, cid.Cid) (io.Reader, error)
@@ -38,7 +41,9 @@ func CalculatePoSt(ctx context.Context, plumbing sbPlumbing, sortedCommRs go_sec
 	return res.Proof, nil
 }
 
-// AddPiece adds piece data to a staged sector
+// AddPiece adds piece data to a staged sector. Pieces larger than the number
+// of user bytes which fit into a sector of the miner's sector size are rejected
+// with sectorbuilder.ErrPieceTooLarge.
 func AddPiece(ctx context.Context, plumbing sbPlumbing, pieceReader io.Reader) (uint64, error) {
 	if plumbing.SectorBuilder() == nil {
 		return 0, errors.New("must be mining to add piece")
@@ -59,6 +64,14 @@ func AddPiece(ctx context.Context, plumbing sbPlumbing, pieceReader io.Reader) (
 		return 0, errors.Wrap(err, "could not calculate piece size")
 	}
 
+	maxPieceSize, err := maxPieceSizeForMiner(ctx, plumbing)
+	if err != nil {
+		return 0, err
+	}
+	if size > maxPieceSize {
+		return 0, errors.Wrapf(sectorbuilder.ErrPieceTooLarge, "piece of %d bytes exceeds the %d bytes available per sector", size, maxPieceSize)
+	}
+
 	sectorID, err := plumbing.SectorBuilder().AddPiece(ctx, node.Cid(), size, dagReader)
 	if err != nil {
 		return 0, errors.Wrap(err, "could not add piece")
@@ -76,3 +89,23 @@ func SealNow(ctx context.Context, plumbing sbPlumbing) error {
 	// start sealing on all existing staged sectors
 	return plumbing.SectorBuilder().SealAllStagedSectors(ctx)
 }
+
+// maxPieceSizeForMiner returns the maximum number of user bytes which fit into
+// a sector of the size committed to by the node's miner actor.
+func maxPieceSizeForMiner(ctx context.Context, plumbing sbPlumbing) (uint64, error) {
+	retVal, err := plumbing.ConfigGet("mining.minerAddress")
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get miner address")
+	}
+	minerAddr, ok := retVal.(address.Address)
+	if !ok {
+		return 0, errors.New("problem converting miner address")
+	}
+
+	sectorSize, err := plumbing.MinerGetSectorSize(ctx, minerAddr)
+	if err != nil {
+		return 0, errors.Wrap(err, "could not get miner sector size")
+	}
+
+	return go_sectorbuilder.GetMaxUserBytesPerStagedSector(sectorSize.Uint64()), nil
+}
@@ -9,12 +9,14 @@ import (
 	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 	ipld "github.com/ipfs/go-ipld-format"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	. "github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/sectorbuilder"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/filecoin-project/go-sectorbuilder"
 )
 
@@ -45,21 +47,47 @@ func TestAddPiece(t *testing.T) {
 		// doesn't seal sectors
 		assert.Equal(t, 0, p.sectorbuilder.sealAllSectorsCount)
 	})
+
+	t.Run("rejects piece too large for miner sector size", func(t *testing.T) {
+		p := newTestSectorBuilderPlumbing(0)
+		p.pieceData = make([]byte, go_sectorbuilder.GetMaxUserBytesPerStagedSector(p.sectorSize.Uint64())+1)
+
+		_, err := AddPiece(context.Background(), p, bytes.NewReader(p.pieceData))
+		require.Error(t, err)
+		assert.Equal(t, sectorbuilder.ErrPieceTooLarge, errors.Cause(err))
+
+		// never reaches the sector builder
+		assert.Equal(t, 0, p.sectorbuilder.addPieceCount)
+	})
 }
 
 func newTestSectorBuilderPlumbing(stagedSectors int) *testSectorBuilderPlumbing {
 	sb := &testSectorBuilder{numStagedSectors: stagedSectors}
 	return &testSectorBuilderPlumbing{
 		sectorbuilder: sb,
+		minerAddr:     address.NewForTestGetter()(),
+		sectorSize:    types.OneKiBSectorSize,
+		pieceData:     testPieceData,
 	}
 }
 
 type testSectorBuilderPlumbing struct {
 	sectorbuilder *testSectorBuilder
+	minerAddr     address.Address
+	sectorSize    *types.BytesAmount
+	pieceData     []byte
 }
 
 var testPieceData = []byte{1, 2, 3}
 
+func (tsbp *testSectorBuilderPlumbing) ConfigGet(dottedPath string) (interface{}, error) {
+	return tsbp.minerAddr, nil
+}
+
+func (tsbp *testSectorBuilderPlumbing) MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error) {
+	return tsbp.sectorSize, nil
+}
+
 func (tsbp *testSectorBuilderPlumbing) SectorBuilder() sectorbuilder.SectorBuilder {
 	return tsbp.sectorbuilder
 }
@@ -69,11 +97,11 @@ func (tsbp *testSectorBuilderPlumbing) DAGImportData(ctx context.Context, pieceR
 }
 
 func (tsbp *testSectorBuilderPlumbing) DAGCat(ctx context.Context, cid cid.Cid) (io.Reader, error) {
-	return bytes.NewReader(testPieceData), nil
+	return bytes.NewReader(tsbp.pieceData), nil
 }
 
 func (tsbp *testSectorBuilderPlumbing) DAGGetFileSize(ctx context.Context, c cid.Cid) (uint64, error) {
-	return uint64(len(testPieceData)), nil
+	return uint64(len(tsbp.pieceData)), nil
 }
 
 type testSectorBuilder struct {
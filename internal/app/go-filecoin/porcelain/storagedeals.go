@@ -2,6 +2,7 @@ package porcelain
 
 import (
 	"context"
+	"sort"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
@@ -48,6 +49,84 @@ func DealGet(ctx context.Context, plumbing dealGetPlumbing, dealCid cid.Cid) (*s
 	return nil, ErrDealNotFound
 }
 
+// DealRecord is a storage deal of this node as listed by client list-deals
+// and miner list-deals.
+type DealRecord struct {
+	ProposalCid  cid.Cid         `json:"proposalCid"`
+	State        string          `json:"state"`
+	TotalPrice   types.AttoFIL   `json:"totalPrice"`
+	Duration     uint64          `json:"duration"`
+	Counterparty address.Address `json:"counterparty"`
+}
+
+// ClientListDeals lists the deals this node proposed as a client, that is the
+// stored deals with a miner other than `ownMiner`, the miner of this node if
+// it has one. The counterparty of each deal is its miner. Without `states`
+// the deals that were rejected or failed are left out, otherwise only the
+// deals in one of `states` are listed.
+func ClientListDeals(ctx context.Context, plumbing dealGetPlumbing, ownMiner address.Address, states ...storagedeal.State) ([]DealRecord, error) {
+	return listDealRecords(ctx, plumbing, states, func(deal *storagedeal.Deal) (address.Address, bool) {
+		return deal.Miner, deal.Miner != ownMiner
+	})
+}
+
+// MinerListDealRecords lists the deals miner `minerAddr` received, with the
+// paying client as counterparty. States filter the deals as in
+// ClientListDeals.
+func MinerListDealRecords(ctx context.Context, plumbing dealGetPlumbing, minerAddr address.Address, states ...storagedeal.State) ([]DealRecord, error) {
+	return listDealRecords(ctx, plumbing, states, func(deal *storagedeal.Deal) (address.Address, bool) {
+		return deal.Proposal.Payment.Payer, deal.Miner == minerAddr
+	})
+}
+
+// listDealRecords lists the stored deals in `states` for which `counterparty`
+// returns true, ordered by proposal cid.
+func listDealRecords(ctx context.Context, plumbing dealGetPlumbing, states []storagedeal.State, counterparty func(*storagedeal.Deal) (address.Address, bool)) ([]DealRecord, error) {
+	dealCh, err := plumbing.DealsLs(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	records := []DealRecord{}
+	for result := range dealCh {
+		if result.Err != nil {
+			return nil, result.Err
+		}
+		deal := result.Deal
+		if deal.Proposal == nil || deal.Response == nil || !dealStateListed(deal.Response.State, states) {
+			continue
+		}
+		party, ok := counterparty(&deal)
+		if !ok {
+			continue
+		}
+		records = append(records, DealRecord{
+			ProposalCid:  deal.Response.ProposalCid,
+			State:        deal.Response.State.String(),
+			TotalPrice:   deal.Proposal.TotalPrice,
+			Duration:     deal.Proposal.Duration,
+			Counterparty: party,
+		})
+	}
+
+	sort.Slice(records, func(i, j int) bool {
+		return records[i].ProposalCid.String() < records[j].ProposalCid.String()
+	})
+	return records, nil
+}
+
+func dealStateListed(state storagedeal.State, states []storagedeal.State) bool {
+	if len(states) == 0 {
+		return state != storagedeal.Rejected && state != storagedeal.Failed
+	}
+	for _, s := range states {
+		if s == state {
+			return true
+		}
+	}
+	return false
+}
+
 type dealLsPlumbing interface {
 	ConfigGet(string) (interface{}, error)
 	DealsIterator() (*query.Results, error)
@@ -85,6 +85,64 @@ func TestDealGetNotFound(t *testing.T) {
 	assert.Nil(t, resultDeal)
 }
 
+func TestListDeals(t *testing.T) {
+	tf.UnitTest(t)
+
+	addrGetter := address.NewForTestGetter()
+	ownMiner, otherMiner, client := addrGetter(), addrGetter(), addrGetter()
+	cidGetter := types.NewCidForTestGetter()
+
+	deal := func(minerAddr address.Address, state storagedeal.State) *storagedeal.Deal {
+		return &storagedeal.Deal{
+			Miner: minerAddr,
+			Proposal: &storagedeal.SignedProposal{Proposal: storagedeal.Proposal{
+				TotalPrice: types.NewAttoFILFromFIL(3),
+				Duration:   10,
+				Payment:    storagedeal.PaymentInfo{Payer: client},
+			}},
+			Response: &storagedeal.SignedResponse{Response: storagedeal.Response{
+				State:       state,
+				ProposalCid: cidGetter(),
+			}},
+		}
+	}
+	proposed := deal(otherMiner, storagedeal.Accepted)
+	failed := deal(otherMiner, storagedeal.Failed)
+	received := deal(ownMiner, storagedeal.Complete)
+	plumbing := &testDealLsPlumbing{
+		deals: []*storagedeal.Deal{proposed, failed, received},
+	}
+
+	t.Run("client deals are those with other miners", func(t *testing.T) {
+		records, err := porcelain.ClientListDeals(context.Background(), plumbing, ownMiner)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, porcelain.DealRecord{
+			ProposalCid:  proposed.Response.ProposalCid,
+			State:        "accepted",
+			TotalPrice:   types.NewAttoFILFromFIL(3),
+			Duration:     10,
+			Counterparty: otherMiner,
+		}, records[0])
+	})
+
+	t.Run("states select the listed deals", func(t *testing.T) {
+		records, err := porcelain.ClientListDeals(context.Background(), plumbing, ownMiner, storagedeal.Failed)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, failed.Response.ProposalCid, records[0].ProposalCid)
+	})
+
+	t.Run("miner deals have the client as counterparty", func(t *testing.T) {
+		records, err := porcelain.MinerListDealRecords(context.Background(), plumbing, ownMiner)
+		require.NoError(t, err)
+		require.Len(t, records, 1)
+		assert.Equal(t, received.Response.ProposalCid, records[0].ProposalCid)
+		assert.Equal(t, "complete", records[0].State)
+		assert.Equal(t, client, records[0].Counterparty)
+	})
+}
+
 type testRedeemPlumbing struct {
 	t *testing.T
 
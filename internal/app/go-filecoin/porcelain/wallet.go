@@ -33,6 +33,24 @@ func WalletBalance(ctx context.Context, plumbing wbPlumbing, addr address.Addres
 	return act.Balance, nil
 }
 
+type wtbPlumbing interface {
+	ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error)
+	WalletAddresses() []address.Address
+}
+
+// WalletTotalBalance sums the current balances of every address in the wallet
+func WalletTotalBalance(ctx context.Context, plumbing wtbPlumbing) (types.AttoFIL, error) {
+	total := types.ZeroAttoFIL
+	for _, addr := range plumbing.WalletAddresses() {
+		balance, err := WalletBalance(ctx, plumbing, addr)
+		if err != nil {
+			return types.ZeroAttoFIL, errors.Wrapf(err, "failed to get balance of %s", addr)
+		}
+		total = total.Add(balance)
+	}
+	return total, nil
+}
+
 type wdaPlumbing interface {
 	ConfigGet(dottedPath string) (interface{}, error)
 	ConfigSet(dottedPath string, paramJSON string) error
@@ -62,3 +80,37 @@ func WalletDefaultAddress(plumbing wdaPlumbing) (address.Address, error) {
 
 	return address.Undef, ErrNoDefaultFromAddress
 }
+
+type wsdaPlumbing interface {
+	ConfigSet(dottedPath string, paramJSON string) error
+	WalletAddresses() []address.Address
+}
+
+// WalletSetDefaultAddress makes addr the default wallet address in the config.
+// The address must be in the wallet.
+func WalletSetDefaultAddress(plumbing wsdaPlumbing, addr address.Address) error {
+	for _, a := range plumbing.WalletAddresses() {
+		if a == addr {
+			return plumbing.ConfigSet("wallet.defaultAddress", addr.String())
+		}
+	}
+	return errors.Errorf("address %s is not in the wallet", addr)
+}
+
+type wdelPlumbing interface {
+	ConfigGet(dottedPath string) (interface{}, error)
+	WalletDeleteAddress(addr address.Address) error
+}
+
+// WalletDeleteAddress removes the key of addr from the wallet. The default
+// address cannot be deleted; another default has to be set first.
+func WalletDeleteAddress(plumbing wdelPlumbing, addr address.Address) error {
+	ret, err := plumbing.ConfigGet("wallet.defaultAddress")
+	if err != nil {
+		return err
+	}
+	if ret.(address.Address) == addr {
+		return errors.Errorf("cannot delete the default address %s; set another default address first", addr)
+	}
+	return plumbing.WalletDeleteAddress(addr)
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 
 	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/plumbing/cfg"
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
@@ -75,6 +76,49 @@ func TestWalletBalance(t *testing.T) {
 	})
 }
 
+type wtbTestPlumbing struct {
+	balances map[address.Address]types.AttoFIL
+}
+
+func (wtbtp *wtbTestPlumbing) ActorGet(ctx context.Context, addr address.Address) (*actor.Actor, error) {
+	balance, ok := wtbtp.balances[addr]
+	if !ok {
+		return nil, errors.New("unexpected address")
+	}
+	return actor.NewActor(cid.Undef, balance), nil
+}
+
+func (wtbtp *wtbTestPlumbing) WalletAddresses() []address.Address {
+	var addrs []address.Address
+	for addr := range wtbtp.balances {
+		addrs = append(addrs, addr)
+	}
+	return addrs
+}
+
+func TestWalletTotalBalance(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	addrGetter := address.NewForTestGetter()
+
+	t.Run("sums the balances of all addresses", func(t *testing.T) {
+		plumbing := &wtbTestPlumbing{balances: map[address.Address]types.AttoFIL{
+			addrGetter(): types.NewAttoFILFromFIL(20),
+			addrGetter(): types.NewAttoFILFromFIL(3),
+			addrGetter(): types.ZeroAttoFIL,
+		}}
+		total, err := porcelain.WalletTotalBalance(ctx, plumbing)
+		require.NoError(t, err)
+		assert.Equal(t, types.NewAttoFILFromFIL(23), total)
+	})
+
+	t.Run("an empty wallet has zero balance", func(t *testing.T) {
+		total, err := porcelain.WalletTotalBalance(ctx, &wtbTestPlumbing{})
+		require.NoError(t, err)
+		assert.True(t, total.IsZero())
+	})
+}
+
 func TestWalletDefaultAddress(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -111,6 +155,68 @@ func TestWalletDefaultAddress(t *testing.T) {
 	})
 }
 
+func TestWalletSetDefaultAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("sets an address in the wallet as the default", func(t *testing.T) {
+		wdatp := newWdaTestPlumbing(t)
+		_, err := wdatp.WalletNewAddress()
+		require.NoError(t, err)
+		addr, err := wdatp.WalletNewAddress()
+		require.NoError(t, err)
+
+		require.NoError(t, porcelain.WalletSetDefaultAddress(wdatp, addr))
+
+		got, err := porcelain.WalletDefaultAddress(wdatp)
+		require.NoError(t, err)
+		assert.Equal(t, addr, got)
+	})
+
+	t.Run("rejects an address that is not in the wallet", func(t *testing.T) {
+		wdatp := newWdaTestPlumbing(t)
+		def, err := wdatp.WalletNewAddress()
+		require.NoError(t, err)
+		require.NoError(t, wdatp.ConfigSet("wallet.defaultAddress", def.String()))
+
+		err = porcelain.WalletSetDefaultAddress(wdatp, address.TestAddress)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "not in the wallet")
+
+		got, err := porcelain.WalletDefaultAddress(wdatp)
+		require.NoError(t, err)
+		assert.Equal(t, def, got)
+	})
+}
+
+func (wdatp *wdaTestPlumbing) WalletDeleteAddress(addr address.Address) error {
+	return wdatp.wallet.DeleteAddress(addr)
+}
+
+func TestWalletDeleteAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	wdatp := newWdaTestPlumbing(t)
+	def, err := wdatp.WalletNewAddress()
+	require.NoError(t, err)
+	require.NoError(t, wdatp.ConfigSet("wallet.defaultAddress", def.String()))
+	other, err := wdatp.WalletNewAddress()
+	require.NoError(t, err)
+
+	t.Run("refuses to delete the default address", func(t *testing.T) {
+		err := porcelain.WalletDeleteAddress(wdatp, def)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "default address")
+		assert.True(t, isInList(def, wdatp.WalletAddresses()))
+	})
+
+	t.Run("deletes any other address", func(t *testing.T) {
+		require.NoError(t, porcelain.WalletDeleteAddress(wdatp, other))
+		assert.False(t, isInList(other, wdatp.WalletAddresses()))
+
+		assert.Equal(t, wallet.ErrUnknownAddress, porcelain.WalletDeleteAddress(wdatp, other))
+	})
+}
+
 func isInList(needle address.Address, haystack []address.Address) bool {
 	for _, a := range haystack {
 		if a == needle {
@@ -134,6 +134,17 @@ func TestTipSet(t *testing.T) {
 		assert.NotEqual(t, ts1a, blk.UndefTipSet)
 		assert.NotEqual(t, ts2, blk.UndefTipSet)
 		assert.NotEqual(t, ts3, blk.UndefTipSet)
+
+		// Equals compares keys, which do not depend on the order the
+		// blocks were given in.
+		assert.True(t, ts1a.Equals(ts1b))
+		assert.True(t, ts1b.Equals(ts1a))
+		assert.Equal(t, ts1a.Key(), ts1b.Key())
+		assert.Equal(t, ts1a.String(), ts1b.String())
+		assert.False(t, ts1a.Equals(ts2))
+		assert.False(t, ts2.Equals(ts3))
+		assert.False(t, ts3.Equals(blk.UndefTipSet))
+		assert.True(t, blk.UndefTipSet.Equals(blk.UndefTipSet))
 	})
 
 	t.Run("slice", func(t *testing.T) {
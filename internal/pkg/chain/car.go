@@ -10,6 +10,7 @@ import (
 	"github.com/ipfs/go-cid"
 	format "github.com/ipfs/go-ipld-format"
 	logging "github.com/ipfs/go-log"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
@@ -163,16 +164,110 @@ type carStore interface {
 	Put(blocks.Block) error
 }
 
-// Import imports a chain from `in` to `bs`.
+// ErrIncompleteChain is returned by Import when the chain in the car file does
+// not connect its head back to a genesis block.
+var ErrIncompleteChain = errors.New("car file does not contain a complete chain")
+
+// Import imports a chain from `in` to `bs`. Nothing is imported unless every
+// tipset from the head in the car header back to a genesis block is in `in`,
+// along with the message and receipt roots of its blocks and the genesis state
+// root.
 func Import(ctx context.Context, cs carStore, in io.Reader) (block.TipSetKey, error) {
-	header, err := car.LoadCar(cs, in)
+	loaded := newCarImportStore()
+	header, err := car.LoadCar(loaded, in)
 	if err != nil {
 		return block.UndefTipSet.Key(), err
 	}
 	headKey := block.NewTipSetKey(header.Roots...)
+	if err := validateCarChain(loaded, headKey); err != nil {
+		return block.UndefTipSet.Key(), err
+	}
+
+	for _, c := range loaded.order {
+		if err := cs.Put(loaded.blocks[c]); err != nil {
+			return block.UndefTipSet.Key(), err
+		}
+	}
 	return headKey, nil
 }
 
+// validateCarChain walks the tipsets in `bs` from `head` through their parents
+// and fails unless the walk ends at a genesis block. The message and receipt
+// roots of every block, and the state root of the genesis block, must be in
+// `bs` too: the state of later tipsets is recomputed from them when the chain
+// is synced.
+func validateCarChain(bs *carImportStore, head block.TipSetKey) error {
+	cur := head
+	var prevHeight uint64
+	for {
+		if cur.Empty() {
+			return errors.Wrap(ErrIncompleteChain, "empty tipset key")
+		}
+		var blks []*block.Block
+		for _, c := range cur.ToSlice() {
+			raw, ok := bs.blocks[c]
+			if !ok {
+				return errors.Wrapf(ErrIncompleteChain, "missing block %s", c)
+			}
+			blk, err := block.DecodeBlock(raw.RawData())
+			if err != nil {
+				return errors.Wrapf(err, "failed to decode block %s", c)
+			}
+			for _, root := range []cid.Cid{blk.Messages.SecpRoot, blk.Messages.BLSRoot, blk.MessageReceipts} {
+				if _, ok := bs.blocks[root]; !ok {
+					return errors.Wrapf(ErrIncompleteChain, "missing messages or receipts %s of block %s", root, c)
+				}
+			}
+			blks = append(blks, blk)
+		}
+		ts, err := block.NewTipSet(blks...)
+		if err != nil {
+			return errors.Wrapf(err, "invalid tipset %s", cur)
+		}
+		height, err := ts.Height()
+		if err != nil {
+			return err
+		}
+		if !cur.Equals(head) && height >= prevHeight {
+			return errors.Wrapf(ErrIncompleteChain, "tipset %s at height %d is not below its child", cur, height)
+		}
+		parents, err := ts.Parents()
+		if err != nil {
+			return err
+		}
+		if parents.Empty() {
+			if height != 0 {
+				return errors.Wrapf(ErrIncompleteChain, "tipset %s at height %d has no parents", cur, height)
+			}
+			if _, ok := bs.blocks[blks[0].StateRoot]; !ok {
+				return errors.Wrapf(ErrIncompleteChain, "missing state root %s of genesis block %s", blks[0].StateRoot, cur)
+			}
+			return nil
+		}
+		prevHeight = height
+		cur = parents
+	}
+}
+
+// carImportStore holds the blocks of a car file in memory, in the order they
+// were read, until the chain they make up has been validated.
+type carImportStore struct {
+	blocks map[cid.Cid]blocks.Block
+	order  []cid.Cid
+}
+
+func newCarImportStore() *carImportStore {
+	return &carImportStore{blocks: make(map[cid.Cid]blocks.Block)}
+}
+
+func (cs *carImportStore) Put(b blocks.Block) error {
+	if _, ok := cs.blocks[b.Cid()]; !ok {
+		cs.blocks[b.Cid()] = b
+		cs.order = append(cs.order, b.Cid())
+	}
+	return nil
+}
+
 // carExportBlockstore allows a structure that would normally put blocks in a block store to output to a car file instead.
 type carExportBlockstore struct {
 	out io.Writer
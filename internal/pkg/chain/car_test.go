@@ -4,20 +4,25 @@ import (
 	"bufio"
 	"bytes"
 	"context"
+	"io"
 	"testing"
 
 	"github.com/filecoin-project/go-amt-ipld"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/ipfs/go-car"
+	carutil "github.com/ipfs/go-car/util"
 	"github.com/ipfs/go-cid"
 	ds "github.com/ipfs/go-datastore"
 	"github.com/ipfs/go-ipfs-blockstore"
 	"github.com/ipfs/go-ipld-cbor"
 	format "github.com/ipfs/go-ipld-format"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/whyrusleeping/cbor-gen"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
@@ -217,6 +222,69 @@ func TestChainImportExportMultiTipSetWithMessages(t *testing.T) {
 	validateBlockstoreImport(t, ts3.Key(), gene.Key(), bstore)
 }
 
+func TestChainImportRefusesIncompleteChain(t *testing.T) {
+	tf.UnitTest(t)
+	ctx, gene, cb, _, _, bstore := setupDeps(t)
+	headTS := cb.AppendOn(cb.AppendOn(gene, 1), 1)
+
+	// write a car file with the head block but none of its ancestors
+	var buf bytes.Buffer
+	hdr, err := encoding.Encode(car.CarHeader{
+		Roots:   headTS.Key().ToSlice(),
+		Version: 1,
+	})
+	require.NoError(t, err)
+	require.NoError(t, carutil.LdWrite(&buf, hdr))
+	headBlk := headTS.At(0)
+	require.NoError(t, carutil.LdWrite(&buf, headBlk.Cid().Bytes(), headBlk.ToNode().RawData()))
+
+	_, err = chain.Import(ctx, bstore, &buf)
+	assert.Equal(t, chain.ErrIncompleteChain, errors.Cause(err))
+
+	// nothing was imported
+	has, err := bstore.Has(headBlk.Cid())
+	require.NoError(t, err)
+	assert.False(t, has)
+}
+
+func TestChainImportRefusesMissingRoots(t *testing.T) {
+	tf.UnitTest(t)
+
+	for name, missing := range map[string]func(gene, head block.TipSet) cid.Cid{
+		"receipts":      func(_, head block.TipSet) cid.Cid { return head.At(0).MessageReceipts },
+		"messages":      func(_, head block.TipSet) cid.Cid { return head.At(0).Messages.SecpRoot },
+		"genesis state": func(gene, _ block.TipSet) cid.Cid { return gene.At(0).StateRoot },
+	} {
+		t.Run(name, func(t *testing.T) {
+			ctx, gene, cb, carW, carR, bstore := setupDeps(t)
+			headTS := cb.AppendOn(gene, 1)
+			mustExportToBuffer(ctx, t, headTS, cb, &mockStateReader{}, carW)
+
+			// copy the car file, leaving out the missing block
+			skip := missing(gene, headTS)
+			cr, err := car.NewCarReader(carR)
+			require.NoError(t, err)
+			var buf bytes.Buffer
+			hdr, err := encoding.Encode(cr.Header)
+			require.NoError(t, err)
+			require.NoError(t, carutil.LdWrite(&buf, hdr))
+			for {
+				blk, err := cr.Next()
+				if err == io.EOF {
+					break
+				}
+				require.NoError(t, err)
+				if !blk.Cid().Equals(skip) {
+					require.NoError(t, carutil.LdWrite(&buf, blk.Cid().Bytes(), blk.RawData()))
+				}
+			}
+
+			_, err = chain.Import(ctx, bstore, &buf)
+			assert.Equal(t, chain.ErrIncompleteChain, errors.Cause(err))
+		})
+	}
+}
+
 func mustExportToBuffer(ctx context.Context, t *testing.T, head block.TipSet, cb *chain.Builder, msr *mockStateReader, carW *bufio.Writer) {
 	err := chain.Export(ctx, head, cb, cb, msr, carW)
 	assert.NoError(t, err)
@@ -309,6 +377,18 @@ func requireAMTDecoding(t *testing.T, bstore blockstore.Blockstore, root *amt.Ro
 
 type mockStateReader struct{}
 
+// ChainStateTree returns the genesis state of the chain builder, whose state
+// root is the cid of the bytes "null".
 func (mr *mockStateReader) ChainStateTree(ctx context.Context, c cid.Cid) ([]format.Node, error) {
-	return nil, nil
+	return []format.Node{&mockStateNode{c: c, raw: []byte("null")}}, nil
 }
+
+// mockStateNode is a state tree node that only has a cid and raw data.
+type mockStateNode struct {
+	format.Node
+	c   cid.Cid
+	raw []byte
+}
+
+func (n *mockStateNode) Cid() cid.Cid    { return n.c }
+func (n *mockStateNode) RawData() []byte { return n.raw }
@@ -308,6 +308,13 @@ func (store *Store) HasTipSetAndStatesWithParentsAndHeight(parentKey block.TipSe
 	return store.tipIndex.HasByParentsAndHeight(parentKey, h)
 }
 
+// TipSets returns every tipset tracked by the default store's tipIndex: the
+// ancestors of the head loaded at startup and every tipset validated since,
+// including those on forks that are not ancestors of the current head.
+func (store *Store) TipSets() []block.TipSet {
+	return store.tipIndex.TipSets()
+}
+
 // HeadEvents returns a pubsub interface the pushes events each time the
 // default store's head is reset.
 func (store *Store) HeadEvents() *pubsub.PubSub {
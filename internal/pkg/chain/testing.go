@@ -300,6 +300,11 @@ func (bb *BlockBuilder) IncHeight(nullBlocks types.Uint64) {
 	bb.block.Height += nullBlocks
 }
 
+// SetParentWeight overrides the block's parent weight.
+func (bb *BlockBuilder) SetParentWeight(weight types.Uint64) {
+	bb.block.ParentWeight = weight
+}
+
 // AddMessages adds a message & receipt collection to the block.
 func (bb *BlockBuilder) AddMessages(secpmsgs []*types.SignedMessage, blsMsgs []*types.UnsignedMessage) {
 	ctx := context.Background()
@@ -127,6 +127,17 @@ func (ti *TipIndex) Has(tsKey block.TipSetKey) bool {
 	return ok
 }
 
+// TipSets returns every tipset stored in the TipIndex, in no particular order.
+func (ti *TipIndex) TipSets() []block.TipSet {
+	ti.mu.Lock()
+	defer ti.mu.Unlock()
+	tipsets := make([]block.TipSet, 0, len(ti.tsasByID))
+	for _, tsas := range ti.tsasByID {
+		tipsets = append(tipsets, tsas.TipSet)
+	}
+	return tipsets
+}
+
 // GetByParentsAndHeight returns the all tipsets and states stored in the TipIndex
 // such that the parent ID of these tipsets equals the input.
 func (ti *TipIndex) GetByParentsAndHeight(pKey block.TipSetKey, h uint64) ([]*TipSetMetadata, error) {
@@ -2,6 +2,7 @@ package chain
 
 import (
 	"context"
+	"sort"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/ipfs/go-cid"
@@ -118,3 +119,112 @@ func CollectTipsToCommonAncestor(ctx context.Context, store TipSetProvider, oldH
 	newTips, err = CollectTipSetsOfHeightAtLeast(ctx, newIter, types.NewBlockHeight(commonHeight+uint64(1)))
 	return
 }
+
+// WeightViolation describes a tipset whose recorded parent weight does not
+// exceed the parent weight recorded by its parent, i.e. a point where chain
+// weight fails to strictly increase from parent to child.
+type WeightViolation struct {
+	Height            uint64
+	Key               block.TipSetKey
+	ParentWeight      uint64
+	ParentHeight      uint64
+	GrandparentWeight uint64
+}
+
+// FindWeightViolation walks the tipsets yielded by iter and returns the lowest
+// violation among those with height in [fromHeight, toHeight], or nil if
+// weight strictly increases throughout. The genesis tipset and its children
+// are not checked since genesis has no parent weight to compare against.
+func FindWeightViolation(iter *TipsetIterator, fromHeight, toHeight uint64) (*WeightViolation, error) {
+	var violation *WeightViolation
+	for !iter.Complete() {
+		ts := iter.Value()
+		height, err := ts.Height()
+		if err != nil {
+			return nil, err
+		}
+		if height < fromHeight {
+			break
+		}
+		if err := iter.Next(); err != nil {
+			return nil, err
+		}
+		if height > toHeight {
+			continue
+		}
+		if iter.Complete() {
+			break
+		}
+
+		parent := iter.Value()
+		grandparentKey, err := parent.Parents()
+		if err != nil {
+			return nil, err
+		}
+		if grandparentKey.Len() == 0 {
+			break
+		}
+
+		weight, err := ts.ParentWeight()
+		if err != nil {
+			return nil, err
+		}
+		parentWeight, err := parent.ParentWeight()
+		if err != nil {
+			return nil, err
+		}
+		if weight <= parentWeight {
+			parentHeight, err := parent.Height()
+			if err != nil {
+				return nil, err
+			}
+			violation = &WeightViolation{
+				Height:            height,
+				Key:               ts.Key(),
+				ParentWeight:      weight,
+				ParentHeight:      parentHeight,
+				GrandparentWeight: parentWeight,
+			}
+		}
+	}
+	return violation, nil
+}
+
+// FindOrphans returns the blocks of tipsets that are not in the chain yielded
+// by iter, ordered by height and then CID. A block appears once even if it is
+// in several of tipsets.
+func FindOrphans(iter *TipsetIterator, tipsets []block.TipSet) ([]*block.Block, error) {
+	onChain := make(map[cid.Cid]bool)
+	var err error
+	for ; !iter.Complete(); err = iter.Next() {
+		if err != nil {
+			return nil, err
+		}
+		for i := 0; i < iter.Value().Len(); i++ {
+			onChain[iter.Value().At(i).Cid()] = true
+		}
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	orphans := []*block.Block{}
+	for _, ts := range tipsets {
+		for i := 0; i < ts.Len(); i++ {
+			blk := ts.At(i)
+			if onChain[blk.Cid()] {
+				continue
+			}
+			// Mark it so a block in several orphaned tipsets is listed once.
+			onChain[blk.Cid()] = true
+			orphans = append(orphans, blk)
+		}
+	}
+	sort.Slice(orphans, func(i, j int) bool {
+		if orphans[i].Height != orphans[j].Height {
+			return orphans[i].Height < orphans[j].Height
+		}
+		return orphans[i].Cid().String() < orphans[j].Cid().String()
+	})
+	return orphans, nil
+}
@@ -8,9 +8,11 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
 	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
@@ -74,3 +76,83 @@ func TestIterAncestors(t *testing.T) {
 		assert.Error(t, it.Next())
 	})
 }
+
+func TestFindWeightViolation(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	miner, err := address.NewSecp256k1Address([]byte(fmt.Sprintf("address")))
+	require.NoError(t, err)
+
+	t.Run("weight increasing throughout passes", func(t *testing.T) {
+		builder := chain.NewBuilder(t, miner)
+		head := builder.AppendManyOn(5, builder.NewGenesis())
+
+		violation, err := chain.FindWeightViolation(chain.IterAncestors(ctx, builder, head), 0, 5)
+		require.NoError(t, err)
+		assert.Nil(t, violation)
+	})
+
+	t.Run("detects an understated weight at its height", func(t *testing.T) {
+		builder := chain.NewBuilder(t, miner)
+		parent := builder.AppendManyOn(2, builder.NewGenesis())
+		parentWeight, err := parent.ParentWeight()
+		require.NoError(t, err)
+
+		bad := builder.BuildOneOn(parent, func(b *chain.BlockBuilder) {
+			b.SetParentWeight(types.Uint64(parentWeight))
+		})
+		head := builder.AppendManyOn(2, bad)
+
+		violation, err := chain.FindWeightViolation(chain.IterAncestors(ctx, builder, head), 0, 5)
+		require.NoError(t, err)
+		require.NotNil(t, violation)
+		assert.Equal(t, uint64(3), violation.Height)
+		assert.Equal(t, bad.Key(), violation.Key)
+		assert.Equal(t, uint64(2), violation.ParentHeight)
+		assert.Equal(t, parentWeight, violation.ParentWeight)
+		assert.Equal(t, parentWeight, violation.GrandparentWeight)
+
+		// Ranges that exclude the bad tipset pass.
+		violation, err = chain.FindWeightViolation(chain.IterAncestors(ctx, builder, head), 4, 5)
+		require.NoError(t, err)
+		assert.Nil(t, violation)
+		violation, err = chain.FindWeightViolation(chain.IterAncestors(ctx, builder, head), 0, 2)
+		require.NoError(t, err)
+		assert.Nil(t, violation)
+	})
+}
+
+func TestFindOrphans(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder := chain.NewBuilder(t, address.Undef)
+
+	genesis := builder.NewGenesis()
+	link1 := builder.AppendOn(genesis, 2)
+	head := builder.AppendManyOn(2, link1)
+
+	// A fork off link1 that the head does not descend from.
+	fork1 := builder.AppendOn(link1, 2)
+	fork2 := builder.AppendOn(fork1, 1)
+	// A tipset of some of link1's blocks orphans nothing.
+	partial := th.RequireNewTipSet(t, link1.At(0))
+
+	tipsets := []block.TipSet{genesis, link1, head, fork1, fork2, partial, fork2}
+	orphans, err := chain.FindOrphans(chain.IterAncestors(ctx, builder, head), tipsets)
+	require.NoError(t, err)
+
+	require.Len(t, orphans, 3)
+	assert.Equal(t, types.Uint64(2), orphans[0].Height)
+	assert.Equal(t, types.Uint64(2), orphans[1].Height)
+	assert.True(t, orphans[0].Cid().String() < orphans[1].Cid().String())
+	assert.Equal(t, fork2.At(0).Cid(), orphans[2].Cid())
+
+	forkCids := map[string]bool{fork1.At(0).Cid().String(): true, fork1.At(1).Cid().String(): true}
+	assert.True(t, forkCids[orphans[0].Cid().String()])
+	assert.True(t, forkCids[orphans[1].Cid().String()])
+
+	// Nothing is orphaned when the head is the tip of the fork.
+	orphans, err = chain.FindOrphans(chain.IterAncestors(ctx, builder, fork2), []block.TipSet{genesis, link1, fork1, fork2})
+	require.NoError(t, err)
+	assert.Empty(t, orphans)
+}
@@ -9,6 +9,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync/internal/syncer"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chainsync/status"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/clock"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
 )
 
 // BlockProposer allows callers to propose new blocks for inclusion in the chain.
@@ -53,3 +54,8 @@ func (m *Manager) BlockProposer() BlockProposer {
 func (m *Manager) Status() status.Status {
 	return m.syncer.Status()
 }
+
+// Equivocations returns the equivocating miners detected while syncing.
+func (m *Manager) Equivocations() []consensus.Equivocation {
+	return m.syncer.Equivocations()
+}
@@ -44,6 +44,8 @@ type Syncer struct {
 	fetcher Fetcher
 	// BadTipSetCache is used to filter out collections of invalid blocks.
 	badTipSets *BadTipSetCache
+	// Records miners that produce more than one block at a height.
+	equivocations *consensus.EquivocationDetector
 
 	// Evaluates tipset messages and stores the resulting states.
 	fullValidator FullBlockValidator
@@ -146,6 +148,7 @@ func NewSyncer(fv FullBlockValidator, hv HeaderValidator, cs ChainSelector, s Ch
 		badTipSets: &BadTipSetCache{
 			bad: make(map[string]struct{}),
 		},
+		equivocations:   consensus.NewEquivocationDetector(consensus.EquivocationFinality),
 		fullValidator:   fv,
 		headerValidator: hv,
 		chainSelector:   cs,
@@ -198,6 +201,9 @@ func (syncer *Syncer) fetchAndValidateHeaders(ctx context.Context, ci *block.Cha
 			if err != nil {
 				return nil, err
 			}
+			if blk := ts.At(i); syncer.equivocations.Observe(blk) {
+				logSyncer.Warnf("miner %s produced more than one block at height %d, latest %s", blk.Miner, blk.Height, blk.Cid())
+			}
 		}
 		parent = headers[i]
 	}
@@ -574,6 +580,12 @@ func (syncer *Syncer) stageIfHeaviest(ctx context.Context, candidate block.TipSe
 	return nil
 }
 
+// Equivocations returns the consensus faults of miners producing more than
+// one block at a height among the headers the syncer has validated.
+func (syncer *Syncer) Equivocations() []consensus.Equivocation {
+	return syncer.equivocations.Faults()
+}
+
 // Status returns the current syncer status.
 func (syncer *Syncer) Status() status.Status {
 	return syncer.reporter.Status()
@@ -493,6 +493,29 @@ func TestSyncerStatus(t *testing.T) {
 	assert.Equal(t, true, s2.SyncingComplete)
 }
 
+func TestDetectsEquivocation(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	builder, store, syncer := setup(ctx, t)
+	genesis := builder.RequireTipSet(store.GetHead())
+
+	// A chain with one block per height has no faults.
+	t1 := builder.AppendOn(genesis, 1)
+	t2 := builder.AppendOn(t1, 1)
+	require.NoError(t, syncer.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", t2.Key(), heightFromTip(t, t2)), false))
+	assert.Empty(t, syncer.Equivocations())
+
+	// The builder's single miner produces a second block at height 1.
+	fork := builder.AppendOn(genesis, 1)
+	require.NoError(t, syncer.HandleNewTipSet(ctx, block.NewChainInfo(peer.ID(""), "", fork.Key(), heightFromTip(t, fork)), false))
+
+	faults := syncer.Equivocations()
+	require.Len(t, faults, 1)
+	assert.Equal(t, t1.At(0).Miner, faults[0].Miner)
+	assert.Equal(t, uint64(1), faults[0].Height)
+	assert.Equal(t, []cid.Cid{t1.At(0).Cid(), fork.At(0).Cid()}, faults[0].Blocks)
+}
+
 func TestStoresMessageReceipts(t *testing.T) {
 	tf.UnitTest(t)
 	ctx := context.Background()
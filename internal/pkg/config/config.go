@@ -7,8 +7,11 @@ import (
 	"os"
 	"reflect"
 	"regexp"
+	"sort"
 	"strings"
+	"time"
 
+	ma "github.com/multiformats/go-multiaddr"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -18,6 +21,7 @@ import (
 // Config is an in memory representation of the filecoin configuration file
 type Config struct {
 	API           *APIConfig           `json:"api"`
+	Blocks        *BlocksConfig        `json:"blocks"`
 	Bootstrap     *BootstrapConfig     `json:"bootstrap"`
 	Datastore     *DatastoreConfig     `json:"datastore"`
 	Heartbeat     *HeartbeatConfig     `json:"heartbeat"`
@@ -50,6 +54,20 @@ func newDefaultAPIConfig() *APIConfig {
 	}
 }
 
+// BlocksConfig holds all configuration options related to block contents.
+type BlocksConfig struct {
+	// MaxMessages is the maximum number of messages this node includes from
+	// the message pool in a block it mines. It may not exceed the protocol's
+	// types.BlockMessageLimit, which is what blocks are validated against.
+	MaxMessages uint `json:"max_messages"`
+}
+
+func newDefaultBlocksConfig() *BlocksConfig {
+	return &BlocksConfig{
+		MaxMessages: types.BlockMessageLimit,
+	}
+}
+
 // DatastoreConfig holds all the configuration options for the datastore.
 // TODO: use the advanced datastore configuration from ipfs
 type DatastoreConfig struct {
@@ -106,6 +124,12 @@ type MiningConfig struct {
 	MinerAddress            address.Address `json:"minerAddress"`
 	AutoSealIntervalSeconds uint            `json:"autoSealIntervalSeconds"`
 	StoragePrice            types.AttoFIL   `json:"storagePrice"`
+	// MinGasPrice is the lowest gas price of a message mining includes in a
+	// block. Messages priced below it stay in the message pool.
+	MinGasPrice types.AttoFIL `json:"min_gas_price"`
+	// MinPeers is the fewest peers the node must be connected to for mining
+	// to run. Mining pauses while the node has fewer.
+	MinPeers uint `json:"min_peers"`
 }
 
 func newDefaultMiningConfig() *MiningConfig {
@@ -113,12 +137,17 @@ func newDefaultMiningConfig() *MiningConfig {
 		MinerAddress:            address.Undef,
 		AutoSealIntervalSeconds: 120,
 		StoragePrice:            types.ZeroAttoFIL,
+		MinGasPrice:             types.ZeroAttoFIL,
+		MinPeers:                0,
 	}
 }
 
 // WalletConfig holds all configuration options related to the wallet.
 type WalletConfig struct {
 	DefaultAddress address.Address `json:"defaultAddress,omitempty"`
+	// Encrypted is true if the keys in the wallet datastore are encrypted,
+	// in which case the daemon starts with the wallet locked.
+	Encrypted bool `json:"encrypted,omitempty"`
 }
 
 func newDefaultWalletConfig() *WalletConfig {
@@ -234,6 +263,7 @@ func newDefaultSectorbaseConfig() *SectorBaseConfig {
 func NewDefaultConfig() *Config {
 	return &Config{
 		API:           newDefaultAPIConfig(),
+		Blocks:        newDefaultBlocksConfig(),
 		Bootstrap:     newDefaultBootstrapConfig(),
 		Datastore:     newDefaultDatastoreConfig(),
 		Swarm:         newDefaultSwarmConfig(),
@@ -284,17 +314,130 @@ func ReadFile(file string) (*Config, error) {
 		return nil, err
 	}
 
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
 	return cfg, nil
 }
 
+// FieldError is the error Validate returns for a config field with an
+// unusable value.
+type FieldError struct {
+	// Key is the dotted key of the field, e.g. 'swarm.address'.
+	Key    string
+	Value  string
+	Reason string
+}
+
+func (e *FieldError) Error() string {
+	return fmt.Sprintf("invalid value %q for config field %s: %s", e.Value, e.Key, e.Reason)
+}
+
+// Validate checks that the config values are usable: that addresses are
+// multiaddrs, with ports in range, and that durations are positive. It
+// returns a *FieldError for the first invalid field.
+func (cfg *Config) Validate() error {
+	if cfg.API != nil {
+		if err := validateMultiaddr("api.address", cfg.API.Address); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Swarm != nil {
+		if err := validateMultiaddr("swarm.address", cfg.Swarm.Address); err != nil {
+			return err
+		}
+		if cfg.Swarm.PublicRelayAddress != "" {
+			if err := validateMultiaddr("swarm.public_relay_address", cfg.Swarm.PublicRelayAddress); err != nil {
+				return err
+			}
+		}
+	}
+
+	if cfg.Blocks != nil && cfg.Blocks.MaxMessages > types.BlockMessageLimit {
+		return &FieldError{Key: "blocks.max_messages", Value: fmt.Sprint(cfg.Blocks.MaxMessages), Reason: fmt.Sprintf("must not exceed %d", types.BlockMessageLimit)}
+	}
+
+	if cfg.Bootstrap != nil {
+		for _, addr := range cfg.Bootstrap.Addresses {
+			if err := validateMultiaddr("bootstrap.addresses", addr); err != nil {
+				return err
+			}
+		}
+		if cfg.Bootstrap.MinPeerThreshold < 0 {
+			return &FieldError{Key: "bootstrap.minPeerThreshold", Value: fmt.Sprint(cfg.Bootstrap.MinPeerThreshold), Reason: "must not be negative"}
+		}
+		if err := validateDuration("bootstrap.period", cfg.Bootstrap.Period); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Heartbeat != nil {
+		if cfg.Heartbeat.BeatTarget != "" {
+			if err := validateMultiaddr("heartbeat.beatTarget", cfg.Heartbeat.BeatTarget); err != nil {
+				return err
+			}
+		}
+		if err := validateDuration("heartbeat.beatPeriod", cfg.Heartbeat.BeatPeriod); err != nil {
+			return err
+		}
+		if err := validateDuration("heartbeat.reconnectPeriod", cfg.Heartbeat.ReconnectPeriod); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Observability != nil && cfg.Observability.Metrics != nil {
+		if err := validateDuration("observability.metrics.reportInterval", cfg.Observability.Metrics.ReportInterval); err != nil {
+			return err
+		}
+		if err := validateMultiaddr("observability.metrics.prometheusEndpoint", cfg.Observability.Metrics.PrometheusEndpoint); err != nil {
+			return err
+		}
+	}
+
+	if cfg.Observability != nil && cfg.Observability.Tracing != nil {
+		sampler := cfg.Observability.Tracing.ProbabilitySampler
+		if sampler < 0 || sampler > 1 {
+			return &FieldError{Key: "observability.tracing.probabilitySampler", Value: fmt.Sprint(sampler), Reason: "must be between 0 and 1"}
+		}
+	}
+
+	return nil
+}
+
+func validateMultiaddr(key, value string) error {
+	if _, err := ma.NewMultiaddr(value); err != nil {
+		return &FieldError{Key: key, Value: value, Reason: err.Error()}
+	}
+	return nil
+}
+
+func validateDuration(key, value string) error {
+	d, err := time.ParseDuration(value)
+	if err != nil {
+		return &FieldError{Key: key, Value: value, Reason: err.Error()}
+	}
+	if d <= 0 {
+		return &FieldError{Key: key, Value: value, Reason: "duration must be positive"}
+	}
+	return nil
+}
+
 // Set sets the config sub-struct referenced by `key`, e.g. 'api.address'
-// or 'datastore' to the json key value pair encoded in jsonVal.
+// or 'datastore' to the json key value pair encoded in jsonVal. Keys that
+// are not in the config and values of the wrong type are rejected, leaving
+// the config unchanged.
 func (cfg *Config) Set(dottedKey string, jsonString string) error {
 	if !json.Valid([]byte(jsonString)) {
 		jsonBytes, _ := json.Marshal(jsonString)
 		jsonString = string(jsonBytes)
 	}
 
+	// json decoding matches keys case insensitively, Get does not.
+	if _, err := cfg.Get(dottedKey); err != nil {
+		return err
+	}
+
 	if err := validate(dottedKey, jsonString); err != nil {
 		return err
 	}
@@ -304,10 +447,27 @@ func (cfg *Config) Set(dottedKey string, jsonString string) error {
 		jsonString = fmt.Sprintf(`{ "%s": %s }`, keys[i], jsonString)
 	}
 
+	// decode into a copy, as decoding stops part way through on errors
+	cfgJSON, err := json.Marshal(cfg)
+	if err != nil {
+		return err
+	}
+	updated := &Config{}
+	if err := json.Unmarshal(cfgJSON, updated); err != nil {
+		return err
+	}
+
 	decoder := json.NewDecoder(strings.NewReader(jsonString))
 	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(updated); err != nil {
+		return err
+	}
+	if err := updated.Validate(); err != nil {
+		return err
+	}
 
-	return decoder.Decode(&cfg)
+	*cfg = *updated
+	return nil
 }
 
 // Get gets the config sub-struct referenced by `key`, e.g. 'api.address'
@@ -338,6 +498,78 @@ OUTER:
 	return nil, fmt.Errorf("empty key is invalid")
 }
 
+// FieldDiff is a single config field whose value differs between two configs.
+// Values are in their JSON form, as they appear in the config file.
+type FieldDiff struct {
+	Key   string      `json:"key"`
+	Value interface{} `json:"value"`
+	Other interface{} `json:"other"`
+}
+
+// Diff compares cfg with other field by field and returns the differing
+// fields, sorted by their dotted key, e.g. 'api.address'. Fields absent from
+// one of the configs are reported with a nil value on that side.
+func (cfg *Config) Diff(other *Config) ([]FieldDiff, error) {
+	fields, err := flatten(cfg)
+	if err != nil {
+		return nil, err
+	}
+	otherFields, err := flatten(other)
+	if err != nil {
+		return nil, err
+	}
+
+	keys := make(map[string]struct{})
+	for k := range fields {
+		keys[k] = struct{}{}
+	}
+	for k := range otherFields {
+		keys[k] = struct{}{}
+	}
+
+	var diffs []FieldDiff
+	for k := range keys {
+		if !reflect.DeepEqual(fields[k], otherFields[k]) {
+			diffs = append(diffs, FieldDiff{Key: k, Value: fields[k], Other: otherFields[k]})
+		}
+	}
+	sort.Slice(diffs, func(i, j int) bool { return diffs[i].Key < diffs[j].Key })
+
+	return diffs, nil
+}
+
+// flatten maps the dotted key of every leaf field of cfg to its JSON value.
+// Arrays are treated as leaves.
+func flatten(cfg *Config) (map[string]interface{}, error) {
+	raw, err := json.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+	var tree map[string]interface{}
+	if err := json.Unmarshal(raw, &tree); err != nil {
+		return nil, err
+	}
+
+	fields := make(map[string]interface{})
+	var walk func(prefix string, node map[string]interface{})
+	walk = func(prefix string, node map[string]interface{}) {
+		for k, v := range node {
+			key := k
+			if prefix != "" {
+				key = prefix + "." + k
+			}
+			if sub, ok := v.(map[string]interface{}); ok {
+				walk(key, sub)
+				continue
+			}
+			fields[key] = v
+		}
+	}
+	walk("", tree)
+
+	return fields, nil
+}
+
 // validate runs validations on a given key and json string. validate uses the
 // validators map defined at the top of this file to determine which validations
 // to use for each key.
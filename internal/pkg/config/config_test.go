@@ -11,6 +11,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
@@ -57,6 +58,9 @@ func TestWriteFile(t *testing.T) {
 			"PUT"
 		]
 	},
+	"blocks": {
+		"max_messages": 1000
+	},
 	"bootstrap": {
 		"addresses": [],
 		"minPeerThreshold": 0,
@@ -75,7 +79,9 @@ func TestWriteFile(t *testing.T) {
 	"mining": {
 		"minerAddress": "empty",
 		"autoSealIntervalSeconds": 120,
-		"storagePrice": "0"
+		"storagePrice": "0",
+		"min_gas_price": "0",
+		"min_peers": 0
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
@@ -340,6 +346,24 @@ path = "mushroom-mushroom"}`
 		assert.Contains(t, err.Error(), address.ErrUnknownNetwork.Error())
 	})
 
+	t.Run("invalid set leaves the config unchanged", func(t *testing.T) {
+		cfg := NewDefaultConfig()
+
+		err := cfg.Set("bootstrap", `{"period": "3m", "minPeerThreshold": "not a number"}`)
+		assert.Error(t, err)
+		err = cfg.Set("bootstrap", `{"period": "3m", "nope": 1}`)
+		assert.Error(t, err)
+		assert.Equal(t, NewDefaultConfig(), cfg)
+	})
+
+	t.Run("keys must match exactly", func(t *testing.T) {
+		cfg := NewDefaultConfig()
+
+		err := cfg.Set("Bootstrap.Period", `"3m"`)
+		assert.Error(t, err)
+		assert.Equal(t, NewDefaultConfig(), cfg)
+	})
+
 	t.Run("setting leaves does not interfere with neighboring leaves", func(t *testing.T) {
 		cfg := NewDefaultConfig()
 
@@ -351,6 +375,58 @@ path = "mushroom-mushroom"}`
 	})
 }
 
+func TestConfigValidate(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("the default config is valid", func(t *testing.T) {
+		assert.NoError(t, NewDefaultConfig().Validate())
+	})
+
+	t.Run("read file rejects a bad swarm address", func(t *testing.T) {
+		cfgpath, cleaner, err := createConfigFile(`{"swarm": {"address": "/ip4/0.0.0.0/tcp/notaport"}}`)
+		require.NoError(t, err)
+		defer func() {
+			require.NoError(t, cleaner())
+		}()
+
+		_, err = ReadFile(cfgpath)
+		require.Error(t, err)
+		fieldErr, ok := err.(*FieldError)
+		require.True(t, ok)
+		assert.Equal(t, "swarm.address", fieldErr.Key)
+		assert.Contains(t, err.Error(), "swarm")
+		assert.Contains(t, err.Error(), "/ip4/0.0.0.0/tcp/notaport")
+	})
+
+	t.Run("rejects invalid fields", func(t *testing.T) {
+		for key, set := range map[string]func(cfg *Config){
+			"api.address":                              func(cfg *Config) { cfg.API.Address = "localhost:3453" },
+			"blocks.max_messages":                      func(cfg *Config) { cfg.Blocks.MaxMessages = types.BlockMessageLimit + 1 },
+			"swarm.address":                            func(cfg *Config) { cfg.Swarm.Address = "/ip4/0.0.0.0/tcp/70000" },
+			"bootstrap.addresses":                      func(cfg *Config) { cfg.Bootstrap.Addresses = []string{"fake"} },
+			"bootstrap.minPeerThreshold":               func(cfg *Config) { cfg.Bootstrap.MinPeerThreshold = -1 },
+			"bootstrap.period":                         func(cfg *Config) { cfg.Bootstrap.Period = "-1m" },
+			"heartbeat.beatPeriod":                     func(cfg *Config) { cfg.Heartbeat.BeatPeriod = "often" },
+			"observability.metrics.reportInterval":     func(cfg *Config) { cfg.Observability.Metrics.ReportInterval = "0s" },
+			"observability.tracing.probabilitySampler": func(cfg *Config) { cfg.Observability.Tracing.ProbabilitySampler = 2 },
+		} {
+			cfg := NewDefaultConfig()
+			set(cfg)
+			err := cfg.Validate()
+			require.Error(t, err, key)
+			assert.Equal(t, key, err.(*FieldError).Key)
+		}
+	})
+
+	t.Run("set rejects invalid values", func(t *testing.T) {
+		cfg := NewDefaultConfig()
+
+		err := cfg.Set("swarm.address", "not a multiaddr")
+		assert.Error(t, err)
+		assert.Equal(t, NewDefaultConfig(), cfg)
+	})
+}
+
 func createConfigFile(content string) (string, func() error, error) {
 	dir, err := ioutil.TempDir("", "config")
 	if err != nil {
@@ -366,3 +442,27 @@ func createConfigFile(content string) (string, func() error, error) {
 		return os.RemoveAll(dir)
 	}, nil
 }
+
+func TestConfigDiff(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("identical configs have no differences", func(t *testing.T) {
+		diffs, err := NewDefaultConfig().Diff(NewDefaultConfig())
+		require.NoError(t, err)
+		assert.Empty(t, diffs)
+	})
+
+	t.Run("reports each differing field by dotted key", func(t *testing.T) {
+		cfg := NewDefaultConfig()
+		other := NewDefaultConfig()
+		require.NoError(t, other.Set("api.address", "/ip4/127.0.0.1/tcp/9999"))
+		require.NoError(t, other.Set("heartbeat.nickname", "Bob"))
+
+		diffs, err := cfg.Diff(other)
+		require.NoError(t, err)
+		require.Len(t, diffs, 2)
+
+		assert.Equal(t, FieldDiff{Key: "api.address", Value: cfg.API.Address, Other: "/ip4/127.0.0.1/tcp/9999"}, diffs[0])
+		assert.Equal(t, FieldDiff{Key: "heartbeat.nickname", Value: cfg.Heartbeat.Nickname, Other: "Bob"}, diffs[1])
+	})
+}
@@ -135,6 +135,29 @@ func TestIsElectionWinner(t *testing.T) {
 	})
 }
 
+func TestMockWinnerElectionValidator(t *testing.T) {
+	tf.UnitTest(t)
+
+	newAddr := address.NewForTestGetter()
+	winner := newAddr()
+	other := newAddr()
+	validator := consensus.MockWinnerElectionValidator{
+		ElectionValidator: &consensus.FailingElectionValidator{},
+		Winner:            winner,
+	}
+	ptv := consensus.NewFakePowerTableView(types.NewBytesAmount(0), types.NewBytesAmount(42), nil)
+	ticket := consensus.MakeFakeTicketForTest()
+	proof := consensus.MakeFakeElectionProofForTest()
+
+	wins, err := validator.IsElectionWinner(context.Background(), ptv, ticket, 0, proof, winner, winner)
+	require.NoError(t, err)
+	assert.True(t, wins)
+
+	wins, err = validator.IsElectionWinner(context.Background(), ptv, ticket, 0, proof, other, other)
+	require.NoError(t, err)
+	assert.False(t, wins)
+}
+
 func TestRunElection(t *testing.T) {
 	signer, kis := types.NewMockSignersAndKeyInfo(1)
 	electionAddr := requireAddress(t, &kis[0])
@@ -0,0 +1,124 @@
+package consensus
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// Equivocation is the consensus fault of a miner producing more than one
+// block at the same height.
+type Equivocation struct {
+	Miner  address.Address
+	Height uint64
+	// Blocks are the conflicting blocks in the order they were seen.
+	Blocks []cid.Cid
+}
+
+// EquivocationFinality is the number of rounds below the highest block it has
+// seen for which an EquivocationDetector remembers blocks. Conflicts deeper
+// than that are no longer detected.
+const EquivocationFinality = 900
+
+type minerAtHeight struct {
+	miner  address.Address
+	height uint64
+}
+
+// EquivocationDetector remembers which blocks each miner produced at each
+// height and reports miners that produced more than one. It forgets blocks,
+// and the faults among them, more than finality rounds below the highest
+// block it has seen, so its memory stays bounded. Like the syncer's bad tipset
+// cache it is only in-memory, so detected faults are forgotten when the node
+// restarts.
+type EquivocationDetector struct {
+	mu       sync.Mutex
+	seen     map[minerAtHeight][]cid.Cid
+	finality uint64
+	highest  uint64
+}
+
+// NewEquivocationDetector returns an EquivocationDetector that has seen no
+// blocks and remembers blocks for finality rounds.
+func NewEquivocationDetector(finality uint64) *EquivocationDetector {
+	return &EquivocationDetector{
+		seen:     make(map[minerAtHeight][]cid.Cid),
+		finality: finality,
+	}
+}
+
+// Observe records blk and returns true if it is the first block conflicting
+// with one already seen from the same miner at the same height. Seeing the
+// same block again is not a fault, and neither is a block below the
+// finality height, which is not recorded.
+func (d *EquivocationDetector) Observe(blk *block.Block) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	height := uint64(blk.Height)
+	if height > d.highest {
+		d.highest = height
+		d.prune()
+	}
+	if height < d.finalityHeight() {
+		return false
+	}
+
+	key := minerAtHeight{miner: blk.Miner, height: height}
+	blkCid := blk.Cid()
+	for _, c := range d.seen[key] {
+		if c.Equals(blkCid) {
+			return false
+		}
+	}
+	d.seen[key] = append(d.seen[key], blkCid)
+	return len(d.seen[key]) == 2
+}
+
+// finalityHeight returns the lowest height whose blocks are remembered.
+func (d *EquivocationDetector) finalityHeight() uint64 {
+	if d.highest < d.finality {
+		return 0
+	}
+	return d.highest - d.finality
+}
+
+// prune forgets the blocks below the finality height.
+func (d *EquivocationDetector) prune() {
+	finalityHeight := d.finalityHeight()
+	for key := range d.seen {
+		if key.height < finalityHeight {
+			delete(d.seen, key)
+		}
+	}
+}
+
+// Faults returns every equivocation seen so far ordered by height and then
+// miner address.
+func (d *EquivocationDetector) Faults() []Equivocation {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	var faults []Equivocation
+	for key, blocks := range d.seen {
+		if len(blocks) < 2 {
+			continue
+		}
+		faults = append(faults, Equivocation{
+			Miner:  key.miner,
+			Height: key.height,
+			Blocks: append([]cid.Cid{}, blocks...),
+		})
+	}
+	sort.Slice(faults, func(i, j int) bool {
+		if faults[i].Height != faults[j].Height {
+			return faults[i].Height < faults[j].Height
+		}
+		return faults[i].Miner.String() < faults[j].Miner.String()
+	})
+	return faults
+}
@@ -0,0 +1,90 @@
+package consensus_test
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+func TestEquivocationDetector(t *testing.T) {
+	tf.UnitTest(t)
+
+	addrGetter := address.NewForTestGetter()
+	minerA, minerB := addrGetter(), addrGetter()
+
+	blk := func(miner address.Address, height, timestamp uint64) *block.Block {
+		return &block.Block{Miner: miner, Height: types.Uint64(height), Timestamp: types.Uint64(timestamp)}
+	}
+
+	t.Run("distinct heights and miners are not faults", func(t *testing.T) {
+		d := consensus.NewEquivocationDetector(consensus.EquivocationFinality)
+		assert.False(t, d.Observe(blk(minerA, 1, 1)))
+		assert.False(t, d.Observe(blk(minerA, 2, 2)))
+		assert.False(t, d.Observe(blk(minerB, 1, 1)))
+		assert.Empty(t, d.Faults())
+	})
+
+	t.Run("seeing a block twice is not a fault", func(t *testing.T) {
+		d := consensus.NewEquivocationDetector(consensus.EquivocationFinality)
+		assert.False(t, d.Observe(blk(minerA, 1, 1)))
+		assert.False(t, d.Observe(blk(minerA, 1, 1)))
+		assert.Empty(t, d.Faults())
+	})
+
+	t.Run("two blocks from one miner at one height", func(t *testing.T) {
+		d := consensus.NewEquivocationDetector(consensus.EquivocationFinality)
+		first, second, third := blk(minerA, 3, 1), blk(minerA, 3, 2), blk(minerA, 3, 3)
+		assert.False(t, d.Observe(first))
+		assert.True(t, d.Observe(second))
+		// Only the first conflict is reported as new.
+		assert.False(t, d.Observe(third))
+		assert.False(t, d.Observe(blk(minerB, 3, 1)))
+
+		faults := d.Faults()
+		require.Len(t, faults, 1)
+		assert.Equal(t, minerA, faults[0].Miner)
+		assert.Equal(t, uint64(3), faults[0].Height)
+		assert.Equal(t, []cid.Cid{first.Cid(), second.Cid(), third.Cid()}, faults[0].Blocks)
+	})
+
+	t.Run("faults are ordered by height", func(t *testing.T) {
+		d := consensus.NewEquivocationDetector(consensus.EquivocationFinality)
+		d.Observe(blk(minerB, 5, 1))
+		d.Observe(blk(minerB, 5, 2))
+		d.Observe(blk(minerA, 2, 1))
+		d.Observe(blk(minerA, 2, 2))
+
+		faults := d.Faults()
+		require.Len(t, faults, 2)
+		assert.Equal(t, uint64(2), faults[0].Height)
+		assert.Equal(t, uint64(5), faults[1].Height)
+	})
+
+	t.Run("blocks below the finality height are forgotten", func(t *testing.T) {
+		d := consensus.NewEquivocationDetector(10)
+		d.Observe(blk(minerA, 2, 1))
+		d.Observe(blk(minerA, 2, 2))
+		d.Observe(blk(minerB, 5, 1))
+		require.Len(t, d.Faults(), 1)
+
+		// Height 13 moves the finality height to 3, past the fault at 2.
+		d.Observe(blk(minerA, 13, 1))
+		assert.Empty(t, d.Faults())
+		// Blocks below the finality height are neither faults nor recorded.
+		assert.False(t, d.Observe(blk(minerA, 2, 3)))
+		assert.Empty(t, d.Faults())
+
+		// Blocks at and above it still are.
+		assert.True(t, d.Observe(blk(minerB, 5, 2)))
+		require.Len(t, d.Faults(), 1)
+		assert.Equal(t, uint64(5), d.Faults()[0].Height)
+	})
+}
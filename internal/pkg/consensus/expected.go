@@ -48,6 +48,8 @@ var (
 	ErrUnorderedTipSets = errors.New("trying to order two identical tipsets")
 	// ErrReceiptRootMismatch is returned when the block's receipt root doesn't match the receipt root computed for the parent tipset.
 	ErrReceiptRootMismatch = errors.New("blocks receipt root does not match parent tip set")
+	// ErrTooManyMessages is returned when a block carries more messages than types.BlockMessageLimit.
+	ErrTooManyMessages = errors.New("block carries too many messages")
 )
 
 // DefaultBlockTime is the estimated proving period time.
@@ -143,6 +145,10 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts block.TipSet, blsM
 	span.AddAttributes(trace.StringAttribute("tipset", ts.String()))
 	defer tracing.AddErrorEndSpan(ctx, span, &err)
 
+	if err := c.validateMessageCounts(ts, blsMessages, secpMessages); err != nil {
+		return cid.Undef, []*types.MessageReceipt{}, err
+	}
+
 	priorState, err := c.loadStateTree(ctx, parentStateRoot)
 	if err != nil {
 		return cid.Undef, []*types.MessageReceipt{}, err
@@ -170,6 +176,18 @@ func (c *Expected) RunStateTransition(ctx context.Context, ts block.TipSet, blsM
 	return root, receipts, err
 }
 
+// validateMessageCounts returns ErrTooManyMessages if any block in the tipset
+// carries more BLS and secp messages combined than types.BlockMessageLimit.
+func (c *Expected) validateMessageCounts(ts block.TipSet, blsMessages [][]*types.UnsignedMessage, secpMessages [][]*types.SignedMessage) error {
+	for i := 0; i < ts.Len(); i++ {
+		count := len(blsMessages[i]) + len(secpMessages[i])
+		if count > types.BlockMessageLimit {
+			return errors.Wrapf(ErrTooManyMessages, "block %s has %d messages, limit is %d", ts.At(i).Cid(), count, types.BlockMessageLimit)
+		}
+	}
+	return nil
+}
+
 // validateMining checks validity of the ticket, proof, signature and miner
 // address of every block in the tipset.
 //    Returns an error if any block:
@@ -167,6 +167,26 @@ func TestExpected_RunStateTransition_validateMining(t *testing.T) {
 		assert.Contains(t, err.Error(), "secp message signature invalid")
 	})
 
+	t.Run("fails when a block carries more messages than the limit", func(t *testing.T) {
+		as := testActorState(t, kis)
+		exp := consensus.NewExpected(cistore, bstore, th.NewFakeProcessor(), as, th.BlockTimeTest, &consensus.FakeElectionMachine{}, &consensus.FakeTicketMachine{})
+
+		pTipSet := th.RequireNewTipSet(t, genesisBlock)
+		nextBlocks := requireMakeNBlocks(t, 3, pTipSet, genesisBlock.StateRoot, types.EmptyReceiptsCID, kis, mockSigner)
+		tipSet := th.RequireNewTipSet(t, nextBlocks...)
+
+		blsMessages, secpMessages := emptyMessages(len(nextBlocks))
+		msg := types.NewUnsignedMessage(address.TestAddress, address.TestAddress2, 0, types.NewAttoFILFromFIL(0), types.InvalidMethodID, []byte{})
+		for i := 0; i < types.BlockMessageLimit; i++ {
+			blsMessages[0] = append(blsMessages[0], msg)
+		}
+		secpMessages[0] = append(secpMessages[0], &types.SignedMessage{Message: *msg})
+
+		_, _, err = exp.RunStateTransition(ctx, tipSet, blsMessages, secpMessages, []block.TipSet{pTipSet}, uint64(nextBlocks[0].ParentWeight), nextBlocks[0].StateRoot, nextBlocks[0].MessageReceipts)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), consensus.ErrTooManyMessages.Error())
+	})
+
 	t.Run("returns nil + mining error when ticket validation fails", func(t *testing.T) {
 		as := testActorState(t, kis)
 		exp := consensus.NewExpected(cistore, bstore, th.NewFakeProcessor(), as, th.BlockTimeTest, &consensus.FakeElectionMachine{}, &consensus.FailingTicketValidator{})
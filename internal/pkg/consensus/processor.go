@@ -46,6 +46,9 @@ type BlockRewarder interface {
 
 	// GasReward pays gas from the sender to the miner
 	GasReward(ctx context.Context, st state.Tree, minerOwnerAddr address.Address, msg *types.UnsignedMessage, cost types.AttoFIL) error
+
+	// BlockRewardAt returns the reward BlockReward pays for a block at height h
+	BlockRewardAt(h uint64) types.AttoFIL
 }
 
 // ApplicationResult contains the result of successfully applying one message.
@@ -81,6 +84,12 @@ func NewDefaultProcessor() *DefaultProcessor {
 	}
 }
 
+// BlockRewardAt returns the block reward the processor's rewarder pays to the
+// miner of a block at height h.
+func (p *DefaultProcessor) BlockRewardAt(h uint64) types.AttoFIL {
+	return p.blockRewarder.BlockRewardAt(h)
+}
+
 // NewConfiguredProcessor creates a default processor with custom validation and rewards.
 func NewConfiguredProcessor(validator MessageValidator, rewarder BlockRewarder, actors builtin.Actors) *DefaultProcessor {
 	return &DefaultProcessor{
@@ -591,6 +600,16 @@ func (br *DefaultBlockRewarder) BlockRewardAmount() types.AttoFIL {
 	return types.NewAttoFILFromFIL(1000)
 }
 
+// BlockRewardAt returns the block reward paid to the miner of a block at height
+// h. The genesis block is not mined and pays nothing. The emission schedule
+// does not decay yet, so every later height pays the same BlockRewardAmount.
+func (br *DefaultBlockRewarder) BlockRewardAt(h uint64) types.AttoFIL {
+	if h == 0 {
+		return types.ZeroAttoFIL
+	}
+	return br.BlockRewardAmount()
+}
+
 // rewardTransfer retrieves two actors from the given addresses and attempts to transfer the given value from the balance of the first's to the second.
 func rewardTransfer(ctx context.Context, fromAddr, toAddr address.Address, value types.AttoFIL, st *state.CachedTree) error {
 	fromActor, err := st.GetActor(ctx, fromAddr)
@@ -229,6 +229,23 @@ func TestProcessTipsetReward(t *testing.T) {
 	assert.Equal(t, minerBalance.Add(blockRewardAmount), minerOwnerActor.Balance)
 }
 
+func TestBlockRewardAt(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("default rewarder pays a flat reward after genesis", func(t *testing.T) {
+		processor := NewDefaultProcessor()
+		assert.Equal(t, types.ZeroAttoFIL, processor.BlockRewardAt(0))
+		for _, h := range []uint64{1, 2, 100, 1 << 40} {
+			assert.Equal(t, types.NewAttoFILFromFIL(1000), processor.BlockRewardAt(h), "height %d", h)
+		}
+	})
+
+	t.Run("configured rewarder is used", func(t *testing.T) {
+		processor := NewConfiguredProcessor(&FakeMessageValidator{}, &FakeBlockRewarder{}, builtin.DefaultActors)
+		assert.Equal(t, types.ZeroAttoFIL, processor.BlockRewardAt(1))
+	})
+}
+
 func TestProcessTipsetVMErrors(t *testing.T) {
 	tf.BadUnitTestWithSideEffects(t)
 
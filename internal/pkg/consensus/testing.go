@@ -122,6 +122,11 @@ func (tbr *FakeBlockRewarder) GasReward(ctx context.Context, st state.Tree, mine
 	return nil
 }
 
+// BlockRewardAt is always zero, as BlockReward pays nothing
+func (tbr *FakeBlockRewarder) BlockRewardAt(h uint64) types.AttoFIL {
+	return types.ZeroAttoFIL
+}
+
 // NewFakeProcessor creates a processor with a test validator and test rewarder
 func NewFakeProcessor(actors builtin.Actors) *DefaultProcessor {
 	return &DefaultProcessor{
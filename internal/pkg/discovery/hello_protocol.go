@@ -3,6 +3,7 @@ package discovery
 import (
 	"context"
 	"fmt"
+	"sync"
 	"time"
 
 	"github.com/ipfs/go-cid"
@@ -12,6 +13,7 @@ import (
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-core/protocol"
 	ma "github.com/multiformats/go-multiaddr"
+	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	cbu "github.com/filecoin-project/go-filecoin/internal/pkg/cborutil"
@@ -49,6 +51,11 @@ type HelloProtocolHandler struct {
 	getHeaviestTipSet getTipSetFunc
 
 	networkName string
+
+	// lastHello holds the last hello message received from each connected
+	// peer.
+	lastHelloMu sync.Mutex
+	lastHello   map[peer.ID]*HelloMessage
 }
 
 type peerDiscoveredCallback func(ci *block.ChainInfo)
@@ -67,6 +74,7 @@ func NewHelloProtocolHandler(h host.Host, gen cid.Cid, networkName string) *Hell
 		host:        h,
 		genesis:     gen,
 		networkName: networkName,
+		lastHello:   make(map[peer.ID]*HelloMessage),
 	}
 }
 
@@ -135,6 +143,65 @@ func (h *HelloProtocolHandler) receiveHello(ctx context.Context, p peer.ID) (*He
 	return &hello, nil
 }
 
+func (h *HelloProtocolHandler) recordHello(p peer.ID, msg *HelloMessage) {
+	h.lastHelloMu.Lock()
+	defer h.lastHelloMu.Unlock()
+	h.lastHello[p] = msg
+}
+
+func (h *HelloProtocolHandler) forgetHello(p peer.ID) {
+	h.lastHelloMu.Lock()
+	defer h.lastHelloMu.Unlock()
+	delete(h.lastHello, p)
+}
+
+func (h *HelloProtocolHandler) lastHelloFrom(p peer.ID) (*HelloMessage, bool) {
+	h.lastHelloMu.Lock()
+	defer h.lastHelloMu.Unlock()
+	msg, ok := h.lastHello[p]
+	return msg, ok
+}
+
+// HelloExchange is the outcome of a hello handshake with a peer.
+type HelloExchange struct {
+	Ours   HelloMessage
+	Theirs HelloMessage
+	// Replayed is set when the peer did not answer and Theirs is the last
+	// message it sent while connected.
+	Replayed bool
+}
+
+// GenesisMatch returns true if both sides of the exchange have the same
+// genesis block. Peers with different genesis blocks disconnect from each
+// other and never sync.
+func (e *HelloExchange) GenesisMatch() bool {
+	return e.Ours.GenesisHash.Equals(e.Theirs.GenesisHash)
+}
+
+// Handshake requests a hello message from peer p. If p is connected but does
+// not answer, the last message received from p is replayed instead. The last
+// message is forgotten once p disconnects.
+func (h *HelloProtocolHandler) Handshake(ctx context.Context, p peer.ID) (*HelloExchange, error) {
+	ours, err := h.getOurHelloMessage()
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, helloTimeout)
+	defer cancel()
+	theirs, err := h.receiveHello(ctx, p)
+	if err == nil {
+		h.recordHello(p, theirs)
+		return &HelloExchange{Ours: *ours, Theirs: *theirs}, nil
+	}
+
+	last, ok := h.lastHelloFrom(p)
+	if !ok {
+		return nil, errors.Wrapf(err, "failed to receive hello from peer %s", p)
+	}
+	return &HelloExchange{Ours: *ours, Theirs: *last, Replayed: true}, nil
+}
+
 // sendHello send a hello message on stream `s`.
 func (h *HelloProtocolHandler) sendHello(s net.Stream) error {
 	msg, err := h.getOurHelloMessage()
@@ -180,6 +247,7 @@ func (hn *helloProtocolNotifiee) Connected(n net.Network, c net.Conn) {
 			_ = c.Close()
 			return
 		}
+		hn.asHandler().recordHello(from, hello)
 
 		// process the hello message
 		ci, err := hn.asHandler().processHelloMessage(from, hello)
@@ -203,6 +271,13 @@ func (hn *helloProtocolNotifiee) Connected(n net.Network, c net.Conn) {
 
 func (hn *helloProtocolNotifiee) Listen(n net.Network, a ma.Multiaddr)      { /* empty */ }
 func (hn *helloProtocolNotifiee) ListenClose(n net.Network, a ma.Multiaddr) { /* empty */ }
-func (hn *helloProtocolNotifiee) Disconnected(n net.Network, c net.Conn)    { /* empty */ }
 func (hn *helloProtocolNotifiee) OpenedStream(n net.Network, s net.Stream)  { /* empty */ }
 func (hn *helloProtocolNotifiee) ClosedStream(n net.Network, s net.Stream)  { /* empty */ }
+
+func (hn *helloProtocolNotifiee) Disconnected(n net.Network, c net.Conn) {
+	// forget the peer's last hello once its last connection is closed
+	p := c.RemotePeer()
+	if n.Connectedness(p) != net.Connected {
+		hn.asHandler().forgetHello(p)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/libp2p/go-libp2p-core/protocol"
 	mocknet "github.com/libp2p/go-libp2p/p2p/net/mock"
 
 	"github.com/stretchr/testify/assert"
@@ -164,3 +165,77 @@ func TestHelloMultiBlock(t *testing.T) {
 	msc1.AssertExpectations(t)
 	msc2.AssertExpectations(t)
 }
+
+func TestHelloHandshakeExchange(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	mn, err := mocknet.WithNPeers(ctx, 3)
+	require.NoError(t, err)
+
+	a, b, c := mn.Hosts()[0], mn.Hosts()[1], mn.Hosts()[2]
+
+	builder := chain.NewBuilder(t, address.Undef)
+	genesisA := builder.AppendBlockOn(block.UndefTipSet)
+	genesisB := builder.AppendBlockOn(block.UndefTipSet)
+
+	heavy1 := th.RequireNewTipSet(t, &block.Block{Height: 2, Ticket: block.Ticket{VRFProof: []byte{0}}})
+	heavy2 := th.RequireNewTipSet(t, &block.Block{Height: 3, Ticket: block.Ticket{VRFProof: []byte{1}}})
+
+	msc1, msc2, msc3 := new(mockHelloCallback), new(mockHelloCallback), new(mockHelloCallback)
+	msc1.On("HelloCallback", mock.Anything, mock.Anything, mock.Anything).Return()
+	msc2.On("HelloCallback", mock.Anything, mock.Anything, mock.Anything).Return()
+	msc3.On("HelloCallback", mock.Anything, mock.Anything, mock.Anything).Return()
+	hg1, hg2 := &mockHeaviestGetter{heavy1}, &mockHeaviestGetter{heavy2}
+
+	handlerA := discovery.NewHelloProtocolHandler(a, genesisA.Cid(), "")
+	handlerA.Register(msc1.HelloCallback, hg1.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(b, genesisA.Cid(), "").Register(msc2.HelloCallback, hg2.getHeaviestTipSet)
+	discovery.NewHelloProtocolHandler(c, genesisB.Cid(), "").Register(msc3.HelloCallback, hg2.getHeaviestTipSet)
+
+	require.NoError(t, mn.LinkAll())
+
+	t.Run("same genesis", func(t *testing.T) {
+		exchange, err := handlerA.Handshake(ctx, b.ID())
+		require.NoError(t, err)
+		assert.False(t, exchange.Replayed)
+		assert.True(t, exchange.GenesisMatch())
+		assert.Equal(t, heavy1.Key(), exchange.Ours.HeaviestTipSetCids)
+		assert.Equal(t, heavy2.Key(), exchange.Theirs.HeaviestTipSetCids)
+		assert.Equal(t, uint64(3), exchange.Theirs.HeaviestTipSetHeight)
+	})
+
+	t.Run("different genesis", func(t *testing.T) {
+		exchange, err := handlerA.Handshake(ctx, c.ID())
+		require.NoError(t, err)
+		assert.False(t, exchange.GenesisMatch())
+		assert.Equal(t, genesisB.Cid(), exchange.Theirs.GenesisHash)
+	})
+
+	t.Run("replays the last hello from a connected peer that does not answer", func(t *testing.T) {
+		b.RemoveStreamHandler(protocol.ID("/filecoin/hello/"))
+
+		exchange, err := handlerA.Handshake(ctx, b.ID())
+		require.NoError(t, err)
+		assert.True(t, exchange.Replayed)
+		assert.Equal(t, heavy2.Key(), exchange.Theirs.HeaviestTipSetCids)
+	})
+
+	t.Run("forgets the last hello once the peer disconnects", func(t *testing.T) {
+		require.NoError(t, mn.UnlinkPeers(a.ID(), b.ID()))
+		require.NoError(t, mn.DisconnectPeers(a.ID(), b.ID()))
+
+		_, err := handlerA.Handshake(ctx, b.ID())
+		assert.Error(t, err)
+	})
+
+	t.Run("fails for an unreachable peer never heard from", func(t *testing.T) {
+		d, err := mn.GenPeer()
+		require.NoError(t, err)
+
+		_, err = handlerA.Handshake(ctx, d.ID())
+		assert.Error(t, err)
+	})
+}
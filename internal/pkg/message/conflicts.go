@@ -0,0 +1,83 @@
+package message
+
+import (
+	"sort"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// NonceConflict is a nonce of a sender that more than one distinct message
+// uses, at least one of which is still pending. At most one of them can ever
+// be executed.
+type NonceConflict struct {
+	From    address.Address
+	Nonce   uint64
+	Pending []cid.Cid
+	Mined   []cid.Cid
+}
+
+// FindNonceConflicts returns the (sender, nonce) pairs that a pending message
+// shares with a different pending or mined message, ordered by sender and
+// nonce. A message that is both pending and mined is not a conflict.
+func FindNonceConflicts(pending, mined []*types.SignedMessage) ([]NonceConflict, error) {
+	type entry struct {
+		pending, mined []cid.Cid
+		seen           map[cid.Cid]bool
+	}
+	byNonce := make(map[addressNonce]*entry)
+	add := func(msg *types.SignedMessage, isPending bool) error {
+		c, err := msg.Cid()
+		if err != nil {
+			return err
+		}
+		key := newAddressNonce(msg)
+		e, ok := byNonce[key]
+		if !ok {
+			e = &entry{seen: make(map[cid.Cid]bool)}
+			byNonce[key] = e
+		}
+		if e.seen[c] {
+			return nil
+		}
+		e.seen[c] = true
+		if isPending {
+			e.pending = append(e.pending, c)
+		} else {
+			e.mined = append(e.mined, c)
+		}
+		return nil
+	}
+	for _, msg := range pending {
+		if err := add(msg, true); err != nil {
+			return nil, err
+		}
+	}
+	for _, msg := range mined {
+		if err := add(msg, false); err != nil {
+			return nil, err
+		}
+	}
+
+	conflicts := []NonceConflict{}
+	for key, e := range byNonce {
+		if len(e.pending) == 0 || len(e.seen) < 2 {
+			continue
+		}
+		conflicts = append(conflicts, NonceConflict{
+			From:    key.addr,
+			Nonce:   key.nonce,
+			Pending: e.pending,
+			Mined:   e.mined,
+		})
+	}
+	sort.Slice(conflicts, func(i, j int) bool {
+		if conflicts[i].From != conflicts[j].From {
+			return conflicts[i].From.String() < conflicts[j].From.String()
+		}
+		return conflicts[i].Nonce < conflicts[j].Nonce
+	})
+	return conflicts, nil
+}
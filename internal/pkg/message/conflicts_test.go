@@ -0,0 +1,56 @@
+package message_test
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/message"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+func TestFindNonceConflicts(t *testing.T) {
+	tf.UnitTest(t)
+
+	mustCid := func(msg *types.SignedMessage) cid.Cid {
+		c, err := msg.Cid()
+		require.NoError(t, err)
+		return c
+	}
+	from := mockSigner.Addresses[0]
+
+	// Two pending messages with nonce 0.
+	pendingA, pendingB := newSignedMessage(), newSignedMessage()
+	// A pending message with nonce 1 that has also been mined.
+	minedPending := mustSetNonce(mockSigner, newSignedMessage(), 1)
+	// A pending message with nonce 2 and a different mined one.
+	stale, mined := mustSetNonce(mockSigner, newSignedMessage(), 2), mustSetNonce(mockSigner, newSignedMessage(), 2)
+	// Two mined messages with nonce 3 and nothing pending.
+	oldA, oldB := mustSetNonce(mockSigner, newSignedMessage(), 3), mustSetNonce(mockSigner, newSignedMessage(), 3)
+
+	conflicts, err := message.FindNonceConflicts(
+		[]*types.SignedMessage{pendingA, minedPending, stale, pendingB},
+		[]*types.SignedMessage{minedPending, mined, oldA, oldB},
+	)
+	require.NoError(t, err)
+	require.Len(t, conflicts, 2)
+
+	assert.Equal(t, from, conflicts[0].From)
+	assert.Equal(t, uint64(0), conflicts[0].Nonce)
+	assert.ElementsMatch(t, []cid.Cid{mustCid(pendingA), mustCid(pendingB)}, conflicts[0].Pending)
+	assert.Empty(t, conflicts[0].Mined)
+
+	assert.Equal(t, message.NonceConflict{
+		From:    from,
+		Nonce:   2,
+		Pending: []cid.Cid{mustCid(stale)},
+		Mined:   []cid.Cid{mustCid(mined)},
+	}, conflicts[1])
+
+	none, err := message.FindNonceConflicts([]*types.SignedMessage{minedPending}, []*types.SignedMessage{minedPending})
+	require.NoError(t, err)
+	assert.Empty(t, none)
+}
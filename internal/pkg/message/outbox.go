@@ -111,7 +111,7 @@ func (ob *Outbox) Send(ctx context.Context, from, to address.Address, value type
 	}
 
 	rawMsg := types.NewMeteredMessage(from, to, nonce, value, method, encodedParams, gasPrice, gasLimit)
-	signed, err := types.NewSignedMessage(*rawMsg, ob.signer)
+	signed, err := types.NewSignedMessageContext(ctx, *rawMsg, ob.signer)
 
 	if err != nil {
 		return cid.Undef, nil, errors.Wrap(err, "failed to sign message")
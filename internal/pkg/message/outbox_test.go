@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -158,4 +159,26 @@ func TestOutbox(t *testing.T) {
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "account or empty")
 	})
+
+	t.Run("gives up signing once the context is done", func(t *testing.T) {
+		w, _ := types.NewMockSignersAndKeyInfo(1)
+		sender := w.Addresses[0]
+		toAddr := address.NewForTestGetter()()
+		queue := message.NewQueue()
+		publisher := &message.MockPublisher{}
+		provider := message.NewFakeProvider(t)
+
+		head := provider.NewGenesis()
+		actr, _ := account.NewActor(types.ZeroAttoFIL)
+		provider.SetHeadAndActor(t, head.Key(), sender, actr)
+
+		ob := message.NewOutbox(w, message.FakeValidator{}, queue, publisher, message.NullPolicy{}, provider, provider, newOutboxTestJournal(t))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		_, _, err := ob.Send(ctx, sender, toAddr, types.ZeroAttoFIL, types.NewGasPrice(0), types.NewGasUnits(0), true, types.InvalidMethodID)
+		assert.Equal(t, context.Canceled, errors.Cause(err))
+		assert.Empty(t, queue.List(sender))
+		assert.Nil(t, publisher.Message)
+	})
 }
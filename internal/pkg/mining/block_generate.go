@@ -63,8 +63,15 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 	// Construct list of message candidates for inclusion.
 	// These messages will be processed, and those that fail excluded from the block.
 	pending := w.messageSource.Pending()
+	if w.minGasPrice != nil {
+		pending = filterByGasPrice(pending, w.minGasPrice())
+	}
 	mq := NewMessageQueue(pending)
-	candidateMsgs := orderMessageCandidates(mq.Drain())
+	maxMessages := uint(types.BlockMessageLimit)
+	if w.maxMessages != nil && w.maxMessages() < maxMessages {
+		maxMessages = w.maxMessages()
+	}
+	candidateMsgs := orderMessageCandidates(mq.DrainAtMost(maxMessages))
 
 	// run state transition to learn which messages are valid
 	vms := vm.NewStorageMap(w.blockstore)
@@ -127,6 +134,11 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 		return nil, errors.Wrapf(err, "error retrieving receipt root for tipset %s", baseTipSet.Key().String())
 	}
 
+	timestamp, err := w.blockTimestamp(baseTipSet, nullBlockCount)
+	if err != nil {
+		return nil, errors.Wrap(err, "get block timestamp")
+	}
+
 	next := &block.Block{
 		Miner:           w.minerAddr,
 		Height:          types.Uint64(blockHeight),
@@ -137,7 +149,7 @@ func (w *DefaultWorker) Generate(ctx context.Context,
 		ElectionProof:   electionProof,
 		StateRoot:       baseStateRoot,
 		Ticket:          ticket,
-		Timestamp:       types.Uint64(w.clock.Now().Unix()),
+		Timestamp:       timestamp,
 		BLSAggregateSig: blsAggregateSig,
 	}
 	workerAddr, err := w.api.MinerGetWorkerAddress(ctx, w.minerAddr, baseTipSet.Key())
@@ -187,3 +199,37 @@ func orderMessageCandidates(messages []*types.SignedMessage) []*types.SignedMess
 	}
 	return append(blsMessages, secpMessages...)
 }
+
+// filterByGasPrice drops the messages priced below minGasPrice. Later messages
+// from the same senders are kept but fail to apply until the dropped ones are
+// mined, so they stay in the message pool too.
+func filterByGasPrice(messages []*types.SignedMessage, minGasPrice types.AttoFIL) []*types.SignedMessage {
+	var kept []*types.SignedMessage
+	for _, m := range messages {
+		if !m.Message.GasPrice.LessThan(minGasPrice) {
+			kept = append(kept, m)
+		}
+	}
+	return kept
+}
+
+// blockTimestamp returns the timestamp of a block mined on baseTipSet after
+// nullBlockCount null rounds. It is the current time unless mock mining sets an
+// interval between blocks. Mock timestamps are clamped to the current time, as
+// block validation rejects blocks from the future.
+func (w *DefaultWorker) blockTimestamp(baseTipSet block.TipSet, nullBlockCount uint64) (types.Uint64, error) {
+	now := types.Uint64(w.clock.Now().Unix())
+	if w.mockMineInterval == 0 {
+		return now, nil
+	}
+	parentTimestamp, err := baseTipSet.MinTimestamp()
+	if err != nil {
+		return 0, err
+	}
+	rounds := nullBlockCount + 1
+	timestamp := parentTimestamp + types.Uint64(uint64(w.mockMineInterval.Seconds())*rounds)
+	if timestamp > now {
+		return now, nil
+	}
+	return timestamp, nil
+}
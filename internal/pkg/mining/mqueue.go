@@ -80,6 +80,20 @@ func (mq *MessageQueue) Drain() []*types.SignedMessage {
 	return out
 }
 
+// DrainAtMost removes and returns up to n messages in a slice, in the order
+// they would be popped. Messages beyond the first n remain in the queue.
+func (mq *MessageQueue) DrainAtMost(n uint) []*types.SignedMessage {
+	var out []*types.SignedMessage
+	for uint(len(out)) < n {
+		msg, hasMore := mq.Pop()
+		if !hasMore {
+			break
+		}
+		out = append(out, msg)
+	}
+	return out
+}
+
 // A slice of messages ordered by CallSeqNum (for a single sender).
 type nonceQueue []*types.SignedMessage
 
@@ -100,4 +100,18 @@ func TestMessageQueueOrder(t *testing.T) {
 		assert.Equal(t, expected, actual)
 		assert.True(t, q.Empty())
 	})
+
+	t.Run("drains at most n in order", func(t *testing.T) {
+		msgs := []*types.SignedMessage{
+			sign(a0, to, 0, 0, 1),
+			sign(a0, to, 1, 0, 3),
+			sign(a2, to, 0, 0, 2),
+		}
+
+		q := NewMessageQueue(msgs)
+		assert.Equal(t, []*types.SignedMessage{msgs[2], msgs[0]}, q.DrainAtMost(2))
+		assert.False(t, q.Empty())
+		assert.Equal(t, []*types.SignedMessage{msgs[1]}, q.DrainAtMost(2))
+		assert.True(t, q.Empty())
+	})
 }
@@ -36,6 +36,12 @@ type timingScheduler struct {
 	// pollHeadFunc is the function the scheduler uses to poll for the
 	// current heaviest tipset
 	pollHeadFunc func() (block.TipSet, error)
+	// pausedFunc returns why the scheduler should skip mining this round, or
+	// nil if it may mine. A nil function never pauses.
+	pausedFunc func() error
+	// nullRounds is the number of null blocks the first mining run on the
+	// initial base mines with.
+	nullRounds uint64
 
 	isStarted bool
 }
@@ -89,9 +95,19 @@ func (s *timingScheduler) Start(miningCtx context.Context) (<-chan Output, *sync
 				// TODO: investigate if there is a better way to handle this situation.
 				continue
 			}
+			if s.pausedFunc != nil {
+				if err := s.pausedFunc(); err != nil {
+					log.Infof("mining paused: %s", err)
+					continue
+				}
+			}
 
 			// Determine how many null blocks we should mine with.
-			nullBlkCount = nextNullBlkCount(nullBlkCount, prevBase, base)
+			if !prevBase.Defined() {
+				nullBlkCount = s.nullRounds
+			} else {
+				nullBlkCount = nextNullBlkCount(nullBlkCount, prevBase, base)
+			}
 
 			// Mine synchronously! Ignore all new tipsets.
 			prevWon = s.worker.Mine(miningCtx, base, nullBlkCount, outCh)
@@ -127,9 +143,10 @@ func nextNullBlkCount(prevNullBlkCount uint64, prevBase, currBase block.TipSet)
 }
 
 // NewScheduler returns a new timingScheduler to schedule mining work on the
-// input worker.
-func NewScheduler(w Worker, md time.Duration, f func() (block.TipSet, error)) Scheduler {
-	return &timingScheduler{worker: w, mineDelay: md, pollHeadFunc: f}
+// input worker. Rounds for which paused returns an error are skipped; paused
+// may be nil.
+func NewScheduler(w Worker, md time.Duration, f func() (block.TipSet, error), paused func() error) Scheduler {
+	return &timingScheduler{worker: w, mineDelay: md, pollHeadFunc: f, pausedFunc: paused}
 }
 
 // MineOnce is a convenience function that presents a synchronous blocking
@@ -139,10 +156,17 @@ func NewScheduler(w Worker, md time.Duration, f func() (block.TipSet, error)) Sc
 // Then the scheduler takes this polling function, and the worker and the
 // mining duration
 func MineOnce(ctx context.Context, w Worker, md time.Duration, ts block.TipSet) (Output, error) {
+	return MineOnceAfterNullRounds(ctx, w, md, ts, 0)
+}
+
+// MineOnceAfterNullRounds is like MineOnce but starts mining as if nullRounds
+// rounds without blocks had already passed on top of the input tipset, so the
+// winning block's height accounts for them.
+func MineOnceAfterNullRounds(ctx context.Context, w Worker, md time.Duration, ts block.TipSet, nullRounds uint64) (Output, error) {
 	pollHeadFunc := func() (block.TipSet, error) {
 		return ts, nil
 	}
-	s := NewScheduler(w, md, pollHeadFunc)
+	s := &timingScheduler{worker: w, mineDelay: md, pollHeadFunc: pollHeadFunc, nullRounds: nullRounds}
 	subCtx, subCtxCancel := context.WithCancel(ctx)
 	defer subCtxCancel()
 
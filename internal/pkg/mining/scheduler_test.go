@@ -2,6 +2,8 @@ package mining_test
 
 import (
 	"context"
+	"errors"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -42,6 +44,33 @@ func TestMineOnce(t *testing.T) {
 	assert.True(t, ts.ToSlice()[0].StateRoot.Equals(result.NewBlock.StateRoot))
 }
 
+// TestMineOnceAfterNullRounds tests that the first mining run starts from the
+// requested number of null rounds and later runs continue counting from there.
+func TestMineOnceAfterNullRounds(t *testing.T) {
+	tf.UnitTest(t)
+
+	ts := newTestUtils(t)
+
+	var nullBlkCounts []uint64
+	worker := NewTestWorkerWithDeps(func(c context.Context, ts block.TipSet, nullBlkCount uint64, outCh chan<- Output) bool {
+		nullBlkCounts = append(nullBlkCounts, nullBlkCount)
+		// lose the first election
+		if len(nullBlkCounts) == 1 {
+			return false
+		}
+		select {
+		case outCh <- Output{NewBlock: ts.At(0)}:
+		case <-c.Done():
+		}
+		return true
+	})
+
+	result, err := MineOnceAfterNullRounds(context.Background(), worker, MineDelayTest, ts, 3)
+	require.NoError(t, err)
+	assert.NoError(t, result.Err)
+	assert.Equal(t, []uint64{3, 4}, nullBlkCounts)
+}
+
 // TestMineOnce10Null calls mine once off of a base tipset with a ticket that
 // will win after 10 rounds and verifies that the output has 1 ticket and a
 // +10 height.
@@ -120,7 +149,7 @@ func TestSchedulerPassesValue(t *testing.T) {
 		return head, nil
 	}
 	worker := NewTestWorkerWithDeps(checkValsMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	head = ts // set head so headFunc returns correctly
 	outCh, _ := scheduler.Start(ctx)
 	<-outCh
@@ -140,7 +169,7 @@ func TestSchedulerErrorsOnUnsetHead(t *testing.T) {
 		return block.UndefTipSet, nil
 	}
 	worker := NewTestWorkerWithDeps(nothingMine)
-	scheduler := NewScheduler(worker, MineDelayTest, nilHeadFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, nilHeadFunc, nil)
 	outCh, doneWg := scheduler.Start(ctx)
 	output := <-outCh
 	assert.Error(t, output.Err)
@@ -171,7 +200,7 @@ func TestSchedulerUpdatesNullBlkCount(t *testing.T) {
 		return head, nil
 	}
 	worker := NewTestWorkerWithDeps(checkTArrMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	head = ts
 	outCh, _ := scheduler.Start(ctx)
 	<-outCh
@@ -208,7 +237,7 @@ func TestSchedulerPassesManyValues(t *testing.T) {
 		return false
 	}
 	worker := NewTestWorkerWithDeps(checkValsMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	checkTS = ts1
 	head = ts1
 	outCh, _ := scheduler.Start(ctx)
@@ -244,7 +273,7 @@ func TestSchedulerCollect(t *testing.T) {
 		return false
 	}
 	worker := NewTestWorkerWithDeps(checkValsMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	head = ts1
 	outCh, _ := scheduler.Start(ctx)
 	// again this is racing on the assumption that mining delay is long
@@ -276,7 +305,7 @@ func TestSchedulerCancelMiningCtx(t *testing.T) {
 		return false
 	}
 	worker := NewTestWorkerWithDeps(shouldCancelMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	head = ts
 	outCh, doneWg := scheduler.Start(miningCtx)
 	miningCtxCancel()
@@ -305,7 +334,7 @@ func TestSchedulerMultiRoundWithCollect(t *testing.T) {
 		return false
 	}
 	worker := NewTestWorkerWithDeps(checkValsMine)
-	scheduler := NewScheduler(worker, MineDelayTest, headFunc)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, nil)
 	checkTS = ts1
 	head = ts1
 	outCh, doneWg := scheduler.Start(ctx)
@@ -327,3 +356,40 @@ func TestSchedulerMultiRoundWithCollect(t *testing.T) {
 
 	assert.Equal(t, ChannelClosed, ReceiveOutCh(outCh))
 }
+
+func TestSchedulerSkipsRoundsWhilePaused(t *testing.T) {
+	tf.UnitTest(t)
+	ts := newTestUtils(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	headFunc := func() (block.TipSet, error) {
+		return ts, nil
+	}
+	var paused, mined int32 = 1, 0
+	pausedFunc := func() error {
+		if atomic.LoadInt32(&paused) == 1 {
+			return errors.New("too few peers")
+		}
+		return nil
+	}
+	countMine := func(c context.Context, inTS block.TipSet, nbc uint64, outCh chan<- Output) bool {
+		atomic.AddInt32(&mined, 1)
+		outCh <- Output{}
+		return true
+	}
+	worker := NewTestWorkerWithDeps(countMine)
+	scheduler := NewScheduler(worker, MineDelayTest, headFunc, pausedFunc)
+	outCh, doneWg := scheduler.Start(ctx)
+
+	// Several rounds pass without mining.
+	time.Sleep(3 * MineDelayTest)
+	assert.Equal(t, int32(0), atomic.LoadInt32(&mined))
+
+	atomic.StoreInt32(&paused, 0)
+	<-outCh
+	assert.Equal(t, int32(1), atomic.LoadInt32(&mined))
+
+	cancel()
+	doneWg.Wait()
+}
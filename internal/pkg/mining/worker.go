@@ -114,6 +114,12 @@ type DefaultWorker struct {
 	messageStore  chain.MessageWriter // nolint: structcheck
 	blockstore    blockstore.Blockstore
 	clock         clock.Clock
+	maxMessages   func() uint
+	minGasPrice   func() types.AttoFIL
+
+	// mock mining
+	mockMineInterval time.Duration
+	mockMineWinner   address.Address
 }
 
 // WorkerParameters use for NewDefaultWorker parameters
@@ -138,6 +144,23 @@ type WorkerParameters struct {
 	MessageStore  chain.MessageWriter
 	Blockstore    blockstore.Blockstore
 	Clock         clock.Clock
+	// MaxMessages returns the maximum number of messages to include in a
+	// block. It is read for each block and capped at types.BlockMessageLimit;
+	// a nil function includes up to that limit.
+	MaxMessages func() uint
+	// MinGasPrice returns the lowest gas price of a message to include in a
+	// block. It is read for each block; a nil function includes any price.
+	MinGasPrice func() types.AttoFIL
+
+	// MockMineInterval, when nonzero, stamps each block this long after the
+	// earliest timestamp of its parents for every round since them instead of
+	// with the current time. Timestamps never run past the current time. It
+	// must not be shorter than the block time.
+	MockMineInterval time.Duration
+	// MockMineWinner, when set, is the only miner the worker mines for. That
+	// miner wins every election; Mine outputs an error for any other miner
+	// instead of running an election.
+	MockMineWinner address.Address
 }
 
 // NewDefaultWorker instantiates a new Worker.
@@ -158,6 +181,11 @@ func NewDefaultWorker(parameters WorkerParameters) *DefaultWorker {
 		ticketGen:      parameters.TicketGen,
 		tsMetadata:     parameters.TipSetMetadata,
 		clock:          parameters.Clock,
+		maxMessages:    parameters.MaxMessages,
+		minGasPrice:    parameters.MinGasPrice,
+
+		mockMineInterval: parameters.MockMineInterval,
+		mockMineWinner:   parameters.MockMineWinner,
 	}
 }
 
@@ -177,6 +205,11 @@ func (w *DefaultWorker) Mine(ctx context.Context, base block.TipSet, nullBlkCoun
 		return
 	}
 
+	if !w.mockMineWinner.Empty() && w.mockMineWinner != w.minerAddr {
+		outCh <- Output{Err: errors.Errorf("miner %s is not the mock mining winner %s", w.minerAddr, w.mockMineWinner)}
+		return
+	}
+
 	// Read uncached worker address
 	workerAddr, err := w.api.MinerGetWorkerAddress(ctx, w.minerAddr, base.Key())
 	if err != nil {
@@ -242,17 +275,21 @@ func (w *DefaultWorker) Mine(ctx context.Context, base block.TipSet, nullBlkCoun
 		outCh <- Output{Err: err}
 		return
 	}
-	powerTable, err := w.getPowerTable(ctx, base.Key())
-	if err != nil {
-		log.Errorf("Worker.Mine couldn't get snapshot for tipset: %s", err.Error())
-		outCh <- Output{Err: err}
-		return
-	}
-	weHaveAWinner, err := w.election.IsElectionWinner(ctx, powerTable, electionTicket, nullBlkCount, electionProof, workerAddr, w.minerAddr)
-	if err != nil {
-		log.Errorf("Worker.Mine couldn't run election: %s", err.Error())
-		outCh <- Output{Err: err}
-		return
+	// The mock mining winner wins every election, whatever its power.
+	weHaveAWinner := !w.mockMineWinner.Empty() && w.mockMineWinner == w.minerAddr
+	if !weHaveAWinner {
+		powerTable, err := w.getPowerTable(ctx, base.Key())
+		if err != nil {
+			log.Errorf("Worker.Mine couldn't get snapshot for tipset: %s", err.Error())
+			outCh <- Output{Err: err}
+			return
+		}
+		weHaveAWinner, err = w.election.IsElectionWinner(ctx, powerTable, electionTicket, nullBlkCount, electionProof, workerAddr, w.minerAddr)
+		if err != nil {
+			log.Errorf("Worker.Mine couldn't run election: %s", err.Error())
+			outCh <- Output{Err: err}
+			return
+		}
 	}
 
 	// This address has mining rights, so mine a block
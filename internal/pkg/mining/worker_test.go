@@ -262,6 +262,76 @@ func Test_Mine(t *testing.T) {
 		assert.EqualError(t, r.Err, "bad input tipset with no blocks sent to Mine()")
 		assert.False(t, ticketGen)
 	})
+
+	t.Run("Mock mining winner wins without an election", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		worker := mining.NewDefaultWorker(mining.WorkerParameters{
+			API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+			MinerAddr:      minerAddr,
+			MinerOwnerAddr: minerOwnerAddr,
+			WorkerSigner:   mockSigner,
+
+			TipSetMetadata: fakeTSMetadata{},
+			GetStateTree:   getStateTree,
+			GetWeight:      getWeightTest,
+			GetAncestors:   getAncestors,
+			Election:       &losingElectionMachine{},
+			TicketGen:      consensus.NewMockTicketMachine(func(block.Ticket) {}),
+
+			MessageSource: pool,
+			Processor:     th.NewFakeProcessor(),
+			Blockstore:    bs,
+			MessageStore:  messages,
+			Clock:         clock.NewSystemClock(),
+
+			MockMineWinner: minerAddr,
+		})
+		outCh := make(chan mining.Output)
+		go worker.Mine(ctx, tipSet, 0, outCh)
+		r := <-outCh
+		require.NoError(t, r.Err)
+		assert.Equal(t, minerAddr, r.NewBlock.Miner)
+	})
+
+	t.Run("Not the mock mining winner", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+		ticketGen := false
+		sawTicket := func(_ block.Ticket) {
+			ticketGen = true
+		}
+		testTicketGen := consensus.NewMockTicketMachine(sawTicket)
+		worker := mining.NewDefaultWorker(mining.WorkerParameters{
+			API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+			MinerAddr:      minerAddr,
+			MinerOwnerAddr: minerOwnerAddr,
+			WorkerSigner:   mockSigner,
+
+			TipSetMetadata: fakeTSMetadata{},
+			GetStateTree:   getStateTree,
+			GetWeight:      getWeightTest,
+			GetAncestors:   getAncestors,
+			Election:       &consensus.FakeElectionMachine{},
+			TicketGen:      testTicketGen,
+
+			MessageSource: pool,
+			Processor:     th.NewFakeProcessor(),
+			Blockstore:    bs,
+			MessageStore:  messages,
+			Clock:         clock.NewSystemClock(),
+
+			MockMineWinner: addrs[0],
+		})
+		outCh := make(chan mining.Output)
+		go worker.Mine(ctx, tipSet, 0, outCh)
+		r := <-outCh
+		require.Error(t, r.Err)
+		assert.Contains(t, r.Err.Error(), "is not the mock mining winner")
+		assert.False(t, ticketGen)
+	})
 }
 
 func sharedSetupInitial() (*hamt.CborIpldStore, *message.Pool, cid.Cid) {
@@ -647,6 +717,80 @@ func TestGeneratePoolBlockResults(t *testing.T) {
 	assert.Len(t, msgs, 1) // This is the good message
 }
 
+func TestGenerateSkipsMessagesBelowMinGasPrice(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	mockSigner, blockSignerAddr := setupSigner()
+	newCid := types.NewCidForTestGetter()
+	st, pool, addrs, bs := sharedSetup(t, mockSigner)
+
+	getStateTree := func(c context.Context, ts block.TipSet) (state.Tree, error) {
+		return st, nil
+	}
+	getAncestors := func(ctx context.Context, ts block.TipSet, newBlockHeight *types.BlockHeight) ([]block.TipSet, error) {
+		return nil, nil
+	}
+
+	messages := chain.NewMessageStore(bs)
+
+	worker := mining.NewDefaultWorker(mining.WorkerParameters{
+		API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+		MinerAddr:      addrs[4],
+		MinerOwnerAddr: addrs[3],
+		WorkerSigner:   mockSigner,
+
+		TipSetMetadata: fakeTSMetadata{},
+		GetStateTree:   getStateTree,
+		GetWeight:      getWeightTest,
+		GetAncestors:   getAncestors,
+		Election:       &consensus.FakeElectionMachine{},
+		TicketGen:      &consensus.FakeTicketMachine{},
+
+		MessageSource: pool,
+		Processor:     consensus.NewDefaultProcessor(),
+		Blockstore:    bs,
+		MessageStore:  messages,
+		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
+		MinGasPrice:   func() types.AttoFIL { return types.NewGasPrice(2) },
+	})
+
+	cheap := types.NewMeteredMessage(addrs[1], addrs[0], 0, types.ZeroAttoFIL, types.SendMethodID, nil, types.NewGasPrice(1), types.NewGasUnits(0))
+	smsgCheap, err := types.NewSignedMessage(*cheap, &mockSigner)
+	require.NoError(t, err)
+
+	priced := types.NewMeteredMessage(addrs[0], addrs[1], 0, types.ZeroAttoFIL, types.SendMethodID, nil, types.NewGasPrice(2), types.NewGasUnits(0))
+	smsgPriced, err := types.NewSignedMessage(*priced, &mockSigner)
+	require.NoError(t, err)
+
+	_, err = pool.Add(ctx, smsgCheap, 0)
+	require.NoError(t, err)
+	_, err = pool.Add(ctx, smsgPriced, 0)
+	require.NoError(t, err)
+
+	stateRoot, err := st.Flush(ctx)
+	require.NoError(t, err)
+
+	baseBlock := block.Block{
+		Parents:       block.NewTipSetKey(newCid()),
+		Height:        types.Uint64(100),
+		StateRoot:     stateRoot,
+		ElectionProof: consensus.MakeFakeElectionProofForTest(),
+	}
+	blk, err := worker.Generate(ctx, th.RequireNewTipSet(t, &baseBlock), block.Ticket{VRFProof: []byte{0}}, consensus.MakeFakeElectionProofForTest(), 0)
+	require.NoError(t, err)
+
+	// Only the message at the minimum gas price is included.
+	msgs, _, err := messages.LoadMessages(ctx, blk.Messages)
+	require.NoError(t, err)
+	require.Len(t, msgs, 1)
+	assert.Equal(t, smsgPriced.Message.GasPrice, msgs[0].Message.GasPrice)
+
+	// The cheap message stays in the pool.
+	assert.Contains(t, pool.Pending(), smsgCheap)
+}
+
 func TestGenerateSetsBasicFields(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -713,6 +857,72 @@ func TestGenerateSetsBasicFields(t *testing.T) {
 	assert.Equal(t, minerAddr, blk.Miner)
 }
 
+func TestGenerateMockMineInterval(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	mockSigner, blockSignerAddr := setupSigner()
+	newCid := types.NewCidForTestGetter()
+
+	st, pool, addrs, bs := sharedSetup(t, mockSigner)
+
+	getStateTree := func(c context.Context, ts block.TipSet) (state.Tree, error) {
+		return st, nil
+	}
+	getAncestors := func(ctx context.Context, ts block.TipSet, newBlockHeight *types.BlockHeight) ([]block.TipSet, error) {
+		return nil, nil
+	}
+	minerAddr := addrs[4]
+	minerOwnerAddr := addrs[3]
+
+	worker := mining.NewDefaultWorker(mining.WorkerParameters{
+		API: th.NewDefaultFakeWorkerPorcelainAPI(blockSignerAddr),
+
+		MinerAddr:      minerAddr,
+		MinerOwnerAddr: minerOwnerAddr,
+		WorkerSigner:   mockSigner,
+
+		TipSetMetadata: fakeTSMetadata{},
+		GetStateTree:   getStateTree,
+		GetWeight:      getWeightTest,
+		GetAncestors:   getAncestors,
+		Election:       &consensus.FakeElectionMachine{},
+		TicketGen:      &consensus.FakeTicketMachine{},
+
+		MessageSource: pool,
+		Processor:     consensus.NewDefaultProcessor(),
+		Blockstore:    bs,
+		MessageStore:  chain.NewMessageStore(bs),
+		Clock:         th.NewFakeClock(time.Unix(1234567890, 0)),
+
+		MockMineInterval: 30 * time.Second,
+	})
+
+	baseBlock := block.Block{
+		Height:        types.Uint64(100),
+		StateRoot:     newCid(),
+		ElectionProof: consensus.MakeFakeElectionProofForTest(),
+		Timestamp:     types.Uint64(1000),
+	}
+	baseTipSet := th.RequireNewTipSet(t, &baseBlock)
+
+	blk, err := worker.Generate(ctx, baseTipSet, mining.NthTicket(7), consensus.MakeFakeElectionProofForTest(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, types.Uint64(1030), blk.Timestamp)
+
+	// Each null round adds an interval.
+	blk, err = worker.Generate(ctx, baseTipSet, mining.NthTicket(7), consensus.MakeFakeElectionProofForTest(), 2)
+	require.NoError(t, err)
+	assert.Equal(t, types.Uint64(1090), blk.Timestamp)
+
+	// Timestamps never run past the current time.
+	baseBlock.Timestamp = types.Uint64(1234567880)
+	baseTipSet = th.RequireNewTipSet(t, &baseBlock)
+	blk, err = worker.Generate(ctx, baseTipSet, mining.NthTicket(7), consensus.MakeFakeElectionProofForTest(), 0)
+	require.NoError(t, err)
+	assert.Equal(t, types.Uint64(1234567890), blk.Timestamp)
+}
+
 func TestGenerateWithoutMessages(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -859,3 +1069,12 @@ func (tm fakeTSMetadata) GetTipSetStateRoot(key block.TipSetKey) (cid.Cid, error
 func (tm fakeTSMetadata) GetTipSetReceiptsRoot(key block.TipSetKey) (cid.Cid, error) {
 	return dag.NewRawNode([]byte("receipt root")).Cid(), nil
 }
+
+// losingElectionMachine runs fake elections that no miner wins.
+type losingElectionMachine struct {
+	consensus.FakeElectionMachine
+}
+
+func (lem *losingElectionMachine) IsElectionWinner(ctx context.Context, ptv consensus.PowerTableView, ticket block.Ticket, nullCount uint64, electionProof block.VRFPi, signerAddr, minerAddr address.Address) (bool, error) {
+	return false, nil
+}
@@ -0,0 +1,92 @@
+package net
+
+import (
+	"sync"
+
+	"github.com/libp2p/go-libp2p-core/metrics"
+	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/libp2p/go-libp2p-core/protocol"
+)
+
+// BandwidthStats is the amount of data the node moved over the swarm since it
+// started or since the counters were last reset. Rates are not affected by a
+// reset.
+type BandwidthStats struct {
+	Total      metrics.Stats
+	ByPeer     map[string]metrics.Stats `json:",omitempty"`
+	ByProtocol map[string]metrics.Stats `json:",omitempty"`
+}
+
+// bandwidthBaseline holds the counters at the time of the last reset. The
+// libp2p counters cannot be reset, so they are reported relative to it.
+type bandwidthBaseline struct {
+	lk         sync.Mutex
+	total      metrics.Stats
+	byPeer     map[peer.ID]metrics.Stats
+	byProtocol map[protocol.ID]metrics.Stats
+}
+
+// Bandwidth returns the bandwidth used since the counters were last reset,
+// optionally broken down by peer and by protocol.
+func (network *Network) Bandwidth(byPeer, byProtocol bool) BandwidthStats {
+	network.bandwidth.lk.Lock()
+	defer network.bandwidth.lk.Unlock()
+	return network.bandwidthSinceReset(byPeer, byProtocol)
+}
+
+// ResetBandwidth returns the bandwidth used since the counters were last
+// reset, broken down by peer and by protocol, and resets the counters.
+func (network *Network) ResetBandwidth() BandwidthStats {
+	network.bandwidth.lk.Lock()
+	defer network.bandwidth.lk.Unlock()
+
+	stats := network.bandwidthSinceReset(true, true)
+
+	network.bandwidth.total = network.Reporter.GetBandwidthTotals()
+	network.bandwidth.byPeer = make(map[peer.ID]metrics.Stats)
+	for _, pid := range network.host.Peerstore().Peers() {
+		network.bandwidth.byPeer[pid] = network.Reporter.GetBandwidthForPeer(pid)
+	}
+	network.bandwidth.byProtocol = make(map[protocol.ID]metrics.Stats)
+	for _, p := range network.host.Mux().Protocols() {
+		pid := protocol.ID(p)
+		network.bandwidth.byProtocol[pid] = network.Reporter.GetBandwidthForProtocol(pid)
+	}
+	return stats
+}
+
+// bandwidthSinceReset must be called with the baseline lock held.
+func (network *Network) bandwidthSinceReset(byPeer, byProtocol bool) BandwidthStats {
+	stats := BandwidthStats{
+		Total: statsSince(network.Reporter.GetBandwidthTotals(), network.bandwidth.total),
+	}
+	if byPeer {
+		stats.ByPeer = make(map[string]metrics.Stats)
+		for _, pid := range network.host.Peerstore().Peers() {
+			s := statsSince(network.Reporter.GetBandwidthForPeer(pid), network.bandwidth.byPeer[pid])
+			if s.TotalIn != 0 || s.TotalOut != 0 {
+				stats.ByPeer[pid.Pretty()] = s
+			}
+		}
+	}
+	if byProtocol {
+		stats.ByProtocol = make(map[string]metrics.Stats)
+		for _, p := range network.host.Mux().Protocols() {
+			pid := protocol.ID(p)
+			s := statsSince(network.Reporter.GetBandwidthForProtocol(pid), network.bandwidth.byProtocol[pid])
+			if s.TotalIn != 0 || s.TotalOut != 0 {
+				stats.ByProtocol[p] = s
+			}
+		}
+	}
+	return stats
+}
+
+func statsSince(current, baseline metrics.Stats) metrics.Stats {
+	return metrics.Stats{
+		TotalIn:  current.TotalIn - baseline.TotalIn,
+		TotalOut: current.TotalOut - baseline.TotalOut,
+		RateIn:   current.RateIn,
+		RateOut:  current.RateOut,
+	}
+}
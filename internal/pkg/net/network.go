@@ -8,6 +8,7 @@ import (
 
 	"github.com/libp2p/go-libp2p-core/host"
 	"github.com/libp2p/go-libp2p-core/metrics"
+	inet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/peer"
 	"github.com/libp2p/go-libp2p-swarm"
 	ma "github.com/multiformats/go-multiaddr"
@@ -20,7 +21,10 @@ type SwarmConnInfo struct {
 	Peer    string
 	Latency string
 	Muxer   string
-	Streams []SwarmStreamInfo
+	// Direction is "inbound" if the peer opened the connection and "outbound"
+	// if this node did.
+	Direction string
+	Streams   []SwarmStreamInfo
 }
 
 // SwarmStreamInfo represents details about a single swarm stream.
@@ -63,6 +67,8 @@ type Network struct {
 	metrics.Reporter
 	*Router
 	*Pinger
+
+	bandwidth bandwidthBaseline
 }
 
 // New returns a new Network
@@ -95,6 +101,18 @@ func (network *Network) GetBandwidthStats() metrics.Stats {
 	return network.Reporter.GetBandwidthTotals()
 }
 
+// Protocols returns the ids of the protocols the node handles, sorted.
+func (network *Network) Protocols() []string {
+	protocols := network.host.Mux().Protocols()
+	sort.Strings(protocols)
+	return protocols
+}
+
+// PeerProtocols returns the ids of the protocols a peer announced it supports.
+func (network *Network) PeerProtocols(pid peer.ID) ([]string, error) {
+	return network.host.Peerstore().GetProtocols(pid)
+}
+
 // ConnectionResult represents the result of an attempted connection from the
 // Connect method.
 type ConnectionResult struct {
@@ -139,6 +157,24 @@ func (network *Network) Connect(ctx context.Context, addrs []string) (<-chan Con
 	return outCh, nil
 }
 
+// Disconnect closes all connections to the peer at the given address. It
+// fails if the node is not connected to the peer.
+func (network *Network) Disconnect(addr string) (peer.ID, error) {
+	pis, err := PeerAddrsToAddrInfo([]string{addr})
+	if err != nil {
+		return "", err
+	}
+	pid := pis[0].ID
+
+	if network.host.Network().Connectedness(pid) != inet.Connected {
+		return "", fmt.Errorf("not connected to peer %s", pid.Pretty())
+	}
+	if err := network.host.Network().ClosePeer(pid); err != nil {
+		return "", errors.Wrapf(err, "failed to disconnect from peer %s", pid.Pretty())
+	}
+	return pid, nil
+}
+
 // Peers lists peers currently available on the network
 func (network *Network) Peers(ctx context.Context, verbose, latency, streams bool) (*SwarmConnInfos, error) {
 	if network.host == nil {
@@ -157,6 +193,9 @@ func (network *Network) Peers(ctx context.Context, verbose, latency, streams boo
 			Peer: pid.Pretty(),
 		}
 
+		if verbose {
+			ci.Direction = connDirection(c.Stat().Direction)
+		}
 		if verbose || latency {
 			lat := network.host.Peerstore().LatencyEWMA(pid)
 			if lat == 0 {
@@ -179,3 +218,15 @@ func (network *Network) Peers(ctx context.Context, verbose, latency, streams boo
 	sort.Sort(&out)
 	return &out, nil
 }
+
+// connDirection describes who opened a connection.
+func connDirection(dir inet.Direction) string {
+	switch dir {
+	case inet.DirInbound:
+		return "inbound"
+	case inet.DirOutbound:
+		return "outbound"
+	default:
+		return "unknown"
+	}
+}
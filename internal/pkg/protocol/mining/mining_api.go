@@ -2,15 +2,19 @@ package mining
 
 import (
 	"context"
-	"fmt"
+	"sync/atomic"
 	"time"
 
+	logging "github.com/ipfs/go-log"
+	"github.com/pkg/errors"
+
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/mining"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
-	"github.com/pkg/errors"
 )
 
+var log = logging.Logger("mining/api")
+
 type miningChainReader interface {
 	GetHead() block.TipSetKey
 	GetTipSet(tsKey block.TipSetKey) (block.TipSet, error)
@@ -22,11 +26,17 @@ type API struct {
 	addNewBlockFunc func(context.Context, *block.Block) (err error)
 	chainReader     miningChainReader
 	isMiningFunc    func() bool
+	stopHeightFunc  func() uint64
+	pausedFunc      func() error
 	mineDelay       time.Duration
 	setupMiningFunc func(context.Context) error
-	startMiningFunc func(context.Context) error
+	startMiningFunc func(context.Context, uint64) error
 	stopMiningFunc  func(context.Context)
 	getWorkerFunc   func(ctx context.Context) (mining.Worker, error)
+
+	// nullRounds counts the null rounds requested with MiningAddNullRounds
+	// that the next MiningOnce has not yet mined over.
+	nullRounds *uint64
 }
 
 // New creates a new API instance with the provided deps
@@ -35,9 +45,11 @@ func New(
 	addNewBlockFunc func(context.Context, *block.Block) (err error),
 	chainReader miningChainReader,
 	isMiningFunc func() bool,
+	stopHeightFunc func() uint64,
+	pausedFunc func() error,
 	blockMineDelay time.Duration,
 	setupMiningFunc func(ctx context.Context) error,
-	startMiningFunc func(context.Context) error,
+	startMiningFunc func(context.Context, uint64) error,
 	stopMiningfunc func(context.Context),
 	getWorkerFunc func(ctx context.Context) (mining.Worker, error),
 ) API {
@@ -46,11 +58,14 @@ func New(
 		addNewBlockFunc: addNewBlockFunc,
 		chainReader:     chainReader,
 		isMiningFunc:    isMiningFunc,
+		stopHeightFunc:  stopHeightFunc,
+		pausedFunc:      pausedFunc,
 		mineDelay:       blockMineDelay,
 		setupMiningFunc: setupMiningFunc,
 		startMiningFunc: startMiningFunc,
 		stopMiningFunc:  stopMiningfunc,
 		getWorkerFunc:   getWorkerFunc,
+		nullRounds:      new(uint64),
 	}
 }
 
@@ -65,6 +80,18 @@ func (a *API) MiningIsActive() bool {
 	return a.isMiningFunc()
 }
 
+// MiningStopHeight returns the height at which mining stops, or zero if
+// mining does not stop on its own
+func (a *API) MiningStopHeight() uint64 {
+	return a.stopHeightFunc()
+}
+
+// MiningPaused returns why active mining is skipping rounds, or nil if it
+// is not
+func (a *API) MiningPaused() error {
+	return a.pausedFunc()
+}
+
 // MiningOnce mines a single block in the given context, and returns the new block.
 func (a *API) MiningOnce(ctx context.Context) (*block.Block, error) {
 	if a.isMiningFunc() {
@@ -81,8 +108,11 @@ func (a *API) MiningOnce(ctx context.Context) (*block.Block, error) {
 		return nil, err
 	}
 
-	fmt.Printf("mining once\n")
-	res, err := mining.MineOnce(ctx, miningWorker, a.mineDelay, ts)
+	// The null rounds stay pending until a block is mined over them, so that
+	// a failed attempt does not lose them.
+	nullRounds := atomic.LoadUint64(a.nullRounds)
+	log.Debugf("mining once after %d null rounds", nullRounds)
+	res, err := mining.MineOnceAfterNullRounds(ctx, miningWorker, a.mineDelay, ts, nullRounds)
 	if err != nil {
 		return nil, err
 	}
@@ -90,14 +120,23 @@ func (a *API) MiningOnce(ctx context.Context) (*block.Block, error) {
 		return nil, res.Err
 	}
 
-	fmt.Printf("adding block\n")
+	log.Debugf("adding block %s", res.NewBlock.Cid())
 	if err := a.addNewBlockFunc(ctx, res.NewBlock); err != nil {
 		return nil, err
 	}
+	// Subtract rather than reset, keeping null rounds added meanwhile.
+	atomic.AddUint64(a.nullRounds, ^(nullRounds - 1))
 
 	return res.NewBlock, nil
 }
 
+// MiningAddNullRounds simulates n rounds in which no block was mined. The next
+// block mined with MiningOnce is mined as if these rounds had passed on top of
+// the current head. Returns the total number of null rounds pending.
+func (a *API) MiningAddNullRounds(n uint64) uint64 {
+	return atomic.AddUint64(a.nullRounds, n)
+}
+
 // MiningSetup sets up a storage miner without running repeated tasks like mining
 func (a *API) MiningSetup(ctx context.Context) error {
 	return a.setupMiningFunc(ctx)
@@ -105,7 +144,13 @@ func (a *API) MiningSetup(ctx context.Context) error {
 
 // MiningStart calls the node's StartMining function
 func (a *API) MiningStart(ctx context.Context) error {
-	return a.startMiningFunc(ctx)
+	return a.startMiningFunc(ctx, 0)
+}
+
+// MiningStartUntil starts mining and stops once a block is mined at
+// stopHeight
+func (a *API) MiningStartUntil(ctx context.Context, stopHeight uint64) error {
+	return a.startMiningFunc(ctx, stopHeight)
 }
 
 // MiningStop calls the node's StopMining function
@@ -2,8 +2,12 @@ package mining_test
 
 import (
 	"context"
+	"errors"
+	"sync"
 	"testing"
 
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/mining"
 	bapi "github.com/filecoin-project/go-filecoin/internal/pkg/protocol/mining"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -12,6 +16,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/node"
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 )
 
@@ -129,6 +134,77 @@ func TestMiningAPI_MiningTogether(t *testing.T) {
 	require.NotNil(t, blk)
 }
 
+func TestMiningAPI_MiningOnceNullRounds(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	head := th.RequireNewTipSet(t, &block.Block{})
+	worker := &nullRoundsWorker{err: errors.New("lost the election")}
+	api := bapi.New(
+		nil,
+		func(context.Context, *block.Block) error { return nil },
+		&headReader{head},
+		func() bool { return false },
+		nil,
+		nil,
+		0,
+		nil,
+		nil,
+		nil,
+		func(context.Context) (mining.Worker, error) { return worker, nil },
+	)
+
+	api.MiningAddNullRounds(2)
+	_, err := api.MiningOnce(ctx)
+	assert.Error(t, err)
+
+	// The failed attempt leaves the null rounds pending.
+	worker.err = nil
+	_, err = api.MiningOnce(ctx)
+	require.NoError(t, err)
+
+	// The mined block consumed them.
+	_, err = api.MiningOnce(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []uint64{2, 2, 0}, worker.nullRounds)
+}
+
+// nullRoundsWorker records the null rounds it is asked to mine over and
+// outputs a block, or err if it is set.
+type nullRoundsWorker struct {
+	lk         sync.Mutex
+	err        error
+	nullRounds []uint64
+}
+
+func (w *nullRoundsWorker) Mine(ctx context.Context, base block.TipSet, nullBlkCount uint64, outCh chan<- mining.Output) bool {
+	w.lk.Lock()
+	w.nullRounds = append(w.nullRounds, nullBlkCount)
+	err := w.err
+	w.lk.Unlock()
+
+	if err != nil {
+		outCh <- mining.NewOutput(nil, err)
+	} else {
+		outCh <- mining.NewOutput(&block.Block{Height: base.At(0).Height + types.Uint64(nullBlkCount) + 1}, nil)
+	}
+	// Wait for the scheduler to stop, so it does not mine again.
+	<-ctx.Done()
+	return err == nil
+}
+
+type headReader struct {
+	head block.TipSet
+}
+
+func (r *headReader) GetHead() block.TipSetKey {
+	return r.head.Key()
+}
+
+func (r *headReader) GetTipSet(block.TipSetKey) (block.TipSet, error) {
+	return r.head, nil
+}
+
 func newAPI(t *testing.T) (bapi.API, *node.Node) {
 	seed := node.MakeChainSeed(t, node.TestGenCfg)
 	builderOpts := []node.BuilderOpt{}
@@ -144,9 +220,11 @@ func newAPI(t *testing.T) (bapi.API, *node.Node) {
 		nd.AddNewBlock,
 		nd.Chain().ChainReader,
 		nd.IsMining,
+		nd.MiningStopHeight,
+		nd.MiningPaused,
 		bt,
 		nd.SetupMining,
-		nd.StartMining,
+		nd.StartMiningUntil,
 		nd.StopMining,
 		nd.CreateMiningWorker), nd
 }
@@ -10,17 +10,30 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
-// API here is the API for a retrieval client.
+// API here is the API for a retrieval client and miner.
 type API struct {
 	rc *Client
+	rm *Miner
 }
 
-// NewAPI creates a new API for a retrieval client.
-func NewAPI(rc *Client) API {
-	return API{rc: rc}
+// NewAPI creates a new API for a retrieval client and miner.
+func NewAPI(rc *Client, rm *Miner) API {
+	return API{rc: rc, rm: rm}
 }
 
 // RetrievePiece retrieves bytes referenced by CID pieceCID
 func (a *API) RetrievePiece(ctx context.Context, pieceCID cid.Cid, mpid peer.ID, minerAddr address.Address) (io.ReadCloser, error) {
 	return a.rc.RetrievePiece(ctx, mpid, pieceCID)
 }
+
+// AuditPiece challenges the miner to prove it still holds the piece with CID
+// pieceCID, given the client's own copy of its bytes.
+func (a *API) AuditPiece(ctx context.Context, pieceCID cid.Cid, mpid peer.ID, data []byte) (*AuditResult, error) {
+	return a.rc.AuditPiece(ctx, mpid, pieceCID, data)
+}
+
+// DropPiece makes this node's retrieval miner behave as if it lost the piece
+// with CID pieceCID.
+func (a *API) DropPiece(pieceCID cid.Cid) {
+	a.rm.DropPiece(pieceCID)
+}
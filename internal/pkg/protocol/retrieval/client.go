@@ -3,6 +3,9 @@ package retrieval
 import (
 	"bytes"
 	"context"
+	"crypto/rand"
+	"encoding/binary"
+	"fmt"
 	"io"
 	"io/ioutil"
 	"time"
@@ -23,6 +26,16 @@ import (
 // succeed.
 const RetrievePieceChunkSize = 256 << 8
 
+// AuditChallengeLength is the number of bytes of a piece an audit challenges
+// the miner to hash.
+const AuditChallengeLength = 256
+
+const auditNonceLength = 32
+
+// ErrPieceNotFound is returned by RetrievePiece when the miner does not hold
+// the requested piece.
+var ErrPieceNotFound = errors.New("miner does not have the piece")
+
 type clientPorcelainAPI interface {
 	PingMinerWithTimeout(ctx context.Context, p peer.ID, to time.Duration) error
 }
@@ -73,6 +86,9 @@ func (sc *Client) RetrievePiece(ctx context.Context, minerPeerID peer.ID, pieceC
 		return nil, errors.Wrap(err, "failed to read response message from stream")
 	}
 
+	if res.Status == NotFound {
+		return nil, errors.Wrapf(ErrPieceNotFound, "could not retrieve piece %s - error from miner: %s", pieceCID, res.ErrorMessage)
+	}
 	if res.Status != Success {
 		return nil, errors.Errorf("could not retrieve piece - error from miner: %s", res.ErrorMessage)
 	}
@@ -97,6 +113,73 @@ func (sc *Client) RetrievePiece(ctx context.Context, minerPeerID peer.ID, pieceC
 	return buffered, nil
 }
 
+// AuditPiece challenges a miner to prove it still holds a piece. The client
+// picks a random range of its local copy of the piece, data, and a random nonce,
+// and compares the miner's digest of that range with its own.
+func (sc *Client) AuditPiece(ctx context.Context, minerPeerID peer.ID, pieceCID cid.Cid, data []byte) (*AuditResult, error) {
+	req, err := newAuditPieceRequest(pieceCID, data)
+	if err != nil {
+		return nil, err
+	}
+	result := &AuditResult{Offset: req.Offset, Length: req.Length}
+
+	err = sc.api.PingMinerWithTimeout(ctx, minerPeerID, 15*time.Second)
+	if err == net.ErrPingSelf {
+		return nil, errors.New("attempting to audit a piece held by self. Please use a separate go-filecoin node as client")
+	}
+	if err != nil {
+		return nil, err
+	}
+	s, err := sc.host.NewStream(ctx, minerPeerID, auditProtocol)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to create stream to retrieval miner")
+	}
+	defer sc.safeCloseStream(s)
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(&req); err != nil {
+		return nil, errors.Wrap(err, "failed to write request message to stream")
+	}
+
+	var res AuditPieceResponse
+	if err := cbu.NewMsgReader(s).ReadMsg(&res); err != nil {
+		return nil, errors.Wrap(err, "failed to read response message from stream")
+	}
+
+	switch {
+	case res.Status != Success:
+		result.Reason = fmt.Sprintf("error from miner: %s", res.ErrorMessage)
+	case !bytes.Equal(res.Digest, AuditDigest(req.Nonce, data[req.Offset:req.Offset+req.Length])):
+		result.Reason = "digest from miner does not match the piece"
+	default:
+		result.Passed = true
+	}
+	return result, nil
+}
+
+func newAuditPieceRequest(pieceCID cid.Cid, data []byte) (AuditPieceRequest, error) {
+	req := AuditPieceRequest{
+		PieceRef: pieceCID,
+		Nonce:    make([]byte, auditNonceLength),
+	}
+	if _, err := rand.Read(req.Nonce); err != nil {
+		return AuditPieceRequest{}, errors.Wrap(err, "failed to generate nonce")
+	}
+
+	size := uint64(len(data))
+	req.Length = AuditChallengeLength
+	if size < req.Length {
+		req.Length = size
+	}
+	if size > req.Length {
+		var b [8]byte
+		if _, err := rand.Read(b[:]); err != nil {
+			return AuditPieceRequest{}, errors.Wrap(err, "failed to generate offset")
+		}
+		req.Offset = binary.BigEndian.Uint64(b[:]) % (size - req.Length + 1)
+	}
+	return req, nil
+}
+
 func (sc *Client) safeCloseStream(stream inet.Stream) {
 	if err := stream.Close(); err != nil {
 		log.Errorf("error closing stream: %s", err)
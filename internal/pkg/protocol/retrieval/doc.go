@@ -5,4 +5,11 @@
 // 3. MINER sends CLIENT a RetrievePieceResponse with Status set to Success if it has PieceRef in a sealed sector
 // 4. MINER sends CLIENT RetrievePieceChunks until all data associated with PieceRef has been sent
 // 5. CLIENT reads RetrievePieceChunk from stream until EOF and then closes stream
+//
+// Clients audit that a miner still holds a piece with a spot-check:
+//
+// 1. CLIENT opens /fil/retrieval/audit/0.0.0 stream to MINER
+// 2. CLIENT sends MINER an AuditPieceRequest for a random range of PieceRef and a random nonce
+// 3. MINER sends CLIENT an AuditPieceResponse with the digest of the nonce and the bytes in that range
+// 4. CLIENT compares the digest with the one it computes from its own copy of the piece
 package retrieval
@@ -1,39 +1,67 @@
 package retrieval
 
 import (
+	"context"
+	"crypto/sha256"
+	"io"
 	"io/ioutil"
+	"sync"
 
+	"github.com/ipfs/go-cid"
 	logging "github.com/ipfs/go-log"
 	host "github.com/libp2p/go-libp2p-core/host"
 	inet "github.com/libp2p/go-libp2p-core/network"
 	"github.com/libp2p/go-libp2p-core/protocol"
+	"github.com/pkg/errors"
 
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
 	cbu "github.com/filecoin-project/go-filecoin/internal/pkg/cborutil"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/sectorbuilder"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
 
 var log = logging.Logger("/fil/retrieval")
 
 const retrievalFreeProtocol = protocol.ID("/fil/retrieval/free/0.0.0")
 
+const auditProtocol = protocol.ID("/fil/retrieval/audit/0.0.0")
+
+// errPieceNotHeld is the cause of the readPiece errors for pieces the miner
+// does not hold.
+var errPieceNotHeld = errors.New("piece is not held by the miner")
+
 // TODO: better name
 type minerNode interface {
 	Host() host.Host
+	MiningAddress() (address.Address, error)
 	SectorBuilder() sectorbuilder.SectorBuilder
 }
 
+type minerPorcelainAPI interface {
+	DealsLs(ctx context.Context) (<-chan *porcelain.StorageDealLsResult, error)
+}
+
 // Miner serves requests for pieces from RetrievalClients.
 type Miner struct {
 	node minerNode
+	api  minerPorcelainAPI
+
+	// dropped holds the pieces the miner pretends to have lost, see DropPiece.
+	droppedLk sync.Mutex
+	dropped   map[cid.Cid]struct{}
 }
 
 // NewMiner is used to create a Miner and bind a handling function to the piece retrieval protocol.
-func NewMiner(nd minerNode) *Miner {
+func NewMiner(nd minerNode, api minerPorcelainAPI) *Miner {
 	rm := &Miner{
-		node: nd,
+		node:    nd,
+		api:     api,
+		dropped: make(map[cid.Cid]struct{}),
 	}
 
 	nd.Host().SetStreamHandler(retrievalFreeProtocol, rm.handleRetrievePieceForFree)
+	nd.Host().SetStreamHandler(auditProtocol, rm.handleAuditPiece)
 
 	return rm
 }
@@ -47,25 +75,33 @@ func (rm *Miner) handleRetrievePieceForFree(s inet.Stream) {
 		return
 	}
 
-	reader, err := rm.node.SectorBuilder().ReadPieceFromSealedSector(req.PieceRef)
-	if err != nil {
-		log.Warnf("failed to obtain a reader for piece with CID %s: %s", req.PieceRef.String(), err)
-
+	writeFailure := func(status RetrievePieceStatus, err error) {
 		resp := RetrievePieceResponse{
-			Status:       Failure,
+			Status:       status,
 			ErrorMessage: err.Error(),
 		}
 
 		if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
 			log.Warnf("failed to write response for piece with CID %s: %s", req.PieceRef.String(), err)
 		}
+	}
 
+	reader, err := rm.readPiece(req.PieceRef)
+	if err != nil {
+		log.Warnf("failed to obtain a reader for piece with CID %s: %s", req.PieceRef.String(), err)
+		if errors.Cause(err) == errPieceNotHeld {
+			writeFailure(NotFound, err)
+		} else {
+			writeFailure(Failure, err)
+		}
 		return
 	}
 
 	bs, err := ioutil.ReadAll(reader)
 	if err != nil {
 		log.Errorf("failed to read all bytes: %s", err)
+		writeFailure(Failure, err)
+		return
 	}
 
 	resp := RetrievePieceResponse{
@@ -94,3 +130,118 @@ func (rm *Miner) handleRetrievePieceForFree(s inet.Stream) {
 		}
 	}
 }
+
+func (rm *Miner) handleAuditPiece(s inet.Stream) {
+	defer s.Close() // nolint: errcheck
+
+	var req AuditPieceRequest
+	if err := cbu.NewMsgReader(s).ReadMsg(&req); err != nil {
+		log.Errorf("failed to read piece audit request: %s", err)
+		return
+	}
+
+	digest, err := rm.auditPiece(req)
+	resp := AuditPieceResponse{
+		Status: Success,
+		Digest: digest,
+	}
+	if err != nil {
+		log.Warnf("failed to audit piece with CID %s: %s", req.PieceRef.String(), err)
+		resp = AuditPieceResponse{
+			Status:       Failure,
+			ErrorMessage: err.Error(),
+		}
+	}
+
+	if err := cbu.NewMsgWriter(s).WriteMsg(&resp); err != nil {
+		log.Warnf("failed to write audit response for piece with CID %s: %s", req.PieceRef.String(), err)
+	}
+}
+
+func (rm *Miner) auditPiece(req AuditPieceRequest) ([]byte, error) {
+	reader, err := rm.readPiece(req.PieceRef)
+	if err != nil {
+		return nil, err
+	}
+	bs, err := ioutil.ReadAll(reader)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read piece")
+	}
+	if req.Offset+req.Length > uint64(len(bs)) {
+		return nil, errors.Errorf("range [%d, %d) is out of bounds of the %d byte piece", req.Offset, req.Offset+req.Length, len(bs))
+	}
+	return AuditDigest(req.Nonce, bs[req.Offset:req.Offset+req.Length]), nil
+}
+
+// DropPiece makes the miner behave as if it lost the data of a piece: it is no
+// longer retrievable and audits of it fail. It lets tests simulate data loss.
+func (rm *Miner) DropPiece(pieceCid cid.Cid) {
+	rm.droppedLk.Lock()
+	defer rm.droppedLk.Unlock()
+	rm.dropped[pieceCid] = struct{}{}
+}
+
+func (rm *Miner) readPiece(pieceCid cid.Cid) (io.Reader, error) {
+	rm.droppedLk.Lock()
+	_, dropped := rm.dropped[pieceCid]
+	rm.droppedLk.Unlock()
+	if dropped {
+		return nil, errors.Wrapf(errPieceNotHeld, "piece %s has been dropped", pieceCid)
+	}
+
+	reader, err := rm.node.SectorBuilder().ReadPieceFromSealedSector(pieceCid)
+	if err == nil {
+		return reader, nil
+	}
+
+	// The sector builder fails the same way for a piece it does not have and
+	// for one it could not read, so the miner's deals tell the two apart.
+	held, heldErr := rm.holdsPiece(context.Background(), pieceCid)
+	if heldErr != nil {
+		return nil, errors.Wrapf(heldErr, "failed to read piece %s (%s) and to look up its deal", pieceCid, err)
+	}
+	if !held {
+		return nil, errors.Wrapf(errPieceNotHeld, "no deal stores piece %s (%s)", pieceCid, err)
+	}
+	return nil, errors.Wrapf(err, "failed to read piece %s", pieceCid)
+}
+
+// holdsPiece returns true if the miner accepted a deal for the piece and has
+// staged its data into a sector.
+func (rm *Miner) holdsPiece(ctx context.Context, pieceCid cid.Cid) (bool, error) {
+	minerAddr, err := rm.node.MiningAddress()
+	if err != nil {
+		// a node without a miner stores no pieces
+		return false, nil
+	}
+
+	dealCh, err := rm.api.DealsLs(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	// drain the channel so that the lister does not block on a send
+	held := false
+	for result := range dealCh {
+		if result.Err != nil {
+			return false, result.Err
+		}
+		deal := result.Deal
+		if deal.Miner != minerAddr || deal.Proposal == nil || deal.Response == nil || !deal.Proposal.PieceRef.Equals(pieceCid) {
+			continue
+		}
+		if deal.Response.State == storagedeal.Staged || deal.Response.State == storagedeal.Complete {
+			held = true
+		}
+	}
+	return held, nil
+}
+
+// AuditDigest is the answer to an audit challenge with nonce over data, the
+// challenged range of a piece.
+func AuditDigest(nonce []byte, data []byte) []byte {
+	h := sha256.New()
+	h.Write(nonce) // nolint: errcheck
+	h.Write(data)  // nolint: errcheck
+	return h.Sum(nil)
+}
@@ -14,6 +14,8 @@ func init() {
 	encoding.RegisterIpldCborType(RetrievePieceRequest{})
 	encoding.RegisterIpldCborType(RetrievePieceResponse{})
 	encoding.RegisterIpldCborType(RetrievePieceChunk{})
+	encoding.RegisterIpldCborType(AuditPieceRequest{})
+	encoding.RegisterIpldCborType(AuditPieceResponse{})
 }
 
 //
@@ -27,3 +29,11 @@ func init() {
 //
 // Encoding/Decoding impls for RetrievePieceChunk
 //
+
+//
+// Encoding/Decoding impls for AuditPieceRequest
+//
+
+//
+// Encoding/Decoding impls for AuditPieceResponse
+//
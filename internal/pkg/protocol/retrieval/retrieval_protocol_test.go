@@ -7,10 +7,13 @@ import (
 
 	"github.com/ipfs/go-cid"
 	"github.com/libp2p/go-libp2p-core/peer"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
 	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/node"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/retrieval"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
@@ -39,6 +42,85 @@ func TestRetrievalProtocolPieceNotFound(t *testing.T) {
 	require.Error(t, err)
 }
 
+func TestRetrievePieceNotHeldByMiner(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	minerNode, clientNode, minerAddr, _ := configureMinerAndClient(t)
+
+	require.NoError(t, minerNode.StartMining(ctx))
+	defer minerNode.StopMining(ctx)
+
+	someRandomCid := types.NewCidForTestGetter()()
+
+	minerPID, err := clientNode.PorcelainAPI.MinerGetPeerID(ctx, minerAddr)
+	require.NoError(t, err)
+
+	_, err = retrievePieceBytes(ctx, clientNode.RetrievalProtocol.RetrievalAPI, someRandomCid, minerPID, minerAddr)
+	assert.Equal(t, retrieval.ErrPieceNotFound, errors.Cause(err))
+}
+
+func TestRetrievePieceHeldButUnreadable(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	minerNode, clientNode, minerAddr, _ := configureMinerAndClient(t)
+
+	require.NoError(t, minerNode.StartMining(ctx))
+	defer minerNode.StopMining(ctx)
+
+	// The miner has a deal for the piece, but its sector builder cannot read it.
+	newCid := types.NewCidForTestGetter()
+	pieceCid := newCid()
+	require.NoError(t, minerNode.PorcelainAPI.DealPut(&storagedeal.Deal{
+		Miner:    minerAddr,
+		Proposal: &storagedeal.SignedProposal{Proposal: storagedeal.Proposal{PieceRef: pieceCid}},
+		Response: &storagedeal.SignedResponse{Response: storagedeal.Response{State: storagedeal.Complete, ProposalCid: newCid()}},
+	}))
+
+	minerPID, err := clientNode.PorcelainAPI.MinerGetPeerID(ctx, minerAddr)
+	require.NoError(t, err)
+
+	_, err = retrievePieceBytes(ctx, clientNode.RetrievalProtocol.RetrievalAPI, pieceCid, minerPID, minerAddr)
+	require.Error(t, err)
+	assert.NotEqual(t, retrieval.ErrPieceNotFound, errors.Cause(err))
+}
+
+func TestAuditPieceNotFound(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	minerNode, clientNode, minerAddr, _ := configureMinerAndClient(t)
+
+	require.NoError(t, minerNode.StartMining(ctx))
+	defer minerNode.StopMining(ctx)
+
+	someRandomCid := types.NewCidForTestGetter()()
+
+	minerPID, err := clientNode.PorcelainAPI.MinerGetPeerID(ctx, minerAddr)
+	require.NoError(t, err)
+
+	result, err := clientNode.RetrievalProtocol.RetrievalAPI.AuditPiece(ctx, someRandomCid, minerPID, []byte("HODLHODLHODL"))
+	require.NoError(t, err)
+	assert.False(t, result.Passed)
+	assert.Contains(t, result.Reason, "error from miner")
+	assert.Equal(t, uint64(12), result.Length)
+}
+
+func TestAuditDigest(t *testing.T) {
+	tf.UnitTest(t)
+
+	data := []byte("HODLHODLHODL")
+	digest := retrieval.AuditDigest([]byte("nonce"), data)
+	assert.Equal(t, digest, retrieval.AuditDigest([]byte("nonce"), data))
+	// A miner cannot answer a challenge without the nonce or the data.
+	assert.NotEqual(t, digest, retrieval.AuditDigest([]byte("other"), data))
+	assert.NotEqual(t, digest, retrieval.AuditDigest([]byte("nonce"), data[1:]))
+}
+
 func retrievePieceBytes(ctx context.Context, retrievalAPI *retrieval.API, data cid.Cid, minerPID peer.ID, addr address.Address) ([]byte, error) {
 	r, err := retrievalAPI.RetrievePiece(ctx, data, minerPID, addr)
 	if err != nil {
@@ -16,6 +16,9 @@ const (
 
 	// Success means that the piece could be retrieved from the miner
 	Success
+
+	// NotFound means that the miner does not hold the piece
+	NotFound
 )
 
 // RetrievePieceRequest represents a retrieval miner's request for content.
@@ -33,3 +36,30 @@ type RetrievePieceResponse struct {
 type RetrievePieceChunk struct {
 	Data []byte
 }
+
+// AuditPieceRequest challenges a miner to prove it holds a piece by hashing a
+// range of its bytes together with a nonce chosen by the client.
+type AuditPieceRequest struct {
+	PieceRef cid.Cid
+	Offset   uint64
+	Length   uint64
+	Nonce    []byte
+}
+
+// AuditPieceResponse answers an AuditPieceRequest. Digest is set when Status
+// is Success.
+type AuditPieceResponse struct {
+	Status       RetrievePieceStatus
+	ErrorMessage string
+	Digest       []byte
+}
+
+// AuditResult is the outcome of a spot-check of a piece held by a miner.
+type AuditResult struct {
+	// Offset and Length are the range of the piece that was challenged.
+	Offset uint64
+	Length uint64
+	Passed bool
+	// Reason explains why the audit did not pass.
+	Reason string
+}
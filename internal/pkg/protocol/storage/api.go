@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/ipfs/go-cid"
 
@@ -27,11 +28,21 @@ func (a *API) ProposeStorageDeal(ctx context.Context, data cid.Cid, miner addres
 	return a.sc.ProposeDeal(ctx, miner, data, askid, duration, allowDuplicates)
 }
 
+// RenewStorageDeal calls the storage client RenewDeal function
+func (a *API) RenewStorageDeal(ctx context.Context, prop cid.Cid, extend uint64) (*storagedeal.SignedResponse, error) {
+	return a.sc.RenewDeal(ctx, prop, extend)
+}
+
 // QueryStorageDeal calls the storage client QueryDeal function
 func (a *API) QueryStorageDeal(ctx context.Context, prop cid.Cid) (*storagedeal.SignedResponse, error) {
 	return a.sc.QueryDeal(ctx, prop)
 }
 
+// WaitForStorageDeal calls the storage client WaitForDeal function
+func (a *API) WaitForStorageDeal(ctx context.Context, prop cid.Cid, target storagedeal.State, interval time.Duration) (*storagedeal.SignedResponse, error) {
+	return a.sc.WaitForDeal(ctx, prop, target, interval)
+}
+
 // Payments calls the storage client LoadVouchersForDeal function
 func (a *API) Payments(ctx context.Context, dealCid cid.Cid) ([]*types.PaymentVoucher, error) {
 	return a.sc.LoadVouchersForDeal(ctx, dealCid)
@@ -62,6 +62,7 @@ type clientPorcelainAPI interface {
 	DAGGetFileSize(context.Context, cid.Cid) (uint64, error)
 	DAGCat(context.Context, cid.Cid) (io.Reader, error)
 	DealPut(*storagedeal.Deal) error
+	DealTranscriptPut(*storagedeal.Transcript) error
 	DealsLs(context.Context) (<-chan *porcelain.StorageDealLsResult, error)
 	MinerGetAsk(ctx context.Context, minerAddr address.Address, askID uint64) (miner.Ask, error)
 	MinerGetSectorSize(ctx context.Context, minerAddr address.Address) (*types.BytesAmount, error)
@@ -73,6 +74,17 @@ type clientPorcelainAPI interface {
 	WalletDefaultAddress() (address.Address, error)
 }
 
+// NegotiationError is returned when a signed proposal was made but no deal
+// came of it. The transcript of the negotiation is kept under ProposalCid.
+type NegotiationError struct {
+	ProposalCid cid.Cid
+	Err         error
+}
+
+func (e *NegotiationError) Error() string {
+	return fmt.Sprintf("proposal %s: %s", e.ProposalCid, e.Err)
+}
+
 // Client is used to make deals directly with storage miners.
 type Client struct {
 	api                 clientPorcelainAPI
@@ -140,7 +152,6 @@ func (smc *Client) ProposeDeal(ctx context.Context, miner address.Address, data
 	if err != nil {
 		return nil, errors.Wrap(err, "failed to get ask price")
 	}
-	price := ask.Price
 
 	headKey := smc.api.ChainHeadKey()
 	head, err := smc.api.ChainTipSet(headKey)
@@ -152,35 +163,100 @@ func (smc *Client) ProposeDeal(ctx context.Context, miner address.Address, data
 	if err != nil {
 		return nil, errors.Wrapf(err, "failed to get height of tipset: %s", headKey.String())
 	}
-	chainHeight := types.NewBlockHeight(h)
 
-	fromAddress, err := smc.api.WalletDefaultAddress()
+	proposal := &storagedeal.Proposal{
+		PieceRef:     data,
+		Size:         types.NewBytesAmount(pieceSize),
+		TotalPrice:   ask.Price.MulBigInt(big.NewInt(int64(pieceSize * duration))),
+		Duration:     duration,
+		MinerAddress: miner,
+	}
+
+	if smc.isMaybeDupDeal(ctx, proposal) && !allowDuplicates {
+		return nil, Errors[ErrDuplicateDeal]
+	}
+
+	return smc.negotiate(ctx, pid, minerAlive, headKey, h, proposal, pieceCommitmentResponse.CommP)
+}
+
+// RenewDeal proposes to the same miner a deal for the piece of the deal with
+// the given proposal cid, covering the extend blocks after the original deal
+// ends. The renewal refers to the original deal, so the miner keeps the piece
+// it already holds instead of fetching and sealing it again, and the piece
+// commitment of the original deal is reused. The extension is priced per
+// byte and block like the original deal, and its payments start when the
+// original deal ends.
+func (smc *Client) RenewDeal(ctx context.Context, proposalCid cid.Cid, extend uint64) (*storagedeal.SignedResponse, error) {
+	if extend == 0 {
+		return nil, errors.New("must extend the deal by at least one block")
+	}
+
+	deal, err := smc.api.DealGet(ctx, proposalCid)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "failed to fetch deal: %s", proposalCid)
+	}
+	if deal.Response.State == storagedeal.Rejected || deal.Response.State == storagedeal.Failed {
+		return nil, fmt.Errorf("cannot renew %s deal %s", deal.Response.State, proposalCid)
 	}
 
-	minerOwner, err := smc.api.MinerGetOwnerAddress(ctx, miner)
+	headKey := smc.api.ChainHeadKey()
+	head, err := smc.api.ChainTipSet(headKey)
 	if err != nil {
-		return nil, err
+		return nil, errors.Wrapf(err, "failed to get head tipset: %s", headKey.String())
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to get height of tipset: %s", headKey.String())
 	}
 
-	minerWorker, err := smc.api.MinerGetWorkerAddress(ctx, miner, headKey)
+	end := deal.StartHeight + deal.Proposal.Duration
+	if end <= h {
+		return nil, fmt.Errorf("deal %s expired at height %d", proposalCid, end)
+	}
+
+	pid, err := smc.api.MinerGetPeerID(ctx, deal.Miner)
 	if err != nil {
 		return nil, err
 	}
 
-	totalPrice := price.MulBigInt(big.NewInt(int64(pieceSize * duration)))
+	minerAlive := make(chan error, 1)
+	go func() {
+		defer close(minerAlive)
+		minerAlive <- smc.api.PingMinerWithTimeout(ctx, pid, 15*time.Second)
+	}()
 
+	original := deal.Proposal.Proposal
 	proposal := &storagedeal.Proposal{
-		PieceRef:     data,
-		Size:         types.NewBytesAmount(pieceSize),
-		TotalPrice:   totalPrice,
-		Duration:     duration,
-		MinerAddress: miner,
+		PieceRef:     original.PieceRef,
+		Size:         original.Size,
+		TotalPrice:   original.TotalPrice.MulBigInt(big.NewInt(int64(extend))).DivCeil(types.NewAttoFIL(big.NewInt(int64(original.Duration)))),
+		Duration:     extend,
+		MinerAddress: deal.Miner,
+		Renews:       &proposalCid,
 	}
 
-	if smc.isMaybeDupDeal(ctx, proposal) && !allowDuplicates {
-		return nil, Errors[ErrDuplicateDeal]
+	return smc.negotiate(ctx, pid, minerAlive, headKey, end, proposal, deal.CommP)
+}
+
+// negotiate pays for proposal from startHeight on, sends it to the miner at
+// pid and records the deal starting at startHeight if the miner accepts it.
+func (smc *Client) negotiate(ctx context.Context, pid peer.ID, minerAlive <-chan error, headKey block.TipSetKey, startHeight uint64, proposal *storagedeal.Proposal, commP types.CommP) (*storagedeal.SignedResponse, error) {
+	miner := proposal.MinerAddress
+	paymentStart := types.NewBlockHeight(startHeight)
+
+	fromAddress, err := smc.api.WalletDefaultAddress()
+	if err != nil {
+		return nil, err
+	}
+
+	minerOwner, err := smc.api.MinerGetOwnerAddress(ctx, miner)
+	if err != nil {
+		return nil, err
+	}
+
+	minerWorker, err := smc.api.MinerGetWorkerAddress(ctx, miner, headKey)
+	if err != nil {
+		return nil, err
 	}
 
 	// see if we managed to connect to the miner
@@ -195,17 +271,18 @@ func (smc *Client) ProposeDeal(ctx context.Context, miner address.Address, data
 	proposal.Payment.Payer = fromAddress
 
 	// create payment information
-	if totalPrice.GreaterThan(types.ZeroAttoFIL) {
+	if proposal.TotalPrice.GreaterThan(types.ZeroAttoFIL) {
 		cpResp, err := smc.api.CreatePayments(ctx, porcelain.CreatePaymentsParams{
 			From:            fromAddress,
 			To:              minerOwner,
-			Value:           totalPrice,
-			Duration:        duration,
+			Value:           proposal.TotalPrice,
+			Duration:        proposal.Duration,
 			MinerAddress:    miner,
-			CommP:           pieceCommitmentResponse.CommP,
+			CommP:           commP,
 			PaymentInterval: VoucherInterval,
-			PieceSize:       types.NewBytesAmount(pieceSize),
-			ChannelExpiry:   *chainHeight.Add(types.NewBlockHeight(duration + ChannelExpiryInterval)),
+			PaymentStart:    paymentStart,
+			PieceSize:       proposal.Size,
+			ChannelExpiry:   *paymentStart.Add(types.NewBlockHeight(proposal.Duration + ChannelExpiryInterval)),
 			GasPrice:        types.NewAttoFIL(big.NewInt(CreateChannelGasPrice)),
 			GasLimit:        types.NewGasUnits(CreateChannelGasLimit),
 		})
@@ -223,31 +300,56 @@ func (smc *Client) ProposeDeal(ctx context.Context, miner address.Address, data
 	if err != nil {
 		return nil, err
 	}
+	proposalCid, err := convert.ToCid(signedProposal)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cid of proposal")
+	}
+
+	transcript := &storagedeal.Transcript{ProposalCid: proposalCid, Miner: miner}
+	defer smc.saveTranscript(transcript)
+	transcript.Record("proposal signed", "by %s: piece %s, size %s, price %s, duration %d", fromAddress, proposal.PieceRef, proposal.Size, proposal.TotalPrice, proposal.Duration)
+	if proposal.Payment.Channel != nil {
+		transcript.Record("payment created", "channel %s with %d vouchers", proposal.Payment.Channel, len(proposal.Payment.Vouchers))
+	}
 
 	// send proposal
+	transcript.Record("proposal sent", "to %s at peer %s", miner, pid.Pretty())
 	var response storagedeal.SignedResponse
 	// We reset the context to not timeout to allow large file transfers
 	// to complete.
 	err = smc.ProtocolRequestFunc(ctx, makeDealProtocol, pid, smc.host, signedProposal, &response)
 	if err != nil {
-		return nil, errors.Wrap(err, "error sending proposal")
+		transcript.Record("proposal failed", "%s", err)
+		return nil, &NegotiationError{ProposalCid: proposalCid, Err: errors.Wrap(err, "error sending proposal")}
 	}
+	transcript.Record("response received", "state %s, message %q", response.State, response.Message)
 
-	if err := smc.checkDealResponse(ctx, &response, minerWorker); err != nil {
-		return nil, errors.Wrap(err, "response check failed")
+	if err := smc.checkDealResponse(ctx, &response, minerWorker, proposal, transcript); err != nil {
+		transcript.Record("deal not made", "%s", err)
+		return nil, &NegotiationError{ProposalCid: proposalCid, Err: errors.Wrap(err, "response check failed")}
 	}
 
 	// Note: currently the miner requests the data out of band
 
-	if err := smc.recordResponse(ctx, &response, miner, signedProposal, pieceCommitmentResponse.CommP); err != nil {
-		return nil, errors.Wrap(err, "failed to track response")
+	if err := smc.recordResponse(ctx, &response, miner, signedProposal, commP, startHeight); err != nil {
+		transcript.Record("deal not made", "%s", err)
+		return nil, &NegotiationError{ProposalCid: proposalCid, Err: errors.Wrap(err, "failed to track response")}
 	}
+	transcript.Record("deal recorded", "starting at height %d", startHeight)
 	smc.log.Debugf("proposed deal for: %s, %v\n", miner.String(), proposal)
 
 	return &response, nil
 }
 
-func (smc *Client) recordResponse(ctx context.Context, resp *storagedeal.SignedResponse, miner address.Address, p *storagedeal.SignedProposal, commP types.CommP) error {
+// saveTranscript persists a negotiation transcript. A transcript is a
+// debugging aid, so failing to save one does not fail the negotiation.
+func (smc *Client) saveTranscript(transcript *storagedeal.Transcript) {
+	if err := smc.api.DealTranscriptPut(transcript); err != nil {
+		smc.log.Errorf("failed to save transcript of proposal %s: %s", transcript.ProposalCid, err)
+	}
+}
+
+func (smc *Client) recordResponse(ctx context.Context, resp *storagedeal.SignedResponse, miner address.Address, p *storagedeal.SignedProposal, commP types.CommP, startHeight uint64) error {
 	proposalCid, err := convert.ToCid(p)
 	if err != nil {
 		return errors.New("failed to get cid of proposal")
@@ -264,14 +366,15 @@ func (smc *Client) recordResponse(ctx context.Context, resp *storagedeal.SignedR
 	}
 
 	return smc.api.DealPut(&storagedeal.Deal{
-		Miner:    miner,
-		Proposal: p,
-		Response: resp,
-		CommP:    commP,
+		Miner:       miner,
+		Proposal:    p,
+		Response:    resp,
+		CommP:       commP,
+		StartHeight: startHeight,
 	})
 }
 
-func (smc *Client) checkDealResponse(ctx context.Context, resp *storagedeal.SignedResponse, workerAddr address.Address) error {
+func (smc *Client) checkDealResponse(ctx context.Context, resp *storagedeal.SignedResponse, workerAddr address.Address, proposal *storagedeal.Proposal, transcript *storagedeal.Transcript) error {
 	valid, err := resp.VerifySignature(workerAddr)
 	if err != nil {
 		return errors.Wrap(err, "Could not verify response signature")
@@ -280,6 +383,7 @@ func (smc *Client) checkDealResponse(ctx context.Context, resp *storagedeal.Sign
 	if !valid {
 		return errors.New("Response signature is invalid")
 	}
+	transcript.Record("response signature verified", "signed by worker %s", workerAddr)
 
 	switch resp.State {
 	case storagedeal.Rejected:
@@ -288,6 +392,13 @@ func (smc *Client) checkDealResponse(ctx context.Context, resp *storagedeal.Sign
 		return fmt.Errorf("deal failed: %s", resp.Message)
 	case storagedeal.Accepted:
 		return nil
+	case storagedeal.Complete:
+		// A renewed piece is already sealed, so the miner completes the
+		// renewal as soon as it accepts it.
+		if proposal.Renews != nil {
+			return nil
+		}
+		return fmt.Errorf("invalid proposal response: %s", resp.State)
 	default:
 		return fmt.Errorf("invalid proposal response: %s", resp.State)
 	}
@@ -336,6 +447,34 @@ func (smc *Client) QueryDeal(ctx context.Context, proposalCid cid.Cid) (*storage
 	return &resp, nil
 }
 
+// WaitForDeal queries the deal with the given proposal cid every interval
+// until it reaches or passes the target state, and returns the response that
+// did. It fails as soon as the deal is rejected or fails instead, and when
+// ctx is done.
+func (smc *Client) WaitForDeal(ctx context.Context, proposalCid cid.Cid, target storagedeal.State, interval time.Duration) (*storagedeal.SignedResponse, error) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		resp, err := smc.QueryDeal(ctx, proposalCid)
+		if err != nil {
+			return nil, err
+		}
+		if resp.State.Reached(target) {
+			return resp, nil
+		}
+		if resp.State.Terminal() {
+			return nil, errors.Errorf("deal %s is %s instead of %s: %s", proposalCid, resp.State, target, resp.Message)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, errors.Wrapf(ctx.Err(), "deal %s is still %s", proposalCid, resp.State)
+		case <-ticker.C:
+		}
+	}
+}
+
 func (smc *Client) isMaybeDupDeal(ctx context.Context, p *storagedeal.Proposal) bool {
 	dealsCh, err := smc.api.DealsLs(ctx)
 	if err != nil {
@@ -122,6 +122,26 @@ func TestProposeDeal(t *testing.T) {
 
 		assert.Equal(t, retrievedDeal.Response, dealResponse)
 	})
+
+	t.Run("and keeps a transcript of the negotiation", func(t *testing.T) {
+		transcript, ok := testAPI.transcripts[dealResponse.ProposalCid]
+		require.True(t, ok)
+		assert.Equal(t, minerAddr, transcript.Miner)
+
+		var steps []string
+		for _, entry := range transcript.Entries {
+			steps = append(steps, entry.Step)
+		}
+		assert.Equal(t, []string{
+			"proposal signed",
+			"payment created",
+			"proposal sent",
+			"response received",
+			"response signature verified",
+			"deal recorded",
+		}, steps)
+		assert.Contains(t, transcript.Entries[3].Detail, "accepted")
+	})
 }
 
 func TestProposeZeroPriceDeal(t *testing.T) {
@@ -253,9 +273,233 @@ func TestProposeDealFailsWhenSignatureIsInvalid(t *testing.T) {
 	assert.Contains(t, err.Error(), "signature is invalid")
 }
 
+func TestProposeDealKeepsTranscriptWhenRejected(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addressCreator := address.NewForTestGetter()
+
+	pieceSize := uint64(7)
+	pieceReader := bytes.NewReader(make([]byte, pieceSize))
+	testAPI := newTestClientAPI(t, pieceReader, pieceSize)
+	testNode := newTestClientNode(func(request interface{}) (interface{}, error) {
+		p, ok := request.(*storagedeal.SignedProposal)
+		require.True(t, ok)
+
+		pcid, err := convert.ToCid(p)
+		require.NoError(t, err)
+		resp := &storagedeal.SignedResponse{
+			Response: storagedeal.Response{
+				State:       storagedeal.Rejected,
+				Message:     "price too low",
+				ProposalCid: pcid,
+			},
+		}
+		require.NoError(t, resp.Sign(testAPI.signer, testAPI.worker))
+		return resp, nil
+	})
+
+	client := NewClient(th.NewFakeHost(), testAPI)
+	client.ProtocolRequestFunc = testNode.MakeTestProtocolRequest
+
+	_, err := client.ProposeDeal(ctx, addressCreator(), types.CidFromString(t, "somecid"), uint64(67), uint64(10000), false)
+	require.Error(t, err)
+	negErr, ok := err.(*NegotiationError)
+	require.True(t, ok)
+	assert.Contains(t, err.Error(), "deal rejected: price too low")
+	assert.Empty(t, testAPI.deals)
+
+	transcript, ok := testAPI.transcripts[negErr.ProposalCid]
+	require.True(t, ok)
+	last := transcript.Entries[len(transcript.Entries)-1]
+	assert.Equal(t, "deal not made", last.Step)
+	assert.Contains(t, last.Detail, "deal rejected: price too low")
+}
+
+func TestRenewDeal(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addressCreator := address.NewForTestGetter()
+
+	pieceSize := uint64(7)
+	pieceReader := bytes.NewReader(make([]byte, pieceSize))
+	testAPI := newTestClientAPI(t, pieceReader, pieceSize)
+
+	var proposal *storagedeal.SignedProposal
+	state, message := storagedeal.Accepted, "OK"
+	testNode := newTestClientNode(func(request interface{}) (interface{}, error) {
+		p, ok := request.(*storagedeal.SignedProposal)
+		require.True(t, ok)
+		proposal = p
+
+		pcid, err := convert.ToCid(p)
+		require.NoError(t, err)
+		resp := &storagedeal.SignedResponse{
+			Response: storagedeal.Response{
+				State:       state,
+				Message:     message,
+				ProposalCid: pcid,
+			},
+		}
+		require.NoError(t, resp.Sign(testAPI.signer, testAPI.worker))
+		return resp, nil
+	})
+
+	client := NewClient(th.NewFakeHost(), testAPI)
+	client.ProtocolRequestFunc = testNode.MakeTestProtocolRequest
+
+	dataCid := types.CidFromString(t, "somecid")
+	minerAddr := addressCreator()
+	original, err := client.ProposeDeal(ctx, minerAddr, dataCid, uint64(67), uint64(10000), false)
+	require.NoError(t, err)
+	originalDeal, err := testAPI.DealGet(ctx, original.ProposalCid)
+	require.NoError(t, err)
+	assert.Equal(t, testAPI.blockHeight, originalDeal.StartHeight)
+
+	// The original deal ends at 773 + 10000 = 10773.
+	testAPI.blockHeight = 1773
+
+	t.Run("extends the deal for the same piece", func(t *testing.T) {
+		state = storagedeal.Complete
+		defer func() { state = storagedeal.Accepted }()
+
+		renewal, err := client.RenewDeal(ctx, original.ProposalCid, 500)
+		require.NoError(t, err)
+
+		assert.Equal(t, dataCid, proposal.PieceRef)
+		assert.Equal(t, originalDeal.Proposal.Size, proposal.Size)
+		assert.Equal(t, minerAddr, proposal.MinerAddress)
+		assert.Equal(t, &original.ProposalCid, proposal.Renews)
+		assert.Equal(t, uint64(500), proposal.Duration)
+
+		// Only the extension is paid for, from the end of the original deal.
+		expectedTotalPrice := testAPI.askPrice.MulBigInt(big.NewInt(int64(pieceSize * 500)))
+		assert.Equal(t, expectedTotalPrice, proposal.TotalPrice)
+		assert.Equal(t, types.NewBlockHeight(10773), testAPI.paymentParams.PaymentStart)
+		assert.Equal(t, *types.NewBlockHeight(10773 + 500 + ChannelExpiryInterval), testAPI.paymentParams.ChannelExpiry)
+
+		renewalDeal, err := testAPI.DealGet(ctx, renewal.ProposalCid)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Complete, renewalDeal.Response.State)
+		assert.Equal(t, originalDeal.CommP, renewalDeal.CommP)
+		assert.Equal(t, uint64(10773), renewalDeal.StartHeight)
+		assert.Equal(t, uint64(10773+500), renewalDeal.StartHeight+renewalDeal.Proposal.Duration)
+	})
+
+	t.Run("surfaces the miner's rejection", func(t *testing.T) {
+		state, message = storagedeal.Rejected, "no room for renewals"
+		defer func() { state, message = storagedeal.Accepted, "OK" }()
+
+		_, err := client.RenewDeal(ctx, original.ProposalCid, 500)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "no room for renewals")
+	})
+
+	t.Run("fails for an expired deal", func(t *testing.T) {
+		testAPI.blockHeight = 10773
+		defer func() { testAPI.blockHeight = 1773 }()
+
+		_, err := client.RenewDeal(ctx, original.ProposalCid, 500)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "expired at height 10773")
+	})
+
+	t.Run("fails for an unknown deal", func(t *testing.T) {
+		_, err := client.RenewDeal(ctx, types.CidFromString(t, "unknown"), 500)
+		assert.Error(t, err)
+	})
+}
+
+func TestWaitForDeal(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+	addressCreator := address.NewForTestGetter()
+
+	pieceSize := uint64(7)
+	pieceReader := bytes.NewReader(make([]byte, pieceSize))
+	testAPI := newTestClientAPI(t, pieceReader, pieceSize)
+
+	// The deal moves to the next of states on every query.
+	var states []storagedeal.State
+	queries := 0
+	testNode := newTestClientNode(func(request interface{}) (interface{}, error) {
+		var pcid cid.Cid
+		state := storagedeal.Accepted
+		switch r := request.(type) {
+		case *storagedeal.SignedProposal:
+			var err error
+			pcid, err = convert.ToCid(r)
+			require.NoError(t, err)
+		case storagedeal.QueryRequest:
+			pcid = r.Cid
+			state = states[queries]
+			if queries < len(states)-1 {
+				queries++
+			}
+		default:
+			t.Fatalf("unexpected request %T", request)
+		}
+
+		resp := &storagedeal.SignedResponse{
+			Response: storagedeal.Response{
+				State:       state,
+				Message:     "OK",
+				ProposalCid: pcid,
+			},
+		}
+		require.NoError(t, resp.Sign(testAPI.signer, testAPI.worker))
+		return resp, nil
+	})
+
+	client := NewClient(th.NewFakeHost(), testAPI)
+	client.ProtocolRequestFunc = testNode.MakeTestProtocolRequest
+
+	deal, err := client.ProposeDeal(ctx, addressCreator(), types.CidFromString(t, "somecid"), uint64(67), uint64(10000), false)
+	require.NoError(t, err)
+
+	t.Run("waits until the deal reaches the state", func(t *testing.T) {
+		states, queries = []storagedeal.State{storagedeal.Accepted, storagedeal.Started, storagedeal.Staged}, 0
+
+		resp, err := client.WaitForDeal(ctx, deal.ProposalCid, storagedeal.Staged, time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Staged, resp.State)
+		assert.Equal(t, 2, queries)
+	})
+
+	t.Run("returns a deal past the state", func(t *testing.T) {
+		states, queries = []storagedeal.State{storagedeal.Complete}, 0
+
+		resp, err := client.WaitForDeal(ctx, deal.ProposalCid, storagedeal.Staged, time.Millisecond)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Complete, resp.State)
+	})
+
+	t.Run("fails fast when the deal fails", func(t *testing.T) {
+		states, queries = []storagedeal.State{storagedeal.Started, storagedeal.Failed, storagedeal.Complete}, 0
+
+		_, err := client.WaitForDeal(ctx, deal.ProposalCid, storagedeal.Complete, time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is failed instead of complete")
+		assert.Equal(t, 2, queries)
+	})
+
+	t.Run("fails when the context is done", func(t *testing.T) {
+		states, queries = []storagedeal.State{storagedeal.Started}, 0
+
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+		defer cancel()
+		_, err := client.WaitForDeal(ctx, deal.ProposalCid, storagedeal.Complete, time.Millisecond)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "is still started")
+	})
+}
+
 type clientTestAPI struct {
 	askPrice       types.AttoFIL
 	createdPayment bool
+	paymentParams  porcelain.CreatePaymentsParams
 	blockHeight    uint64
 	channelID      *types.ChannelID
 	msgCid         cid.Cid
@@ -265,6 +509,7 @@ type clientTestAPI struct {
 	perPayment     types.AttoFIL
 	testing        *testing.T
 	deals          map[cid.Cid]*storagedeal.Deal
+	transcripts    map[cid.Cid]*storagedeal.Transcript
 	pieceReader    io.Reader
 	pieceSize      uint64
 	signer         types.Signer
@@ -290,6 +535,7 @@ func newTestClientAPI(t *testing.T, pieceReader io.Reader, pieceSize uint64) *cl
 		signer:         mockSigner,
 		testing:        t,
 		deals:          make(map[cid.Cid]*storagedeal.Deal),
+		transcripts:    make(map[cid.Cid]*storagedeal.Transcript),
 		pieceReader:    pieceReader,
 		pieceSize:      pieceSize,
 	}
@@ -309,6 +555,7 @@ func (ctp *clientTestAPI) ChainTipSet(_ block.TipSetKey) (block.TipSet, error) {
 
 func (ctp *clientTestAPI) CreatePayments(ctx context.Context, config porcelain.CreatePaymentsParams) (*porcelain.CreatePaymentsReturn, error) {
 	ctp.createdPayment = true
+	ctp.paymentParams = config
 	resp := &porcelain.CreatePaymentsReturn{
 		CreatePaymentsParams: config,
 		Channel:              ctp.channelID,
@@ -433,6 +680,11 @@ func (ctp *clientTestAPI) DealPut(storageDeal *storagedeal.Deal) error {
 	return nil
 }
 
+func (ctp *clientTestAPI) DealTranscriptPut(transcript *storagedeal.Transcript) error {
+	ctp.transcripts[transcript.ProposalCid] = transcript
+	return nil
+}
+
 func (ctp *clientTestAPI) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, _ block.TipSetKey, params ...interface{}) ([][]byte, error) {
 	return [][]byte{{byte(types.TestProofsMode)}}, nil
 }
@@ -0,0 +1,100 @@
+package storage
+
+import (
+	"context"
+	"sort"
+
+	"github.com/ipfs/go-cid"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// faultSlasherPlumbing is the subset of the plumbing API that FaultSlasher needs.
+type faultSlasherPlumbing interface {
+	MessageEstimateGas(ctx context.Context, from, to address.Address, value types.AttoFIL, method types.MethodID, params ...interface{}) (*porcelain.GasEstimate, error)
+	MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, baseKey block.TipSetKey, params ...interface{}) ([][]byte, error)
+	MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error)
+}
+
+// FaultSlasher watches the chain for storage miners whose PoSt deadline has
+// passed without a PoSt and sends messages slashing them.
+type FaultSlasher struct {
+	plumbing faultSlasherPlumbing
+	from     address.Address
+
+	// slashed holds the late miners a slashing message has already been sent
+	// for, so that they are not sent another one on every new head.
+	slashed map[address.Address]struct{}
+}
+
+// NewFaultSlasher creates a FaultSlasher that sends slashing messages from
+// address `from`.
+func NewFaultSlasher(plumbing faultSlasherPlumbing, from address.Address) *FaultSlasher {
+	return &FaultSlasher{
+		plumbing: plumbing,
+		from:     from,
+		slashed:  make(map[address.Address]struct{}),
+	}
+}
+
+// OnNewHeaviestTipSet slashes every miner that is too late to submit its PoSt
+// as of tipset ts.
+func (fs *FaultSlasher) OnNewHeaviestTipSet(ctx context.Context, ts block.TipSet) error {
+	res, err := fs.plumbing.MessageQuery(ctx, address.Undef, address.StorageMarketAddress, storagemarket.GetLateMiners, ts.Key())
+	if err != nil {
+		return errors.Wrap(err, "query GetLateMiners method failed")
+	}
+	lateVal, err := abi.Deserialize(res[0], abi.MinerPoStStates)
+	if err != nil {
+		return errors.Wrap(err, "deserialization failed")
+	}
+	lateMiners, ok := lateVal.Val.(*map[string]uint64)
+	if !ok {
+		return errors.New("type assertion failed")
+	}
+
+	// Send in a stable order so the message nonces don't depend on map order.
+	var late []string
+	for addr := range *lateMiners {
+		late = append(late, addr)
+	}
+	sort.Strings(late)
+
+	stillLate := make(map[address.Address]struct{})
+	for _, addrStr := range late {
+		addr, err := address.NewFromString(addrStr)
+		if err != nil {
+			return err
+		}
+		stillLate[addr] = struct{}{}
+		if _, ok := fs.slashed[addr]; ok {
+			continue
+		}
+
+		gas, err := fs.plumbing.MessageEstimateGas(ctx, fs.from, addr, types.ZeroAttoFIL, miner.SlashStorageFault)
+		if err != nil {
+			return errors.Wrapf(err, "failed to estimate gas to slash miner %s", addr)
+		}
+		_, _, err = fs.plumbing.MessageSend(ctx, fs.from, addr, types.ZeroAttoFIL, gas.GasPrice, gas.GasLimit, miner.SlashStorageFault)
+		if err != nil {
+			return errors.Wrapf(err, "failed to slash miner %s", addr)
+		}
+		fs.slashed[addr] = struct{}{}
+		log.Infof("slashed miner %s for missing its PoSt", addr)
+	}
+
+	// Miners that are no longer late have been slashed or caught up.
+	for addr := range fs.slashed {
+		if _, ok := stillLate[addr]; !ok {
+			delete(fs.slashed, addr)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+package storage_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/ipfs/go-cid"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/app/go-filecoin/porcelain"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/miner"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+type slasherTestPlumbing struct {
+	t    *testing.T
+	late []address.Address
+	sent []address.Address
+}
+
+var slasherTestGas = porcelain.GasEstimate{GasLimit: types.NewGasUnits(123), GasPrice: types.NewGasPrice(2)}
+
+func (stp *slasherTestPlumbing) MessageEstimateGas(ctx context.Context, from, to address.Address, value types.AttoFIL, method types.MethodID, params ...interface{}) (*porcelain.GasEstimate, error) {
+	require.Equal(stp.t, miner.SlashStorageFault, method)
+	estimate := slasherTestGas
+	return &estimate, nil
+}
+
+func (stp *slasherTestPlumbing) MessageQuery(ctx context.Context, optFrom, to address.Address, method types.MethodID, _ block.TipSetKey, params ...interface{}) ([][]byte, error) {
+	states := map[string]uint64{}
+	for _, addr := range stp.late {
+		states[addr.String()] = miner.PoStStateUnrecoverable
+	}
+	val := abi.Value{Type: abi.MinerPoStStates, Val: &states}
+	bytes, err := val.Serialize()
+	require.NoError(stp.t, err)
+	return [][]byte{bytes}, nil
+}
+
+func (stp *slasherTestPlumbing) MessageSend(ctx context.Context, from, to address.Address, value types.AttoFIL, gasPrice types.AttoFIL, gasLimit types.GasUnits, method types.MethodID, params ...interface{}) (cid.Cid, chan error, error) {
+	require.Equal(stp.t, miner.SlashStorageFault, method)
+	require.Equal(stp.t, slasherTestGas.GasLimit, gasLimit)
+	require.Equal(stp.t, slasherTestGas.GasPrice, gasPrice)
+	stp.sent = append(stp.sent, to)
+	return cid.Undef, nil, nil
+}
+
+func TestFaultSlasher(t *testing.T) {
+	tf.UnitTest(t)
+	ctx := context.Background()
+	addrGetter := address.NewForTestGetter()
+	from, minerA, minerB := addrGetter(), addrGetter(), addrGetter()
+
+	plumbing := &slasherTestPlumbing{t: t}
+	slasher := storage.NewFaultSlasher(plumbing, from)
+
+	require.NoError(t, slasher.OnNewHeaviestTipSet(ctx, block.UndefTipSet))
+	assert.Empty(t, plumbing.sent)
+
+	plumbing.late = []address.Address{minerA}
+	require.NoError(t, slasher.OnNewHeaviestTipSet(ctx, block.UndefTipSet))
+	assert.Equal(t, []address.Address{minerA}, plumbing.sent)
+
+	// A miner is only slashed once while it stays late.
+	plumbing.late = []address.Address{minerA, minerB}
+	require.NoError(t, slasher.OnNewHeaviestTipSet(ctx, block.UndefTipSet))
+	assert.Equal(t, []address.Address{minerA, minerB}, plumbing.sent)
+
+	// Once it is no longer late it can be slashed again.
+	plumbing.late = []address.Address{minerB}
+	require.NoError(t, slasher.OnNewHeaviestTipSet(ctx, block.UndefTipSet))
+	plumbing.late = []address.Address{minerA, minerB}
+	require.NoError(t, slasher.OnNewHeaviestTipSet(ctx, block.UndefTipSet))
+	assert.Equal(t, []address.Address{minerA, minerB, minerA}, plumbing.sent)
+}
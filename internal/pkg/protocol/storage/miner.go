@@ -172,6 +172,14 @@ func (sm *Miner) receiveStorageProposal(ctx context.Context, sp *storagedeal.Sig
 		return sm.rejectProposal(ctx, sp, fmt.Sprint("invalid deal signature"))
 	}
 
+	var renewed *storagedeal.Deal
+	if sp.Renews != nil {
+		renewed, err = sm.validateRenewal(ctx, sp)
+		if err != nil {
+			return sm.rejectProposal(ctx, sp, err.Error())
+		}
+	}
+
 	// compute expected total price for deal (storage price * duration * bytes)
 	price, err := sm.getStoragePrice()
 	if err != nil {
@@ -180,7 +188,7 @@ func (sm *Miner) receiveStorageProposal(ctx context.Context, sp *storagedeal.Sig
 
 	// skip payment validation (assume there is no payment) if miner is not charging for storage.
 	if price.GreaterThan(types.ZeroAttoFIL) {
-		if err := sm.validateDealPayment(ctx, sp, price); err != nil {
+		if err := sm.validateDealPayment(ctx, sp, price, renewed); err != nil {
 			return sm.rejectProposal(ctx, sp, err.Error())
 		}
 	}
@@ -191,10 +199,53 @@ func (sm *Miner) receiveStorageProposal(ctx context.Context, sp *storagedeal.Sig
 	}
 
 	// Payment is valid, everything else checks out, let's accept this proposal
+	if renewed != nil {
+		return sm.acceptRenewal(ctx, sp, renewed)
+	}
 	return sm.acceptProposal(ctx, sp)
 }
 
-func (sm *Miner) validateDealPayment(ctx context.Context, p *storagedeal.SignedProposal, price types.AttoFIL) error {
+// validateRenewal checks that p renews a complete, unexpired deal for the
+// same piece and client, and returns that deal.
+func (sm *Miner) validateRenewal(ctx context.Context, p *storagedeal.SignedProposal) (*storagedeal.Deal, error) {
+	renewed, err := sm.porcelainAPI.DealGet(ctx, *p.Renews)
+	if err != nil {
+		return nil, fmt.Errorf("unknown deal %s", p.Renews)
+	}
+
+	if renewed.Proposal.Payment.Payer != p.Payment.Payer {
+		return nil, fmt.Errorf("deal %s was proposed by another client", p.Renews)
+	}
+
+	if !renewed.Proposal.PieceRef.Equals(p.PieceRef) || !renewed.Proposal.Size.Equal(p.Size) {
+		return nil, fmt.Errorf("proposal is not for the piece of deal %s", p.Renews)
+	}
+
+	if renewed.Response.State != storagedeal.Complete {
+		return nil, fmt.Errorf("deal %s is %s, only complete deals can be renewed", p.Renews, renewed.Response.State)
+	}
+
+	head, err := sm.porcelainAPI.ChainTipSet(sm.porcelainAPI.ChainHeadKey())
+	if err != nil {
+		return nil, fmt.Errorf("could not access head tipset")
+	}
+	h, err := head.Height()
+	if err != nil {
+		return nil, fmt.Errorf("could not get current block height")
+	}
+
+	end := renewed.StartHeight + renewed.Proposal.Duration
+	if end <= h {
+		return nil, fmt.Errorf("deal %s expired at height %d", p.Renews, end)
+	}
+
+	return renewed, nil
+}
+
+// validateDealPayment checks that the payment of p covers its price. Payments
+// start at the current block height, or for a renewal when the renewed deal
+// ends.
+func (sm *Miner) validateDealPayment(ctx context.Context, p *storagedeal.SignedProposal, price types.AttoFIL, renewed *storagedeal.Deal) error {
 	if p.Size == nil {
 		return fmt.Errorf("proposed deal has no size")
 	}
@@ -222,16 +273,21 @@ func (sm *Miner) validateDealPayment(ctx context.Context, p *storagedeal.SignedP
 		return fmt.Errorf("payment channel does not contain enough funds (%s < %s)", channel.Amount.String(), expectedPrice.String())
 	}
 
-	// start with current block height
-	head, err := sm.porcelainAPI.ChainTipSet(sm.porcelainAPI.ChainHeadKey())
-	if err != nil {
-		return fmt.Errorf("could not access head tipset")
-	}
-	h, err := head.Height()
-	if err != nil {
-		return fmt.Errorf("could not get current block height")
+	// start with current block height, or the end of the renewed deal
+	var blockHeight *types.BlockHeight
+	if renewed != nil {
+		blockHeight = types.NewBlockHeight(renewed.StartHeight + renewed.Proposal.Duration)
+	} else {
+		head, err := sm.porcelainAPI.ChainTipSet(sm.porcelainAPI.ChainHeadKey())
+		if err != nil {
+			return fmt.Errorf("could not access head tipset")
+		}
+		h, err := head.Height()
+		if err != nil {
+			return fmt.Errorf("could not get current block height")
+		}
+		blockHeight = types.NewBlockHeight(h)
 	}
-	blockHeight := types.NewBlockHeight(h)
 
 	// require at least one payment
 	if len(p.Payment.Vouchers) < 1 {
@@ -349,10 +405,20 @@ func (sm *Miner) acceptProposal(ctx context.Context, p *storagedeal.SignedPropos
 		return nil, errors.Wrap(err, "could not sign deal response")
 	}
 
+	head, err := sm.porcelainAPI.ChainTipSet(sm.porcelainAPI.ChainHeadKey())
+	if err != nil {
+		return nil, errors.Wrap(err, "could not access head tipset")
+	}
+	startHeight, err := head.Height()
+	if err != nil {
+		return nil, errors.Wrap(err, "could not get current block height")
+	}
+
 	storageDeal := &storagedeal.Deal{
-		Miner:    sm.minerAddr,
-		Proposal: p,
-		Response: signed,
+		Miner:       sm.minerAddr,
+		Proposal:    p,
+		Response:    signed,
+		StartHeight: startHeight,
 	}
 
 	if err := sm.porcelainAPI.DealPut(storageDeal); err != nil {
@@ -365,6 +431,38 @@ func (sm *Miner) acceptProposal(ctx context.Context, p *storagedeal.SignedPropos
 	return signed, nil
 }
 
+// acceptRenewal accepts a proposal extending the deal renewed. The piece is
+// already sealed, so the renewal is complete as soon as it is accepted,
+// carries the proof of the renewed deal and starts when that deal ends.
+func (sm *Miner) acceptRenewal(ctx context.Context, p *storagedeal.SignedProposal, renewed *storagedeal.Deal) (*storagedeal.SignedResponse, error) {
+	proposalCid, err := convert.ToCid(p)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to get cid of proposal")
+	}
+
+	resp := storagedeal.Response{
+		State:       storagedeal.Complete,
+		ProposalCid: proposalCid,
+		ProofInfo:   renewed.Response.ProofInfo,
+	}
+	signed, err := sm.signResponse(ctx, resp)
+	if err != nil {
+		return nil, errors.Wrap(err, "could not sign deal response")
+	}
+
+	storageDeal := &storagedeal.Deal{
+		Miner:       sm.minerAddr,
+		Proposal:    p,
+		Response:    signed,
+		StartHeight: renewed.StartHeight + renewed.Proposal.Duration,
+	}
+	if err := sm.porcelainAPI.DealPut(storageDeal); err != nil {
+		return nil, errors.Wrap(err, "Could not persist miner deal")
+	}
+
+	return signed, nil
+}
+
 func (sm *Miner) rejectProposal(ctx context.Context, p *storagedeal.SignedProposal, reason string) (*storagedeal.SignedResponse, error) {
 	proposalCid, err := convert.ToCid(p)
 	if err != nil {
@@ -20,6 +20,7 @@ import (
 	"github.com/filecoin-project/go-filecoin/internal/pkg/sectorbuilder"
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/util/convert"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor"
@@ -54,6 +55,7 @@ func TestReceiveStorageProposal(t *testing.T) {
 		for _, deal := range porcelainAPI.deals {
 			assert.Equal(t, storagedeal.Accepted, deal.Response.State)
 			assert.Equal(t, "", deal.Response.Message)
+			assert.Equal(t, porcelainAPI.blockHeight, deal.StartHeight)
 		}
 	})
 
@@ -249,6 +251,104 @@ func TestReceiveStorageProposal(t *testing.T) {
 	})
 }
 
+func TestReceiveStorageRenewal(t *testing.T) {
+	tf.UnitTest(t)
+
+	ctx := context.Background()
+
+	t.Run("Completes renewals without fetching or sealing the piece", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+
+		processed := make(chan cid.Cid, 1)
+		miner.proposalProcessor = func(ctx context.Context, m *Miner, c cid.Cid) { processed <- c }
+
+		renewal, err := testRenewalProposal(porcelainAPI, renewed).NewSignedProposal(porcelainAPI.payerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Complete, res.State)
+		assert.Equal(t, renewed.Response.ProofInfo, res.ProofInfo)
+
+		deal, err := porcelainAPI.DealGet(ctx, res.ProposalCid)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Complete, deal.Response.State)
+		assert.Equal(t, renewed.StartHeight+renewed.Proposal.Duration, deal.StartHeight)
+
+		// The proposal processor fetches the piece and adds it to a sector.
+		assert.Empty(t, processed)
+	})
+
+	t.Run("Rejects renewals of unknown deals", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+
+		proposal := testRenewalProposal(porcelainAPI, renewed)
+		unknown := types.CidFromString(t, "unknown")
+		proposal.Renews = &unknown
+		renewal, err := proposal.NewSignedProposal(porcelainAPI.payerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Rejected, res.State)
+		assert.Contains(t, res.Message, "unknown deal")
+	})
+
+	t.Run("Rejects renewals by another client", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+
+		proposal := testRenewalProposal(porcelainAPI, renewed)
+		proposal.Payment.Payer = porcelainAPI.workerAddress
+		renewal, err := proposal.NewSignedProposal(porcelainAPI.workerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Rejected, res.State)
+		assert.Contains(t, res.Message, "proposed by another client")
+	})
+
+	t.Run("Rejects renewals for another piece", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+
+		proposal := testRenewalProposal(porcelainAPI, renewed)
+		proposal.PieceRef = types.CidFromString(t, "otherpiece")
+		renewal, err := proposal.NewSignedProposal(porcelainAPI.payerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Rejected, res.State)
+		assert.Contains(t, res.Message, "not for the piece of deal")
+	})
+
+	t.Run("Rejects renewals of deals that are not complete", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+		renewed.Response.State = storagedeal.Staged
+
+		renewal, err := testRenewalProposal(porcelainAPI, renewed).NewSignedProposal(porcelainAPI.payerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Rejected, res.State)
+		assert.Contains(t, res.Message, "only complete deals can be renewed")
+	})
+
+	t.Run("Rejects renewals of expired deals", func(t *testing.T) {
+		porcelainAPI, miner, renewed := minerWithCompleteDealTestSetup(t)
+		porcelainAPI.blockHeight = renewed.StartHeight + renewed.Proposal.Duration
+
+		renewal, err := testRenewalProposal(porcelainAPI, renewed).NewSignedProposal(porcelainAPI.payerAddress, porcelainAPI.signer)
+		require.NoError(t, err)
+
+		res, err := miner.receiveStorageProposal(ctx, renewal)
+		require.NoError(t, err)
+		assert.Equal(t, storagedeal.Rejected, res.State)
+		assert.Contains(t, res.Message, "expired at height")
+	})
+}
+
 func TestDealsAwaitingSealPersistence(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -785,6 +885,44 @@ func minerWithAcceptedDealTestSetup(t *testing.T, proposalCid cid.Cid, sectorID
 	return porcelainAPI, miner, proposal
 }
 
+// simulates a miner holding a sealed piece for a complete deal that started 100 blocks ago
+func minerWithCompleteDealTestSetup(t *testing.T) (*minerTestPorcelain, *Miner, *storagedeal.Deal) {
+	porcelainAPI, miner, proposal := defaultMinerTestSetup(t, VoucherInterval, defaultAmountInc)
+
+	proposalCid, err := convert.ToCid(proposal)
+	require.NoError(t, err)
+
+	storageDeal := &storagedeal.Deal{
+		Miner:    miner.minerAddr,
+		Proposal: proposal,
+		Response: &storagedeal.SignedResponse{
+			Response: storagedeal.Response{
+				State:       storagedeal.Complete,
+				ProposalCid: proposalCid,
+				ProofInfo:   &storagedeal.ProofInfo{SectorID: 777},
+			},
+		},
+		StartHeight: porcelainAPI.blockHeight - 100,
+	}
+	require.NoError(t, porcelainAPI.DealPut(storageDeal))
+
+	return porcelainAPI, miner, storageDeal
+}
+
+// testRenewalProposal returns an unsigned proposal renewing the deal renewed
+// for the same duration, with fresh payment vouchers from the end of renewed
+// on a channel that outlives the renewal.
+func testRenewalProposal(porcelainAPI *minerTestPorcelain, renewed *storagedeal.Deal) *storagedeal.Proposal {
+	end := renewed.StartHeight + renewed.Proposal.Duration
+	porcelainAPI.paymentStart = types.NewBlockHeight(end)
+	porcelainAPI.channelEol = types.NewBlockHeight(end + renewed.Proposal.Duration + ChannelExpiryInterval)
+
+	proposal := renewed.Proposal.Proposal
+	proposal.Renews = &renewed.Response.ProposalCid
+	proposal.Payment.Vouchers = testPaymentVouchers(porcelainAPI, VoucherInterval, defaultAmountInc)
+	return &proposal
+}
+
 func newMinerTestSetup(porcelainAPI *minerTestPorcelain, voucherInterval int, amountInc uint64) (*Miner, *storagedeal.SignedProposal) {
 	vouchers := testPaymentVouchers(porcelainAPI, voucherInterval, amountInc)
 	miner := newTestMiner(porcelainAPI)
@@ -55,3 +55,49 @@ func (s State) String() string {
 		return fmt.Sprintf("<unrecognized %d>", s)
 	}
 }
+
+// ParseState returns the state named `s`, as printed by State.String.
+// "sealed" names Complete, the state of a deal whose sector is sealed.
+func ParseState(s string) (State, error) {
+	if s == "sealed" {
+		return Complete, nil
+	}
+	for state := Unset; state <= Complete; state++ {
+		if state.String() == s {
+			return state, nil
+		}
+	}
+	return Unset, fmt.Errorf("unknown deal state %q", s)
+}
+
+// progress ranks the states a successful deal goes through, from accepted to
+// complete. Other states rank 0.
+func (s State) progress() int {
+	switch s {
+	case Accepted:
+		return 1
+	case Started:
+		return 2
+	case Staged:
+		return 3
+	case Complete:
+		return 4
+	default:
+		return 0
+	}
+}
+
+// Reached returns true if a deal in state s is in the target state or has
+// passed it on the way to completion.
+func (s State) Reached(target State) bool {
+	if target.progress() == 0 {
+		return s == target
+	}
+	return s.progress() >= target.progress()
+}
+
+// Terminal returns true if a deal in state s failed for good and will not
+// make any more progress.
+func (s State) Terminal() bool {
+	return s == Rejected || s == Failed
+}
@@ -19,6 +19,8 @@ func init() {
 	encoding.RegisterIpldCborType(ProofInfo{})
 	encoding.RegisterIpldCborType(QueryRequest{})
 	encoding.RegisterIpldCborType(Deal{})
+	encoding.RegisterIpldCborType(TranscriptEntry{})
+	encoding.RegisterIpldCborType(Transcript{})
 }
 
 //
@@ -52,3 +54,11 @@ func init() {
 //
 // Encoding/Decoding impls for Deal
 //
+
+//
+// Encoding/Decoding impls for TranscriptEntry
+//
+
+//
+// Encoding/Decoding impls for Transcript
+//
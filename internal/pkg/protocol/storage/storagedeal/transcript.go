@@ -0,0 +1,37 @@
+package storagedeal
+
+import (
+	"fmt"
+
+	"github.com/ipfs/go-cid"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// TranscriptEntry is a single step of a deal negotiation.
+type TranscriptEntry struct {
+	// Step names what happened, e.g. "proposal sent"
+	Step string
+
+	// Detail describes the step
+	Detail string
+}
+
+// Transcript records the steps a client took while negotiating a storage deal
+// and what the miner replied, so that the negotiation can be inspected after
+// the fact. Transcripts are kept for rejected deals too.
+type Transcript struct {
+	// ProposalCid is the cid of the signed proposal the transcript is for
+	ProposalCid cid.Cid
+
+	// Miner is the address of the miner the proposal was sent to
+	Miner address.Address
+
+	// Entries are the steps of the negotiation in the order they happened
+	Entries []TranscriptEntry
+}
+
+// Record appends a step to the transcript.
+func (t *Transcript) Record(step string, format string, args ...interface{}) {
+	t.Entries = append(t.Entries, TranscriptEntry{Step: step, Detail: fmt.Sprintf(format, args...)})
+}
@@ -47,6 +47,12 @@ type Proposal struct {
 	// MinerAddress is the address of the storage miner in the deal proposal
 	MinerAddress address.Address
 
+	// Renews is the cid of the proposal of an existing deal with the same
+	// miner for the same piece when this proposal extends that deal. The
+	// miner already holds the piece, so it is neither transferred nor sealed
+	// again, and the deal starts when the renewed deal ends.
+	Renews *cid.Cid
+
 	// Payment is a reference to the mechanism that the proposer
 	// will use to pay the miner. It should be verifiable by the
 	// miner using on-chain information.
@@ -138,6 +144,11 @@ type Deal struct {
 	CommP    types.CommP
 	Proposal *SignedProposal
 	Response *SignedResponse
+
+	// StartHeight is the chain height at which the client proposed the deal,
+	// or at which the miner accepted it. The deal lasts Proposal.Duration
+	// blocks from there.
+	StartHeight uint64
 }
 
 // ProofInfo contains the details about a seal proof, that the client needs to know to verify that his deal was posted on chain.
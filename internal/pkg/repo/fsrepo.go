@@ -38,6 +38,10 @@ const (
 
 var log = logging.Logger("repo")
 
+// ErrRepoInUse is returned when opening a repo that is already open, e.g. by
+// a running daemon.
+var ErrRepoInUse = errors.New("repo already in use")
+
 // FSRepo is a repo implementation backed by a filesystem.
 type FSRepo struct {
 	// Path to the repo root directory.
@@ -77,6 +81,10 @@ func InitFSRepo(targetPath string, version uint, cfg *config.Config) error {
 
 	dirpath := container + MakeRepoDirName(basename, time.Now(), version, 0)
 
+	if err := checkNotLocked(linkPath); err != nil {
+		return err
+	}
+
 	exists, err := fileExists(linkPath)
 	if err != nil {
 		return errors.Wrapf(err, "error inspecting repo symlink path %s", linkPath)
@@ -103,6 +111,10 @@ func InitFSRepoDirect(targetPath string, version uint, cfg *config.Config) error
 		return err
 	}
 
+	if err := checkNotLocked(repoPath); err != nil {
+		return err
+	}
+
 	if err := ensureWritableDirectory(repoPath); err != nil {
 		return errors.Wrap(err, "no writable directory")
 	}
@@ -163,6 +175,9 @@ func OpenFSRepo(repoPath string, version uint) (*FSRepo, error) {
 
 	r.lockfile, err = lockfile.Lock(r.path, lockFile)
 	if err != nil {
+		if locked, lerr := lockfile.Locked(r.path, lockFile); lerr == nil && locked {
+			return nil, errors.Wrapf(ErrRepoInUse, "failed to take repo lock of %s", r.path)
+		}
 		return nil, errors.Wrap(err, "failed to take repo lock")
 	}
 
@@ -174,6 +189,19 @@ func OpenFSRepo(repoPath string, version uint) (*FSRepo, error) {
 	return r, nil
 }
 
+// checkNotLocked returns ErrRepoInUse if the repo at repoPath is locked, e.g.
+// by a running daemon.
+func checkNotLocked(repoPath string) error {
+	locked, err := lockfile.Locked(repoPath, lockFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to check repo lock of %s", repoPath)
+	}
+	if locked {
+		return errors.Wrapf(ErrRepoInUse, "refusing to init repo at %s", repoPath)
+	}
+	return nil
+}
+
 // MakeRepoDirName constructs a name for a concrete repo directory, which includes its
 // version number and a timestamp. The name will begin with prefix and, if uniqueifier is
 // non-zero, end with that (intended as an ordinal for finding a free name).
@@ -450,6 +478,17 @@ func WriteVersion(p string, version uint) error {
 	return ioutil.WriteFile(filepath.Join(p, versionFilename), []byte(strconv.Itoa(int(version))), 0644)
 }
 
+// ReadConfig reads the config file of the repo at the specified path
+// without opening the repo.
+func ReadConfig(repoPath string) (*config.Config, error) {
+	configFile := filepath.Join(repoPath, configFilename)
+	cfg, err := config.ReadFile(configFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read config file at %q", configFile)
+	}
+	return cfg, nil
+}
+
 // ReadVersion returns the unparsed (string) version
 // from the version file in the specified repo.
 func ReadVersion(repoPath string) (string, error) {
@@ -11,6 +11,7 @@ import (
 	"testing"
 
 	ds "github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -35,6 +36,9 @@ const (
 			"PUT"
 		]
 	},
+	"blocks": {
+		"max_messages": 1000
+	},
 	"bootstrap": {
 		"addresses": [],
 		"minPeerThreshold": 0,
@@ -53,7 +57,9 @@ const (
 	"mining": {
 		"minerAddress": "empty",
 		"autoSealIntervalSeconds": 120,
-		"storagePrice": "0"
+		"storagePrice": "0",
+		"min_gas_price": "0",
+		"min_peers": 0
 	},
 	"mpool": {
 		"maxPoolSize": 10000,
@@ -202,7 +208,7 @@ func TestFSRepoRoundtrip(t *testing.T) {
 	defer RequireRemoveAll(t, container)
 
 	cfg := config.NewDefaultConfig()
-	cfg.API.Address = "foo" // testing that what we get back isnt just the default
+	cfg.API.Address = "/ip4/127.0.0.1/tcp/4444" // testing that what we get back isnt just the default
 
 	repoPath := path.Join(container, "repo")
 	assert.NoError(t, err, InitFSRepo(repoPath, 42, cfg))
@@ -233,7 +239,7 @@ func TestFSRepoReplaceAndSnapshotConfig(t *testing.T) {
 	repoPath := path.Join(container, "repo")
 
 	cfg := config.NewDefaultConfig()
-	cfg.API.Address = "foo"
+	cfg.API.Address = "/ip4/127.0.0.1/tcp/4444"
 	assert.NoError(t, err, InitFSRepo(repoPath, 42, cfg))
 
 	expSnpsht, err := ioutil.ReadFile(filepath.Join(repoPath, configFilename))
@@ -243,15 +249,15 @@ func TestFSRepoReplaceAndSnapshotConfig(t *testing.T) {
 	assert.NoError(t, err)
 
 	newCfg := config.NewDefaultConfig()
-	newCfg.API.Address = "bar"
+	newCfg.API.Address = "/ip4/127.0.0.1/tcp/5555"
 
 	assert.NoError(t, r1.ReplaceConfig(newCfg))
-	assert.Equal(t, "bar", r1.Config().API.Address)
+	assert.Equal(t, "/ip4/127.0.0.1/tcp/5555", r1.Config().API.Address)
 	assert.NoError(t, r1.Close())
 
 	r2, err := OpenFSRepo(repoPath, 42)
 	assert.NoError(t, err)
-	assert.Equal(t, "bar", r2.Config().API.Address)
+	assert.Equal(t, "/ip4/127.0.0.1/tcp/5555", r2.Config().API.Address)
 	assert.NoError(t, r2.Close())
 
 	// assert that a single snapshot was created when replacing the config
@@ -281,6 +287,13 @@ func TestRepoLock(t *testing.T) {
 	_, err = OpenFSRepo(repoPath, 42)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "failed to take repo lock")
+	assert.Equal(t, ErrRepoInUse, errors.Cause(err))
+
+	// An open repo cannot be initialized over.
+	err = InitFSRepo(repoPath, 42, cfg)
+	assert.Equal(t, ErrRepoInUse, errors.Cause(err))
+	err = InitFSRepoDirect(repoPath, 42, cfg)
+	assert.Equal(t, ErrRepoInUse, errors.Cause(err))
 	assert.NoError(t, r.Close())
 
 	_, err = os.Lstat(filepath.Join(repoPath, lockFile))
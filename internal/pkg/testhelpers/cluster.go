@@ -0,0 +1,92 @@
+package testhelpers
+
+import (
+	"testing"
+)
+
+// Cluster is a group of test daemons for tests that need more than a pair of
+// nodes. The first daemon is the leader, e.g. the miner of the cluster, the
+// others are its followers. Daemons are not connected until one of the
+// Connect methods is called.
+type Cluster struct {
+	test    *testing.T
+	daemons []*TestDaemon
+}
+
+// NewCluster creates and starts n daemons, each configured with options.
+func NewCluster(t *testing.T, n int, options ...func(*TestDaemon)) *Cluster {
+	t.Helper()
+
+	nodeOptions := make([][]func(*TestDaemon), n)
+	for i := range nodeOptions {
+		nodeOptions[i] = options
+	}
+	return NewClusterWithOptions(t, nodeOptions...)
+}
+
+// NewClusterWithOptions creates and starts one daemon per list of options,
+// so that each node can be configured differently, e.g. to only let the
+// leader mine.
+func NewClusterWithOptions(t *testing.T, nodeOptions ...[]func(*TestDaemon)) *Cluster {
+	t.Helper()
+
+	c := &Cluster{test: t}
+	for _, options := range nodeOptions {
+		// Each daemon listens on ports of its own choosing, see NewDaemon.
+		c.daemons = append(c.daemons, NewDaemon(t, options...).Start())
+	}
+	return c
+}
+
+// Daemons returns all daemons of the cluster, the leader first.
+func (c *Cluster) Daemons() []*TestDaemon {
+	return append([]*TestDaemon{}, c.daemons...)
+}
+
+// Leader returns the first daemon of the cluster.
+func (c *Cluster) Leader() *TestDaemon {
+	return c.daemons[0]
+}
+
+// Followers returns all daemons but the leader.
+func (c *Cluster) Followers() []*TestDaemon {
+	return append([]*TestDaemon{}, c.daemons[1:]...)
+}
+
+// ConnectAll connects every daemon to every other daemon.
+func (c *Cluster) ConnectAll() *Cluster {
+	c.test.Helper()
+
+	for i, d := range c.daemons {
+		for _, peer := range c.daemons[i+1:] {
+			d.ConnectSuccess(peer)
+		}
+	}
+	return c
+}
+
+// ConnectRing connects every daemon to the next one, and the last one to the
+// leader.
+func (c *Cluster) ConnectRing() *Cluster {
+	c.test.Helper()
+
+	n := len(c.daemons)
+	switch {
+	case n < 2:
+	case n == 2:
+		// A ring of two is a single connection.
+		c.daemons[0].ConnectSuccess(c.daemons[1])
+	default:
+		for i, d := range c.daemons {
+			d.ConnectSuccess(c.daemons[(i+1)%n])
+		}
+	}
+	return c
+}
+
+// Shutdown stops all daemons, asserting that they exited successfully.
+func (c *Cluster) Shutdown() {
+	for _, d := range c.daemons {
+		d.ShutdownSuccess()
+	}
+}
@@ -0,0 +1,60 @@
+package testhelpers
+
+import (
+	"encoding/json"
+	"io"
+	"reflect"
+	"strings"
+
+	"github.com/stretchr/testify/require"
+)
+
+// RunSuccessJSON runs the given command against the test daemon with JSON
+// encoding, asserts that it succeeded and decodes its stdout into out, which
+// must be a pointer. Commands that emit several newline-delimited JSON values
+// are decoded into a slice, one element per value, when out points to one.
+func RunSuccessJSON(td *TestDaemon, out interface{}, args ...string) {
+	td.test.Helper()
+
+	// handle RunSuccessJSON(td, out, "cmd subcmd")
+	if len(args) == 1 {
+		args = strings.Split(args[0], " ")
+	}
+	args = append(args, "--enc=json")
+	stdout := td.RunSuccess(args...).ReadStdout()
+
+	var values []json.RawMessage
+	dec := json.NewDecoder(strings.NewReader(stdout))
+	for {
+		var value json.RawMessage
+		err := dec.Decode(&value)
+		if err == io.EOF {
+			break
+		}
+		require.NoError(td.test, err, "malformed JSON from %q:\n%s", strings.Join(args, " "), stdout)
+		values = append(values, value)
+	}
+	require.NotEmpty(td.test, values, "no JSON output from %q", strings.Join(args, " "))
+
+	ptr := reflect.ValueOf(out)
+	require.Equal(td.test, reflect.Ptr, ptr.Kind(), "RunSuccessJSON must decode into a pointer, got %T", out)
+	isSlice := ptr.Elem().Kind() == reflect.Slice
+
+	// A single value is decoded as is, unless it is one element of a slice.
+	if len(values) == 1 {
+		err := json.Unmarshal(values[0], out)
+		if err == nil || !isSlice {
+			require.NoError(td.test, err, "could not decode output of %q into %T:\n%s", strings.Join(args, " "), out, stdout)
+			return
+		}
+	}
+	require.True(td.test, isSlice, "%q emitted %d JSON values, which cannot be decoded into %T", strings.Join(args, " "), len(values), out)
+
+	slice := reflect.MakeSlice(ptr.Elem().Type(), 0, len(values))
+	for _, value := range values {
+		elem := reflect.New(slice.Type().Elem())
+		require.NoError(td.test, json.Unmarshal(value, elem.Interface()), "could not decode output of %q into %T:\n%s", strings.Join(args, " "), out, value)
+		slice = reflect.Append(slice, elem.Elem())
+	}
+	ptr.Elem().Set(slice)
+}
@@ -75,6 +75,11 @@ func (tbr *FakeBlockRewarder) GasReward(ctx context.Context, st state.Tree, mine
 	return nil
 }
 
+// BlockRewardAt returns zero
+func (tbr *FakeBlockRewarder) BlockRewardAt(h uint64) types.AttoFIL {
+	return types.ZeroAttoFIL
+}
+
 // FakeBlockValidator passes everything as valid
 type FakeBlockValidator struct{}
 
@@ -0,0 +1,71 @@
+package testhelpers
+
+import (
+	"bytes"
+	"io"
+	"sync"
+)
+
+// logPipe drains a daemon output pipe as the daemon writes it, copying the
+// output to a log and buffering it so that it can still be read, e.g. by
+// ReadStderr, once the daemon stopped. Unlike the pipe itself it never blocks
+// the daemon when nobody reads.
+type logPipe struct {
+	lk   sync.Mutex
+	cond *sync.Cond
+	buf  bytes.Buffer
+	done bool
+	err  error
+}
+
+// newLogPipe starts copying src to log, which must be safe for concurrent
+// writes if it is shared between pipes.
+func newLogPipe(src io.Reader, log io.Writer) *logPipe {
+	lp := &logPipe{}
+	lp.cond = sync.NewCond(&lp.lk)
+
+	go func() {
+		p := make([]byte, 4096)
+		for {
+			n, err := src.Read(p)
+			if n > 0 {
+				// The log is best effort, the buffered output is what tests read.
+				log.Write(p[:n]) // nolint: errcheck
+
+				lp.lk.Lock()
+				lp.buf.Write(p[:n])
+				lp.cond.Broadcast()
+				lp.lk.Unlock()
+			}
+			if err != nil {
+				lp.lk.Lock()
+				lp.done = true
+				if err != io.EOF {
+					lp.err = err
+				}
+				lp.cond.Broadcast()
+				lp.lk.Unlock()
+				return
+			}
+		}
+	}()
+	return lp
+}
+
+// Read reads buffered output, blocking until there is some or the pipe is
+// closed.
+func (lp *logPipe) Read(p []byte) (int, error) {
+	lp.lk.Lock()
+	defer lp.lk.Unlock()
+
+	for lp.buf.Len() == 0 && !lp.done {
+		lp.cond.Wait()
+	}
+	if lp.buf.Len() > 0 {
+		return lp.buf.Read(p)
+	}
+	if lp.err != nil {
+		return 0, lp.err
+	}
+	return 0, io.EOF
+}
@@ -0,0 +1,30 @@
+package testhelpers
+
+import (
+	"io/ioutil"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/tools/gengen/util"
+)
+
+// MakeGenesis writes the genesis block described by spec to a car file in the
+// temp directory, for use with the GenesisFile option, and returns its path
+// and the generated keys and miners. The caller removes the file. Like
+// go-filecoin genesis new, specs without a proofs mode use the test proofs
+// mode and the same spec always yields the same genesis block.
+func MakeGenesis(t *testing.T, spec *gengen.GenesisCfg) (string, *gengen.RenderedGenInfo) {
+	t.Helper()
+	gengen.ApplyProofsModeDefaults(spec, false, false)
+
+	f, err := ioutil.TempFile("", "genesis.*.car")
+	require.NoError(t, err)
+	defer f.Close() // nolint: errcheck
+
+	info, err := gengen.GenGenesisCar(spec, f, 0, time.Unix(123456789, 0))
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+	return f.Name(), info
+}
@@ -1,9 +1,13 @@
 package testhelpers
 
 import (
+	"bytes"
 	"io"
 	"io/ioutil"
+	"os/exec"
+	"regexp"
 	"strings"
+	"syscall"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -19,19 +23,37 @@ type CmdOutput struct {
 	errbytes []byte
 
 	// The status or exit code of a successfully-executed command. This value is only meaningful
-	// after the command completes, and if error is nil.
+	// after the command completes, and if error is nil. The one exception is a command killed
+	// by a signal (such as when it timed out), whose status is 128 plus the signal number even
+	// though error is set.
 	status int
 	// Any error encountered in arranging execution of the command (e.g. I/O errors reading from
 	// streams). An error here indicates that the status and output buffers may not be meaningful.
 	// This is distinguished from an "application" error indicated in the status code and output
 	// streams.
 	error error
+	// Whether AssertSuccess tolerates WARNING lines on stderr.
+	allowWarnings bool
 
 	tb testing.TB
 }
 
 // ReadOutput reads the `stdout` and `stderr` streams completely and returns a new Output object.
 func ReadOutput(tb testing.TB, args []string, stdout io.Reader, stderr io.Reader) *CmdOutput {
+	return StreamOutput(tb, args, stdout, stderr, nil, nil)
+}
+
+// StreamOutput is like ReadOutput, but also copies `stdout` to outW and `stderr` to errW as the
+// output arrives, so that callers can act on the output of a command before it completes. Either
+// writer may be nil.
+func StreamOutput(tb testing.TB, args []string, stdout io.Reader, stderr io.Reader, outW io.Writer, errW io.Writer) *CmdOutput {
+	if outW != nil {
+		stdout = io.TeeReader(stdout, outW)
+	}
+	if errW != nil {
+		stderr = io.TeeReader(stderr, errW)
+	}
+
 	// Consume the streams in parallel to avoid potential deadlock around the remote process
 	// blocking on a full error stream buffer (e.g. logs) while we're waiting for the output
 	// stream to complete, or vice versa.
@@ -97,6 +119,13 @@ func (o *CmdOutput) ReadStdoutTrimNewlines() string {
 	return strings.Trim(o.ReadStdout(), "\n")
 }
 
+// AllowWarnings makes AssertSuccess tolerate WARNING lines on stderr, for
+// commands that warn by design. CRITICAL and ERROR lines still fail.
+func (o *CmdOutput) AllowWarnings() *CmdOutput {
+	o.allowWarnings = true
+	return o
+}
+
 // AssertSuccess asserts that the output represents a successful execution.
 func (o *CmdOutput) AssertSuccess() *CmdOutput {
 	o.tb.Helper()
@@ -104,7 +133,9 @@ func (o *CmdOutput) AssertSuccess() *CmdOutput {
 
 	assert.NotContains(o.tb, oErr, "CRITICAL")
 	assert.NotContains(o.tb, oErr, "ERROR")
-	assert.NotContains(o.tb, oErr, "WARNING")
+	if !o.allowWarnings {
+		assert.NotContains(o.tb, oErr, "WARNING")
+	}
 	assert.NotContains(o.tb, oErr, "Error:")
 	return o
 }
@@ -115,24 +146,122 @@ func (o *CmdOutput) AssertFail(err string) *CmdOutput {
 	o.tb.Helper()
 	assert.Empty(o.tb, o.ReadStdout()) // Also checks no invocation error.
 	assert.Contains(o.tb, o.ReadStderr(), err)
+	return o.AssertExitCode(1)
+}
+
+// AssertFailRegex asserts that the output represents a failed execution, with
+// the error matching the regular expression pattern. Use it for errors that
+// embed variable data such as cids or addresses.
+func (o *CmdOutput) AssertFailRegex(pattern string) *CmdOutput {
+	o.tb.Helper()
+	assert.Empty(o.tb, o.ReadStdout()) // Also checks no invocation error.
+	o.assertStderrMatches(pattern)
+	return o.AssertExitCode(1)
+}
+
+// AssertStderrMatches asserts that the command succeeded and that its stderr
+// matches the regular expression pattern, e.g. for a command that succeeds
+// with a warning.
+func (o *CmdOutput) AssertStderrMatches(pattern string) *CmdOutput {
+	o.tb.Helper()
+	o.assertStderrMatches(pattern)
+	return o.AssertExitCode(0)
+}
+
+func (o *CmdOutput) assertStderrMatches(pattern string) {
+	o.tb.Helper()
+	re, err := regexp.Compile(pattern)
+	require.NoError(o.tb, err, "invalid pattern %q", pattern)
+	stderr := o.ReadStderr() // Also checks no invocation error.
+	assert.True(o.tb, re.MatchString(stderr), "stderr of \"%s\" does not match %q:\n%s", strings.Join(o.Args, " "), pattern, stderr)
+}
+
+// AssertExitCode asserts that the command exited with the given code. A command
+// killed by a signal has code 128 plus the signal number.
+func (o *CmdOutput) AssertExitCode(code int) *CmdOutput {
+	o.tb.Helper()
+	assert.Equal(o.tb, code, o.status, "exit code of \"%s\"", strings.Join(o.Args, " "))
 	return o
 }
 
+// ExitCode returns the exit code of a command that exited with err. Like a
+// shell, it reports a command killed by a signal as 128 plus the signal
+// number, so that a command killed on timeout can be told apart from one
+// that failed.
+func ExitCode(err *exec.ExitError) int {
+	status, ok := err.Sys().(syscall.WaitStatus)
+	if !ok {
+		return 1
+	}
+	if status.Signaled() {
+		return 128 + int(status.Signal())
+	}
+	return status.ExitStatus()
+}
+
+// panicStart matches the first line of the report of a Go panic or fatal
+// runtime error.
+var panicStart = regexp.MustCompile(`(?m)^(panic|fatal error): `)
+
+// PanicReport returns the report of a Go panic or fatal runtime error in the
+// stderr of a process, from its message to the end of the stack traces, or
+// "" if stderr holds none.
+func PanicReport(stderr string) string {
+	loc := panicStart.FindStringIndex(stderr)
+	if loc == nil {
+		return ""
+	}
+	report := stderr[loc[0]:]
+	// A log line may start with "panic: " too; a real report has the stack.
+	if !strings.Contains(report, "\ngoroutine ") {
+		return ""
+	}
+	return report
+}
+
 // requireNoError requires that no execution error has been recorded, which would render the status
 // code and output streams incomplete.
 func (o *CmdOutput) requireNoError() {
 	require.NoError(o.tb, o.error, "execution error for \"%s\"", strings.Join(o.Args, " "))
 }
 
+// lineWriter calls handler with each complete line written to it, without the
+// line break.
+type lineWriter struct {
+	handler func(line string)
+	partial []byte
+}
+
+func (lw *lineWriter) Write(p []byte) (int, error) {
+	lw.partial = append(lw.partial, p...)
+	for {
+		i := bytes.IndexByte(lw.partial, '\n')
+		if i < 0 {
+			break
+		}
+		lw.handler(string(lw.partial[:i]))
+		lw.partial = lw.partial[i+1:]
+	}
+	return len(p), nil
+}
+
+// Flush calls handler with the last line if it was not terminated.
+func (lw *lineWriter) Flush() {
+	if len(lw.partial) > 0 {
+		lw.handler(string(lw.partial))
+		lw.partial = nil
+	}
+}
+
 // Reads everything a reader has to offer into memory, providing the completed buffer on
 // the returned channel.
 func readAllAsync(tb testing.TB, r io.Reader) chan []byte {
 	ch := make(chan []byte, 1)
 	go func() {
-		bytes, err := ioutil.ReadAll(r)
+		buf, err := ioutil.ReadAll(r)
 		require.NoError(tb, err)
 		if err == nil {
-			ch <- bytes
+			ch <- buf
 		} else {
 			close(ch)
 		}
@@ -0,0 +1,180 @@
+// +build !windows
+
+package testhelpers_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os/exec"
+	"strings"
+	"sync"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+)
+
+func TestExitCode(t *testing.T) {
+	tf.UnitTest(t)
+
+	exitErr := func(cmd *exec.Cmd) *exec.ExitError {
+		err := cmd.Run()
+		require.Error(t, err)
+		exitErr, ok := err.(*exec.ExitError)
+		require.True(t, ok)
+		return exitErr
+	}
+
+	t.Run("reports the exit status", func(t *testing.T) {
+		assert.Equal(t, 1, th.ExitCode(exitErr(exec.Command("sh", "-c", "exit 1"))))
+		assert.Equal(t, 3, th.ExitCode(exitErr(exec.Command("sh", "-c", "exit 3"))))
+	})
+
+	t.Run("reports the signal of a killed process", func(t *testing.T) {
+		cmd := exec.Command("sleep", "10")
+		require.NoError(t, cmd.Start())
+		require.NoError(t, cmd.Process.Signal(syscall.SIGTERM))
+		err := cmd.Wait()
+		exitErr, ok := err.(*exec.ExitError)
+		require.True(t, ok)
+		assert.Equal(t, 128+int(syscall.SIGTERM), th.ExitCode(exitErr))
+	})
+
+	t.Run("reports a timeout as a kill", func(t *testing.T) {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+		defer cancel()
+		assert.Equal(t, 128+int(syscall.SIGKILL), th.ExitCode(exitErr(exec.CommandContext(ctx, "sleep", "10"))))
+	})
+}
+
+// syncBuffer is a buffer that can be read while output is streamed into it.
+type syncBuffer struct {
+	mu  sync.Mutex
+	buf bytes.Buffer
+}
+
+func (sb *syncBuffer) Write(p []byte) (int, error) {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.Write(p)
+}
+
+func (sb *syncBuffer) String() string {
+	sb.mu.Lock()
+	defer sb.mu.Unlock()
+	return sb.buf.String()
+}
+
+func TestStreamOutput(t *testing.T) {
+	tf.UnitTest(t)
+
+	stdoutR, stdoutW := io.Pipe()
+	var streamed syncBuffer
+
+	done := make(chan *th.CmdOutput)
+	go func() {
+		done <- th.StreamOutput(t, []string{"cmd"}, stdoutR, strings.NewReader("some log"), &streamed, nil)
+	}()
+
+	// Output is streamed before the command completes.
+	_, err := stdoutW.Write([]byte("first\n"))
+	require.NoError(t, err)
+	require.NoError(t, th.WaitForIt(100, 10*time.Millisecond, func() (bool, error) {
+		return streamed.String() == "first\n", nil
+	}))
+
+	_, err = stdoutW.Write([]byte("second\n"))
+	require.NoError(t, err)
+	require.NoError(t, stdoutW.Close())
+
+	out := <-done
+	assert.Equal(t, "first\nsecond\n", streamed.String())
+	assert.Equal(t, "first\nsecond\n", out.ReadStdout())
+	assert.Equal(t, "some log", out.ReadStderr())
+}
+
+func TestAssertStderrMatches(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("failure matching a pattern", func(t *testing.T) {
+		out := th.ReadOutput(t, []string{"cmd"}, strings.NewReader(""), strings.NewReader("Error: no such miner: t0123\n"))
+		out.SetStatus(1)
+		out.AssertFailRegex(`no such miner: t0\d+`)
+	})
+
+	t.Run("success with a warning", func(t *testing.T) {
+		out := th.ReadOutput(t, []string{"cmd"}, strings.NewReader("ok\n"), strings.NewReader("WARNING: peer QmFoo is slow\n"))
+		out.AssertStderrMatches(`peer Qm\w+ is slow`)
+	})
+
+	t.Run("pattern does not match", func(t *testing.T) {
+		mt := &failureRecorder{TB: t}
+		out := th.ReadOutput(mt, []string{"cmd"}, strings.NewReader(""), strings.NewReader("Error: no such miner: t0123\n"))
+		out.SetStatus(1)
+		out.AssertFailRegex(`no such actor`)
+		assert.True(t, mt.failed)
+	})
+}
+
+func TestAllowWarnings(t *testing.T) {
+	tf.UnitTest(t)
+
+	warning := "WARNING: using default address\n"
+
+	t.Run("warnings fail by default", func(t *testing.T) {
+		fr := &failureRecorder{TB: t}
+		th.ReadOutput(fr, []string{"cmd"}, strings.NewReader("ok\n"), strings.NewReader(warning)).AssertSuccess()
+		assert.True(t, fr.failed)
+	})
+
+	t.Run("warnings are tolerated when allowed", func(t *testing.T) {
+		th.ReadOutput(t, []string{"cmd"}, strings.NewReader("ok\n"), strings.NewReader(warning)).AllowWarnings().AssertSuccess()
+	})
+
+	t.Run("errors still fail when warnings are allowed", func(t *testing.T) {
+		fr := &failureRecorder{TB: t}
+		th.ReadOutput(fr, []string{"cmd"}, strings.NewReader(""), strings.NewReader(warning+"ERROR: boom\n")).AllowWarnings().AssertSuccess()
+		assert.True(t, fr.failed)
+	})
+}
+
+// failureRecorder records assertion failures instead of failing the test.
+type failureRecorder struct {
+	testing.TB
+	failed bool
+}
+
+func (fr *failureRecorder) Errorf(format string, args ...interface{}) {
+	fr.failed = true
+}
+
+func TestPanicReport(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("returns the panic and its stack", func(t *testing.T) {
+		report := "panic: runtime error: invalid memory address or nil pointer dereference\n" +
+			"[signal SIGSEGV: segmentation violation code=0x1 addr=0x0 pc=0x4a1f2b]\n\n" +
+			"goroutine 42 [running]:\n" +
+			"github.com/filecoin-project/go-filecoin/internal/pkg/mining.(*DefaultWorker).Mine(...)\n"
+		stderr := "INFO  mining: starting\nWARNING peer is slow\n" + report
+
+		assert.Equal(t, report, th.PanicReport(stderr))
+	})
+
+	t.Run("returns fatal runtime errors", func(t *testing.T) {
+		stderr := "fatal error: concurrent map writes\n\ngoroutine 7 [running]:\nruntime.throw(...)\n"
+		assert.Equal(t, stderr, th.PanicReport(stderr))
+	})
+
+	t.Run("ignores output without a panic", func(t *testing.T) {
+		assert.Empty(t, th.PanicReport(""))
+		assert.Empty(t, th.PanicReport("ERROR chain: failed to sync: panic: not really\ngoroutine 1 [running]:\n"))
+		assert.Empty(t, th.PanicReport("panic: logged, but the process went on\n"))
+	})
+}
@@ -0,0 +1,84 @@
+package testhelpers
+
+import (
+	"reflect"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// ReturnDecoder decodes the return values of a message receipt into a typed value.
+type ReturnDecoder func(ret [][]byte) (interface{}, error)
+
+// returnDecoderKey identifies a method of an actor.
+type returnDecoderKey struct {
+	actor  address.Address
+	method types.MethodID
+}
+
+// returnDecoders holds the decoders of methods whose return value tests
+// commonly need, keyed by the address of the actor and the method.
+var returnDecoders = map[returnDecoderKey]ReturnDecoder{
+	{address.StorageMarketAddress, storagemarket.CreateStorageMiner}: ABIReturnDecoder(abi.Address),
+	{address.PaymentBrokerAddress, paymentbroker.CreateChannel}:      ABIReturnDecoder(abi.ChannelID),
+}
+
+// RegisterReturnDecoder registers the decoder for the return value of method
+// on the actor at actor, replacing any previously registered decoder.
+func RegisterReturnDecoder(actor address.Address, method types.MethodID, decoder ReturnDecoder) {
+	returnDecoders[returnDecoderKey{actor, method}] = decoder
+}
+
+// ABIReturnDecoder returns a decoder for a method returning a single value of
+// the given ABI type.
+func ABIReturnDecoder(t abi.Type) ReturnDecoder {
+	return func(ret [][]byte) (interface{}, error) {
+		if len(ret) == 0 {
+			return nil, errors.New("receipt has no return value")
+		}
+		val, err := abi.Deserialize(ret[0], t)
+		if err != nil {
+			return nil, err
+		}
+		return val.Val, nil
+	}
+}
+
+// DecodeReturn decodes the return value of a receipt for a message to the
+// method of the actor at to and stores it in the value pointed to by out. The
+// decoder registered for the actor and method is used if there is one,
+// otherwise the value is decoded according to the method's ABI return types.
+func DecodeReturn(to address.Address, method types.MethodID, returnTypes []abi.Type, ret [][]byte, out interface{}) error {
+	decoder, ok := returnDecoders[returnDecoderKey{to, method}]
+	if !ok {
+		if len(returnTypes) == 0 {
+			return errors.Errorf("no decoder for the return value of method %d of %s", method, to)
+		}
+		decoder = ABIReturnDecoder(returnTypes[0])
+	}
+
+	val, err := decoder(ret)
+	if err != nil {
+		return errors.Wrapf(err, "failed to decode the return value of method %d of %s", method, to)
+	}
+
+	dst := reflect.ValueOf(out)
+	if dst.Kind() != reflect.Ptr || dst.IsNil() {
+		return errors.Errorf("cannot decode into non-pointer %T", out)
+	}
+	src := reflect.ValueOf(val)
+	if src.Kind() == reflect.Ptr && !src.Type().AssignableTo(dst.Elem().Type()) && !src.IsNil() {
+		// Allow decoding e.g. a *types.ChannelID into a types.ChannelID.
+		src = src.Elem()
+	}
+	if !src.Type().AssignableTo(dst.Elem().Type()) {
+		return errors.Errorf("cannot decode %T into %T", val, out)
+	}
+	dst.Elem().Set(src)
+	return nil
+}
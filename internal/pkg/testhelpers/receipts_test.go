@@ -0,0 +1,69 @@
+package testhelpers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/paymentbroker"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+func TestDecodeReturn(t *testing.T) {
+	tf.UnitTest(t)
+
+	minerAddr := address.NewForTestGetter()()
+
+	t.Run("decodes the new miner address of miner create", func(t *testing.T) {
+		var got address.Address
+		require.NoError(t, th.DecodeReturn(address.StorageMarketAddress, storagemarket.CreateStorageMiner, nil, [][]byte{minerAddr.Bytes()}, &got))
+		assert.Equal(t, minerAddr, got)
+	})
+
+	t.Run("decodes the channel id of paych create", func(t *testing.T) {
+		ret := [][]byte{types.NewChannelID(7).Bytes()}
+
+		var ptr *types.ChannelID
+		require.NoError(t, th.DecodeReturn(address.PaymentBrokerAddress, paymentbroker.CreateChannel, nil, ret, &ptr))
+		assert.True(t, types.NewChannelID(7).Equal(ptr))
+
+		var val types.ChannelID
+		require.NoError(t, th.DecodeReturn(address.PaymentBrokerAddress, paymentbroker.CreateChannel, nil, ret, &val))
+		assert.True(t, types.NewChannelID(7).Equal(&val))
+	})
+
+	t.Run("falls back to the method's ABI return types", func(t *testing.T) {
+		var got address.Address
+		require.NoError(t, th.DecodeReturn(minerAddr, types.MethodID(8), []abi.Type{abi.Address}, [][]byte{minerAddr.Bytes()}, &got))
+		assert.Equal(t, minerAddr, got)
+	})
+
+	t.Run("uses a registered decoder", func(t *testing.T) {
+		th.RegisterReturnDecoder(minerAddr, types.MethodID(9), func(ret [][]byte) (interface{}, error) {
+			return len(ret), nil
+		})
+
+		var got int
+		require.NoError(t, th.DecodeReturn(minerAddr, types.MethodID(9), nil, [][]byte{{1}, {2}}, &got))
+		assert.Equal(t, 2, got)
+	})
+
+	t.Run("errors", func(t *testing.T) {
+		var got address.Address
+		err := th.DecodeReturn(minerAddr, types.MethodID(10), nil, [][]byte{minerAddr.Bytes()}, &got)
+		assert.Contains(t, err.Error(), "no decoder")
+
+		var wrong string
+		err = th.DecodeReturn(address.StorageMarketAddress, storagemarket.CreateStorageMiner, nil, [][]byte{minerAddr.Bytes()}, &wrong)
+		assert.Contains(t, err.Error(), "cannot decode")
+
+		err = th.DecodeReturn(address.StorageMarketAddress, storagemarket.CreateStorageMiner, nil, nil, &got)
+		assert.Contains(t, err.Error(), "no return value")
+	})
+}
@@ -0,0 +1,43 @@
+package testhelpers
+
+import (
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+)
+
+// reorgTimeout is how long InduceReorg waits for the heads of the daemons to
+// converge after reconnecting them, polling every reorgPollInterval.
+const (
+	reorgTimeout      = time.Minute
+	reorgPollInterval = 100 * time.Millisecond
+)
+
+// InduceReorg makes the connected daemons a and b build competing chains and
+// rejoin. It disconnects them, mines blocksA blocks on a and blocksB blocks on
+// b while they cannot see each other's blocks, reconnects them and waits until
+// they agree on a head. It returns that head, so that tests can assert which
+// fork won. Both daemons must be able to mine. The test fails if the heads do
+// not converge within a minute.
+func InduceReorg(a, b *TestDaemon, blocksA, blocksB int) block.TipSet {
+	a.test.Helper()
+
+	a.DisconnectSuccess(b)
+	for i := 0; i < blocksA; i++ {
+		a.RunSuccess("mining", "once")
+	}
+	for i := 0; i < blocksB; i++ {
+		b.RunSuccess("mining", "once")
+	}
+	a.ConnectSuccess(b)
+
+	var head block.TipSet
+	err := WaitForIt(int(reorgTimeout/reorgPollInterval), reorgPollInterval, func() (bool, error) {
+		head = a.ChainHead()
+		return head.Key().Equals(b.HeadKey()), nil
+	})
+	require.NoError(a.test, err, "heads did not converge within %s after reconnecting", reorgTimeout)
+	return head
+}
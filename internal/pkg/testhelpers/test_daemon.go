@@ -1,8 +1,10 @@
 package testhelpers
 
 import (
+	"bufio"
 	"bytes"
 	"context"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -13,6 +15,7 @@ import (
 	"path"
 	"path/filepath"
 	"regexp"
+	"strconv"
 	"strings"
 	"sync"
 	"syscall"
@@ -21,13 +24,18 @@ import (
 
 	"github.com/filecoin-project/go-filecoin/build/project"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/block"
+	"github.com/ipfs/go-car"
 	"github.com/ipfs/go-cid"
+	"github.com/libp2p/go-libp2p-core/metrics"
 	ma "github.com/multiformats/go-multiaddr"
-	"github.com/multiformats/go-multiaddr-net"
 	"github.com/pkg/errors"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/config"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/consensus"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/protocol/storage/storagedeal"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/abi"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/actor/builtin/storagemarket"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 
 	"github.com/stretchr/testify/assert"
@@ -37,8 +45,10 @@ import (
 const (
 	// DefaultDaemonCmdTimeout is the default timeout for executing commands.
 	DefaultDaemonCmdTimeout = 1 * time.Minute
-	repoName                = "repo"
-	sectorsName             = "sectors"
+	// DefaultAPITimeout is the default time a started daemon has to serve its API.
+	DefaultAPITimeout = 20 * time.Second
+	repoName          = "repo"
+	sectorsName       = "sectors"
 )
 
 // RunSuccessFirstLine executes the given command, asserts success and returns
@@ -59,10 +69,15 @@ func RunSuccessLines(td *TestDaemon, args ...string) []string {
 type TestDaemon struct {
 	containerDir     string // Path to directory containing repo and sectors
 	genesisFile      string
+	importedChain    string
 	keyFiles         []string
+	passphraseFile   string
 	withMiner        string
 	autoSealInterval string
 	isRelay          bool
+	cmdAPIAddr       string
+	mockMineInterval time.Duration
+	mockMineWinner   string
 
 	firstRun bool
 	init     bool
@@ -75,8 +90,17 @@ type TestDaemon struct {
 	process        *exec.Cmd
 	test           *testing.T
 	cmdTimeout     time.Duration
+	apiTimeout     time.Duration
 	defaultAddress string
 	daemonArgs     []string
+
+	// logFile is where the daemon output is copied to, if set
+	logFile string
+	log     *os.File
+
+	// panicReport is the report of a panic of the daemon, found in its stderr
+	// once it exited
+	panicReport string
 }
 
 // RepoDir returns the repo directory of the test daemon.
@@ -99,13 +123,62 @@ func (td *TestDaemon) CmdAddr() (ma.Multiaddr, error) {
 	return ma.NewMultiaddr(strings.TrimSpace(string(str)))
 }
 
-// Config is a helper to read out the config of the daemon.
+// APIURL returns the base URL of the HTTP API of the test daemon (if it is
+// running), e.g. http://127.0.0.1:3453/api.
+func (td *TestDaemon) APIURL() (string, error) {
+	addr, err := ioutil.ReadFile(filepath.Join(td.RepoDir(), "api"))
+	if err != nil {
+		return "", err
+	}
+	return APIURLFromAddr(strings.TrimSpace(string(addr)))
+}
+
+// Config is a helper to read out the config of the daemon from its repo. Use
+// ConfigGet for the values of a running daemon.
 func (td *TestDaemon) Config() *config.Config {
 	cfg, err := config.ReadFile(filepath.Join(td.RepoDir(), "config.json"))
 	require.NoError(td.test, err)
 	return cfg
 }
 
+// ConfigGet returns the JSON value of the config field at the dotted key,
+// e.g. `mining.minerAddress`, as the running daemon sees it.
+// equivalent to:
+//     `go-filecoin config $KEY`
+func (td *TestDaemon) ConfigGet(key string) string {
+	td.test.Helper()
+	return td.RunSuccess("config", key).ReadStdoutTrimNewlines()
+}
+
+// ConfigSet sets the config field at the dotted key to value, which is
+// parsed as JSON if it is valid JSON and taken as a string otherwise. The
+// daemon persists the change to its repo and fails the test if the key is
+// not in the config or the value has the wrong type.
+// equivalent to:
+//     `go-filecoin config $KEY $VALUE`
+func (td *TestDaemon) ConfigSet(key, value string) {
+	td.test.Helper()
+	td.RunSuccess("config", key, value)
+}
+
+// ConfigDiff returns the keys of the config fields whose values differ between
+// this daemon and the other daemon.
+// equivalent to:
+//     `go-filecoin config diff $OTHER_REPO_DIR`
+func (td *TestDaemon) ConfigDiff(other *TestDaemon) []string {
+	td.test.Helper()
+	out := td.RunSuccess("config", "diff", other.RepoDir(), "--enc=json")
+
+	var diffs []config.FieldDiff
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &diffs))
+
+	keys := make([]string, len(diffs))
+	for i, d := range diffs {
+		keys[i] = d.Key
+	}
+	return keys
+}
+
 // GetMinerAddress returns the miner address for this daemon.
 func (td *TestDaemon) GetMinerAddress() address.Address {
 	return td.Config().Mining.MinerAddress
@@ -121,10 +194,55 @@ func (td *TestDaemon) Run(args ...string) *CmdOutput {
 // stdin of the process.
 func (td *TestDaemon) RunWithStdin(stdin io.Reader, args ...string) *CmdOutput {
 	td.test.Helper()
-	bin := MustGetFilecoinBinary()
+	return td.RunWithOpts(RunOpts{Stdin: stdin}, args...)
+}
+
+// RunOpts are options of a single command run against a test daemon.
+type RunOpts struct {
+	// Stdin is the stdin of the command, if not nil.
+	Stdin io.Reader
+	// Timeout overrides the daemon's command timeout for this command, if not zero.
+	Timeout time.Duration
+}
+
+// RunWithOpts executes the given command against the test daemon with the given
+// options, e.g. a longer timeout for a command that waits on the chain.
+func (td *TestDaemon) RunWithOpts(opts RunOpts, args ...string) *CmdOutput {
+	td.test.Helper()
+	return td.run(opts, nil, args...)
+}
+
+// RunStreaming executes the given command against the test daemon and calls handler with
+// each line of its stdout as it arrives, rather than once the command completes. The
+// returned output still holds all of stdout.
+func (td *TestDaemon) RunStreaming(handler func(line string), args ...string) *CmdOutput {
+	td.test.Helper()
+	lw := &lineWriter{handler: handler}
+	o := td.run(RunOpts{}, lw, args...)
+	lw.Flush()
+	return o
+}
 
-	ctx, cancel := context.WithTimeout(context.Background(), td.cmdTimeout)
-	defer cancel()
+// RunAsync starts the given command against the test daemon without waiting for it
+// to complete. The returned function waits for the command and returns its output;
+// like the command's assertions, it must be called on the test goroutine.
+func (td *TestDaemon) RunAsync(args ...string) func() *CmdOutput {
+	td.test.Helper()
+	return td.start(RunOpts{}, nil, args...)
+}
+
+// run executes the given command against the test daemon, copying its stdout to outW if
+// it is not nil.
+func (td *TestDaemon) run(opts RunOpts, outW io.Writer, args ...string) *CmdOutput {
+	td.test.Helper()
+	return td.start(opts, outW, args...)()
+}
+
+// start starts the given command against the test daemon, copying its stdout to outW
+// if it is not nil, and returns a function waiting for the command to complete.
+func (td *TestDaemon) start(opts RunOpts, outW io.Writer, args ...string) func() *CmdOutput {
+	td.test.Helper()
+	bin := MustGetFilecoinBinary()
 
 	addr, err := td.CmdAddr()
 	require.NoError(td.test, err)
@@ -136,43 +254,70 @@ func (td *TestDaemon) RunWithStdin(stdin io.Reader, args ...string) *CmdOutput {
 
 	finalArgs := append(args, "--repodir="+td.RepoDir(), "--cmdapiaddr="+addr.String())
 
+	timeout := td.cmdTimeout
+	if opts.Timeout != 0 {
+		timeout = opts.Timeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+
 	td.logRun(finalArgs...)
 	cmd := exec.CommandContext(ctx, bin, finalArgs...)
 
-	if stdin != nil {
-		cmd.Stdin = stdin
+	if opts.Stdin != nil {
+		cmd.Stdin = opts.Stdin
 	}
 
 	stderr, err := cmd.StderrPipe()
-	require.NoError(td.test, err)
+	if err != nil {
+		cancel()
+		require.NoError(td.test, err)
+	}
 
 	stdout, err := cmd.StdoutPipe()
-	require.NoError(td.test, err)
+	if err != nil {
+		cancel()
+		require.NoError(td.test, err)
+	}
+
+	if err := cmd.Start(); err != nil {
+		cancel()
+		require.NoError(td.test, err)
+	}
+
+	// Read the output as it arrives, so the command never blocks on a full pipe
+	// while nobody waits for it.
+	output := make(chan *CmdOutput, 1)
+	go func() {
+		output <- StreamOutput(td.test, args, stdout, stderr, outW, nil)
+	}()
 
-	require.NoError(td.test, cmd.Start())
+	return func() *CmdOutput {
+		td.test.Helper()
+		defer cancel()
 
-	o := ReadOutput(td.test, args, stdout, stderr)
-	td.test.Logf("stdout\n%s", o.ReadStdout())
-	td.test.Logf("stderr\n%s", o.ReadStderr())
+		o := <-output
+		td.test.Logf("stdout\n%s", o.ReadStdout())
+		td.test.Logf("stderr\n%s", o.ReadStderr())
 
-	err = cmd.Wait()
+		err := cmd.Wait()
 
-	switch err := err.(type) {
-	case *exec.ExitError:
-		if ctx.Err() == context.DeadlineExceeded {
-			o.SetInvocationError(errors.Wrapf(err, "context deadline exceeded for command: %q", strings.Join(finalArgs, " ")))
-		} else {
+		switch err := err.(type) {
+		case *exec.ExitError:
 			// "Successful" invocation, but a non-zero exit code.
-			// It's non-trivial to get the real 'exit code' cross platform, so just use "1".
-			o.SetStatus(1)
+			o.SetStatus(ExitCode(err))
+			if ctx.Err() == context.DeadlineExceeded {
+				// Keep the status, which shows the process was killed, but fail
+				// reads of the incomplete output.
+				o.error = errors.Wrapf(err, "context deadline exceeded after %s for command: %q", timeout, strings.Join(finalArgs, " "))
+			}
+		default:
+			o.SetInvocationError(err)
+		case nil:
+			o.SetStatus(0)
 		}
-	default:
-		o.SetInvocationError(err)
-	case nil:
-		o.SetStatus(0)
-	}
 
-	return o
+		return o
+	}
 }
 
 // RunSuccess is like Run, but asserts that the command exited successfully.
@@ -190,25 +335,16 @@ func (td *TestDaemon) RunFail(err string, args ...string) *CmdOutput {
 
 // GetID returns the id of the daemon.
 func (td *TestDaemon) GetID() string {
-	out := td.RunSuccess("id")
-	var parsed map[string]interface{}
-	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &parsed))
-
-	return parsed["ID"].(string)
+	var parsed struct{ ID string }
+	RunSuccessJSON(td, &parsed, "id")
+	return parsed.ID
 }
 
 // GetAddresses returns all of the addresses of the daemon.
 func (td *TestDaemon) GetAddresses() []string {
-	out := td.RunSuccess("id")
-	var parsed map[string]interface{}
-	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &parsed))
-	adders := parsed["Addresses"].([]interface{})
-
-	res := make([]string, len(adders))
-	for i, addr := range adders {
-		res[i] = addr.(string)
-	}
-	return res
+	var parsed struct{ Addresses []string }
+	RunSuccessJSON(td, &parsed, "id")
+	return parsed.Addresses
 }
 
 // ConnectSuccess connects the daemon to another daemon, asserting that
@@ -274,6 +410,38 @@ Outer:
 	return out
 }
 
+// DisconnectSuccess disconnects the daemon from another daemon, asserting
+// that afterwards neither of them lists the other as a peer.
+func (td *TestDaemon) DisconnectSuccess(remote *TestDaemon) *CmdOutput {
+	td.test.Helper()
+
+	remoteAddrs := remote.GetAddresses()
+	require.NotEmpty(td.test, remoteAddrs, "remote daemon has no addresses")
+	out := td.RunSuccess("swarm", "disconnect", remoteAddrs[0])
+
+	localID := td.GetID()
+	remoteID := remote.GetID()
+	delay := 100 * time.Millisecond
+
+	disconnected1 := false
+	disconnected2 := false
+	for i := 0; i < 10; i++ {
+		p1 := td.RunSuccess("swarm", "peers").ReadStdout()
+		p2 := remote.RunSuccess("swarm", "peers").ReadStdout()
+		disconnected1 = !strings.Contains(p1, remoteID)
+		disconnected2 = !strings.Contains(p2, localID)
+		if disconnected1 && disconnected2 {
+			break
+		}
+		time.Sleep(delay)
+	}
+
+	require.True(td.test, disconnected1, "failed to disconnect p1 -> p2")
+	require.True(td.test, disconnected2, "failed to disconnect p2 -> p1")
+
+	return out
+}
+
 // ReadStdout returns a string representation of the stdout of the daemon.
 func (td *TestDaemon) ReadStdout() string {
 	td.lk.Lock()
@@ -314,13 +482,38 @@ func (td *TestDaemon) Start() *TestDaemon {
 	// on first startup import key pairs, if defined
 	if td.firstRun {
 		for _, file := range td.keyFiles {
+			require.NoError(td.test, td.ValidateKeyFile(file))
 			td.RunSuccess("wallet", "import", file)
 		}
+		if td.importedChain != "" {
+			td.syncImportedChain()
+		}
 	}
 
 	return td
 }
 
+// syncImportedChain has the daemon sync to the head of the car file it was
+// initialized from. Its blocks are already in the repo, so the syncer only
+// computes and validates the state of each tipset before setting the head.
+func (td *TestDaemon) syncImportedChain() {
+	f, err := os.Open(td.importedChain)
+	require.NoError(td.test, err)
+	defer func() { _ = f.Close() }()
+	header, err := car.ReadHeader(bufio.NewReader(f))
+	require.NoError(td.test, err)
+
+	head := block.NewTipSetKey(header.Roots...)
+	args := []string{"chain", "sync", td.GetID()}
+	for _, c := range header.Roots {
+		args = append(args, c.String())
+	}
+	td.RunSuccess(args...)
+	require.NoError(td.test, WaitForIt(100, 100*time.Millisecond, func() (bool, error) {
+		return td.HeadKey().Equals(head), nil
+	}), "daemon did not sync to the imported head %s", head)
+}
+
 // Stop stops the daemon
 func (td *TestDaemon) Stop() *TestDaemon {
 	if err := td.process.Process.Signal(syscall.SIGINT); err != nil {
@@ -339,21 +532,34 @@ func (td *TestDaemon) Restart() *TestDaemon {
 	return td.Start()
 }
 
-// Shutdown stops the daemon and deletes the repository.
-func (td *TestDaemon) Shutdown() {
+// shutdownTimeout is how long the shutdown methods wait for the daemon to exit
+// after signalling it, before they kill it.
+const shutdownTimeout = 30 * time.Second
+
+// Shutdown stops the daemon and deletes the repository. It returns false if
+// the daemon did not exit within the shutdown timeout and had to be killed.
+func (td *TestDaemon) Shutdown() bool {
 	if err := td.process.Process.Signal(syscall.SIGTERM); err != nil {
 		td.test.Errorf("Daemon Stderr:\n%s", td.ReadStderr())
 		td.test.Fatalf("Failed to kill daemon %s", err)
 	}
 
+	graceful := td.waitForExit(syscall.SIGTERM)
 	td.cleanupFilesystem()
+	return graceful
 }
 
-// ShutdownSuccess stops the daemon, asserting that it exited successfully.
+// ShutdownSuccess stops the daemon, asserting that it exited successfully
+// within the shutdown timeout.
 func (td *TestDaemon) ShutdownSuccess() {
 	err := td.process.Process.Signal(syscall.SIGTERM)
 	assert.NoError(td.test, err)
 
+	graceful := td.waitForExit(syscall.SIGTERM)
+	if td.PanicOccurred() {
+		td.test.Errorf("daemon panicked:\n%s", td.panicReport)
+	}
+	assert.True(td.test, graceful, "daemon did not exit within %s", shutdownTimeout)
 	td.assertNoLogErrors()
 	td.cleanupFilesystem()
 }
@@ -378,195 +584,1446 @@ func (td *TestDaemon) assertNoLogErrors() {
 	assert.NotContains(td.test, filteredStdErr, "ERROR")
 }
 
-// ShutdownEasy stops the daemon using `SIGINT`.
-func (td *TestDaemon) ShutdownEasy() {
+// ShutdownEasy stops the daemon using `SIGINT`. It returns false if the
+// daemon did not exit within the shutdown timeout and had to be killed.
+func (td *TestDaemon) ShutdownEasy() bool {
 	err := td.process.Process.Signal(syscall.SIGINT)
 	assert.NoError(td.test, err)
-	tdOut := td.ReadStderr()
-	assert.NoError(td.test, err, tdOut)
 
+	graceful := td.waitForExit(syscall.SIGINT)
 	td.cleanupFilesystem()
+	return graceful
+}
+
+// PanicOccurred returns true if the daemon panicked. Panics are only found
+// once the daemon was shut down.
+func (td *TestDaemon) PanicOccurred() bool {
+	td.lk.Lock()
+	defer td.lk.Unlock()
+	return td.panicReport != ""
+}
+
+// waitForExit waits for the daemon, which was sent sig, to exit, so that its
+// repo is not deleted while it still uses it. It kills the daemon if it does
+// not exit within the shutdown timeout and returns whether it exited by
+// itself. The output of the daemon can still be read afterwards.
+func (td *TestDaemon) waitForExit(sig os.Signal) bool {
+	td.lk.Lock()
+	stdoutR, stderrR := td.Stdout, td.Stderr
+	td.lk.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		// Buffer the output, since waiting closes the pipes.
+		var stdout []byte
+		read := make(chan struct{})
+		go func() {
+			stdout, _ = ioutil.ReadAll(stdoutR)
+			close(read)
+		}()
+		stderr, _ := ioutil.ReadAll(stderrR)
+		<-read
+		_ = td.process.Wait()
+
+		td.lk.Lock()
+		td.Stdout, td.Stderr = bytes.NewReader(stdout), bytes.NewReader(stderr)
+		td.panicReport = PanicReport(string(stderr))
+		td.lk.Unlock()
+	}()
+
+	select {
+	case <-done:
+		return true
+	case <-time.After(shutdownTimeout):
+		td.test.Logf("daemon did not exit within %s of %s, killing it", shutdownTimeout, sig)
+		if err := td.process.Process.Kill(); err != nil {
+			td.test.Logf("failed to kill daemon: %s", err)
+		}
+		<-done
+		return false
+	}
 }
 
+// Bounds of the exponential backoff between WaitForAPI checks.
+const (
+	apiCheckMinDelay = 50 * time.Millisecond
+	apiCheckMaxDelay = time.Second
+)
+
 // WaitForAPI polls if the API on the daemon is available, and blocks until
-// it is.
+// it is or the daemon's API timeout passed. The error then includes that of
+// the last check, which usually tells why the daemon did not come online.
 func (td *TestDaemon) WaitForAPI() error {
-	var err error
-	for i := 0; i < 100; i++ {
-		err = tryAPICheck(td)
+	deadline := time.Now().Add(td.apiTimeout)
+	delay := apiCheckMinDelay
+	for {
+		err := tryAPICheck(td)
 		if err == nil {
 			return nil
 		}
-		time.Sleep(time.Millisecond * 100)
+
+		remaining := time.Until(deadline)
+		if remaining <= 0 {
+			return errors.Wrapf(err, "filecoin node failed to come online in %s; last err", td.apiTimeout)
+		}
+		if delay > remaining {
+			delay = remaining
+		}
+		time.Sleep(delay)
+
+		delay *= 2
+		if delay > apiCheckMaxDelay {
+			delay = apiCheckMaxDelay
+		}
 	}
-	return fmt.Errorf("filecoin node failed to come online in given time period (10 seconds); last err = %s", err)
 }
 
-// CreateStorageMinerAddr issues a new message to the network, mines the message
-// and returns the address of the new miner
+// HealthCheck is the state of one subsystem in the output of the health
+// command.
+type HealthCheck struct {
+	State  string
+	Detail string
+}
+
+// HealthReport is the output of the health command.
+type HealthReport struct {
+	State       string
+	Sync        HealthCheck
+	MessagePool HealthCheck
+	Wallet      HealthCheck
+	Peers       HealthCheck
+}
+
+// Health reports the health of the daemon's subsystems.
 // equivalent to:
-//     `go-filecoin miner create --from $TEST_ACCOUNT 20`
-func (td *TestDaemon) CreateStorageMinerAddr(peer *TestDaemon, fromAddr string) address.Address {
-	var wg sync.WaitGroup
-	var minerAddr address.Address
-	wg.Add(1)
-	go func() {
-		miner := td.RunSuccess("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "100", "20")
-		addr, err := address.NewFromString(strings.Trim(miner.ReadStdout(), "\n"))
-		require.NoError(td.test, err)
-		require.NotEqual(td.test, addr, address.Undef)
-		minerAddr = addr
-		wg.Done()
-	}()
-	wg.Wait()
+//     `go-filecoin health`
+func (td *TestDaemon) Health() HealthReport {
+	td.test.Helper()
+	out := td.RunSuccess("health", "--enc=json")
 
-	return minerAddr
+	var report HealthReport
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &report))
+	return report
 }
 
-// MinerSetPrice creates an ask for a CURRENTLY MINING test daemon and waits for it to appears on chain. It returns the
-// cid of the AddAsk message so other daemons can `message wait` for it.
-func (td *TestDaemon) MinerSetPrice(minerAddr string, fromAddr string, price string, expiry string) cid.Cid {
-	setPriceReturn := td.RunSuccess("miner", "set-price",
-		"--from", fromAddr,
-		"--miner", minerAddr,
-		"--gas-price", "1",
-		"--gas-limit", "300",
-		"--enc", "json",
-		price, expiry).ReadStdout()
+// CreateStorageMinerAddr issues a message creating a new miner, has peer mine
+// a block once the message is in peer's message pool, and returns the address
+// of the new miner and the cid of the creating message.
+// equivalent to:
+//     `go-filecoin miner create --from $TEST_ACCOUNT 20`
+func (td *TestDaemon) CreateStorageMinerAddr(peer *TestDaemon, fromAddr string) (address.Address, cid.Cid) {
+	td.test.Helper()
+	from, err := address.NewFromString(fromAddr)
+	require.NoError(td.test, err)
 
-	resultStruct := struct {
-		MinerSetPriceResponse struct {
-			AddAskCid cid.Cid
+	// miner create returns only once its message is mined, so it runs
+	// while peer mines.
+	waitCreated := td.RunAsync("miner", "create", "--from", fromAddr, "--gas-price", "1", "--gas-limit", "100", "20")
+
+	var msgCid cid.Cid
+	err = WaitForIt(int(td.cmdTimeout/(100*time.Millisecond)), 100*time.Millisecond, func() (bool, error) {
+		for _, msg := range peer.MpoolLs() {
+			if msg.Message.From == from && msg.Message.To == address.StorageMarketAddress && msg.Message.Method == storagemarket.CreateStorageMiner {
+				msgCid, err = msg.Cid()
+				return true, err
+			}
 		}
-	}{}
+		return false, nil
+	})
+	require.NoError(td.test, err, "miner create message never reached the message pool")
 
-	if err := json.Unmarshal([]byte(setPriceReturn), &resultStruct); err != nil {
-		require.NoError(td.test, err)
-	}
-	return resultStruct.MinerSetPriceResponse.AddAskCid
-}
+	peer.RunSuccess("mining", "once")
 
-// UpdatePeerID updates a currently mining miner's peer ID
-func (td *TestDaemon) UpdatePeerID() {
-	var idOutput map[string]interface{}
-	peerIDJSON := td.RunSuccess("id").ReadStdout()
-	err := json.Unmarshal([]byte(peerIDJSON), &idOutput)
+	out := waitCreated().AssertSuccess()
+	minerAddr, err := address.NewFromString(out.ReadStdoutTrimNewlines())
 	require.NoError(td.test, err)
-	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=300", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
-	updateCid, err := cid.Parse(updateCidStr)
+	require.NotEqual(td.test, address.Undef, minerAddr)
+	return minerAddr, msgCid
+}
+
+// CreateMinerWithSectorSize issues a new message to the network creating a
+// miner which commits sectors of `size` bytes, and returns the address of the
+// new miner once the message has been mined. Some other daemon must be mining.
+// equivalent to:
+//     `go-filecoin miner create --from $FROM --sector-size $SIZE 20`
+func (td *TestDaemon) CreateMinerWithSectorSize(fromAddr string, size uint64) address.Address {
+	td.test.Helper()
+	miner := td.RunSuccess("miner", "create",
+		"--from", fromAddr,
+		"--sector-size", strconv.FormatUint(size, 10),
+		"--gas-price", "1",
+		"--gas-limit", "300",
+		"20")
+	addr, err := address.NewFromString(strings.Trim(miner.ReadStdout(), "\n"))
 	require.NoError(td.test, err)
-	assert.NotNil(td.test, updateCid)
+	require.NotEqual(td.test, addr, address.Undef)
+	return addr
+}
 
-	td.WaitForMessageRequireSuccess(updateCid)
+// PledgeCost returns the collateral this daemon reports is required to commit
+// the given amount of power in sectors of the default size.
+// equivalent to:
+//     `go-filecoin miner pledge-cost $POWER`
+func (td *TestDaemon) PledgeCost(power *types.BytesAmount) *types.AttoFIL {
+	td.test.Helper()
+	out := td.RunSuccess("miner", "pledge-cost", power.String())
+	cost, ok := types.NewAttoFILFromFILString(strings.Trim(out.ReadStdout(), "\n"))
+	require.True(td.test, ok)
+	return &cost
 }
 
-// WaitForMessageRequireSuccess accepts a message cid and blocks until a message with matching cid is included in a
-// block. The receipt is then inspected to ensure that the corresponding message receipt had a 0 exit code.
-func (td *TestDaemon) WaitForMessageRequireSuccess(msgCid cid.Cid) *types.MessageReceipt {
-	args := []string{"message", "wait", msgCid.String(), "--receipt=true", "--message=false"}
-	trim := strings.Trim(td.RunSuccess(args...).ReadStdout(), "\n")
-	rcpt := &types.MessageReceipt{}
-	require.NoError(td.test, json.Unmarshal([]byte(trim), rcpt))
-	require.Equal(td.test, 0, int(rcpt.ExitCode))
-	return rcpt
+// MinerPower returns the power of the miner at addr and the total power of
+// the storage market.
+// equivalent to:
+//     `go-filecoin miner power $MINER`
+func (td *TestDaemon) MinerPower(addr string) (miner, total *types.BytesAmount) {
+	td.test.Helper()
+	out := td.RunSuccess("miner", "power", addr, "--enc=json")
+
+	var power struct {
+		Power *types.BytesAmount
+		Total *types.BytesAmount
+	}
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &power))
+	return power.Power, power.Total
 }
 
-// CreateAddress adds a new address to the daemons wallet and
-// returns it.
+// MiningCheck returns an error describing why this daemon cannot sign blocks
+// for its configured miner, or nil if it can.
 // equivalent to:
-//     `go-filecoin address new`
-func (td *TestDaemon) CreateAddress() string {
+//     `go-filecoin mining check`
+func (td *TestDaemon) MiningCheck() error {
 	td.test.Helper()
-	outNew := td.RunSuccess("address", "new")
-	addr := strings.Trim(outNew.ReadStdout(), "\n")
-	require.NotEmpty(td.test, addr)
-	return addr
+	out := td.Run("mining", "check")
+	status, err := out.Status()
+	require.NoError(td.test, err)
+	if status != 0 {
+		return errors.New(strings.TrimSpace(out.ReadStderr()))
+	}
+	return nil
 }
 
-// MineAndPropagate mines a block and ensure the block has propagated to all `peers`
-// by comparing the current head block of `td` with the head block of each peer in `peers`
-func (td *TestDaemon) MineAndPropagate(wait time.Duration, peers ...*TestDaemon) {
-	td.RunSuccess("mining", "once")
-	// short circuit
-	if peers == nil {
-		return
+// MinerWorker returns the worker address of the miner at addr and whether
+// this daemon's wallet holds the worker key.
+// equivalent to:
+//     `go-filecoin miner worker $MINER`
+func (td *TestDaemon) MinerWorker(addr string) (address.Address, bool) {
+	td.test.Helper()
+	out := td.RunSuccess("miner", "worker", addr, "--enc=json")
+	var res struct {
+		WorkerAddress address.Address `json:"workerAddress"`
+		InWallet      bool            `json:"inWallet"`
 	}
-	// ensure all peers have same chain head as `td`
-	td.MustHaveChainHeadBy(wait, peers)
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &res))
+	return res.WorkerAddress, res.InWallet
 }
 
-// MustHaveChainHeadBy ensures all `peers` have the same chain head as `td`, by
-// duration `wait`
-func (td *TestDaemon) MustHaveChainHeadBy(wait time.Duration, peers []*TestDaemon) {
-	// will signal all nodes have completed check
-	done := make(chan struct{})
-	var wg sync.WaitGroup
+// SetMinerWorker changes the worker of miner to worker with a message signed
+// by the miner owner, and waits for the message to succeed. Some daemon must
+// be mining.
+// equivalent to:
+//     `go-filecoin miner set-worker $MINER $WORKER`
+func (td *TestDaemon) SetMinerWorker(miner, worker string) {
+	td.test.Helper()
+	out := td.RunSuccess("miner", "set-worker", "--gas-price=1", "--gas-limit=300", miner, worker)
+	msgCid, err := cid.Parse(out.ReadStdoutTrimNewlines())
+	require.NoError(td.test, err)
+	td.WaitForMessageRequireSuccess(msgCid)
+}
 
-	expHeadBlks := td.GetChainHead()
-	var expHeadCids []cid.Cid
-	for _, blk := range expHeadBlks {
-		expHeadCids = append(expHeadCids, blk.Cid())
+// PendingSectors returns the committed but not yet proven sectors of the
+// miner at addr, mapping sector id to the height by which it must be proven.
+// equivalent to:
+//     `go-filecoin miner pending-sectors $MINER`
+func (td *TestDaemon) PendingSectors(addr string) map[uint64]uint64 {
+	td.test.Helper()
+	out := td.RunSuccess("miner", "pending-sectors", addr, "--enc=json")
+	var res []struct {
+		SectorID uint64
+		Deadline types.BlockHeight
 	}
-	expHeadKey := block.NewTipSetKey(expHeadCids...)
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &res))
 
-	for _, p := range peers {
-		wg.Add(1)
-		go func(p *TestDaemon) {
-			for {
-				actHeadBlks := p.GetChainHead()
-				var actHeadCids []cid.Cid
-				for _, blk := range actHeadBlks {
-					actHeadCids = append(actHeadCids, blk.Cid())
-				}
-				actHeadKey := block.NewTipSetKey(actHeadCids...)
-				if expHeadKey.Equals(actHeadKey) {
-					wg.Done()
-					return
-				}
-				time.Sleep(100 * time.Millisecond)
-			}
-		}(p)
+	pending := make(map[uint64]uint64, len(res))
+	for _, sector := range res {
+		pending[sector.SectorID] = sector.Deadline.AsBigInt().Uint64()
 	}
+	return pending
+}
 
-	go func() {
-		wg.Wait()
-		done <- struct{}{}
-	}()
+// ExportMinerDeals writes the manifest of the deals of the miner at addr to
+// path, equivalent to:
+//     `go-filecoin miner export-deals $MINER $PATH`
+func (td *TestDaemon) ExportMinerDeals(addr, path string) {
+	td.test.Helper()
+	td.RunSuccess("miner", "export-deals", addr, path)
+}
 
-	select {
-	case <-done:
-		return
-	case <-time.After(wait):
-		td.test.Fatal("Timeout waiting for chains to sync")
-	}
+// DealRecord is a deal as listed by the client list-deals and miner
+// list-deals commands.
+type DealRecord struct {
+	ProposalCid  cid.Cid         `json:"proposalCid"`
+	State        string          `json:"state"`
+	TotalPrice   types.AttoFIL   `json:"totalPrice"`
+	Duration     uint64          `json:"duration"`
+	Counterparty address.Address `json:"counterparty"`
 }
 
-// GetChainHead returns the blocks in the head tipset from `td`
-func (td *TestDaemon) GetChainHead() []block.Block {
-	out := td.RunSuccess("chain", "ls", "--enc=json")
-	bc := td.MustUnmarshalChain(out.ReadStdout())
-	return bc[0]
+// ClientListDeals returns the deals this daemon proposed as a client, only
+// those in `states` if any are given.
+// equivalent to:
+//     `go-filecoin client list-deals --state=$STATES`
+func (td *TestDaemon) ClientListDeals(states ...string) []DealRecord {
+	td.test.Helper()
+	return td.listDeals([]string{"client", "list-deals"}, states)
 }
 
-// MustUnmarshalChain unmarshals the chain from `input` into a slice of blocks
-func (td *TestDaemon) MustUnmarshalChain(input string) [][]block.Block {
-	chain := strings.Trim(input, "\n")
-	var bs [][]block.Block
+// MinerListDeals returns the deals the miner of this daemon received, only
+// those in `states` if any are given.
+// equivalent to:
+//     `go-filecoin miner list-deals --state=$STATES`
+func (td *TestDaemon) MinerListDeals(states ...string) []DealRecord {
+	td.test.Helper()
+	return td.listDeals([]string{"miner", "list-deals"}, states)
+}
 
-	for _, line := range bytes.Split([]byte(chain), []byte{'\n'}) {
-		var b []block.Block
-		if err := json.Unmarshal(line, &b); err != nil {
-			td.test.Fatal(err)
-		}
-		bs = append(bs, b)
+func (td *TestDaemon) listDeals(args []string, states []string) []DealRecord {
+	td.test.Helper()
+	args = append(args, "--enc=json")
+	if len(states) > 0 {
+		args = append(args, "--state="+strings.Join(states, ","))
 	}
+	out := td.RunSuccess(args...)
 
-	return bs
+	var deals []DealRecord
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &deals))
+	return deals
 }
 
-// MakeMoney mines a block and ensures that the block has been propagated to all peers.
-func (td *TestDaemon) MakeMoney(rewards int, peers ...*TestDaemon) {
-	for i := 0; i < rewards; i++ {
+// AuditDeal spot-checks that the miner of the deal with proposal CID negid
+// still holds its data and returns whether the audit passed.
+// equivalent to:
+//     `go-filecoin client audit-deal $NEGID`
+func (td *TestDaemon) AuditDeal(negid string) bool {
+	td.test.Helper()
+	out := td.RunSuccess("client", "audit-deal", negid, "--enc=json")
+
+	var result struct{ Passed bool }
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &result))
+	return result.Passed
+}
+
+// DealLog returns the negotiation transcript of the deal proposal with the
+// given id, equivalent to:
+//     `go-filecoin client deal-log $NEGID`
+func (td *TestDaemon) DealLog(negid string) *storagedeal.Transcript {
+	td.test.Helper()
+	out := td.RunSuccess("client", "deal-log", negid, "--enc=json")
+
+	var transcript storagedeal.Transcript
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &transcript))
+	return &transcript
+}
+
+// WaitDealState blocks until the deal with the given id reaches the given
+// state or a later one, and fails the test if the deal fails instead.
+// equivalent to:
+//     `go-filecoin client wait-deal $ID --state $STATE`
+func (td *TestDaemon) WaitDealState(id, state string) {
+	td.test.Helper()
+	td.RunSuccess("client", "wait-deal", id, "--state", state)
+}
+
+// DropPiece makes the node's retrieval miner behave as if it lost the piece,
+// equivalent to:
+//     `go-filecoin dev drop-piece $PIECE`
+func (td *TestDaemon) DropPiece(pieceCid string) {
+	td.test.Helper()
+	td.RunSuccess("dev", "drop-piece", pieceCid)
+}
+
+// RetrieveData retrieves the piece with the given cid from the miner of the
+// `miner` daemon and returns its bytes.
+// equivalent to:
+//     `go-filecoin retrieval-client retrieve $MINER $CID $FILE`
+func (td *TestDaemon) RetrieveData(miner *TestDaemon, cid string) []byte {
+	td.test.Helper()
+	outFile := filepath.Join(td.containerDir, "retrieved-"+cid)
+	td.RunSuccess("retrieval-client", "retrieve", miner.GetMinerAddress().String(), cid, outFile)
+
+	data, err := ioutil.ReadFile(outFile)
+	require.NoError(td.test, err)
+	require.NoError(td.test, os.Remove(outFile))
+	return data
+}
+
+// SetMinGasPrice sets the lowest gas price of messages the daemon includes in
+// the blocks it mines, equivalent to:
+//     `go-filecoin mining set-min-gas-price $PRICE`
+func (td *TestDaemon) SetMinGasPrice(price *types.AttoFIL) {
+	td.test.Helper()
+	td.RunSuccess("mining", "set-min-gas-price", price.String())
+}
+
+// DecodeSignature returns the components of sig.
+// equivalent to:
+//     `go-filecoin wallet decode-sig $SIG`
+func (td *TestDaemon) DecodeSignature(sig types.Signature) types.SignatureComponents {
+	td.test.Helper()
+	out := td.RunSuccess("wallet", "decode-sig", hex.EncodeToString(sig), "--enc=json")
+
+	var components types.SignatureComponents
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &components))
+	return components
+}
+
+// SignData signs data with the key of addr.
+// equivalent to:
+//     `go-filecoin wallet sign --from $ADDR < $DATA`
+func (td *TestDaemon) SignData(addr string, data []byte) types.Signature {
+	td.test.Helper()
+	out := td.RunWithStdin(bytes.NewReader(data), "wallet", "sign", "--from", addr).AssertSuccess()
+
+	sig, err := hex.DecodeString(out.ReadStdoutTrimNewlines())
+	require.NoError(td.test, err)
+	return sig
+}
+
+// ImportSeed imports the first count addresses of the mnemonic phrase into
+// the wallet and returns them.
+// equivalent to:
+//     `go-filecoin wallet import-seed --count $COUNT < $PHRASE`
+func (td *TestDaemon) ImportSeed(phrase string, count int) []string {
+	td.test.Helper()
+	out := td.RunWithStdin(strings.NewReader(phrase), "wallet", "import-seed", "--count", strconv.Itoa(count), "--enc=json").AssertSuccess()
+
+	var addrs struct{ Addresses []string }
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &addrs))
+	return addrs.Addresses
+}
+
+// NullRounds queues n null rounds ahead of the next mined block, equivalent to:
+//     `go-filecoin dev null-round <n>`
+func (td *TestDaemon) NullRounds(n int) {
+	td.test.Helper()
+	td.RunSuccess("dev", "null-round", strconv.Itoa(n))
+}
+
+// MineUntil starts mining until height and waits for the head to reach it.
+// equivalent to:
+//     `go-filecoin mining start --until <height>`
+func (td *TestDaemon) MineUntil(height uint64) {
+	td.test.Helper()
+	td.RunSuccess("mining", "start", "--until", strconv.FormatUint(height, 10))
+	require.NoError(td.test, WaitForIt(100, BlockTimeTest, func() (bool, error) {
+		return uint64(td.GetChainHead()[0].Height) >= height, nil
+	}))
+}
+
+// MiningStart starts mining blocks continuously.
+// equivalent to:
+//     `go-filecoin mining start`
+func (td *TestDaemon) MiningStart() {
+	td.test.Helper()
+	td.RunSuccess("mining", "start")
+}
+
+// MiningStop stops mining. Once it returns the daemon mines no more blocks.
+// equivalent to:
+//     `go-filecoin mining stop`
+func (td *TestDaemon) MiningStop() {
+	td.test.Helper()
+	td.RunSuccess("mining", "stop")
+}
+
+// MiningStatus is the output of the mining status command.
+type MiningStatus struct {
+	Active       bool               `json:"active"`
+	StopHeight   uint64             `json:"stopHeight"`
+	PausedReason string             `json:"pausedReason"`
+	Miner        address.Address    `json:"minerAddress"`
+	SectorSize   *types.BytesAmount `json:"sectorSize"`
+}
+
+// MiningStatus returns whether the daemon is mining and with which miner and
+// sector size.
+// equivalent to:
+//     `go-filecoin mining status`
+func (td *TestDaemon) MiningStatus() MiningStatus {
+	td.test.Helper()
+	out := td.RunSuccess("mining", "status", "--enc=json")
+
+	var status MiningStatus
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &status))
+	return status
+}
+
+// MinerSetPrice creates an ask for a CURRENTLY MINING test daemon and waits for it to appears on chain. It returns the
+// cid of the AddAsk message so other daemons can `message wait` for it.
+func (td *TestDaemon) MinerSetPrice(minerAddr string, fromAddr string, price string, expiry string) cid.Cid {
+	setPriceReturn := td.RunSuccess("miner", "set-price",
+		"--from", fromAddr,
+		"--miner", minerAddr,
+		"--gas-price", "1",
+		"--gas-limit", "300",
+		"--enc", "json",
+		price, expiry).ReadStdout()
+
+	resultStruct := struct {
+		MinerSetPriceResponse struct {
+			AddAskCid cid.Cid
+		}
+	}{}
+
+	if err := json.Unmarshal([]byte(setPriceReturn), &resultStruct); err != nil {
+		require.NoError(td.test, err)
+	}
+	return resultStruct.MinerSetPriceResponse.AddAskCid
+}
+
+// UpdatePeerID updates a currently mining miner's peer ID
+func (td *TestDaemon) UpdatePeerID() {
+	var idOutput map[string]interface{}
+	peerIDJSON := td.RunSuccess("id").ReadStdout()
+	err := json.Unmarshal([]byte(peerIDJSON), &idOutput)
+	require.NoError(td.test, err)
+	updateCidStr := td.RunSuccess("miner", "update-peerid", "--gas-price=1", "--gas-limit=300", td.GetMinerAddress().String(), idOutput["ID"].(string)).ReadStdoutTrimNewlines()
+	updateCid, err := cid.Parse(updateCidStr)
+	require.NoError(td.test, err)
+	assert.NotNil(td.test, updateCid)
+
+	td.WaitForMessageRequireSuccess(updateCid)
+}
+
+// WaitForMessageRequireSuccess accepts a message cid and blocks until a message with matching cid is included in a
+// block. The receipt is then inspected to ensure that the corresponding message receipt had a 0 exit code.
+// The test fails if the message is not mined within the daemon's command timeout.
+func (td *TestDaemon) WaitForMessageRequireSuccess(msgCid cid.Cid) *types.MessageReceipt {
+	td.test.Helper()
+	rcpt, err := td.WaitForMessageTimeout(msgCid, td.cmdTimeout)
+	require.NoError(td.test, err)
+	require.Equal(td.test, 0, int(rcpt.ExitCode))
+	return rcpt
+}
+
+// WaitForMessageResult blocks until the message with the given cid is mined,
+// requires that it succeeded and decodes its return value into the value
+// pointed to by out. See DecodeReturn for how the return value is decoded.
+// equivalent to:
+//     `go-filecoin message wait $CID --enc=json`
+func (td *TestDaemon) WaitForMessageResult(msgCid cid.Cid, out interface{}) *types.MessageReceipt {
+	td.test.Helper()
+	res := td.RunWithOpts(RunOpts{Timeout: td.cmdTimeout + messageWaitGrace},
+		"message", "wait", msgCid.String(), "--timeout="+td.cmdTimeout.String(), "--enc=json")
+	res.AssertSuccess()
+
+	var wait struct {
+		Message   *types.SignedMessage
+		Receipt   *types.MessageReceipt
+		Signature *struct{ Return []abi.Type }
+	}
+	require.NoError(td.test, json.Unmarshal(res.Stdout(), &wait))
+	require.NotNil(td.test, wait.Receipt, "message %s has no receipt", msgCid)
+	require.Equal(td.test, 0, int(wait.Receipt.ExitCode))
+
+	var returnTypes []abi.Type
+	if wait.Signature != nil {
+		returnTypes = wait.Signature.Return
+	}
+	require.NoError(td.test, DecodeReturn(wait.Message.Message.To, wait.Message.Message.Method, returnTypes, wait.Receipt.Return, out))
+	return wait.Receipt
+}
+
+// messageWaitGrace is how much longer than the message wait itself a test
+// waits for `message wait` to give up on its own.
+const messageWaitGrace = 10 * time.Second
+
+// WaitForMessageTimeout blocks until the message with the given cid is included in a
+// block and returns its receipt. It returns an error if the message is not mined within d.
+// equivalent to:
+//     `go-filecoin message wait $CID --receipt=true --message=false --timeout=$D`
+func (td *TestDaemon) WaitForMessageTimeout(msgCid cid.Cid, d time.Duration) (*types.MessageReceipt, error) {
+	td.test.Helper()
+	out := td.RunWithOpts(RunOpts{Timeout: d + messageWaitGrace},
+		"message", "wait", msgCid.String(), "--receipt=true", "--message=false", "--timeout="+d.String())
+	status, err := out.Status()
+	if err != nil {
+		return nil, err
+	}
+	if status != 0 {
+		return nil, errors.Errorf("message %s was not mined within %s: %s", msgCid, d, strings.TrimSpace(out.ReadStderr()))
+	}
+
+	// With --message=false the text output is the receipt alone.
+	rcpt := &types.MessageReceipt{}
+	if err := json.Unmarshal([]byte(out.ReadStdoutTrimNewlines()), rcpt); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode receipt of %s", msgCid)
+	}
+	return rcpt, nil
+}
+
+// MessageInfo is the output of the message show command. State is one of
+// "unknown", "pending" and "mined".
+type MessageInfo struct {
+	State   string
+	Message *types.SignedMessage
+	Receipt *types.MessageReceipt
+	Block   *cid.Cid
+	Height  uint64
+}
+
+// MessageShow returns the message with the given cid and whether it is
+// unknown, pending or mined, without waiting for it to be mined.
+// equivalent to:
+//     `go-filecoin message show $CID --enc=json`
+func (td *TestDaemon) MessageShow(msgCid cid.Cid) MessageInfo {
+	td.test.Helper()
+	out := td.RunSuccess("message", "show", msgCid.String(), "--enc=json")
+
+	var info MessageInfo
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &info))
+	return info
+}
+
+// ImportFile imports the file at path into the daemon and returns the cid
+// of the imported data. The CLI process reads the file and sends it to the
+// daemon through the API.
+// equivalent to:
+//     `go-filecoin client import $PATH`
+func (td *TestDaemon) ImportFile(path string) string {
+	td.test.Helper()
+	return td.RunSuccess("client", "import", path).ReadStdoutTrimNewlines()
+}
+
+// DagCat returns the data of the DAG with the given root, reassembled from
+// its leaves.
+// equivalent to:
+//     `go-filecoin dag cat $CID`
+func (td *TestDaemon) DagCat(cid string) []byte {
+	td.test.Helper()
+	return []byte(td.RunSuccess("dag", "cat", cid).ReadStdout())
+}
+
+// ActorView is the output of the actor show command. State is the JSON of
+// the decoded state of actors of known types, and empty for other actors.
+type ActorView struct {
+	ActorType string
+	Address   string
+	Code      cid.Cid
+	Nonce     uint64
+	Balance   types.AttoFIL
+	Head      cid.Cid
+	State     json.RawMessage
+}
+
+// ActorState returns the actor at addr and its decoded state.
+// equivalent to:
+//     `go-filecoin actor show $ADDR --enc=json`
+func (td *TestDaemon) ActorState(addr string) ActorView {
+	td.test.Helper()
+	out := td.RunSuccess("actor", "show", addr, "--enc=json")
+
+	var view ActorView
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &view))
+	return view
+}
+
+// ListActors returns the actors in the state tree at the head of the chain.
+// The views have no State.
+// equivalent to:
+//     `go-filecoin actor ls --enc=json`
+func (td *TestDaemon) ListActors() []ActorView {
+	td.test.Helper()
+	return td.listActors("actor", "ls", "--enc=json")
+}
+
+// ListActorsOfCode returns the actors with the given code in the state tree
+// at the head of the chain.
+// equivalent to:
+//     `go-filecoin actor ls --code $CODE --enc=json`
+func (td *TestDaemon) ListActorsOfCode(code cid.Cid) []ActorView {
+	td.test.Helper()
+	return td.listActors("actor", "ls", "--code", code.String(), "--enc=json")
+}
+
+func (td *TestDaemon) listActors(args ...string) []ActorView {
+	td.test.Helper()
+	out := td.RunSuccess(args...)
+
+	var views []ActorView
+	dec := json.NewDecoder(strings.NewReader(out.ReadStdout()))
+	for dec.More() {
+		var view ActorView
+		require.NoError(td.test, dec.Decode(&view))
+		views = append(views, view)
+	}
+	return views
+}
+
+// ReplayMessageAt executes the message with the given cid against the state
+// of the tipset at height and returns the receipt it would get.
+// equivalent to:
+//     `go-filecoin message replay-one $CID --at $HEIGHT`
+func (td *TestDaemon) ReplayMessageAt(msgCid string, height uint64) *types.MessageReceipt {
+	td.test.Helper()
+	out := td.RunSuccess("message", "replay-one", msgCid,
+		"--at", strconv.FormatUint(height, 10),
+		"--enc=json",
+	)
+
+	var result struct {
+		Receipt *types.MessageReceipt
+	}
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &result))
+	require.NotNil(td.test, result.Receipt)
+	return result.Receipt
+}
+
+// MpoolImport adds the newline delimited JSON signed messages in the file at
+// path to the message pool and returns how many were accepted and rejected.
+// equivalent to:
+//     `go-filecoin mpool import $PATH`
+func (td *TestDaemon) MpoolImport(path string) (accepted, rejected int) {
+	td.test.Helper()
+	out := td.RunSuccess("mpool", "import", path, "--enc=json")
+
+	var result struct {
+		Accepted int
+		Rejected int
+	}
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &result))
+	return result.Accepted, result.Rejected
+}
+
+// MpoolLs returns the pending messages, ordered by sender and nonce.
+// equivalent to:
+//     `go-filecoin mpool ls --enc=json`
+func (td *TestDaemon) MpoolLs() []types.SignedMessage {
+	td.test.Helper()
+	out := td.RunSuccess("mpool", "ls", "--enc=json")
+
+	var pending []types.SignedMessage
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &pending))
+	return pending
+}
+
+// WaitForMpoolCount blocks until the message pool holds at least n messages.
+// It returns an error if that does not happen within d.
+// equivalent to:
+//     `go-filecoin mpool wait-for-count $N --timeout $D`
+func (td *TestDaemon) WaitForMpoolCount(n int, d time.Duration) error {
+	td.test.Helper()
+	// Leave the command time to report its own timeout.
+	out := td.RunWithOpts(RunOpts{Timeout: d + td.cmdTimeout}, "mpool", "wait-for-count", strconv.Itoa(n), "--timeout", d.String())
+	status, err := out.Status()
+	if err != nil {
+		return err
+	}
+	if status != 0 {
+		return errors.New(strings.TrimSpace(out.ReadStderr()))
+	}
+	return nil
+}
+
+// Nonce returns the nonce the next message from addr must have, given its
+// on-chain nonce and its pending messages.
+// equivalent to:
+//     `go-filecoin wallet nonce $ADDR`
+func (td *TestDaemon) Nonce(addr string) uint64 {
+	td.test.Helper()
+	out := td.RunSuccess("wallet", "nonce", addr)
+	nonce, err := strconv.ParseUint(out.ReadStdoutTrimNewlines(), 10, 64)
+	require.NoError(td.test, err)
+	return nonce
+}
+
+// MpoolRm removes the pending message with cid msgCid, and the pending
+// messages of its sender with higher nonces, and returns the removed cids.
+// equivalent to:
+//     `go-filecoin mpool rm $CID`
+func (td *TestDaemon) MpoolRm(msgCid cid.Cid) []cid.Cid {
+	td.test.Helper()
+	out := td.RunSuccess("mpool", "rm", msgCid.String(), "--enc=json")
+
+	var removed []cid.Cid
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &removed))
+	return removed
+}
+
+// MpoolConflict is a sender nonce listed by the mpool check-conflicts command.
+type MpoolConflict struct {
+	From    address.Address
+	Nonce   uint64
+	Pending []cid.Cid
+	Mined   []cid.Cid
+}
+
+// MpoolConflicts returns the nonces that a pending message shares with a
+// different pending or recently mined message.
+// equivalent to:
+//     `go-filecoin mpool check-conflicts`
+func (td *TestDaemon) MpoolConflicts() []MpoolConflict {
+	td.test.Helper()
+	out := td.RunSuccess("mpool", "check-conflicts", "--enc=json")
+
+	var conflicts []MpoolConflict
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &conflicts))
+	return conflicts
+}
+
+// CreateAddress adds a new address to the daemons wallet and
+// returns it.
+// equivalent to:
+//     `go-filecoin address new`
+func (td *TestDaemon) CreateAddress() string {
+	td.test.Helper()
+	outNew := td.RunSuccess("address", "new")
+	addr := strings.Trim(outNew.ReadStdout(), "\n")
+	require.NotEmpty(td.test, addr)
+	return addr
+}
+
+// CreateWalletAddrOfType adds a new address with a key of type keyType,
+// secp256k1 or bls, to the daemons wallet and returns it.
+// equivalent to:
+//     `go-filecoin address new --type $KEY_TYPE`
+func (td *TestDaemon) CreateWalletAddrOfType(keyType string) string {
+	td.test.Helper()
+	outNew := td.RunSuccess("address", "new", "--type", keyType)
+	addr := strings.Trim(outNew.ReadStdout(), "\n")
+	require.NotEmpty(td.test, addr)
+	return addr
+}
+
+// MineAndPropagate mines a block and ensure the block has propagated to all `peers`
+// by comparing the current head block of `td` with the head block of each peer in `peers`
+func (td *TestDaemon) MineAndPropagate(wait time.Duration, peers ...*TestDaemon) {
+	td.RunSuccess("mining", "once")
+	// short circuit
+	if peers == nil {
+		return
+	}
+	// ensure all peers have same chain head as `td`
+	td.MustHaveChainHeadBy(wait, peers)
+}
+
+// MustHaveChainHeadBy ensures all `peers` have the same chain head as `td`, by
+// duration `wait`
+func (td *TestDaemon) MustHaveChainHeadBy(wait time.Duration, peers []*TestDaemon) {
+	// will signal all nodes have completed check
+	done := make(chan struct{})
+	var wg sync.WaitGroup
+
+	expHeadKey := td.HeadKey()
+
+	for _, p := range peers {
+		wg.Add(1)
+		go func(p *TestDaemon) {
+			for {
+				if expHeadKey.Equals(p.HeadKey()) {
+					wg.Done()
+					return
+				}
+				time.Sleep(100 * time.Millisecond)
+			}
+		}(p)
+	}
+
+	go func() {
+		wg.Wait()
+		done <- struct{}{}
+	}()
+
+	select {
+	case <-done:
+		return
+	case <-time.After(wait):
+		td.test.Fatal("Timeout waiting for chains to sync")
+	}
+}
+
+// ChainHead returns the head tipset of `td`, equivalent to:
+//     `go-filecoin chain head`
+func (td *TestDaemon) ChainHead() block.TipSet {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "head", "--enc=json")
+
+	var head struct {
+		Key    block.TipSetKey
+		Blocks []*block.Block
+	}
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &head))
+	ts, err := block.NewTipSet(head.Blocks...)
+	require.NoError(td.test, err)
+	require.True(td.test, head.Key.Equals(ts.Key()), "head key %s does not match its blocks", head.Key)
+	return ts
+}
+
+// HeadKey returns the key of the head tipset of `td`, equivalent to:
+//     `go-filecoin chain head`
+func (td *TestDaemon) HeadKey() block.TipSetKey {
+	td.test.Helper()
+	return td.ChainHead().Key()
+}
+
+// ChainExport writes the chain of `td`, from its head back to genesis, to a
+// car file at path, equivalent to:
+//     `go-filecoin chain export <path>`
+func (td *TestDaemon) ChainExport(path string) {
+	td.test.Helper()
+	td.RunSuccess("chain", "export", path)
+}
+
+// ClusterConsensus reports whether `td` and all `peers` have the same head
+// tipset, along with the head key of each of them by peer ID. Unlike
+// MustHaveChainHeadBy it does not wait for the heads to converge.
+func (td *TestDaemon) ClusterConsensus(peers []*TestDaemon) (agreed bool, heads map[string]block.TipSetKey) {
+	td.test.Helper()
+	expHeadKey := td.HeadKey()
+	heads = map[string]block.TipSetKey{td.GetID(): expHeadKey}
+	agreed = true
+	for _, p := range peers {
+		key := p.HeadKey()
+		heads[p.GetID()] = key
+		if !key.Equals(expHeadKey) {
+			agreed = false
+		}
+	}
+	return agreed, heads
+}
+
+// GetChainHead returns the blocks in the head tipset from `td`
+func (td *TestDaemon) GetChainHead() []block.Block {
+	td.test.Helper()
+	head := td.ChainHead()
+	blocks := make([]block.Block, head.Len())
+	for i := range blocks {
+		blocks[i] = *head.At(i)
+	}
+	return blocks
+}
+
+// BlockWithReceipts is the output of the chain block command.
+type BlockWithReceipts struct {
+	Header   block.Block
+	Messages []*types.SignedMessage
+	Receipts []*types.MessageReceipt
+}
+
+// GetBlock returns the block with cid c with its messages and, if the block is
+// on the current chain, their receipts.
+// equivalent to:
+//     `go-filecoin chain block $CID --enc=json`
+func (td *TestDaemon) GetBlock(c cid.Cid) BlockWithReceipts {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "block", c.String(), "--enc=json")
+
+	var blk BlockWithReceipts
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &blk))
+	return blk
+}
+
+// GetChainHeadN returns the blocks of the n most recent tipsets, the head first.
+// equivalent to:
+//     `go-filecoin chain ls --limit $N`
+func (td *TestDaemon) GetChainHeadN(n int) [][]block.Block {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "ls", "--limit", strconv.Itoa(n), "--enc=json")
+	return td.MustUnmarshalChain(out.ReadStdout(), n)
+}
+
+// ComputeBlockCid returns the CID this daemon computes for the JSON encoding
+// of header.
+// equivalent to:
+//     `go-filecoin chain block-cid < $HEADER_JSON`
+func (td *TestDaemon) ComputeBlockCid(header block.Block) cid.Cid {
+	td.test.Helper()
+	headerJSON, err := json.Marshal(&header)
+	require.NoError(td.test, err)
+
+	out := td.RunWithStdin(bytes.NewReader(headerJSON), "chain", "block-cid").AssertSuccess()
+	c, err := cid.Parse(out.ReadStdoutTrimNewlines())
+	require.NoError(td.test, err)
+	return c
+}
+
+// MustUnmarshalChain unmarshals the chain from `input` into a slice of blocks,
+// one slice per tipset. If limit is not zero, it stops after limit tipsets
+// without decoding the rest of the input.
+func (td *TestDaemon) MustUnmarshalChain(input string, limit int) [][]block.Block {
+	var bs [][]block.Block
+
+	dec := json.NewDecoder(strings.NewReader(input))
+	for limit == 0 || len(bs) < limit {
+		var b []block.Block
+		err := dec.Decode(&b)
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			td.test.Fatal(err)
+		}
+		bs = append(bs, b)
+	}
+
+	return bs
+}
+
+// ReceiptRow is a single message receipt as listed by `chain receipts`.
+type ReceiptRow struct {
+	Height     uint64
+	MessageCid cid.Cid
+	ExitCode   uint8
+	GasAttoFIL types.AttoFIL
+	Return     [][]byte
+}
+
+// DealLifetime is the lifetime of a storage deal as reported by
+// `client deal-lifetime`.
+type DealLifetime struct {
+	PieceRef    cid.Cid
+	StartHeight uint64
+	Duration    uint64
+	EndHeight   uint64
+	Remaining   uint64
+	Expired     bool
+	State       storagedeal.State
+}
+
+// DealLifetime returns the lifetime of the deal this daemon proposed with the
+// given id.
+// equivalent to:
+//     `go-filecoin client deal-lifetime $NEGID`
+func (td *TestDaemon) DealLifetime(negid string) DealLifetime {
+	td.test.Helper()
+	out := td.RunSuccess("client", "deal-lifetime", negid, "--enc=json")
+
+	var lifetime DealLifetime
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &lifetime))
+	return lifetime
+}
+
+// RenewDeal renews the deal this daemon proposed with the given id by blocks
+// and returns the id of the renewal deal.
+// equivalent to:
+//     `go-filecoin client renew-deal $NEGID --extend $BLOCKS`
+func (td *TestDaemon) RenewDeal(negid string, blocks uint64) string {
+	td.test.Helper()
+	out := td.RunSuccess("client", "renew-deal", negid,
+		"--extend", strconv.FormatUint(blocks, 10),
+		"--enc=json",
+	)
+
+	var resp storagedeal.Response
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &resp))
+	return resp.ProposalCid.String()
+}
+
+// GasEstimate is the output of the message estimate-gas command.
+type GasEstimate struct {
+	GasLimit types.GasUnits
+	GasPrice types.AttoFIL
+}
+
+// EstimateGas returns the gas limit and price suggested for sending value
+// from from to to, optionally invoking a method given by its ID and the hex
+// of its ABI encoded params.
+// equivalent to:
+//     `go-filecoin message estimate-gas --from $FROM --value $VALUE $TO [$METHOD [$PARAMS]]`
+func (td *TestDaemon) EstimateGas(from, to, value string, methodAndParams ...string) GasEstimate {
+	td.test.Helper()
+
+	args := append([]string{"message", "estimate-gas", "--from", from, "--value", value, to}, methodAndParams...)
+	var estimate GasEstimate
+	RunSuccessJSON(td, &estimate, args...)
+	return estimate
+}
+
+// MarketEscrow is the output of the market escrow command.
+type MarketEscrow struct {
+	Locked    types.AttoFIL
+	Available types.AttoFIL
+}
+
+// MarketEscrow returns the funds addr holds in escrow.
+// equivalent to:
+//     `go-filecoin market escrow $ADDR`
+func (td *TestDaemon) MarketEscrow(addr string) MarketEscrow {
+	td.test.Helper()
+	out := td.RunSuccess("market", "escrow", addr, "--enc=json")
+
+	var escrow MarketEscrow
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &escrow))
+	return escrow
+}
+
+// MarketWithdraw withdraws the funds available in escrow to from and returns
+// the cids of the reclaim messages.
+// equivalent to:
+//     `go-filecoin market withdraw --from $FROM`
+func (td *TestDaemon) MarketWithdraw(from string) []cid.Cid {
+	td.test.Helper()
+	out := td.RunSuccess("market", "withdraw", "--from", from,
+		"--gas-price", "1", "--gas-limit", "300",
+		"--enc=json",
+	)
+
+	var withdrawal struct {
+		Messages []cid.Cid
+	}
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &withdrawal))
+	return withdrawal.Messages
+}
+
+// paymentChannelEol is the block height at which payment channels created by
+// CreatePaymentChannel expire, far beyond the length of a test chain.
+const paymentChannelEol = "100000"
+
+// CreatePaymentChannel creates a payment channel from `from` to `to` holding
+// `amount` and returns its id once the creating message has been mined. Some
+// daemon must be mining.
+// equivalent to:
+//     `go-filecoin paych create --from $FROM $TO $AMOUNT 100000`
+func (td *TestDaemon) CreatePaymentChannel(from, to string, amount *types.AttoFIL) *types.ChannelID {
+	td.test.Helper()
+	out := td.RunSuccess("paych", "create",
+		"--from", from,
+		"--gas-price", "1", "--gas-limit", "300",
+		to, amount.String(), paymentChannelEol,
+	)
+	msgCid, err := cid.Parse(out.ReadStdoutTrimNewlines())
+	require.NoError(td.test, err)
+
+	var id *types.ChannelID
+	td.WaitForMessageResult(msgCid, &id)
+	return id
+}
+
+// OrderBookAsk is an ask listed by the market orderbook command.
+type OrderBookAsk struct {
+	Miner      address.Address
+	ID         uint64
+	Price      types.AttoFIL
+	SectorSize *types.BytesAmount
+	Expiry     *types.BlockHeight
+	Duration   uint64
+}
+
+// OrderBookBid is a bid listed by the market orderbook command.
+type OrderBookBid struct {
+	Client     address.Address
+	Miner      address.Address
+	ProposalID cid.Cid
+	TotalPrice types.AttoFIL
+	Size       *types.BytesAmount
+	Duration   uint64
+	State      storagedeal.State
+}
+
+// OrderBook is the output of the market orderbook command.
+type OrderBook struct {
+	Asks []OrderBookAsk
+	Bids []OrderBookBid
+}
+
+// OrderBook returns the unexpired asks and the open bids of the market.
+// equivalent to:
+//     `go-filecoin market orderbook`
+func (td *TestDaemon) OrderBook() OrderBook {
+	td.test.Helper()
+	out := td.RunSuccess("market", "orderbook", "--enc=json")
+
+	var book OrderBook
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &book))
+	return book
+}
+
+// MinerCandidate is a miner listed by the client find-miners command.
+type MinerCandidate struct {
+	Miner  address.Address
+	Power  *types.BytesAmount
+	Price  types.AttoFIL
+	AskID  uint64
+	Expiry *types.BlockHeight
+}
+
+// FindMiners returns the miners with at least minPower asking at most
+// maxPrice, most powerful first. Either criterion may be nil.
+// equivalent to:
+//     `go-filecoin client find-miners --min-power $POWER --max-price $PRICE`
+func (td *TestDaemon) FindMiners(minPower *types.BytesAmount, maxPrice *types.AttoFIL) []MinerCandidate {
+	td.test.Helper()
+	args := []string{"client", "find-miners", "--enc=json"}
+	if minPower != nil {
+		args = append(args, "--min-power", minPower.String())
+	}
+	if maxPrice != nil {
+		args = append(args, "--max-price", maxPrice.String())
+	}
+	out := td.RunSuccess(args...)
+
+	var candidates []MinerCandidate
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &candidates))
+	return candidates
+}
+
+// WalletBalance returns the balance of addr in the state of the chain head,
+// zero if addr has no actor yet.
+// equivalent to:
+//     `go-filecoin wallet balance $ADDR`
+func (td *TestDaemon) WalletBalance(addr string) *types.AttoFIL {
+	td.test.Helper()
+	out := td.RunSuccess("wallet", "balance", addr, "--enc=json")
+
+	var balance types.AttoFIL
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &balance))
+	return &balance
+}
+
+// TotalBalance returns the sum of the balances of all wallet addresses.
+// equivalent to:
+//     `go-filecoin wallet total-balance`
+func (td *TestDaemon) TotalBalance() *types.AttoFIL {
+	td.test.Helper()
+	out := td.RunSuccess("wallet", "total-balance", "--enc=json")
+
+	var balance types.AttoFIL
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &balance))
+	return &balance
+}
+
+// ExportWalletKey returns the key of the given wallet address, equivalent to:
+//     `go-filecoin wallet export $ADDR`
+func (td *TestDaemon) ExportWalletKey(addr string) *types.KeyInfo {
+	td.test.Helper()
+
+	var result struct{ KeyInfo []*types.KeyInfo }
+	RunSuccessJSON(td, &result, "wallet", "export", addr)
+	require.Len(td.test, result.KeyInfo, 1)
+	return result.KeyInfo[0]
+}
+
+// DeleteWalletAddr removes the key of the given address from the wallet,
+// equivalent to:
+//     `go-filecoin wallet rm $ADDR`
+func (td *TestDaemon) DeleteWalletAddr(addr string) {
+	td.test.Helper()
+	td.RunSuccess("wallet", "rm", addr)
+}
+
+// ValidateKeyFile returns an error naming path if it is not a wallet file
+// that can be imported.
+// equivalent to:
+//     `go-filecoin wallet validate-key $PATH`
+func (td *TestDaemon) ValidateKeyFile(path string) error {
+	td.test.Helper()
+	out := td.Run("wallet", "validate-key", path)
+	status, err := out.Status()
+	require.NoError(td.test, err)
+	if status != 0 {
+		return errors.Errorf("invalid key file %s: %s", path, strings.TrimSpace(out.ReadStderr()))
+	}
+	return nil
+}
+
+// CheckWeightMonotonic returns an error describing the lowest tipset between
+// the given heights inclusive at which chain weight does not increase, or nil
+// if it increases throughout.
+// equivalent to:
+//     `go-filecoin chain check-weight --from-height $FROM --to-height $TO`
+func (td *TestDaemon) CheckWeightMonotonic(fromHeight, toHeight uint64) error {
+	td.test.Helper()
+	out := td.Run("chain", "check-weight",
+		"--from-height", strconv.FormatUint(fromHeight, 10),
+		"--to-height", strconv.FormatUint(toHeight, 10))
+	status, err := out.Status()
+	require.NoError(td.test, err)
+	if status != 0 {
+		return errors.New(strings.TrimSpace(out.ReadStderr()))
+	}
+	return nil
+}
+
+// Receipts returns the receipts of all messages mined in tipsets between the
+// given heights inclusive, from the highest tipset to the lowest.
+// equivalent to:
+//     `go-filecoin chain receipts --from-height $FROM --to-height $TO`
+func (td *TestDaemon) Receipts(fromHeight, toHeight uint64) []ReceiptRow {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "receipts",
+		"--from-height", strconv.FormatUint(fromHeight, 10),
+		"--to-height", strconv.FormatUint(toHeight, 10),
+		"--enc=json",
+	)
+
+	var rows []ReceiptRow
+	decoder := json.NewDecoder(strings.NewReader(out.ReadStdout()))
+	for decoder.More() {
+		var row ReceiptRow
+		require.NoError(td.test, decoder.Decode(&row))
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// ChainFaults returns the equivocations the daemon has detected.
+// equivalent to:
+//     `go-filecoin chain faults`
+func (td *TestDaemon) ChainFaults() []consensus.Equivocation {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "faults", "--enc=json")
+
+	var faults []consensus.Equivocation
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &faults))
+	return faults
+}
+
+// ChainOrphan is a block listed by the chain orphans command.
+type ChainOrphan struct {
+	Cid    cid.Cid
+	Height uint64
+	Miner  address.Address
+}
+
+// ChainOrphans returns the validated blocks that are not ancestors of the
+// daemon's head.
+// equivalent to:
+//     `go-filecoin chain orphans`
+func (td *TestDaemon) ChainOrphans() []ChainOrphan {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "orphans", "--enc=json")
+
+	var orphans []ChainOrphan
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &orphans))
+	return orphans
+}
+
+// BlockReward returns the block reward paid for mining a block at height.
+// equivalent to:
+//     `go-filecoin chain block-reward --at $HEIGHT`
+func (td *TestDaemon) BlockReward(height uint64) *types.AttoFIL {
+	td.test.Helper()
+	out := td.RunSuccess("chain", "block-reward", "--at", strconv.FormatUint(height, 10), "--enc=json")
+
+	var reward types.AttoFIL
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &reward))
+	return &reward
+}
+
+// PeerInfo is a connection listed by the swarm peers command.
+type PeerInfo struct {
+	Addr      string
+	Peer      string
+	Latency   string
+	Direction string
+	Streams   []struct{ Protocol string }
+}
+
+// Peers returns the connections of this daemon with their direction, latency
+// and open streams.
+// equivalent to:
+//     `go-filecoin swarm peers --verbose`
+func (td *TestDaemon) Peers() []PeerInfo {
+	td.test.Helper()
+	out := td.RunSuccess("swarm", "peers", "--verbose", "--enc=json")
+
+	var infos struct{ Peers []PeerInfo }
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &infos))
+	return infos.Peers
+}
+
+// PeerCount returns the number of connections of this daemon.
+func (td *TestDaemon) PeerCount() int {
+	td.test.Helper()
+	return len(td.Peers())
+}
+
+// BandwidthStats is the output of the swarm bandwidth command.
+type BandwidthStats struct {
+	Total      metrics.Stats
+	ByPeer     map[string]metrics.Stats
+	ByProtocol map[string]metrics.Stats
+}
+
+// Bandwidth returns the data this daemon moved over the swarm since its
+// counters were last reset, by peer and by protocol.
+// equivalent to:
+//     `go-filecoin swarm bandwidth --by-peer --by-protocol`
+func (td *TestDaemon) Bandwidth() BandwidthStats {
+	td.test.Helper()
+	out := td.RunSuccess("swarm", "bandwidth", "--by-peer", "--by-protocol", "--enc=json")
+
+	var stats BandwidthStats
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &stats))
+	return stats
+}
+
+// ResetBandwidth returns the data this daemon moved over the swarm since its
+// counters were last reset and resets them.
+// equivalent to:
+//     `go-filecoin swarm bandwidth --reset`
+func (td *TestDaemon) ResetBandwidth() BandwidthStats {
+	td.test.Helper()
+	out := td.RunSuccess("swarm", "bandwidth", "--reset", "--enc=json")
+
+	var stats BandwidthStats
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &stats))
+	return stats
+}
+
+// HelloSide is one side of the output of the swarm hello command.
+type HelloSide struct {
+	Genesis      cid.Cid
+	Head         block.TipSetKey
+	Height       uint64
+	ParentWeight *uint64
+}
+
+// Hello is the output of the swarm hello command.
+type Hello struct {
+	Ours         HelloSide
+	Theirs       HelloSide
+	GenesisMatch bool
+	Replayed     bool
+}
+
+// Hello performs the hello handshake with peer.
+// equivalent to:
+//     `go-filecoin swarm hello $PEER`
+func (td *TestDaemon) Hello(peer string) Hello {
+	td.test.Helper()
+	out := td.RunSuccess("swarm", "hello", peer, "--enc=json")
+
+	var hello Hello
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &hello))
+	return hello
+}
+
+// SwarmProtocol is a protocol listed by the swarm protocols command.
+type SwarmProtocol struct {
+	ID            string
+	Name          string
+	Version       string
+	Enabled       bool
+	PeerSupported bool
+}
+
+// SwarmProtocols lists the protocols the daemon handles.
+// equivalent to:
+//     `go-filecoin swarm protocols`
+func (td *TestDaemon) SwarmProtocols() []SwarmProtocol {
+	td.test.Helper()
+	out := td.RunSuccess("swarm", "protocols", "--enc=json")
+
+	var protocols []SwarmProtocol
+	require.NoError(td.test, json.Unmarshal([]byte(out.ReadStdout()), &protocols))
+	return protocols
+}
+
+// MakeMoney mines a block and ensures that the block has been propagated to all peers.
+func (td *TestDaemon) MakeMoney(rewards int, peers ...*TestDaemon) {
+	for i := 0; i < rewards; i++ {
 		td.MineAndPropagate(time.Second*1, peers...)
 	}
 }
@@ -577,19 +2034,21 @@ func (td *TestDaemon) GetDefaultAddress() string {
 	return addrs.ReadStdout()
 }
 
-func tryAPICheck(td *TestDaemon) error {
-	maddr, err := td.CmdAddr()
-	if err != nil {
-		return err
-	}
+// SetDefaultAddress makes addr the default sender address for this daemon,
+// equivalent to:
+//     `go-filecoin wallet set-default <addr>`
+func (td *TestDaemon) SetDefaultAddress(addr string) {
+	td.test.Helper()
+	td.RunSuccess("wallet", "set-default", addr)
+}
 
-	_, host, err := manet.DialArgs(maddr)
+func tryAPICheck(td *TestDaemon) error {
+	apiURL, err := td.APIURL()
 	if err != nil {
 		return err
 	}
 
-	url := fmt.Sprintf("http://%s/api/id", host)
-	resp, err := http.Get(url)
+	resp, err := http.Get(apiURL + "/id")
 	if err != nil {
 		return err
 	}
@@ -630,6 +2089,14 @@ func CmdTimeout(t time.Duration) func(*TestDaemon) {
 	}
 }
 
+// APITimeout sets how long a starting daemon has to serve its API before
+// Start fails, DefaultAPITimeout by default.
+func APITimeout(t time.Duration) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.apiTimeout = t
+	}
+}
+
 // KeyFile specifies a key file for this daemon to add to their wallet during init
 func KeyFile(kf string) func(*TestDaemon) {
 	return func(td *TestDaemon) {
@@ -658,6 +2125,26 @@ func GenesisFile(a string) func(*TestDaemon) {
 	}
 }
 
+// ImportChain initializes the daemon from a car file written by ChainExport
+// instead of a genesis file, so that the blocks of the chain are in its repo.
+// Init fails unless the chain connects back to a genesis block. On its first
+// start the daemon syncs to the head of the chain, validating its state.
+func ImportChain(path string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.genesisFile = path
+		td.importedChain = path
+	}
+}
+
+// WalletPassphraseFile allows setting the --wallet-passphrase-file flag on
+// init, so that the daemon keeps the keys of its wallet encrypted. The wallet
+// is locked when the daemon starts, so key files cannot be imported.
+func WalletPassphraseFile(path string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.passphraseFile = path
+	}
+}
+
 // WithMiner allows setting the --with-miner flag on init.
 func WithMiner(m string) func(*TestDaemon) {
 	return func(td *TestDaemon) {
@@ -665,6 +2152,42 @@ func WithMiner(m string) func(*TestDaemon) {
 	}
 }
 
+// LogToFile copies the stdout and stderr of the daemon to the file at path as
+// the daemon writes them. A relative path is relative to the repo dir. The
+// path is logged if the test fails, and the daemon's directory is then kept.
+func LogToFile(path string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.logFile = path
+	}
+}
+
+// CmdAPIAddr sets the address the daemon serves its API on, a multiaddr with
+// port 0 by default so that the port is allocated when listening.
+func CmdAPIAddr(addr string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.cmdAPIAddr = addr
+	}
+}
+
+// MockMineInterval starts the daemon with the --mock-mine-interval option, so
+// that each block it mines is stamped d after its parents for every round
+// since them instead of with the current time, but never past it. d is
+// truncated to seconds and must not be shorter than BlockTimeTest.
+func MockMineInterval(d time.Duration) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.mockMineInterval = d
+	}
+}
+
+// MockMineWinner starts the daemon with the --mock-mine-winner option, so that
+// it only mines blocks for the miner at addr, which wins every election. Mining
+// for any other miner fails.
+func MockMineWinner(addr string) func(*TestDaemon) {
+	return func(td *TestDaemon) {
+		td.mockMineWinner = addr
+	}
+}
+
 // IsRelay starts the daemon with the --is-relay option.
 func IsRelay(td *TestDaemon) {
 	td.isRelay = true
@@ -681,6 +2204,7 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 		init:        true, // we want to init unless told otherwise
 		firstRun:    true,
 		cmdTimeout:  DefaultDaemonCmdTimeout,
+		apiTimeout:  DefaultAPITimeout,
 		genesisFile: GenesisFilePath(), // default file includes all test addresses,
 	}
 
@@ -698,6 +2222,10 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 		td.containerDir = newDir
 	}
 
+	if td.logFile != "" && !filepath.IsAbs(td.logFile) {
+		td.logFile = filepath.Join(td.RepoDir(), td.logFile)
+	}
+
 	repoDirFlag := fmt.Sprintf("--repodir=%s", td.RepoDir())
 	sectorDirFlag := fmt.Sprintf("--sectordir=%s", td.SectorDir())
 
@@ -720,6 +2248,10 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 		initopts = append(initopts, fmt.Sprintf("--auto-seal-interval-seconds=%s", td.autoSealInterval))
 	}
 
+	if td.passphraseFile != "" {
+		initopts = append(initopts, fmt.Sprintf("--wallet-passphrase-file=%s", td.passphraseFile))
+	}
+
 	if td.init {
 		t.Logf("run: go-filecoin init %s", initopts)
 		out, err := RunInit(td, initopts...)
@@ -732,6 +2264,9 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 	// Defer allocation of a command API port until listening. The node will write the
 	// listening address to the "api" file in the repo, from where we can read it when issuing commands.
 	cmdAddr := "/ip4/127.0.0.1/tcp/0"
+	if td.cmdAPIAddr != "" {
+		cmdAddr = td.cmdAPIAddr
+	}
 	cmdAPIAddrFlag := fmt.Sprintf("--cmdapiaddr=%s", cmdAddr)
 
 	swarmAddr := "/ip4/127.0.0.1/tcp/0"
@@ -745,6 +2280,14 @@ func NewDaemon(t *testing.T, options ...func(*TestDaemon)) *TestDaemon {
 		td.daemonArgs = append(td.daemonArgs, "--is-relay")
 	}
 
+	if td.mockMineInterval != 0 {
+		td.daemonArgs = append(td.daemonArgs, fmt.Sprintf("--mock-mine-interval=%s", td.mockMineInterval))
+	}
+
+	if td.mockMineWinner != "" {
+		td.daemonArgs = append(td.daemonArgs, fmt.Sprintf("--mock-mine-winner=%s", td.mockMineWinner))
+	}
+
 	return td
 }
 
@@ -783,13 +2326,43 @@ func (td *TestDaemon) createNewProcess() {
 	if err != nil {
 		td.test.Fatal(err)
 	}
+	if td.logFile != "" {
+		td.openLog()
+		td.Stdout = newLogPipe(td.Stdout, td.log)
+		td.Stderr = newLogPipe(td.Stderr, td.log)
+	}
 	td.Stdin, err = td.process.StdinPipe()
 	if err != nil {
 		td.test.Fatal(err)
 	}
 }
 
+// openLog opens the log file on first use, appending the output of restarted
+// daemons to that of earlier runs.
+func (td *TestDaemon) openLog() {
+	if td.log != nil {
+		return
+	}
+	require.NoError(td.test, os.MkdirAll(filepath.Dir(td.logFile), 0755))
+	log, err := os.OpenFile(td.logFile, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	require.NoError(td.test, err)
+	td.log = log
+}
+
 func (td *TestDaemon) cleanupFilesystem() {
+	if td.log != nil {
+		if err := td.log.Close(); err != nil {
+			td.test.Logf("error closing daemon log %s: %s", td.logFile, err)
+		}
+	}
+	if td.logFile != "" && td.test.Failed() {
+		td.test.Logf("daemon log: %s", td.logFile)
+		// Keep the log of failed tests around for inspection.
+		if strings.HasPrefix(td.logFile, td.containerDir+string(filepath.Separator)) {
+			return
+		}
+	}
+
 	if td.containerDir != "" {
 		err := os.RemoveAll(td.containerDir)
 		if err != nil {
@@ -4,9 +4,13 @@ import (
 	"fmt"
 	"net"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
+	ma "github.com/multiformats/go-multiaddr"
+	"github.com/multiformats/go-multiaddr-net"
+
 	"github.com/filecoin-project/go-filecoin/build/project"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 )
@@ -27,6 +31,35 @@ func GetFreePort() (int, error) {
 	return l.Addr().(*net.TCPAddr).Port, nil
 }
 
+// APIURLFromAddr returns the base URL of the HTTP API listening on addr, which
+// is either a multiaddr, e.g. /ip4/127.0.0.1/tcp/3453, or a host:port pair, in
+// which the host may be omitted. Addresses that listen on all interfaces are
+// reached on the loopback interface.
+func APIURLFromAddr(addr string) (string, error) {
+	var hostport string
+	if strings.HasPrefix(addr, "/") {
+		maddr, err := ma.NewMultiaddr(addr)
+		if err != nil {
+			return "", err
+		}
+		_, hostport, err = manet.DialArgs(maddr)
+		if err != nil {
+			return "", err
+		}
+	} else {
+		hostport = addr
+	}
+
+	host, port, err := net.SplitHostPort(hostport)
+	if err != nil {
+		return "", err
+	}
+	if host == "" || net.ParseIP(host).IsUnspecified() {
+		host = "127.0.0.1"
+	}
+	return fmt.Sprintf("http://%s/api", net.JoinHostPort(host, port)), nil
+}
+
 // MustGetFilecoinBinary returns the path where the filecoin binary will be if it has been built and panics otherwise.
 func MustGetFilecoinBinary() string {
 	path, err := GetFilecoinBinary()
@@ -0,0 +1,34 @@
+package testhelpers_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	th "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+)
+
+func TestAPIURLFromAddr(t *testing.T) {
+	tf.UnitTest(t)
+
+	for addr, expected := range map[string]string{
+		"/ip4/127.0.0.1/tcp/3453": "http://127.0.0.1:3453/api",
+		"/ip4/10.1.2.3/tcp/3453":  "http://10.1.2.3:3453/api",
+		"/ip4/0.0.0.0/tcp/3453":   "http://127.0.0.1:3453/api",
+		"/ip6/::1/tcp/3453":       "http://[::1]:3453/api",
+		"10.1.2.3:3453":           "http://10.1.2.3:3453/api",
+		":3453":                   "http://127.0.0.1:3453/api",
+		"[::]:3453":               "http://127.0.0.1:3453/api",
+	} {
+		url, err := th.APIURLFromAddr(addr)
+		require.NoError(t, err, addr)
+		assert.Equal(t, expected, url, addr)
+	}
+
+	for _, addr := range []string{"", "3453", "/ip4/127.0.0.1", "/ip4/nonsense/tcp/3453"} {
+		_, err := th.APIURLFromAddr(addr)
+		assert.Error(t, err, addr)
+	}
+}
@@ -96,6 +96,10 @@ func NewAttoFILFromFILString(s string) (AttoFIL, bool) {
 	if len(decPart) > attoPower || len(splitNumber) > 2 {
 		return ZeroAttoFIL, false
 	}
+	// At least one digit is required, "", "." or "-" are not amounts
+	if strings.TrimLeft(intPart, "+-")+decPart == "" {
+		return ZeroAttoFIL, false
+	}
 	// The decimal is right padded with 0's if it less than 18 digits long
 	for len(decPart) < attoPower {
 		decPart += "0"
@@ -195,12 +199,18 @@ func (z AttoFIL) Bytes() []byte {
 	return leb128.FromBigInt(&z.val)
 }
 
+// String returns z in FIL, as a decimal without trailing zeros.
 func (z AttoFIL) String() string {
+	// Pad the absolute value, a sign would count towards the padded width.
+	sign := ""
+	if z.IsNegative() {
+		sign = "-"
+	}
 	attoPadLength := strconv.Itoa(attoPower + 1)
-	paddedStr := fmt.Sprintf("%0"+attoPadLength+"d", &z.val)
+	paddedStr := fmt.Sprintf("%0"+attoPadLength+"d", new(big.Int).Abs(&z.val))
 	decimaledStr := fmt.Sprintf("%s.%s", paddedStr[:len(paddedStr)-attoPower], paddedStr[len(paddedStr)-attoPower:])
 	noTrailZeroStr := strings.TrimRight(decimaledStr, "0")
-	return strings.TrimRight(noTrailZeroStr, ".")
+	return sign + strings.TrimRight(noTrailZeroStr, ".")
 }
 
 // CalculatePrice treats z as a price in AttoFIL/Byte and applies it to numBytes to calculate a total price.
@@ -2,6 +2,7 @@ package types
 
 import (
 	"encoding/json"
+	"math"
 	"math/big"
 	"math/rand"
 	"testing"
@@ -386,6 +387,37 @@ func TestString(t *testing.T) {
 	// A number of attFIL that is an integer number of FIL
 	attoFIL, _ = new(big.Int).SetString("123000000000000000000", 10)
 	assert.Equal(t, "123", NewAttoFIL(attoFIL).String())
+
+	// Negative numbers of attoFIL, above and below one FIL
+	attoFIL, _ = new(big.Int).SetString("-1500000000000000000", 10)
+	assert.Equal(t, "-1.5", NewAttoFIL(attoFIL).String())
+	attoFIL, _ = new(big.Int).SetString("-5", 10)
+	assert.Equal(t, "-0.000000000000000005", NewAttoFIL(attoFIL).String())
+}
+
+func TestAttoFILOverflow(t *testing.T) {
+	tf.UnitTest(t)
+
+	maxUint64 := NewAttoFIL(new(big.Int).SetUint64(math.MaxUint64))
+
+	t.Run("does not wrap around past the largest uint64", func(t *testing.T) {
+		sum := maxUint64.Add(NewAttoFIL(big.NewInt(1)))
+		assert.Equal(t, BigIntFromString("18446744073709551616"), sum.val)
+		assert.True(t, maxUint64.LessThan(sum))
+	})
+
+	t.Run("goes negative instead of wrapping around below zero", func(t *testing.T) {
+		diff := ZeroAttoFIL.Sub(NewAttoFIL(big.NewInt(1)))
+		assert.True(t, diff.IsNegative())
+		assert.True(t, diff.LessThan(ZeroAttoFIL))
+	})
+
+	t.Run("parses and prints amounts beyond the largest uint64", func(t *testing.T) {
+		attoFIL, ok := NewAttoFILFromFILString("123456789012345678901234567890.123456789012345678")
+		assert.True(t, ok)
+		assert.Equal(t, BigIntFromString("123456789012345678901234567890123456789012345678"), attoFIL.val)
+		assert.Equal(t, "123456789012345678901234567890.123456789012345678", attoFIL.String())
+	})
 }
 
 func TestNewAttoFILFromFILString(t *testing.T) {
@@ -406,6 +438,23 @@ func TestNewAttoFILFromFILString(t *testing.T) {
 
 		attoFIL, _ = NewAttoFILFromFILString("12345")
 		assert.Equal(t, BigIntFromString("12345000000000000000000"), attoFIL.val)
+
+		attoFIL, _ = NewAttoFILFromFILString("0.000000000000000001")
+		assert.Equal(t, BigIntFromString("1"), attoFIL.val)
+
+		attoFIL, _ = NewAttoFILFromFILString("-2.5")
+		assert.Equal(t, BigIntFromString("-2500000000000000000"), attoFIL.val)
+
+		attoFIL, _ = NewAttoFILFromFILString("7.")
+		assert.Equal(t, BigIntFromString("7000000000000000000"), attoFIL.val)
+	})
+
+	t.Run("round trips through String", func(t *testing.T) {
+		for _, s := range []string{"0", "1", "0.5", "-0.5", "1.000000000000000001", "-12.345"} {
+			attoFIL, ok := NewAttoFILFromFILString(s)
+			assert.True(t, ok, s)
+			assert.Equal(t, s, attoFIL.String())
+		}
 	})
 
 	t.Run("rejects nonsense values", func(t *testing.T) {
@@ -423,5 +472,13 @@ func TestNewAttoFILFromFILString(t *testing.T) {
 
 		_, ok = NewAttoFILFromFILString("127.0.0.1")
 		assert.False(t, ok)
+
+		_, ok = NewAttoFILFromFILString("0.0000000000000000001")
+		assert.False(t, ok)
+
+		for _, empty := range []string{"", ".", "-", "-."} {
+			_, ok = NewAttoFILFromFILString(empty)
+			assert.False(t, ok, "%q", empty)
+		}
 	})
 }
@@ -1,6 +1,7 @@
 package types
 
 import (
+	"encoding/json"
 	"fmt"
 
 	"github.com/Workiva/go-datastructures/bitarray"
@@ -90,6 +91,28 @@ func (is IntSet) Values() []uint64 {
 	return is.ba.ToNums()
 }
 
+// MarshalJSON encodes the IntSet as the array of its values.
+func (is IntSet) MarshalJSON() ([]byte, error) {
+	if is.ba == nil {
+		return []byte("[]"), nil
+	}
+	values := is.Values()
+	if values == nil {
+		values = []uint64{}
+	}
+	return json.Marshal(values)
+}
+
+// UnmarshalJSON decodes an IntSet from the array of its values.
+func (is *IntSet) UnmarshalJSON(b []byte) error {
+	var values []uint64
+	if err := json.Unmarshal(b, &values); err != nil {
+		return err
+	}
+	*is = NewIntSet(values...)
+	return nil
+}
+
 // String returns a printable string of the IntSet.
 func (is IntSet) String() string {
 	return fmt.Sprintf("%d", is.Values())
@@ -1,11 +1,13 @@
 package types_test
 
 import (
+	"encoding/json"
 	"math/rand"
 	"sort"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
@@ -108,4 +110,22 @@ func TestIntSet(t *testing.T) {
 
 		assert.Equal(t, 0, types.EmptyIntSet().Size())
 	})
+
+	t.Run("JSON", func(t *testing.T) {
+		out, err := json.Marshal(types.NewIntSet(3, 1, 2))
+		require.NoError(t, err)
+		assert.Equal(t, "[1,2,3]", string(out))
+
+		var is types.IntSet
+		require.NoError(t, json.Unmarshal(out, &is))
+		assert.Equal(t, []uint64{1, 2, 3}, is.Values())
+
+		out, err = json.Marshal(types.EmptyIntSet())
+		require.NoError(t, err)
+		assert.Equal(t, "[]", string(out))
+
+		out, err = json.Marshal(types.IntSet{})
+		require.NoError(t, err)
+		assert.Equal(t, "[]", string(out))
+	})
 }
@@ -54,6 +54,24 @@ func (ki *KeyInfo) Equals(other *KeyInfo) bool {
 	return bytes.Equal(ki.PrivateKey, other.PrivateKey)
 }
 
+// Validate checks that the key's crypto system is known and that its private
+// key has the length that system requires.
+func (ki *KeyInfo) Validate() error {
+	var expected int
+	switch ki.CryptSystem {
+	case SECP256K1:
+		expected = crypto.PrivateKeyBytes
+	case BLS:
+		expected = bls.PrivateKeyBytes
+	default:
+		return errors.Errorf("unknown crypto system: %q", ki.CryptSystem)
+	}
+	if len(ki.PrivateKey) != expected {
+		return errors.Errorf("invalid %s private key length: got %d bytes, expected %d", ki.CryptSystem, len(ki.PrivateKey), expected)
+	}
+	return nil
+}
+
 // Address returns the address for this keyinfo
 func (ki *KeyInfo) Address() (address.Address, error) {
 	if ki.CryptSystem == BLS {
@@ -31,3 +31,25 @@ func TestKeyInfoMarshal(t *testing.T) {
 	assert.Equal(t, ki.Type(), kiBack.Type())
 	assert.True(t, ki.Equals(kiBack))
 }
+
+func TestKeyInfoValidate(t *testing.T) {
+	tf.UnitTest(t)
+
+	testKey, err := crypto.GenerateKey()
+	assert.NoError(t, err)
+
+	t.Run("valid secp256k1 key", func(t *testing.T) {
+		ki := &KeyInfo{PrivateKey: testKey, CryptSystem: SECP256K1}
+		assert.NoError(t, ki.Validate())
+	})
+
+	t.Run("truncated key", func(t *testing.T) {
+		ki := &KeyInfo{PrivateKey: testKey[:20], CryptSystem: SECP256K1}
+		assert.EqualError(t, ki.Validate(), "invalid secp256k1 private key length: got 20 bytes, expected 32")
+	})
+
+	t.Run("unknown crypto system", func(t *testing.T) {
+		ki := &KeyInfo{PrivateKey: testKey, CryptSystem: "test_key_type"}
+		assert.EqualError(t, ki.Validate(), `unknown crypto system: "test_key_type"`)
+	})
+}
@@ -39,6 +39,10 @@ type GasUnits = Uint64
 // BlockGasLimit is the maximum amount of gas that can be used to execute messages in a single block
 var BlockGasLimit = NewGasUnits(10000000)
 
+// BlockMessageLimit is the maximum number of messages, BLS and secp256k1
+// combined, that a single block may carry.
+const BlockMessageLimit = 1000
+
 // EmptyMessagesCID is the cid of an empty collection of messages.
 var EmptyMessagesCID cid.Cid
 
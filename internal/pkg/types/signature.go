@@ -1,6 +1,9 @@
 package types
 
 import (
+	"fmt"
+
+	"github.com/filecoin-project/go-bls-sigs"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
 	logging "github.com/ipfs/go-log"
 	"github.com/minio/blake2b-simd"
@@ -13,6 +16,43 @@ var log = logging.Logger("types")
 // Signature is the result of a cryptographic sign operation.
 type Signature []byte
 
+// SecpSignatureBytes is the length of a secp256k1 signature: 32 bytes of r,
+// 32 bytes of s and the recovery id.
+const SecpSignatureBytes = 65
+
+// SignatureComponents is the breakdown of a signature into its parts.
+type SignatureComponents struct {
+	// Scheme is SECP256K1 or BLS, inferred from the length of the signature.
+	Scheme string
+	Length int
+	// R, S and RecoveryID are only set for secp256k1 signatures.
+	R          []byte
+	S          []byte
+	RecoveryID byte
+}
+
+// DecodeSignature splits sig into its components. Signatures do not carry
+// their scheme, so it is inferred from their length.
+func DecodeSignature(sig Signature) (*SignatureComponents, error) {
+	switch len(sig) {
+	case SecpSignatureBytes:
+		return &SignatureComponents{
+			Scheme:     SECP256K1,
+			Length:     len(sig),
+			R:          sig[:32],
+			S:          sig[32:64],
+			RecoveryID: sig[64],
+		}, nil
+	case bls.SignatureBytes:
+		return &SignatureComponents{
+			Scheme: BLS,
+			Length: len(sig),
+		}, nil
+	default:
+		return nil, fmt.Errorf("signature of %d bytes is neither secp256k1 (%d bytes) nor bls (%d bytes)", len(sig), SecpSignatureBytes, bls.SignatureBytes)
+	}
+}
+
 // IsValidSignature cryptographically verifies that 'sig' is the signed hash of 'data' with
 // the public key belonging to `addr`.
 func IsValidSignature(data []byte, addr address.Address, sig Signature) bool {
@@ -0,0 +1,46 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/filecoin-project/go-bls-sigs"
+	"github.com/minio/blake2b-simd"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+)
+
+func TestDecodeSignature(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("secp256k1", func(t *testing.T) {
+		sk, err := crypto.GenerateKey()
+		require.NoError(t, err)
+		hash := blake2b.Sum256([]byte("data"))
+		sig, err := crypto.SignSecp(sk, hash[:])
+		require.NoError(t, err)
+
+		components, err := DecodeSignature(sig)
+		require.NoError(t, err)
+		assert.Equal(t, SECP256K1, components.Scheme)
+		assert.Equal(t, SecpSignatureBytes, components.Length)
+		assert.Equal(t, sig[:32], components.R)
+		assert.Equal(t, sig[32:64], components.S)
+		assert.True(t, components.RecoveryID <= 3)
+	})
+
+	t.Run("bls", func(t *testing.T) {
+		components, err := DecodeSignature(make(Signature, bls.SignatureBytes))
+		require.NoError(t, err)
+		assert.Equal(t, BLS, components.Scheme)
+		assert.Equal(t, bls.SignatureBytes, components.Length)
+		assert.Nil(t, components.R)
+	})
+
+	t.Run("unknown length", func(t *testing.T) {
+		_, err := DecodeSignature(make(Signature, 10))
+		assert.Error(t, err)
+	})
+}
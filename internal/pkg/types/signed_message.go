@@ -2,10 +2,12 @@ package types
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 
 	"github.com/filecoin-project/go-filecoin/internal/pkg/encoding"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 	"github.com/ipfs/go-cid"
 	cbor "github.com/ipfs/go-ipld-cbor"
 	ipld "github.com/ipfs/go-ipld-format"
@@ -17,6 +19,8 @@ var (
 	ErrMessageSigned = errors.New("message already contains a signature")
 	// ErrMessageUnsigned is returned when `RecoverAddress` is called on a signedmessage that does not contain a signature
 	ErrMessageUnsigned = errors.New("message does not contain a signature")
+	// ErrInvalidSignature is returned by `CheckSignature` when the signature of a signedmessage is not by its sender
+	ErrInvalidSignature = errors.New("signature does not match the message sender")
 )
 
 // SignedMessage contains a message and its signature
@@ -31,12 +35,17 @@ type SignedMessage struct {
 // NewSignedMessage accepts a message `msg` and a signer `s`. NewSignedMessage returns a `SignedMessage` containing
 // a signature derived from the serialized `msg` and `msg.From`
 func NewSignedMessage(msg UnsignedMessage, s Signer) (*SignedMessage, error) {
+	return NewSignedMessageContext(context.Background(), msg, s)
+}
+
+// NewSignedMessageContext is NewSignedMessage, but gives up once ctx is done.
+func NewSignedMessageContext(ctx context.Context, msg UnsignedMessage, s Signer) (*SignedMessage, error) {
 	msgData, err := msg.Marshal()
 	if err != nil {
 		return nil, err
 	}
 
-	sig, err := s.SignBytes(msgData, msg.From)
+	sig, err := SignBytesContext(ctx, s, msgData, msg.From)
 	if err != nil {
 		return nil, err
 	}
@@ -90,12 +99,34 @@ func (smsg *SignedMessage) ToNode() (ipld.Node, error) {
 
 // VerifySignature returns true iff the signature is valid for the message content and from address.
 func (smsg *SignedMessage) VerifySignature() bool {
-	bmsg, err := smsg.Message.Marshal()
-	if err != nil {
+	if err := smsg.CheckSignature(); err != nil {
 		log.Infof("invalid signature: %s", err)
 		return false
 	}
-	return IsValidSignature(bmsg, smsg.Message.From, smsg.Signature)
+	return true
+}
+
+// CheckSignature checks that the message is signed by its sender, using only
+// the signature and the from address. It returns ErrInvalidSignature if the
+// signature does not match, and a different error if the signature cannot be
+// checked at all.
+func (smsg *SignedMessage) CheckSignature() error {
+	from := smsg.Message.From
+	if from.Protocol() != address.SECP256K1 && from.Protocol() != address.BLS {
+		return errors.Errorf("cannot check signature of non-key sender %s", from)
+	}
+	if len(smsg.Signature) == 0 {
+		return ErrMessageUnsigned
+	}
+
+	bmsg, err := smsg.Message.Marshal()
+	if err != nil {
+		return errors.Wrap(err, "failed to encode message")
+	}
+	if !IsValidSignature(bmsg, from, smsg.Signature) {
+		return errors.Wrapf(ErrInvalidSignature, "message from %s", from)
+	}
+	return nil
 }
 
 func (smsg *SignedMessage) String() string {
@@ -1,9 +1,11 @@
 package types
 
 import (
+	"context"
 	"reflect"
 	"testing"
 
+	"github.com/pkg/errors"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 
@@ -73,6 +75,92 @@ func TestSignedMessageCidToNode(t *testing.T) {
 
 }
 
+func TestSignedMessageCheckSignature(t *testing.T) {
+	tf.UnitTest(t)
+
+	signer := NewMockSigner(MustGenerateKeyInfo(2, 42))
+
+	t.Run("valid message", func(t *testing.T) {
+		smsg := makeMessage(t, signer, 42)
+		assert.NoError(t, smsg.CheckSignature())
+		assert.True(t, smsg.VerifySignature())
+	})
+
+	t.Run("tampered body", func(t *testing.T) {
+		smsg := makeMessage(t, signer, 42)
+		smsg.Message.Params = []byte("other params")
+		err := smsg.CheckSignature()
+		assert.Equal(t, ErrInvalidSignature, errors.Cause(err))
+		assert.False(t, smsg.VerifySignature())
+	})
+
+	t.Run("wrong signer", func(t *testing.T) {
+		smsg := makeMessage(t, signer, 42)
+		bmsg, err := smsg.Message.Marshal()
+		require.NoError(t, err)
+		smsg.Signature, err = signer.SignBytes(bmsg, signer.Addresses[1])
+		require.NoError(t, err)
+
+		err = smsg.CheckSignature()
+		assert.Equal(t, ErrInvalidSignature, errors.Cause(err))
+		assert.False(t, smsg.VerifySignature())
+	})
+
+	t.Run("unsigned message", func(t *testing.T) {
+		smsg := makeMessage(t, signer, 42)
+		smsg.Signature = nil
+		assert.Equal(t, ErrMessageUnsigned, smsg.CheckSignature())
+	})
+
+	t.Run("sender without a key", func(t *testing.T) {
+		smsg := makeMessage(t, signer, 42)
+		var err error
+		smsg.Message.From, err = address.NewIDAddress(100)
+		require.NoError(t, err)
+		assert.Contains(t, smsg.CheckSignature().Error(), "non-key sender")
+	})
+}
+
+func TestNewSignedMessageContext(t *testing.T) {
+	tf.UnitTest(t)
+
+	msg := makeMessage(t, mockSigner, 42).Message
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	t.Run("checks the context before signing", func(t *testing.T) {
+		_, err := NewSignedMessageContext(ctx, msg, mockSigner)
+		assert.Equal(t, context.Canceled, err)
+	})
+
+	t.Run("passes the context to context signers", func(t *testing.T) {
+		signer := &recordingContextSigner{Signer: mockSigner}
+		smsg, err := NewSignedMessageContext(context.Background(), msg, signer)
+		require.NoError(t, err)
+		assert.NoError(t, smsg.CheckSignature())
+		assert.Equal(t, context.Background(), signer.ctx)
+
+		_, err = NewSignedMessageContext(ctx, msg, signer)
+		assert.Equal(t, context.Canceled, err)
+		assert.Equal(t, ctx, signer.ctx)
+	})
+}
+
+// recordingContextSigner signs with Signer, remembering the last context it
+// was given.
+type recordingContextSigner struct {
+	Signer
+	ctx context.Context
+}
+
+func (s *recordingContextSigner) SignBytesContext(ctx context.Context, data []byte, addr address.Address) (Signature, error) {
+	s.ctx = ctx
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.SignBytes(data, addr)
+}
+
 func makeMessage(t *testing.T, signer MockSigner, nonce uint64) *SignedMessage {
 	newAddr, err := address.NewSecp256k1Address([]byte("receiver"))
 	require.NoError(t, err)
@@ -1,8 +1,31 @@
 package types
 
-import "github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+import (
+	"context"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
 
 // Signer is an interface for SignBytes
 type Signer interface {
 	SignBytes(data []byte, addr address.Address) (Signature, error)
 }
+
+// ContextSigner is a Signer whose signing can be cancelled, e.g. a wallet
+// holding keys on a remote signer.
+type ContextSigner interface {
+	SignBytesContext(ctx context.Context, data []byte, addr address.Address) (Signature, error)
+}
+
+// SignBytesContext signs `data` with the key of `addr`. Signers that
+// implement ContextSigner give up once ctx is done, others are only checked
+// before they sign.
+func SignBytesContext(ctx context.Context, s Signer, data []byte, addr address.Address) (Signature, error) {
+	if cs, ok := s.(ContextSigner); ok {
+		return cs.SignBytesContext(ctx, data, addr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return s.SignBytes(data, addr)
+}
@@ -91,6 +91,12 @@ type State struct {
 	// currently required to prove.
 	ProvingSet types.IntSet
 
+	// ProvenSet is the set of sector ids of committed sectors covered by at
+	// least one accepted PoSt. Committed sectors missing from this set are
+	// still pending their first proof. It is nil in state written before the
+	// field existed, see provenSet.
+	ProvenSet *types.IntSet
+
 	LastUsedSectorID uint64
 
 	// ProvingPeriodEnd is the block height at the end of the current proving period.
@@ -114,6 +120,21 @@ type State struct {
 	// OwedStorageCollateral is the collateral for sectors that have been slashed.
 	// This collateral can be collected from arbitrated deals, but not de-pledged.
 	OwedStorageCollateral types.AttoFIL
+
+	// SlashedCollateral is the collateral burnt when this miner was slashed.
+	SlashedCollateral types.AttoFIL
+}
+
+// provenSet returns the sectors covered by an accepted PoSt. State written
+// before ProvenSet existed has no record of them, so all its committed sectors
+// read as pending. The next accepted PoSt proves the proving set, which holds
+// every sector committed before the previous PoSt, so the record is exact from
+// then on.
+func (state *State) provenSet() types.IntSet {
+	if state.ProvenSet == nil {
+		return types.EmptyIntSet()
+	}
+	return *state.ProvenSet
 }
 
 // Ask is a price advertisement by the miner
@@ -148,6 +169,9 @@ const (
 	GetProvingWindow
 	CalculateLateFee
 	GetActiveCollateral
+	GetPendingSectors
+	GetFaults
+	GetSlashedCollateral
 )
 
 // NewActor returns a new miner actor with the provided balance.
@@ -157,6 +181,7 @@ func NewActor() *actor.Actor {
 
 // NewState creates a miner state struct
 func NewState(owner, worker address.Address, pid peer.ID, sectorSize *types.BytesAmount) *State {
+	proven := types.EmptyIntSet()
 	return &State{
 		Owner:                 owner,
 		Worker:                worker,
@@ -169,6 +194,7 @@ func NewState(owner, worker address.Address, pid peer.ID, sectorSize *types.Byte
 		NextFaultSet:          types.EmptyIntSet(),
 		NextDoneSet:           types.EmptyIntSet(),
 		ProvingSet:            types.EmptyIntSet(),
+		ProvenSet:             &proven,
 		LastUsedSectorID:      0,
 		ProvingPeriodEnd:      types.NewBlockHeight(0),
 		Power:                 types.NewBytesAmount(0),
@@ -176,6 +202,7 @@ func NewState(owner, worker address.Address, pid peer.ID, sectorSize *types.Byte
 		SlashedSet:            types.EmptyIntSet(),
 		SlashedAt:             types.NewBlockHeight(0),
 		OwedStorageCollateral: types.ZeroAttoFIL,
+		SlashedCollateral:     types.ZeroAttoFIL,
 	}
 }
 
@@ -286,6 +313,18 @@ var signatures = dispatch.Exports{
 		Params: []abi.Type{},
 		Return: []abi.Type{abi.AttoFIL},
 	},
+	GetPendingSectors: &dispatch.FunctionSignature{
+		Params: []abi.Type{},
+		Return: []abi.Type{abi.UintArray},
+	},
+	GetFaults: &dispatch.FunctionSignature{
+		Params: []abi.Type{},
+		Return: []abi.Type{abi.UintArray},
+	},
+	GetSlashedCollateral: &dispatch.FunctionSignature{
+		Params: []abi.Type{},
+		Return: []abi.Type{abi.AttoFIL},
+	},
 }
 
 // Method returns method definition for a given method id.
@@ -337,6 +376,12 @@ func (a *Actor) Method(id types.MethodID) (dispatch.Method, *dispatch.FunctionSi
 		return reflect.ValueOf((*Impl)(a).CalculateLateFee), signatures[CalculateLateFee], true
 	case GetActiveCollateral:
 		return reflect.ValueOf((*Impl)(a).GetActiveCollateral), signatures[GetActiveCollateral], true
+	case GetPendingSectors:
+		return reflect.ValueOf((*Impl)(a).GetPendingSectors), signatures[GetPendingSectors], true
+	case GetFaults:
+		return reflect.ValueOf((*Impl)(a).GetFaults), signatures[GetFaults], true
+	case GetSlashedCollateral:
+		return reflect.ValueOf((*Impl)(a).GetSlashedCollateral), signatures[GetSlashedCollateral], true
 	default:
 		return nil, nil, false
 	}
@@ -632,11 +677,12 @@ func (a *Impl) IsBootstrapMiner(ctx invocationContext) (bool, uint8, error) {
 
 // GetPoStState returns whether the miner's last submitPoSt is within the proving period,
 // late or after the generation attack threshold.
-func (*Impl) GetPoStState(ctx invocationContext) (*big.Int, uint8, error) {
+func (a *Impl) GetPoStState(ctx invocationContext) (*big.Int, uint8, error) {
 	var state State
 	out, err := actor.WithState(ctx, &state, func() (interface{}, error) {
-		// Don't check lateness unless there is storage to prove
-		if state.ProvingSet.Size() == 0 {
+		// Don't check lateness unless there is storage to prove. Bootstrap
+		// miners never submit PoSts, so they are never late.
+		if state.ProvingSet.Size() == 0 || a.Bootstrap {
 			return int64(PoStStateNoStorage), nil
 		}
 		epoch := ctx.Runtime().CurrentEpoch()
@@ -1144,7 +1190,11 @@ func (a *Impl) SubmitPoSt(ctx invocationContext, poStProof types.PoStProof, faul
 			}
 		}
 
-		// Update SectorSet, DoneSet and ProvingSet
+		// Every sector in the proving set that was not reported faulty has
+		// now been proven at least once.
+		proven := state.provenSet().Union(state.ProvingSet.Difference(faults.SectorIds))
+
+		// Update SectorSet, DoneSet, ProvingSet and ProvenSet
 		if err = state.SectorCommitments.Drop(done.Values()); err != nil {
 			return nil, err
 		}
@@ -1158,6 +1208,8 @@ func (a *Impl) SubmitPoSt(ctx invocationContext, poStProof types.PoStProof, faul
 			return nil, err
 		}
 		state.ProvingSet = types.NewIntSet(sectorIDsToProve...)
+		proven = proven.Difference(done).Difference(faults.SectorIds)
+		state.ProvenSet = &proven
 		state.NextDoneSet = done
 
 		return nil, nil
@@ -1171,8 +1223,8 @@ func (a *Impl) SubmitPoSt(ctx invocationContext, poStProof types.PoStProof, faul
 
 // SlashStorageFault is called by an independent actor to remove power and
 // take collateral from this miner when the miner has failed to submit a
-// PoSt on time.
-func (*Impl) SlashStorageFault(ctx invocationContext) (uint8, error) {
+// PoSt on time. The collateral for every sector in the proving set is burnt.
+func (a *Impl) SlashStorageFault(ctx invocationContext) (uint8, error) {
 	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
 		return internal.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
 	}
@@ -1185,6 +1237,11 @@ func (*Impl) SlashStorageFault(ctx invocationContext) (uint8, error) {
 			return nil, errors.NewCodedRevertError(ErrMinerAlreadySlashed, "miner already slashed")
 		}
 
+		// Bootstrap miners are not expected to prove.
+		if a.Bootstrap {
+			return nil, errors.NewCodedRevertError(ErrMinerNotSlashable, "bootstrap miners are not slashable")
+		}
+
 		// Only a miner who is expected to prove, can be slashed.
 		if state.ProvingSet.Size() == 0 {
 			return nil, errors.NewCodedRevertError(ErrMinerNotSlashable, "miner is inactive")
@@ -1210,12 +1267,28 @@ func (*Impl) SlashStorageFault(ctx invocationContext) (uint8, error) {
 		// record what has been slashed
 		state.SlashedSet = state.ProvingSet
 
+		// burn the collateral of the slashed sectors
+		slashed := CollateralForSector(state.SectorSize).MulBigInt(big.NewInt(int64(state.SlashedSet.Size())))
+		if slashed.GreaterThan(state.ActiveCollateral) {
+			slashed = state.ActiveCollateral
+		}
+		if slashed.GreaterThan(ctx.Balance()) {
+			slashed = ctx.Balance()
+		}
+		if err := a.burnFunds(ctx, slashed); err != nil {
+			return nil, errors.RevertErrorWrapf(err, "Failed to burn slashed collateral %s", slashed)
+		}
+		state.ActiveCollateral = state.ActiveCollateral.Sub(slashed)
+		state.SlashedCollateral = slashed
+
 		// reserve collateral for arbitration
 		// TODO: We currently do not know the correct amount of collateral to reserve here: https://github.com/filecoin-project/go-filecoin/issues/3050
 		state.OwedStorageCollateral = types.ZeroAttoFIL
 
 		// remove proving set from our sectors
 		state.SectorCommitments.Drop(state.SlashedSet.Values())
+		proven := state.provenSet().Difference(state.SlashedSet)
+		state.ProvenSet = &proven
 
 		// clear proving set
 		state.ProvingSet = types.NewIntSet()
@@ -1247,6 +1320,75 @@ func (*Impl) GetProvingWindow(ctx invocationContext) ([]types.Uint64, uint8, err
 	}, 0, nil
 }
 
+// GetPendingSectors returns the committed sectors no PoSt has been accepted for
+// yet, as a flat list of (sector id, deadline) pairs. The deadline is the last
+// height at which a PoSt covering the sector is on time: the end of the current
+// proving period for sectors in the proving set, and the end of the following
+// one for sectors that join the proving set at the next PoSt.
+func (*Impl) GetPendingSectors(ctx invocationContext) ([]types.Uint64, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, internal.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	committed, err := state.SectorCommitments.IDs()
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	nextProvingPeriodEnd := state.ProvingPeriodEnd.Add(types.NewBlockHeight(ProvingPeriodDuration(state.SectorSize)))
+	pending := []types.Uint64{}
+	for _, id := range types.NewIntSet(committed...).Difference(state.provenSet()).Values() {
+		deadline := nextProvingPeriodEnd
+		if state.ProvingSet.Has(id) {
+			deadline = state.ProvingPeriodEnd
+		}
+		pending = append(pending, types.Uint64(id), types.Uint64(deadline.AsBigInt().Uint64()))
+	}
+
+	return pending, 0, nil
+}
+
+// GetFaults returns the number of sectors this miner was slashed for missing
+// its PoSt and the height at which it was slashed, zero if it never was.
+func (*Impl) GetFaults(ctx invocationContext) ([]types.Uint64, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return nil, internal.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return nil, errors.CodeError(err), err
+	}
+
+	return []types.Uint64{
+		types.Uint64(state.SlashedSet.Size()),
+		types.Uint64(state.SlashedAt.AsBigInt().Uint64()),
+	}, 0, nil
+}
+
+// GetSlashedCollateral returns the collateral burnt when this miner was
+// slashed.
+func (*Impl) GetSlashedCollateral(ctx invocationContext) (types.AttoFIL, uint8, error) {
+	if err := ctx.Charge(actor.DefaultGasCost); err != nil {
+		return types.ZeroAttoFIL, internal.ErrInsufficientGas, errors.RevertErrorWrap(err, "Insufficient gas")
+	}
+
+	var state State
+	err := actor.ReadState(ctx, &state)
+	if err != nil {
+		return types.ZeroAttoFIL, errors.CodeError(err), err
+	}
+
+	return state.SlashedCollateral, 0, nil
+}
+
 // CalculateLateFee calculates the late fee due for a PoSt arriving at `height` for the actor's current
 // power and proving period.
 func (a *Impl) CalculateLateFee(ctx invocationContext, height *types.BlockHeight) (types.AttoFIL, uint8, error) {
@@ -1315,6 +1457,16 @@ func CollateralForSector(sectorSize *types.BytesAmount) types.AttoFIL {
 	return MinimumCollateralPerSector
 }
 
+// CollateralForPower returns the collateral required to commit enough sectors
+// of the given size to reach the given amount of power. Power that does not
+// fill a whole sector still requires the collateral for that sector.
+func CollateralForPower(sectorSize, power *types.BytesAmount) types.AttoFIL {
+	numSectors := new(big.Int).Add(power.BigInt(), sectorSize.BigInt())
+	numSectors.Sub(numSectors, big.NewInt(1))
+	numSectors.Div(numSectors, sectorSize.BigInt())
+	return CollateralForSector(sectorSize).MulBigInt(numSectors)
+}
+
 // LatePoStGracePeriod is the number of blocks after a proving period ends
 // after which a storage miner will be subject to storage fault slashing.
 func LatePoStGracePeriod(sectorSize *types.BytesAmount) *types.BlockHeight {
@@ -2,6 +2,7 @@ package miner_test
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"math/big"
@@ -313,6 +314,24 @@ func TestCBOREncodeState(t *testing.T) {
 
 }
 
+func TestJSONEncodeState(t *testing.T) {
+	tf.UnitTest(t)
+
+	state := NewState(address.TestAddress, address.TestAddress, th.RequireRandomPeerID(t), types.OneKiBSectorSize)
+	state.ProvingSet = types.NewIntSet(1, 4)
+
+	out, err := json.Marshal(state)
+	require.NoError(t, err)
+
+	var decoded struct {
+		ProvingSet []uint64
+		ProvenSet  []uint64
+	}
+	require.NoError(t, json.Unmarshal(out, &decoded))
+	assert.Equal(t, []uint64{1, 4}, decoded.ProvingSet)
+	assert.Equal(t, []uint64{}, decoded.ProvenSet)
+}
+
 func TestPeerIdGetterAndSetter(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -508,6 +527,40 @@ func TestMinerCommitSector(t *testing.T) {
 		require.NotEqual(t, uint8(0), res.Receipt.ExitCode)
 	})
 
+	t.Run("collateral for power is exactly enough to commit that power", func(t *testing.T) {
+		ctx := context.Background()
+
+		power := types.NewBytesAmount(3 * types.OneKiBSectorSize.Uint64())
+		pledgeCost := CollateralForPower(types.OneKiBSectorSize, power)
+		assert.Equal(t, MinimumCollateralPerSector.MulBigInt(big.NewInt(3)), pledgeCost)
+
+		// a fraction of a sector costs the collateral for the whole sector
+		assert.Equal(t, pledgeCost, CollateralForPower(types.OneKiBSectorSize, power.Sub(types.NewBytesAmount(1))))
+
+		commitSectors := func(collateral types.AttoFIL) []*consensus.ApplicationResult {
+			st, vms := th.RequireCreateStorages(ctx, t)
+			minerAddr := th.CreateTestMinerWith(collateral, t, st, vms, address.TestAddress, th.RequireRandomPeerID(t), 0)
+
+			var results []*consensus.ApplicationResult
+			for i := uint64(0); i < 3; i++ {
+				res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, 3, CommitSector, nil, i, th.MakeCommitment(), th.MakeCommitment(), th.MakeCommitment(), th.MakeRandomBytes(types.TwoPoRepProofPartitions.ProofLen()))
+				require.NoError(t, err)
+				results = append(results, res)
+			}
+			return results
+		}
+
+		for _, res := range commitSectors(pledgeCost) {
+			require.NoError(t, res.ExecutionError)
+			require.Equal(t, uint8(0), res.Receipt.ExitCode)
+		}
+
+		results := commitSectors(pledgeCost.Sub(types.NewAttoFIL(big.NewInt(1))))
+		require.Equal(t, uint8(0), results[1].Receipt.ExitCode)
+		require.Error(t, results[2].ExecutionError)
+		assert.Equal(t, uint8(ErrInsufficientCollateral), results[2].Receipt.ExitCode)
+	})
+
 	t.Run("a miner successfully commits a sector", func(t *testing.T) {
 		ctx := context.Background()
 		st, vms := th.RequireCreateStorages(ctx, t)
@@ -589,6 +642,20 @@ func (mal *minerActorLiason) requireReadState() State {
 	return minerState
 }
 
+// requireDropProvenSet rewrites the miner state without its ProvenSet, the way
+// state written before the field existed reads.
+func (mal *minerActorLiason) requireDropProvenSet() {
+	minerState := mal.requireReadState()
+	minerState.ProvenSet = nil
+
+	miner := state.MustGetActor(mal.st, mal.minerAddr)
+	storage := mal.vms.NewStorage(mal.minerAddr, miner)
+	head, err := storage.Put(&minerState)
+	require.NoError(mal.t, err)
+	require.NoError(mal.t, storage.Commit(head, storage.Head()))
+	require.NoError(mal.t, mal.st.SetActor(context.Background(), mal.minerAddr, miner))
+}
+
 func (mal *minerActorLiason) requirePower(queryHeight uint64) *types.BytesAmount {
 	mal.requireHeightNotPast(queryHeight)
 	res, err := th.CreateAndApplyTestMessage(mal.t, mal.st, mal.vms, mal.minerAddr, 0, queryHeight, GetPower, mal.ancestors)
@@ -609,6 +676,18 @@ func (mal *minerActorLiason) requireTotalStorage(queryHeight uint64) *types.Byte
 	return types.NewBytesAmountFromBytes(res.Receipt.Return[0])
 }
 
+func (mal *minerActorLiason) requirePendingSectors(queryHeight uint64) []types.Uint64 {
+	mal.requireHeightNotPast(queryHeight)
+	res, err := th.CreateAndApplyTestMessage(mal.t, mal.st, mal.vms, mal.minerAddr, 0, queryHeight, GetPendingSectors, mal.ancestors)
+	require.NoError(mal.t, err)
+	require.NoError(mal.t, res.ExecutionError)
+	require.Equal(mal.t, uint8(0), res.Receipt.ExitCode)
+	require.Equal(mal.t, 1, len(res.Receipt.Return))
+	ret, err := abi.Deserialize(res.Receipt.Return[0], abi.UintArray)
+	require.NoError(mal.t, err)
+	return ret.Val.([]types.Uint64)
+}
+
 func (mal *minerActorLiason) assertPoStFail(blockHeight uint64, done types.IntSet, exitCode uint8) {
 	mal.requireHeightNotPast(blockHeight)
 	res, err := th.CreateAndApplyTestMessage(mal.t, mal.st, mal.vms, mal.minerAddr, 0, blockHeight, SubmitPoSt, mal.ancestors, th.MakeRandomPoStProofForTest(), types.EmptyFaultSet(), done)
@@ -1050,6 +1129,68 @@ func TestMinerSubmitPoStNextDoneSet(t *testing.T) {
 	})
 }
 
+func TestMinerGetPendingSectors(t *testing.T) {
+	tf.UnitTest(t)
+
+	firstCommitBlockHeight := uint64(3)
+	secondProvingPeriodStart := LargestSectorSizeProvingPeriodBlocks + firstCommitBlockHeight
+
+	faults := types.EmptyFaultSet()
+	done := types.EmptyIntSet()
+
+	t.Run("no pending sectors before first commit", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		assert.Empty(t, mal.requirePendingSectors(firstCommitBlockHeight))
+	})
+
+	t.Run("committed sectors are pending until proven", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+		mal.requireCommit(firstCommitBlockHeight+1, uint64(2))
+
+		// Sector 1 is in the proving set and is due at the end of this period,
+		// sector 2 joins at the next PoSt and is due a period later.
+		periodEnd := mal.requireReadState().ProvingPeriodEnd.AsBigInt().Uint64()
+		expected := []types.Uint64{
+			1, types.Uint64(periodEnd),
+			2, types.Uint64(periodEnd + LargestSectorSizeProvingPeriodBlocks),
+		}
+		assert.Equal(t, expected, mal.requirePendingSectors(firstCommitBlockHeight+2))
+
+		mal.requirePoSt(firstCommitBlockHeight+5, done, faults)
+		expected = []types.Uint64{2, types.Uint64(periodEnd + LargestSectorSizeProvingPeriodBlocks)}
+		assert.Equal(t, expected, mal.requirePendingSectors(firstCommitBlockHeight+5))
+
+		mal.requirePoSt(secondProvingPeriodStart+5, done, faults)
+		assert.Empty(t, mal.requirePendingSectors(secondProvingPeriodStart+5))
+	})
+
+	t.Run("faulted sectors are not proven", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+
+		mal.requirePoSt(firstCommitBlockHeight+5, done, types.NewFaultSet([]uint64{1}))
+		assert.Empty(t, mal.requirePendingSectors(firstCommitBlockHeight+5))
+		assert.False(t, mal.requireReadState().ProvenSet.Has(1))
+	})
+
+	t.Run("state without a proven set is pending until the next PoSt", func(t *testing.T) {
+		mal := setupMinerActorLiason(t)
+		mal.requireCommit(firstCommitBlockHeight, uint64(1))
+		mal.requirePoSt(firstCommitBlockHeight+5, done, faults)
+		require.Empty(t, mal.requirePendingSectors(firstCommitBlockHeight+5))
+
+		mal.requireDropProvenSet()
+		pending := mal.requirePendingSectors(firstCommitBlockHeight + 6)
+		require.Len(t, pending, 2)
+		assert.Equal(t, types.Uint64(1), pending[0])
+
+		mal.requirePoSt(secondProvingPeriodStart+5, done, faults)
+		assert.Empty(t, mal.requirePendingSectors(secondProvingPeriodStart+5))
+		assert.True(t, mal.requireReadState().ProvenSet.Has(1))
+	})
+}
+
 func TestMinerSubmitPoSt(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -1392,6 +1533,45 @@ func TestActorSlashStorageFault(t *testing.T) {
 		assert.Equal(t, types.ZeroAttoFIL, minerState.OwedStorageCollateral)
 	})
 
+	t.Run("slashing burns collateral and is reported as faults", func(t *testing.T) {
+		ctx := context.Background()
+		st, vms, outAddr := createMinerWithPower(t)
+		minerAddr := th.RequireActorIDAddress(ctx, t, st, vms, outAddr)
+
+		result := callQueryMethodSuccess(GetFaults, ctx, t, st, vms, address.TestAddress, minerAddr)
+		faultsVal, err := abi.Deserialize(result[0], abi.UintArray)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Uint64{0, 0}, faultsVal.Val)
+
+		minerActor, err := st.GetActor(ctx, minerAddr)
+		require.NoError(t, err)
+		balanceBefore := minerActor.Balance
+		collateral := MinimumCollateralPerSector.MulBigInt(big.NewInt(2))
+
+		slashTime := lastPossibleSubmission + 1
+		res, err := th.CreateAndApplyTestMessage(t, st, vms, minerAddr, 0, slashTime, SlashStorageFault, nil)
+		require.NoError(t, err)
+		require.NoError(t, res.ExecutionError)
+
+		minerState := mustGetMinerState(st, vms, minerAddr)
+		assert.Equal(t, collateral, minerState.SlashedCollateral)
+		assert.True(t, minerState.ActiveCollateral.IsZero())
+
+		minerActor, err = st.GetActor(ctx, minerAddr)
+		require.NoError(t, err)
+		assert.Equal(t, balanceBefore.Sub(collateral), minerActor.Balance)
+
+		result = callQueryMethodSuccess(GetFaults, ctx, t, st, vms, address.TestAddress, minerAddr)
+		faultsVal, err = abi.Deserialize(result[0], abi.UintArray)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Uint64{2, types.Uint64(slashTime)}, faultsVal.Val)
+
+		result = callQueryMethodSuccess(GetSlashedCollateral, ctx, t, st, vms, address.TestAddress, minerAddr)
+		slashedVal, err := abi.Deserialize(result[0], abi.AttoFIL)
+		require.NoError(t, err)
+		assert.Equal(t, collateral, slashedVal.Val)
+	})
+
 	t.Run("slashing a miner twice fails", func(t *testing.T) {
 		st, vms, minerAddr := createMinerWithPower(t)
 
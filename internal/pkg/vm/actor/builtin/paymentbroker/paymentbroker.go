@@ -707,7 +707,7 @@ const separator = 0x0
 // channel, amount, validAt (earliest block height for redeem) and from address.
 // It does so by signing the following bytes: (channelID | 0x0 | amount | 0x0 | validAt)
 func SignVoucher(channelID *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, addr address.Address, condition *types.Predicate, signer types.Signer) (types.Signature, error) {
-	data, err := createVoucherSignatureData(channelID, amount, validAt, condition)
+	data, err := VoucherSignatureData(channelID, amount, validAt, condition)
 	if err != nil {
 		return nil, err
 	}
@@ -716,7 +716,7 @@ func SignVoucher(channelID *types.ChannelID, amount types.AttoFIL, validAt *type
 
 // VerifyVoucherSignature returns whether the voucher's signature is valid
 func VerifyVoucherSignature(payer address.Address, chid *types.ChannelID, amt types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate, sig []byte) bool {
-	data, err := createVoucherSignatureData(chid, amt, validAt, condition)
+	data, err := VoucherSignatureData(chid, amt, validAt, condition)
 	// the only error is failure to encode the values
 	if err != nil {
 		return false
@@ -724,7 +724,9 @@ func VerifyVoucherSignature(payer address.Address, chid *types.ChannelID, amt ty
 	return types.IsValidSignature(data, payer, sig)
 }
 
-func createVoucherSignatureData(channelID *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, error) {
+// VoucherSignatureData returns the bytes SignVoucher signs for the given
+// combination of channel, amount, condition and validAt.
+func VoucherSignatureData(channelID *types.ChannelID, amount types.AttoFIL, validAt *types.BlockHeight, condition *types.Predicate) ([]byte, error) {
 	data := append(channelID.Bytes(), separator)
 	data = append(data, amount.Bytes()...)
 	data = append(data, separator)
@@ -1,6 +1,8 @@
 package wallet
 
 import (
+	"context"
+
 	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
 )
@@ -30,3 +32,56 @@ type Importer interface {
 	// into the backend
 	ImportKey(ki *types.KeyInfo) error
 }
+
+// Exporter is a specialization of a wallet backend that can hand out the
+// keys it holds, e.g. for backups. Disk backed wallets can do this, hardware
+// and remote signers refuse to.
+type Exporter interface {
+	// ExportKey returns the keyinfo of the given address
+	// iff the backend holds it.
+	ExportKey(addr address.Address) (*types.KeyInfo, error)
+}
+
+// PublicKeyGetter is a specialization of a wallet backend that can hand out
+// the public key of an address it signs for without exposing the private key,
+// e.g. to verify signatures or to encrypt to the holder of the address.
+type PublicKeyGetter interface {
+	// GetPublicKey returns the public key of the given address, in the
+	// encoding of its signature scheme.
+	GetPublicKey(addr address.Address) ([]byte, error)
+}
+
+// Deleter is a specialization of a wallet backend that can remove keys
+// from its permanent storage, e.g. after a key was rotated.
+type Deleter interface {
+	// DeleteAddress removes the key of the given address from the
+	// backend. It returns ErrUnknownAddress if the backend does not
+	// contain the address.
+	DeleteAddress(addr address.Address) error
+}
+
+// ContextSigner is a specialization of a wallet backend whose signing can be
+// cancelled, e.g. a remote signer or a hardware wallet that waits for the
+// user to confirm on the device. The wallet prefers it over SignBytes.
+type ContextSigner interface {
+	// SignBytesContext is SignBytes, but gives up and returns ctx.Err()
+	// once ctx is done.
+	SignBytesContext(ctx context.Context, data []byte, addr address.Address) (types.Signature, error)
+}
+
+// Verifier is a specialization of a wallet backend that checks the
+// signatures of its addresses itself, e.g. because they are not plain
+// secp256k1 or BLS signatures.
+type Verifier interface {
+	// Verify checks that `sig` is a signature of `data` by `addr`.
+	Verify(data []byte, addr address.Address, sig types.Signature) bool
+}
+
+// BatchSigner is a specialization of a wallet backend that signs many items
+// with the same key in one call, looking the key up only once. The wallet
+// prefers it over SignBytes for batches.
+type BatchSigner interface {
+	// SignBytesBatch signs each of `items` with the key of `addr` and
+	// returns the signatures in the same order.
+	SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error)
+}
@@ -0,0 +1,151 @@
+package wallet
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/filecoin-project/go-bls-sigs"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// BLSBackendType is the reflect type of the BLSBackend.
+var BLSBackendType = reflect.TypeOf(&BLSBackend{})
+
+// BLSBackend is a wallet backend that only holds BLS keys, whose signatures
+// can be aggregated. Keys are kept in memory and are lost when the backend is
+// discarded; use the DSBackend to persist BLS keys.
+type BLSBackend struct {
+	lk   sync.RWMutex
+	keys map[address.Address]*types.KeyInfo
+}
+
+var _ Backend = (*BLSBackend)(nil)
+var _ Importer = (*BLSBackend)(nil)
+var _ Exporter = (*BLSBackend)(nil)
+var _ Deleter = (*BLSBackend)(nil)
+var _ PublicKeyGetter = (*BLSBackend)(nil)
+
+// NewBLSBackend constructs an empty BLS backend.
+func NewBLSBackend() *BLSBackend {
+	return &BLSBackend{keys: make(map[address.Address]*types.KeyInfo)}
+}
+
+// ImportKey adds the BLS key described by `ki` to the backend.
+func (backend *BLSBackend) ImportKey(ki *types.KeyInfo) error {
+	if ki.CryptSystem != types.BLS {
+		return errors.Errorf("bls backend cannot hold %s keys", ki.CryptSystem)
+	}
+	if err := ki.Validate(); err != nil {
+		return err
+	}
+	addr, err := ki.Address()
+	if err != nil {
+		return err
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+	backend.keys[addr] = &types.KeyInfo{
+		PrivateKey:  append([]byte{}, ki.PrivateKey...),
+		CryptSystem: ki.CryptSystem,
+	}
+	return nil
+}
+
+// ExportKey returns the key of address `addr` iff the backend holds it.
+func (backend *BLSBackend) ExportKey(addr address.Address) (*types.KeyInfo, error) {
+	return backend.GetKeyInfo(addr)
+}
+
+// GetPublicKey returns the BLS public key of address `addr`.
+func (backend *BLSBackend) GetPublicKey(addr address.Address) ([]byte, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+	return ki.PublicKey(), nil
+}
+
+// DeleteAddress removes the key of address `addr` from the backend.
+func (backend *BLSBackend) DeleteAddress(addr address.Address) error {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if _, ok := backend.keys[addr]; !ok {
+		return ErrUnknownAddress
+	}
+	delete(backend.keys, addr)
+	return nil
+}
+
+// NewAddress generates a new BLS key and returns its address.
+func (backend *BLSBackend) NewAddress() (address.Address, error) {
+	privateKey := bls.PrivateKeyGenerate()
+	ki := &types.KeyInfo{
+		PrivateKey:  privateKey[:],
+		CryptSystem: types.BLS,
+	}
+	if err := backend.ImportKey(ki); err != nil {
+		return address.Undef, err
+	}
+	return ki.Address()
+}
+
+// Addresses returns the addresses of the keys in the backend.
+func (backend *BLSBackend) Addresses() []address.Address {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	var cpy []address.Address
+	for addr := range backend.keys {
+		cpy = append(cpy, addr)
+	}
+	return cpy
+}
+
+// HasAddress checks if the backend holds the key of the passed in address.
+// Safe for concurrent access.
+func (backend *BLSBackend) HasAddress(addr address.Address) bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	_, ok := backend.keys[addr]
+	return ok
+}
+
+// SignBytes signs `data` with the BLS key of `addr`.
+func (backend *BLSBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+	return crypto.SignBLS(ki.PrivateKey, data)
+}
+
+// Verify checks that `sig` is a BLS signature of `data` by the key of `addr`.
+// The key does not need to be in the backend.
+func (backend *BLSBackend) Verify(data []byte, addr address.Address, sig types.Signature) bool {
+	if addr.Protocol() != address.BLS || len(sig) != bls.SignatureBytes {
+		return false
+	}
+	return crypto.VerifyBLS(addr.Payload(), data, sig)
+}
+
+// GetKeyInfo returns the key of address `addr` iff the backend holds it.
+func (backend *BLSBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	ki, ok := backend.keys[addr]
+	if !ok {
+		return nil, errors.New("backend does not contain address")
+	}
+	return &types.KeyInfo{
+		PrivateKey:  append([]byte{}, ki.PrivateKey...),
+		CryptSystem: ki.CryptSystem,
+	}, nil
+}
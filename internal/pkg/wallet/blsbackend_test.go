@@ -0,0 +1,71 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+func TestBLSBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	dsb, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	blsb := wallet.NewBLSBackend()
+	w := wallet.New(dsb, blsb)
+
+	secpAddr, err := dsb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	blsAddr, err := blsb.NewAddress()
+	require.NoError(t, err)
+
+	assert.Equal(t, address.BLS, blsAddr.Protocol())
+	assert.Len(t, w.Backends(wallet.BLSBackendType), 1)
+	assert.ElementsMatch(t, []address.Address{secpAddr, blsAddr}, w.Addresses())
+
+	t.Run("signs with secp and bls addresses in the same wallet", func(t *testing.T) {
+		data := []byte("data to be signed")
+
+		secpSig, err := w.SignBytes(data, secpAddr)
+		require.NoError(t, err)
+		blsSig, err := w.SignBytes(data, blsAddr)
+		require.NoError(t, err)
+
+		assert.True(t, types.IsValidSignature(data, secpAddr, secpSig))
+		assert.True(t, types.IsValidSignature(data, blsAddr, blsSig))
+		assert.True(t, blsb.Verify(data, blsAddr, blsSig))
+
+		assert.False(t, types.IsValidSignature([]byte("other data"), blsAddr, blsSig))
+		assert.False(t, blsb.Verify([]byte("other data"), blsAddr, blsSig))
+		assert.False(t, blsb.Verify(data, secpAddr, secpSig))
+	})
+
+	t.Run("imports bls keys only", func(t *testing.T) {
+		// One bls key followed by one secp key.
+		keys := types.MustGenerateMixedKeyInfo(1, 1)
+		require.NoError(t, blsb.ImportKey(&keys[0]))
+		imported, err := keys[0].Address()
+		require.NoError(t, err)
+		assert.True(t, w.HasAddress(imported))
+
+		ki, err := blsb.GetKeyInfo(imported)
+		require.NoError(t, err)
+		assert.True(t, ki.Equals(&keys[0]))
+
+		assert.Error(t, blsb.ImportKey(&keys[1]))
+		assert.Error(t, blsb.ImportKey(&types.KeyInfo{PrivateKey: []byte("short"), CryptSystem: types.BLS}))
+	})
+
+	t.Run("does not know other addresses", func(t *testing.T) {
+		assert.False(t, blsb.HasAddress(secpAddr))
+		_, err := blsb.SignBytes([]byte("data"), secpAddr)
+		assert.Error(t, err)
+	})
+}
@@ -29,9 +29,19 @@ type DSBackend struct {
 
 	// TODO: proper cache
 	cache map[address.Address]struct{}
+
+	// keys caches the keys read from the datastore, so that signing does not
+	// read and decode them every time. Nil if keys must not be kept in
+	// memory.
+	keys map[address.Address]*types.KeyInfo
 }
 
 var _ Backend = (*DSBackend)(nil)
+var _ Importer = (*DSBackend)(nil)
+var _ Exporter = (*DSBackend)(nil)
+var _ Deleter = (*DSBackend)(nil)
+var _ PublicKeyGetter = (*DSBackend)(nil)
+var _ BatchSigner = (*DSBackend)(nil)
 
 // NewDSBackend constructs a new backend using the passed in datastore.
 func NewDSBackend(ds repo.Datastore) (*DSBackend, error) {
@@ -59,6 +69,7 @@ func NewDSBackend(ds repo.Datastore) (*DSBackend, error) {
 	return &DSBackend{
 		ds:    ds,
 		cache: cache,
+		keys:  make(map[address.Address]*types.KeyInfo),
 	}, nil
 }
 
@@ -67,6 +78,38 @@ func (backend *DSBackend) ImportKey(ki *types.KeyInfo) error {
 	return backend.putKeyInfo(ki)
 }
 
+// ExportKey returns the KeyInfo of address `addr` iff the backend contains it.
+func (backend *DSBackend) ExportKey(addr address.Address) (*types.KeyInfo, error) {
+	return backend.GetKeyInfo(addr)
+}
+
+// GetPublicKey returns the public key of address `addr` iff the backend
+// contains it.
+func (backend *DSBackend) GetPublicKey(addr address.Address) ([]byte, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+	return ki.PublicKey(), nil
+}
+
+// DeleteAddress removes the key of address `addr` from the datastore.
+// Safe for concurrent access.
+func (backend *DSBackend) DeleteAddress(addr address.Address) error {
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+
+	if _, ok := backend.cache[addr]; !ok {
+		return ErrUnknownAddress
+	}
+	if err := backend.ds.Delete(ds.NewKey(addr.String())); err != nil {
+		return errors.Wrap(err, "failed to delete key from backend")
+	}
+	delete(backend.cache, addr)
+	delete(backend.keys, addr)
+	return nil
+}
+
 // Addresses returns a list of all addresses that are stored in this backend.
 func (backend *DSBackend) Addresses() []address.Address {
 	backend.lk.RLock()
@@ -155,6 +198,7 @@ func (backend *DSBackend) putKeyInfo(ki *types.KeyInfo) error {
 	}
 
 	backend.cache[a] = struct{}{}
+	delete(backend.keys, a)
 	return nil
 }
 
@@ -165,6 +209,27 @@ func (backend *DSBackend) SignBytes(data []byte, addr address.Address) (types.Si
 		return nil, err
 	}
 
+	return signWithKey(data, ki)
+}
+
+// SignBytesBatch signs each of `items` with the key of `addr`, loading the
+// key only once.
+func (backend *DSBackend) SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+
+	sigs := make([]types.Signature, len(items))
+	for i, data := range items {
+		if sigs[i], err = signWithKey(data, ki); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
+func signWithKey(data []byte, ki *types.KeyInfo) (types.Signature, error) {
 	if ki.CryptSystem == types.BLS {
 		return crypto.SignBLS(ki.PrivateKey, data)
 	}
@@ -175,11 +240,21 @@ func (backend *DSBackend) SignBytes(data []byte, addr address.Address) (types.Si
 }
 
 // GetKeyInfo will return the private & public keys associated with address `addr`
-// iff backend contains the addr.
+// iff backend contains the addr. The key is read from the datastore the first
+// time and cached in memory afterwards.
+// Safe for concurrent access.
 func (backend *DSBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
-	if !backend.HasAddress(addr) {
+	backend.lk.RLock()
+	_, ok := backend.cache[addr]
+	cached := backend.keys[addr]
+	backend.lk.RUnlock()
+
+	if !ok {
 		return nil, errors.New("backend does not contain address")
 	}
+	if cached != nil {
+		return copyKeyInfo(cached), nil
+	}
 
 	// kib is a cbor of types.KeyInfo
 	kib, err := backend.ds.Get(ds.NewKey(addr.String()))
@@ -192,5 +267,19 @@ func (backend *DSBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, erro
 		return nil, errors.Wrap(err, "failed to unmarshal keyinfo from backend")
 	}
 
+	backend.lk.Lock()
+	// Don't cache the key if it was deleted while it was read.
+	if _, ok := backend.cache[addr]; ok && backend.keys != nil {
+		backend.keys[addr] = copyKeyInfo(ki)
+	}
+	backend.lk.Unlock()
+
 	return ki, nil
 }
+
+func copyKeyInfo(ki *types.KeyInfo) *types.KeyInfo {
+	return &types.KeyInfo{
+		PrivateKey:  append([]byte{}, ki.PrivateKey...),
+		CryptSystem: ki.CryptSystem,
+	}
+}
@@ -10,6 +10,7 @@ import (
 	"github.com/stretchr/testify/require"
 
 	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
 )
 
 func TestDSBackendSimple(t *testing.T) {
@@ -108,6 +109,34 @@ func TestDSBackendErrorsForUnknownAddress(t *testing.T) {
 
 }
 
+func TestDSBackendDeleteAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+
+	addr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	require.NoError(t, fs.DeleteAddress(addr))
+
+	t.Log("address is gone from the backend and the datastore")
+	assert.False(t, fs.HasAddress(addr))
+	has, err := ds.Has(datastore.NewKey(addr.String()))
+	require.NoError(t, err)
+	assert.False(t, has)
+
+	fs2, err := NewDSBackend(ds)
+	require.NoError(t, err)
+	assert.False(t, fs2.HasAddress(addr))
+
+	t.Log("deleting it again reports an unknown address")
+	assert.Equal(t, ErrUnknownAddress, fs.DeleteAddress(addr))
+}
+
 func TestDSBackendParallel(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -133,3 +162,81 @@ func TestDSBackendParallel(t *testing.T) {
 	wg.Wait()
 	assert.Len(t, fs.Addresses(), 10)
 }
+
+func TestDSBackendKeyCache(t *testing.T) {
+	tf.UnitTest(t)
+
+	ds := datastore.NewMapDatastore()
+	defer func() {
+		require.NoError(t, ds.Close())
+	}()
+	fs, err := NewDSBackend(ds)
+	require.NoError(t, err)
+
+	addr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	assert.NotContains(t, fs.keys, addr)
+
+	t.Log("the key is cached when it is first read")
+	ki, err := fs.GetKeyInfo(addr)
+	require.NoError(t, err)
+	assert.Contains(t, fs.keys, addr)
+
+	t.Log("callers cannot modify the cached key")
+	ki.PrivateKey[0]++
+	cached, err := fs.GetKeyInfo(addr)
+	require.NoError(t, err)
+	assert.False(t, ki.Equals(cached))
+	ki.PrivateKey[0]--
+	assert.True(t, ki.Equals(cached))
+
+	t.Log("deleting the address drops the cached key")
+	require.NoError(t, fs.DeleteAddress(addr))
+	assert.NotContains(t, fs.keys, addr)
+	_, err = fs.SignBytes([]byte("data"), addr)
+	assert.Contains(t, err.Error(), "backend does not contain address")
+
+	t.Log("a re-imported key is read from the datastore again")
+	require.NoError(t, fs.ImportKey(ki))
+	assert.NotContains(t, fs.keys, addr)
+	sig, err := fs.SignBytes([]byte("data"), addr)
+	require.NoError(t, err)
+	assert.True(t, types.IsValidSignature([]byte("data"), addr, sig))
+	assert.Contains(t, fs.keys, addr)
+
+	t.Log("the encrypted backend does not cache keys")
+	eb, err := NewEncryptedBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	require.NoError(t, eb.Unlock("passphrase"))
+	eaddr, err := eb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	_, err = eb.SignBytes([]byte("data"), eaddr)
+	require.NoError(t, err)
+	assert.Empty(t, eb.keys)
+}
+
+func BenchmarkDSBackendSignBytesCached(b *testing.B) {
+	benchmarkDSBackendSignBytes(b, true)
+}
+
+func BenchmarkDSBackendSignBytesUncached(b *testing.B) {
+	benchmarkDSBackendSignBytes(b, false)
+}
+
+func benchmarkDSBackendSignBytes(b *testing.B, cached bool) {
+	fs, err := NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(b, err)
+	if !cached {
+		fs.keys = nil
+	}
+	addr, err := fs.NewAddress(address.SECP256K1)
+	require.NoError(b, err)
+	data := []byte("data to be signed")
+
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := fs.SignBytes(data, addr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
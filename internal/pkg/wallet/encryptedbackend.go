@@ -0,0 +1,237 @@
+package wallet
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"io"
+	"reflect"
+	"sync"
+
+	ds "github.com/ipfs/go-datastore"
+	dsq "github.com/ipfs/go-datastore/query"
+	"github.com/pkg/errors"
+	"golang.org/x/crypto/scrypt"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/repo"
+)
+
+var (
+	// ErrWalletLocked is returned when the keys of a locked encrypted backend are used.
+	ErrWalletLocked = errors.New("wallet is locked")
+
+	// ErrAuthentication is returned when a key cannot be decrypted, i.e. the
+	// passphrase is wrong or the stored key was tampered with.
+	ErrAuthentication = errors.New("wrong passphrase or corrupted key")
+)
+
+// EncryptedBackendType is the reflect type of the EncryptedBackend.
+var EncryptedBackendType = reflect.TypeOf(&EncryptedBackend{})
+
+// EncryptedBackend is a datastore backend that keeps its keys encrypted at
+// rest. Keys are encrypted with AES-GCM under a key derived from a passphrase
+// with scrypt. The backend starts locked: its addresses are known, but it
+// can neither sign nor store new keys until it is unlocked.
+type EncryptedBackend struct {
+	*DSBackend
+
+	store *encryptedDatastore
+}
+
+var _ Backend = (*EncryptedBackend)(nil)
+var _ Importer = (*EncryptedBackend)(nil)
+var _ Exporter = (*EncryptedBackend)(nil)
+var _ Deleter = (*EncryptedBackend)(nil)
+var _ BatchSigner = (*EncryptedBackend)(nil)
+
+// NewEncryptedBackend constructs a locked backend storing its keys in the
+// passed in datastore, which must hold no plaintext keys.
+func NewEncryptedBackend(store repo.Datastore) (*EncryptedBackend, error) {
+	eds := &encryptedDatastore{Datastore: store}
+	backend, err := NewDSBackend(eds)
+	if err != nil {
+		return nil, err
+	}
+	// Decrypted keys are not cached, so that a locked backend cannot sign.
+	backend.keys = nil
+	return &EncryptedBackend{DSBackend: backend, store: eds}, nil
+}
+
+// Unlock derives the encryption key from passphrase and checks it against
+// the stored keys. It returns ErrAuthentication if any of them cannot be
+// decrypted. The first passphrase a backend without keys is unlocked with
+// becomes its passphrase.
+func (backend *EncryptedBackend) Unlock(passphrase string) error {
+	return backend.store.unlock(passphrase)
+}
+
+// Lock forgets the encryption key until the backend is unlocked again.
+func (backend *EncryptedBackend) Lock() {
+	backend.store.lock()
+}
+
+// Locked returns true if the backend is locked.
+func (backend *EncryptedBackend) Locked() bool {
+	return backend.store.locked()
+}
+
+// scrypt parameters, as recommended for interactive logins in 2017.
+const (
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	scryptSalt   = 16
+)
+
+// encryptedDatastore encrypts the values put into the datastore it wraps and
+// decrypts the values it gets. A value is stored as the scrypt salt, the
+// AES-GCM nonce and the sealed plaintext, authenticated with its key.
+type encryptedDatastore struct {
+	repo.Datastore
+
+	lk sync.RWMutex
+	// salt is the salt of values put into the datastore, nil while locked
+	salt []byte
+	// aeads are the ciphers derived from the passphrase, by salt
+	aeads map[string]cipher.AEAD
+}
+
+func (eds *encryptedDatastore) unlock(passphrase string) error {
+	results, err := eds.Datastore.Query(dsq.Query{})
+	if err != nil {
+		return errors.Wrap(err, "failed to query datastore")
+	}
+	entries, err := results.Rest()
+	if err != nil {
+		return errors.Wrap(err, "failed to read query results")
+	}
+
+	// Check the passphrase against every stored key, deriving a cipher once
+	// per salt, before accepting it.
+	var salt []byte
+	aeads := make(map[string]cipher.AEAD)
+	for _, entry := range entries {
+		if len(entry.Value) < scryptSalt {
+			return ErrAuthentication
+		}
+		entrySalt := entry.Value[:scryptSalt]
+		aead, ok := aeads[string(entrySalt)]
+		if !ok {
+			aead, err = deriveAEAD(passphrase, entrySalt)
+			if err != nil {
+				return err
+			}
+			aeads[string(entrySalt)] = aead
+		}
+		if _, err := open(aead, ds.NewKey(entry.Key), entry.Value); err != nil {
+			return err
+		}
+		salt = entrySalt
+	}
+
+	if salt == nil {
+		salt = make([]byte, scryptSalt)
+		if _, err := io.ReadFull(rand.Reader, salt); err != nil {
+			return err
+		}
+		aead, err := deriveAEAD(passphrase, salt)
+		if err != nil {
+			return err
+		}
+		aeads[string(salt)] = aead
+	}
+
+	eds.lk.Lock()
+	defer eds.lk.Unlock()
+	eds.salt = append([]byte{}, salt...)
+	eds.aeads = aeads
+	return nil
+}
+
+func (eds *encryptedDatastore) lock() {
+	eds.lk.Lock()
+	defer eds.lk.Unlock()
+	eds.salt = nil
+	eds.aeads = nil
+}
+
+func (eds *encryptedDatastore) locked() bool {
+	eds.lk.RLock()
+	defer eds.lk.RUnlock()
+	return eds.salt == nil
+}
+
+// Put encrypts value before putting it into the wrapped datastore.
+func (eds *encryptedDatastore) Put(key ds.Key, value []byte) error {
+	eds.lk.RLock()
+	salt, aead := eds.salt, eds.aeads[string(eds.salt)]
+	eds.lk.RUnlock()
+	if salt == nil {
+		return ErrWalletLocked
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return err
+	}
+	sealed := append(append([]byte{}, salt...), nonce...)
+	sealed = aead.Seal(sealed, nonce, value, key.Bytes())
+	return eds.Datastore.Put(key, sealed)
+}
+
+// Get decrypts the value it gets from the wrapped datastore.
+func (eds *encryptedDatastore) Get(key ds.Key) ([]byte, error) {
+	sealed, err := eds.Datastore.Get(key)
+	if err != nil {
+		return nil, err
+	}
+	if len(sealed) < scryptSalt {
+		return nil, ErrAuthentication
+	}
+
+	eds.lk.RLock()
+	aead, ok := eds.aeads[string(sealed[:scryptSalt])]
+	eds.lk.RUnlock()
+	if !ok {
+		return nil, ErrWalletLocked
+	}
+
+	value, err := open(aead, key, sealed)
+	if err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// Batch is not supported, batched puts would not be encrypted.
+func (eds *encryptedDatastore) Batch() (ds.Batch, error) {
+	return nil, ds.ErrBatchUnsupported
+}
+
+func deriveAEAD(passphrase string, salt []byte) (cipher.AEAD, error) {
+	key, err := scrypt.Key([]byte(passphrase), salt, scryptN, scryptR, scryptP, scryptKeyLen)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to derive key from passphrase")
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+	return cipher.NewGCM(block)
+}
+
+// open decrypts the value sealed under key. Sealed values are bound to their
+// key, so that the keys of two addresses cannot be swapped.
+func open(aead cipher.AEAD, key ds.Key, sealed []byte) ([]byte, error) {
+	rest := sealed[scryptSalt:]
+	if len(rest) < aead.NonceSize() {
+		return nil, ErrAuthentication
+	}
+	nonce, ciphertext := rest[:aead.NonceSize()], rest[aead.NonceSize():]
+	value, err := aead.Open(nil, nonce, ciphertext, key.Bytes())
+	if err != nil {
+		return nil, ErrAuthentication
+	}
+	return value, nil
+}
@@ -0,0 +1,97 @@
+package wallet_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+func TestEncryptedBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	store := datastore.NewMapDatastore()
+	eb, err := wallet.NewEncryptedBackend(store)
+	require.NoError(t, err)
+	assert.True(t, eb.Locked())
+
+	_, err = eb.NewAddress(address.SECP256K1)
+	assert.Equal(t, wallet.ErrWalletLocked, errors.Cause(err))
+
+	require.NoError(t, eb.Unlock("correct horse"))
+	addr, err := eb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	ki, err := eb.GetKeyInfo(addr)
+	require.NoError(t, err)
+
+	imported := types.MustGenerateKeyInfo(1, 42)[0]
+	require.NoError(t, eb.ImportKey(&imported))
+	importedAddr, err := imported.Address()
+	require.NoError(t, err)
+
+	data := []byte("data to be signed")
+
+	t.Run("keys are encrypted at rest", func(t *testing.T) {
+		for _, key := range []*types.KeyInfo{ki, &imported} {
+			a, err := key.Address()
+			require.NoError(t, err)
+			stored, err := store.Get(datastore.NewKey(a.String()))
+			require.NoError(t, err)
+			assert.False(t, bytes.Contains(stored, key.PrivateKey))
+		}
+	})
+
+	t.Run("a locked wallet refuses to sign", func(t *testing.T) {
+		w := wallet.New(eb)
+		eb.Lock()
+		defer func() { require.NoError(t, eb.Unlock("correct horse")) }()
+
+		assert.True(t, w.HasAddress(addr))
+		_, err := w.SignBytes(data, addr)
+		assert.Equal(t, wallet.ErrWalletLocked, errors.Cause(err))
+	})
+
+	t.Run("a wrong passphrase fails to authenticate", func(t *testing.T) {
+		reopened, err := wallet.NewEncryptedBackend(store)
+		require.NoError(t, err)
+		assert.Equal(t, wallet.ErrAuthentication, reopened.Unlock("battery staple"))
+		assert.True(t, reopened.Locked())
+	})
+
+	t.Run("keys survive reopening the backend", func(t *testing.T) {
+		reopened, err := wallet.NewEncryptedBackend(store)
+		require.NoError(t, err)
+		assert.ElementsMatch(t, []address.Address{addr, importedAddr}, reopened.Addresses())
+
+		require.NoError(t, wallet.New(reopened).Unlock("correct horse"))
+		got, err := reopened.GetKeyInfo(addr)
+		require.NoError(t, err)
+		assert.True(t, ki.Equals(got))
+
+		sig, err := reopened.SignBytes(data, importedAddr)
+		require.NoError(t, err)
+		assert.True(t, types.IsValidSignature(data, importedAddr, sig))
+	})
+
+	t.Run("swapped keys do not decrypt", func(t *testing.T) {
+		swapped := datastore.NewMapDatastore()
+		a, err := store.Get(datastore.NewKey(addr.String()))
+		require.NoError(t, err)
+		b, err := store.Get(datastore.NewKey(importedAddr.String()))
+		require.NoError(t, err)
+		require.NoError(t, swapped.Put(datastore.NewKey(addr.String()), b))
+		require.NoError(t, swapped.Put(datastore.NewKey(importedAddr.String()), a))
+
+		reopened, err := wallet.NewEncryptedBackend(swapped)
+		require.NoError(t, err)
+		assert.Equal(t, wallet.ErrAuthentication, reopened.Unlock("correct horse"))
+	})
+}
@@ -0,0 +1,129 @@
+package wallet
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/minio/blake2b-simd"
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// HDBackendType is the reflect type of the HDBackend.
+var HDBackendType = reflect.TypeOf(&HDBackend{})
+
+// HDBackend is a wallet backend holding secp256k1 keys derived from a BIP39
+// mnemonic following BIP32, along the BIP44 path m/44'/461'/0'/0/i. Keys are
+// kept in memory and are lost when the backend is discarded; import the keys
+// of MnemonicKeys into the DSBackend to persist them.
+type HDBackend struct {
+	lk   sync.RWMutex
+	keys map[address.Address]*types.KeyInfo
+}
+
+var _ Backend = (*HDBackend)(nil)
+var _ Importer = (*HDBackend)(nil)
+var _ Exporter = (*HDBackend)(nil)
+
+// NewHDBackend constructs an empty HD backend.
+func NewHDBackend() *HDBackend {
+	return &HDBackend{keys: make(map[address.Address]*types.KeyInfo)}
+}
+
+// ImportMnemonic derives the keys of the first `count` addresses of the
+// mnemonic `phrase` and adds them to the backend. Importing the same phrase
+// again yields the same addresses.
+func (backend *HDBackend) ImportMnemonic(phrase string, count int) ([]address.Address, error) {
+	kis, err := MnemonicKeys(phrase, count)
+	if err != nil {
+		return nil, err
+	}
+
+	addrs := make([]address.Address, len(kis))
+	for i, ki := range kis {
+		if err := backend.ImportKey(ki); err != nil {
+			return nil, err
+		}
+		if addrs[i], err = ki.Address(); err != nil {
+			return nil, err
+		}
+	}
+	return addrs, nil
+}
+
+// ImportKey adds the secp256k1 key described by `ki` to the backend.
+func (backend *HDBackend) ImportKey(ki *types.KeyInfo) error {
+	if ki.CryptSystem != types.SECP256K1 {
+		return errors.Errorf("hd backend cannot hold %s keys", ki.CryptSystem)
+	}
+	if err := ki.Validate(); err != nil {
+		return err
+	}
+	addr, err := ki.Address()
+	if err != nil {
+		return err
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+	backend.keys[addr] = &types.KeyInfo{
+		PrivateKey:  append([]byte{}, ki.PrivateKey...),
+		CryptSystem: ki.CryptSystem,
+	}
+	return nil
+}
+
+// ExportKey returns the key of address `addr` iff the backend holds it.
+func (backend *HDBackend) ExportKey(addr address.Address) (*types.KeyInfo, error) {
+	return backend.GetKeyInfo(addr)
+}
+
+// Addresses returns the addresses of the keys in the backend.
+func (backend *HDBackend) Addresses() []address.Address {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	var cpy []address.Address
+	for addr := range backend.keys {
+		cpy = append(cpy, addr)
+	}
+	return cpy
+}
+
+// HasAddress checks if the backend holds the key of the passed in address.
+// Safe for concurrent access.
+func (backend *HDBackend) HasAddress(addr address.Address) bool {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	_, ok := backend.keys[addr]
+	return ok
+}
+
+// SignBytes signs `data` with the key of `addr`.
+func (backend *HDBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	ki, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
+	hash := blake2b.Sum256(data)
+	return crypto.SignSecp(ki.PrivateKey, hash[:])
+}
+
+// GetKeyInfo returns the key of address `addr` iff the backend holds it.
+func (backend *HDBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	backend.lk.RLock()
+	defer backend.lk.RUnlock()
+
+	ki, ok := backend.keys[addr]
+	if !ok {
+		return nil, errors.New("backend does not contain address")
+	}
+	return &types.KeyInfo{
+		PrivateKey:  append([]byte{}, ki.PrivateKey...),
+		CryptSystem: ki.CryptSystem,
+	}, nil
+}
@@ -0,0 +1,62 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+func TestHDBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	hdb := wallet.NewHDBackend()
+	w := wallet.New(hdb)
+
+	addrs, err := hdb.ImportMnemonic(testMnemonic, 3)
+	require.NoError(t, err)
+	require.Len(t, addrs, 3)
+	assert.ElementsMatch(t, addrs, w.Addresses())
+	for _, addr := range addrs {
+		assert.Equal(t, address.SECP256K1, addr.Protocol())
+	}
+
+	t.Run("yields the same addresses for the same phrase", func(t *testing.T) {
+		again, err := wallet.NewHDBackend().ImportMnemonic(testMnemonic, 3)
+		require.NoError(t, err)
+		assert.Equal(t, addrs, again)
+
+		// Importing into the same backend again keeps the addresses.
+		again, err = hdb.ImportMnemonic(testMnemonic, 3)
+		require.NoError(t, err)
+		assert.Equal(t, addrs, again)
+		assert.Len(t, hdb.Addresses(), 3)
+	})
+
+	t.Run("signs with derived keys", func(t *testing.T) {
+		data := []byte("data to be signed")
+		sig, err := w.SignBytes(data, addrs[1])
+		require.NoError(t, err)
+		assert.True(t, types.IsValidSignature(data, addrs[1], sig))
+		assert.False(t, types.IsValidSignature(data, addrs[0], sig))
+	})
+
+	t.Run("rejects counts out of range", func(t *testing.T) {
+		_, err := wallet.NewHDBackend().ImportMnemonic(testMnemonic, 0)
+		assert.Contains(t, err.Error(), "count must be positive")
+
+		_, err = wallet.NewHDBackend().ImportMnemonic(testMnemonic, wallet.MaxMnemonicKeys+1)
+		assert.Contains(t, err.Error(), "count must be at most")
+	})
+
+	t.Run("imports secp keys only", func(t *testing.T) {
+		keys := types.MustGenerateMixedKeyInfo(1, 1)
+		assert.Error(t, hdb.ImportKey(&keys[0]))
+		require.NoError(t, hdb.ImportKey(&keys[1]))
+	})
+}
@@ -0,0 +1,139 @@
+package wallet
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"math/big"
+	"strings"
+
+	"github.com/pkg/errors"
+	"github.com/tyler-smith/go-bip39"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/crypto"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+)
+
+// FilecoinCoinType is the SLIP-44 coin type of Filecoin, used in the
+// derivation path of HD keys.
+const FilecoinCoinType = 461
+
+// MaxMnemonicKeys is the most keys derived from one mnemonic at a time.
+const MaxMnemonicKeys = 100
+
+// hardened is added to the index of a derivation step to harden it.
+const hardened = 1 << 31
+
+// secp256k1N is the order of the secp256k1 curve.
+var secp256k1N, _ = new(big.Int).SetString("fffffffffffffffffffffffffffffffebaaedce6af48a03bbfd25e8cd0364141", 16)
+
+// MnemonicKeys derives the secp256k1 keys of the first `count` addresses of
+// the BIP39 mnemonic `phrase`, without a passphrase. Keys follow BIP32 along
+// the BIP44 path m/44'/461'/0'/0/i.
+func MnemonicKeys(phrase string, count int) ([]*types.KeyInfo, error) {
+	if count < 1 {
+		return nil, errors.Errorf("count must be positive, got %d", count)
+	}
+	if count > MaxMnemonicKeys {
+		return nil, errors.Errorf("count must be at most %d, got %d", MaxMnemonicKeys, count)
+	}
+	seed, err := mnemonicSeed(phrase, "")
+	if err != nil {
+		return nil, err
+	}
+
+	kis := make([]*types.KeyInfo, count)
+	for i := range kis {
+		path := []uint32{44 + hardened, FilecoinCoinType + hardened, hardened, 0, uint32(i)}
+		key, err := DeriveKey(seed, path)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to derive key %d", i)
+		}
+		kis[i] = &types.KeyInfo{
+			PrivateKey:  key,
+			CryptSystem: types.SECP256K1,
+		}
+	}
+	return kis, nil
+}
+
+// mnemonicSeed returns the BIP39 seed of the mnemonic `phrase`, after checking
+// its words against the English word list and its checksum.
+func mnemonicSeed(phrase, passphrase string) ([]byte, error) {
+	words := strings.Fields(phrase)
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, errors.Errorf("mnemonic must have 12, 15, 18, 21 or 24 words, got %d", len(words))
+	}
+	for _, w := range words {
+		if _, ok := bip39.GetWordIndex(w); !ok {
+			return nil, errors.Errorf("invalid mnemonic word %q", w)
+		}
+	}
+
+	seed, err := bip39.NewSeedWithErrorChecking(strings.Join(words, " "), passphrase)
+	if err == bip39.ErrChecksumIncorrect {
+		return nil, errors.New("invalid mnemonic checksum")
+	}
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid mnemonic")
+	}
+	return seed, nil
+}
+
+// DeriveKey derives the secp256k1 private key at `path` from the BIP32 master
+// `seed`. Indexes of hardened steps have the hardened bit (1<<31) set.
+func DeriveKey(seed []byte, path []uint32) ([]byte, error) {
+	key, chainCode, err := bip32Step([]byte("Bitcoin seed"), seed, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, index := range path {
+		var data []byte
+		if index >= hardened {
+			data = append([]byte{0}, key...)
+		} else {
+			data = compressedPublicKey(key)
+		}
+		data = append(data, 0, 0, 0, 0)
+		binary.BigEndian.PutUint32(data[len(data)-4:], index)
+
+		if key, chainCode, err = bip32Step(chainCode, data, key); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// bip32Step derives a key and chain code from the HMAC of data keyed with
+// hmacKey, adding the parent key if there is one.
+func bip32Step(hmacKey, data, parent []byte) ([]byte, []byte, error) {
+	mac := hmac.New(sha512.New, hmacKey)
+	_, _ = mac.Write(data)
+	sum := mac.Sum(nil)
+
+	k := new(big.Int).SetBytes(sum[:32])
+	if k.Cmp(secp256k1N) >= 0 {
+		return nil, nil, errors.New("derived key is out of range")
+	}
+	if parent != nil {
+		k.Add(k, new(big.Int).SetBytes(parent))
+		k.Mod(k, secp256k1N)
+	}
+	if k.Sign() == 0 {
+		return nil, nil, errors.New("derived key is zero")
+	}
+
+	key := make([]byte, crypto.PrivateKeyBytes)
+	blob := k.Bytes()
+	copy(key[crypto.PrivateKeyBytes-len(blob):], blob)
+	return key, sum[32:], nil
+}
+
+// compressedPublicKey returns the 33 byte SEC1 compressed public key of `key`.
+func compressedPublicKey(key []byte) []byte {
+	// The uncompressed key is 0x04 || x || y.
+	pub := crypto.PublicKey(key)
+	compressed := append([]byte{2 + pub[64]&1}, pub[1:33]...)
+	return compressed
+}
@@ -0,0 +1,79 @@
+package wallet_test
+
+import (
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+const testMnemonic = "abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about"
+
+func TestDeriveKey(t *testing.T) {
+	tf.UnitTest(t)
+
+	// Test vector 1 of BIP32.
+	seed, err := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	require.NoError(t, err)
+
+	for path, expected := range map[string]struct {
+		path []uint32
+		key  string
+	}{
+		"m":         {nil, "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		"m/0H":      {[]uint32{1 << 31}, "edb2e14f9ee77d26dd93b4ecede8d16ed408ce149b6cd80b0715a2d911a0afea"},
+		"m/0H/1":    {[]uint32{1 << 31, 1}, "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		"m/0H/1/2H": {[]uint32{1 << 31, 1, 2 + 1<<31}, "cbce0d719ecf7431d88e6a89fa1483e02e35092af60c042b1df2ff59fa424dca"},
+	} {
+		key, err := wallet.DeriveKey(seed, expected.path)
+		require.NoError(t, err)
+		assert.Equal(t, expected.key, hex.EncodeToString(key), path)
+	}
+}
+
+func TestMnemonicKeys(t *testing.T) {
+	tf.UnitTest(t)
+
+	t.Run("derives keys along the filecoin path", func(t *testing.T) {
+		kis, err := wallet.MnemonicKeys(testMnemonic, 2)
+		require.NoError(t, err)
+		require.Len(t, kis, 2)
+
+		assert.Equal(t, types.SECP256K1, kis[0].CryptSystem)
+		assert.Equal(t, "e1808079c6734eff9a187c917455dc1b2c70385e13f1cd6cecc94978e57f7f76", hex.EncodeToString(kis[0].PrivateKey))
+		assert.Equal(t, "ff91cfecbd459ca53112e15c6dd9b26cf4422bb5935c5616d5a6cad95ab0253b", hex.EncodeToString(kis[1].PrivateKey))
+	})
+
+	t.Run("ignores extra whitespace", func(t *testing.T) {
+		kis, err := wallet.MnemonicKeys(testMnemonic, 1)
+		require.NoError(t, err)
+		spaced, err := wallet.MnemonicKeys("  "+testMnemonic+"\n", 1)
+		require.NoError(t, err)
+		assert.True(t, kis[0].Equals(spaced[0]))
+	})
+
+	t.Run("rejects invalid mnemonics", func(t *testing.T) {
+		_, err := wallet.MnemonicKeys("abandon about", 1)
+		assert.Contains(t, err.Error(), "must have 12, 15, 18, 21 or 24 words")
+
+		_, err = wallet.MnemonicKeys("Abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about", 1)
+		assert.Contains(t, err.Error(), "invalid mnemonic word")
+
+		_, err = wallet.MnemonicKeys("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon filecoin", 1)
+		assert.Contains(t, err.Error(), "invalid mnemonic word")
+
+		_, err = wallet.MnemonicKeys("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon", 1)
+		assert.Contains(t, err.Error(), "invalid mnemonic checksum")
+
+		_, err = wallet.MnemonicKeys(testMnemonic, 0)
+		assert.Contains(t, err.Error(), "count must be positive")
+
+		_, err = wallet.MnemonicKeys(testMnemonic, wallet.MaxMnemonicKeys+1)
+		assert.Contains(t, err.Error(), "count must be at most")
+	})
+}
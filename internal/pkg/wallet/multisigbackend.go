@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"reflect"
+	"sync"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// MultisigBackendType is the reflect type of the MultisigBackend.
+var MultisigBackendType = reflect.TypeOf(&MultisigBackend{})
+
+// MultisigBackend is a wallet backend for a single m-of-n multisig address.
+// It holds no keys itself: it composes the backends that hold the keys of
+// some of the n signers, and accepts partial signatures of the others, e.g.
+// signatures made on other daemons. A multisig signature is valid once
+// m distinct signers signed the same data.
+//
+// The multisig address is an actor address derived from the threshold and
+// the signer addresses, so it cannot be verified without this backend.
+type MultisigBackend struct {
+	lk sync.Mutex
+
+	addr      address.Address
+	threshold int
+	signers   []address.Address
+	backends  []Backend
+
+	// partials are signatures added by AddSignature, by signer
+	partials map[address.Address]types.Signature
+}
+
+var _ Backend = (*MultisigBackend)(nil)
+
+// NewMultisigBackend constructs a backend for the address that requires
+// `threshold` of `signers` to sign. `backends` hold the keys of the local
+// signers; they need not hold all of them.
+func NewMultisigBackend(threshold int, signers []address.Address, backends ...Backend) (*MultisigBackend, error) {
+	addr, err := NewMultisigAddress(threshold, signers)
+	if err != nil {
+		return nil, err
+	}
+	return &MultisigBackend{
+		addr:      addr,
+		threshold: threshold,
+		signers:   append([]address.Address{}, signers...),
+		backends:  backends,
+		partials:  make(map[address.Address]types.Signature),
+	}, nil
+}
+
+// NewMultisigAddress returns the address that requires `threshold` of
+// `signers` to sign. The order of the signers matters.
+func NewMultisigAddress(threshold int, signers []address.Address) (address.Address, error) {
+	if len(signers) == 0 || len(signers) > maxMultisigSigners {
+		return address.Undef, errors.Errorf("a multisig address needs between 1 and %d signers, got %d", maxMultisigSigners, len(signers))
+	}
+	if threshold < 1 || threshold > len(signers) {
+		return address.Undef, errors.Errorf("threshold %d is not between 1 and the number of signers %d", threshold, len(signers))
+	}
+
+	seen := make(map[address.Address]bool, len(signers))
+	data := []byte{byte(threshold)}
+	for _, signer := range signers {
+		if signer.Protocol() != address.SECP256K1 && signer.Protocol() != address.BLS {
+			return address.Undef, errors.Errorf("signer %s is not a key address", signer)
+		}
+		if seen[signer] {
+			return address.Undef, errors.Errorf("signer %s is listed twice", signer)
+		}
+		seen[signer] = true
+		data = append(data, signer.Bytes()...)
+	}
+	return address.NewActorAddress(data)
+}
+
+// maxMultisigSigners bounds the signers of a multisig address so that a
+// signer index fits a byte of the multisig signature.
+const maxMultisigSigners = 255
+
+// Addresses returns the multisig address.
+func (backend *MultisigBackend) Addresses() []address.Address {
+	return []address.Address{backend.addr}
+}
+
+// HasAddress checks if addr is the multisig address.
+func (backend *MultisigBackend) HasAddress(addr address.Address) bool {
+	return addr == backend.addr
+}
+
+// Signers returns the addresses that can sign for the multisig address.
+func (backend *MultisigBackend) Signers() []address.Address {
+	return append([]address.Address{}, backend.signers...)
+}
+
+// RequiredSigners returns how many distinct signers must sign.
+func (backend *MultisigBackend) RequiredSigners() int {
+	return backend.threshold
+}
+
+// AddSignature adds the partial signature of signer `addr`, replacing any
+// earlier one. It is only checked, and used, when data is signed.
+func (backend *MultisigBackend) AddSignature(addr address.Address, sig types.Signature) error {
+	if backend.signerIndex(addr) < 0 {
+		return errors.Errorf("%s is not a signer of %s", addr, backend.addr)
+	}
+
+	backend.lk.Lock()
+	defer backend.lk.Unlock()
+	backend.partials[addr] = append(types.Signature{}, sig...)
+	return nil
+}
+
+// SignBytes signs `data` with the keys of the local signers and combines
+// these signatures with the added partial signatures that are valid for
+// `data`. It fails if fewer than RequiredSigners signatures are available.
+func (backend *MultisigBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	if !backend.HasAddress(addr) {
+		return nil, errors.New("backend does not contain address")
+	}
+
+	backend.lk.Lock()
+	partials := make(map[address.Address]types.Signature, len(backend.partials))
+	for signer, sig := range backend.partials {
+		partials[signer] = sig
+	}
+	backend.lk.Unlock()
+
+	var parts []multisigPart
+	for i, signer := range backend.signers {
+		if len(parts) == backend.threshold {
+			break
+		}
+		sig, ok := partials[signer]
+		if !ok || !types.IsValidSignature(data, signer, sig) {
+			sig, ok = backend.signLocally(data, signer)
+		}
+		if ok {
+			parts = append(parts, multisigPart{index: i, sig: sig})
+		}
+	}
+	if len(parts) < backend.threshold {
+		return nil, errors.Errorf("only %d of the %d required signers of %s signed", len(parts), backend.threshold, addr)
+	}
+	return encodeMultisigSignature(parts)
+}
+
+// Verify checks that `sig` holds valid signatures of `data` by at least
+// RequiredSigners distinct signers of the multisig address `addr`.
+func (backend *MultisigBackend) Verify(data []byte, addr address.Address, sig types.Signature) bool {
+	if !backend.HasAddress(addr) {
+		return false
+	}
+	parts, err := decodeMultisigSignature(sig)
+	if err != nil {
+		return false
+	}
+
+	signed := make(map[int]bool, len(parts))
+	for _, part := range parts {
+		if part.index >= len(backend.signers) || signed[part.index] {
+			return false
+		}
+		if !types.IsValidSignature(data, backend.signers[part.index], part.sig) {
+			return false
+		}
+		signed[part.index] = true
+	}
+	return len(signed) >= backend.threshold
+}
+
+// GetKeyInfo always fails: there is no single key of a multisig address.
+func (backend *MultisigBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	return nil, errors.Errorf("multisig address %s has no key", addr)
+}
+
+func (backend *MultisigBackend) signerIndex(addr address.Address) int {
+	for i, signer := range backend.signers {
+		if signer == addr {
+			return i
+		}
+	}
+	return -1
+}
+
+func (backend *MultisigBackend) signLocally(data []byte, signer address.Address) (types.Signature, bool) {
+	for _, b := range backend.backends {
+		if !b.HasAddress(signer) {
+			continue
+		}
+		// A backend that fails to sign, e.g. an unreachable remote signer,
+		// just does not count towards the threshold.
+		if sig, err := b.SignBytes(data, signer); err == nil {
+			return sig, true
+		}
+	}
+	return nil, false
+}
+
+// multisigPart is the signature of the signer at `index`.
+type multisigPart struct {
+	index int
+	sig   types.Signature
+}
+
+// encodeMultisigSignature lays out each part as its signer index, the length
+// of its signature and the signature, one byte each for index and length.
+func encodeMultisigSignature(parts []multisigPart) (types.Signature, error) {
+	var out types.Signature
+	for _, part := range parts {
+		if len(part.sig) > 255 {
+			return nil, errors.Errorf("signature of %d bytes is too long", len(part.sig))
+		}
+		out = append(out, byte(part.index), byte(len(part.sig)))
+		out = append(out, part.sig...)
+	}
+	return out, nil
+}
+
+func decodeMultisigSignature(sig types.Signature) ([]multisigPart, error) {
+	var parts []multisigPart
+	for len(sig) > 0 {
+		if len(sig) < 2 || len(sig) < 2+int(sig[1]) {
+			return nil, errors.New("truncated multisig signature")
+		}
+		end := 2 + int(sig[1])
+		parts = append(parts, multisigPart{index: int(sig[0]), sig: sig[2:end]})
+		sig = sig[end:]
+	}
+	return parts, nil
+}
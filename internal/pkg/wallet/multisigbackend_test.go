@@ -0,0 +1,96 @@
+package wallet_test
+
+import (
+	"testing"
+
+	"github.com/ipfs/go-datastore"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+func TestMultisigBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	data := []byte("data to be signed")
+
+	// Each signer keeps their key in a backend of their own, the third one
+	// with a bls key.
+	dsb1, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	dsb2, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	blsb := wallet.NewBLSBackend()
+
+	signer1, err := dsb1.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	signer2, err := dsb2.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	signer3, err := blsb.NewAddress()
+	require.NoError(t, err)
+	signers := []address.Address{signer1, signer2, signer3}
+
+	t.Run("two of three signers sign", func(t *testing.T) {
+		msb, err := wallet.NewMultisigBackend(2, signers, dsb1, blsb)
+		require.NoError(t, err)
+		addr := msb.Addresses()[0]
+		assert.Equal(t, address.Actor, addr.Protocol())
+		assert.Equal(t, 2, msb.RequiredSigners())
+
+		w := wallet.New(dsb1, msb)
+		sig, err := w.SignBytes(data, addr)
+		require.NoError(t, err)
+		assert.True(t, msb.Verify(data, addr, sig))
+		assert.False(t, msb.Verify([]byte("other data"), addr, sig))
+	})
+
+	t.Run("a single signature does not verify", func(t *testing.T) {
+		msb, err := wallet.NewMultisigBackend(2, signers, dsb1)
+		require.NoError(t, err)
+		addr := msb.Addresses()[0]
+
+		_, err = msb.SignBytes(data, addr)
+		assert.Error(t, err)
+
+		// A 1-of-3 signature of the same signers does not satisfy 2-of-3.
+		single, err := wallet.NewMultisigBackend(1, signers, dsb1)
+		require.NoError(t, err)
+		sig, err := single.SignBytes(data, single.Addresses()[0])
+		require.NoError(t, err)
+		assert.False(t, msb.Verify(data, addr, sig))
+	})
+
+	t.Run("partial signatures are collected from other backends", func(t *testing.T) {
+		msb, err := wallet.NewMultisigBackend(2, signers, dsb1)
+		require.NoError(t, err)
+		addr := msb.Addresses()[0]
+
+		partial, err := dsb2.SignBytes(data, signer2)
+		require.NoError(t, err)
+		require.NoError(t, msb.AddSignature(signer2, partial))
+
+		sig, err := msb.SignBytes(data, addr)
+		require.NoError(t, err)
+		assert.True(t, msb.Verify(data, addr, sig))
+
+		// The partial signature is not valid for other data.
+		_, err = msb.SignBytes([]byte("other data"), addr)
+		assert.Error(t, err)
+
+		stranger, err := dsb1.NewAddress(address.SECP256K1)
+		require.NoError(t, err)
+		assert.Error(t, msb.AddSignature(stranger, partial))
+	})
+
+	t.Run("rejects invalid signer sets", func(t *testing.T) {
+		_, err := wallet.NewMultisigAddress(0, signers)
+		assert.Error(t, err)
+		_, err = wallet.NewMultisigAddress(4, signers)
+		assert.Error(t, err)
+		_, err = wallet.NewMultisigAddress(1, []address.Address{signer1, signer1})
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,140 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"net/http"
+	"reflect"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+)
+
+// RemoteBackendType is the reflect type of the RemoteBackend.
+var RemoteBackendType = reflect.TypeOf(&RemoteBackend{})
+
+// remoteSignTimeout bounds how long a single signing request may take.
+const remoteSignTimeout = 30 * time.Second
+
+// RemoteSignRequest is the body POSTed to a remote signer.
+type RemoteSignRequest struct {
+	Address address.Address `json:"address"`
+	Data    []byte          `json:"data"`
+}
+
+// RemoteSignResponse is the body a remote signer replies with.
+type RemoteSignResponse struct {
+	Signature types.Signature `json:"signature"`
+}
+
+// RemoteBackend is a wallet backend that holds no keys. It forwards signing
+// requests for a fixed set of addresses to an HTTP endpoint, such as a
+// custodial service or a bridge to a hardware wallet.
+type RemoteBackend struct {
+	url    string
+	addrs  map[address.Address]struct{}
+	client *http.Client
+}
+
+var _ Backend = (*RemoteBackend)(nil)
+var _ ContextSigner = (*RemoteBackend)(nil)
+var _ PublicKeyGetter = (*RemoteBackend)(nil)
+
+// NewRemoteBackend constructs a backend that signs for `addrs` by POSTing a
+// RemoteSignRequest to `url`.
+func NewRemoteBackend(url string, addrs []address.Address) *RemoteBackend {
+	set := make(map[address.Address]struct{}, len(addrs))
+	for _, addr := range addrs {
+		set[addr] = struct{}{}
+	}
+	return &RemoteBackend{
+		url:    url,
+		addrs:  set,
+		client: &http.Client{Timeout: remoteSignTimeout},
+	}
+}
+
+// Addresses returns the addresses the remote signer signs for.
+func (backend *RemoteBackend) Addresses() []address.Address {
+	var cpy []address.Address
+	for addr := range backend.addrs {
+		cpy = append(cpy, addr)
+	}
+	return cpy
+}
+
+// HasAddress checks if the remote signer signs for the passed in address.
+func (backend *RemoteBackend) HasAddress(addr address.Address) bool {
+	_, ok := backend.addrs[addr]
+	return ok
+}
+
+// SignBytes asks the remote signer to sign `data` with the key of `addr`. The
+// signature it returns is checked before it is used.
+func (backend *RemoteBackend) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	return backend.SignBytesContext(context.Background(), data, addr)
+}
+
+// SignBytesContext is SignBytes, but abandons the request to the remote
+// signer once ctx is done.
+func (backend *RemoteBackend) SignBytesContext(ctx context.Context, data []byte, addr address.Address) (types.Signature, error) {
+	if !backend.HasAddress(addr) {
+		return nil, errors.New("backend does not contain address")
+	}
+
+	body, err := json.Marshal(RemoteSignRequest{Address: addr, Data: data})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequest(http.MethodPost, backend.url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := backend.client.Do(req.WithContext(ctx))
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		return nil, errors.Wrap(err, "failed to reach remote signer")
+	}
+	defer resp.Body.Close() // nolint: errcheck
+
+	if resp.StatusCode != http.StatusOK {
+		msg, _ := ioutil.ReadAll(resp.Body)
+		return nil, fmt.Errorf("remote signer returned %s: %s", resp.Status, bytes.TrimSpace(msg))
+	}
+	var out RemoteSignResponse
+	if err := json.NewDecoder(resp.Body).Decode(&out); err != nil {
+		return nil, errors.Wrap(err, "failed to decode remote signer response")
+	}
+
+	if !types.IsValidSignature(data, addr, out.Signature) {
+		return nil, fmt.Errorf("remote signer returned an invalid signature for %s", addr)
+	}
+	return out.Signature, nil
+}
+
+// GetPublicKey returns the public key of a BLS address, which is the payload
+// of the address. The public key of a secp256k1 address is only known to the
+// remote signer.
+func (backend *RemoteBackend) GetPublicKey(addr address.Address) ([]byte, error) {
+	if !backend.HasAddress(addr) {
+		return nil, errors.New("backend does not contain address")
+	}
+	if addr.Protocol() != address.BLS {
+		return nil, fmt.Errorf("the public key of %s is held by the remote signer", addr)
+	}
+	return addr.Payload(), nil
+}
+
+// GetKeyInfo always fails: the keys of a remote signer never leave it.
+func (backend *RemoteBackend) GetKeyInfo(addr address.Address) (*types.KeyInfo, error) {
+	return nil, errors.New("remote signer does not export keys")
+}
@@ -0,0 +1,142 @@
+package wallet_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	tf "github.com/filecoin-project/go-filecoin/internal/pkg/testhelpers/testflags"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/types"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/vm/address"
+	"github.com/filecoin-project/go-filecoin/internal/pkg/wallet"
+)
+
+// mockRemoteSigner is a remote signer serving requests with the keys of a
+// MockSigner. Its handler runs on the server's goroutines, so it reports
+// failures on errs instead of failing the test itself.
+type mockRemoteSigner struct {
+	*httptest.Server
+	errs chan error
+}
+
+// newMockRemoteSigner serves signing requests with the keys in ms. If forge
+// is set it replies with a signature that does not verify.
+func newMockRemoteSigner(ms types.MockSigner, forge bool) *mockRemoteSigner {
+	errs := make(chan error, 16)
+	report := func(err error) {
+		select {
+		case errs <- err:
+		default:
+		}
+	}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req wallet.RemoteSignRequest
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			report(err)
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+
+		sig, err := ms.SignBytes(req.Data, req.Address)
+		if err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		if forge {
+			sig[0] ^= 0xff
+		}
+		if err := json.NewEncoder(w).Encode(wallet.RemoteSignResponse{Signature: sig}); err != nil {
+			report(err)
+		}
+	}))
+	return &mockRemoteSigner{Server: server, errs: errs}
+}
+
+// close shuts the signer down and fails the test if its handler failed.
+func (s *mockRemoteSigner) close(t *testing.T) {
+	s.Server.Close()
+	close(s.errs)
+	for err := range s.errs {
+		assert.NoError(t, err)
+	}
+}
+
+func TestRemoteBackend(t *testing.T) {
+	tf.UnitTest(t)
+
+	ms := types.NewMockSigner(types.MustGenerateKeyInfo(2, 42))
+	signer := newMockRemoteSigner(ms, false)
+	defer signer.close(t)
+
+	addr := ms.Addresses[0]
+	backend := wallet.NewRemoteBackend(signer.URL, []address.Address{addr})
+	w := wallet.New(backend)
+
+	assert.Equal(t, []address.Address{addr}, w.Addresses())
+	assert.True(t, w.HasAddress(addr))
+	assert.False(t, w.HasAddress(ms.Addresses[1]))
+	assert.Len(t, w.Backends(wallet.RemoteBackendType), 1)
+
+	t.Run("signs through the remote signer", func(t *testing.T) {
+		data := []byte("remote data")
+		sig, err := w.SignBytes(data, addr)
+		require.NoError(t, err)
+		assert.True(t, types.IsValidSignature(data, addr, sig))
+	})
+
+	t.Run("signed messages verify", func(t *testing.T) {
+		msg := types.NewMeteredMessage(addr, address.NewForTestGetter()(), 0, types.ZeroAttoFIL, types.SendMethodID, nil, types.NewGasPrice(1), types.NewGasUnits(0))
+		smsg, err := types.NewSignedMessage(*msg, w)
+		require.NoError(t, err)
+		assert.True(t, smsg.VerifySignature())
+	})
+
+	t.Run("refuses addresses it was not given", func(t *testing.T) {
+		_, err := backend.SignBytes([]byte("data"), ms.Addresses[1])
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects invalid signatures", func(t *testing.T) {
+		forger := newMockRemoteSigner(ms, true)
+		defer forger.close(t)
+
+		_, err := wallet.NewRemoteBackend(forger.URL, []address.Address{addr}).SignBytes([]byte("data"), addr)
+		assert.Error(t, err)
+	})
+
+	t.Run("does not export keys", func(t *testing.T) {
+		_, err := w.Export([]address.Address{addr})
+		assert.Error(t, err)
+	})
+
+	t.Run("gives up when the context is done", func(t *testing.T) {
+		// A signer that never answers, like a device nobody confirms on.
+		unblock := make(chan struct{})
+		stuck := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			select {
+			case <-r.Context().Done():
+			case <-unblock:
+			}
+		}))
+		defer stuck.Close()
+		defer close(unblock)
+		w := wallet.New(wallet.NewRemoteBackend(stuck.URL, []address.Address{addr}))
+
+		ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+		defer cancel()
+		_, err := w.SignBytesContext(ctx, []byte("data"), addr)
+		assert.Equal(t, context.DeadlineExceeded, err)
+
+		ctx, cancel = context.WithCancel(context.Background())
+		cancel()
+		_, err = w.SignBytesContext(ctx, []byte("data"), addr)
+		assert.Equal(t, context.Canceled, err)
+	})
+}
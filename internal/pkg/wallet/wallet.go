@@ -2,6 +2,7 @@ package wallet
 
 import (
 	"bytes"
+	"context"
 	"fmt"
 	"reflect"
 	"sort"
@@ -98,33 +99,99 @@ func (w *Wallet) Backends(kind reflect.Type) []Backend {
 // SignBytes cryptographically signs `data` using the private key corresponding to
 // address `addr`
 func (w *Wallet) SignBytes(data []byte, addr address.Address) (types.Signature, error) {
+	return w.SignBytesContext(context.Background(), data, addr)
+}
+
+// SignBytesContext is SignBytes, but gives up and returns ctx.Err() once ctx
+// is done. Backends that implement ContextSigner are cancelled while signing,
+// others are only checked before they sign.
+func (w *Wallet) SignBytesContext(ctx context.Context, data []byte, addr address.Address) (types.Signature, error) {
 	// Check that we are storing the address to sign for.
 	backend, err := w.Find(addr)
 	if err != nil {
 		return nil, errors.Wrapf(err, "could not find address: %s", addr)
 	}
+	if cs, ok := backend.(ContextSigner); ok {
+		return cs.SignBytesContext(ctx, data, addr)
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 	return backend.SignBytes(data, addr)
 }
 
+// SignBytesBatch signs each of `items` with the private key corresponding to
+// address `addr` and returns the signatures in the same order. Backends that
+// implement BatchSigner sign the whole batch at once, others sign the items
+// one at a time.
+func (w *Wallet) SignBytesBatch(items [][]byte, addr address.Address) ([]types.Signature, error) {
+	backend, err := w.Find(addr)
+	if err != nil {
+		return nil, errors.Wrapf(err, "could not find address: %s", addr)
+	}
+	if bs, ok := backend.(BatchSigner); ok {
+		return bs.SignBytesBatch(items, addr)
+	}
+
+	sigs := make([]types.Signature, len(items))
+	for i, data := range items {
+		if sigs[i], err = w.SignBytes(data, addr); err != nil {
+			return nil, err
+		}
+	}
+	return sigs, nil
+}
+
 // NewAddress creates a new account address on the default wallet backend.
 func NewAddress(w *Wallet, p address.Protocol) (address.Address, error) {
-	backends := w.Backends(DSBackendType)
+	backends := w.datastoreBackends()
 	if len(backends) == 0 {
 		return address.Undef, fmt.Errorf("missing default ds backend")
 	}
 
-	backend := (backends[0]).(*DSBackend)
-	return backend.NewAddress(p)
+	return backends[0].NewAddress(p)
+}
+
+// datastoreBackends returns the backends keeping their keys in a datastore,
+// which create and import keys. An encrypted backend keeps them encrypted.
+func (w *Wallet) datastoreBackends() []*DSBackend {
+	var out []*DSBackend
+	for _, backend := range w.Backends(DSBackendType) {
+		out = append(out, backend.(*DSBackend))
+	}
+	for _, backend := range w.Backends(EncryptedBackendType) {
+		out = append(out, backend.(*EncryptedBackend).DSBackend)
+	}
+	return out
+}
+
+// Verify checks that `sig` is a signature of `data` by `addr`. The address
+// does not need to be in the wallet; if it is and its backend is a Verifier,
+// the backend checks the signature.
+func (w *Wallet) Verify(data []byte, addr address.Address, sig types.Signature) bool {
+	if backend, err := w.Find(addr); err == nil {
+		if v, ok := backend.(Verifier); ok {
+			return v.Verify(data, addr, sig)
+		}
+	}
+	return types.IsValidSignature(data, addr, sig)
 }
 
 // GetPubKeyForAddress returns the public key in the keystore associated with
-// the given address.
+// the given address. BLS public keys are in the BLS public key encoding.
 func (w *Wallet) GetPubKeyForAddress(addr address.Address) ([]byte, error) {
-	info, err := w.keyInfoForAddr(addr)
+	backend, err := w.Find(addr)
 	if err != nil {
 		return nil, err
 	}
+	if pkg, ok := backend.(PublicKeyGetter); ok {
+		return pkg.GetPublicKey(addr)
+	}
 
+	info, err := backend.GetKeyInfo(addr)
+	if err != nil {
+		return nil, err
+	}
 	return info.PublicKey(), nil
 }
 
@@ -153,15 +220,11 @@ func (w *Wallet) keyInfoForAddr(addr address.Address) (*types.KeyInfo, error) {
 
 // Import adds the given keyinfos to the wallet
 func (w *Wallet) Import(kinfos ...*types.KeyInfo) ([]address.Address, error) {
-	dsb := w.Backends(DSBackendType)
+	dsb := w.datastoreBackends()
 	if len(dsb) != 1 {
 		return nil, fmt.Errorf("expected exactly one datastore wallet backend")
 	}
-
-	imp, ok := dsb[0].(Importer)
-	if !ok {
-		return nil, fmt.Errorf("datastore backend wallets should implement importer")
-	}
+	imp := dsb[0]
 
 	var out []address.Address
 	for _, ki := range kinfos {
@@ -187,7 +250,11 @@ func (w *Wallet) Export(addrs []address.Address) ([]*types.KeyInfo, error) {
 			return nil, err
 		}
 
-		ki, err := bck.GetKeyInfo(addr)
+		exp, ok := bck.(Exporter)
+		if !ok {
+			return nil, fmt.Errorf("the backend of %s does not export keys", addr)
+		}
+		ki, err := exp.ExportKey(addr)
 		if err != nil {
 			return nil, err
 		}
@@ -196,3 +263,31 @@ func (w *Wallet) Export(addrs []address.Address) ([]*types.KeyInfo, error) {
 
 	return out, nil
 }
+
+// DeleteAddress removes the key of the given address from the backend that
+// holds it. It returns ErrUnknownAddress if no backend holds the address.
+func (w *Wallet) DeleteAddress(addr address.Address) error {
+	bck, err := w.Find(addr)
+	if err != nil {
+		return err
+	}
+	del, ok := bck.(Deleter)
+	if !ok {
+		return fmt.Errorf("the backend of %s does not delete keys", addr)
+	}
+	return del.DeleteAddress(addr)
+}
+
+// Unlock unlocks the encrypted backends of the wallet with passphrase.
+func (w *Wallet) Unlock(passphrase string) error {
+	backends := w.Backends(EncryptedBackendType)
+	if len(backends) == 0 {
+		return fmt.Errorf("the wallet has no encrypted backend")
+	}
+	for _, backend := range backends {
+		if err := backend.(*EncryptedBackend).Unlock(passphrase); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -2,6 +2,7 @@ package wallet_test
 
 import (
 	"bytes"
+	"context"
 	"testing"
 
 	"github.com/filecoin-project/go-bls-sigs"
@@ -151,6 +152,41 @@ func TestSimpleSignAndVerify(t *testing.T) {
 	assert.False(t, secondValid)
 }
 
+func TestWalletVerify(t *testing.T) {
+	tf.UnitTest(t)
+
+	data := []byte("data to be signed")
+
+	dsb, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	blsb := wallet.NewBLSBackend()
+	secpAddr, err := dsb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	blsAddr, err := blsb.NewAddress()
+	require.NoError(t, err)
+	msb, err := wallet.NewMultisigBackend(2, []address.Address{secpAddr, blsAddr}, dsb, blsb)
+	require.NoError(t, err)
+	msAddr := msb.Addresses()[0]
+
+	signer := wallet.New(dsb, blsb, msb)
+	for _, addr := range []address.Address{secpAddr, blsAddr, msAddr} {
+		sig, err := signer.SignBytes(data, addr)
+		require.NoError(t, err)
+
+		assert.True(t, signer.Verify(data, addr, sig), "%s", addr)
+		assert.False(t, signer.Verify([]byte("other data"), addr, sig), "%s", addr)
+	}
+
+	t.Run("verifies addresses not in the wallet", func(t *testing.T) {
+		verifier := wallet.New()
+		for _, addr := range []address.Address{secpAddr, blsAddr} {
+			sig, err := signer.SignBytes(data, addr)
+			require.NoError(t, err)
+			assert.True(t, verifier.Verify(data, addr, sig), "%s", addr)
+		}
+	})
+}
+
 func TestSignErrorCases(t *testing.T) {
 	tf.UnitTest(t)
 
@@ -198,3 +234,224 @@ func TestSignErrorCases(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "could not find address:")
 }
+
+func TestSignBytesContextFallsBackToSignBytes(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	w := wallet.New(fs)
+	addr, err := wallet.NewAddress(w, address.SECP256K1)
+	require.NoError(t, err)
+
+	data := []byte("data")
+	sig, err := w.SignBytesContext(context.Background(), data, addr)
+	require.NoError(t, err)
+	assert.True(t, types.IsValidSignature(data, addr, sig))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = w.SignBytesContext(ctx, data, addr)
+	assert.Equal(t, context.Canceled, err)
+}
+
+func TestSignBytesBatch(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	other, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	w := wallet.New(fs, singleSigner{other})
+
+	items := [][]byte{[]byte("first"), []byte("second"), []byte("third")}
+
+	t.Run("signs all items with a batch signer", func(t *testing.T) {
+		for _, protocol := range []address.Protocol{address.SECP256K1, address.BLS} {
+			addr, err := wallet.NewAddress(w, protocol)
+			require.NoError(t, err)
+
+			sigs, err := w.SignBytesBatch(items, addr)
+			require.NoError(t, err)
+			require.Len(t, sigs, len(items))
+			for i, data := range items {
+				assert.True(t, types.IsValidSignature(data, addr, sigs[i]))
+			}
+			assert.False(t, types.IsValidSignature(items[0], addr, sigs[1]))
+		}
+	})
+
+	t.Run("falls back to signing one at a time", func(t *testing.T) {
+		addr, err := other.NewAddress(address.SECP256K1)
+		require.NoError(t, err)
+
+		sigs, err := w.SignBytesBatch(items, addr)
+		require.NoError(t, err)
+		require.Len(t, sigs, len(items))
+		for i, data := range items {
+			assert.True(t, types.IsValidSignature(data, addr, sigs[i]))
+		}
+	})
+
+	t.Run("fails for unknown addresses", func(t *testing.T) {
+		_, err := w.SignBytesBatch(items, address.NewForTestGetter()())
+		assert.Contains(t, err.Error(), "could not find address")
+	})
+}
+
+func BenchmarkSignBytes(b *testing.B) {
+	w, addr, items := setupSignBenchmark(b)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		for _, data := range items {
+			if _, err := w.SignBytes(data, addr); err != nil {
+				b.Fatal(err)
+			}
+		}
+	}
+}
+
+func BenchmarkSignBytesBatch(b *testing.B) {
+	w, addr, items := setupSignBenchmark(b)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := w.SignBytesBatch(items, addr); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// setupSignBenchmark returns an unlocked encrypted wallet, one of its
+// addresses and 100 items to sign.
+func setupSignBenchmark(b *testing.B) (*wallet.Wallet, address.Address, [][]byte) {
+	eb, err := wallet.NewEncryptedBackend(datastore.NewMapDatastore())
+	require.NoError(b, err)
+	require.NoError(b, eb.Unlock("correct horse"))
+	addr, err := eb.NewAddress(address.SECP256K1)
+	require.NoError(b, err)
+
+	items := make([][]byte, 100)
+	for i := range items {
+		items[i] = []byte{byte(i)}
+	}
+	return wallet.New(eb), addr, items
+}
+
+func TestSignMessage(t *testing.T) {
+	tf.UnitTest(t)
+
+	fs, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	to := address.NewForTestGetter()()
+
+	for _, protocol := range []address.Protocol{address.SECP256K1, address.BLS} {
+		from, err := fs.NewAddress(protocol)
+		require.NoError(t, err)
+
+		msg := types.NewMeteredMessage(from, to, 3, types.NewAttoFILFromFIL(2), types.MethodID(1), []byte("params"), types.NewGasPrice(1), types.NewGasUnits(100))
+		smsg, err := types.NewSignedMessage(*msg, fs)
+		require.NoError(t, err)
+		assert.Equal(t, *msg, smsg.Message)
+		assert.True(t, smsg.VerifySignature())
+
+		t.Log("the signature does not cover a tampered message")
+		smsg.Message.CallSeqNum++
+		assert.False(t, smsg.VerifySignature())
+		smsg.Message.CallSeqNum--
+		smsg.Message.Value = types.NewAttoFILFromFIL(20)
+		assert.False(t, smsg.VerifySignature())
+	}
+
+	t.Log("the backend must hold the sender's key")
+	msg := types.NewUnsignedMessage(to, to, 0, types.ZeroAttoFIL, types.MethodID(1), nil)
+	_, err = types.NewSignedMessage(*msg, fs)
+	assert.Error(t, err)
+}
+
+func TestGetPubKeyForAddress(t *testing.T) {
+	tf.UnitTest(t)
+
+	dsb, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	blsb := wallet.NewBLSBackend()
+
+	secpAddr, err := dsb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	blsAddr, err := blsb.NewAddress()
+	require.NoError(t, err)
+
+	ms := types.NewMockSigner(types.MustGenerateKeyInfo(1, 42))
+	remoteSecp := ms.Addresses[0]
+	remoteBLS := types.MustGenerateMixedKeyInfo(1, 1)[0]
+	remoteBLSAddr, err := remoteBLS.Address()
+	require.NoError(t, err)
+	remote := wallet.NewRemoteBackend("http://localhost", []address.Address{remoteSecp, remoteBLSAddr})
+
+	w := wallet.New(dsb, blsb, remote)
+
+	t.Run("secp256k1 keys derive their address", func(t *testing.T) {
+		pubkey, err := w.GetPubKeyForAddress(secpAddr)
+		require.NoError(t, err)
+		derived, err := address.NewSecp256k1Address(pubkey)
+		require.NoError(t, err)
+		assert.Equal(t, secpAddr, derived)
+	})
+
+	t.Run("bls keys are in the bls encoding", func(t *testing.T) {
+		pubkey, err := w.GetPubKeyForAddress(blsAddr)
+		require.NoError(t, err)
+		assert.Len(t, pubkey, bls.PublicKeyBytes)
+		derived, err := address.NewBLSAddress(pubkey)
+		require.NoError(t, err)
+		assert.Equal(t, blsAddr, derived)
+	})
+
+	t.Run("remote signers only know bls keys", func(t *testing.T) {
+		pubkey, err := w.GetPubKeyForAddress(remoteBLSAddr)
+		require.NoError(t, err)
+		assert.Equal(t, remoteBLS.PublicKey(), pubkey)
+
+		_, err = w.GetPubKeyForAddress(remoteSecp)
+		assert.Error(t, err)
+	})
+}
+
+func TestWalletExport(t *testing.T) {
+	tf.UnitTest(t)
+
+	dsb, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	blsb := wallet.NewBLSBackend()
+	remoteAddr := types.NewMockSigner(types.MustGenerateKeyInfo(1, 42)).Addresses[0]
+	w := wallet.New(dsb, blsb, wallet.NewRemoteBackend("http://127.0.0.1:0", []address.Address{remoteAddr}))
+
+	secpAddr, err := dsb.NewAddress(address.SECP256K1)
+	require.NoError(t, err)
+	blsAddr, err := blsb.NewAddress()
+	require.NoError(t, err)
+
+	kis, err := w.Export([]address.Address{secpAddr, blsAddr})
+	require.NoError(t, err)
+	require.Len(t, kis, 2)
+	for i, addr := range []address.Address{secpAddr, blsAddr} {
+		exported, err := kis[i].Address()
+		require.NoError(t, err)
+		assert.Equal(t, addr, exported)
+	}
+
+	// Exported keys import into another wallet.
+	other, err := wallet.NewDSBackend(datastore.NewMapDatastore())
+	require.NoError(t, err)
+	imported, err := wallet.New(other).Import(kis...)
+	require.NoError(t, err)
+	assert.Equal(t, []address.Address{secpAddr, blsAddr}, imported)
+
+	_, err = w.Export([]address.Address{remoteAddr})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "does not export keys")
+}
+
+// singleSigner hides the batch signing of the backend it wraps.
+type singleSigner struct {
+	wallet.Backend
+}
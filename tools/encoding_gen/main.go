@@ -95,6 +95,8 @@ func main() {
 		retrieval.RetrievePieceRequest{},  // protocol/retrieval/types.go
 		retrieval.RetrievePieceResponse{}, // protocol/retrieval/types.go
 		retrieval.RetrievePieceChunk{},    // protocol/retrieval/types.go
+		retrieval.AuditPieceRequest{},     // protocol/retrieval/types.go
+		retrieval.AuditPieceResponse{},    // protocol/retrieval/types.go
 	); err != nil {
 		fmt.Println(err)
 		os.Exit(1)
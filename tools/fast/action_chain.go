@@ -6,16 +6,17 @@ import (
 
 	"github.com/ipfs/go-cid"
 
+	"github.com/filecoin-project/go-filecoin/cmd/go-filecoin"
 	"github.com/filecoin-project/go-filecoin/internal/pkg/chain"
 )
 
 // ChainHead runs the chain head command against the filecoin process.
 func (f *Filecoin) ChainHead(ctx context.Context) ([]cid.Cid, error) {
-	var out []cid.Cid
+	var out commands.ChainHeadResult
 	if err := f.RunCmdJSONWithStdin(ctx, nil, &out, "go-filecoin", "chain", "head"); err != nil {
 		return nil, err
 	}
-	return out, nil
+	return out.Key.ToSlice(), nil
 
 }
 
@@ -112,20 +112,20 @@ func (f *Filecoin) MinerProvingWindow(ctx context.Context, miner address.Address
 }
 
 // MinerWorker runs the `miner worker` command against the filecoin process
-func (f *Filecoin) MinerWorker(ctx context.Context) (commands.MinerWorkerResult, error) {
+func (f *Filecoin) MinerWorker(ctx context.Context, minerAddr address.Address) (commands.MinerWorkerResult, error) {
 	var out commands.MinerWorkerResult
 
-	if err := f.RunCmdJSONWithStdin(ctx, nil, &out, "go-filecoin", "miner", "worker"); err != nil {
+	if err := f.RunCmdJSONWithStdin(ctx, nil, &out, "go-filecoin", "miner", "worker", minerAddr.String()); err != nil {
 		return out, err
 	}
 	return out, nil
 }
 
 // MinerSetWorker runs the `miner set-worker` command against the filecoin process
-func (f *Filecoin) MinerSetWorker(ctx context.Context, newAddr address.Address, options ...ActionOption) (cid.Cid, error) {
+func (f *Filecoin) MinerSetWorker(ctx context.Context, minerAddr, newAddr address.Address, options ...ActionOption) (cid.Cid, error) {
 	var out cid.Cid
 
-	args := []string{"go-filecoin", "miner", "set-worker", newAddr.String()}
+	args := []string{"go-filecoin", "miner", "set-worker", minerAddr.String(), newAddr.String()}
 
 	for _, option := range options {
 		args = append(args, option()...)
@@ -4,6 +4,7 @@ import (
 	"context"
 	"fmt"
 	"io"
+	"math"
 	"math/big"
 	mrand "math/rand"
 	"strconv"
@@ -36,6 +37,7 @@ import (
 	dag "github.com/ipfs/go-merkledag"
 	"github.com/libp2p/go-libp2p-core/peer"
 	mh "github.com/multiformats/go-multihash"
+	"github.com/pkg/errors"
 	typegen "github.com/whyrusleeping/cbor-gen"
 )
 
@@ -80,6 +82,51 @@ type GenesisCfg struct {
 	ProofsMode types.ProofsMode
 }
 
+// networkFunds is the balance, in whole filecoin, of the network account in
+// the genesis block. The collateral of the genesis miners is paid from it.
+const networkFunds = 10000000000
+
+// minerCollateral is the collateral, in whole filecoin, that each genesis
+// miner is created with.
+const minerCollateral = 100000
+
+// Validate checks that cfg describes a genesis block that can be created:
+// every preallocation and miner owner refers to a generated key and the
+// network account can pay the collateral of all miners.
+func (cfg *GenesisCfg) Validate() error {
+	if cfg.Keys < 0 {
+		return fmt.Errorf("negative number of keys %d", cfg.Keys)
+	}
+	if len(cfg.PreAlloc) > cfg.Keys {
+		return fmt.Errorf("%d preallocations but only %d keys", len(cfg.PreAlloc), cfg.Keys)
+	}
+	for i, v := range cfg.PreAlloc {
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+			return fmt.Errorf("preallocation %d: %q is not a whole number of filecoin", i, v)
+		}
+	}
+
+	for i, m := range cfg.Miners {
+		if m == nil {
+			return fmt.Errorf("miner %d is empty", i)
+		}
+		if m.Owner < 0 || m.Owner >= cfg.Keys {
+			return fmt.Errorf("miner %d: owner %d is not one of the %d keys", i, m.Owner, cfg.Keys)
+		}
+		if m.NumCommittedSectors > 0 && m.SectorSize == 0 {
+			return fmt.Errorf("miner %d: committed sectors without a sector size", i)
+		}
+		if m.SectorSize > 0 && m.NumCommittedSectors > math.MaxUint64/m.SectorSize {
+			return fmt.Errorf("miner %d: power of %d sectors of %d bytes overflows", i, m.NumCommittedSectors, m.SectorSize)
+		}
+	}
+	if uint64(len(cfg.Miners))*minerCollateral > networkFunds {
+		return fmt.Errorf("collateral of %d miners exceeds the %d FIL of the network account", len(cfg.Miners), networkFunds)
+	}
+
+	return nil
+}
+
 // RenderedGenInfo contains information about a genesis block creation
 type RenderedGenInfo struct {
 	// Keys is the set of keys generated
@@ -110,6 +157,10 @@ type RenderedMinerInfo struct {
 //
 // WARNING: Do not use maps in this code, they will make this code non deterministic.
 func GenGen(ctx context.Context, cfg *GenesisCfg, cst *hamt.CborIpldStore, bs blockstore.Blockstore, seed int64, genesisTime time.Time) (*RenderedGenInfo, error) {
+	if err := cfg.Validate(); err != nil {
+		return nil, errors.Wrap(err, "invalid genesis configuration")
+	}
+
 	pnrg := mrand.New(mrand.NewSource(seed))
 	keys, err := genKeys(cfg.Keys, pnrg)
 	if err != nil {
@@ -221,7 +272,7 @@ func setupPrealloc(st state.Tree, keys []*types.KeyInfo, prealloc []string) erro
 		}
 	}
 
-	netact, err := account.NewActor(types.NewAttoFILFromFIL(10000000000))
+	netact, err := account.NewActor(types.NewAttoFILFromFIL(networkFunds))
 	if err != nil {
 		return err
 	}
@@ -256,12 +307,12 @@ func setupMiners(st state.Tree, sm vm.StorageMap, keys []*types.KeyInfo, miners
 		}
 
 		// give collateral to account actor
-		_, err = applyMessageDirect(ctx, st, sm, address.NetworkAddress, addr, types.NewAttoFILFromFIL(100000), types.SendMethodID)
+		_, err = applyMessageDirect(ctx, st, sm, address.NetworkAddress, addr, types.NewAttoFILFromFIL(minerCollateral), types.SendMethodID)
 		if err != nil {
 			return nil, err
 		}
 
-		ret, err := applyMessageDirect(ctx, st, sm, addr, address.PowerAddress, types.NewAttoFILFromFIL(100000), power.CreateStorageMiner, addr, addr, pid, types.NewBytesAmount(m.SectorSize))
+		ret, err := applyMessageDirect(ctx, st, sm, addr, address.PowerAddress, types.NewAttoFILFromFIL(minerCollateral), power.CreateStorageMiner, addr, addr, pid, types.NewBytesAmount(m.SectorSize))
 		if err != nil {
 			return nil, err
 		}
@@ -365,6 +416,11 @@ func (gbr *blockRewarder) GasReward(ctx context.Context, st state.Tree, minerOwn
 	return nil
 }
 
+// BlockRewardAt is zero
+func (gbr *blockRewarder) BlockRewardAt(h uint64) types.AttoFIL {
+	return types.ZeroAttoFIL
+}
+
 // signer doesn't actually sign because it's not actually validated
 type signer struct{}
 
@@ -77,3 +77,55 @@ func TestGenGenDeterministicBetweenBuilds(t *testing.T) {
 		}
 	}
 }
+
+func TestGenesisCfgValidate(t *testing.T) {
+	tf.UnitTest(t)
+
+	valid := func() *GenesisCfg {
+		return &GenesisCfg{
+			Keys:     2,
+			PreAlloc: []string{"10", "50"},
+			Miners: []*CreateStorageMinerConfig{
+				{Owner: 1, NumCommittedSectors: 3, SectorSize: types.OneKiBSectorSize.Uint64()},
+			},
+		}
+	}
+	assert.NoError(t, valid().Validate())
+	assert.NoError(t, testConfig.Validate())
+
+	cases := []struct {
+		name   string
+		modify func(cfg *GenesisCfg)
+		err    string
+	}{
+		{"more preallocations than keys", func(cfg *GenesisCfg) { cfg.Keys = 1; cfg.Miners[0].Owner = 0 }, "2 preallocations but only 1 keys"},
+		{"fractional preallocation", func(cfg *GenesisCfg) { cfg.PreAlloc[1] = "1.5" }, "preallocation 1"},
+		{"negative preallocation", func(cfg *GenesisCfg) { cfg.PreAlloc[0] = "-10" }, "preallocation 0"},
+		{"owner out of range", func(cfg *GenesisCfg) { cfg.Miners[0].Owner = 2 }, "owner 2 is not one of the 2 keys"},
+		{"negative owner", func(cfg *GenesisCfg) { cfg.Miners[0].Owner = -1 }, "owner -1"},
+		{"empty miner", func(cfg *GenesisCfg) { cfg.Miners = append(cfg.Miners, nil) }, "miner 1 is empty"},
+		{"sectors without size", func(cfg *GenesisCfg) { cfg.Miners[0].SectorSize = 0 }, "without a sector size"},
+		{"power overflows", func(cfg *GenesisCfg) { cfg.Miners[0].NumCommittedSectors = 1 << 60 }, "overflows"},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			cfg := valid()
+			tc.modify(cfg)
+			err := cfg.Validate()
+			if assert.Error(t, err) {
+				assert.Contains(t, err.Error(), tc.err)
+			}
+		})
+	}
+
+	t.Run("GenGen rejects invalid configurations", func(t *testing.T) {
+		cfg := valid()
+		cfg.Miners[0].Owner = 5
+
+		bstore := blockstore.NewBlockstore(ds.NewMapDatastore())
+		_, err := GenGen(context.Background(), cfg, hamt.CSTFromBstore(bstore), bstore, 0, defaultGenesisTime)
+		if assert.Error(t, err) {
+			assert.Contains(t, err.Error(), "invalid genesis configuration")
+		}
+	})
+}